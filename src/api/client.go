@@ -637,6 +637,17 @@ func (c *Client) Wallets() ([]WalletResponse, error) {
 	return wrs, nil
 }
 
+// WalletsWithEvents makes a request to GET /api/v1/wallets?events=1, returning the wallets
+// and the recent changes of the wallet directory applied by the wallet directory watcher
+func (c *Client) WalletsWithEvents() (*WalletsWithEventsResponse, error) {
+	var rsp WalletsWithEventsResponse
+	if err := c.Get("/api/v1/wallets?events=1", &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
 // CreateUnencryptedWallet makes a request to POST /api/v1/wallet/create and creates
 // a wallet without encryption.
 // If scanN is <= 0, the scan number defaults to 1
@@ -678,6 +689,62 @@ func (c *Client) CreateEncryptedWallet(seed, label, password string, scanN int)
 	return &w, nil
 }
 
+// CreateWalletOptions are the options for creating a wallet
+type CreateWalletOptions struct {
+	Type           string
+	Seed           string
+	SeedPassphrase string
+	XPub           string
+	Label          string
+	Password       string
+	ScanN          int
+	Encrypt        bool
+	FreshChange    bool
+}
+
+// CreateWallet makes a request to POST /api/v1/wallet/create and creates a wallet.
+// If the wallet type is not specified, a deterministic wallet is created.
+// If scanN is <= 0, the scan number defaults to 1
+func (c *Client) CreateWallet(o CreateWalletOptions) (*WalletResponse, error) {
+	v := url.Values{}
+	v.Add("label", o.Label)
+	v.Add("encrypt", fmt.Sprint(o.Encrypt))
+
+	if o.Seed != "" {
+		v.Add("seed", o.Seed)
+	}
+
+	if o.Type != "" {
+		v.Add("type", o.Type)
+	}
+
+	if o.XPub != "" {
+		v.Add("xpub", o.XPub)
+	}
+
+	if o.SeedPassphrase != "" {
+		v.Add("seed-passphrase", o.SeedPassphrase)
+	}
+
+	if o.Password != "" {
+		v.Add("password", o.Password)
+	}
+
+	if o.ScanN > 0 {
+		v.Add("scan", fmt.Sprint(o.ScanN))
+	}
+
+	if o.FreshChange {
+		v.Add("fresh-change", "true")
+	}
+
+	var w WalletResponse
+	if err := c.PostForm("/api/v1/wallet/create", strings.NewReader(v.Encode()), &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
 // NewWalletAddress makes a request to POST /api/v1/wallet/newAddress
 // if n is <= 0, defaults to 1
 func (c *Client) NewWalletAddress(id string, n int, password string) ([]string, error) {
@@ -719,6 +786,7 @@ type CreateTransactionRequest struct {
 	To                []Receiver     `json:"to"`
 	UxOuts            []string       `json:"unspents,omitempty"`
 	Addresses         []string       `json:"addresses,omitempty"`
+	CoinSelection     string         `json:"coin_selection,omitempty"`
 }
 
 // HoursSelection defines options for hours distribution
@@ -737,9 +805,10 @@ type Receiver struct {
 
 // WalletCreateTransactionRequest is sent to /api/v1/wallet/transaction
 type WalletCreateTransactionRequest struct {
-	Unsigned bool   `json:"unsigned"`
-	WalletID string `json:"wallet_id"`
-	Password string `json:"password"`
+	Unsigned         bool   `json:"unsigned"`
+	WalletID         string `json:"wallet_id"`
+	Password         string `json:"password"`
+	ApprovalPassword string `json:"approval_password,omitempty"`
 	CreateTransactionRequest
 }
 
@@ -754,6 +823,27 @@ func (c *Client) WalletCreateTransaction(req WalletCreateTransactionRequest) (*C
 	return &r, nil
 }
 
+// WalletSplitTransactionRequest is sent to /api/v2/wallet/transaction/split
+type WalletSplitTransactionRequest struct {
+	WalletID         string `json:"wallet_id"`
+	Password         string `json:"password"`
+	ApprovalPassword string `json:"approval_password,omitempty"`
+	CreateTransactionRequest
+}
+
+// WalletSplitTransaction makes a request to POST /api/v2/wallet/transaction/split.
+// The returned transactions are signed but not broadcast. If there is more than one transaction,
+// the payment transaction is last and can only be injected once the others are confirmed.
+func (c *Client) WalletSplitTransaction(req WalletSplitTransactionRequest) (*WalletSplitTransactionResponse, error) {
+	var rsp WalletSplitTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/transaction/split", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // WalletSignTransaction makes a request to POST /api/v2/wallet/transaction/sign
 func (c *Client) WalletSignTransaction(req WalletSignTransactionRequest) (*CreateTransactionResponse, error) {
 	var r CreateTransactionResponse
@@ -765,6 +855,72 @@ func (c *Client) WalletSignTransaction(req WalletSignTransactionRequest) (*Creat
 	return nil, err
 }
 
+// CreatePartialTransaction makes a request to POST /api/v2/transaction/partial/create
+func (c *Client) CreatePartialTransaction(encodedTxn string) (*PartialTransactionResponse, error) {
+	req := CreatePartialTransactionRequest{
+		EncodedTransaction: encodedTxn,
+	}
+
+	var r PartialTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/transaction/partial/create", req, &r)
+	if ok {
+		return &r, err
+	}
+	return nil, err
+}
+
+// WalletSignPartialTransaction makes a request to POST /api/v2/wallet/transaction/partial/sign
+func (c *Client) WalletSignPartialTransaction(req WalletSignPartialTransactionRequest) (*PartialTransactionResponse, error) {
+	var r PartialTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/transaction/partial/sign", req, &r)
+	if ok {
+		return &r, err
+	}
+	return nil, err
+}
+
+// CombinePartialTransactions makes a request to POST /api/v2/transaction/partial/combine
+func (c *Client) CombinePartialTransactions(pts []json.RawMessage) (*PartialTransactionResponse, error) {
+	req := CombinePartialTransactionsRequest{
+		PartialTransactions: pts,
+	}
+
+	var r PartialTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/transaction/partial/combine", req, &r)
+	if ok {
+		return &r, err
+	}
+	return nil, err
+}
+
+// InspectPartialTransaction makes a request to POST /api/v2/transaction/partial/inspect
+func (c *Client) InspectPartialTransaction(pt json.RawMessage) (*InspectPartialTransactionResponse, error) {
+	req := PartialTransactionRequest{
+		PartialTransaction: pt,
+	}
+
+	var r InspectPartialTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/transaction/partial/inspect", req, &r)
+	if ok {
+		return &r, err
+	}
+	return nil, err
+}
+
+// FinalizePartialTransaction makes a request to POST /api/v2/transaction/partial/finalize
+func (c *Client) FinalizePartialTransaction(pt json.RawMessage) (*FinalizePartialTransactionResponse, error) {
+	req := PartialTransactionRequest{
+		PartialTransaction: pt,
+	}
+
+	var r FinalizePartialTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/transaction/partial/finalize", req, &r)
+	if ok {
+		return &r, err
+	}
+	return nil, err
+}
+
 // CreateTransaction makes a request to POST /api/v2/transaction
 func (c *Client) CreateTransaction(req CreateTransactionRequest) (*CreateTransactionResponse, error) {
 	var r CreateTransactionResponse
@@ -776,6 +932,17 @@ func (c *Client) CreateTransaction(req CreateTransactionRequest) (*CreateTransac
 	return nil, err
 }
 
+// PreviewTransaction makes a request to POST /api/v2/transaction/preview
+func (c *Client) PreviewTransaction(req CreateTransactionRequest) (*TransactionPreviewResponse, error) {
+	var r TransactionPreviewResponse
+	endpoint := "/api/v2/transaction/preview"
+	ok, err := c.PostJSONV2(endpoint, req, &r)
+	if ok {
+		return &r, err
+	}
+	return nil, err
+}
+
 // WalletUnconfirmedTransactions makes a request to GET /api/v1/wallet/transactions
 func (c *Client) WalletUnconfirmedTransactions(id string) (*UnconfirmedTxnsResponse, error) {
 	v := url.Values{}
@@ -864,6 +1031,59 @@ func (c *Client) WalletSeed(id string, password string) (string, error) {
 	return r.Seed, nil
 }
 
+// WalletSeedShares makes a request to POST /api/v2/wallet/seed/shares to split the seed of an encrypted wallet
+// into n share mnemonics, any threshold of which recover the seed
+func (c *Client) WalletSeedShares(id, password string, n, threshold int) ([]string, error) {
+	req := WalletSeedSharesRequest{
+		ID:        id,
+		Password:  password,
+		Shares:    n,
+		Threshold: threshold,
+	}
+
+	var rsp WalletSeedSharesResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/seed/shares", req, &rsp)
+	if ok {
+		return rsp.Shares, err
+	}
+
+	return nil, err
+}
+
+// WalletExport makes a request to POST /api/v2/wallet/export to export wallets to a password encrypted bundle.
+// cryptoType is optional.
+func (c *Client) WalletExport(ids []string, password, cryptoType string) (*WalletBundle, error) {
+	req := WalletExportRequest{
+		IDs:        ids,
+		Password:   password,
+		CryptoType: cryptoType,
+	}
+
+	var rsp WalletBundle
+	ok, err := c.PostJSONV2("/api/v2/wallet/export", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletImport makes a request to POST /api/v2/wallet/import to import the wallets of a bundle
+func (c *Client) WalletImport(bundle WalletBundle, password string) (*WalletImportResponse, error) {
+	req := WalletImportRequest{
+		Bundle:   bundle,
+		Password: password,
+	}
+
+	var rsp WalletImportResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/import", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // NetworkConnection makes a request to GET /api/v1/network/connection
 func (c *Client) NetworkConnection(addr string) (*readable.Connection, error) {
 	v := url.Values{}
@@ -1156,6 +1376,30 @@ func (c *Client) VerifyAddress(addr string) (*VerifyAddressResponse, error) {
 	return nil, err
 }
 
+// Events makes a request to GET /api/v2/events, which waits for the events of the addresses and wallets.
+// A timeout of 0 uses the node's default timeout.
+func (c *Client) Events(addrs, wallets []string, timeout time.Duration) (*EventsResponse, error) {
+	v := url.Values{}
+	if len(addrs) != 0 {
+		v.Add("addrs", strings.Join(addrs, ","))
+	}
+	if len(wallets) != 0 {
+		v.Add("wallets", strings.Join(wallets, ","))
+	}
+	if timeout != 0 {
+		v.Add("timeout", fmt.Sprint(int64(timeout/time.Second)))
+	}
+	endpoint := "/api/v2/events?" + v.Encode()
+
+	var rsp EventsResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // RichlistParams are arguments to the /richlist endpoint
 type RichlistParams struct {
 	N                   int
@@ -1211,9 +1455,36 @@ func (c *Client) Health() (*HealthResponse, error) {
 
 // EncryptWallet makes a request to POST /api/v1/wallet/encrypt to encrypt a specific wallet with the given password
 func (c *Client) EncryptWallet(id, password string) (*WalletResponse, error) {
+	return c.EncryptWalletCryptoType(id, password, "")
+}
+
+// EncryptWalletCryptoType makes a request to POST /api/v1/wallet/encrypt to encrypt a specific wallet with the given password
+// and crypto type. If cryptoType is empty, the node's wallet crypto type is used.
+func (c *Client) EncryptWalletCryptoType(id, password, cryptoType string) (*WalletResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("password", password)
+	if cryptoType != "" {
+		v.Add("crypto_type", cryptoType)
+	}
+	var wlt WalletResponse
+	if err := c.PostForm("/api/v1/wallet/encrypt", strings.NewReader(v.Encode()), &wlt); err != nil {
+		return nil, err
+	}
+
+	return &wlt, nil
+}
+
+// EncryptWalletArgon2id makes a request to POST /api/v1/wallet/encrypt to encrypt a specific wallet with the given password
+// and the argon2id-chacha20poly1305 crypto type, with the given argon2id parameters
+func (c *Client) EncryptWalletArgon2id(id, password string, argon2idTime, argon2idMemory uint32, argon2idParallelism uint8) (*WalletResponse, error) {
 	v := url.Values{}
 	v.Add("id", id)
 	v.Add("password", password)
+	v.Add("crypto_type", "argon2id-chacha20poly1305")
+	v.Add("argon2id_time", fmt.Sprint(argon2idTime))
+	v.Add("argon2id_memory", fmt.Sprint(argon2idMemory))
+	v.Add("argon2id_parallelism", fmt.Sprint(argon2idParallelism))
 	var wlt WalletResponse
 	if err := c.PostForm("/api/v1/wallet/encrypt", strings.NewReader(v.Encode()), &wlt); err != nil {
 		return nil, err
@@ -1239,14 +1510,326 @@ func (c *Client) DecryptWallet(id, password string) (*WalletResponse, error) {
 // The password argument is optional, if provided, the recovered wallet will be encrypted with this password,
 // otherwise the recovered wallet will be unencrypted.
 func (c *Client) RecoverWallet(id, seed, password string) (*WalletResponse, error) {
+	return c.RecoverWalletWithPassphrase(id, seed, "", password)
+}
+
+// RecoverWalletWithPassphrase makes a request to POST /api/v2/wallet/recover to recover an encrypted bip44 wallet
+// by seed and seed passphrase. The other arguments are the same as RecoverWallet.
+func (c *Client) RecoverWalletWithPassphrase(id, seed, seedPassphrase, password string) (*WalletResponse, error) {
+	rsp, err := c.RecoverWalletScan(id, seed, seedPassphrase, password, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsp.WalletResponse, nil
+}
+
+// RecoverWalletScan makes a request to POST /api/v2/wallet/recover to recover a wallet by seed,
+// discovering the addresses with transaction history until gapLimit consecutive addresses have none.
+// Unencrypted wallets can be recovered if gapLimit is not 0.
+// The other arguments are the same as RecoverWalletWithPassphrase.
+// The result of the scan of each address chain is returned with the wallet.
+func (c *Client) RecoverWalletScan(id, seed, seedPassphrase, password string, gapLimit uint64) (*WalletRecoverResponse, error) {
+	req := WalletRecoverRequest{
+		ID:             id,
+		Seed:           seed,
+		SeedPassphrase: seedPassphrase,
+		Password:       password,
+		GapLimit:       gapLimit,
+	}
+
+	var rsp WalletRecoverResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/recover", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// RecoverWalletFromShares makes a request to POST /api/v2/wallet/recover to recover a wallet
+// from seed share mnemonics, created by WalletSeedShares.
+// The other arguments are the same as RecoverWalletScan.
+func (c *Client) RecoverWalletFromShares(id string, seedShares []string, seedPassphrase, password string, gapLimit uint64) (*WalletRecoverResponse, error) {
 	req := WalletRecoverRequest{
+		ID:             id,
+		SeedShares:     seedShares,
+		SeedPassphrase: seedPassphrase,
+		Password:       password,
+		GapLimit:       gapLimit,
+	}
+
+	var rsp WalletRecoverResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/recover", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// ChangeWalletPassword makes a request to POST /api/v2/wallet/password to change the password of an encrypted wallet.
+// The cryptoType argument is optional, if provided, the wallet will be re-encrypted with this crypto type,
+// otherwise the wallet's current crypto type is kept.
+func (c *Client) ChangeWalletPassword(id, oldPassword, newPassword, cryptoType string) (*WalletResponse, error) {
+	req := WalletChangePasswordRequest{
+		ID:          id,
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+		CryptoType:  cryptoType,
+	}
+
+	var rsp WalletResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/password", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// UpdateWalletAddress makes a request to POST /api/v2/wallet/address/update to update the metadata of a wallet address.
+// Nil arguments are left unchanged.
+func (c *Client) UpdateWalletAddress(id, addr string, label, purpose *string, tags *[]string) (*WalletResponse, error) {
+	req := WalletUpdateAddressRequest{
+		ID:      id,
+		Address: addr,
+		Label:   label,
+		Purpose: purpose,
+		Tags:    tags,
+	}
+
+	var rsp WalletResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/address/update", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletFrozenOutputs makes a request to GET /api/v2/wallet/outputs/frozen
+func (c *Client) WalletFrozenOutputs(id string) (*WalletFrozenOutputsResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	endpoint := "/api/v2/wallet/outputs/frozen?" + v.Encode()
+
+	var rsp WalletFrozenOutputsResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// SetWalletFreshChange makes a request to POST /api/v2/wallet/options to set whether the wallet
+// sends the change of each transaction to a new change address
+func (c *Client) SetWalletFreshChange(id string, freshChange bool) (*WalletResponse, error) {
+	req := WalletOptionsRequest{
+		ID:          id,
+		FreshChange: &freshChange,
+	}
+
+	var rsp WalletResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/options", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// SetWalletSignerSocket makes a request to POST /api/v2/wallet/options to set the unix socket
+// of the external signer which signs the wallet's transactions. An empty socketPath removes it.
+func (c *Client) SetWalletSignerSocket(id, socketPath string) (*WalletResponse, error) {
+	req := WalletOptionsRequest{
+		ID:           id,
+		SignerSocket: &socketPath,
+	}
+
+	var rsp WalletResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/options", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletSpendingPolicy makes a request to GET /api/v2/wallet/policy
+func (c *Client) WalletSpendingPolicy(id string) (*WalletSpendingPolicyResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	endpoint := "/api/v2/wallet/policy?" + v.Encode()
+
+	var rsp WalletSpendingPolicyResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// UpdateWalletSpendingPolicy makes a request to POST /api/v2/wallet/policy/update
+func (c *Client) UpdateWalletSpendingPolicy(req WalletUpdateSpendingPolicyRequest) (*WalletSpendingPolicyResponse, error) {
+	var rsp WalletSpendingPolicyResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/policy/update", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// ApproveWalletTransaction makes a request to POST /api/v2/wallet/policy/approve.
+// Returns the inner hash of the approved transaction.
+func (c *Client) ApproveWalletTransaction(req WalletApproveTransactionRequest) (string, error) {
+	var rsp string
+	ok, err := c.PostJSONV2("/api/v2/wallet/policy/approve", req, &rsp)
+	if ok {
+		return rsp, err
+	}
+
+	return "", err
+}
+
+// FreezeWalletOutputs makes a request to POST /api/v2/wallet/outputs/freeze.
+// Frozen outputs are not spent when creating transactions, unless they are explicitly selected.
+func (c *Client) FreezeWalletOutputs(id string, uxOuts []string) (*WalletFrozenOutputsResponse, error) {
+	return c.updateWalletFrozenOutputs("/api/v2/wallet/outputs/freeze", id, uxOuts)
+}
+
+// UnfreezeWalletOutputs makes a request to POST /api/v2/wallet/outputs/unfreeze
+func (c *Client) UnfreezeWalletOutputs(id string, uxOuts []string) (*WalletFrozenOutputsResponse, error) {
+	return c.updateWalletFrozenOutputs("/api/v2/wallet/outputs/unfreeze", id, uxOuts)
+}
+
+func (c *Client) updateWalletFrozenOutputs(endpoint, id string, uxOuts []string) (*WalletFrozenOutputsResponse, error) {
+	req := WalletFreezeOutputsRequest{
+		ID:     id,
+		UxOuts: uxOuts,
+	}
+
+	var rsp WalletFrozenOutputsResponse
+	ok, err := c.PostJSONV2(endpoint, req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// ImportWalletKeys makes a request to POST /api/v2/wallet/keys/import to import secret keys into a collection wallet.
+// The keys can be hex encoded or in the Bitcoin wallet import format (WIF).
+// The password argument must be provided if the wallet is encrypted.
+func (c *Client) ImportWalletKeys(id string, keys []string, password string) (*WalletResponse, error) {
+	req := WalletImportKeysRequest{
 		ID:       id,
-		Seed:     seed,
+		Keys:     keys,
 		Password: password,
 	}
 
 	var rsp WalletResponse
-	ok, err := c.PostJSONV2("/api/v2/wallet/recover", req, &rsp)
+	ok, err := c.PostJSONV2("/api/v2/wallet/keys/import", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// RemoveWalletKeys makes a request to POST /api/v2/wallet/keys/remove to remove the keys of the given addresses
+// from a collection wallet.
+// The password argument must be provided if the wallet is encrypted.
+func (c *Client) RemoveWalletKeys(id string, addrs []string, password string) (*WalletResponse, error) {
+	req := WalletRemoveKeysRequest{
+		ID:        id,
+		Addresses: addrs,
+		Password:  password,
+	}
+
+	var rsp WalletResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/keys/remove", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletSweep makes a request to POST /api/v2/wallet/sweep to move all coins and coin hours
+// owned by secret keys, or by the addresses of a seed, to a new address of a wallet.
+// The transaction is signed with the swept keys, injected and broadcast.
+func (c *Client) WalletSweep(req WalletSweepRequest) (*CreateTransactionResponse, error) {
+	var rsp CreateTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/sweep", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletConsolidate makes a request to POST /api/v2/wallet/consolidate to send the smallest
+// unspent outputs of a wallet to a single output of the wallet.
+// With req.Preview, the unsigned transactions are returned without being broadcast.
+func (c *Client) WalletConsolidate(req WalletConsolidateRequest) (*WalletConsolidateResponse, error) {
+	var rsp WalletConsolidateResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/consolidate", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletPayouts makes a request to GET /api/v2/wallet/payouts.
+// If statuses are specified, only the payouts with one of these statuses are returned.
+func (c *Client) WalletPayouts(id string, statuses []string) (*WalletPayoutsResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	if len(statuses) != 0 {
+		v.Add("status", strings.Join(statuses, ","))
+	}
+	endpoint := "/api/v2/wallet/payouts?" + v.Encode()
+
+	var rsp WalletPayoutsResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// AddWalletPayouts makes a request to POST /api/v2/wallet/payouts/add to queue payouts for a wallet.
+// The node periodically sends the pending payouts of the wallet in transactions with many outputs.
+func (c *Client) AddWalletPayouts(id string, payouts []PayoutRequest) (*WalletPayoutsResponse, error) {
+	req := WalletPayoutsAddRequest{
+		ID:      id,
+		Payouts: payouts,
+	}
+
+	var rsp WalletPayoutsResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/payouts/add", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// CancelWalletPayouts makes a request to POST /api/v2/wallet/payouts/cancel to cancel pending payouts of a wallet
+func (c *Client) CancelWalletPayouts(id string, ids []uint64) (*WalletPayoutsResponse, error) {
+	req := WalletPayoutsCancelRequest{
+		ID:  id,
+		IDs: ids,
+	}
+
+	var rsp WalletPayoutsResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/payouts/cancel", req, &rsp)
 	if ok {
 		return &rsp, err
 	}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// maxConsolidateTransactions is the maximum number of transactions created by a consolidation
+const maxConsolidateTransactions = 10
+
+// WalletConsolidateRequest is the request data for POST /api/v2/wallet/consolidate
+type WalletConsolidateRequest struct {
+	ID              string `json:"id"`
+	Password        string `json:"password,omitempty"`
+	Address         string `json:"address,omitempty"`
+	MaxInputs       int    `json:"max_inputs,omitempty"`
+	MaxTransactions int    `json:"max_transactions,omitempty"`
+	Preview         bool   `json:"preview"`
+	// ExpectedInnerHashes are the inner hashes of the previewed transactions.
+	// Inner hashes don't include signatures, so they are the same for the signed transactions
+	ExpectedInnerHashes []string `json:"expected_inner_hashes,omitempty"`
+	// ExpectedFee is the previewed total coin hours burned
+	ExpectedFee string `json:"expected_fee,omitempty"`
+}
+
+// WalletConsolidateResponse is returned by POST /api/v2/wallet/consolidate
+type WalletConsolidateResponse struct {
+	Transactions []CreateTransactionResponse `json:"transactions"`
+	Inputs       int                         `json:"inputs"`
+	Fee          string                      `json:"fee"`
+	Broadcast    bool                        `json:"broadcast"`
+}
+
+// NewWalletConsolidateResponse creates a WalletConsolidateResponse
+func NewWalletConsolidateResponse(txns []coin.Transaction, inputs [][]visor.TransactionInput, broadcast bool) (*WalletConsolidateResponse, error) {
+	rsp := &WalletConsolidateResponse{
+		Transactions: make([]CreateTransactionResponse, len(txns)),
+		Broadcast:    broadcast,
+	}
+
+	for i := range txns {
+		txnRsp, err := NewCreateTransactionResponse(&txns[i], inputs[i])
+		if err != nil {
+			return nil, err
+		}
+		rsp.Transactions[i] = *txnRsp
+
+		rsp.Inputs += len(txns[i].In)
+	}
+
+	totalFee, err := transactionsFee(txns, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp.Fee = fmt.Sprint(totalFee)
+
+	return rsp, nil
+}
+
+// transactionsFee returns the total coin hours burned by the transactions
+func transactionsFee(txns []coin.Transaction, inputs [][]visor.TransactionInput) (uint64, error) {
+	var totalFee uint64
+	for i := range txns {
+		var inputHours uint64
+		for _, in := range inputs[i] {
+			var err error
+			inputHours, err = mathutil.AddUint64(inputHours, in.CalculatedHours)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		outputHours, err := txns[i].OutputHours()
+		if err != nil {
+			return 0, err
+		}
+
+		totalFee, err = mathutil.AddUint64(totalFee, inputHours-outputHours)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return totalFee, nil
+}
+
+// walletConsolidateHandler sends the smallest unspent outputs of a wallet to a single output of the wallet,
+// in one or more transactions no larger than the maximum transaction size.
+// With preview, the unsigned transactions are returned so that their coin hour fee can be checked.
+// Otherwise the transactions are signed, injected and broadcast.
+// URI: /api/v2/wallet/consolidate
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	password: wallet password, must be provided if the wallet is encrypted and preview is false [optional]
+//	address: wallet address which receives the outputs, defaults to the first address of the wallet [optional]
+//	max_inputs: maximum number of inputs of a transaction, defaults to as many as fit in a transaction [optional]
+//	max_transactions: maximum number of transactions to create, defaults to 1, at most 10 [optional]
+//	preview: return the unsigned transactions without signing and broadcasting them [optional]
+//	expected_inner_hashes: inner hashes of the previewed transactions, which must match the transactions to broadcast [optional]
+//	expected_fee: previewed total coin hours burned, which must match the fee of the transactions to broadcast [optional]
+func walletConsolidateHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletConsolidateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.Password = ""
+		}()
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Preview && req.Password != "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password must not be used with preview")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Preview && (len(req.ExpectedInnerHashes) != 0 || req.ExpectedFee != "") {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "expected_inner_hashes and expected_fee must not be used with preview")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		expectedInnerHashes := make([]cipher.SHA256, len(req.ExpectedInnerHashes))
+		for i, h := range req.ExpectedInnerHashes {
+			var err error
+			expectedInnerHashes[i], err = cipher.SHA256FromHex(h)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid expected_inner_hashes[%d]: %v", i, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		var expectedFee uint64
+		if req.ExpectedFee != "" {
+			var err error
+			expectedFee, err = strconv.ParseUint(req.ExpectedFee, 10, 64)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, "invalid expected_fee value")
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		if req.MaxInputs < 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "max_inputs must not be negative")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.MaxTransactions < 0 || req.MaxTransactions > maxConsolidateTransactions {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("max_transactions must be between 1 and %d", maxConsolidateTransactions))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		p := wallet.ConsolidateParams{
+			MaxInputs:       req.MaxInputs,
+			MaxTransactions: req.MaxTransactions,
+		}
+
+		if p.MaxTransactions == 0 {
+			p.MaxTransactions = 1
+		}
+
+		if req.Address != "" {
+			addr, err := cipher.DecodeBase58Address(req.Address)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address: %v", err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			p.Address = addr
+		}
+
+		signed := visor.TxnSigned
+		if req.Preview {
+			signed = visor.TxnUnsigned
+		}
+
+		txns, inputs, err := gateway.WalletCreateConsolidateTransactions(req.ID, []byte(req.Password), p, signed)
+		if err != nil {
+			writeHTTPResponse(w, walletConsolidateErrorResponse(err))
+			return
+		}
+
+		if !req.Preview {
+			if len(req.ExpectedInnerHashes) != 0 {
+				if err := checkConsolidateInnerHashes(txns, expectedInnerHashes); err != nil {
+					resp := NewHTTPErrorResponse(http.StatusConflict, err.Error())
+					writeHTTPResponse(w, resp)
+					return
+				}
+			}
+
+			if req.ExpectedFee != "" {
+				fee, err := transactionsFee(txns, inputs)
+				if err != nil {
+					resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+					writeHTTPResponse(w, resp)
+					return
+				}
+
+				if fee != expectedFee {
+					resp := NewHTTPErrorResponse(http.StatusConflict, fmt.Sprintf("consolidation fee %d does not match expected_fee %d", fee, expectedFee))
+					writeHTTPResponse(w, resp)
+					return
+				}
+			}
+
+			for i, txn := range txns {
+				if err := gateway.InjectBroadcastTransaction(txn); err != nil {
+					resp := walletConsolidateErrorResponse(err)
+					if i > 0 {
+						resp.Error.Message = fmt.Sprintf("%d of %d transactions were broadcast: %s", i, len(txns), resp.Error.Message)
+					}
+					writeHTTPResponse(w, resp)
+					return
+				}
+			}
+		}
+
+		rsp, err := NewWalletConsolidateResponse(txns, inputs, !req.Preview)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rsp,
+		})
+	}
+}
+
+// checkConsolidateInnerHashes returns an error if the transactions don't have the expected inner hashes, in order
+func checkConsolidateInnerHashes(txns []coin.Transaction, expected []cipher.SHA256) error {
+	if len(txns) != len(expected) {
+		return fmt.Errorf("consolidation has %d transactions, expected %d", len(txns), len(expected))
+	}
+
+	for i := range txns {
+		if txns[i].InnerHash != expected[i] {
+			return fmt.Errorf("inner hash %s of transaction %d does not match expected_inner_hashes", txns[i].InnerHash.Hex(), i)
+		}
+	}
+
+	return nil
+}
+
+func walletConsolidateErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.SpendingPolicyError:
+		return NewHTTPErrorResponse(http.StatusForbidden, err.Error())
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, "")
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	case transaction.Error,
+		visor.UserError,
+		visor.ErrTxnViolatesUserConstraint,
+		visor.ErrTxnViolatesHardConstraint,
+		visor.ErrTxnViolatesSoftConstraint:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		if daemon.IsBroadcastFailure(err) {
+			return NewHTTPErrorResponse(http.StatusServiceUnavailable, err.Error())
+		}
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletConsolidate(t *testing.T) {
+	makeConsolidateTxn := func() (coin.Transaction, []visor.TransactionInput) {
+		var txn coin.Transaction
+		var inputs []visor.TransactionInput
+		var keys []cipher.SecKey
+		var coins, hours uint64
+		for i := 0; i < 2; i++ {
+			ux, uxKey := makeUxOutWithSecret(t)
+			err := txn.PushInput(ux.Hash())
+			require.NoError(t, err)
+			keys = append(keys, uxKey)
+			coins += ux.Body.Coins
+			hours += ux.Body.Hours
+
+			input, err := visor.NewTransactionInput(ux, ux.Head.Time)
+			require.NoError(t, err)
+			inputs = append(inputs, input)
+		}
+		err := txn.PushOutput(makeAddress(), coins, hours/2)
+		require.NoError(t, err)
+		txn.SignInputs(keys)
+		err = txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn, inputs
+	}
+
+	txn0, inputs0 := makeConsolidateTxn()
+	txn1, inputs1 := makeConsolidateTxn()
+	txns := []coin.Transaction{txn0, txn1}
+	inputs := [][]visor.TransactionInput{inputs0, inputs1}
+
+	addr := makeAddress()
+
+	rsp, err := NewWalletConsolidateResponse(txns, inputs, true)
+	require.NoError(t, err)
+	previewRsp, err := NewWalletConsolidateResponse(txns, inputs, false)
+	require.NoError(t, err)
+
+	type gatewayReturnPair struct {
+		txns       []coin.Transaction
+		inputs     [][]visor.TransactionInput
+		err        error
+		injectErrs []error
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           WalletConsolidateRequest
+		params        wallet.ConsolidateParams
+		signed        visor.TxnSignedFlag
+		gatewayReturn *gatewayReturnPair
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "password with preview",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{ID: "foo.wlt", Password: "pwd", Preview: true},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "password must not be used with preview"),
+		},
+		{
+			name:         "expected fee with preview",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{ID: "foo.wlt", ExpectedFee: "1", Preview: true},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "expected_inner_hashes and expected_fee must not be used with preview"),
+		},
+		{
+			name:         "invalid expected inner hash",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{ID: "foo.wlt", ExpectedInnerHashes: []string{"abcd"}},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid expected_inner_hashes[0]: Invalid hex length"),
+		},
+		{
+			name:         "invalid expected fee",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{ID: "foo.wlt", ExpectedFee: "foo"},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid expected_fee value"),
+		},
+		{
+			name:         "negative max inputs",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{ID: "foo.wlt", MaxInputs: -1},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "max_inputs must not be negative"),
+		},
+		{
+			name:         "too many transactions",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{ID: "foo.wlt", MaxTransactions: maxConsolidateTransactions + 1},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "max_transactions must be between 1 and 10"),
+		},
+		{
+			name:         "invalid address",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletConsolidateRequest{ID: "foo.wlt", Address: "foo"},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:        "wallet does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt"},
+			params:      wallet.ConsolidateParams{MaxTransactions: 1},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:        "nothing to consolidate",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt"},
+			params:      wallet.ConsolidateParams{MaxTransactions: 1},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrNothingToConsolidate,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrNothingToConsolidate.Error()),
+		},
+		{
+			name:        "spending policy",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt"},
+			params:      wallet.ConsolidateParams{MaxTransactions: 1},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrSpendingPolicyMaxCoinsExceeded,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, wallet.ErrSpendingPolicyMaxCoinsExceeded.Error()),
+		},
+		{
+			name:        "other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt"},
+			params:      wallet.ConsolidateParams{MaxTransactions: 1},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				err: errors.New("db error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "db error"),
+		},
+		{
+			name:        "broadcast failure",
+			method:      http.MethodPost,
+			status:      http.StatusServiceUnavailable,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt"},
+			params:      wallet.ConsolidateParams{MaxTransactions: 1},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:       txns,
+				inputs:     inputs,
+				injectErrs: []error{daemon.ErrNetworkingDisabled},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusServiceUnavailable, daemon.ErrNetworkingDisabled.Error()),
+		},
+		{
+			name:        "broadcast failure after first transaction",
+			method:      http.MethodPost,
+			status:      http.StatusServiceUnavailable,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt", MaxTransactions: 2},
+			params:      wallet.ConsolidateParams{MaxTransactions: 2},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:       txns,
+				inputs:     inputs,
+				injectErrs: []error{nil, daemon.ErrNetworkingDisabled},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusServiceUnavailable, "1 of 2 transactions were broadcast: "+daemon.ErrNetworkingDisabled.Error()),
+		},
+		{
+			name:        "expected inner hashes mismatch",
+			method:      http.MethodPost,
+			status:      http.StatusConflict,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt", MaxTransactions: 2, ExpectedInnerHashes: []string{txn1.InnerHash.Hex(), txn0.InnerHash.Hex()}},
+			params:      wallet.ConsolidateParams{MaxTransactions: 2},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:   txns,
+				inputs: inputs,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusConflict, fmt.Sprintf("inner hash %s of transaction 0 does not match expected_inner_hashes", txn0.InnerHash.Hex())),
+		},
+		{
+			name:        "expected inner hashes count mismatch",
+			method:      http.MethodPost,
+			status:      http.StatusConflict,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt", MaxTransactions: 2, ExpectedInnerHashes: []string{txn0.InnerHash.Hex()}},
+			params:      wallet.ConsolidateParams{MaxTransactions: 2},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:   txns,
+				inputs: inputs,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusConflict, "consolidation has 2 transactions, expected 1"),
+		},
+		{
+			name:        "expected fee mismatch",
+			method:      http.MethodPost,
+			status:      http.StatusConflict,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt", MaxTransactions: 2, ExpectedFee: "1"},
+			params:      wallet.ConsolidateParams{MaxTransactions: 2},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:   txns,
+				inputs: inputs,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusConflict, fmt.Sprintf("consolidation fee %s does not match expected_fee 1", rsp.Fee)),
+		},
+		{
+			name:        "ok preview",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt", Address: addr.String(), MaxInputs: 2, MaxTransactions: 2, Preview: true},
+			params:      wallet.ConsolidateParams{Address: addr, MaxInputs: 2, MaxTransactions: 2},
+			signed:      visor.TxnUnsigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:   txns,
+				inputs: inputs,
+			},
+			httpResponse: HTTPResponse{
+				Data: previewRsp,
+			},
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req:         WalletConsolidateRequest{ID: "foo.wlt", Password: "pwd", MaxTransactions: 2},
+			params:      wallet.ConsolidateParams{MaxTransactions: 2},
+			signed:      visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:       txns,
+				inputs:     inputs,
+				injectErrs: []error{nil, nil},
+			},
+			httpResponse: HTTPResponse{
+				Data: rsp,
+			},
+		},
+		{
+			name:        "ok expected inner hashes and fee",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: WalletConsolidateRequest{
+				ID:                  "foo.wlt",
+				MaxTransactions:     2,
+				ExpectedInnerHashes: []string{txn0.InnerHash.Hex(), txn1.InnerHash.Hex()},
+				ExpectedFee:         rsp.Fee,
+			},
+			params: wallet.ConsolidateParams{MaxTransactions: 2},
+			signed: visor.TxnSigned,
+			gatewayReturn: &gatewayReturnPair{
+				txns:       txns,
+				inputs:     inputs,
+				injectErrs: []error{nil, nil},
+			},
+			httpResponse: HTTPResponse{
+				Data: rsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayReturn != nil {
+				gateway.On("WalletCreateConsolidateTransactions", tc.req.ID, []byte(tc.req.Password), tc.params, tc.signed).Return(tc.gatewayReturn.txns, tc.gatewayReturn.inputs, tc.gatewayReturn.err)
+				for i, err := range tc.gatewayReturn.injectErrs {
+					gateway.On("InjectBroadcastTransaction", tc.gatewayReturn.txns[i]).Return(err)
+				}
+			}
+
+			httpBody := toJSON(t, tc.req)
+			endpoint := "/api/v2/wallet/consolidate"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var consolidateRsp WalletConsolidateResponse
+				err := json.Unmarshal(rsp.Data, &consolidateRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, *tc.httpResponse.Data.(*WalletConsolidateResponse), consolidateRsp)
+			}
+
+			if tc.req.Preview || tc.status == http.StatusConflict {
+				gateway.AssertNotCalled(t, "InjectBroadcastTransaction")
+			}
+		})
+	}
+}
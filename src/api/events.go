@@ -0,0 +1,191 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const (
+	// defaultEventsTimeout is the time GET /api/v2/events waits for an event by default
+	defaultEventsTimeout = 30 * time.Second
+	// maxEventsTimeout is the longest time GET /api/v2/events waits for an event,
+	// which must be shorter than the server's write timeout
+	maxEventsTimeout = 50 * time.Second
+)
+
+// EventResponse is an event returned by GET /api/v2/events
+type EventResponse struct {
+	Type    string   `json:"type"`
+	Time    int64    `json:"time"`
+	Address string   `json:"address"`
+	Wallets []string `json:"wallets,omitempty"`
+	Txid    string   `json:"txid"`
+	UxID    string   `json:"uxid"`
+	Coins   string   `json:"coins"`
+	Hours   uint64   `json:"hours"`
+	BkSeq   uint64   `json:"block_seq"`
+}
+
+// NewEventResponse creates an EventResponse from a visor.Event
+func NewEventResponse(e visor.Event) (*EventResponse, error) {
+	coins, err := droplet.ToString(e.Coins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventResponse{
+		Type:    string(e.Type),
+		Time:    e.Time.Unix(),
+		Address: e.Address.String(),
+		Wallets: e.Wallets,
+		Txid:    e.Txid.Hex(),
+		UxID:    e.UxID.Hex(),
+		Coins:   coins,
+		Hours:   e.Hours,
+		BkSeq:   e.BkSeq,
+	}, nil
+}
+
+// EventsResponse is the response data for GET /api/v2/events
+type EventsResponse struct {
+	Events []EventResponse `json:"events"`
+	// Dropped is the number of events not returned because too many were published at once
+	Dropped uint64 `json:"dropped"`
+}
+
+// eventsHandler waits for the events of addresses and wallets, by long polling.
+// The request returns as soon as events are published, or with no events after the timeout.
+// Events published between two requests are not returned.
+// URI: /api/v2/events
+// Method: GET
+// Args:
+//	addrs: comma-separated addresses [optional]
+//	wallets: comma-separated wallet ids [optional]
+//	timeout: seconds to wait for events, default 30, max 50 [optional]
+// At least one address or wallet is required.
+func eventsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		addrs, err := parseAddressesFromStr(r.FormValue("addrs"))
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("parse parameter: 'addrs' failed: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wltIDs := splitCommaString(r.FormValue("wallets"))
+
+		if len(addrs) == 0 && len(wltIDs) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "addrs or wallets is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		timeout := defaultEventsTimeout
+		if s := r.FormValue("timeout"); s != "" {
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, "invalid timeout value")
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			timeout = time.Duration(n) * time.Second
+			if timeout > maxEventsTimeout {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("timeout must be <= %d", maxEventsTimeout/time.Second))
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		for _, id := range wltIDs {
+			if _, err := gateway.GetWallet(id); err != nil {
+				var resp HTTPResponse
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		sub := gateway.SubscribeEvents(visor.EventFilter{
+			Addresses: addrs,
+			Wallets:   wltIDs,
+		}, 0)
+		defer sub.Close()
+
+		events := waitForEvents(r, sub, timeout)
+		if events == nil && r.Context().Err() != nil {
+			// The client went away
+			return
+		}
+
+		rsp := EventsResponse{
+			Events:  make([]EventResponse, 0, len(events)),
+			Dropped: sub.Dropped(),
+		}
+		for _, e := range events {
+			er, err := NewEventResponse(e)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				writeHTTPResponse(w, resp)
+				return
+			}
+			rsp.Events = append(rsp.Events, *er)
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rsp,
+		})
+	}
+}
+
+// waitForEvents waits for the first event of the subscription, then collects the events
+// published with it. Returns nil after the timeout, or if the request is canceled.
+func waitForEvents(r *http.Request, sub *visor.Subscription, timeout time.Duration) []visor.Event {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var events []visor.Event
+	select {
+	case e, ok := <-sub.Events():
+		if !ok {
+			return nil
+		}
+		events = append(events, e)
+	case <-timer.C:
+		return nil
+	case <-r.Context().Done():
+		return nil
+	}
+
+	// The events of a database transaction are published at once, so they are already buffered
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestEvents(t *testing.T) {
+	addr := testutil.MakeAddress()
+	txid := testutil.RandSHA256(t)
+	uxID := testutil.RandSHA256(t)
+
+	events := []visor.Event{
+		{
+			Type:    visor.EventIncomingUnconfirmed,
+			Time:    time.Unix(1e9, 0),
+			Address: addr,
+			Wallets: []string{"foo.wlt"},
+			Txid:    txid,
+			UxID:    uxID,
+			Coins:   1e6,
+			Hours:   10,
+		},
+		{
+			Type:    visor.EventConfirmed,
+			Time:    time.Unix(1e9+10, 0),
+			Address: addr,
+			Txid:    txid,
+			UxID:    uxID,
+			Coins:   1e6,
+			Hours:   10,
+			BkSeq:   5,
+		},
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		query        string
+		status       int
+		httpResponse HTTPResponse
+		filter       *visor.EventFilter
+		wallets      []string
+		gatewayErr   error
+		events       []visor.Event
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "no addresses or wallets",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "addrs or wallets is required"),
+		},
+		{
+			name:         "invalid address",
+			method:       http.MethodGet,
+			query:        "addrs=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "parse parameter: 'addrs' failed: address \"foo\" is invalid: Invalid address length"),
+		},
+		{
+			name:         "invalid timeout",
+			method:       http.MethodGet,
+			query:        "addrs=" + addr.String() + "&timeout=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid timeout value"),
+		},
+		{
+			name:         "timeout too large",
+			method:       http.MethodGet,
+			query:        "addrs=" + addr.String() + "&timeout=51",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "timeout must be <= 50"),
+		},
+		{
+			name:         "wallet doesn't exist",
+			method:       http.MethodGet,
+			query:        "wallets=foo.wlt",
+			status:       http.StatusNotFound,
+			wallets:      []string{"foo.wlt"},
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "wallet api disabled",
+			method:       http.MethodGet,
+			query:        "wallets=foo.wlt",
+			status:       http.StatusForbidden,
+			wallets:      []string{"foo.wlt"},
+			gatewayErr:   wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:   "no events before the timeout",
+			method: http.MethodGet,
+			query:  "addrs=" + addr.String() + "&timeout=0",
+			status: http.StatusOK,
+			filter: &visor.EventFilter{
+				Addresses: []cipher.Address{addr},
+			},
+			httpResponse: HTTPResponse{
+				Data: EventsResponse{
+					Events: []EventResponse{},
+				},
+			},
+		},
+		{
+			name:    "ok",
+			method:  http.MethodGet,
+			query:   "addrs=" + addr.String() + "&wallets=foo.wlt,bar.wlt",
+			status:  http.StatusOK,
+			wallets: []string{"foo.wlt", "bar.wlt"},
+			filter: &visor.EventFilter{
+				Addresses: []cipher.Address{addr},
+				Wallets:   []string{"foo.wlt", "bar.wlt"},
+			},
+			events: events,
+			httpResponse: HTTPResponse{
+				Data: EventsResponse{
+					Events: []EventResponse{
+						{
+							Type:    "incoming_unconfirmed",
+							Time:    1e9,
+							Address: addr.String(),
+							Wallets: []string{"foo.wlt"},
+							Txid:    txid.Hex(),
+							UxID:    uxID.Hex(),
+							Coins:   "1.000000",
+							Hours:   10,
+						},
+						{
+							Type:    "confirmed",
+							Time:    1e9 + 10,
+							Address: addr.String(),
+							Txid:    txid.Hex(),
+							UxID:    uxID.Hex(),
+							Coins:   "1.000000",
+							Hours:   10,
+							BkSeq:   5,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			for _, id := range tc.wallets {
+				gateway.On("GetWallet", id).Return(nil, tc.gatewayErr)
+			}
+
+			var sub *visor.Subscription
+			if tc.filter != nil {
+				// The events are published before the request, and are buffered by the subscription
+				bus := visor.NewEventBus()
+				sub = bus.Subscribe(*tc.filter, 0)
+				bus.Publish(tc.events)
+				gateway.On("SubscribeEvents", *tc.filter, 0).Return(sub)
+			}
+
+			endpoint := "/api/v2/events"
+			if tc.query != "" {
+				endpoint += "?" + tc.query
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var eventsRsp EventsResponse
+				err := json.Unmarshal(rsp.Data, &eventsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(EventsResponse), eventsRsp)
+			}
+
+			// The subscription is closed after the request
+			if sub != nil {
+				_, ok := <-sub.Events()
+				require.False(t, ok)
+			}
+		})
+	}
+}
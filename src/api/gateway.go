@@ -59,6 +59,7 @@ type Visorer interface {
 	StartedAt() time.Time
 	HeadBkSeq() (uint64, bool, error)
 	GetBlockchainMetadata() (*visor.BlockchainMetadata, error)
+	SubscribeEvents(filter visor.EventFilter, bufferSize int) *visor.Subscription
 	ResendUnconfirmedTxns() ([]cipher.SHA256, error)
 	GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, error)
 	GetSignedBlockByHashVerbose(hash cipher.SHA256) (*coin.SignedBlock, [][]visor.TransactionInput, error)
@@ -72,6 +73,7 @@ type Visorer interface {
 	GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
 	GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error)
 	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
+	AddressesActivity(addrs []cipher.Address) ([]bool, error)
 	VerifyTxnVerbose(txn *coin.Transaction, signed visor.TxnSignedFlag) ([]visor.TransactionInput, bool, error)
 	AddressCount() (uint64, error)
 	GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error)
@@ -88,22 +90,47 @@ type Visorer interface {
 	GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
 	GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error)
 	CreateTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
-	WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
+	PreviewTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*visor.TransactionPreview, error)
+	WalletCreateTransaction(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletCreateTransactionSigned(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletSignTransaction(wltID string, password []byte, txn *coin.Transaction, signIndexes []int) (*coin.Transaction, []visor.TransactionInput, error)
+	CreatePartialTransaction(txn coin.Transaction) (*transaction.PartialTransaction, error)
+	EnqueuePayouts(wltID string, reqs []visor.PayoutRequest) ([]visor.Payout, error)
+	GetPayouts(wltID string, statuses []visor.PayoutStatus) ([]visor.Payout, error)
+	CancelPayouts(wltID string, ids []uint64) ([]visor.Payout, error)
+	CreateSweepTransaction(wltID string, password []byte, keys []cipher.SecKey) (*coin.Transaction, []visor.TransactionInput, error)
+	WalletCreateConsolidateTransactions(wltID string, password []byte, p wallet.ConsolidateParams, signed visor.TxnSignedFlag) ([]coin.Transaction, [][]visor.TransactionInput, error)
+	WalletCreateSplitTransactions(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) ([]coin.Transaction, [][]visor.TransactionInput, error)
 }
 
 // Walleter interface for wallet.Service methods used by the API
 type Walleter interface {
 	UnloadWallet(wltID string) error
-	EncryptWallet(wltID string, password []byte) (*wallet.Wallet, error)
+	EncryptWallet(wltID string, password []byte, cryptoType wallet.CryptoType, argon2idParams *wallet.Argon2idParams) (*wallet.Wallet, error)
 	DecryptWallet(wltID string, password []byte) (*wallet.Wallet, error)
 	GetWalletSeed(wltID string, password []byte) (string, error)
+	GetWalletSeedShares(wltID string, password []byte, n, threshold int) ([]string, error)
+	ExportWallets(wltIDs []string) (*wallet.Bundle, error)
+	ImportWallets(b *wallet.Bundle) ([]wallet.ImportedWallet, error)
 	CreateWallet(wltName string, options wallet.Options, bg wallet.BalanceGetter) (*wallet.Wallet, error)
-	RecoverWallet(wltID, seed string, password []byte) (*wallet.Wallet, error)
+	RecoverWalletScan(wltID, seed, seedPassphrase string, password []byte, gapLimit uint64, tf wallet.TransactionsFinder) (*wallet.Wallet, []wallet.ScanProgress, error)
+	RecoverWalletFromShares(wltID string, shares []string, seedPassphrase string, password []byte, gapLimit uint64, tf wallet.TransactionsFinder) (*wallet.Wallet, []wallet.ScanProgress, error)
+	ChangePassword(wltID string, oldPassword, newPassword []byte, cryptoType wallet.CryptoType) (*wallet.Wallet, error)
+	UpdateAddressMeta(wltID string, addr cipher.Addresser, u wallet.EntryMetaUpdate) (*wallet.Wallet, error)
+	SetFreshChange(wltID string, freshChange bool) (*wallet.Wallet, error)
+	SetSignerSocket(wltID, socketPath string) (*wallet.Wallet, error)
+	SpendingPolicy(wltID string) (*wallet.SpendingPolicy, error)
+	SetSpendingPolicy(wltID string, limits wallet.SpendingLimits, approvalPassword, newApprovalPassword []byte) (*wallet.SpendingPolicy, error)
+	ApproveTransaction(wltID string, txnInnerHash cipher.SHA256, approvalPassword []byte) error
+	FreezeUxOuts(wltID string, uxOuts []cipher.SHA256) (*wallet.Wallet, error)
+	UnfreezeUxOuts(wltID string, uxOuts []cipher.SHA256) (*wallet.Wallet, error)
 	NewAddresses(wltID string, password []byte, n uint64) ([]cipher.Address, error)
+	ImportKeys(wltID string, password []byte, keys []cipher.SecKey) (*wallet.Wallet, error)
+	RemoveKeys(wltID string, password []byte, addrs []cipher.Address) (*wallet.Wallet, error)
+	SignPartialTransaction(wltID string, password []byte, pt *transaction.PartialTransaction) (*transaction.PartialTransaction, error)
 	GetWallet(wltID string) (*wallet.Wallet, error)
 	GetWallets() (wallet.Wallets, error)
+	WalletDirEvents() ([]wallet.WalletDirEvent, error)
 	UpdateWalletLabel(wltID, label string) error
 	WalletDir() (string, error)
 }
@@ -471,6 +471,12 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/wallet/transaction/sign", walletSignTransactionHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
 	})
+	webHandlerV2("/wallet/transaction/split", walletSplitTransactionHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/transaction/partial/sign", walletSignPartialTransactionHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
 	webHandlerV1("/wallet/transactions", walletTransactionsHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsWallet},
 	})
@@ -489,9 +495,18 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV1("/wallet/seed", walletSeedHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsInsecureWalletSeed},
 	})
+	webHandlerV2("/wallet/seed/shares", walletSeedSharesHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsInsecureWalletSeed},
+	})
 	webHandlerV2("/wallet/seed/verify", http.HandlerFunc(walletVerifySeedHandler), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
 	})
+	webHandlerV2("/wallet/export", walletExportHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsInsecureWalletSeed},
+	})
+	webHandlerV2("/wallet/import", walletImportHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
 
 	webHandlerV1("/wallet/unload", walletUnloadHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
@@ -505,6 +520,54 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/wallet/recover", walletRecoverHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
 	})
+	webHandlerV2("/wallet/password", walletChangePasswordHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/address/update", walletUpdateAddressHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/options", walletOptionsHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/policy", walletSpendingPolicyHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/policy/update", walletUpdateSpendingPolicyHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/policy/approve", walletApproveTransactionHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/outputs/frozen", walletFrozenOutputsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/outputs/freeze", walletFreezeOutputsHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/outputs/unfreeze", walletUnfreezeOutputsHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/keys/import", walletImportKeysHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/keys/remove", walletRemoveKeysHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/sweep", walletSweepHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/consolidate", walletConsolidateHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/payouts", walletPayoutsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/payouts/add", walletPayoutsAddHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/payouts/cancel", walletPayoutsCancelHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
 
 	// Blockchain interface
 	webHandlerV1("/blockchain/metadata", blockchainMetadataHandler(gateway), map[string][]string{
@@ -557,9 +620,24 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		// http.MethodGet:  []string{EndpointsRead},
 		http.MethodPost: []string{EndpointsTransaction},
 	})
+	webHandlerV2("/transaction/preview", transactionPreviewHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
 	webHandlerV2("/transaction/verify", verifyTxnHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsRead},
 	})
+	webHandlerV2("/transaction/partial/create", createPartialTransactionHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
+	webHandlerV2("/transaction/partial/combine", http.HandlerFunc(combinePartialTransactionsHandler), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
+	webHandlerV2("/transaction/partial/inspect", http.HandlerFunc(inspectPartialTransactionHandler), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
+	webHandlerV2("/transaction/partial/finalize", http.HandlerFunc(finalizePartialTransactionHandler), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
 	webHandlerV1("/transactions", transactionsHandler(gateway), map[string][]string{
 		http.MethodGet:  []string{EndpointsRead},
 		http.MethodPost: []string{EndpointsRead},
@@ -595,6 +673,11 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		http.MethodGet: []string{EndpointsPrometheus},
 	})
 
+	// Event endpoints
+	webHandlerV2("/events", eventsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+
 	// Address related endpoints
 	webHandlerV2("/address/verify", http.HandlerFunc(addressVerifyHandler), map[string][]string{
 		http.MethodPost: []string{EndpointsRead},
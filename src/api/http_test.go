@@ -155,24 +155,105 @@ var endpointsMethods = map[string][]string{
 		http.MethodGet,
 	},
 
+	"/api/v2/transaction/preview": []string{
+		http.MethodPost,
+	},
 	"/api/v2/transaction/verify": []string{
 		http.MethodPost,
 	},
 	"/api/v2/address/verify": []string{
 		http.MethodPost,
 	},
+	"/api/v2/events": []string{
+		http.MethodGet,
+	},
 	"/api/v2/wallet/recover": []string{
 		http.MethodPost,
 	},
+	"/api/v2/wallet/password": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/address/update": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/options": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/policy": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/policy/update": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/policy/approve": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/outputs/frozen": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/outputs/freeze": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/outputs/unfreeze": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/keys/import": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/keys/remove": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/payouts": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/payouts/add": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/payouts/cancel": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/sweep": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/consolidate": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/seed/shares": []string{
+		http.MethodPost,
+	},
 	"/api/v2/wallet/seed/verify": []string{
 		http.MethodPost,
 	},
+	"/api/v2/wallet/export": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/import": []string{
+		http.MethodPost,
+	},
 	"/api/v2/wallet/transaction/sign": []string{
 		http.MethodPost,
 	},
+	"/api/v2/wallet/transaction/split": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/transaction/partial/sign": []string{
+		http.MethodPost,
+	},
 	"/api/v2/transaction": []string{
 		http.MethodPost,
 	},
+	"/api/v2/transaction/partial/create": []string{
+		http.MethodPost,
+	},
+	"/api/v2/transaction/partial/combine": []string{
+		http.MethodPost,
+	},
+	"/api/v2/transaction/partial/inspect": []string{
+		http.MethodPost,
+	},
+	"/api/v2/transaction/partial/finalize": []string{
+		http.MethodPost,
+	},
 
 	"/api/v2/data": []string{
 		http.MethodGet,
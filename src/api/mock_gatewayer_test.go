@@ -53,6 +53,144 @@ func (_m *MockGatewayer) AddressCount() (uint64, error) {
 	return r0, r1
 }
 
+// AddressesActivity provides a mock function with given fields: addrs
+func (_m *MockGatewayer) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	ret := _m.Called(addrs)
+
+	var r0 []bool
+	if rf, ok := ret.Get(0).(func([]cipher.Address) []bool); ok {
+		r0 = rf(addrs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bool)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]cipher.Address) error); ok {
+		r1 = rf(addrs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApproveTransaction provides a mock function with given fields: wltID, txnInnerHash, approvalPassword
+func (_m *MockGatewayer) ApproveTransaction(wltID string, txnInnerHash cipher.SHA256, approvalPassword []byte) error {
+	ret := _m.Called(wltID, txnInnerHash, approvalPassword)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, cipher.SHA256, []byte) error); ok {
+		r0 = rf(wltID, txnInnerHash, approvalPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CancelPayouts provides a mock function with given fields: wltID, ids
+func (_m *MockGatewayer) CancelPayouts(wltID string, ids []uint64) ([]visor.Payout, error) {
+	ret := _m.Called(wltID, ids)
+
+	var r0 []visor.Payout
+	if rf, ok := ret.Get(0).(func(string, []uint64) []visor.Payout); ok {
+		r0 = rf(wltID, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.Payout)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []uint64) error); ok {
+		r1 = rf(wltID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangePassword provides a mock function with given fields: wltID, oldPassword, newPassword, cryptoType
+func (_m *MockGatewayer) ChangePassword(wltID string, oldPassword []byte, newPassword []byte, cryptoType wallet.CryptoType) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, oldPassword, newPassword, cryptoType)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, []byte, []byte, wallet.CryptoType) *wallet.Wallet); ok {
+		r0 = rf(wltID, oldPassword, newPassword, cryptoType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, []byte, wallet.CryptoType) error); ok {
+		r1 = rf(wltID, oldPassword, newPassword, cryptoType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePartialTransaction provides a mock function with given fields: txn
+func (_m *MockGatewayer) CreatePartialTransaction(txn coin.Transaction) (*transaction.PartialTransaction, error) {
+	ret := _m.Called(txn)
+
+	var r0 *transaction.PartialTransaction
+	if rf, ok := ret.Get(0).(func(coin.Transaction) *transaction.PartialTransaction); ok {
+		r0 = rf(txn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*transaction.PartialTransaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(coin.Transaction) error); ok {
+		r1 = rf(txn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSweepTransaction provides a mock function with given fields: wltID, password, keys
+func (_m *MockGatewayer) CreateSweepTransaction(wltID string, password []byte, keys []cipher.SecKey) (*coin.Transaction, []visor.TransactionInput, error) {
+	ret := _m.Called(wltID, password, keys)
+
+	var r0 *coin.Transaction
+	if rf, ok := ret.Get(0).(func(string, []byte, []cipher.SecKey) *coin.Transaction); ok {
+		r0 = rf(wltID, password, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coin.Transaction)
+		}
+	}
+
+	var r1 []visor.TransactionInput
+	if rf, ok := ret.Get(1).(func(string, []byte, []cipher.SecKey) []visor.TransactionInput); ok {
+		r1 = rf(wltID, password, keys)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]visor.TransactionInput)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, []byte, []cipher.SecKey) error); ok {
+		r2 = rf(wltID, password, keys)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // CreateTransaction provides a mock function with given fields: p, wp
 func (_m *MockGatewayer) CreateTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(p, wp)
@@ -159,13 +297,13 @@ func (_m *MockGatewayer) DisconnectByGnetID(gnetID uint64) error {
 	return r0
 }
 
-// EncryptWallet provides a mock function with given fields: wltID, password
-func (_m *MockGatewayer) EncryptWallet(wltID string, password []byte) (*wallet.Wallet, error) {
-	ret := _m.Called(wltID, password)
+// EncryptWallet provides a mock function with given fields: wltID, password, cryptoType, argon2idParams
+func (_m *MockGatewayer) EncryptWallet(wltID string, password []byte, cryptoType wallet.CryptoType, argon2idParams *wallet.Argon2idParams) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, password, cryptoType, argon2idParams)
 
 	var r0 *wallet.Wallet
-	if rf, ok := ret.Get(0).(func(string, []byte) *wallet.Wallet); ok {
-		r0 = rf(wltID, password)
+	if rf, ok := ret.Get(0).(func(string, []byte, wallet.CryptoType, *wallet.Argon2idParams) *wallet.Wallet); ok {
+		r0 = rf(wltID, password, cryptoType, argon2idParams)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*wallet.Wallet)
@@ -173,8 +311,77 @@ func (_m *MockGatewayer) EncryptWallet(wltID string, password []byte) (*wallet.W
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, []byte) error); ok {
-		r1 = rf(wltID, password)
+	if rf, ok := ret.Get(1).(func(string, []byte, wallet.CryptoType, *wallet.Argon2idParams) error); ok {
+		r1 = rf(wltID, password, cryptoType, argon2idParams)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnqueuePayouts provides a mock function with given fields: wltID, reqs
+func (_m *MockGatewayer) EnqueuePayouts(wltID string, reqs []visor.PayoutRequest) ([]visor.Payout, error) {
+	ret := _m.Called(wltID, reqs)
+
+	var r0 []visor.Payout
+	if rf, ok := ret.Get(0).(func(string, []visor.PayoutRequest) []visor.Payout); ok {
+		r0 = rf(wltID, reqs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.Payout)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []visor.PayoutRequest) error); ok {
+		r1 = rf(wltID, reqs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportWallets provides a mock function with given fields: wltIDs
+func (_m *MockGatewayer) ExportWallets(wltIDs []string) (*wallet.Bundle, error) {
+	ret := _m.Called(wltIDs)
+
+	var r0 *wallet.Bundle
+	if rf, ok := ret.Get(0).(func([]string) *wallet.Bundle); ok {
+		r0 = rf(wltIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Bundle)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(wltIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FreezeUxOuts provides a mock function with given fields: wltID, uxOuts
+func (_m *MockGatewayer) FreezeUxOuts(wltID string, uxOuts []cipher.SHA256) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, uxOuts)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, []cipher.SHA256) *wallet.Wallet); ok {
+		r0 = rf(wltID, uxOuts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []cipher.SHA256) error); ok {
+		r1 = rf(wltID, uxOuts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -565,6 +772,29 @@ func (_m *MockGatewayer) GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [
 	return r0, r1, r2
 }
 
+// GetPayouts provides a mock function with given fields: wltID, statuses
+func (_m *MockGatewayer) GetPayouts(wltID string, statuses []visor.PayoutStatus) ([]visor.Payout, error) {
+	ret := _m.Called(wltID, statuses)
+
+	var r0 []visor.Payout
+	if rf, ok := ret.Get(0).(func(string, []visor.PayoutStatus) []visor.Payout); ok {
+		r0 = rf(wltID, statuses)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.Payout)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []visor.PayoutStatus) error); ok {
+		r1 = rf(wltID, statuses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRichlist provides a mock function with given fields: includeDistribution
 func (_m *MockGatewayer) GetRichlist(includeDistribution bool) (visor.Richlist, error) {
 	ret := _m.Called(includeDistribution)
@@ -1020,6 +1250,29 @@ func (_m *MockGatewayer) GetWalletSeed(wltID string, password []byte) (string, e
 	return r0, r1
 }
 
+// GetWalletSeedShares provides a mock function with given fields: wltID, password, n, threshold
+func (_m *MockGatewayer) GetWalletSeedShares(wltID string, password []byte, n int, threshold int) ([]string, error) {
+	ret := _m.Called(wltID, password, n, threshold)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, []byte, int, int) []string); ok {
+		r0 = rf(wltID, password, n, threshold)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, int, int) error); ok {
+		r1 = rf(wltID, password, n, threshold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetWalletUnconfirmedTransactions provides a mock function with given fields: wltID
 func (_m *MockGatewayer) GetWalletUnconfirmedTransactions(wltID string) ([]visor.UnconfirmedTransaction, error) {
 	ret := _m.Called(wltID)
@@ -1126,6 +1379,52 @@ func (_m *MockGatewayer) HeadBkSeq() (uint64, bool, error) {
 	return r0, r1, r2
 }
 
+// ImportKeys provides a mock function with given fields: wltID, password, keys
+func (_m *MockGatewayer) ImportKeys(wltID string, password []byte, keys []cipher.SecKey) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, password, keys)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, []byte, []cipher.SecKey) *wallet.Wallet); ok {
+		r0 = rf(wltID, password, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, []cipher.SecKey) error); ok {
+		r1 = rf(wltID, password, keys)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportWallets provides a mock function with given fields: b
+func (_m *MockGatewayer) ImportWallets(b *wallet.Bundle) ([]wallet.ImportedWallet, error) {
+	ret := _m.Called(b)
+
+	var r0 []wallet.ImportedWallet
+	if rf, ok := ret.Get(0).(func(*wallet.Bundle) []wallet.ImportedWallet); ok {
+		r0 = rf(b)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]wallet.ImportedWallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*wallet.Bundle) error); ok {
+		r1 = rf(b)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InjectBroadcastTransaction provides a mock function with given fields: txn
 func (_m *MockGatewayer) InjectBroadcastTransaction(txn coin.Transaction) error {
 	ret := _m.Called(txn)
@@ -1163,13 +1462,100 @@ func (_m *MockGatewayer) NewAddresses(wltID string, password []byte, n uint64) (
 	return r0, r1
 }
 
-// RecoverWallet provides a mock function with given fields: wltID, seed, password
-func (_m *MockGatewayer) RecoverWallet(wltID string, seed string, password []byte) (*wallet.Wallet, error) {
-	ret := _m.Called(wltID, seed, password)
+// PreviewTransaction provides a mock function with given fields: p, wp
+func (_m *MockGatewayer) PreviewTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*visor.TransactionPreview, error) {
+	ret := _m.Called(p, wp)
+
+	var r0 *visor.TransactionPreview
+	if rf, ok := ret.Get(0).(func(transaction.Params, visor.CreateTransactionParams) *visor.TransactionPreview); ok {
+		r0 = rf(p, wp)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.TransactionPreview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(transaction.Params, visor.CreateTransactionParams) error); ok {
+		r1 = rf(p, wp)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecoverWalletFromShares provides a mock function with given fields: wltID, shares, seedPassphrase, password, gapLimit, tf
+func (_m *MockGatewayer) RecoverWalletFromShares(wltID string, shares []string, seedPassphrase string, password []byte, gapLimit uint64, tf wallet.TransactionsFinder) (*wallet.Wallet, []wallet.ScanProgress, error) {
+	ret := _m.Called(wltID, shares, seedPassphrase, password, gapLimit, tf)
 
 	var r0 *wallet.Wallet
-	if rf, ok := ret.Get(0).(func(string, string, []byte) *wallet.Wallet); ok {
-		r0 = rf(wltID, seed, password)
+	if rf, ok := ret.Get(0).(func(string, []string, string, []byte, uint64, wallet.TransactionsFinder) *wallet.Wallet); ok {
+		r0 = rf(wltID, shares, seedPassphrase, password, gapLimit, tf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 []wallet.ScanProgress
+	if rf, ok := ret.Get(1).(func(string, []string, string, []byte, uint64, wallet.TransactionsFinder) []wallet.ScanProgress); ok {
+		r1 = rf(wltID, shares, seedPassphrase, password, gapLimit, tf)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]wallet.ScanProgress)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, []string, string, []byte, uint64, wallet.TransactionsFinder) error); ok {
+		r2 = rf(wltID, shares, seedPassphrase, password, gapLimit, tf)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RecoverWalletScan provides a mock function with given fields: wltID, seed, seedPassphrase, password, gapLimit, tf
+func (_m *MockGatewayer) RecoverWalletScan(wltID string, seed string, seedPassphrase string, password []byte, gapLimit uint64, tf wallet.TransactionsFinder) (*wallet.Wallet, []wallet.ScanProgress, error) {
+	ret := _m.Called(wltID, seed, seedPassphrase, password, gapLimit, tf)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, string, string, []byte, uint64, wallet.TransactionsFinder) *wallet.Wallet); ok {
+		r0 = rf(wltID, seed, seedPassphrase, password, gapLimit, tf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 []wallet.ScanProgress
+	if rf, ok := ret.Get(1).(func(string, string, string, []byte, uint64, wallet.TransactionsFinder) []wallet.ScanProgress); ok {
+		r1 = rf(wltID, seed, seedPassphrase, password, gapLimit, tf)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]wallet.ScanProgress)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, string, string, []byte, uint64, wallet.TransactionsFinder) error); ok {
+		r2 = rf(wltID, seed, seedPassphrase, password, gapLimit, tf)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RemoveKeys provides a mock function with given fields: wltID, password, addrs
+func (_m *MockGatewayer) RemoveKeys(wltID string, password []byte, addrs []cipher.Address) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, password, addrs)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, []byte, []cipher.Address) *wallet.Wallet); ok {
+		r0 = rf(wltID, password, addrs)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*wallet.Wallet)
@@ -1177,8 +1563,8 @@ func (_m *MockGatewayer) RecoverWallet(wltID string, seed string, password []byt
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string, []byte) error); ok {
-		r1 = rf(wltID, seed, password)
+	if rf, ok := ret.Get(1).(func(string, []byte, []cipher.Address) error); ok {
+		r1 = rf(wltID, password, addrs)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1223,6 +1609,121 @@ func (_m *MockGatewayer) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 	return r0, r1
 }
 
+// SetFreshChange provides a mock function with given fields: wltID, freshChange
+func (_m *MockGatewayer) SetFreshChange(wltID string, freshChange bool) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, freshChange)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, bool) *wallet.Wallet); ok {
+		r0 = rf(wltID, freshChange)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, bool) error); ok {
+		r1 = rf(wltID, freshChange)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSignerSocket provides a mock function with given fields: wltID, socketPath
+func (_m *MockGatewayer) SetSignerSocket(wltID string, socketPath string) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, socketPath)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, string) *wallet.Wallet); ok {
+		r0 = rf(wltID, socketPath)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(wltID, socketPath)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSpendingPolicy provides a mock function with given fields: wltID, limits, approvalPassword, newApprovalPassword
+func (_m *MockGatewayer) SetSpendingPolicy(wltID string, limits wallet.SpendingLimits, approvalPassword []byte, newApprovalPassword []byte) (*wallet.SpendingPolicy, error) {
+	ret := _m.Called(wltID, limits, approvalPassword, newApprovalPassword)
+
+	var r0 *wallet.SpendingPolicy
+	if rf, ok := ret.Get(0).(func(string, wallet.SpendingLimits, []byte, []byte) *wallet.SpendingPolicy); ok {
+		r0 = rf(wltID, limits, approvalPassword, newApprovalPassword)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.SpendingPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, wallet.SpendingLimits, []byte, []byte) error); ok {
+		r1 = rf(wltID, limits, approvalPassword, newApprovalPassword)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SignPartialTransaction provides a mock function with given fields: wltID, password, pt
+func (_m *MockGatewayer) SignPartialTransaction(wltID string, password []byte, pt *transaction.PartialTransaction) (*transaction.PartialTransaction, error) {
+	ret := _m.Called(wltID, password, pt)
+
+	var r0 *transaction.PartialTransaction
+	if rf, ok := ret.Get(0).(func(string, []byte, *transaction.PartialTransaction) *transaction.PartialTransaction); ok {
+		r0 = rf(wltID, password, pt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*transaction.PartialTransaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, *transaction.PartialTransaction) error); ok {
+		r1 = rf(wltID, password, pt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SpendingPolicy provides a mock function with given fields: wltID
+func (_m *MockGatewayer) SpendingPolicy(wltID string) (*wallet.SpendingPolicy, error) {
+	ret := _m.Called(wltID)
+
+	var r0 *wallet.SpendingPolicy
+	if rf, ok := ret.Get(0).(func(string) *wallet.SpendingPolicy); ok {
+		r0 = rf(wltID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.SpendingPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(wltID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StartedAt provides a mock function with given fields:
 func (_m *MockGatewayer) StartedAt() time.Time {
 	ret := _m.Called()
@@ -1237,6 +1738,45 @@ func (_m *MockGatewayer) StartedAt() time.Time {
 	return r0
 }
 
+// SubscribeEvents provides a mock function with given fields: filter, bufferSize
+func (_m *MockGatewayer) SubscribeEvents(filter visor.EventFilter, bufferSize int) *visor.Subscription {
+	ret := _m.Called(filter, bufferSize)
+
+	var r0 *visor.Subscription
+	if rf, ok := ret.Get(0).(func(visor.EventFilter, int) *visor.Subscription); ok {
+		r0 = rf(filter, bufferSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.Subscription)
+		}
+	}
+
+	return r0
+}
+
+// UnfreezeUxOuts provides a mock function with given fields: wltID, uxOuts
+func (_m *MockGatewayer) UnfreezeUxOuts(wltID string, uxOuts []cipher.SHA256) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, uxOuts)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, []cipher.SHA256) *wallet.Wallet); ok {
+		r0 = rf(wltID, uxOuts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []cipher.SHA256) error); ok {
+		r1 = rf(wltID, uxOuts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UnloadWallet provides a mock function with given fields: wltID
 func (_m *MockGatewayer) UnloadWallet(wltID string) error {
 	ret := _m.Called(wltID)
@@ -1251,6 +1791,29 @@ func (_m *MockGatewayer) UnloadWallet(wltID string) error {
 	return r0
 }
 
+// UpdateAddressMeta provides a mock function with given fields: wltID, addr, u
+func (_m *MockGatewayer) UpdateAddressMeta(wltID string, addr cipher.Addresser, u wallet.EntryMetaUpdate) (*wallet.Wallet, error) {
+	ret := _m.Called(wltID, addr, u)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, cipher.Addresser, wallet.EntryMetaUpdate) *wallet.Wallet); ok {
+		r0 = rf(wltID, addr, u)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, cipher.Addresser, wallet.EntryMetaUpdate) error); ok {
+		r1 = rf(wltID, addr, u)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateWalletLabel provides a mock function with given fields: wltID, label
 func (_m *MockGatewayer) UpdateWalletLabel(wltID string, label string) error {
 	ret := _m.Called(wltID, label)
@@ -1295,13 +1858,77 @@ func (_m *MockGatewayer) VerifyTxnVerbose(txn *coin.Transaction, signed visor.Tx
 	return r0, r1, r2
 }
 
-// WalletCreateTransaction provides a mock function with given fields: wltID, p, wp
-func (_m *MockGatewayer) WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
-	ret := _m.Called(wltID, p, wp)
+// WalletCreateConsolidateTransactions provides a mock function with given fields: wltID, password, p, signed
+func (_m *MockGatewayer) WalletCreateConsolidateTransactions(wltID string, password []byte, p wallet.ConsolidateParams, signed visor.TxnSignedFlag) ([]coin.Transaction, [][]visor.TransactionInput, error) {
+	ret := _m.Called(wltID, password, p, signed)
+
+	var r0 []coin.Transaction
+	if rf, ok := ret.Get(0).(func(string, []byte, wallet.ConsolidateParams, visor.TxnSignedFlag) []coin.Transaction); ok {
+		r0 = rf(wltID, password, p, signed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]coin.Transaction)
+		}
+	}
+
+	var r1 [][]visor.TransactionInput
+	if rf, ok := ret.Get(1).(func(string, []byte, wallet.ConsolidateParams, visor.TxnSignedFlag) [][]visor.TransactionInput); ok {
+		r1 = rf(wltID, password, p, signed)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([][]visor.TransactionInput)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, []byte, wallet.ConsolidateParams, visor.TxnSignedFlag) error); ok {
+		r2 = rf(wltID, password, p, signed)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// WalletCreateSplitTransactions provides a mock function with given fields: wltID, password, p, wp
+func (_m *MockGatewayer) WalletCreateSplitTransactions(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) ([]coin.Transaction, [][]visor.TransactionInput, error) {
+	ret := _m.Called(wltID, password, p, wp)
+
+	var r0 []coin.Transaction
+	if rf, ok := ret.Get(0).(func(string, []byte, transaction.Params, visor.CreateTransactionParams) []coin.Transaction); ok {
+		r0 = rf(wltID, password, p, wp)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]coin.Transaction)
+		}
+	}
+
+	var r1 [][]visor.TransactionInput
+	if rf, ok := ret.Get(1).(func(string, []byte, transaction.Params, visor.CreateTransactionParams) [][]visor.TransactionInput); ok {
+		r1 = rf(wltID, password, p, wp)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([][]visor.TransactionInput)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, []byte, transaction.Params, visor.CreateTransactionParams) error); ok {
+		r2 = rf(wltID, password, p, wp)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// WalletCreateTransaction provides a mock function with given fields: wltID, password, p, wp
+func (_m *MockGatewayer) WalletCreateTransaction(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
+	ret := _m.Called(wltID, password, p, wp)
 
 	var r0 *coin.Transaction
-	if rf, ok := ret.Get(0).(func(string, transaction.Params, visor.CreateTransactionParams) *coin.Transaction); ok {
-		r0 = rf(wltID, p, wp)
+	if rf, ok := ret.Get(0).(func(string, []byte, transaction.Params, visor.CreateTransactionParams) *coin.Transaction); ok {
+		r0 = rf(wltID, password, p, wp)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*coin.Transaction)
@@ -1309,8 +1936,8 @@ func (_m *MockGatewayer) WalletCreateTransaction(wltID string, p transaction.Par
 	}
 
 	var r1 []visor.TransactionInput
-	if rf, ok := ret.Get(1).(func(string, transaction.Params, visor.CreateTransactionParams) []visor.TransactionInput); ok {
-		r1 = rf(wltID, p, wp)
+	if rf, ok := ret.Get(1).(func(string, []byte, transaction.Params, visor.CreateTransactionParams) []visor.TransactionInput); ok {
+		r1 = rf(wltID, password, p, wp)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).([]visor.TransactionInput)
@@ -1318,8 +1945,8 @@ func (_m *MockGatewayer) WalletCreateTransaction(wltID string, p transaction.Par
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(string, transaction.Params, visor.CreateTransactionParams) error); ok {
-		r2 = rf(wltID, p, wp)
+	if rf, ok := ret.Get(2).(func(string, []byte, transaction.Params, visor.CreateTransactionParams) error); ok {
+		r2 = rf(wltID, password, p, wp)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1380,6 +2007,29 @@ func (_m *MockGatewayer) WalletDir() (string, error) {
 	return r0, r1
 }
 
+// WalletDirEvents provides a mock function with given fields:
+func (_m *MockGatewayer) WalletDirEvents() ([]wallet.WalletDirEvent, error) {
+	ret := _m.Called()
+
+	var r0 []wallet.WalletDirEvent
+	if rf, ok := ret.Get(0).(func() []wallet.WalletDirEvent); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]wallet.WalletDirEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WalletSignTransaction provides a mock function with given fields: wltID, password, txn, signIndexes
 func (_m *MockGatewayer) WalletSignTransaction(wltID string, password []byte, txn *coin.Transaction, signIndexes []int) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(wltID, password, txn, signIndexes)
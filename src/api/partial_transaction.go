@@ -0,0 +1,412 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// PartialTransactionResponse is returned by the endpoints which create, sign or combine a partially signed transaction
+type PartialTransactionResponse struct {
+	PartialTransaction json.RawMessage `json:"partial_transaction"`
+}
+
+// NewPartialTransactionResponse creates a PartialTransactionResponse
+func NewPartialTransactionResponse(pt *transaction.PartialTransaction) (*PartialTransactionResponse, error) {
+	b, err := pt.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartialTransactionResponse{
+		PartialTransaction: b,
+	}, nil
+}
+
+// PartialTransactionInput is an input of an inspected partially signed transaction
+type PartialTransactionInput struct {
+	UxID        string `json:"uxid"`
+	Address     string `json:"address"`
+	Coins       string `json:"coins"`
+	Hours       string `json:"hours"`
+	Signed      bool   `json:"signed"`
+	Signer      string `json:"signer,omitempty"`
+	ChildNumber uint32 `json:"child_number"`
+	Change      uint32 `json:"change"`
+}
+
+// PartialTransactionSigner is the signing progress of one signer of an inspected partially signed transaction
+type PartialTransactionSigner struct {
+	Signer string `json:"signer"`
+	Inputs []int  `json:"inputs"`
+	Signed int    `json:"signed"`
+}
+
+// InspectPartialTransactionResponse is returned by /api/v2/transaction/partial/inspect
+type InspectPartialTransactionResponse struct {
+	Version     uint32                     `json:"version"`
+	TxID        string                     `json:"txid"`
+	InnerHash   string                     `json:"inner_hash"`
+	FullySigned bool                       `json:"fully_signed"`
+	Inputs      []PartialTransactionInput  `json:"inputs"`
+	Outputs     []CreatedTransactionOutput `json:"outputs"`
+	Signers     []PartialTransactionSigner `json:"signers"`
+}
+
+// NewInspectPartialTransactionResponse creates an InspectPartialTransactionResponse
+func NewInspectPartialTransactionResponse(pt *transaction.PartialTransaction) (*InspectPartialTransactionResponse, error) {
+	status := pt.Status()
+
+	inputs := make([]PartialTransactionInput, len(pt.Inputs))
+	for i, in := range pt.Inputs {
+		coins, err := droplet.ToString(in.UxOut.Body.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs[i] = PartialTransactionInput{
+			UxID:        in.UxOut.Hash().Hex(),
+			Address:     in.UxOut.Body.Address.String(),
+			Coins:       coins,
+			Hours:       fmt.Sprint(in.UxOut.Body.Hours),
+			Signed:      status.Signed[i],
+			Signer:      in.Signer,
+			ChildNumber: in.ChildNumber,
+			Change:      in.Change,
+		}
+	}
+
+	txID := pt.Transaction.Hash()
+	outputs := make([]CreatedTransactionOutput, len(pt.Transaction.Out))
+	for i, o := range pt.Transaction.Out {
+		co, err := NewCreatedTransactionOutput(o, txID)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = *co
+	}
+
+	signers := make([]PartialTransactionSigner, len(status.Signers))
+	for i, s := range status.Signers {
+		signers[i] = PartialTransactionSigner{
+			Signer: s.Signer,
+			Inputs: s.Inputs,
+			Signed: s.Signed,
+		}
+	}
+
+	return &InspectPartialTransactionResponse{
+		Version:     pt.Version,
+		TxID:        txID.Hex(),
+		InnerHash:   pt.Transaction.InnerHash.Hex(),
+		FullySigned: status.FullySigned,
+		Inputs:      inputs,
+		Outputs:     outputs,
+		Signers:     signers,
+	}, nil
+}
+
+// FinalizePartialTransactionResponse is returned by /api/v2/transaction/partial/finalize
+type FinalizePartialTransactionResponse struct {
+	TxID               string `json:"txid"`
+	EncodedTransaction string `json:"encoded_transaction"`
+}
+
+// CreatePartialTransactionRequest is the request body object for /api/v2/transaction/partial/create
+type CreatePartialTransactionRequest struct {
+	EncodedTransaction string `json:"encoded_transaction"`
+}
+
+// WalletSignPartialTransactionRequest is the request body object for /api/v2/wallet/transaction/partial/sign
+type WalletSignPartialTransactionRequest struct {
+	WalletID           string          `json:"wallet_id"`
+	Password           string          `json:"password"`
+	PartialTransaction json.RawMessage `json:"partial_transaction"`
+}
+
+// CombinePartialTransactionsRequest is the request body object for /api/v2/transaction/partial/combine
+type CombinePartialTransactionsRequest struct {
+	PartialTransactions []json.RawMessage `json:"partial_transactions"`
+}
+
+// PartialTransactionRequest is the request body object for /api/v2/transaction/partial/inspect
+// and /api/v2/transaction/partial/finalize
+type PartialTransactionRequest struct {
+	PartialTransaction json.RawMessage `json:"partial_transaction"`
+}
+
+// decodePartialTransaction decodes a partially signed transaction from a request body field
+func decodePartialTransaction(b json.RawMessage) (*transaction.PartialTransaction, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, errors.New("partial_transaction is required")
+	}
+
+	return transaction.DeserializePartialTransaction(b)
+}
+
+// partialTransactionErrorResponse maps the errors of the partially signed transaction endpoints to an HTTPResponse
+func partialTransactionErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.SpendingPolicyError:
+		return NewHTTPErrorResponse(http.StatusForbidden, err.Error())
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, err.Error())
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, err.Error())
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	case transaction.Error,
+		visor.UserError,
+		visor.ErrTxnViolatesSoftConstraint,
+		visor.ErrTxnViolatesHardConstraint,
+		visor.ErrTxnViolatesUserConstraint,
+		blockdb.ErrUnspentNotExist:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// writePartialTransactionResponse writes a PartialTransactionResponse
+func writePartialTransactionResponse(w http.ResponseWriter, pt *transaction.PartialTransaction) {
+	ptResp, err := NewPartialTransactionResponse(pt)
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: ptResp,
+	})
+}
+
+// checkJSONPost writes an error response and returns false if the request is not a JSON POST request
+func checkJSONPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+		writeHTTPResponse(w, resp)
+		return false
+	}
+
+	if r.Header.Get("Content-Type") != ContentTypeJSON {
+		resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+		writeHTTPResponse(w, resp)
+		return false
+	}
+
+	return true
+}
+
+// createPartialTransactionHandler creates a partially signed transaction from an unsigned transaction.
+// The outputs spent by the transaction must be unspent.
+// Method: POST
+// URI: /api/v2/transaction/partial/create
+// Args: JSON body
+func createPartialTransactionHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkJSONPost(w, r) {
+			return
+		}
+
+		var req CreatePartialTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.EncodedTransaction == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "encoded_transaction is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txn, err := decodeTxn(req.EncodedTransaction)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("Decode transaction failed: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		pt, err := gateway.CreatePartialTransaction(*txn)
+		if err != nil {
+			writeHTTPResponse(w, partialTransactionErrorResponse(err))
+			return
+		}
+
+		writePartialTransactionResponse(w, pt)
+	}
+}
+
+// walletSignPartialTransactionHandler signs the inputs of a partially signed transaction that are owned by a wallet
+// Method: POST
+// URI: /api/v2/wallet/transaction/partial/sign
+// Args: JSON body
+func walletSignPartialTransactionHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkJSONPost(w, r) {
+			return
+		}
+
+		var req WalletSignPartialTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.WalletID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "wallet_id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		pt, err := decodePartialTransaction(req.PartialTransaction)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		signed, err := gateway.SignPartialTransaction(req.WalletID, []byte(req.Password), pt)
+		if err != nil {
+			writeHTTPResponse(w, partialTransactionErrorResponse(err))
+			return
+		}
+
+		writePartialTransactionResponse(w, signed)
+	}
+}
+
+// combinePartialTransactionsHandler merges the signatures of partially signed transactions of the same transaction
+// Method: POST
+// URI: /api/v2/transaction/partial/combine
+// Args: JSON body
+func combinePartialTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkJSONPost(w, r) {
+		return
+	}
+
+	var req CombinePartialTransactionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	if len(req.PartialTransactions) == 0 {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, "partial_transactions is required")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	pts := make([]*transaction.PartialTransaction, len(req.PartialTransactions))
+	for i, b := range req.PartialTransactions {
+		pt, err := decodePartialTransaction(b)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("partial_transactions[%d]: %v", i, err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+		pts[i] = pt
+	}
+
+	combined, err := transaction.CombinePartialTransactions(pts...)
+	if err != nil {
+		writeHTTPResponse(w, partialTransactionErrorResponse(err))
+		return
+	}
+
+	writePartialTransactionResponse(w, combined)
+}
+
+// inspectPartialTransactionHandler reports the inputs, outputs and signing progress of a partially signed transaction
+// Method: POST
+// URI: /api/v2/transaction/partial/inspect
+// Args: JSON body
+func inspectPartialTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkJSONPost(w, r) {
+		return
+	}
+
+	var req PartialTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	pt, err := decodePartialTransaction(req.PartialTransaction)
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	inspectResp, err := NewInspectPartialTransactionResponse(pt)
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: inspectResp,
+	})
+}
+
+// finalizePartialTransactionHandler returns the signed transaction of a fully signed partially signed transaction.
+// The transaction is not broadcast.
+// Method: POST
+// URI: /api/v2/transaction/partial/finalize
+// Args: JSON body
+func finalizePartialTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkJSONPost(w, r) {
+		return
+	}
+
+	var req PartialTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	pt, err := decodePartialTransaction(req.PartialTransaction)
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	txn, err := pt.Finalize()
+	if err != nil {
+		writeHTTPResponse(w, partialTransactionErrorResponse(err))
+		return
+	}
+
+	txnHex, err := txn.SerializeHex()
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: FinalizePartialTransactionResponse{
+			TxID:               txn.Hash().Hex(),
+			EncodedTransaction: txnHex,
+		},
+	})
+}
@@ -0,0 +1,482 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// makePartialTransaction creates a partially signed transaction spending one output of each secret key
+func makePartialTransaction(t *testing.T, secKeys []cipher.SecKey) *transaction.PartialTransaction {
+	var txn coin.Transaction
+	uxOuts := make([]coin.UxOut, len(secKeys))
+	for i, s := range secKeys {
+		uxOuts[i] = coin.UxOut{
+			Head: coin.UxHead{
+				Time:  100,
+				BkSeq: 2,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        cipher.MustAddressFromSecKey(s),
+				Coins:          2e6,
+				Hours:          100,
+			},
+		}
+		err := txn.PushInput(uxOuts[i].Hash())
+		require.NoError(t, err)
+	}
+
+	err := txn.PushOutput(testutil.MakeAddress(), uint64(len(secKeys))*2e6, 50)
+	require.NoError(t, err)
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	pt, err := transaction.NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+
+	return pt
+}
+
+func signPartialTransactionInput(t *testing.T, pt *transaction.PartialTransaction, secKey cipher.SecKey, i int) *transaction.PartialTransaction {
+	signed := pt.Copy()
+	err := signed.Transaction.SignInput(secKey, i)
+	require.NoError(t, err)
+	err = signed.Transaction.UpdateHeader()
+	require.NoError(t, err)
+	return signed
+}
+
+func serializePartialTransaction(t *testing.T, pt *transaction.PartialTransaction) json.RawMessage {
+	b, err := pt.Serialize()
+	require.NoError(t, err)
+	return b
+}
+
+// servePartialTransactionRequest sends a request to a partially signed transaction endpoint
+// and decodes the response data into data, if any
+func servePartialTransactionRequest(t *testing.T, gateway *MockGatewayer, method, endpoint, contentType, body string, status int, httpResponse HTTPResponse, data interface{}) {
+	req, err := http.NewRequest(method, endpoint, strings.NewReader(body))
+	require.NoError(t, err)
+
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	setCSRFParameters(t, tokenValid, req)
+
+	rr := httptest.NewRecorder()
+
+	cfg := defaultMuxConfig()
+	cfg.disableCSRF = false
+
+	handler := newServerMux(cfg, gateway)
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, status, rr.Code, "got `%v` want `%v`", rr.Code, status)
+
+	var rsp ReceivedHTTPResponse
+	err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+
+	require.Equal(t, httpResponse.Error, rsp.Error)
+
+	if rsp.Data == nil {
+		require.Nil(t, httpResponse.Data)
+		return
+	}
+
+	require.NotNil(t, httpResponse.Data)
+	err = json.Unmarshal(rsp.Data, data)
+	require.NoError(t, err)
+}
+
+func TestCreatePartialTransaction(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+	pt := makePartialTransaction(t, secKeys)
+	pt.Inputs[1].Signer = "foo.wlt"
+
+	txnHex, err := pt.Transaction.SerializeHex()
+	require.NoError(t, err)
+
+	type gatewayReturnPair struct {
+		pt  *transaction.PartialTransaction
+		err error
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		contentType   string
+		status        int
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn *gatewayReturnPair
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			contentType:  ContentTypeForm,
+			status:       http.StatusUnsupportedMediaType,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, ""),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "encoded_transaction missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpBody:     toJSON(t, CreatePartialTransactionRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "encoded_transaction is required"),
+		},
+		{
+			name:   "invalid encoded_transaction",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			httpBody: toJSON(t, CreatePartialTransactionRequest{
+				EncodedTransaction: "abc",
+			}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "Decode transaction failed: encoding/hex: odd length hex string"),
+		},
+		{
+			name:   "transaction already signed",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			httpBody: toJSON(t, CreatePartialTransactionRequest{
+				EncodedTransaction: txnHex,
+			}),
+			gatewayReturn: &gatewayReturnPair{
+				err: visor.ErrTransactionAlreadySigned,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, visor.ErrTransactionAlreadySigned.Error()),
+		},
+		{
+			name:   "other error",
+			method: http.MethodPost,
+			status: http.StatusInternalServerError,
+			httpBody: toJSON(t, CreatePartialTransactionRequest{
+				EncodedTransaction: txnHex,
+			}),
+			gatewayReturn: &gatewayReturnPair{
+				err: errors.New("failed"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "failed"),
+		},
+		{
+			name:   "ok",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			httpBody: toJSON(t, CreatePartialTransactionRequest{
+				EncodedTransaction: txnHex,
+			}),
+			gatewayReturn: &gatewayReturnPair{
+				pt: pt,
+			},
+			httpResponse: HTTPResponse{
+				Data: PartialTransactionResponse{
+					PartialTransaction: serializePartialTransaction(t, pt),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayReturn != nil {
+				gateway.On("CreatePartialTransaction", pt.Transaction).Return(tc.gatewayReturn.pt, tc.gatewayReturn.err)
+			}
+
+			var rsp PartialTransactionResponse
+			servePartialTransactionRequest(t, gateway, tc.method, "/api/v2/transaction/partial/create", tc.contentType, tc.httpBody, tc.status, tc.httpResponse, &rsp)
+
+			if tc.status == http.StatusOK {
+				rspPt, err := transaction.DeserializePartialTransaction(rsp.PartialTransaction)
+				require.NoError(t, err)
+				require.Equal(t, pt, rspPt)
+			}
+		})
+	}
+}
+
+func TestWalletSignPartialTransaction(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+	pt := makePartialTransaction(t, secKeys)
+	signed := signPartialTransactionInput(t, pt, secKeys[0], 0)
+	signed.Inputs[0].Signer = "foo.wlt"
+
+	type gatewayReturnPair struct {
+		pt  *transaction.PartialTransaction
+		err error
+	}
+
+	cases := []struct {
+		name          string
+		status        int
+		req           WalletSignPartialTransactionRequest
+		httpResponse  HTTPResponse
+		gatewayReturn *gatewayReturnPair
+	}{
+		{
+			name:   "wallet_id missing",
+			status: http.StatusBadRequest,
+			req: WalletSignPartialTransactionRequest{
+				PartialTransaction: serializePartialTransaction(t, pt),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "wallet_id is required"),
+		},
+		{
+			name:   "partial_transaction missing",
+			status: http.StatusBadRequest,
+			req: WalletSignPartialTransactionRequest{
+				WalletID: "foo.wlt",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "partial_transaction is required"),
+		},
+		{
+			name:   "invalid partial_transaction",
+			status: http.StatusBadRequest,
+			req: WalletSignPartialTransactionRequest{
+				WalletID:           "foo.wlt",
+				PartialTransaction: json.RawMessage(`{"version":2}`),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, transaction.ErrUnsupportedPartialTransactionVersion.Error()),
+		},
+		{
+			name:   "wallet does not exist",
+			status: http.StatusNotFound,
+			req: WalletSignPartialTransactionRequest{
+				WalletID:           "foo.wlt",
+				PartialTransaction: serializePartialTransaction(t, pt),
+			},
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, wallet.ErrWalletNotExist.Error()),
+		},
+		{
+			name:   "wallet api disabled",
+			status: http.StatusForbidden,
+			req: WalletSignPartialTransactionRequest{
+				WalletID:           "foo.wlt",
+				PartialTransaction: serializePartialTransaction(t, pt),
+			},
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, wallet.ErrWalletAPIDisabled.Error()),
+		},
+		{
+			name:   "no inputs to sign",
+			status: http.StatusBadRequest,
+			req: WalletSignPartialTransactionRequest{
+				WalletID:           "foo.wlt",
+				PartialTransaction: serializePartialTransaction(t, pt),
+			},
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrNoInputsToSign,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrNoInputsToSign.Error()),
+		},
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			req: WalletSignPartialTransactionRequest{
+				WalletID:           "foo.wlt",
+				Password:           "pwd",
+				PartialTransaction: serializePartialTransaction(t, pt),
+			},
+			gatewayReturn: &gatewayReturnPair{
+				pt: signed,
+			},
+			httpResponse: HTTPResponse{
+				Data: PartialTransactionResponse{
+					PartialTransaction: serializePartialTransaction(t, signed),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayReturn != nil {
+				gateway.On("SignPartialTransaction", tc.req.WalletID, []byte(tc.req.Password), pt).Return(tc.gatewayReturn.pt, tc.gatewayReturn.err)
+			}
+
+			var rsp PartialTransactionResponse
+			servePartialTransactionRequest(t, gateway, http.MethodPost, "/api/v2/wallet/transaction/partial/sign", "", toJSON(t, tc.req), tc.status, tc.httpResponse, &rsp)
+
+			if tc.status == http.StatusOK {
+				rspPt, err := transaction.DeserializePartialTransaction(rsp.PartialTransaction)
+				require.NoError(t, err)
+				require.Equal(t, signed, rspPt)
+			}
+		})
+	}
+}
+
+func TestCombineInspectFinalizePartialTransaction(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+	pt := makePartialTransaction(t, secKeys)
+	a := signPartialTransactionInput(t, pt, secKeys[0], 0)
+	a.Inputs[0].Signer = "a.wlt"
+	b := signPartialTransactionInput(t, pt, secKeys[1], 1)
+	other := makePartialTransaction(t, secKeys)
+
+	combined, err := transaction.CombinePartialTransactions(a, b)
+	require.NoError(t, err)
+	signedTxn, err := combined.Finalize()
+	require.NoError(t, err)
+	signedTxnHex, err := signedTxn.SerializeHex()
+	require.NoError(t, err)
+
+	t.Run("combine", func(t *testing.T) {
+		cases := []struct {
+			name         string
+			status       int
+			req          CombinePartialTransactionsRequest
+			httpResponse HTTPResponse
+		}{
+			{
+				name:         "partial_transactions missing",
+				status:       http.StatusBadRequest,
+				httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "partial_transactions is required"),
+			},
+			{
+				name:   "invalid partial transaction",
+				status: http.StatusBadRequest,
+				req: CombinePartialTransactionsRequest{
+					PartialTransactions: []json.RawMessage{
+						serializePartialTransaction(t, a),
+						json.RawMessage(`"foo"`),
+					},
+				},
+				httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "partial_transactions[1]: invalid partial transaction: json: cannot unmarshal string into Go value of type transaction.partialTransactionJSON"),
+			},
+			{
+				name:   "different transactions",
+				status: http.StatusBadRequest,
+				req: CombinePartialTransactionsRequest{
+					PartialTransactions: []json.RawMessage{
+						serializePartialTransaction(t, a),
+						serializePartialTransaction(t, other),
+					},
+				},
+				httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, transaction.ErrPartialTransactionsMismatch.Error()),
+			},
+			{
+				name:   "ok",
+				status: http.StatusOK,
+				req: CombinePartialTransactionsRequest{
+					PartialTransactions: []json.RawMessage{
+						serializePartialTransaction(t, a),
+						serializePartialTransaction(t, b),
+					},
+				},
+				httpResponse: HTTPResponse{
+					Data: PartialTransactionResponse{
+						PartialTransaction: serializePartialTransaction(t, combined),
+					},
+				},
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				var rsp PartialTransactionResponse
+				servePartialTransactionRequest(t, &MockGatewayer{}, http.MethodPost, "/api/v2/transaction/partial/combine", "", toJSON(t, tc.req), tc.status, tc.httpResponse, &rsp)
+
+				if tc.status == http.StatusOK {
+					rspPt, err := transaction.DeserializePartialTransaction(rsp.PartialTransaction)
+					require.NoError(t, err)
+					require.Equal(t, combined, rspPt)
+				}
+			})
+		}
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		var rsp InspectPartialTransactionResponse
+		servePartialTransactionRequest(t, &MockGatewayer{}, http.MethodPost, "/api/v2/transaction/partial/inspect", "", toJSON(t, PartialTransactionRequest{
+			PartialTransaction: serializePartialTransaction(t, a),
+		}), http.StatusOK, HTTPResponse{Data: struct{}{}}, &rsp)
+
+		require.Equal(t, uint32(transaction.PartialTransactionVersion), rsp.Version)
+		require.Equal(t, a.Transaction.Hash().Hex(), rsp.TxID)
+		require.False(t, rsp.FullySigned)
+		require.Equal(t, []PartialTransactionInput{
+			{
+				UxID:    a.Inputs[0].UxOut.Hash().Hex(),
+				Address: a.Inputs[0].UxOut.Body.Address.String(),
+				Coins:   "2.000000",
+				Hours:   "100",
+				Signed:  true,
+				Signer:  "a.wlt",
+			},
+			{
+				UxID:    a.Inputs[1].UxOut.Hash().Hex(),
+				Address: a.Inputs[1].UxOut.Body.Address.String(),
+				Coins:   "2.000000",
+				Hours:   "100",
+			},
+		}, rsp.Inputs)
+		require.Len(t, rsp.Outputs, 1)
+		require.Equal(t, "4.000000", rsp.Outputs[0].Coins)
+		require.Equal(t, []PartialTransactionSigner{
+			{
+				Signer: "a.wlt",
+				Inputs: []int{0},
+				Signed: 1,
+			},
+			{
+				Signer: a.Inputs[1].UxOut.Body.Address.String(),
+				Inputs: []int{1},
+			},
+		}, rsp.Signers)
+
+		servePartialTransactionRequest(t, &MockGatewayer{}, http.MethodPost, "/api/v2/transaction/partial/inspect", "", toJSON(t, PartialTransactionRequest{}),
+			http.StatusBadRequest, NewHTTPErrorResponse(http.StatusBadRequest, "partial_transaction is required"), nil)
+	})
+
+	t.Run("finalize", func(t *testing.T) {
+		servePartialTransactionRequest(t, &MockGatewayer{}, http.MethodPost, "/api/v2/transaction/partial/finalize", "", toJSON(t, PartialTransactionRequest{
+			PartialTransaction: serializePartialTransaction(t, a),
+		}), http.StatusBadRequest, NewHTTPErrorResponse(http.StatusBadRequest, transaction.ErrPartialTransactionNotFullySigned.Error()), nil)
+
+		var rsp FinalizePartialTransactionResponse
+		servePartialTransactionRequest(t, &MockGatewayer{}, http.MethodPost, "/api/v2/transaction/partial/finalize", "", toJSON(t, PartialTransactionRequest{
+			PartialTransaction: serializePartialTransaction(t, combined),
+		}), http.StatusOK, HTTPResponse{Data: struct{}{}}, &rsp)
+
+		require.Equal(t, FinalizePartialTransactionResponse{
+			TxID:               signedTxn.Hash().Hex(),
+			EncodedTransaction: signedTxnHex,
+		}, rsp)
+	})
+}
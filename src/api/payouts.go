@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// Payout is a payout queued for a wallet
+type Payout struct {
+	ID      uint64    `json:"id"`
+	Address string    `json:"address"`
+	Coins   string    `json:"coins"`
+	Status  string    `json:"status"`
+	Txid    string    `json:"txid,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// NewPayout creates a Payout from a visor.Payout
+func NewPayout(p visor.Payout) (*Payout, error) {
+	coins, err := droplet.ToString(p.Coins)
+	if err != nil {
+		return nil, err
+	}
+
+	var txid string
+	if p.Status != visor.PayoutStatusPending && p.Status != visor.PayoutStatusCanceled {
+		txid = p.Txid.Hex()
+	}
+
+	return &Payout{
+		ID:      p.ID,
+		Address: p.Address.String(),
+		Coins:   coins,
+		Status:  string(p.Status),
+		Txid:    txid,
+		Error:   p.Error,
+		Created: p.Created,
+		Updated: p.Updated,
+	}, nil
+}
+
+// WalletPayoutsResponse is returned by the /api/v2/wallet/payouts endpoints
+type WalletPayoutsResponse struct {
+	ID      string   `json:"id"`
+	Payouts []Payout `json:"payouts"`
+}
+
+// NewWalletPayoutsResponse creates a WalletPayoutsResponse
+func NewWalletPayoutsResponse(wltID string, ps []visor.Payout) (*WalletPayoutsResponse, error) {
+	payouts := make([]Payout, len(ps))
+	for i, p := range ps {
+		rp, err := NewPayout(p)
+		if err != nil {
+			return nil, err
+		}
+		payouts[i] = *rp
+	}
+
+	return &WalletPayoutsResponse{
+		ID:      wltID,
+		Payouts: payouts,
+	}, nil
+}
+
+func writeWalletPayoutsResponse(w http.ResponseWriter, wltID string, ps []visor.Payout) {
+	rsp, err := NewWalletPayoutsResponse(wltID, ps)
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: rsp,
+	})
+}
+
+// walletPayoutsHandler returns the payouts queued for a wallet
+// URI: /api/v2/wallet/payouts
+// Method: GET
+// Args:
+//	id: wallet id [required]
+//	status: comma separated payout statuses to return, pending, sent, confirmed, failed or canceled [optional]
+func walletPayoutsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var statuses []visor.PayoutStatus
+		if s := r.FormValue("status"); s != "" {
+			for _, x := range strings.Split(s, ",") {
+				status := visor.PayoutStatus(strings.TrimSpace(x))
+				switch status {
+				case visor.PayoutStatusPending,
+					visor.PayoutStatusSent,
+					visor.PayoutStatusConfirmed,
+					visor.PayoutStatusFailed,
+					visor.PayoutStatusCanceled:
+				default:
+					resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid status %q", x))
+					writeHTTPResponse(w, resp)
+					return
+				}
+				statuses = append(statuses, status)
+			}
+		}
+
+		ps, err := gateway.GetPayouts(wltID, statuses)
+		if err != nil {
+			writeHTTPResponse(w, payoutsErrorResponse(err))
+			return
+		}
+
+		writeWalletPayoutsResponse(w, wltID, ps)
+	}
+}
+
+// PayoutRequest is a payout of a WalletPayoutsAddRequest
+type PayoutRequest struct {
+	Address string `json:"address"`
+	Coins   string `json:"coins"`
+}
+
+// WalletPayoutsAddRequest is the request data for POST /api/v2/wallet/payouts/add
+type WalletPayoutsAddRequest struct {
+	ID      string          `json:"id"`
+	Payouts []PayoutRequest `json:"payouts"`
+}
+
+// walletPayoutsAddHandler adds payouts to the payout queue of a wallet.
+// The node periodically sends the pending payouts of a wallet in transactions with many outputs.
+// The wallet must not be encrypted.
+// URI: /api/v2/wallet/payouts/add
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	payouts: addresses and coins to send [required]
+func walletPayoutsAddHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletPayoutsAddRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.Payouts) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "payouts is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		reqs := make([]visor.PayoutRequest, len(req.Payouts))
+		for i, p := range req.Payouts {
+			addr, err := cipher.DecodeBase58Address(p.Address)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address %q: %v", p.Address, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			coins, err := droplet.FromString(p.Coins)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid coins %q: %v", p.Coins, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			reqs[i] = visor.PayoutRequest{
+				Address: addr,
+				Coins:   coins,
+			}
+		}
+
+		ps, err := gateway.EnqueuePayouts(req.ID, reqs)
+		if err != nil {
+			writeHTTPResponse(w, payoutsErrorResponse(err))
+			return
+		}
+
+		writeWalletPayoutsResponse(w, req.ID, ps)
+	}
+}
+
+// WalletPayoutsCancelRequest is the request data for POST /api/v2/wallet/payouts/cancel
+type WalletPayoutsCancelRequest struct {
+	ID  string   `json:"id"`
+	IDs []uint64 `json:"ids"`
+}
+
+// walletPayoutsCancelHandler cancels pending payouts of a wallet.
+// No payout is canceled if any of them is not pending.
+// URI: /api/v2/wallet/payouts/cancel
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	ids: ids of the payouts to cancel [required]
+func walletPayoutsCancelHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletPayoutsCancelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.IDs) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "ids is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		ps, err := gateway.CancelPayouts(req.ID, req.IDs)
+		if err != nil {
+			writeHTTPResponse(w, payoutsErrorResponse(err))
+			return
+		}
+
+		writeWalletPayoutsResponse(w, req.ID, ps)
+	}
+}
+
+func payoutsErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, "")
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	case visor.UserError:
+		if err == visor.ErrPayoutNotExist {
+			return NewHTTPErrorResponse(http.StatusNotFound, err.Error())
+		}
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
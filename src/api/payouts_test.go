@@ -0,0 +1,480 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func makeTestPayouts(t *testing.T) ([]visor.Payout, *WalletPayoutsResponse) {
+	created := time.Unix(1540000000, 0).UTC()
+	txid := testutil.RandSHA256(t)
+
+	ps := []visor.Payout{
+		{
+			ID:       1,
+			WalletID: "foo.wlt",
+			Address:  testutil.MakeAddress(),
+			Coins:    1e6,
+			Status:   visor.PayoutStatusSent,
+			Txid:     txid,
+			Created:  created,
+			Updated:  created.Add(time.Minute),
+		},
+		{
+			ID:       2,
+			WalletID: "foo.wlt",
+			Address:  testutil.MakeAddress(),
+			Coins:    2100,
+			Status:   visor.PayoutStatusPending,
+			Error:    "balance is not sufficient",
+			Created:  created,
+			Updated:  created,
+		},
+	}
+
+	rsp, err := NewWalletPayoutsResponse("foo.wlt", ps)
+	require.NoError(t, err)
+
+	require.Equal(t, txid.Hex(), rsp.Payouts[0].Txid)
+	require.Equal(t, "1.000000", rsp.Payouts[0].Coins)
+	require.Empty(t, rsp.Payouts[1].Txid)
+	require.Equal(t, "0.002100", rsp.Payouts[1].Coins)
+
+	return ps, rsp
+}
+
+func checkWalletPayoutsResponse(t *testing.T, rr *httptest.ResponseRecorder, status int, httpResponse HTTPResponse) {
+	require.Equal(t, status, rr.Code, "got `%v` want `%v`", rr.Code, status)
+
+	var rsp ReceivedHTTPResponse
+	err := json.Unmarshal(rr.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+
+	require.Equal(t, httpResponse.Error, rsp.Error)
+
+	if rsp.Data == nil {
+		require.Nil(t, httpResponse.Data)
+	} else {
+		require.NotNil(t, httpResponse.Data)
+
+		var payoutsRsp WalletPayoutsResponse
+		err := json.Unmarshal(rsp.Data, &payoutsRsp)
+		require.NoError(t, err)
+
+		require.Equal(t, *httpResponse.Data.(*WalletPayoutsResponse), payoutsRsp)
+	}
+}
+
+func TestWalletPayouts(t *testing.T) {
+	ps, payoutsRsp := makeTestPayouts(t)
+
+	type gatewayReturnPair struct {
+		payouts []visor.Payout
+		err     error
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		query         url.Values
+		statuses      []visor.PayoutStatus
+		gatewayReturn *gatewayReturnPair
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:   "invalid status",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			query: url.Values{
+				"id":     []string{"foo.wlt"},
+				"status": []string{"pending,unknown"},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid status "unknown"`),
+		},
+		{
+			name:   "wallet does not exist",
+			method: http.MethodGet,
+			status: http.StatusNotFound,
+			query: url.Values{
+				"id": []string{"foo.wlt"},
+			},
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "wallet api disabled",
+			method: http.MethodGet,
+			status: http.StatusForbidden,
+			query: url.Values{
+				"id": []string{"foo.wlt"},
+			},
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:   "ok",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			query: url.Values{
+				"id": []string{"foo.wlt"},
+			},
+			gatewayReturn: &gatewayReturnPair{
+				payouts: ps,
+			},
+			httpResponse: HTTPResponse{
+				Data: payoutsRsp,
+			},
+		},
+		{
+			name:   "ok with status",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			query: url.Values{
+				"id":     []string{"foo.wlt"},
+				"status": []string{"sent, pending"},
+			},
+			statuses: []visor.PayoutStatus{visor.PayoutStatusSent, visor.PayoutStatusPending},
+			gatewayReturn: &gatewayReturnPair{
+				payouts: ps,
+			},
+			httpResponse: HTTPResponse{
+				Data: payoutsRsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayReturn != nil {
+				gateway.On("GetPayouts", tc.query.Get("id"), tc.statuses).Return(tc.gatewayReturn.payouts, tc.gatewayReturn.err)
+			}
+
+			endpoint := "/api/v2/wallet/payouts"
+			if len(tc.query) > 0 {
+				endpoint += "?" + tc.query.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			checkWalletPayoutsResponse(t, rr, tc.status, tc.httpResponse)
+		})
+	}
+}
+
+func TestWalletPayoutsAdd(t *testing.T) {
+	ps, payoutsRsp := makeTestPayouts(t)
+
+	type gatewayReturnPair struct {
+		payouts []visor.Payout
+		err     error
+	}
+
+	okReq := WalletPayoutsAddRequest{
+		ID: "foo.wlt",
+		Payouts: []PayoutRequest{
+			{
+				Address: ps[0].Address.String(),
+				Coins:   "1",
+			},
+			{
+				Address: ps[1].Address.String(),
+				Coins:   "0.0021",
+			},
+		},
+	}
+	okReqs := []visor.PayoutRequest{
+		{
+			Address: ps[0].Address,
+			Coins:   1e6,
+		},
+		{
+			Address: ps[1].Address,
+			Coins:   2100,
+		},
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		httpBody      string
+		gatewayReturn *gatewayReturnPair
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, okReq),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletPayoutsAddRequest{Payouts: okReq.Payouts}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "payouts missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletPayoutsAddRequest{ID: "foo.wlt"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "payouts is required"),
+		},
+		{
+			name:        "invalid address",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			httpBody: toJSON(t, WalletPayoutsAddRequest{
+				ID:      "foo.wlt",
+				Payouts: []PayoutRequest{{Address: "xxx", Coins: "1"}},
+			}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid address "xxx": Invalid address length`),
+		},
+		{
+			name:        "invalid coins",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			httpBody: toJSON(t, WalletPayoutsAddRequest{
+				ID:      "foo.wlt",
+				Payouts: []PayoutRequest{{Address: ps[0].Address.String(), Coins: "1.0000001"}},
+			}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid coins "1.0000001": Droplet string conversion failed: Too many decimal places`),
+		},
+		{
+			name:        "wallet encrypted",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			httpBody:    toJSON(t, okReq),
+			gatewayReturn: &gatewayReturnPair{
+				err: visor.ErrPayoutWalletEncrypted,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, visor.ErrPayoutWalletEncrypted.Error()),
+		},
+		{
+			name:        "wallet does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			httpBody:    toJSON(t, okReq),
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:        "other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			httpBody:    toJSON(t, okReq),
+			gatewayReturn: &gatewayReturnPair{
+				err: errors.New("db error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "db error"),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			httpBody:    toJSON(t, okReq),
+			gatewayReturn: &gatewayReturnPair{
+				payouts: ps,
+			},
+			httpResponse: HTTPResponse{
+				Data: payoutsRsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayReturn != nil {
+				gateway.On("EnqueuePayouts", "foo.wlt", okReqs).Return(tc.gatewayReturn.payouts, tc.gatewayReturn.err)
+			}
+
+			endpoint := "/api/v2/wallet/payouts/add"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			checkWalletPayoutsResponse(t, rr, tc.status, tc.httpResponse)
+		})
+	}
+}
+
+func TestWalletPayoutsCancel(t *testing.T) {
+	ps, payoutsRsp := makeTestPayouts(t)
+
+	type gatewayReturnPair struct {
+		payouts []visor.Payout
+		err     error
+	}
+
+	okReq := WalletPayoutsCancelRequest{
+		ID:  "foo.wlt",
+		IDs: []uint64{1, 2},
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		httpBody      string
+		gatewayReturn *gatewayReturnPair
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, okReq),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletPayoutsCancelRequest{IDs: okReq.IDs}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "ids missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletPayoutsCancelRequest{ID: "foo.wlt"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "ids is required"),
+		},
+		{
+			name:        "payout does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			httpBody:    toJSON(t, okReq),
+			gatewayReturn: &gatewayReturnPair{
+				err: visor.ErrPayoutNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, visor.ErrPayoutNotExist.Error()),
+		},
+		{
+			name:        "payout not pending",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			httpBody:    toJSON(t, okReq),
+			gatewayReturn: &gatewayReturnPair{
+				err: visor.ErrPayoutNotPending,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, visor.ErrPayoutNotPending.Error()),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			httpBody:    toJSON(t, okReq),
+			gatewayReturn: &gatewayReturnPair{
+				payouts: ps,
+			},
+			httpResponse: HTTPResponse{
+				Data: payoutsRsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayReturn != nil {
+				gateway.On("CancelPayouts", okReq.ID, okReq.IDs).Return(tc.gatewayReturn.payouts, tc.gatewayReturn.err)
+			}
+
+			endpoint := "/api/v2/wallet/payouts/cancel"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			checkWalletPayoutsResponse(t, rr, tc.status, tc.httpResponse)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// TransactionPreviewResponse is returned by POST /api/v2/transaction/preview
+type TransactionPreviewResponse struct {
+	Transaction        CreatedTransaction        `json:"transaction"`
+	EncodedTransaction string                    `json:"encoded_transaction"`
+	Fee                string                    `json:"fee"`
+	Size               uint32                    `json:"size"`
+	Change             *CreatedTransactionOutput `json:"change"`
+	Limits             TransactionPreviewLimits  `json:"limits"`
+}
+
+// TransactionPreviewLimits are the checks of a previewed transaction against each set of transaction verification parameters
+type TransactionPreviewLimits struct {
+	User        VerifyTxnCheck `json:"user"`
+	Unconfirmed VerifyTxnCheck `json:"unconfirmed"`
+	CreateBlock VerifyTxnCheck `json:"create_block"`
+}
+
+// VerifyTxnCheck is the result of checking a previewed transaction against transaction verification parameters
+type VerifyTxnCheck struct {
+	readable.VerifyTxn
+	RequiredFee string `json:"required_fee"`
+	SizeOK      bool   `json:"size_ok"`
+	FeeOK       bool   `json:"fee_ok"`
+	DecimalsOK  bool   `json:"decimals_ok"`
+	Error       string `json:"error,omitempty"`
+}
+
+// NewVerifyTxnCheck creates a VerifyTxnCheck
+func NewVerifyTxnCheck(c visor.VerifyTxnCheck) VerifyTxnCheck {
+	var errMsg string
+	if c.Err != nil {
+		errMsg = c.Err.Error()
+	}
+
+	return VerifyTxnCheck{
+		VerifyTxn:   readable.NewVerifyTxn(c.Params),
+		RequiredFee: fmt.Sprint(c.RequiredFee),
+		SizeOK:      c.SizeOK,
+		FeeOK:       c.FeeOK,
+		DecimalsOK:  c.DecimalsOK,
+		Error:       errMsg,
+	}
+}
+
+// NewTransactionPreviewResponse creates a TransactionPreviewResponse
+func NewTransactionPreviewResponse(p *visor.TransactionPreview) (*TransactionPreviewResponse, error) {
+	txnRsp, err := NewCreateTransactionResponse(&p.Transaction, p.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	var change *CreatedTransactionOutput
+	if p.Change != nil {
+		change, err = NewCreatedTransactionOutput(*p.Change, p.Transaction.Hash())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &TransactionPreviewResponse{
+		Transaction:        txnRsp.Transaction,
+		EncodedTransaction: txnRsp.EncodedTransaction,
+		Fee:                fmt.Sprint(p.Fee),
+		Size:               p.Size,
+		Change:             change,
+		Limits: TransactionPreviewLimits{
+			User:        NewVerifyTxnCheck(p.User),
+			Unconfirmed: NewVerifyTxnCheck(p.Unconfirmed),
+			CreateBlock: NewVerifyTxnCheck(p.CreateBlock),
+		},
+	}, nil
+}
+
+// transactionPreviewHandler creates an unsigned transaction from provided outputs and parameters like POST /api/v2/transaction,
+// and returns it with its coin hour fee and size checked against the user, unconfirmed pool and block creation
+// transaction verification parameters. Violations of these parameters are reported in the response instead of failing the request.
+// Method: POST
+// URI: /api/v2/transaction/preview
+// Args: JSON body, the same as POST /api/v2/transaction
+func transactionPreviewHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req createTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.Addresses) == 0 && len(req.UxOuts) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "one of addresses or unspents must not be empty")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		preview, err := gateway.PreviewTransaction(req.TransactionParams(), req.VisorParams())
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case blockdb.ErrUnspentNotExist,
+				transaction.Error,
+				visor.UserError,
+				wallet.Error,
+				visor.ErrTxnViolatesUserConstraint,
+				visor.ErrTxnViolatesHardConstraint:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			default:
+				switch err {
+				case fee.ErrTxnNoFee, fee.ErrTxnInsufficientCoinHours:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		rsp, err := NewTransactionPreviewResponse(preview)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, fmt.Sprintf("NewTransactionPreviewResponse failed: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rsp,
+		})
+	}
+}
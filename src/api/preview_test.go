@@ -0,0 +1,235 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+)
+
+func TestTransactionPreview(t *testing.T) {
+	changeAddress := makeAddress()
+	destinationAddress := makeAddress()
+
+	ux, _ := makeUxOutWithSecret(t)
+	input, err := visor.NewTransactionInput(ux, ux.Head.Time)
+	require.NoError(t, err)
+
+	var txn coin.Transaction
+	err = txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(destinationAddress, 1e6, 10)
+	require.NoError(t, err)
+	err = txn.PushOutput(changeAddress, ux.Body.Coins-1e6, 10)
+	require.NoError(t, err)
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	createBlockVerifyTxn := params.UserVerifyTxn
+	createBlockVerifyTxn.MaxTransactionSize = 100
+
+	preview := &visor.TransactionPreview{
+		Transaction: txn,
+		Inputs:      []visor.TransactionInput{input},
+		Change:      &txn.Out[1],
+		Fee:         input.CalculatedHours - 20,
+		Size:        183,
+		User: visor.VerifyTxnCheck{
+			Params:      params.UserVerifyTxn,
+			RequiredFee: 5,
+			SizeOK:      true,
+			FeeOK:       true,
+			DecimalsOK:  true,
+		},
+		Unconfirmed: visor.VerifyTxnCheck{
+			Params:      params.UserVerifyTxn,
+			RequiredFee: 5,
+			SizeOK:      true,
+			FeeOK:       true,
+			DecimalsOK:  true,
+		},
+		CreateBlock: visor.VerifyTxnCheck{
+			Params:      createBlockVerifyTxn,
+			RequiredFee: 5,
+			FeeOK:       true,
+			DecimalsOK:  true,
+			Err:         visor.ErrTxnExceedsMaxBlockSize,
+		},
+	}
+
+	previewRsp, err := NewTransactionPreviewResponse(preview)
+	require.NoError(t, err)
+	require.NotNil(t, previewRsp.Change)
+	require.Equal(t, changeAddress.String(), previewRsp.Change.Address)
+	require.Equal(t, previewRsp.Transaction.Out[1], *previewRsp.Change)
+	require.Equal(t, "", previewRsp.Limits.User.Error)
+	require.Equal(t, visor.ErrTxnExceedsMaxBlockSize.Error(), previewRsp.Limits.CreateBlock.Error)
+	require.Equal(t, uint32(100), previewRsp.Limits.CreateBlock.MaxTransactionSize)
+
+	noChangePreview := *preview
+	noChangePreview.Change = nil
+	noChangeRsp, err := NewTransactionPreviewResponse(&noChangePreview)
+	require.NoError(t, err)
+	require.Nil(t, noChangeRsp.Change)
+
+	validBody := rawCreateTxnRequest{
+		Addresses: []string{changeAddress.String()},
+		HoursSelection: rawHoursSelection{
+			Type:        transaction.HoursSelectionTypeAuto,
+			Mode:        transaction.HoursSelectionModeShare,
+			ShareFactor: newStrPtr("0.5"),
+		},
+		To: []rawReceiver{
+			{
+				Address: destinationAddress.String(),
+				Coins:   "1",
+			},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		method         string
+		contentType    string
+		body           rawCreateTxnRequest
+		status         int
+		gatewayPreview *visor.TransactionPreview
+		gatewayErr     error
+		httpResponse   HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			contentType:  ContentTypeJSON,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "415",
+			method:       http.MethodPost,
+			contentType:  ContentTypeForm,
+			status:       http.StatusUnsupportedMediaType,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, ""),
+		},
+		{
+			name:         "400 - missing hours selection type",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "missing hours_selection.type"),
+		},
+		{
+			name:        "400 - missing addresses and unspents",
+			method:      http.MethodPost,
+			contentType: ContentTypeJSON,
+			body: rawCreateTxnRequest{
+				HoursSelection: validBody.HoursSelection,
+				To:             validBody.To,
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "one of addresses or unspents must not be empty"),
+		},
+		{
+			name:         "400 - unspent does not exist",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusBadRequest,
+			gatewayErr:   blockdb.ErrUnspentNotExist{UxID: "foo"},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, blockdb.ErrUnspentNotExist{UxID: "foo"}.Error()),
+		},
+		{
+			name:         "400 - insufficient balance",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusBadRequest,
+			gatewayErr:   transaction.ErrInsufficientBalance,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, transaction.ErrInsufficientBalance.Error()),
+		},
+		{
+			name:         "400 - no fee",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusBadRequest,
+			gatewayErr:   fee.ErrTxnNoFee,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, fee.ErrTxnNoFee.Error()),
+		},
+		{
+			name:         "500 - other error",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusInternalServerError,
+			gatewayErr:   errors.New("db error"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "db error"),
+		},
+		{
+			name:           "200",
+			method:         http.MethodPost,
+			contentType:    ContentTypeJSON,
+			body:           validBody,
+			status:         http.StatusOK,
+			gatewayPreview: preview,
+			httpResponse: HTTPResponse{
+				Data: previewRsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+
+			httpBody := toJSON(t, tc.body)
+
+			var body createTransactionRequest
+			err := json.Unmarshal([]byte(httpBody), &body)
+			if err == nil && body.Validate() == nil {
+				gateway.On("PreviewTransaction", body.TransactionParams(), body.VisorParams()).Return(tc.gatewayPreview, tc.gatewayErr)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/transaction/preview", strings.NewReader(httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var previewRsp TransactionPreviewResponse
+				err := json.Unmarshal(rsp.Data, &previewRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, *tc.httpResponse.Data.(*TransactionPreviewResponse), previewRsp)
+			}
+		})
+	}
+}
@@ -268,6 +268,7 @@ type createTransactionRequest struct {
 	To                []receiver     `json:"to"`
 	UxOuts            []wh.SHA256    `json:"unspents,omitempty"`
 	Addresses         []wh.Address   `json:"addresses,omitempty"`
+	CoinSelection     string         `json:"coin_selection,omitempty"`
 }
 
 // hoursSelection defines options for hours distribution
@@ -340,6 +341,20 @@ func (r createTransactionRequest) Validate() error {
 		}
 	}
 
+	if r.CoinSelection != "" {
+		valid := false
+		for _, s := range transaction.CoinSelections {
+			if r.CoinSelection == s {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			return errors.New("invalid coin_selection")
+		}
+	}
+
 	if len(r.UxOuts) != 0 && len(r.Addresses) != 0 {
 		return errors.New("unspents and addresses cannot be combined")
 	}
@@ -442,6 +457,7 @@ func (r createTransactionRequest) TransactionParams() transaction.Params {
 		},
 		ChangeAddress: changeAddress,
 		To:            to,
+		CoinSelection: r.CoinSelection,
 	}
 }
 
@@ -548,22 +564,28 @@ func transactionHandlerV2(gateway Gatewayer) http.HandlerFunc {
 
 // walletCreateTransactionRequest is sent to POST /api/v1/wallet/transaction
 type walletCreateTransactionRequest struct {
-	Unsigned bool   `json:"unsigned"`
-	WalletID string `json:"wallet_id"`
-	Password string `json:"password"`
+	Unsigned         bool   `json:"unsigned"`
+	WalletID         string `json:"wallet_id"`
+	Password         string `json:"password"`
+	ApprovalPassword string `json:"approval_password,omitempty"`
 	createTransactionRequest
 }
 
+// VisorParams returns the visor.CreateTransactionParams of the request, with the spending policy approval password
+func (r walletCreateTransactionRequest) VisorParams() visor.CreateTransactionParams {
+	p := r.createTransactionRequest.VisorParams()
+	if r.ApprovalPassword != "" {
+		p.ApprovalPassword = []byte(r.ApprovalPassword)
+	}
+	return p
+}
+
 // Validate validates walletCreateTransactionRequest data
 func (r walletCreateTransactionRequest) Validate() error {
 	if r.WalletID == "" {
 		return errors.New("missing wallet_id")
 	}
 
-	if r.Unsigned && len(r.Password) != 0 {
-		return errors.New("password must not be used for unsigned transactions")
-	}
-
 	return r.createTransactionRequest.Validate()
 }
 
@@ -600,12 +622,19 @@ func walletCreateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 		var txn *coin.Transaction
 		var inputs []visor.TransactionInput
 		if req.Unsigned {
-			txn, inputs, err = gateway.WalletCreateTransaction(req.WalletID, req.TransactionParams(), req.VisorParams())
+			// The password of an unsigned transaction is only used to generate a fresh change address
+			var password []byte
+			if req.Password != "" {
+				password = []byte(req.Password)
+			}
+			txn, inputs, err = gateway.WalletCreateTransaction(req.WalletID, password, req.TransactionParams(), req.VisorParams())
 		} else {
 			txn, inputs, err = gateway.WalletCreateTransactionSigned(req.WalletID, []byte(req.Password), req.TransactionParams(), req.VisorParams())
 		}
 		if err != nil {
 			switch err.(type) {
+			case wallet.SpendingPolicyError:
+				wh.Error403(w, err.Error())
 			case wallet.Error:
 				switch err {
 				case wallet.ErrWalletAPIDisabled:
@@ -725,6 +754,8 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 		if err != nil {
 			var resp HTTPResponse
 			switch err.(type) {
+			case wallet.SpendingPolicyError:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, err.Error())
 			case wallet.Error:
 				switch err {
 				case wallet.ErrWalletNotExist:
@@ -42,6 +42,7 @@ type rawCreateTxnRequest struct {
 	ChangeAddress  string            `json:"change_address,omitempty"`
 	To             []rawReceiver     `json:"to"`
 	Password       string            `json:"password"`
+	CoinSelection  string            `json:"coin_selection,omitempty"`
 }
 
 func TestCreateTransaction(t *testing.T) {
@@ -236,6 +237,19 @@ func TestCreateTransaction(t *testing.T) {
 			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "hours_selection.share_factor cannot be more than 1"),
 		},
 
+		{
+			name:   "400 - invalid coin selection",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type: transaction.HoursSelectionTypeManual,
+				},
+				CoinSelection: "foo",
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid coin_selection"),
+		},
+
 		{
 			name:   "400 - empty sender address",
 			method: http.MethodPost,
@@ -723,6 +737,32 @@ func TestCreateTransaction(t *testing.T) {
 			},
 		},
 
+		{
+			name:   "200 - exact match coin selection",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type: transaction.HoursSelectionTypeManual,
+				},
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "100",
+						Hours:   "10",
+					},
+				},
+				ChangeAddress: changeAddress.String(),
+				Addresses:     []string{changeAddress.String()},
+				CoinSelection: transaction.CoinSelectionExactMatch,
+			},
+			status:                         http.StatusOK,
+			gatewayCreateTransactionResult: txn,
+			gatewayCreateTransactionInputs: inputs,
+			httpResponse: HTTPResponse{
+				Data: createTxnResponse,
+			},
+		},
+
 		{
 			name:                           "200 - manual type nonzero hours - csrf disabled",
 			method:                         http.MethodPost,
@@ -790,6 +830,15 @@ func TestCreateTransaction(t *testing.T) {
 			httpResponse:                NewHTTPErrorResponse(http.StatusBadRequest, "balance is not sufficient"),
 		},
 
+		{
+			name:                        "400 - no exact match",
+			method:                      http.MethodPost,
+			body:                        validBody,
+			status:                      http.StatusBadRequest,
+			gatewayCreateTransactionErr: transaction.ErrNoExactMatch,
+			httpResponse:                NewHTTPErrorResponse(http.StatusBadRequest, "no combination of unspents matches the spend amount exactly"),
+		},
+
 		{
 			name:         "400 - invalid json",
 			method:       http.MethodPost,
@@ -872,9 +921,10 @@ func TestCreateTransaction(t *testing.T) {
 func TestWalletCreateTransaction(t *testing.T) {
 	type rawWalletCreateTxnRequest struct {
 		rawCreateTxnRequest
-		WalletID string `json:"wallet_id"`
-		Password string `json:"password"`
-		Unsigned bool   `json:"unsigned"`
+		WalletID         string `json:"wallet_id"`
+		Password         string `json:"password"`
+		ApprovalPassword string `json:"approval_password,omitempty"`
+		Unsigned         bool   `json:"unsigned"`
 	}
 
 	changeAddress := testutil.MakeAddress()
@@ -938,6 +988,9 @@ func TestWalletCreateTransaction(t *testing.T) {
 		WalletID: "foo.wlt",
 	}
 
+	approvalBody := validBody
+	approvalBody.ApprovalPassword = "approve"
+
 	walletInput := testutil.RandSHA256(t)
 
 	type testCase struct {
@@ -1724,6 +1777,24 @@ func TestWalletCreateTransaction(t *testing.T) {
 			gatewayCreateTransactionErr: wallet.ErrWalletAPIDisabled,
 			err:                         "403 Forbidden",
 		},
+
+		{
+			name:                        "403 - spending policy",
+			method:                      http.MethodPost,
+			body:                        validBody,
+			status:                      http.StatusForbidden,
+			gatewayCreateTransactionErr: wallet.ErrSpendingPolicyDailyLimitExceeded,
+			err:                         "403 Forbidden - spending policy: transaction exceeds the 24 hour spend limit",
+		},
+
+		{
+			name:                        "400 - invalid approval password",
+			method:                      http.MethodPost,
+			body:                        approvalBody,
+			status:                      http.StatusBadRequest,
+			gatewayCreateTransactionErr: wallet.ErrInvalidApprovalPassword,
+			err:                         "400 Bad Request - invalid approval password",
+		},
 	}
 
 	cases := make([]testCase, len(baseCases)*2)
@@ -1754,8 +1825,9 @@ func TestWalletCreateTransaction(t *testing.T) {
 			Password: "foo",
 			Unsigned: true,
 		},
-		status: http.StatusBadRequest,
-		err:    "400 Bad Request - password must not be used for unsigned transactions",
+		status:                      http.StatusBadRequest,
+		gatewayCreateTransactionErr: visor.ErrUnsignedTxnPassword,
+		err:                         "400 Bad Request - password must not be used for unsigned transactions",
 	})
 
 	for _, tc := range cases {
@@ -1770,7 +1842,11 @@ func TestWalletCreateTransaction(t *testing.T) {
 			err = json.Unmarshal(serializedBody, &body)
 			if err == nil {
 				if tc.body.Unsigned {
-					x := gateway.On("WalletCreateTransaction", body.WalletID, body.TransactionParams(), body.VisorParams())
+					var password []byte
+					if body.Password != "" {
+						password = []byte(body.Password)
+					}
+					x := gateway.On("WalletCreateTransaction", body.WalletID, password, body.TransactionParams(), body.VisorParams())
 					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
 				} else {
 					x := gateway.On("WalletCreateTransactionSigned", body.WalletID, []byte(body.Password), body.TransactionParams(), body.VisorParams())
@@ -2059,6 +2135,15 @@ func TestWalletSignTransaction(t *testing.T) {
 			httpResponse:              NewHTTPErrorResponse(http.StatusForbidden, "wallet api is disabled"),
 		},
 
+		{
+			name:                      "403 - spending policy",
+			method:                    http.MethodPost,
+			body:                      validBody,
+			status:                    http.StatusForbidden,
+			gatewaySignTransactionErr: wallet.ErrSpendingPolicyApprovalRequired,
+			httpResponse:              NewHTTPErrorResponse(http.StatusForbidden, "spending policy: transaction requires a second approval"),
+		},
+
 		{
 			name:                         "200 - no password",
 			method:                       http.MethodPost,
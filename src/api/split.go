@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// walletSplitTransactionRequest is sent to POST /api/v2/wallet/transaction/split
+type walletSplitTransactionRequest struct {
+	WalletID         string `json:"wallet_id"`
+	Password         string `json:"password"`
+	ApprovalPassword string `json:"approval_password,omitempty"`
+	createTransactionRequest
+}
+
+// VisorParams returns the visor.CreateTransactionParams of the request, with the spending policy approval password
+func (r walletSplitTransactionRequest) VisorParams() visor.CreateTransactionParams {
+	p := r.createTransactionRequest.VisorParams()
+	if r.ApprovalPassword != "" {
+		p.ApprovalPassword = []byte(r.ApprovalPassword)
+	}
+	return p
+}
+
+// Validate validates walletSplitTransactionRequest data
+func (r walletSplitTransactionRequest) Validate() error {
+	if r.WalletID == "" {
+		return errors.New("missing wallet_id")
+	}
+
+	return r.createTransactionRequest.Validate()
+}
+
+// WalletSplitTransactionResponse is returned by POST /api/v2/wallet/transaction/split
+type WalletSplitTransactionResponse struct {
+	Transactions []CreateTransactionResponse `json:"transactions"`
+	Fee          string                      `json:"fee"`
+}
+
+// NewWalletSplitTransactionResponse creates a WalletSplitTransactionResponse
+func NewWalletSplitTransactionResponse(txns []coin.Transaction, inputs [][]visor.TransactionInput) (*WalletSplitTransactionResponse, error) {
+	rsp := &WalletSplitTransactionResponse{
+		Transactions: make([]CreateTransactionResponse, len(txns)),
+	}
+
+	for i := range txns {
+		txnRsp, err := NewCreateTransactionResponse(&txns[i], inputs[i])
+		if err != nil {
+			return nil, err
+		}
+		rsp.Transactions[i] = *txnRsp
+	}
+
+	totalFee, err := transactionsFee(txns, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp.Fee = fmt.Sprint(totalFee)
+
+	return rsp, nil
+}
+
+// walletSplitTransactionHandler creates the signed transactions of a spend.
+// If a single transaction would be larger than the maximum transaction size, the spend is split into
+// consolidation transactions, which send the chosen unspent outputs to a single output of the wallet each,
+// followed by the payment transaction, which spends the outputs of the consolidation transactions.
+// The transactions are not broadcast. The consolidation transactions must be confirmed before
+// the payment transaction can be injected.
+// URI: /api/v2/wallet/transaction/split
+// Method: POST
+// Args: JSON body, the same as POST /api/v1/wallet/transaction without unsigned
+func walletSplitTransactionHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req walletSplitTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.Password = ""
+			req.ApprovalPassword = ""
+		}()
+
+		if err := req.Validate(); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txns, inputs, err := gateway.WalletCreateSplitTransactions(req.WalletID, []byte(req.Password), req.TransactionParams(), req.VisorParams())
+		if err != nil {
+			writeHTTPResponse(w, walletSplitTransactionErrorResponse(err))
+			return
+		}
+
+		rsp, err := NewWalletSplitTransactionResponse(txns, inputs)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rsp,
+		})
+	}
+}
+
+func walletSplitTransactionErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.SpendingPolicyError:
+		return NewHTTPErrorResponse(http.StatusForbidden, err.Error())
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, "")
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	case blockdb.ErrUnspentNotExist,
+		transaction.Error,
+		visor.UserError,
+		visor.ErrTxnViolatesUserConstraint,
+		visor.ErrTxnViolatesHardConstraint,
+		visor.ErrTxnViolatesSoftConstraint:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		switch err {
+		case fee.ErrTxnNoFee,
+			fee.ErrTxnInsufficientCoinHours:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		default:
+			return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		}
+	}
+}
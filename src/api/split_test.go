@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletSplitTransaction(t *testing.T) {
+	type rawWalletSplitTxnRequest struct {
+		rawCreateTxnRequest
+		WalletID         string `json:"wallet_id"`
+		Password         string `json:"password"`
+		ApprovalPassword string `json:"approval_password,omitempty"`
+	}
+
+	makeSplitTxn := func(nInputs int, to cipher.Address) (coin.Transaction, []visor.TransactionInput) {
+		var txn coin.Transaction
+		var inputs []visor.TransactionInput
+		var keys []cipher.SecKey
+		var coins, hours uint64
+		for i := 0; i < nInputs; i++ {
+			ux, uxKey := makeUxOutWithSecret(t)
+			err := txn.PushInput(ux.Hash())
+			require.NoError(t, err)
+			keys = append(keys, uxKey)
+			coins += ux.Body.Coins
+			hours += ux.Body.Hours
+
+			input, err := visor.NewTransactionInput(ux, ux.Head.Time)
+			require.NoError(t, err)
+			inputs = append(inputs, input)
+		}
+		err := txn.PushOutput(to, coins, hours/2)
+		require.NoError(t, err)
+		txn.SignInputs(keys)
+		err = txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn, inputs
+	}
+
+	destinationAddress := makeAddress()
+	txn0, inputs0 := makeSplitTxn(3, makeAddress())
+	txn1, inputs1 := makeSplitTxn(1, destinationAddress)
+	txns := []coin.Transaction{txn0, txn1}
+	inputs := [][]visor.TransactionInput{inputs0, inputs1}
+
+	rsp, err := NewWalletSplitTransactionResponse(txns, inputs)
+	require.NoError(t, err)
+	require.Len(t, rsp.Transactions, 2)
+
+	validBody := rawWalletSplitTxnRequest{
+		rawCreateTxnRequest: rawCreateTxnRequest{
+			HoursSelection: rawHoursSelection{
+				Type:        transaction.HoursSelectionTypeAuto,
+				Mode:        transaction.HoursSelectionModeShare,
+				ShareFactor: newStrPtr("0.5"),
+			},
+			To: []rawReceiver{
+				{
+					Address: destinationAddress.String(),
+					Coins:   "100",
+				},
+			},
+		},
+		WalletID: "foo.wlt",
+		Password: "pwd",
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		contentType  string
+		body         rawWalletSplitTxnRequest
+		status       int
+		gatewayTxns  []coin.Transaction
+		gatewayIns   [][]visor.TransactionInput
+		gatewayErr   error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			contentType:  ContentTypeJSON,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "415",
+			method:       http.MethodPost,
+			contentType:  ContentTypeForm,
+			status:       http.StatusUnsupportedMediaType,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, ""),
+		},
+		{
+			name:         "400 - missing wallet_id",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         rawWalletSplitTxnRequest{rawCreateTxnRequest: validBody.rawCreateTxnRequest},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "missing wallet_id"),
+		},
+		{
+			name:        "400 - missing hours selection type",
+			method:      http.MethodPost,
+			contentType: ContentTypeJSON,
+			body: rawWalletSplitTxnRequest{
+				WalletID: "foo.wlt",
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "missing hours_selection.type"),
+		},
+		{
+			name:         "404 - wallet does not exist",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusNotFound,
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "400 - split too large",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusBadRequest,
+			gatewayErr:   transaction.ErrSplitTooLarge,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, transaction.ErrSplitTooLarge.Error()),
+		},
+		{
+			name:         "403 - spending policy",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusForbidden,
+			gatewayErr:   wallet.ErrSpendingPolicyMaxCoinsExceeded,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, wallet.ErrSpendingPolicyMaxCoinsExceeded.Error()),
+		},
+		{
+			name:         "500 - other error",
+			method:       http.MethodPost,
+			contentType:  ContentTypeJSON,
+			body:         validBody,
+			status:       http.StatusInternalServerError,
+			gatewayErr:   errors.New("db error"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "db error"),
+		},
+		{
+			name:        "200",
+			method:      http.MethodPost,
+			contentType: ContentTypeJSON,
+			body:        validBody,
+			status:      http.StatusOK,
+			gatewayTxns: txns,
+			gatewayIns:  inputs,
+			httpResponse: HTTPResponse{
+				Data: rsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+
+			httpBody := toJSON(t, tc.body)
+
+			var body walletSplitTransactionRequest
+			err := json.Unmarshal([]byte(httpBody), &body)
+			require.NoError(t, err)
+			if body.Validate() == nil {
+				gateway.On("WalletCreateSplitTransactions", body.WalletID, []byte(body.Password), body.TransactionParams(), body.VisorParams()).Return(tc.gatewayTxns, tc.gatewayIns, tc.gatewayErr)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/transaction/split", strings.NewReader(httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var splitRsp WalletSplitTransactionResponse
+				err := json.Unmarshal(rsp.Data, &splitRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, *tc.httpResponse.Data.(*WalletSplitTransactionResponse), splitRsp)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const (
+	// defaultSweepSeedAddresses is the number of addresses generated from a swept seed if not specified
+	defaultSweepSeedAddresses = 10
+	// maxSweepSeedAddresses is the maximum number of addresses generated from a swept seed
+	maxSweepSeedAddresses = 1000
+)
+
+// WalletSweepRequest is the request data for POST /api/v2/wallet/sweep
+type WalletSweepRequest struct {
+	ID        string   `json:"id"`
+	Password  string   `json:"password"`
+	Keys      []string `json:"keys,omitempty"`
+	Seed      string   `json:"seed,omitempty"`
+	Addresses uint64   `json:"addresses,omitempty"`
+}
+
+// walletSweepHandler moves all coins and coin hours owned by secret keys, or by the addresses of a seed,
+// to a new address of a wallet. The transaction is signed with the swept keys, injected and broadcast.
+// URI: /api/v2/wallet/sweep
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	password: wallet password, used to generate the new address of an encrypted wallet [optional]
+//	keys: secret keys to sweep, hex encoded or in the Bitcoin wallet import format (WIF) [optional]
+//	seed: deterministic wallet seed to sweep, can't be combined with keys [optional]
+//	addresses: number of addresses of the seed to sweep, defaults to 10 [optional]
+func walletSweepHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletSweepRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var keys []cipher.SecKey
+		defer func() {
+			for i := range req.Keys {
+				req.Keys[i] = ""
+			}
+			for i := range keys {
+				keys[i] = cipher.SecKey{}
+			}
+			req.Seed = ""
+			req.Password = ""
+		}()
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		switch {
+		case len(req.Keys) == 0 && req.Seed == "":
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "keys or seed is required")
+			writeHTTPResponse(w, resp)
+			return
+		case len(req.Keys) != 0 && req.Seed != "":
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "keys and seed cannot be combined")
+			writeHTTPResponse(w, resp)
+			return
+		case req.Seed == "" && req.Addresses != 0:
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "addresses can only be used with seed")
+			writeHTTPResponse(w, resp)
+			return
+		case req.Addresses > maxSweepSeedAddresses:
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("addresses must be at most %d", maxSweepSeedAddresses))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Seed != "" {
+			n := req.Addresses
+			if n == 0 {
+				n = defaultSweepSeedAddresses
+			}
+
+			var err error
+			keys, err = cipher.GenerateDeterministicKeyPairs([]byte(req.Seed), int(n))
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				writeHTTPResponse(w, resp)
+				return
+			}
+		} else {
+			keys = make([]cipher.SecKey, len(req.Keys))
+			for i, k := range req.Keys {
+				sk, err := wallet.ParseSecKey(k)
+				if err != nil {
+					resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("keys[%d]: %v", i, err))
+					writeHTTPResponse(w, resp)
+					return
+				}
+				keys[i] = sk
+			}
+		}
+
+		txn, inputs, err := gateway.CreateSweepTransaction(req.ID, []byte(req.Password), keys)
+		if err != nil {
+			writeHTTPResponse(w, walletSweepErrorResponse(err))
+			return
+		}
+
+		if err := gateway.InjectBroadcastTransaction(*txn); err != nil {
+			writeHTTPResponse(w, walletSweepErrorResponse(err))
+			return
+		}
+
+		rsp, err := NewCreateTransactionResponse(txn, inputs)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rsp,
+		})
+	}
+}
+
+func walletSweepErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, "")
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	case visor.UserError,
+		visor.ErrTxnViolatesUserConstraint,
+		visor.ErrTxnViolatesHardConstraint,
+		visor.ErrTxnViolatesSoftConstraint:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		switch {
+		case err == fee.ErrTxnNoFee:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		case daemon.IsBroadcastFailure(err):
+			return NewHTTPErrorResponse(http.StatusServiceUnavailable, err.Error())
+		default:
+			return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		}
+	}
+}
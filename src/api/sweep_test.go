@@ -0,0 +1,260 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletSweep(t *testing.T) {
+	_, sk := cipher.GenerateKeyPair()
+	seedKeys := cipher.MustGenerateDeterministicKeyPairs([]byte("seed"), defaultSweepSeedAddresses)
+
+	ux, uxKey := makeUxOutWithSecret(t)
+	txn := coin.Transaction{}
+	err := txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(makeAddress(), ux.Body.Coins, ux.Body.Hours/2)
+	require.NoError(t, err)
+	txn.SignInputs([]cipher.SecKey{uxKey})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	input, err := visor.NewTransactionInput(ux, ux.Head.Time)
+	require.NoError(t, err)
+	inputs := []visor.TransactionInput{input}
+
+	txnRsp, err := NewCreateTransactionResponse(&txn, inputs)
+	require.NoError(t, err)
+
+	type gatewayReturnPair struct {
+		txn       *coin.Transaction
+		inputs    []visor.TransactionInput
+		err       error
+		injectErr error
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           WalletSweepRequest
+		keys          []cipher.SecKey
+		gatewayReturn *gatewayReturnPair
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletSweepRequest{Keys: []string{sk.Hex()}},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "keys and seed missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletSweepRequest{ID: "foo.wlt"},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "keys or seed is required"),
+		},
+		{
+			name:         "keys and seed combined",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex()}, Seed: "seed"},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "keys and seed cannot be combined"),
+		},
+		{
+			name:         "addresses without seed",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex()}, Addresses: 2},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "addresses can only be used with seed"),
+		},
+		{
+			name:         "too many addresses",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletSweepRequest{ID: "foo.wlt", Seed: "seed", Addresses: maxSweepSeedAddresses + 1},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "addresses must be at most 1000"),
+		},
+		{
+			name:         "invalid key",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex(), "foo"}},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "keys[1]: "+wallet.ErrInvalidSecKeyFormat.Error()),
+		},
+		{
+			name:        "wallet does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req:         WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex()}},
+			keys:        []cipher.SecKey{sk},
+			gatewayReturn: &gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:        "no unspents",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req:         WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex()}},
+			keys:        []cipher.SecKey{sk},
+			gatewayReturn: &gatewayReturnPair{
+				err: visor.ErrSweepNoUnspents,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, visor.ErrSweepNoUnspents.Error()),
+		},
+		{
+			name:        "no coin hours",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req:         WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex()}},
+			keys:        []cipher.SecKey{sk},
+			gatewayReturn: &gatewayReturnPair{
+				err: fee.ErrTxnNoFee,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, fee.ErrTxnNoFee.Error()),
+		},
+		{
+			name:        "other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req:         WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex()}},
+			keys:        []cipher.SecKey{sk},
+			gatewayReturn: &gatewayReturnPair{
+				err: errors.New("db error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "db error"),
+		},
+		{
+			name:        "broadcast failure",
+			method:      http.MethodPost,
+			status:      http.StatusServiceUnavailable,
+			contentType: ContentTypeJSON,
+			req:         WalletSweepRequest{ID: "foo.wlt", Keys: []string{sk.Hex()}},
+			keys:        []cipher.SecKey{sk},
+			gatewayReturn: &gatewayReturnPair{
+				txn:       &txn,
+				inputs:    inputs,
+				injectErr: daemon.ErrNetworkingDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusServiceUnavailable, daemon.ErrNetworkingDisabled.Error()),
+		},
+		{
+			name:        "ok keys",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req:         WalletSweepRequest{ID: "foo.wlt", Password: "pwd", Keys: []string{sk.Hex()}},
+			keys:        []cipher.SecKey{sk},
+			gatewayReturn: &gatewayReturnPair{
+				txn:    &txn,
+				inputs: inputs,
+			},
+			httpResponse: HTTPResponse{
+				Data: txnRsp,
+			},
+		},
+		{
+			name:        "ok seed",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req:         WalletSweepRequest{ID: "foo.wlt", Password: "pwd", Seed: "seed"},
+			keys:        seedKeys,
+			gatewayReturn: &gatewayReturnPair{
+				txn:    &txn,
+				inputs: inputs,
+			},
+			httpResponse: HTTPResponse{
+				Data: txnRsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayReturn != nil {
+				gateway.On("CreateSweepTransaction", tc.req.ID, []byte(tc.req.Password), tc.keys).Return(tc.gatewayReturn.txn, tc.gatewayReturn.inputs, tc.gatewayReturn.err)
+				gateway.On("InjectBroadcastTransaction", mock.Anything).Return(tc.gatewayReturn.injectErr)
+			}
+
+			httpBody := toJSON(t, tc.req)
+			endpoint := "/api/v2/wallet/sweep"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var sweepRsp CreateTransactionResponse
+				err := json.Unmarshal(rsp.Data, &sweepRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, *tc.httpResponse.Data.(*CreateTransactionResponse), sweepRsp)
+			}
+
+			if tc.gatewayReturn != nil && tc.gatewayReturn.err == nil {
+				gateway.AssertCalled(t, "InjectBroadcastTransaction", txn)
+			}
+		})
+	}
+}
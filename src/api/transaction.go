@@ -17,6 +17,7 @@ import (
 	wh "github.com/skycoin/skycoin/src/util/http"
 	"github.com/skycoin/skycoin/src/util/mathutil"
 	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
 )
 
 // pendingTxnsHandler returns pending (unconfirmed) transactions
@@ -369,6 +370,8 @@ func injectTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 				visor.ErrTxnViolatesHardConstraint,
 				visor.ErrTxnViolatesSoftConstraint:
 				wh.Error400(w, err.Error())
+			case wallet.SpendingPolicyError:
+				wh.Error403(w, err.Error())
 			default:
 				if daemon.IsBroadcastFailure(err) {
 					wh.Error503(w, err.Error())
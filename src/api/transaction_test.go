@@ -24,6 +24,7 @@ import (
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
 )
 
 func createUnconfirmedTxn(t *testing.T) visor.UnconfirmedTransaction {
@@ -725,6 +726,15 @@ func TestInjectTransaction(t *testing.T) {
 			injectTransactionArg:   validTransaction,
 			injectTransactionError: errors.New("injectTransactionError"),
 		},
+		{
+			name:                   "403 - spending policy",
+			method:                 http.MethodPost,
+			status:                 http.StatusForbidden,
+			err:                    "403 Forbidden - spending policy: destination address is not allowed",
+			httpBody:               string(validTxnBodyJSON),
+			injectTransactionArg:   validTransaction,
+			injectTransactionError: wallet.ErrSpendingPolicyDestinationNotAllowed,
+		},
 		{
 			name:                 "200",
 			method:               http.MethodPost,
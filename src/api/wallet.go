@@ -4,14 +4,20 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
+	"time"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/kvstorage"
 	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/util/droplet"
 	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
 )
 
@@ -66,11 +72,50 @@ func NewWalletResponse(w *wallet.Wallet) (*WalletResponse, error) {
 		wr.Meta.Timestamp = tm
 	}
 
+	if w.Type() == wallet.WalletTypeBip44 {
+		bip44Coin, err := w.Bip44Coin()
+		if err != nil {
+			return nil, err
+		}
+		account, err := w.Bip44Account()
+		if err != nil {
+			return nil, err
+		}
+
+		bip44CoinNum := uint32(bip44Coin)
+		wr.Meta.Bip44Coin = &bip44CoinNum
+		wr.Meta.Bip44Account = &account
+	}
+
+	if w.Type() == wallet.WalletTypeXPub {
+		wr.Meta.XPub = w.XPub()
+	}
+
+	wr.Meta.FreshChange = w.FreshChange()
+	wr.Meta.SignerSocket = w.SignerSocket()
+
 	for _, e := range w.Entries {
-		wr.Entries = append(wr.Entries, readable.WalletEntry{
+		re := readable.WalletEntry{
 			Address: e.Address.String(),
 			Public:  e.Public.Hex(),
-		})
+			Label:   e.Label,
+			Created: e.Created,
+			Purpose: e.Purpose,
+			Tags:    e.Tags,
+		}
+
+		switch w.Type() {
+		case wallet.WalletTypeBip44:
+			childNumber := e.ChildNumber
+			change := e.Change
+			re.ChildNumber = &childNumber
+			re.Change = &change
+		case wallet.WalletTypeXPub:
+			childNumber := e.ChildNumber
+			re.ChildNumber = &childNumber
+		}
+
+		wr.Entries = append(wr.Entries, re)
 	}
 
 	return &wr, nil
@@ -181,11 +226,15 @@ func balanceHandler(gateway Gatewayer) http.HandlerFunc {
 // URI: /api/v1/wallet/create
 // Method: POST
 // Args:
-//     seed: wallet seed [required]
+//     seed: wallet seed [required, except for xpub wallets]
 //     label: wallet label [required]
+//     type: wallet type, "deterministic", "bip44" or "xpub" [optional, defaults to "deterministic"]
+//     seed-passphrase: bip39 seed passphrase [optional, only used by bip44 wallets]
+//     xpub: bip32 extended public key [required for xpub wallets]
 //     scan: the number of addresses to scan ahead for balances [optional, must be > 0]
 //     encrypt: bool value, whether encrypt the wallet [optional]
 //     password: password for encrypting wallet [optional, must be provided if "encrypt" is set]
+//     fresh-change: bool value, whether to send the change of each transaction to a new change address [optional]
 func walletCreateHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -193,21 +242,60 @@ func walletCreateHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		seed := r.FormValue("seed")
-		if seed == "" {
-			wh.Error400(w, "missing seed")
+		walletType := r.FormValue("type")
+		if walletType != "" && !wallet.IsValidWalletType(walletType) {
+			wh.Error400(w, "invalid wallet type")
 			return
 		}
 
+		seed := r.FormValue("seed")
+		xpub := r.FormValue("xpub")
+		switch walletType {
+		case wallet.WalletTypeXPub:
+			if xpub == "" {
+				wh.Error400(w, "missing xpub")
+				return
+			}
+			if seed != "" {
+				wh.Error400(w, "seed is not allowed for xpub wallets")
+				return
+			}
+		case wallet.WalletTypeCollection:
+			if seed != "" {
+				wh.Error400(w, "seed is not allowed for collection wallets")
+				return
+			}
+			if xpub != "" {
+				wh.Error400(w, "xpub is only allowed for xpub wallets")
+				return
+			}
+		default:
+			if seed == "" {
+				wh.Error400(w, "missing seed")
+				return
+			}
+			if xpub != "" {
+				wh.Error400(w, "xpub is only allowed for xpub wallets")
+				return
+			}
+		}
+
 		label := r.FormValue("label")
 		if label == "" {
 			wh.Error400(w, "missing label")
 			return
 		}
 
+		seedPassphrase := r.FormValue("seed-passphrase")
+		if seedPassphrase != "" && walletType != wallet.WalletTypeBip44 {
+			wh.Error400(w, "seed-passphrase is only supported for bip44 wallets")
+			return
+		}
+
 		password := r.FormValue("password")
 		defer func() {
 			password = ""
+			seedPassphrase = ""
 		}()
 
 		var encrypt bool
@@ -247,12 +335,27 @@ func walletCreateHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		var freshChange bool
+		freshChangeStr := r.FormValue("fresh-change")
+		if freshChangeStr != "" {
+			var err error
+			freshChange, err = strconv.ParseBool(freshChangeStr)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid fresh-change value: %v", err))
+				return
+			}
+		}
+
 		wlt, err := gateway.CreateWallet("", wallet.Options{
-			Seed:     seed,
-			Label:    label,
-			Encrypt:  encrypt,
-			Password: []byte(password),
-			ScanN:    scanN,
+			Seed:           seed,
+			Label:          label,
+			Type:           walletType,
+			SeedPassphrase: seedPassphrase,
+			XPub:           xpub,
+			Encrypt:        encrypt,
+			Password:       []byte(password),
+			ScanN:          scanN,
+			FreshChange:    freshChange,
 		}, gateway)
 		if err != nil {
 			switch err.(type) {
@@ -501,9 +604,26 @@ func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// WalletDirEvent is a change of the wallet directory applied by the wallet directory watcher
+type WalletDirEvent struct {
+	Time     int64  `json:"time"`
+	Filename string `json:"filename"`
+	Type     string `json:"type"`
+	Error    string `json:"error,omitempty"`
+}
+
+// WalletsWithEventsResponse is the response data for GET /api/v1/wallets?events=1
+type WalletsWithEventsResponse struct {
+	Wallets []*WalletResponse `json:"wallets"`
+	Events  []WalletDirEvent  `json:"events"`
+}
+
 // Returns all loaded wallets
 // URI: /api/v1/wallets
 // Method: GET
+// Args:
+//	events: [optional] if true, returns the wallets with the recent changes of the wallet directory
+//		applied by the wallet directory watcher (enabled by -watch-wallet-dir)
 func walletsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -511,6 +631,12 @@ func walletsHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		withEvents, err := parseBoolFlag(r.FormValue("events"))
+		if err != nil {
+			wh.Error400(w, "Invalid value for events")
+			return
+		}
+
 		wlts, err := gateway.GetWallets()
 		if err != nil {
 			switch err {
@@ -537,7 +663,33 @@ func walletsHandler(gateway Gatewayer) http.HandlerFunc {
 			return wrs[i].Meta.Timestamp < wrs[j].Meta.Timestamp
 		})
 
-		wh.SendJSONOr500(logger, w, wrs)
+		if !withEvents {
+			wh.SendJSONOr500(logger, w, wrs)
+			return
+		}
+
+		events, err := gateway.WalletDirEvents()
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		rsp := WalletsWithEventsResponse{
+			Wallets: wrs,
+			Events:  make([]WalletDirEvent, len(events)),
+		}
+		for i, e := range events {
+			rsp.Events[i] = WalletDirEvent{
+				Time:     e.Time.Unix(),
+				Filename: e.Filename,
+				Type:     string(e.Type),
+			}
+			if e.Err != nil {
+				rsp.Events[i].Error = e.Err.Error()
+			}
+		}
+
+		wh.SendJSONOr500(logger, w, rsp)
 	}
 }
 
@@ -676,6 +828,99 @@ func walletSeedHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// WalletSeedSharesRequest is the request data for POST /api/v2/wallet/seed/shares
+type WalletSeedSharesRequest struct {
+	ID        string `json:"id"`
+	Password  string `json:"password"`
+	Shares    int    `json:"shares"`
+	Threshold int    `json:"threshold"`
+}
+
+// WalletSeedSharesResponse is the response data for POST /api/v2/wallet/seed/shares
+type WalletSeedSharesResponse struct {
+	Shares []string `json:"shares"`
+}
+
+// URI: /api/v2/wallet/seed/shares
+// Method: POST
+// Args:
+//	id: wallet id
+//  password: wallet password
+//  shares: number of shares to split the seed into
+//  threshold: number of shares required to recover the seed
+// Splits the seed of an encrypted wallet into share mnemonics with Shamir's secret sharing,
+// any threshold of which recover the seed with POST /api/v2/wallet/recover.
+func walletSeedSharesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletSeedSharesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.Password = ""
+		}()
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Shares <= 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "shares is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Threshold <= 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "threshold is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		shares, err := gateway.GetWalletSeedShares(req.ID, []byte(req.Password), req.Shares, req.Threshold)
+		if err != nil {
+			var resp HTTPResponse
+			switch err {
+			case wallet.ErrWalletAPIDisabled, wallet.ErrSeedAPIDisabled:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+			case wallet.ErrWalletNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			default:
+				switch err.(type) {
+				case wallet.Error:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletSeedSharesResponse{
+				Shares: shares,
+			},
+		})
+	}
+}
+
 // VerifySeedRequest is the request data for POST /api/v2/wallet/seed/verify
 type VerifySeedRequest struct {
 	Seed string `json:"seed"`
@@ -754,6 +999,10 @@ func walletUnloadHandler(gateway Gatewayer) http.HandlerFunc {
 // Args:
 //     id: wallet id
 //     password: wallet password
+//     crypto_type: [optional] crypto type, defaults to the node's wallet crypto type
+//     argon2id_time: [optional] argon2id time parameter of the argon2id-chacha20poly1305 crypto type
+//     argon2id_memory: [optional] argon2id memory parameter in KiB of the argon2id-chacha20poly1305 crypto type
+//     argon2id_parallelism: [optional] argon2id parallelism parameter of the argon2id-chacha20poly1305 crypto type
 func walletEncryptHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -767,17 +1016,39 @@ func walletEncryptHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		var cryptoType wallet.CryptoType
+		if ct := r.FormValue("crypto_type"); ct != "" {
+			var err error
+			cryptoType, err = wallet.CryptoTypeFromString(ct)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid crypto_type: %v", err))
+				return
+			}
+		}
+
+		argon2idParams, err := parseArgon2idParams(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		if argon2idParams != nil && cryptoType != "" && cryptoType != wallet.CryptoTypeArgon2idChacha20poly1305 {
+			wh.Error400(w, "argon2id parameters can only be used with the argon2id-chacha20poly1305 crypto type")
+			return
+		}
+
 		password := r.FormValue("password")
 		defer func() {
 			password = ""
 		}()
 
-		wlt, err := gateway.EncryptWallet(id, []byte(password))
+		wlt, err := gateway.EncryptWallet(id, []byte(password), cryptoType, argon2idParams)
 		if err != nil {
 			switch err {
 			case wallet.ErrWalletEncrypted,
 				wallet.ErrMissingPassword,
-				wallet.ErrInvalidPassword:
+				wallet.ErrInvalidPassword,
+				wallet.ErrWalletTypeNotEncryptable:
 				wh.Error400(w, err.Error())
 			case wallet.ErrWalletAPIDisabled:
 				wh.Error403(w, "")
@@ -799,6 +1070,48 @@ func walletEncryptHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// parseArgon2idParams parses the argon2id_time, argon2id_memory and argon2id_parallelism form values.
+// They must be set together, and nil is returned if none are set
+func parseArgon2idParams(r *http.Request) (*wallet.Argon2idParams, error) {
+	t := r.FormValue("argon2id_time")
+	m := r.FormValue("argon2id_memory")
+	p := r.FormValue("argon2id_parallelism")
+	if t == "" && m == "" && p == "" {
+		return nil, nil
+	}
+
+	if t == "" || m == "" || p == "" {
+		return nil, errors.New("argon2id_time, argon2id_memory and argon2id_parallelism must be set together")
+	}
+
+	tm, err := strconv.ParseUint(t, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid argon2id_time value")
+	}
+
+	memory, err := strconv.ParseUint(m, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid argon2id_memory value")
+	}
+
+	parallelism, err := strconv.ParseUint(p, 10, 8)
+	if err != nil {
+		return nil, errors.New("invalid argon2id_parallelism value")
+	}
+
+	params := &wallet.Argon2idParams{
+		Time:        uint32(tm),
+		Memory:      uint32(memory),
+		Parallelism: uint8(parallelism),
+	}
+
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
 // Decrypts wallet
 // URI: /api/v1/wallet/decrypt
 // Method: POST
@@ -849,24 +1162,50 @@ func walletDecryptHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// maxGapLimit is the largest gap_limit accepted by POST /api/v2/wallet/recover
+const maxGapLimit = 1000
+
 // WalletRecoverRequest is the request data for POST /api/v2/wallet/recover
 type WalletRecoverRequest struct {
-	ID       string `json:"id"`
-	Seed     string `json:"seed"`
-	Password string `json:"password"`
+	ID             string   `json:"id"`
+	Seed           string   `json:"seed"`
+	SeedShares     []string `json:"seed_shares,omitempty"`
+	SeedPassphrase string   `json:"seed_passphrase"`
+	Password       string   `json:"password"`
+	GapLimit       uint64   `json:"gap_limit,omitempty"`
+}
+
+// WalletScanProgress is the result of the address history scan of an address chain
+type WalletScanProgress struct {
+	Chain   uint32 `json:"chain"`
+	Scanned uint64 `json:"scanned"`
+	Found   uint64 `json:"found"`
+}
+
+// WalletRecoverResponse is the response data for POST /api/v2/wallet/recover
+type WalletRecoverResponse struct {
+	WalletResponse
+	Scan []WalletScanProgress `json:"scan,omitempty"`
 }
 
 // URI: /api/v2/wallet/recover
 // Method: POST
 // Args:
 //	id: wallet id
-//  seed: wallet seed
+//  seed: wallet seed, required unless seed_shares is provided
+//  seed_shares: [optional] seed share mnemonics created by POST /api/v2/wallet/seed/shares, to recover the seed from
+//  seed_passphrase: [optional] bip39 seed passphrase, for bip44 wallets
 //  password: [optional] new password
+//  gap_limit: [optional] number of consecutive addresses without transaction history after which address discovery stops
 // Recovers an encrypted wallet by providing the seed.
 // The first address will be generated from seed and compared to the first address
 // of the specified wallet. If they match, the wallet will be regenerated
 // with an optional password.
-// If the wallet is not encrypted, an error is returned.
+// If gap_limit is provided, the addresses following the wallet's addresses are scanned with
+// the historydb address index, and the addresses up to the last one with transaction history are added.
+// Unencrypted wallets can only be recovered with a gap_limit, to discover their addresses.
+// If the wallet is not encrypted and no gap_limit is provided, an error is returned.
+// The result of the scan of each address chain is returned with the wallet.
 func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -894,7 +1233,20 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		if req.Seed == "" {
+		defer func() {
+			req.Seed = ""
+			req.SeedShares = nil
+			req.SeedPassphrase = ""
+			req.Password = ""
+		}()
+
+		if req.Seed != "" && len(req.SeedShares) != 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "seed and seed_shares can't be combined")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Seed == "" && len(req.SeedShares) == 0 {
 			resp := NewHTTPErrorResponse(http.StatusBadRequest, "seed is required")
 			writeHTTPResponse(w, resp)
 			return
@@ -906,17 +1258,35 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 		}
 
 		defer func() {
-			req.Seed = ""
-			req.Password = ""
 			password = nil
 		}()
 
-		wlt, err := gateway.RecoverWallet(req.ID, req.Seed, password)
+		if req.GapLimit > maxGapLimit {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("gap_limit must be <= %d", maxGapLimit))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var wlt *wallet.Wallet
+		var scans []wallet.ScanProgress
+		var err error
+		if len(req.SeedShares) != 0 {
+			wlt, scans, err = gateway.RecoverWalletFromShares(req.ID, req.SeedShares, req.SeedPassphrase, password, req.GapLimit, gateway)
+		} else {
+			wlt, scans, err = gateway.RecoverWalletScan(req.ID, req.Seed, req.SeedPassphrase, password, req.GapLimit, gateway)
+		}
 		if err != nil {
 			var resp HTTPResponse
 			switch err {
-			case wallet.ErrWalletNotEncrypted, wallet.ErrWalletRecoverSeedWrong:
+			case wallet.ErrWalletNotEncrypted,
+				wallet.ErrWalletRecoverSeedWrong,
+				wallet.ErrInvalidSeedShare,
+				wallet.ErrSeedSharesMismatch,
+				wallet.ErrNotEnoughSeedShares,
+				wallet.ErrDuplicateSeedShare:
 				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			case wallet.ErrWalletChanged:
+				resp = NewHTTPErrorResponse(http.StatusConflict, err.Error())
 			case wallet.ErrWalletNotExist:
 				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
 			case wallet.ErrWalletAPIDisabled:
@@ -928,6 +1298,105 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		wltRsp, err := NewWalletResponse(wlt)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		rlt := WalletRecoverResponse{
+			WalletResponse: *wltRsp,
+		}
+		for _, p := range scans {
+			rlt.Scan = append(rlt.Scan, WalletScanProgress{
+				Chain:   p.Chain,
+				Scanned: p.Scanned,
+				Found:   p.Found,
+			})
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rlt,
+		})
+	}
+}
+
+// WalletImportKeysRequest is the request data for POST /api/v2/wallet/keys/import
+type WalletImportKeysRequest struct {
+	ID       string   `json:"id"`
+	Keys     []string `json:"keys"`
+	Password string   `json:"password"`
+}
+
+// URI: /api/v2/wallet/keys/import
+// Method: POST
+// Args:
+//	id: wallet id
+//  keys: secret keys, hex encoded or in the Bitcoin wallet import format (WIF)
+//  password: [optional] wallet password, must be provided if the wallet is encrypted
+// Imports secret keys into a collection wallet.
+// No keys are imported if any of the keys is invalid or already in the wallet.
+func walletImportKeysHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletImportKeysRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		keys := make([]cipher.SecKey, len(req.Keys))
+		defer func() {
+			for i := range req.Keys {
+				req.Keys[i] = ""
+			}
+			for i := range keys {
+				keys[i] = cipher.SecKey{}
+			}
+			req.Password = ""
+		}()
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.Keys) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "keys is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		for i, k := range req.Keys {
+			sk, err := wallet.ParseSecKey(k)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("keys[%d]: %v", i, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			keys[i] = sk
+		}
+
+		wlt, err := gateway.ImportKeys(req.ID, []byte(req.Password), keys)
+		if err != nil {
+			writeHTTPResponse(w, walletKeysErrorResponse(err))
+			return
+		}
+
 		rlt, err := NewWalletResponse(wlt)
 		if err != nil {
 			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
@@ -940,3 +1409,1133 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 		})
 	}
 }
+
+// WalletRemoveKeysRequest is the request data for POST /api/v2/wallet/keys/remove
+type WalletRemoveKeysRequest struct {
+	ID        string   `json:"id"`
+	Addresses []string `json:"addresses"`
+	Password  string   `json:"password"`
+}
+
+// URI: /api/v2/wallet/keys/remove
+// Method: POST
+// Args:
+//	id: wallet id
+//  addresses: addresses of the keys to remove
+//  password: [optional] wallet password, must be provided if the wallet is encrypted
+// Removes the keys of the given addresses from a collection wallet.
+// No keys are removed if any of the addresses is not in the wallet.
+func walletRemoveKeysHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletRemoveKeysRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.Password = ""
+		}()
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.Addresses) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "addresses is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		addrs := make([]cipher.Address, len(req.Addresses))
+		for i, a := range req.Addresses {
+			addr, err := cipher.DecodeBase58Address(a)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address %q: %v", a, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			addrs[i] = addr
+		}
+
+		wlt, err := gateway.RemoveKeys(req.ID, []byte(req.Password), addrs)
+		if err != nil {
+			writeHTTPResponse(w, walletKeysErrorResponse(err))
+			return
+		}
+
+		rlt, err := NewWalletResponse(wlt)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rlt,
+		})
+	}
+}
+
+// walletKeysErrorResponse maps the errors of the collection wallet key endpoints to an HTTPResponse
+func walletKeysErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, "")
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// WalletChangePasswordRequest is the request data for POST /api/v2/wallet/password
+type WalletChangePasswordRequest struct {
+	ID          string `json:"id"`
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+	CryptoType  string `json:"crypto_type"`
+}
+
+// URI: /api/v2/wallet/password
+// Method: POST
+// Args:
+//	id: wallet id
+//  old_password: current wallet password
+//  new_password: new wallet password
+//  crypto_type: [optional] crypto type to re-encrypt the wallet with, defaults to the wallet's current crypto type
+// Changes the password of an encrypted wallet, optionally migrating it to another crypto type.
+// The previous wallet file is kept as a .bak file in the wallet directory.
+func walletChangePasswordHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletChangePasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.OldPassword = ""
+			req.NewPassword = ""
+		}()
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.OldPassword == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "old_password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.NewPassword == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "new_password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var cryptoType wallet.CryptoType
+		if req.CryptoType != "" {
+			var err error
+			cryptoType, err = wallet.CryptoTypeFromString(req.CryptoType)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid crypto_type: %v", err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		wlt, err := gateway.ChangePassword(req.ID, []byte(req.OldPassword), []byte(req.NewPassword), cryptoType)
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				}
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		rlt, err := NewWalletResponse(wlt)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rlt,
+		})
+	}
+}
+
+// WalletUpdateAddressRequest is the request data for POST /api/v2/wallet/address/update
+type WalletUpdateAddressRequest struct {
+	ID      string    `json:"id"`
+	Address string    `json:"address"`
+	Label   *string   `json:"label,omitempty"`
+	Purpose *string   `json:"purpose,omitempty"`
+	Tags    *[]string `json:"tags,omitempty"`
+}
+
+// URI: /api/v2/wallet/address/update
+// Method: POST
+// Args:
+//	id: wallet id
+//  address: wallet address to update
+//  label: [optional] address label, an empty string clears it
+//  purpose: [optional] "receive", "change" or an empty string
+//  tags: [optional] list of address tags, an empty list clears them
+// Updates the metadata of a wallet address. Omitted fields are left unchanged.
+// The metadata is not encrypted, so no password is required.
+func walletUpdateAddressHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletUpdateAddressRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Address == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "address is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(req.Address)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Label == nil && req.Purpose == nil && req.Tags == nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "at least one of label, purpose or tags is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wlt, err := gateway.UpdateAddressMeta(req.ID, addr, wallet.EntryMetaUpdate{
+			Label:   req.Label,
+			Purpose: req.Purpose,
+			Tags:    req.Tags,
+		})
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				}
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		rlt, err := NewWalletResponse(wlt)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rlt,
+		})
+	}
+}
+
+// WalletFrozenOutputsResponse is returned by the frozen outputs endpoints
+type WalletFrozenOutputsResponse struct {
+	UxOuts []string `json:"unspents"`
+}
+
+func newWalletFrozenOutputsResponse(w *wallet.Wallet) WalletFrozenOutputsResponse {
+	hashes := w.FrozenUxOuts()
+	uxOuts := make([]string, len(hashes))
+	for i, h := range hashes {
+		uxOuts[i] = h.Hex()
+	}
+	return WalletFrozenOutputsResponse{
+		UxOuts: uxOuts,
+	}
+}
+
+// walletFrozenOutputsHandler returns the outputs frozen in a wallet.
+// Frozen outputs are not spent when creating transactions, unless they are explicitly selected.
+// URI: /api/v2/wallet/outputs/frozen
+// Method: GET
+// Args:
+//	id: wallet id [required]
+func walletFrozenOutputsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wlt, err := gateway.GetWallet(wltID)
+		if err != nil {
+			writeHTTPResponse(w, frozenOutputsErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newWalletFrozenOutputsResponse(wlt),
+		})
+	}
+}
+
+// WalletFreezeOutputsRequest is the request data for POST /api/v2/wallet/outputs/freeze and /api/v2/wallet/outputs/unfreeze
+type WalletFreezeOutputsRequest struct {
+	ID     string   `json:"id"`
+	UxOuts []string `json:"unspents"`
+}
+
+// walletFreezeOutputsHandler freezes outputs in a wallet
+// URI: /api/v2/wallet/outputs/freeze
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	unspents: hashes of the outputs to freeze [required]
+func walletFreezeOutputsHandler(gateway Gatewayer) http.HandlerFunc {
+	return walletUpdateFrozenOutputsHandler(gateway.FreezeUxOuts)
+}
+
+// walletUnfreezeOutputsHandler unfreezes outputs in a wallet
+// URI: /api/v2/wallet/outputs/unfreeze
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	unspents: hashes of the outputs to unfreeze [required]
+func walletUnfreezeOutputsHandler(gateway Gatewayer) http.HandlerFunc {
+	return walletUpdateFrozenOutputsHandler(gateway.UnfreezeUxOuts)
+}
+
+func walletUpdateFrozenOutputsHandler(update func(wltID string, uxOuts []cipher.SHA256) (*wallet.Wallet, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletFreezeOutputsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.UxOuts) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "unspents is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		uxOuts := make([]cipher.SHA256, len(req.UxOuts))
+		for i, s := range req.UxOuts {
+			h, err := cipher.SHA256FromHex(s)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid unspent hash %q: %v", s, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			uxOuts[i] = h
+		}
+
+		wlt, err := update(req.ID, uxOuts)
+		if err != nil {
+			writeHTTPResponse(w, frozenOutputsErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newWalletFrozenOutputsResponse(wlt),
+		})
+	}
+}
+
+func frozenOutputsErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, "")
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// WalletOptionsRequest is the request data for POST /api/v2/wallet/options
+type WalletOptionsRequest struct {
+	ID           string  `json:"id"`
+	FreshChange  *bool   `json:"fresh_change,omitempty"`
+	SignerSocket *string `json:"signer_socket,omitempty"`
+}
+
+// walletOptionsHandler updates the options of a wallet. At least one option is required.
+// URI: /api/v2/wallet/options
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	fresh_change: whether to send the change of each transaction to a new change address [optional]
+//	signer_socket: unix socket path of an external signer, or an empty string to sign with the wallet's keys [optional]
+func walletOptionsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletOptionsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.FreshChange == nil && req.SignerSocket == nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "fresh_change or signer_socket is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var wlt *wallet.Wallet
+		var err error
+		if req.FreshChange != nil {
+			wlt, err = gateway.SetFreshChange(req.ID, *req.FreshChange)
+		}
+		if err == nil && req.SignerSocket != nil {
+			wlt, err = gateway.SetSignerSocket(req.ID, *req.SignerSocket)
+		}
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				}
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		rsp, err := NewWalletResponse(wlt)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rsp,
+		})
+	}
+}
+
+// WalletSpendingPolicyResponse is returned by GET /api/v2/wallet/policy and POST /api/v2/wallet/policy/update
+type WalletSpendingPolicyResponse struct {
+	ID                     string   `json:"id"`
+	MaxCoinsPerTransaction string   `json:"max_coins_per_transaction"`
+	DailyLimit             string   `json:"daily_limit"`
+	AllowedDestinations    []string `json:"allowed_destinations"`
+	ApprovalThreshold      string   `json:"approval_threshold"`
+	ApprovalPasswordSet    bool     `json:"approval_password_set"`
+	Spent24h               string   `json:"spent_24h"`
+}
+
+// NewWalletSpendingPolicyResponse creates a WalletSpendingPolicyResponse
+func NewWalletSpendingPolicyResponse(wltID string, p *wallet.SpendingPolicy) (*WalletSpendingPolicyResponse, error) {
+	maxCoins, err := droplet.ToString(p.MaxCoinsPerTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyLimit, err := droplet.ToString(p.DailyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, err := droplet.ToString(p.ApprovalThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	spent, err := droplet.ToString(p.Spent(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]string, len(p.AllowedDestinations))
+	for i, a := range p.AllowedDestinations {
+		allowed[i] = a.String()
+	}
+
+	return &WalletSpendingPolicyResponse{
+		ID:                     wltID,
+		MaxCoinsPerTransaction: maxCoins,
+		DailyLimit:             dailyLimit,
+		AllowedDestinations:    allowed,
+		ApprovalThreshold:      threshold,
+		ApprovalPasswordSet:    p.HasApprovalPassword(),
+		Spent24h:               spent,
+	}, nil
+}
+
+func spendingPolicyErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist,
+			wallet.ErrSpendingPolicyNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, err.Error())
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func writeSpendingPolicyResponse(w http.ResponseWriter, wltID string, p *wallet.SpendingPolicy) {
+	rsp, err := NewWalletSpendingPolicyResponse(wltID, p)
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: rsp,
+	})
+}
+
+// walletSpendingPolicyHandler returns the spending policy of a wallet
+// URI: /api/v2/wallet/policy
+// Method: GET
+// Args:
+//	id: wallet id [required]
+func walletSpendingPolicyHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		p, err := gateway.SpendingPolicy(wltID)
+		if err == nil && p == nil {
+			err = wallet.ErrSpendingPolicyNotExist
+		}
+		if err != nil {
+			writeHTTPResponse(w, spendingPolicyErrorResponse(err))
+			return
+		}
+
+		writeSpendingPolicyResponse(w, wltID, p)
+	}
+}
+
+// WalletUpdateSpendingPolicyRequest is the request data for POST /api/v2/wallet/policy/update
+type WalletUpdateSpendingPolicyRequest struct {
+	ID                     string   `json:"id"`
+	MaxCoinsPerTransaction string   `json:"max_coins_per_transaction,omitempty"`
+	DailyLimit             string   `json:"daily_limit,omitempty"`
+	AllowedDestinations    []string `json:"allowed_destinations,omitempty"`
+	ApprovalThreshold      string   `json:"approval_threshold,omitempty"`
+	ApprovalPassword       string   `json:"approval_password,omitempty"`
+	NewApprovalPassword    string   `json:"new_approval_password,omitempty"`
+}
+
+// SpendingLimits parses the spending limits of the request. Empty amounts are 0, which means no limit.
+func (r WalletUpdateSpendingPolicyRequest) SpendingLimits() (*wallet.SpendingLimits, error) {
+	parseCoins := func(name, s string) (uint64, error) {
+		if s == "" {
+			return 0, nil
+		}
+		coins, err := droplet.FromString(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		return coins, nil
+	}
+
+	var limits wallet.SpendingLimits
+	var err error
+	limits.MaxCoinsPerTransaction, err = parseCoins("max_coins_per_transaction", r.MaxCoinsPerTransaction)
+	if err != nil {
+		return nil, err
+	}
+	limits.DailyLimit, err = parseCoins("daily_limit", r.DailyLimit)
+	if err != nil {
+		return nil, err
+	}
+	limits.ApprovalThreshold, err = parseCoins("approval_threshold", r.ApprovalThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range r.AllowedDestinations {
+		a, err := cipher.DecodeBase58Address(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed_destinations address %q: %v", s, err)
+		}
+		limits.AllowedDestinations = append(limits.AllowedDestinations, a)
+	}
+
+	return &limits, nil
+}
+
+// walletUpdateSpendingPolicyHandler sets the spending policy of a wallet.
+// The spending policy is enforced before the wallet signs a transaction and when a transaction is injected.
+// URI: /api/v2/wallet/policy/update
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	max_coins_per_transaction: maximum coins sent by a transaction [optional]
+//	daily_limit: maximum coins sent in any 24 hour period [optional]
+//	allowed_destinations: addresses outside of the wallet that coins can be sent to [optional]
+//	approval_threshold: coins above which a transaction requires a second approval [optional]
+//	approval_password: current approval password, required if the policy has one [optional]
+//	new_approval_password: new approval password, required to set any limit [optional]
+func walletUpdateSpendingPolicyHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletUpdateSpendingPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		limits, err := req.SpendingLimits()
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		p, err := gateway.SetSpendingPolicy(req.ID, *limits, []byte(req.ApprovalPassword), []byte(req.NewApprovalPassword))
+		if err != nil {
+			writeHTTPResponse(w, spendingPolicyErrorResponse(err))
+			return
+		}
+
+		writeSpendingPolicyResponse(w, req.ID, p)
+	}
+}
+
+// WalletApproveTransactionRequest is the request data for POST /api/v2/wallet/policy/approve
+type WalletApproveTransactionRequest struct {
+	ID                 string `json:"id"`
+	EncodedTransaction string `json:"encoded_transaction"`
+	ApprovalPassword   string `json:"approval_password"`
+}
+
+// walletApproveTransactionHandler records the second approval of a transaction above the approval threshold
+// of a wallet's spending policy. The approval is valid for 24 hours. Returns the inner hash of the approved transaction.
+// URI: /api/v2/wallet/policy/approve
+// Method: POST
+// Args:
+//	id: wallet id [required]
+//	encoded_transaction: hex-encoded transaction, signed or unsigned [required]
+//	approval_password: approval password of the spending policy [required]
+func walletApproveTransactionHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletApproveTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.EncodedTransaction == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "encoded_transaction is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ApprovalPassword == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "approval_password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txn, err := decodeTxn(req.EncodedTransaction)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("Decode transaction failed: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := gateway.ApproveTransaction(req.ID, txn.InnerHash, []byte(req.ApprovalPassword)); err != nil {
+			writeHTTPResponse(w, spendingPolicyErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: txn.InnerHash.Hex(),
+		})
+	}
+}
+
+// WalletBundle is an encrypted wallet export bundle, see wallet.EncryptedBundle
+type WalletBundle struct {
+	Type       string `json:"type"`
+	Version    string `json:"version"`
+	CryptoType string `json:"crypto_type"`
+	Data       string `json:"data"`
+}
+
+// WalletExportRequest is the request data for POST /api/v2/wallet/export
+type WalletExportRequest struct {
+	IDs        []string `json:"ids"`
+	Password   string   `json:"password"`
+	CryptoType string   `json:"crypto_type"`
+}
+
+// URI: /api/v2/wallet/export
+// Method: POST
+// Args:
+//	ids: ids of the wallets to export [required]
+//	password: password to encrypt the bundle with [required]
+//	crypto_type: [optional] crypto type to encrypt the bundle with, defaults to scrypt-chacha20poly1305
+// Exports wallets and the transaction notes of their addresses to a password encrypted bundle,
+// which can be imported with POST /api/v2/wallet/import.
+// The wallets are copied unchanged, so encrypted wallets remain encrypted with their own password.
+func walletExportHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.Password = ""
+		}()
+
+		if len(req.IDs) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "ids is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Password == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		cryptoType := wallet.CryptoTypeScryptChacha20poly1305
+		if req.CryptoType != "" {
+			var err error
+			cryptoType, err = wallet.CryptoTypeFromString(req.CryptoType)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid crypto_type: %v", err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		b, err := gateway.ExportWallets(req.IDs)
+		if err != nil {
+			writeHTTPResponse(w, walletBundleErrorResponse(err))
+			return
+		}
+
+		b.Notes, err = walletBundleNotes(gateway, b)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		eb, err := b.Encrypt([]byte(req.Password), cryptoType)
+		if err != nil {
+			writeHTTPResponse(w, walletBundleErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletBundle{
+				Type:       eb.Type,
+				Version:    eb.Version,
+				CryptoType: string(eb.CryptoType),
+				Data:       eb.Data,
+			},
+		})
+	}
+}
+
+// walletBundleNotes returns the transaction notes of the transactions of the addresses of the bundle wallets.
+// No notes are returned if the storage API is disabled.
+func walletBundleNotes(gateway Gatewayer, b *wallet.Bundle) (map[string]string, error) {
+	notes, err := gateway.GetAllStorageValues(kvstorage.TypeTxIDNotes)
+	switch err {
+	case nil:
+	case kvstorage.ErrStorageAPIDisabled, kvstorage.ErrNoSuchStorage:
+		return nil, nil
+	default:
+		return nil, err
+	}
+
+	if len(notes) == 0 {
+		return nil, nil
+	}
+
+	var addrs []cipher.Address
+	for _, rw := range b.Wallets {
+		for _, e := range rw.Entries {
+			addr, err := cipher.DecodeBase58Address(e.Address)
+			if err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+
+	txns, err := gateway.GetTransactions([]visor.TxFilter{visor.NewAddrsFilter(addrs)})
+	if err != nil {
+		return nil, err
+	}
+
+	bundleNotes := make(map[string]string)
+	for _, txn := range txns {
+		txid := txn.Transaction.Hash().Hex()
+		if note, ok := notes[txid]; ok {
+			bundleNotes[txid] = note
+		}
+	}
+
+	if len(bundleNotes) == 0 {
+		return nil, nil
+	}
+
+	return bundleNotes, nil
+}
+
+// WalletImportRequest is the request data for POST /api/v2/wallet/import
+type WalletImportRequest struct {
+	Bundle   WalletBundle `json:"bundle"`
+	Password string       `json:"password"`
+}
+
+// ImportedWallet is the import result of a wallet of a bundle
+type ImportedWallet struct {
+	Filename    string `json:"filename"`
+	ImportedAs  string `json:"imported_as,omitempty"`
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+}
+
+// WalletImportResponse is the response data for POST /api/v2/wallet/import
+type WalletImportResponse struct {
+	Wallets          []ImportedWallet `json:"wallets"`
+	TransactionNotes int              `json:"transaction_notes"`
+}
+
+// URI: /api/v2/wallet/import
+// Method: POST
+// Args:
+//	bundle: bundle created by POST /api/v2/wallet/export [required]
+//	password: password of the bundle [required]
+// Imports the wallets and transaction notes of a bundle.
+// A wallet whose filename is already used is imported with a numeric suffix, e.g. foo_1.wlt.
+// A wallet with the same seed as a loaded wallet is not imported, and the loaded wallet is returned in duplicate_of.
+// Transaction notes are imported if the storage API is enabled, without overwriting existing notes.
+func walletImportHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletImportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.Password = ""
+		}()
+
+		if req.Password == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		eb := wallet.EncryptedBundle{
+			Type:       req.Bundle.Type,
+			Version:    req.Bundle.Version,
+			CryptoType: wallet.CryptoType(req.Bundle.CryptoType),
+			Data:       req.Bundle.Data,
+		}
+
+		b, err := eb.Decrypt([]byte(req.Password))
+		if err != nil {
+			writeHTTPResponse(w, walletBundleErrorResponse(err))
+			return
+		}
+
+		results, err := gateway.ImportWallets(b)
+		if err != nil {
+			writeHTTPResponse(w, walletBundleErrorResponse(err))
+			return
+		}
+
+		var rsp WalletImportResponse
+		for _, r := range results {
+			rsp.Wallets = append(rsp.Wallets, ImportedWallet{
+				Filename:    r.Filename,
+				ImportedAs:  r.ImportedAs,
+				DuplicateOf: r.DuplicateOf,
+			})
+		}
+
+		rsp.TransactionNotes, err = importWalletBundleNotes(gateway, b.Notes)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rsp,
+		})
+	}
+}
+
+// importWalletBundleNotes adds the transaction notes which don't exist yet, and returns the number of notes added.
+// No notes are added if the storage API is disabled.
+func importWalletBundleNotes(gateway Gatewayer, notes map[string]string) (int, error) {
+	if len(notes) == 0 {
+		return 0, nil
+	}
+
+	existing, err := gateway.GetAllStorageValues(kvstorage.TypeTxIDNotes)
+	switch err {
+	case nil:
+	case kvstorage.ErrStorageAPIDisabled, kvstorage.ErrNoSuchStorage:
+		return 0, nil
+	default:
+		return 0, err
+	}
+
+	var n int
+	for txid, note := range notes {
+		if _, ok := existing[txid]; ok {
+			continue
+		}
+
+		if err := gateway.AddStorageValue(kvstorage.TypeTxIDNotes, txid, note); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+func walletBundleErrorResponse(err error) HTTPResponse {
+	switch err {
+	case wallet.ErrWalletAPIDisabled, wallet.ErrSeedAPIDisabled:
+		return NewHTTPErrorResponse(http.StatusForbidden, "")
+	case wallet.ErrWalletNotExist:
+		return NewHTTPErrorResponse(http.StatusNotFound, "")
+	default:
+		switch err.(type) {
+		case wallet.Error:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		default:
+			return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		}
+	}
+}
@@ -10,14 +10,17 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"encoding/json"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/bip39"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/kvstorage"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/visor"
@@ -849,11 +852,15 @@ func TestWalletTransactionsHandler(t *testing.T) {
 func TestWalletCreateHandler(t *testing.T) {
 	entries, responseEntries := makeEntries([]byte("seed"), 5)
 	type httpBody struct {
-		Seed     string
-		Label    string
-		ScanN    string
-		Encrypt  bool
-		Password string
+		Seed           string
+		Label          string
+		Type           string
+		SeedPassphrase string
+		XPub           string
+		ScanN          string
+		Encrypt        bool
+		Password       string
+		FreshChange    string
 	}
 	tt := []struct {
 		name                      string
@@ -917,6 +924,120 @@ func TestWalletCreateHandler(t *testing.T) {
 			err:     "400 Bad Request - scan must be > 0",
 			wltName: "foo",
 		},
+		{
+			name:   "400 - invalid fresh-change value",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:        "foo",
+				Label:       "bar",
+				FreshChange: "foo",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - invalid fresh-change value: strconv.ParseBool: parsing \"foo\": invalid syntax",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - invalid wallet type",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:  "foo",
+				Label: "bar",
+				Type:  "footype",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - invalid wallet type",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - seed-passphrase for deterministic wallet",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:           "foo",
+				Label:          "bar",
+				SeedPassphrase: "baz",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - seed-passphrase is only supported for bip44 wallets",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - missing xpub",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeXPub,
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - missing xpub",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - seed for xpub wallet",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:  "foo",
+				Label: "bar",
+				Type:  wallet.WalletTypeXPub,
+				XPub:  "xpub",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - seed is not allowed for xpub wallets",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - seed for collection wallet",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:  "foo",
+				Label: "bar",
+				Type:  wallet.WalletTypeCollection,
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - seed is not allowed for collection wallets",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - xpub for collection wallet",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeCollection,
+				XPub:  "xpub",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - xpub is only allowed for xpub wallets",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - xpub for deterministic wallet",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:  "foo",
+				Label: "bar",
+				XPub:  "xpub",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - xpub is only allowed for xpub wallets",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - invalid bip44 seed",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:  "foo",
+				Label: "bar",
+				Type:  wallet.WalletTypeBip44,
+			},
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - bip44 wallet seed must be a valid bip39 mnemonic",
+			options: wallet.Options{
+				Label:    "bar",
+				Seed:     "foo",
+				Type:     wallet.WalletTypeBip44,
+				Password: []byte{},
+			},
+			gatewayCreateWalletErr: wallet.ErrInvalidBip44Seed,
+		},
 		{
 			name:   "400 - seed in use",
 			method: http.MethodPost,
@@ -1066,6 +1187,186 @@ func TestWalletCreateHandler(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "200 - OK - fresh change",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:        "foo",
+				Label:       "bar",
+				FreshChange: "true",
+			},
+			status:  http.StatusOK,
+			err:     "",
+			wltName: "filename",
+			options: wallet.Options{
+				Label:       "bar",
+				Seed:        "foo",
+				Password:    []byte{},
+				FreshChange: true,
+			},
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename":    "filename",
+					"label":       "bar",
+					"freshChange": "true",
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename:    "filename",
+					Label:       "bar",
+					FreshChange: true,
+				},
+			},
+		},
+		{
+			name:   "200 - OK - bip44",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:           "foo",
+				Label:          "bar",
+				Type:           wallet.WalletTypeBip44,
+				SeedPassphrase: "baz",
+			},
+			status:  http.StatusOK,
+			err:     "",
+			wltName: "filename",
+			options: wallet.Options{
+				Label:          "bar",
+				Seed:           "foo",
+				Type:           wallet.WalletTypeBip44,
+				SeedPassphrase: "baz",
+				Password:       []byte{},
+			},
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename":     "filename",
+					"type":         wallet.WalletTypeBip44,
+					"bip44Coin":    "8000",
+					"bip44Account": "0",
+				},
+				Entries: []wallet.Entry{
+					{
+						Address:     entries[0].Address,
+						Public:      entries[0].Public,
+						ChildNumber: 0,
+						Change:      0,
+					},
+					{
+						Address:     entries[1].Address,
+						Public:      entries[1].Public,
+						ChildNumber: 3,
+						Change:      1,
+					},
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename:     "filename",
+					Type:         wallet.WalletTypeBip44,
+					Bip44Coin:    newUint32Ptr(8000),
+					Bip44Account: newUint32Ptr(0),
+				},
+				Entries: []readable.WalletEntry{
+					{
+						Address:     responseEntries[0].Address,
+						Public:      responseEntries[0].Public,
+						ChildNumber: newUint32Ptr(0),
+						Change:      newUint32Ptr(0),
+					},
+					{
+						Address:     responseEntries[1].Address,
+						Public:      responseEntries[1].Public,
+						ChildNumber: newUint32Ptr(3),
+						Change:      newUint32Ptr(1),
+					},
+				},
+			},
+		},
+		{
+			name:   "200 - OK - xpub",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeXPub,
+				XPub:  "xpub",
+			},
+			status:  http.StatusOK,
+			err:     "",
+			wltName: "filename",
+			options: wallet.Options{
+				Label:    "bar",
+				Type:     wallet.WalletTypeXPub,
+				XPub:     "xpub",
+				Password: []byte{},
+			},
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename": "filename",
+					"type":     wallet.WalletTypeXPub,
+					"xpub":     "xpub",
+				},
+				Entries: []wallet.Entry{
+					{
+						Address:     entries[0].Address,
+						Public:      entries[0].Public,
+						ChildNumber: 0,
+					},
+					{
+						Address:     entries[1].Address,
+						Public:      entries[1].Public,
+						ChildNumber: 1,
+					},
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename: "filename",
+					Type:     wallet.WalletTypeXPub,
+					XPub:     "xpub",
+				},
+				Entries: []readable.WalletEntry{
+					{
+						Address:     responseEntries[0].Address,
+						Public:      responseEntries[0].Public,
+						ChildNumber: newUint32Ptr(0),
+					},
+					{
+						Address:     responseEntries[1].Address,
+						Public:      responseEntries[1].Public,
+						ChildNumber: newUint32Ptr(1),
+					},
+				},
+			},
+		},
+		{
+			name:   "200 - OK - collection",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeCollection,
+			},
+			status:  http.StatusOK,
+			err:     "",
+			wltName: "filename",
+			options: wallet.Options{
+				Label:    "bar",
+				Type:     wallet.WalletTypeCollection,
+				Password: []byte{},
+			},
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename": "filename",
+					"type":     wallet.WalletTypeCollection,
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename: "filename",
+					Type:     wallet.WalletTypeCollection,
+				},
+			},
+		},
 		{
 			name:   "400 Bad request - encrypt without password",
 			method: http.MethodPost,
@@ -1097,6 +1398,15 @@ func TestWalletCreateHandler(t *testing.T) {
 				if tc.body.Label != "" {
 					v.Add("label", tc.body.Label)
 				}
+				if tc.body.Type != "" {
+					v.Add("type", tc.body.Type)
+				}
+				if tc.body.SeedPassphrase != "" {
+					v.Add("seed-passphrase", tc.body.SeedPassphrase)
+				}
+				if tc.body.XPub != "" {
+					v.Add("xpub", tc.body.XPub)
+				}
 				if tc.body.ScanN != "" {
 					v.Add("scan", tc.body.ScanN)
 				}
@@ -1108,6 +1418,10 @@ func TestWalletCreateHandler(t *testing.T) {
 				if tc.body.Password != "" {
 					v.Add("password", tc.body.Password)
 				}
+
+				if tc.body.FreshChange != "" {
+					v.Add("fresh-change", tc.body.FreshChange)
+				}
 			}
 
 			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
@@ -1542,68 +1856,287 @@ func TestGetWalletSeed(t *testing.T) {
 	}
 }
 
-func TestWalletNewAddressesHandler(t *testing.T) {
-	type httpBody struct {
-		ID       string
-		Num      string
-		Password string
-	}
-	type Addresses struct {
-		Address []string `json:"addresses"`
+func TestWalletSeedShares(t *testing.T) {
+	type gatewayReturnPair struct {
+		shares []string
+		err    error
 	}
 
-	var responseAddresses = Addresses{}
-	var responseEmptyAddresses = Addresses{}
-
-	var emptyAddrs = make([]cipher.Address, 0)
-	var addrs = make([]cipher.Address, 3)
-
-	for i := 0; i < 3; i++ {
-		pub, _ := cipher.MustGenerateDeterministicKeyPair(cipher.RandByte(32))
-		addrs[i] = cipher.AddressFromPubKey(pub)
-		responseAddresses.Address = append(responseAddresses.Address, addrs[i].String())
-	}
+	shares := []string{"share one", "share two", "share three"}
 
-	tt := []struct {
-		name                      string
-		method                    string
-		body                      *httpBody
-		status                    int
-		err                       string
-		walletID                  string
-		n                         uint64
-		password                  string
-		gatewayNewAddressesResult []cipher.Address
-		gatewayNewAddressesErr    error
-		responseBody              Addresses
-		csrfDisabled              bool
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletSeedSharesRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn gatewayReturnPair
 	}{
 		{
-			name:   "405",
-			method: http.MethodGet,
-			status: http.StatusMethodNotAllowed,
-			err:    "405 Method Not Allowed",
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletSeedSharesRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
 		},
 		{
-			name:   "400 - missing wallet id",
-			method: http.MethodPost,
-			status: http.StatusBadRequest,
-			err:    "400 Bad Request - missing wallet id",
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletSeedSharesRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
 		},
 		{
-			name:   "400 - invalid num value",
-			method: http.MethodPost,
-			body: &httpBody{
-				ID:  "foo",
-				Num: "bar",
-			},
-			status: http.StatusBadRequest,
-			err:    "400 Bad Request - invalid num value",
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
 		},
 		{
-			name:   "400 - gateway.NewAddresses error",
-			method: http.MethodPost,
-			body: &httpBody{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletSeedSharesRequest{Password: "pwd", Shares: 3, Threshold: 2}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "shares missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletSeedSharesRequest{ID: "foo", Password: "pwd", Threshold: 2}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "shares is required"),
+		},
+		{
+			name:         "threshold missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletSeedSharesRequest{ID: "foo", Password: "pwd", Shares: 3}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "threshold is required"),
+		},
+		{
+			name:        "invalid threshold",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletSeedSharesRequest{
+				ID:        "foo",
+				Password:  "pwd",
+				Shares:    2,
+				Threshold: 3,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrInvalidSeedSharesThreshold,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInvalidSeedSharesThreshold.Error()),
+		},
+		{
+			name:        "invalid password",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletSeedSharesRequest{
+				ID:        "foo",
+				Password:  "pwd",
+				Shares:    3,
+				Threshold: 2,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrInvalidPassword,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInvalidPassword.Error()),
+		},
+		{
+			name:        "wallet does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req: &WalletSeedSharesRequest{
+				ID:        "foo",
+				Password:  "pwd",
+				Shares:    3,
+				Threshold: 2,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:        "seed api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req: &WalletSeedSharesRequest{
+				ID:        "foo",
+				Password:  "pwd",
+				Shares:    3,
+				Threshold: 2,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrSeedAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:        "wallet other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req: &WalletSeedSharesRequest{
+				ID:        "foo",
+				Password:  "pwd",
+				Shares:    3,
+				Threshold: 2,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: errors.New("wallet error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "wallet error"),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletSeedSharesRequest{
+				ID:        "foo",
+				Password:  "pwd",
+				Shares:    3,
+				Threshold: 2,
+			},
+			gatewayReturn: gatewayReturnPair{
+				shares: shares,
+			},
+			httpResponse: HTTPResponse{
+				Data: WalletSeedSharesResponse{
+					Shares: shares,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("GetWalletSeedShares", tc.req.ID, []byte(tc.req.Password), tc.req.Shares, tc.req.Threshold).Return(tc.gatewayReturn.shares, tc.gatewayReturn.err)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			endpoint := "/api/v2/wallet/seed/shares"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var sharesRsp WalletSeedSharesResponse
+				err := json.Unmarshal(rsp.Data, &sharesRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletSeedSharesResponse), sharesRsp)
+			}
+		})
+	}
+}
+
+func TestWalletNewAddressesHandler(t *testing.T) {
+	type httpBody struct {
+		ID       string
+		Num      string
+		Password string
+	}
+	type Addresses struct {
+		Address []string `json:"addresses"`
+	}
+
+	var responseAddresses = Addresses{}
+	var responseEmptyAddresses = Addresses{}
+
+	var emptyAddrs = make([]cipher.Address, 0)
+	var addrs = make([]cipher.Address, 3)
+
+	for i := 0; i < 3; i++ {
+		pub, _ := cipher.MustGenerateDeterministicKeyPair(cipher.RandByte(32))
+		addrs[i] = cipher.AddressFromPubKey(pub)
+		responseAddresses.Address = append(responseAddresses.Address, addrs[i].String())
+	}
+
+	tt := []struct {
+		name                      string
+		method                    string
+		body                      *httpBody
+		status                    int
+		err                       string
+		walletID                  string
+		n                         uint64
+		password                  string
+		gatewayNewAddressesResult []cipher.Address
+		gatewayNewAddressesErr    error
+		responseBody              Addresses
+		csrfDisabled              bool
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - missing wallet id",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - missing wallet id",
+		},
+		{
+			name:   "400 - invalid num value",
+			method: http.MethodPost,
+			body: &httpBody{
+				ID:  "foo",
+				Num: "bar",
+			},
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid num value",
+		},
+		{
+			name:   "400 - gateway.NewAddresses error",
+			method: http.MethodPost,
+			body: &httpBody{
 				ID:  "foo",
 				Num: "1",
 			},
@@ -2054,6 +2587,111 @@ func TestGetWallets(t *testing.T) {
 	}
 }
 
+func TestGetWalletsWithEvents(t *testing.T) {
+	w, err := wallet.NewWallet("foo.wlt", wallet.Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+
+	wr, err := NewWalletResponse(w)
+	require.NoError(t, err)
+
+	tm := time.Unix(1000, 0)
+
+	cases := []struct {
+		name              string
+		query             string
+		status            int
+		err               string
+		walletDirEvents   []wallet.WalletDirEvent
+		walletDirEventErr error
+		httpResponse      WalletsWithEventsResponse
+	}{
+		{
+			name:   "400 - invalid events",
+			query:  "events=foo",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid value for events",
+		},
+		{
+			name:              "500 - wallet dir events error",
+			query:             "events=1",
+			status:            http.StatusInternalServerError,
+			err:               "500 Internal Server Error - events failed",
+			walletDirEventErr: errors.New("events failed"),
+		},
+		{
+			name:   "200 - no events",
+			query:  "events=true",
+			status: http.StatusOK,
+			httpResponse: WalletsWithEventsResponse{
+				Wallets: []*WalletResponse{wr},
+				Events:  []WalletDirEvent{},
+			},
+		},
+		{
+			name:   "200",
+			query:  "events=1",
+			status: http.StatusOK,
+			walletDirEvents: []wallet.WalletDirEvent{
+				{
+					Time:     tm,
+					Filename: "foo.wlt",
+					Type:     wallet.WalletDirEventLoaded,
+				},
+				{
+					Time:     tm,
+					Filename: "bar.wlt",
+					Type:     wallet.WalletDirEventRejected,
+					Err:      errors.New("invalid wallet"),
+				},
+			},
+			httpResponse: WalletsWithEventsResponse{
+				Wallets: []*WalletResponse{wr},
+				Events: []WalletDirEvent{
+					{
+						Time:     1000,
+						Filename: "foo.wlt",
+						Type:     "loaded",
+					},
+					{
+						Time:     1000,
+						Filename: "bar.wlt",
+						Type:     "rejected",
+						Error:    "invalid wallet",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetWallets").Return(wallet.Wallets{"foo.wlt": w}, nil)
+			gateway.On("WalletDirEvents").Return(tc.walletDirEvents, tc.walletDirEventErr)
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/wallets?"+tc.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var rsp WalletsWithEventsResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rsp))
+			require.Equal(t, tc.httpResponse, rsp)
+		})
+	}
+}
+
 func TestWalletUnloadHandler(t *testing.T) {
 	tt := []struct {
 		name            string
@@ -2149,6 +2787,9 @@ func TestEncryptWallet(t *testing.T) {
 		method        string
 		wltID         string
 		password      string
+		cryptoType    string
+		argon2id      []string
+		gatewayParams *wallet.Argon2idParams
 		gatewayReturn gatewayReturnPair
 		status        int
 		expectWallet  WalletResponse
@@ -2181,20 +2822,126 @@ func TestEncryptWallet(t *testing.T) {
 			},
 		},
 		{
-			name:     "403 Forbidden",
-			method:   http.MethodPost,
-			wltID:    "wallet.wlt",
-			password: "pwd",
+			name:       "200 - OK, crypto type",
+			method:     http.MethodPost,
+			wltID:      "wallet.wlt",
+			password:   "pwd",
+			cryptoType: string(wallet.CryptoTypeArgon2idChacha20poly1305),
 			gatewayReturn: gatewayReturnPair{
-				err: wallet.ErrWalletAPIDisabled,
+				w: &wallet.Wallet{
+					Meta: map[string]string{
+						"filename":   "wallet.wlt",
+						"secrets":    "secrets",
+						"encrypted":  "true",
+						"cryptoType": string(wallet.CryptoTypeArgon2idChacha20poly1305),
+					},
+					Entries: cloneEntries(entries),
+				},
+			},
+			status: http.StatusOK,
+			expectWallet: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename:   "wallet.wlt",
+					Encrypted:  true,
+					CryptoType: string(wallet.CryptoTypeArgon2idChacha20poly1305),
+				},
+				Entries: responseEntries,
 			},
-			status:    http.StatusForbidden,
-			expectErr: "403 Forbidden",
 		},
 		{
-			name:      "405 Method Not Allowed",
-			method:    http.MethodGet,
-			wltID:     "wallet.wlt",
+			name:       "200 - OK, argon2id parameters",
+			method:     http.MethodPost,
+			wltID:      "wallet.wlt",
+			password:   "pwd",
+			cryptoType: string(wallet.CryptoTypeArgon2idChacha20poly1305),
+			argon2id:   []string{"2", "1024", "2"},
+			gatewayParams: &wallet.Argon2idParams{
+				Time:        2,
+				Memory:      1024,
+				Parallelism: 2,
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: &wallet.Wallet{
+					Meta: map[string]string{
+						"filename":   "wallet.wlt",
+						"secrets":    "secrets",
+						"encrypted":  "true",
+						"cryptoType": string(wallet.CryptoTypeArgon2idChacha20poly1305),
+					},
+					Entries: cloneEntries(entries),
+				},
+			},
+			status: http.StatusOK,
+			expectWallet: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename:   "wallet.wlt",
+					Encrypted:  true,
+					CryptoType: string(wallet.CryptoTypeArgon2idChacha20poly1305),
+				},
+				Entries: responseEntries,
+			},
+		},
+		{
+			name:      "400 - Partial argon2id parameters",
+			method:    http.MethodPost,
+			wltID:     "wallet.wlt",
+			password:  "pwd",
+			argon2id:  []string{"2", "", "2"},
+			status:    http.StatusBadRequest,
+			expectErr: "400 Bad Request - argon2id_time, argon2id_memory and argon2id_parallelism must be set together",
+		},
+		{
+			name:      "400 - Invalid argon2id parameter value",
+			method:    http.MethodPost,
+			wltID:     "wallet.wlt",
+			password:  "pwd",
+			argon2id:  []string{"2", "1024", "256"},
+			status:    http.StatusBadRequest,
+			expectErr: "400 Bad Request - invalid argon2id_parallelism value",
+		},
+		{
+			name:      "400 - Invalid argon2id parameters",
+			method:    http.MethodPost,
+			wltID:     "wallet.wlt",
+			password:  "pwd",
+			argon2id:  []string{"1", "8", "2"},
+			status:    http.StatusBadRequest,
+			expectErr: "400 Bad Request - invalid argon2id parameters: argon2id memory must be at least 8*parallelism KiB",
+		},
+		{
+			name:       "400 - argon2id parameters with another crypto type",
+			method:     http.MethodPost,
+			wltID:      "wallet.wlt",
+			password:   "pwd",
+			cryptoType: string(wallet.CryptoTypeScryptChacha20poly1305),
+			argon2id:   []string{"2", "1024", "2"},
+			status:     http.StatusBadRequest,
+			expectErr:  "400 Bad Request - argon2id parameters can only be used with the argon2id-chacha20poly1305 crypto type",
+		},
+		{
+			name:       "400 - Invalid Crypto Type",
+			method:     http.MethodPost,
+			wltID:      "wallet.wlt",
+			password:   "pwd",
+			cryptoType: "foo",
+			status:     http.StatusBadRequest,
+			expectErr:  "400 Bad Request - invalid crypto_type: unknown crypto type",
+		},
+		{
+			name:     "403 Forbidden",
+			method:   http.MethodPost,
+			wltID:    "wallet.wlt",
+			password: "pwd",
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			status:    http.StatusForbidden,
+			expectErr: "403 Forbidden",
+		},
+		{
+			name:      "405 Method Not Allowed",
+			method:    http.MethodGet,
+			wltID:     "wallet.wlt",
 			password:  "pwd",
 			status:    http.StatusMethodNotAllowed,
 			expectErr: "405 Method Not Allowed",
@@ -2255,12 +3002,20 @@ func TestEncryptWallet(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			gateway := &MockGatewayer{}
-			gateway.On("EncryptWallet", tc.wltID, []byte(tc.password)).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+			gateway.On("EncryptWallet", tc.wltID, []byte(tc.password), wallet.CryptoType(tc.cryptoType), tc.gatewayParams).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
 
 			endpoint := "/api/v1/wallet/encrypt"
 			v := url.Values{}
 			v.Add("id", tc.wltID)
 			v.Add("password", tc.password)
+			if tc.cryptoType != "" {
+				v.Add("crypto_type", tc.cryptoType)
+			}
+			if tc.argon2id != nil {
+				v.Add("argon2id_time", tc.argon2id[0])
+				v.Add("argon2id_memory", tc.argon2id[1])
+				v.Add("argon2id_parallelism", tc.argon2id[2])
+			}
 
 			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
 			require.NoError(t, err)
@@ -2497,6 +3252,10 @@ func makeEntries(seed []byte, n int) ([]wallet.Entry, []readable.WalletEntry) {
 	return entries, responseEntries
 }
 
+func newUint32Ptr(x uint32) *uint32 {
+	return &x
+}
+
 func cloneEntries(es []wallet.Entry) []wallet.Entry {
 	var entries []wallet.Entry
 	entries = append(entries, es...)
@@ -2505,8 +3264,9 @@ func cloneEntries(es []wallet.Entry) []wallet.Entry {
 
 func TestWalletRecover(t *testing.T) {
 	type gatewayReturnPair struct {
-		w   *wallet.Wallet
-		err error
+		w     *wallet.Wallet
+		scans []wallet.ScanProgress
+		err   error
 	}
 
 	okWalletUnencrypted, err := wallet.NewWallet("foo", wallet.Options{
@@ -2532,6 +3292,9 @@ func TestWalletRecover(t *testing.T) {
 	okWalletEncryptedResponse, err := NewWalletResponse(okWalletEncrypted)
 	require.NoError(t, err)
 
+	seedShares, err := wallet.SplitSeed("fooseed", 3, 2)
+	require.NoError(t, err)
+
 	cases := []struct {
 		name          string
 		method        string
@@ -2669,7 +3432,24 @@ func TestWalletRecover(t *testing.T) {
 				w: okWalletUnencrypted,
 			},
 			httpResponse: HTTPResponse{
-				Data: *okWalletUnencryptedResponse,
+				Data: WalletRecoverResponse{WalletResponse: *okWalletUnencryptedResponse},
+			},
+		},
+		{
+			name:        "ok, bip44 seed passphrase",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:             "foo",
+				Seed:           "fooseed",
+				SeedPassphrase: "foopassphrase",
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWalletUnencrypted,
+			},
+			httpResponse: HTTPResponse{
+				Data: WalletRecoverResponse{WalletResponse: *okWalletUnencryptedResponse},
 			},
 		},
 		{
@@ -2686,8 +3466,117 @@ func TestWalletRecover(t *testing.T) {
 				w: okWalletEncrypted,
 			},
 			httpResponse: HTTPResponse{
-				Data: *okWalletEncryptedResponse,
+				Data: WalletRecoverResponse{WalletResponse: *okWalletEncryptedResponse},
+			},
+		},
+		{
+			name:        "ok, gap limit",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:       "foo",
+				Seed:     "fooseed",
+				GapLimit: 20,
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWalletUnencrypted,
+				scans: []wallet.ScanProgress{
+					{
+						Chain:   0,
+						Scanned: 30,
+						Found:   10,
+					},
+				},
+			},
+			httpResponse: HTTPResponse{
+				Data: WalletRecoverResponse{
+					WalletResponse: *okWalletUnencryptedResponse,
+					Scan: []WalletScanProgress{
+						{
+							Chain:   0,
+							Scanned: 30,
+							Found:   10,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:        "wallet changed",
+			method:      http.MethodPost,
+			status:      http.StatusConflict,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:       "foo",
+				Seed:     "fooseed",
+				GapLimit: 20,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletChanged,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusConflict, wallet.ErrWalletChanged.Error()),
+		},
+		{
+			name:         "gap limit too large",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletRecoverRequest{ID: "foo", Seed: "fooseed", GapLimit: 1001}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "gap_limit must be <= 1000"),
+		},
+		{
+			name:        "ok, seed shares",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:         "foo",
+				SeedShares: seedShares[1:],
+				Password:   "foopassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWalletEncrypted,
+			},
+			httpResponse: HTTPResponse{
+				Data: WalletRecoverResponse{WalletResponse: *okWalletEncryptedResponse},
+			},
+		},
+		{
+			name:        "not enough seed shares",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:         "foo",
+				SeedShares: seedShares[:1],
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrNotEnoughSeedShares,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrNotEnoughSeedShares.Error()),
+		},
+		{
+			name:        "seed shares mismatch",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:         "foo",
+				SeedShares: seedShares,
 			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrSeedSharesMismatch,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrSeedSharesMismatch.Error()),
+		},
+		{
+			name:         "seed and seed shares",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletRecoverRequest{ID: "foo", Seed: "fooseed", SeedShares: seedShares}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "seed and seed_shares can't be combined"),
 		},
 	}
 
@@ -2699,7 +3588,11 @@ func TestWalletRecover(t *testing.T) {
 				if tc.req.Password != "" {
 					password = []byte(tc.req.Password)
 				}
-				gateway.On("RecoverWallet", tc.req.ID, tc.req.Seed, password).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+				if len(tc.req.SeedShares) != 0 {
+					gateway.On("RecoverWalletFromShares", tc.req.ID, tc.req.SeedShares, tc.req.SeedPassphrase, password, tc.req.GapLimit, gateway).Return(tc.gatewayReturn.w, tc.gatewayReturn.scans, tc.gatewayReturn.err)
+				} else {
+					gateway.On("RecoverWalletScan", tc.req.ID, tc.req.Seed, tc.req.SeedPassphrase, password, tc.req.GapLimit, gateway).Return(tc.gatewayReturn.w, tc.gatewayReturn.scans, tc.gatewayReturn.err)
+				}
 			}
 
 			if tc.httpBody == "" && tc.req != nil {
@@ -2741,12 +3634,2332 @@ func TestWalletRecover(t *testing.T) {
 			} else {
 				require.NotNil(t, tc.httpResponse.Data)
 
-				var wltRsp WalletResponse
+				var wltRsp WalletRecoverResponse
 				err := json.Unmarshal(rsp.Data, &wltRsp)
 				require.NoError(t, err)
 
-				require.Equal(t, tc.httpResponse.Data.(WalletResponse), wltRsp)
+				require.Equal(t, tc.httpResponse.Data.(WalletRecoverResponse), wltRsp)
 			}
 		})
 	}
 }
+
+func TestWalletChangePassword(t *testing.T) {
+	type gatewayReturnPair struct {
+		w   *wallet.Wallet
+		err error
+	}
+
+	okWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Coin:       wallet.CoinTypeSkycoin,
+		Label:      "foolabel",
+		Seed:       "fooseed",
+		Encrypt:    true,
+		Password:   []byte("newpassword"),
+		CryptoType: wallet.CryptoTypeScryptChacha20poly1305Insecure,
+		GenerateN:  10,
+	})
+	require.NoError(t, err)
+	okWalletResponse, err := NewWalletResponse(okWallet)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletChangePasswordRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn gatewayReturnPair
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletChangePasswordRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletChangePasswordRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:        "id missing",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:        "old password missing",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				NewPassword: "newpassword",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "old_password is required"),
+		},
+		{
+			name:        "new password missing",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "new_password is required"),
+		},
+		{
+			name:        "invalid crypto type",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+				CryptoType:  "foo",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid crypto_type: unknown crypto type"),
+		},
+		{
+			name:        "invalid password",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrInvalidPassword,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInvalidPassword.Error()),
+		},
+		{
+			name:        "wallet not encrypted",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotEncrypted,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrWalletNotEncrypted.Error()),
+		},
+		{
+			name:        "wallet does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "Not Found"),
+		},
+		{
+			name:        "wallet api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:        "wallet other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: errors.New("wallet error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "wallet error"),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: *okWalletResponse,
+			},
+		},
+		{
+			name:        "ok, crypto type",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletChangePasswordRequest{
+				ID:          "foo",
+				OldPassword: "oldpassword",
+				NewPassword: "newpassword",
+				CryptoType:  string(wallet.CryptoTypeScryptChacha20poly1305Insecure),
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: *okWalletResponse,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("ChangePassword", tc.req.ID, []byte(tc.req.OldPassword), []byte(tc.req.NewPassword), wallet.CryptoType(tc.req.CryptoType)).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			endpoint := "/api/v2/wallet/password"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var wltRsp WalletResponse
+				err := json.Unmarshal(rsp.Data, &wltRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletResponse), wltRsp)
+			}
+		})
+	}
+}
+
+func TestWalletUpdateAddress(t *testing.T) {
+	type gatewayReturnPair struct {
+		w   *wallet.Wallet
+		err error
+	}
+
+	okWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Coin:      wallet.CoinTypeSkycoin,
+		Label:     "foolabel",
+		Seed:      "fooseed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	addr := okWallet.Entries[1].SkycoinAddress()
+	label := "savings"
+	purpose := wallet.EntryPurposeChange
+	tags := []string{"cold", "exchange"}
+	err = okWallet.UpdateEntryMeta(addr, wallet.EntryMetaUpdate{
+		Label:   &label,
+		Purpose: &purpose,
+		Tags:    &tags,
+	})
+	require.NoError(t, err)
+
+	okWalletResponse, err := NewWalletResponse(okWallet)
+	require.NoError(t, err)
+	require.Equal(t, label, okWalletResponse.Entries[1].Label)
+	require.Equal(t, purpose, okWalletResponse.Entries[1].Purpose)
+	require.Equal(t, tags, okWalletResponse.Entries[1].Tags)
+	require.Equal(t, okWallet.Entries[1].Created, okWalletResponse.Entries[1].Created)
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletUpdateAddressRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn gatewayReturnPair
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateAddressRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletUpdateAddressRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateAddressRequest{Address: addr.String(), Label: &label}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "address missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateAddressRequest{ID: "foo", Label: &label}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address is required"),
+		},
+		{
+			name:         "invalid address",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateAddressRequest{ID: "foo", Address: "xxx", Label: &label}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:         "no fields to update",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateAddressRequest{ID: "foo", Address: addr.String()}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "at least one of label, purpose or tags is required"),
+		},
+		{
+			name:        "invalid purpose",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletUpdateAddressRequest{
+				ID:      "foo",
+				Address: addr.String(),
+				Purpose: &purpose,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrInvalidEntryPurpose,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInvalidEntryPurpose.Error()),
+		},
+		{
+			name:        "unknown address",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletUpdateAddressRequest{
+				ID:      "foo",
+				Address: addr.String(),
+				Label:   &label,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrUnknownAddress,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrUnknownAddress.Error()),
+		},
+		{
+			name:        "wallet does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req: &WalletUpdateAddressRequest{
+				ID:      "foo",
+				Address: addr.String(),
+				Label:   &label,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "Not Found"),
+		},
+		{
+			name:        "wallet api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req: &WalletUpdateAddressRequest{
+				ID:      "foo",
+				Address: addr.String(),
+				Label:   &label,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:        "wallet other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req: &WalletUpdateAddressRequest{
+				ID:      "foo",
+				Address: addr.String(),
+				Label:   &label,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: errors.New("wallet error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "wallet error"),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletUpdateAddressRequest{
+				ID:      "foo",
+				Address: addr.String(),
+				Label:   &label,
+				Purpose: &purpose,
+				Tags:    &tags,
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: *okWalletResponse,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("UpdateAddressMeta", tc.req.ID, addr, wallet.EntryMetaUpdate{
+					Label:   tc.req.Label,
+					Purpose: tc.req.Purpose,
+					Tags:    tc.req.Tags,
+				}).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			endpoint := "/api/v2/wallet/address/update"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var wltRsp WalletResponse
+				err := json.Unmarshal(rsp.Data, &wltRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletResponse), wltRsp)
+			}
+		})
+	}
+}
+
+func TestWalletFrozenOutputs(t *testing.T) {
+	okWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Coin: wallet.CoinTypeSkycoin,
+		Seed: "fooseed",
+	})
+	require.NoError(t, err)
+
+	h := testutil.RandSHA256(t)
+	okWallet.FreezeUxOuts([]cipher.SHA256{h})
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		id            string
+		httpResponse  HTTPResponse
+		gatewayWallet *wallet.Wallet
+		gatewayErr    error
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "wallet doesn't exist",
+			method:       http.MethodGet,
+			status:       http.StatusNotFound,
+			id:           "foo",
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "wallet api disabled",
+			method:       http.MethodGet,
+			status:       http.StatusForbidden,
+			id:           "foo",
+			gatewayErr:   wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:          "ok",
+			method:        http.MethodGet,
+			status:        http.StatusOK,
+			id:            "foo",
+			gatewayWallet: okWallet,
+			httpResponse: HTTPResponse{
+				Data: WalletFrozenOutputsResponse{
+					UxOuts: []string{h.Hex()},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetWallet", tc.id).Return(tc.gatewayWallet, tc.gatewayErr)
+
+			endpoint := "/api/v2/wallet/outputs/frozen"
+			if tc.id != "" {
+				endpoint += "?id=" + tc.id
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var frozenRsp WalletFrozenOutputsResponse
+				err := json.Unmarshal(rsp.Data, &frozenRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletFrozenOutputsResponse), frozenRsp)
+			}
+		})
+	}
+}
+
+func TestWalletFreezeOutputs(t *testing.T) {
+	type gatewayReturnPair struct {
+		w   *wallet.Wallet
+		err error
+	}
+
+	okWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Coin: wallet.CoinTypeSkycoin,
+		Seed: "fooseed",
+	})
+	require.NoError(t, err)
+
+	h := testutil.RandSHA256(t)
+	okWallet.FreezeUxOuts([]cipher.SHA256{h})
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletFreezeOutputsRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn gatewayReturnPair
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletFreezeOutputsRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletFreezeOutputsRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletFreezeOutputsRequest{UxOuts: []string{h.Hex()}}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "unspents missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletFreezeOutputsRequest{ID: "foo"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "unspents is required"),
+		},
+		{
+			name:         "invalid unspent hash",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletFreezeOutputsRequest{ID: "foo", UxOuts: []string{"xxx"}}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid unspent hash "xxx": encoding/hex: invalid byte: U+0078 'x'`),
+		},
+		{
+			name:        "wallet doesn't exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req: &WalletFreezeOutputsRequest{
+				ID:     "foo",
+				UxOuts: []string{h.Hex()},
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:        "wallet api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req: &WalletFreezeOutputsRequest{
+				ID:     "foo",
+				UxOuts: []string{h.Hex()},
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:        "wallet other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req: &WalletFreezeOutputsRequest{
+				ID:     "foo",
+				UxOuts: []string{h.Hex()},
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: errors.New("wallet error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "wallet error"),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletFreezeOutputsRequest{
+				ID:     "foo",
+				UxOuts: []string{h.Hex()},
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: WalletFrozenOutputsResponse{
+					UxOuts: []string{h.Hex()},
+				},
+			},
+		},
+	}
+
+	endpoints := map[string]string{
+		"FreezeUxOuts":   "/api/v2/wallet/outputs/freeze",
+		"UnfreezeUxOuts": "/api/v2/wallet/outputs/unfreeze",
+	}
+
+	for method, endpoint := range endpoints {
+		for _, tc := range cases {
+			t.Run(method+" "+tc.name, func(t *testing.T) {
+				gateway := &MockGatewayer{}
+				if tc.req != nil {
+					gateway.On(method, tc.req.ID, []cipher.SHA256{h}).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+				}
+
+				if tc.httpBody == "" && tc.req != nil {
+					tc.httpBody = toJSON(t, tc.req)
+				}
+
+				req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+				require.NoError(t, err)
+
+				req.Header.Set("Content-Type", tc.contentType)
+
+				setCSRFParameters(t, tokenValid, req)
+
+				rr := httptest.NewRecorder()
+
+				cfg := defaultMuxConfig()
+				cfg.disableCSRF = false
+
+				handler := newServerMux(cfg, gateway)
+				handler.ServeHTTP(rr, req)
+
+				status := rr.Code
+				require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+				var rsp ReceivedHTTPResponse
+				err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+				if rsp.Data == nil {
+					require.Nil(t, tc.httpResponse.Data)
+				} else {
+					require.NotNil(t, tc.httpResponse.Data)
+
+					var frozenRsp WalletFrozenOutputsResponse
+					err := json.Unmarshal(rsp.Data, &frozenRsp)
+					require.NoError(t, err)
+
+					require.Equal(t, tc.httpResponse.Data.(WalletFrozenOutputsResponse), frozenRsp)
+				}
+			})
+		}
+	}
+}
+
+func TestWalletOptions(t *testing.T) {
+	type gatewayReturnPair struct {
+		w   *wallet.Wallet
+		err error
+	}
+
+	okWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Coin:        wallet.CoinTypeSkycoin,
+		Seed:        "fooseed",
+		FreshChange: true,
+	})
+	require.NoError(t, err)
+
+	okWalletResponse, err := NewWalletResponse(okWallet)
+	require.NoError(t, err)
+	require.True(t, okWalletResponse.Meta.FreshChange)
+
+	signerWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Coin:         wallet.CoinTypeSkycoin,
+		Seed:         "fooseed",
+		FreshChange:  true,
+		SignerSocket: "/run/signer.sock",
+	})
+	require.NoError(t, err)
+
+	signerWalletResponse, err := NewWalletResponse(signerWallet)
+	require.NoError(t, err)
+	require.Equal(t, "/run/signer.sock", signerWalletResponse.Meta.SignerSocket)
+
+	freshChange := true
+	signerSocket := "/run/signer.sock"
+	invalidSignerSocket := "signer.sock"
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletOptionsRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn gatewayReturnPair
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletOptionsRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletOptionsRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletOptionsRequest{FreshChange: &freshChange}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "options missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletOptionsRequest{ID: "foo"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "fresh_change or signer_socket is required"),
+		},
+		{
+			name:        "wallet doesn't exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req: &WalletOptionsRequest{
+				ID:          "foo",
+				FreshChange: &freshChange,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:        "wallet api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req: &WalletOptionsRequest{
+				ID:          "foo",
+				FreshChange: &freshChange,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:        "collection wallet",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletOptionsRequest{
+				ID:          "foo",
+				FreshChange: &freshChange,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletCantGenerateAddresses,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrWalletCantGenerateAddresses.Error()),
+		},
+		{
+			name:        "wallet other error",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req: &WalletOptionsRequest{
+				ID:          "foo",
+				FreshChange: &freshChange,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: errors.New("wallet error"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "wallet error"),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletOptionsRequest{
+				ID:          "foo",
+				FreshChange: &freshChange,
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: *okWalletResponse,
+			},
+		},
+		{
+			name:        "invalid signer socket",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletOptionsRequest{
+				ID:           "foo",
+				SignerSocket: &invalidSignerSocket,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrInvalidSignerSocket,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInvalidSignerSocket.Error()),
+		},
+		{
+			name:        "ok, fresh change and signer socket",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletOptionsRequest{
+				ID:           "foo",
+				FreshChange:  &freshChange,
+				SignerSocket: &signerSocket,
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: signerWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: *signerWalletResponse,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil && tc.req.FreshChange != nil {
+				gateway.On("SetFreshChange", tc.req.ID, *tc.req.FreshChange).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+			}
+			if tc.req != nil && tc.req.SignerSocket != nil {
+				gateway.On("SetSignerSocket", tc.req.ID, *tc.req.SignerSocket).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/options", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var walletRsp WalletResponse
+				err := json.Unmarshal(rsp.Data, &walletRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletResponse), walletRsp)
+			}
+		})
+	}
+}
+
+func TestWalletImportKeys(t *testing.T) {
+	type gatewayReturnPair struct {
+		w   *wallet.Wallet
+		err error
+	}
+
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+
+	okWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Type:  wallet.WalletTypeCollection,
+		Label: "foolabel",
+	})
+	require.NoError(t, err)
+	_, err = okWallet.ImportSecKeys(secKeys)
+	require.NoError(t, err)
+	okWalletResponse, err := NewWalletResponse(okWallet)
+	require.NoError(t, err)
+
+	// One key is hex encoded and the other in the Bitcoin wallet import format
+	keys := []string{
+		secKeys[0].Hex(),
+		cipher.BitcoinWalletImportFormatFromSeckey(secKeys[1]),
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletImportKeysRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn gatewayReturnPair
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletImportKeysRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletImportKeysRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:   "id missing",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletImportKeysRequest{
+				Keys: keys,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:   "keys missing",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletImportKeysRequest{
+				ID: "foo",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "keys is required"),
+		},
+		{
+			name:   "invalid key",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletImportKeysRequest{
+				ID:   "foo",
+				Keys: []string{keys[0], "bar"},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "keys[1]: "+wallet.ErrInvalidSecKeyFormat.Error()),
+		},
+		{
+			name:   "wallet not collection",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletImportKeysRequest{
+				ID:   "foo",
+				Keys: keys,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotCollection,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrWalletNotCollection.Error()),
+		},
+		{
+			name:   "wallet does not exist",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &WalletImportKeysRequest{
+				ID:   "foo",
+				Keys: keys,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "wallet api disabled",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			req: &WalletImportKeysRequest{
+				ID:   "foo",
+				Keys: keys,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:   "other error",
+			method: http.MethodPost,
+			status: http.StatusInternalServerError,
+			req: &WalletImportKeysRequest{
+				ID:   "foo",
+				Keys: keys,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: errors.New("failed"),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "failed"),
+		},
+		{
+			name:   "ok",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &WalletImportKeysRequest{
+				ID:       "foo",
+				Keys:     keys,
+				Password: "foopassword",
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: *okWalletResponse,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("ImportKeys", tc.req.ID, []byte(tc.req.Password), secKeys).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			endpoint := "/api/v2/wallet/keys/import"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = ContentTypeJSON
+			}
+
+			req.Header.Set("Content-Type", contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var wltRsp WalletResponse
+				err := json.Unmarshal(rsp.Data, &wltRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletResponse), wltRsp)
+			}
+		})
+	}
+}
+
+func TestWalletRemoveKeys(t *testing.T) {
+	type gatewayReturnPair struct {
+		w   *wallet.Wallet
+		err error
+	}
+
+	okWallet, err := wallet.NewWallet("foo", wallet.Options{
+		Type:  wallet.WalletTypeCollection,
+		Label: "foolabel",
+	})
+	require.NoError(t, err)
+	okWalletResponse, err := NewWalletResponse(okWallet)
+	require.NoError(t, err)
+
+	addrs := []cipher.Address{testutil.MakeAddress(), testutil.MakeAddress()}
+	addrStrs := []string{addrs[0].String(), addrs[1].String()}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletRemoveKeysRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayReturn gatewayReturnPair
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletRemoveKeysRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletRemoveKeysRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:   "id missing",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletRemoveKeysRequest{
+				Addresses: addrStrs,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:   "addresses missing",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletRemoveKeysRequest{
+				ID: "foo",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "addresses is required"),
+		},
+		{
+			name:   "invalid address",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletRemoveKeysRequest{
+				ID:        "foo",
+				Addresses: []string{"bar"},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid address "bar": Invalid address length`),
+		},
+		{
+			name:   "wallet encrypted without password",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletRemoveKeysRequest{
+				ID:        "foo",
+				Addresses: addrStrs,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrMissingPassword,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrMissingPassword.Error()),
+		},
+		{
+			name:   "wallet does not exist",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &WalletRemoveKeysRequest{
+				ID:        "foo",
+				Addresses: addrStrs,
+			},
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "ok",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &WalletRemoveKeysRequest{
+				ID:        "foo",
+				Addresses: addrStrs,
+			},
+			gatewayReturn: gatewayReturnPair{
+				w: okWallet,
+			},
+			httpResponse: HTTPResponse{
+				Data: *okWalletResponse,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("RemoveKeys", tc.req.ID, []byte(tc.req.Password), addrs).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			endpoint := "/api/v2/wallet/keys/remove"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = ContentTypeJSON
+			}
+
+			req.Header.Set("Content-Type", contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var wltRsp WalletResponse
+				err := json.Unmarshal(rsp.Data, &wltRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletResponse), wltRsp)
+			}
+		})
+	}
+}
+
+func TestWalletSpendingPolicy(t *testing.T) {
+	dest := testutil.MakeAddress()
+	policy := &wallet.SpendingPolicy{
+		SpendingLimits: wallet.SpendingLimits{
+			MaxCoinsPerTransaction: 10e6,
+			DailyLimit:             100e6,
+			AllowedDestinations:    []cipher.Address{dest},
+		},
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		id            string
+		status        int
+		httpResponse  HTTPResponse
+		gatewayPolicy *wallet.SpendingPolicy
+		gatewayErr    error
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "wallet doesn't exist",
+			method:       http.MethodGet,
+			id:           "foo.wlt",
+			status:       http.StatusNotFound,
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "wallet doesn't exist"),
+		},
+		{
+			name:         "no spending policy",
+			method:       http.MethodGet,
+			id:           "foo.wlt",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "wallet has no spending policy"),
+		},
+		{
+			name:         "wallet api disabled",
+			method:       http.MethodGet,
+			id:           "foo.wlt",
+			status:       http.StatusForbidden,
+			gatewayErr:   wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:         "other error",
+			method:       http.MethodGet,
+			id:           "foo.wlt",
+			status:       http.StatusInternalServerError,
+			gatewayErr:   errors.New("policy error"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "policy error"),
+		},
+		{
+			name:          "ok",
+			method:        http.MethodGet,
+			id:            "foo.wlt",
+			status:        http.StatusOK,
+			gatewayPolicy: policy,
+			httpResponse: HTTPResponse{
+				Data: WalletSpendingPolicyResponse{
+					ID:                     "foo.wlt",
+					MaxCoinsPerTransaction: "10.000000",
+					DailyLimit:             "100.000000",
+					AllowedDestinations:    []string{dest.String()},
+					ApprovalThreshold:      "0.000000",
+					ApprovalPasswordSet:    false,
+					Spent24h:               "0.000000",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("SpendingPolicy", tc.id).Return(tc.gatewayPolicy, tc.gatewayErr)
+
+			endpoint := "/api/v2/wallet/policy"
+			if tc.id != "" {
+				endpoint += "?id=" + tc.id
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var policyRsp WalletSpendingPolicyResponse
+				err := json.Unmarshal(rsp.Data, &policyRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletSpendingPolicyResponse), policyRsp)
+			}
+		})
+	}
+}
+
+func TestWalletUpdateSpendingPolicy(t *testing.T) {
+	dest := testutil.MakeAddress()
+	limits := wallet.SpendingLimits{
+		MaxCoinsPerTransaction: 10e6,
+		DailyLimit:             100e6,
+		AllowedDestinations:    []cipher.Address{dest},
+	}
+	policy := &wallet.SpendingPolicy{
+		SpendingLimits: limits,
+	}
+
+	validReq := &WalletUpdateSpendingPolicyRequest{
+		ID:                     "foo.wlt",
+		MaxCoinsPerTransaction: "10",
+		DailyLimit:             "100",
+		AllowedDestinations:    []string{dest.String()},
+		ApprovalPassword:       "approve",
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		req           *WalletUpdateSpendingPolicyRequest
+		httpBody      string
+		httpResponse  HTTPResponse
+		gatewayPolicy *wallet.SpendingPolicy
+		gatewayErr    error
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateSpendingPolicyRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletUpdateSpendingPolicyRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateSpendingPolicyRequest{DailyLimit: "100"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "invalid daily_limit",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateSpendingPolicyRequest{ID: "foo.wlt", DailyLimit: "0.0000001"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid daily_limit: Droplet string conversion failed: Too many decimal places"),
+		},
+		{
+			name:         "invalid allowed_destinations",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletUpdateSpendingPolicyRequest{ID: "foo.wlt", AllowedDestinations: []string{"xxx"}}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid allowed_destinations address "xxx": Invalid address length`),
+		},
+		{
+			name:         "wallet doesn't exist",
+			method:       http.MethodPost,
+			status:       http.StatusNotFound,
+			contentType:  ContentTypeJSON,
+			req:          validReq,
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "wallet doesn't exist"),
+		},
+		{
+			name:         "invalid approval password",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          validReq,
+			gatewayErr:   wallet.ErrInvalidApprovalPassword,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid approval password"),
+		},
+		{
+			name:          "ok",
+			method:        http.MethodPost,
+			status:        http.StatusOK,
+			contentType:   ContentTypeJSON,
+			req:           validReq,
+			gatewayPolicy: policy,
+			httpResponse: HTTPResponse{
+				Data: WalletSpendingPolicyResponse{
+					ID:                     "foo.wlt",
+					MaxCoinsPerTransaction: "10.000000",
+					DailyLimit:             "100.000000",
+					AllowedDestinations:    []string{dest.String()},
+					ApprovalThreshold:      "0.000000",
+					Spent24h:               "0.000000",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("SetSpendingPolicy", tc.req.ID, limits, []byte(tc.req.ApprovalPassword), []byte(tc.req.NewApprovalPassword)).Return(tc.gatewayPolicy, tc.gatewayErr)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/policy/update", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var policyRsp WalletSpendingPolicyResponse
+				err := json.Unmarshal(rsp.Data, &policyRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletSpendingPolicyResponse), policyRsp)
+			}
+		})
+	}
+}
+
+func TestWalletApproveTransaction(t *testing.T) {
+	txn := makeTransaction(t)
+
+	validReq := &WalletApproveTransactionRequest{
+		ID:                 "foo.wlt",
+		EncodedTransaction: txn.MustSerializeHex(),
+		ApprovalPassword:   "approve",
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		contentType  string
+		req          *WalletApproveTransactionRequest
+		httpBody     string
+		httpResponse HTTPResponse
+		gatewayErr   error
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletApproveTransactionRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "wrong content-type",
+			method:       http.MethodPost,
+			status:       http.StatusUnsupportedMediaType,
+			contentType:  ContentTypeForm,
+			httpBody:     toJSON(t, WalletApproveTransactionRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletApproveTransactionRequest{EncodedTransaction: "aa", ApprovalPassword: "approve"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:         "encoded_transaction missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletApproveTransactionRequest{ID: "foo.wlt", ApprovalPassword: "approve"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "encoded_transaction is required"),
+		},
+		{
+			name:         "approval_password missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletApproveTransactionRequest{ID: "foo.wlt", EncodedTransaction: "aa"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "approval_password is required"),
+		},
+		{
+			name:         "invalid encoded_transaction",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			httpBody:     toJSON(t, WalletApproveTransactionRequest{ID: "foo.wlt", EncodedTransaction: "aab", ApprovalPassword: "approve"}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "Decode transaction failed: encoding/hex: odd length hex string"),
+		},
+		{
+			name:         "no spending policy",
+			method:       http.MethodPost,
+			status:       http.StatusNotFound,
+			contentType:  ContentTypeJSON,
+			req:          validReq,
+			gatewayErr:   wallet.ErrSpendingPolicyNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "wallet has no spending policy"),
+		},
+		{
+			name:         "invalid approval password",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			contentType:  ContentTypeJSON,
+			req:          validReq,
+			gatewayErr:   wallet.ErrInvalidApprovalPassword,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid approval password"),
+		},
+		{
+			name:        "ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req:         validReq,
+			httpResponse: HTTPResponse{
+				Data: txn.InnerHash.Hex(),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("ApproveTransaction", tc.req.ID, txn.InnerHash, []byte(tc.req.ApprovalPassword)).Return(tc.gatewayErr)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/policy/approve", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var hash string
+				err := json.Unmarshal(rsp.Data, &hash)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data, hash)
+			}
+		})
+	}
+}
+
+func TestWalletExport(t *testing.T) {
+	w, err := wallet.NewWallet("foo.wlt", wallet.Options{
+		Seed:      "seed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	txn := coin.Transaction{Length: 1}
+	txid := txn.Hash().Hex()
+
+	newBundle := func() *wallet.Bundle {
+		return &wallet.Bundle{
+			Created: 100,
+			Wallets: []*wallet.ReadableWallet{wallet.NewReadableWallet(w)},
+		}
+	}
+
+	type gatewayReturn struct {
+		bundle         *wallet.Bundle
+		exportErr      error
+		notes          map[string]string
+		storageErr     error
+		txns           []visor.Transaction
+		getTxnsErr     error
+		getTxnsSkipped bool
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		httpBody      string
+		req           *WalletExportRequest
+		gatewayReturn gatewayReturn
+		err           string
+		notes         map[string]string
+	}{
+		{
+			name:        "405",
+			method:      http.MethodGet,
+			status:      http.StatusMethodNotAllowed,
+			contentType: ContentTypeJSON,
+			err:         "Method Not Allowed",
+		},
+		{
+			name:        "415",
+			method:      http.MethodPost,
+			status:      http.StatusUnsupportedMediaType,
+			contentType: ContentTypeForm,
+			err:         "Unsupported Media Type",
+		},
+		{
+			name:        "400 - missing ids",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			httpBody:    `{"password":"pwd"}`,
+			err:         "ids is required",
+		},
+		{
+			name:        "400 - missing password",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			httpBody:    `{"ids":["foo.wlt"]}`,
+			err:         "password is required",
+		},
+		{
+			name:        "400 - invalid crypto type",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			httpBody:    `{"ids":["foo.wlt"],"password":"pwd","crypto_type":"foo"}`,
+			err:         "invalid crypto_type: unknown crypto type",
+		},
+		{
+			name:        "404 - wallet not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			contentType: ContentTypeJSON,
+			req: &WalletExportRequest{
+				IDs:      []string{"foo.wlt"},
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				exportErr: wallet.ErrWalletNotExist,
+			},
+			err: "Not Found",
+		},
+		{
+			name:        "403 - seed api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req: &WalletExportRequest{
+				IDs:      []string{"foo.wlt"},
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				exportErr: wallet.ErrSeedAPIDisabled,
+			},
+			err: "Forbidden",
+		},
+		{
+			name:        "500 - get transactions failed",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req: &WalletExportRequest{
+				IDs:      []string{"foo.wlt"},
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				bundle: newBundle(),
+				notes: map[string]string{
+					txid: "note",
+				},
+				getTxnsErr: errors.New("get transactions failed"),
+			},
+			err: "get transactions failed",
+		},
+		{
+			name:        "200 - storage api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletExportRequest{
+				IDs:        []string{"foo.wlt"},
+				Password:   "pwd",
+				CryptoType: string(wallet.CryptoTypeSha256Xor),
+			},
+			gatewayReturn: gatewayReturn{
+				bundle:         newBundle(),
+				storageErr:     kvstorage.ErrStorageAPIDisabled,
+				getTxnsSkipped: true,
+			},
+		},
+		{
+			name:        "200",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletExportRequest{
+				IDs:      []string{"foo.wlt"},
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				bundle: newBundle(),
+				notes: map[string]string{
+					txid:                         "note",
+					testutil.RandSHA256(t).Hex(): "other",
+				},
+				txns: []visor.Transaction{
+					{
+						Transaction: txn,
+					},
+				},
+			},
+			notes: map[string]string{
+				txid: "note",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("ExportWallets", tc.req.IDs).Return(tc.gatewayReturn.bundle, tc.gatewayReturn.exportErr)
+				gateway.On("GetAllStorageValues", kvstorage.TypeTxIDNotes).Return(tc.gatewayReturn.notes, tc.gatewayReturn.storageErr)
+				gateway.On("GetTransactions", mock.MatchedBy(func(flts []visor.TxFilter) bool {
+					if len(flts) != 1 {
+						return false
+					}
+					f, ok := flts[0].(visor.AddrsFilter)
+					return ok && len(f.Addrs) == len(w.Entries) && f.Addrs[0] == w.Entries[0].SkycoinAddress()
+				})).Return(tc.gatewayReturn.txns, tc.gatewayReturn.getTxnsErr)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/export", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var rsp ReceivedHTTPResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rsp))
+
+			if tc.status != http.StatusOK {
+				require.Equal(t, tc.err, rsp.Error.Message)
+				return
+			}
+
+			if tc.gatewayReturn.getTxnsSkipped {
+				gateway.AssertNotCalled(t, "GetTransactions", mock.Anything)
+			}
+
+			var wb WalletBundle
+			require.NoError(t, json.Unmarshal(rsp.Data, &wb))
+
+			cryptoType := wallet.CryptoTypeScryptChacha20poly1305
+			if tc.req.CryptoType != "" {
+				cryptoType = wallet.CryptoType(tc.req.CryptoType)
+			}
+			require.Equal(t, string(cryptoType), wb.CryptoType)
+
+			b, err := (&wallet.EncryptedBundle{
+				Type:       wb.Type,
+				Version:    wb.Version,
+				CryptoType: wallet.CryptoType(wb.CryptoType),
+				Data:       wb.Data,
+			}).Decrypt([]byte(tc.req.Password))
+			require.NoError(t, err)
+
+			expected := tc.gatewayReturn.bundle
+			expected.Notes = tc.notes
+			require.Equal(t, expected, b)
+		})
+	}
+}
+
+func TestWalletImport(t *testing.T) {
+	w, err := wallet.NewWallet("foo.wlt", wallet.Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+
+	b := &wallet.Bundle{
+		Created: 100,
+		Wallets: []*wallet.ReadableWallet{wallet.NewReadableWallet(w)},
+		Notes: map[string]string{
+			"a": "note a",
+			"b": "note b",
+		},
+	}
+
+	eb, err := b.Encrypt([]byte("pwd"), wallet.CryptoTypeSha256Xor)
+	require.NoError(t, err)
+	bundle := WalletBundle{
+		Type:       eb.Type,
+		Version:    eb.Version,
+		CryptoType: string(eb.CryptoType),
+		Data:       eb.Data,
+	}
+
+	results := []wallet.ImportedWallet{
+		{
+			Filename:   "foo.wlt",
+			ImportedAs: "foo_1.wlt",
+		},
+	}
+
+	type gatewayReturn struct {
+		results    []wallet.ImportedWallet
+		importErr  error
+		notes      map[string]string
+		storageErr error
+		addErr     error
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		status        int
+		contentType   string
+		httpBody      string
+		req           *WalletImportRequest
+		gatewayReturn gatewayReturn
+		err           string
+		addedNotes    []string
+		rsp           WalletImportResponse
+	}{
+		{
+			name:        "405",
+			method:      http.MethodGet,
+			status:      http.StatusMethodNotAllowed,
+			contentType: ContentTypeJSON,
+			err:         "Method Not Allowed",
+		},
+		{
+			name:        "415",
+			method:      http.MethodPost,
+			status:      http.StatusUnsupportedMediaType,
+			contentType: ContentTypeForm,
+			err:         "Unsupported Media Type",
+		},
+		{
+			name:        "400 - missing password",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletImportRequest{
+				Bundle: bundle,
+			},
+			err: "password is required",
+		},
+		{
+			name:        "400 - invalid password",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletImportRequest{
+				Bundle:   bundle,
+				Password: "wrong",
+			},
+			err: "invalid wallet bundle password",
+		},
+		{
+			name:        "400 - invalid bundle",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletImportRequest{
+				Bundle: WalletBundle{
+					Type: "foo",
+				},
+				Password: "pwd",
+			},
+			err: "invalid wallet bundle",
+		},
+		{
+			name:        "403 - wallet api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			contentType: ContentTypeJSON,
+			req: &WalletImportRequest{
+				Bundle:   bundle,
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				importErr: wallet.ErrWalletAPIDisabled,
+			},
+			err: "Forbidden",
+		},
+		{
+			name:        "500 - add note failed",
+			method:      http.MethodPost,
+			status:      http.StatusInternalServerError,
+			contentType: ContentTypeJSON,
+			req: &WalletImportRequest{
+				Bundle:   bundle,
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				results: results,
+				notes: map[string]string{
+					"a": "existing note",
+				},
+				addErr: errors.New("add failed"),
+			},
+			err: "add failed",
+		},
+		{
+			name:        "200 - storage api disabled",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletImportRequest{
+				Bundle:   bundle,
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				results:    results,
+				storageErr: kvstorage.ErrStorageAPIDisabled,
+			},
+			rsp: WalletImportResponse{
+				Wallets: []ImportedWallet{
+					{
+						Filename:   "foo.wlt",
+						ImportedAs: "foo_1.wlt",
+					},
+				},
+			},
+		},
+		{
+			name:        "200",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletImportRequest{
+				Bundle:   bundle,
+				Password: "pwd",
+			},
+			gatewayReturn: gatewayReturn{
+				results: []wallet.ImportedWallet{
+					{
+						Filename:    "foo.wlt",
+						DuplicateOf: "bar.wlt",
+					},
+				},
+				notes: map[string]string{
+					"a": "existing note",
+				},
+			},
+			addedNotes: []string{"b"},
+			rsp: WalletImportResponse{
+				Wallets: []ImportedWallet{
+					{
+						Filename:    "foo.wlt",
+						DuplicateOf: "bar.wlt",
+					},
+				},
+				TransactionNotes: 1,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("ImportWallets", b).Return(tc.gatewayReturn.results, tc.gatewayReturn.importErr)
+			gateway.On("GetAllStorageValues", kvstorage.TypeTxIDNotes).Return(tc.gatewayReturn.notes, tc.gatewayReturn.storageErr)
+			gateway.On("AddStorageValue", kvstorage.TypeTxIDNotes, "b", "note b").Return(tc.gatewayReturn.addErr)
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/import", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var rsp ReceivedHTTPResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rsp))
+
+			if tc.status != http.StatusOK {
+				require.Equal(t, tc.err, rsp.Error.Message)
+				return
+			}
+
+			// Existing notes are not overwritten
+			gateway.AssertNotCalled(t, "AddStorageValue", kvstorage.TypeTxIDNotes, "a", mock.Anything)
+			gateway.AssertNumberOfCalls(t, "AddStorageValue", len(tc.addedNotes))
+
+			var importRsp WalletImportResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &importRsp))
+			require.Equal(t, tc.rsp, importRsp)
+		})
+	}
+}
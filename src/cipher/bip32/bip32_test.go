@@ -602,6 +602,9 @@ func TestDeserializePrivateInvalidStrings(t *testing.T) {
 
 			_, err = DeserializePrivateKey(b)
 			require.Equal(t, test.err, err)
+
+			_, err = DeserializeEncodedPrivateKey(test.base58)
+			require.Equal(t, test.err, err)
 		})
 	}
 }
@@ -658,6 +661,9 @@ func TestDeserializePublicInvalidStrings(t *testing.T) {
 
 			_, err = DeserializePublicKey(b)
 			require.Equal(t, test.err, err)
+
+			_, err = DeserializeEncodedPublicKey(test.base58)
+			require.Equal(t, test.err, err)
 		})
 	}
 }
@@ -697,6 +703,11 @@ func assertPrivateKeySerialization(t *testing.T, key *PrivateKey, expected strin
 	require.NoError(t, err)
 
 	require.Equal(t, key, key2)
+
+	key3, err := DeserializeEncodedPrivateKey(expected)
+	require.NoError(t, err)
+
+	require.Equal(t, key, key3)
 }
 
 func assertPublicKeySerialization(t *testing.T, key *PublicKey, expected string) {
@@ -711,6 +722,11 @@ func assertPublicKeySerialization(t *testing.T, key *PublicKey, expected string)
 	require.NoError(t, err)
 
 	require.Equal(t, key, key2)
+
+	key3, err := DeserializeEncodedPublicKey(expected)
+	require.NoError(t, err)
+
+	require.Equal(t, key, key3)
 }
 
 func TestValidatePrivateKey(t *testing.T) {
@@ -0,0 +1,92 @@
+/*
+Package bip44 implements the bip44 spec https://github.com/bitcoin/bips/blob/master/bip-0044.mediawiki
+*/
+package bip44
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher/bip32"
+)
+
+// CoinType is the coin_type part of the bip44 path
+type CoinType uint32
+
+const (
+	// CoinTypeBitcoin is the coin_type for Bitcoin
+	CoinTypeBitcoin CoinType = 0
+	// CoinTypeBitcoinTestnet is the coin_type for any Bitcoin testnet
+	CoinTypeBitcoinTestnet CoinType = 1
+	// CoinTypeSkycoin is the coin_type for Skycoin
+	CoinTypeSkycoin CoinType = 8000
+
+	// ExternalChainIndex is the index of the external chain, used for receiving addresses
+	ExternalChainIndex = uint32(0)
+	// ChangeChainIndex is the index of the change chain, used for change addresses
+	ChangeChainIndex = uint32(1)
+)
+
+var (
+	// ErrInvalidAccount is returned if the account number is in the hardened range
+	ErrInvalidAccount = errors.New("bip44 account number must be less than 2^31")
+)
+
+// Coin is a bip32 node at the `coin_type` level of a bip44 path
+type Coin struct {
+	*bip32.PrivateKey
+}
+
+// NewCoin creates a bip32 node at the `coin_type` level of a bip44 path, m/44'/coin_type'
+func NewCoin(seed []byte, coinType CoinType) (*Coin, error) {
+	if uint32(coinType) >= bip32.FirstHardenedChild {
+		return nil, fmt.Errorf("bip44 coin type %d must be less than 2^31", coinType)
+	}
+
+	path := fmt.Sprintf("m/44'/%d'", coinType)
+	k, err := bip32.NewPrivateKeyFromPath(seed, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Coin{
+		PrivateKey: k,
+	}, nil
+}
+
+// Account returns the bip32 node at the `account` level of a bip44 path, m/44'/coin_type'/account'.
+// The account number is hardened automatically and must be less than 2^31.
+// This method can return an ImpossibleChild error.
+func (c *Coin) Account(account uint32) (*Account, error) {
+	if account >= bip32.FirstHardenedChild {
+		return nil, ErrInvalidAccount
+	}
+
+	k, err := c.NewPrivateChildKey(account + bip32.FirstHardenedChild)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		PrivateKey: k,
+	}, nil
+}
+
+// Account is a bip32 node at the `account` level of a bip44 path
+type Account struct {
+	*bip32.PrivateKey
+}
+
+// External returns the external chain node, m/44'/coin_type'/account'/0.
+// Addresses derived from this node are used for receiving coins.
+// This method can return an ImpossibleChild error.
+func (a *Account) External() (*bip32.PrivateKey, error) {
+	return a.NewPrivateChildKey(ExternalChainIndex)
+}
+
+// Change returns the change chain node, m/44'/coin_type'/account'/1.
+// Addresses derived from this node are used for transaction change.
+// This method can return an ImpossibleChild error.
+func (a *Account) Change() (*bip32.PrivateKey, error) {
+	return a.NewPrivateChildKey(ChangeChainIndex)
+}
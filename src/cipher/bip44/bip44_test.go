@@ -0,0 +1,82 @@
+package bip44
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip32"
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+)
+
+func TestNewCoin(t *testing.T) {
+	// Known values for the bip39 "abandon ... about" test mnemonic, as produced
+	// by other bip44 implementations (e.g. hardware wallets)
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	seed, err := bip39.NewSeed(mnemonic, "")
+	require.NoError(t, err)
+
+	c, err := NewCoin(seed, CoinTypeBitcoin)
+	require.NoError(t, err)
+
+	account, err := c.Account(0)
+	require.NoError(t, err)
+	require.Equal(t, "xprv9xpXFhFpqdQK3TmytPBqXtGSwS3DLjojFhTGht8gwAAii8py5X6pxeBnQ6ehJiyJ6nDjWGJfZ95WxByFXVkDxHXrqu53WCRGypk2ttuqncb", account.String())
+	require.Equal(t, "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj", account.PublicKey().String())
+
+	external, err := account.External()
+	require.NoError(t, err)
+
+	k, err := external.NewPrivateChildKey(0)
+	require.NoError(t, err)
+	pk := cipher.MustNewPubKey(k.PublicKey().Key)
+	require.Equal(t, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", cipher.BitcoinAddressFromPubKey(pk).String())
+
+	// The derived keys must match the keys derived from the full path
+	for _, tc := range []struct {
+		coinType CoinType
+		account  uint32
+		change   bool
+		path     string
+	}{
+		{CoinTypeSkycoin, 0, false, "m/44'/8000'/0'/0"},
+		{CoinTypeSkycoin, 0, true, "m/44'/8000'/0'/1"},
+		{CoinTypeSkycoin, 3, false, "m/44'/8000'/3'/0"},
+		{CoinTypeBitcoinTestnet, 1, true, "m/44'/1'/1'/1"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			c, err := NewCoin(seed, tc.coinType)
+			require.NoError(t, err)
+
+			account, err := c.Account(tc.account)
+			require.NoError(t, err)
+
+			var k *bip32.PrivateKey
+			if tc.change {
+				k, err = account.Change()
+			} else {
+				k, err = account.External()
+			}
+			require.NoError(t, err)
+
+			expectedKey, err := bip32.NewPrivateKeyFromPath(seed, tc.path)
+			require.NoError(t, err)
+			require.Equal(t, expectedKey.String(), k.String())
+		})
+	}
+}
+
+func TestInvalidAccount(t *testing.T) {
+	seed, err := bip39.NewSeed(bip39.MustNewDefaultMnemonic(), "")
+	require.NoError(t, err)
+
+	_, err = NewCoin(seed, CoinType(bip32.FirstHardenedChild))
+	require.Error(t, err)
+
+	c, err := NewCoin(seed, CoinTypeSkycoin)
+	require.NoError(t, err)
+
+	_, err = c.Account(bip32.FirstHardenedChild)
+	require.Equal(t, ErrInvalidAccount, err)
+}
@@ -0,0 +1,243 @@
+package encrypt
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/argon2"
+	"github.com/skycoin/skycoin/src/cipher/chacha20poly1305"
+)
+
+const (
+	argon2idChacha20MetaLengthSize = 2  // meta data length field size in bytes
+	argon2idChacha20SaltSize       = 32 // salt bytes number
+)
+
+// Default argon2id parameters
+const (
+	// Argon2idTime: argon2id time parameter, the number of passes over the memory.
+	Argon2idTime = 3
+	// Argon2idMemory: argon2id memory parameter in KiB. 64 MiB, as recommended by RFC 9106 for memory-constrained environments.
+	Argon2idMemory = 64 * 1024
+	// Argon2idParallelism: argon2id parallelism parameter, the number of lanes (and threads) used.
+	Argon2idParallelism = 4
+	// Argon2idKeyLen: The length of returned byte slice that can be used as cryptographic key.
+	Argon2idKeyLen = chacha20poly1305.KeySize
+
+	// Argon2idMaxMemory is the largest memory parameter in KiB accepted when decrypting, 1 GiB.
+	// It prevents crafted metadata from exhausting the memory of the host.
+	Argon2idMaxMemory = 1024 * 1024
+	// Argon2idMaxTime is the largest time parameter accepted when decrypting.
+	// It prevents crafted metadata from keeping the host busy indefinitely.
+	Argon2idMaxTime = 64
+)
+
+// DefaultArgon2idChacha20poly1305 default Argon2idChacha20poly1305 encryptor
+var DefaultArgon2idChacha20poly1305 = Argon2idChacha20poly1305{
+	Time:        Argon2idTime,
+	Memory:      Argon2idMemory,
+	Parallelism: Argon2idParallelism,
+	KeyLen:      Argon2idKeyLen,
+}
+
+// Argon2idChacha20poly1305 provides methods for encryption/decryption with argon2id and chacha20poly1305.
+// The argon2id parameters used for encryption are stored in the authenticated metadata of the
+// encrypted data, so they can be raised over time without breaking decryption of existing data.
+type Argon2idChacha20poly1305 struct {
+	Time        uint32
+	Memory      uint32
+	Parallelism uint8
+	KeyLen      uint32
+}
+
+type argon2idMeta struct {
+	Time        uint32 `json:"t"`
+	Memory      uint32 `json:"m"`
+	Parallelism uint8  `json:"p"`
+	KeyLen      uint32 `json:"keyLen"`
+	Salt        []byte `json:"salt"`
+	Nonce       []byte `json:"nonce"`
+}
+
+// verify checks that the argon2id parameters can be used to derive a chacha20poly1305 key
+func (m argon2idMeta) verify() error {
+	if m.Time < 1 {
+		return errors.New("argon2id time must be at least 1")
+	}
+
+	if m.Time > Argon2idMaxTime {
+		return errors.New("argon2id time is too large")
+	}
+
+	if m.Parallelism < 1 {
+		return errors.New("argon2id parallelism must be at least 1")
+	}
+
+	if m.Memory < 8*uint32(m.Parallelism) {
+		return errors.New("argon2id memory must be at least 8*parallelism KiB")
+	}
+
+	if m.Memory > Argon2idMaxMemory {
+		return errors.New("argon2id memory is too large")
+	}
+
+	if m.KeyLen != chacha20poly1305.KeySize {
+		return errors.New("argon2id key length must be 32")
+	}
+
+	return nil
+}
+
+// Validate checks that the argon2id parameters can be used to derive a chacha20poly1305 key
+func (a Argon2idChacha20poly1305) Validate() error {
+	return argon2idMeta{
+		Time:        a.Time,
+		Memory:      a.Memory,
+		Parallelism: a.Parallelism,
+		KeyLen:      a.KeyLen,
+	}.verify()
+}
+
+// Encrypt encrypts data with password,
+// 1. Argon2id derives the key from password
+// 2. Chacha20poly1305 generates AEAD from the derived key
+// 3. Puts argon2id parameters, salt and nonce into metadata, json serialize it and get the serialized metadata length
+// 4. AEAD.Seal encrypts the data, and use [length][metadata] as additional data
+// 5. Final format: base64([[length][metadata]][ciphertext]), length is 2 bytes.
+func (a Argon2idChacha20poly1305) Encrypt(data, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("missing password")
+	}
+
+	// Prepare metadata
+	m := argon2idMeta{
+		Time:        a.Time,
+		Memory:      a.Memory,
+		Parallelism: a.Parallelism,
+		KeyLen:      a.KeyLen,
+		Salt:        cipher.RandByte(argon2idChacha20SaltSize),
+		Nonce:       cipher.RandByte(chacha20poly1305.NonceSize),
+	}
+
+	if err := m.verify(); err != nil {
+		return nil, err
+	}
+
+	// Argon2id derives key from password
+	dk := argon2.IDKey(password, m.Salt, m.Time, m.Memory, m.Parallelism, m.KeyLen)
+
+	// json serialize the metadata
+	ms, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ms) > math.MaxUint16 {
+		return nil, errors.New("metadata length beyond the math.MaxUint16")
+	}
+
+	length := make([]byte, argon2idChacha20MetaLengthSize)
+	binary.LittleEndian.PutUint16(length, uint16(len(ms)))
+
+	// Additional data for AEAD
+	ad := append(length, ms...)
+	aead, err := chacha20poly1305.New(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := aead.Seal(nil, m.Nonce, data, ad)
+
+	// Base64 encode the [[length][metadata]][ciphertext]
+	rawData := append(ad, ciphertext...)
+	enc := base64.StdEncoding
+	buf := make([]byte, enc.EncodedLen(len(rawData)))
+	enc.Encode(buf, rawData)
+	return buf, nil
+}
+
+// Decrypt decrypts the data with password
+// 1. Base64 decodes the data
+// 2. Reads the first [metaLengthSize] bytes data to get the metadata length, and reads out the metadata.
+// 3. Argon2id derives key from password and parameters in metadata
+// 4. Chacha20poly1305 generates AEAD
+// 5. AEAD decrypts ciphertext with nonce in metadata and [length][metadata] as additional data.
+func (a Argon2idChacha20poly1305) Decrypt(data, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("missing password")
+	}
+
+	m, ad, ciphertext, err := decodeArgon2idData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Argon2id derives key
+	dk := argon2.IDKey(password, m.Salt, m.Time, m.Memory, m.Parallelism, m.KeyLen)
+
+	// Generates AEAD
+	aead, err := chacha20poly1305.New(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Open(nil, m.Nonce, ciphertext, ad)
+}
+
+// Argon2idChacha20poly1305Params returns the argon2id parameters recorded in the metadata of data
+// encrypted by Argon2idChacha20poly1305
+func Argon2idChacha20poly1305Params(data []byte) (Argon2idChacha20poly1305, error) {
+	m, _, _, err := decodeArgon2idData(data)
+	if err != nil {
+		return Argon2idChacha20poly1305{}, err
+	}
+
+	return Argon2idChacha20poly1305{
+		Time:        m.Time,
+		Memory:      m.Memory,
+		Parallelism: m.Parallelism,
+		KeyLen:      m.KeyLen,
+	}, nil
+}
+
+// decodeArgon2idData base64 decodes data encrypted by Argon2idChacha20poly1305,
+// and splits it into the verified metadata, the additional data of the AEAD and the ciphertext
+func decodeArgon2idData(data []byte) (argon2idMeta, []byte, []byte, error) {
+	enc := base64.StdEncoding
+	encData := make([]byte, enc.DecodedLen(len(data)))
+	n, err := enc.Decode(encData, data)
+	if err != nil {
+		return argon2idMeta{}, nil, nil, err
+	}
+	encData = encData[:n]
+
+	if len(encData) < argon2idChacha20MetaLengthSize {
+		return argon2idMeta{}, nil, nil, errors.New("invalid metadata length")
+	}
+
+	length := binary.LittleEndian.Uint16(encData[:argon2idChacha20MetaLengthSize])
+	if argon2idChacha20MetaLengthSize+int(length) > len(encData) {
+		return argon2idMeta{}, nil, nil, errors.New("invalid metadata length")
+	}
+
+	var m argon2idMeta
+	if err := json.Unmarshal(encData[argon2idChacha20MetaLengthSize:argon2idChacha20MetaLengthSize+int(length)], &m); err != nil {
+		return argon2idMeta{}, nil, nil, err
+	}
+
+	if err := m.verify(); err != nil {
+		return argon2idMeta{}, nil, nil, err
+	}
+
+	if len(m.Nonce) != chacha20poly1305.NonceSize {
+		return argon2idMeta{}, nil, nil, errors.New("invalid nonce length")
+	}
+
+	ad := encData[:argon2idChacha20MetaLengthSize+int(length)]
+
+	return m, ad, encData[argon2idChacha20MetaLengthSize+int(length):], nil
+}
@@ -0,0 +1,208 @@
+package encrypt
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgon2idChacha20poly1305Encrypt(t *testing.T) {
+	for _, p := range []uint8{1, 2, 4} {
+		for _, m := range []uint32{64, 1024, 8 * 1024} {
+			name := fmt.Sprintf("t=%v m=%v p=%v keyLen=%v", 1, m, p, 32)
+			t.Run(name, func(t *testing.T) {
+				crypto := Argon2idChacha20poly1305{Time: 1, Memory: m, Parallelism: p, KeyLen: 32}
+				encData, err := crypto.Encrypt([]byte("plaintext"), []byte("password"))
+				require.NoError(t, err)
+
+				data, err := base64.StdEncoding.DecodeString(string(encData))
+				require.NoError(t, err)
+				// Checks the prefix
+				ml := binary.LittleEndian.Uint16(data[:argon2idChacha20MetaLengthSize])
+				require.True(t, int(argon2idChacha20MetaLengthSize+ml) <= len(data))
+				var meta argon2idMeta
+				require.NoError(t, json.Unmarshal(data[argon2idChacha20MetaLengthSize:argon2idChacha20MetaLengthSize+ml], &meta))
+				require.Equal(t, uint32(1), meta.Time)
+				require.Equal(t, m, meta.Memory)
+				require.Equal(t, p, meta.Parallelism)
+				require.Equal(t, uint32(32), meta.KeyLen)
+				require.Len(t, meta.Salt, argon2idChacha20SaltSize)
+
+				params, err := Argon2idChacha20poly1305Params(encData)
+				require.NoError(t, err)
+				require.Equal(t, crypto, params)
+				require.NoError(t, params.Validate())
+
+				// Decrypts with the parameters in the metadata, not those of the decryptor
+				plaintext, err := DefaultArgon2idChacha20poly1305.Decrypt(encData, []byte("password"))
+				require.NoError(t, err)
+				require.Equal(t, []byte("plaintext"), plaintext)
+			})
+		}
+	}
+}
+
+func TestArgon2idChacha20poly1305EncryptInvalidParams(t *testing.T) {
+	tt := []struct {
+		name   string
+		crypto Argon2idChacha20poly1305
+		err    error
+	}{
+		{
+			name:   "zero time",
+			crypto: Argon2idChacha20poly1305{Time: 0, Memory: 1024, Parallelism: 1, KeyLen: 32},
+			err:    errors.New("argon2id time must be at least 1"),
+		},
+		{
+			name:   "time too large",
+			crypto: Argon2idChacha20poly1305{Time: Argon2idMaxTime + 1, Memory: 1024, Parallelism: 1, KeyLen: 32},
+			err:    errors.New("argon2id time is too large"),
+		},
+		{
+			name:   "zero parallelism",
+			crypto: Argon2idChacha20poly1305{Time: 1, Memory: 1024, Parallelism: 0, KeyLen: 32},
+			err:    errors.New("argon2id parallelism must be at least 1"),
+		},
+		{
+			name:   "memory too small",
+			crypto: Argon2idChacha20poly1305{Time: 1, Memory: 31, Parallelism: 4, KeyLen: 32},
+			err:    errors.New("argon2id memory must be at least 8*parallelism KiB"),
+		},
+		{
+			name:   "memory too large",
+			crypto: Argon2idChacha20poly1305{Time: 1, Memory: Argon2idMaxMemory + 1, Parallelism: 1, KeyLen: 32},
+			err:    errors.New("argon2id memory is too large"),
+		},
+		{
+			name:   "invalid key length",
+			crypto: Argon2idChacha20poly1305{Time: 1, Memory: 1024, Parallelism: 1, KeyLen: 16},
+			err:    errors.New("argon2id key length must be 32"),
+		},
+		{
+			name:   "missing password",
+			crypto: DefaultArgon2idChacha20poly1305,
+			err:    errors.New("missing password"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			password := []byte("password")
+			if tc.name == "missing password" {
+				password = nil
+			}
+			_, err := tc.crypto.Encrypt([]byte("plaintext"), password)
+			require.Equal(t, tc.err, err)
+
+			if password != nil {
+				require.Equal(t, tc.err, tc.crypto.Validate())
+			}
+		})
+	}
+}
+
+func TestArgon2idChacha20poly1305Decrypt(t *testing.T) {
+	// Encrypted with t=1 m=1024 p=1 keyLen=32 and password "pwd"
+	encData := []byte("cwB7InQiOjEsIm0iOjEwMjQsInAiOjEsImtleUxlbiI6MzIsInNhbHQiOiIvK1lxaDdqRm51bDAvVFQ2VnhSenY2bjlVcWNWVFpaZmY4cVhhcFRwNWFvPSIsIm5vbmNlIjoib3BTWnJtb0ZSNDhlR3JNUCJ9IHScY6L6aC0hbFEpzFo/eUWBWr7DuuQLRQ==")
+
+	// Metadata requesting more memory or passes than allowed
+	hugeMemoryData := makeArgon2idData(t, 1, 4*1024*1024)
+	hugeTimeData := makeArgon2idData(t, math.MaxUint32, 1024)
+
+	tt := []struct {
+		name    string
+		data    []byte
+		encData []byte
+		decPwd  []byte
+		err     error
+	}{
+		{
+			name:    "ok",
+			data:    []byte("plaintext"),
+			encData: encData,
+			decPwd:  []byte("pwd"),
+		},
+		{
+			name:    "invalid password",
+			encData: encData,
+			decPwd:  []byte("wrong password"),
+			err:     errors.New("chacha20poly1305: message authentication failed"),
+		},
+		{
+			name:    "missing password",
+			encData: encData,
+			decPwd:  nil,
+			err:     errors.New("missing password"),
+		},
+		{
+			name:    "invalid metadata length",
+			encData: []byte(base64.StdEncoding.EncodeToString([]byte{0xff, 0xff, 0x00})),
+			decPwd:  []byte("pwd"),
+			err:     errors.New("invalid metadata length"),
+		},
+		{
+			name:    "memory too large",
+			encData: hugeMemoryData,
+			decPwd:  []byte("pwd"),
+			err:     errors.New("argon2id memory is too large"),
+		},
+		{
+			name:    "time too large",
+			encData: hugeTimeData,
+			decPwd:  []byte("pwd"),
+			err:     errors.New("argon2id time is too large"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			crypto := Argon2idChacha20poly1305{}
+			data, err := crypto.Decrypt(tc.encData, tc.decPwd)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, tc.data, data)
+		})
+	}
+}
+
+// makeArgon2idData makes encrypted data with the given argon2id time and memory parameters in its metadata
+func makeArgon2idData(t *testing.T, time, memory uint32) []byte {
+	ms, err := json.Marshal(argon2idMeta{
+		Time:        time,
+		Memory:      memory,
+		Parallelism: 1,
+		KeyLen:      32,
+		Salt:        make([]byte, argon2idChacha20SaltSize),
+		Nonce:       make([]byte, 12),
+	})
+	require.NoError(t, err)
+	length := make([]byte, argon2idChacha20MetaLengthSize)
+	binary.LittleEndian.PutUint16(length, uint16(len(ms)))
+	return []byte(base64.StdEncoding.EncodeToString(append(append(length, ms...), make([]byte, 32)...)))
+}
+
+func TestArgon2idChacha20poly1305Params(t *testing.T) {
+	// Encrypted with t=1 m=1024 p=1 keyLen=32 and password "pwd"
+	encData := []byte("cwB7InQiOjEsIm0iOjEwMjQsInAiOjEsImtleUxlbiI6MzIsInNhbHQiOiIvK1lxaDdqRm51bDAvVFQ2VnhSenY2bjlVcWNWVFpaZmY4cVhhcFRwNWFvPSIsIm5vbmNlIjoib3BTWnJtb0ZSNDhlR3JNUCJ9IHScY6L6aC0hbFEpzFo/eUWBWr7DuuQLRQ==")
+
+	params, err := Argon2idChacha20poly1305Params(encData)
+	require.NoError(t, err)
+	require.Equal(t, Argon2idChacha20poly1305{
+		Time:        1,
+		Memory:      1024,
+		Parallelism: 1,
+		KeyLen:      32,
+	}, params)
+
+	_, err = Argon2idChacha20poly1305Params([]byte(base64.StdEncoding.EncodeToString([]byte{0xff, 0xff, 0x00})))
+	require.Equal(t, errors.New("invalid metadata length"), err)
+}
@@ -4,6 +4,7 @@ Package encrypt provides encryption methods.
 Encryption methods provided:
 
 * chacha20-poly1305 with scrypt key derivation
+* chacha20-poly1305 with argon2id key derivation
 * sha256xor with sha256 key derivation
 
 The latter is insecure due to the insecure key derivation so should not be used.
@@ -0,0 +1,162 @@
+/*
+Package shamir implements Shamir's secret sharing over GF(2^8), https://dl.acm.org/citation.cfm?doid=359168.359176
+
+Each byte of the secret is the constant term of a random polynomial of degree threshold-1,
+and each share holds the evaluations of the polynomials at a distinct non-zero x coordinate.
+Any threshold shares recover the secret, fewer shares reveal nothing about it.
+*/
+package shamir
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// MaxShares is the maximum number of shares, limited by the number of non-zero elements of GF(2^8)
+const MaxShares = 255
+
+var (
+	// ErrInvalidThreshold is returned if the threshold is not between 1 and the number of shares
+	ErrInvalidThreshold = errors.New("threshold must be between 1 and the number of shares")
+	// ErrTooManyShares is returned if more than MaxShares shares are requested
+	ErrTooManyShares = errors.New("number of shares must be at most 255")
+	// ErrEmptySecret is returned if the secret to split is empty
+	ErrEmptySecret = errors.New("secret is empty")
+	// ErrNoShares is returned if no shares are combined
+	ErrNoShares = errors.New("no shares")
+	// ErrInvalidShare is returned if a share is too short or has a zero x coordinate
+	ErrInvalidShare = errors.New("invalid share")
+	// ErrShareLengthMismatch is returned if the combined shares have different lengths
+	ErrShareLengthMismatch = errors.New("shares have different lengths")
+	// ErrDuplicateShare is returned if two combined shares have the same x coordinate
+	ErrDuplicateShare = errors.New("duplicate share")
+)
+
+// exp and log tables of GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1 and the generator 3
+var (
+	gfExp [510]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfExp[i+255] = x
+		gfLog[x] = byte(i)
+
+		// x *= 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// Split splits secret into n shares, any threshold of which can recover the secret with Combine.
+// Each share is one byte longer than the secret: its first byte is the x coordinate of the share, from 1 to n.
+func Split(secret []byte, n, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if n > MaxShares {
+		return nil, ErrTooManyShares
+	}
+	if threshold < 1 || threshold > n {
+		return nil, ErrInvalidThreshold
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	// coefficients[0] is the secret byte, the other coefficients are random
+	coefficients := make([]byte, threshold)
+	defer func() {
+		for i := range coefficients {
+			coefficients[i] = 0
+		}
+	}()
+
+	for j, s := range secret {
+		coefficients[0] = s
+		copy(coefficients[1:], cipher.RandByte(threshold-1))
+
+		for _, share := range shares {
+			share[j+1] = evaluate(coefficients, share[0])
+		}
+	}
+
+	return shares, nil
+}
+
+// evaluate evaluates the polynomial with coefficients at x, with Horner's method
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+	return y
+}
+
+// Combine recovers a secret from shares created by Split.
+// Combining fewer shares than the threshold of the split returns a wrong secret, without an error.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShares
+	}
+
+	size := len(shares[0])
+	xs := make(map[byte]struct{}, len(shares))
+	for _, share := range shares {
+		if len(share) < 2 || share[0] == 0 {
+			return nil, ErrInvalidShare
+		}
+		if len(share) != size {
+			return nil, ErrShareLengthMismatch
+		}
+		if _, ok := xs[share[0]]; ok {
+			return nil, ErrDuplicateShare
+		}
+		xs[share[0]] = struct{}{}
+	}
+
+	// Lagrange interpolation at x=0. In GF(2^8), subtraction is addition (xor)
+	secret := make([]byte, size-1)
+	for i, si := range shares {
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = gfMul(basis, gfDiv(sj[0], sj[0]^si[0]))
+			}
+		}
+
+		for k := range secret {
+			secret[k] ^= gfMul(basis, si[k+1])
+		}
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,108 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestGF(t *testing.T) {
+	// Known products in the AES field
+	require.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+	require.Equal(t, byte(0xfe), gfMul(0x57, 0x13))
+
+	for a := 0; a < 256; a++ {
+		require.Equal(t, byte(0), gfMul(byte(a), 0))
+		require.Equal(t, byte(a), gfMul(byte(a), 1))
+		for b := 1; b < 256; b++ {
+			require.Equal(t, byte(a), gfDiv(gfMul(byte(a), byte(b)), byte(b)))
+		}
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	cases := []struct {
+		n         int
+		threshold int
+	}{
+		{1, 1},
+		{3, 1},
+		{3, 2},
+		{5, 3},
+		{5, 5},
+		{MaxShares, 10},
+	}
+
+	for _, tc := range cases {
+		secret := cipher.RandByte(32)
+
+		shares, err := Split(secret, tc.n, tc.threshold)
+		require.NoError(t, err)
+		require.Len(t, shares, tc.n)
+		for i, s := range shares {
+			require.Len(t, s, len(secret)+1)
+			require.Equal(t, byte(i+1), s[0])
+		}
+
+		// Any threshold shares recover the secret
+		for i := 0; i+tc.threshold <= tc.n; i++ {
+			combined, err := Combine(shares[i : i+tc.threshold])
+			require.NoError(t, err)
+			require.Equal(t, secret, combined)
+		}
+
+		// All shares recover the secret
+		combined, err := Combine(shares)
+		require.NoError(t, err)
+		require.Equal(t, secret, combined)
+
+		// Fewer shares don't
+		if tc.threshold > 1 {
+			combined, err := Combine(shares[:tc.threshold-1])
+			require.NoError(t, err)
+			require.NotEqual(t, secret, combined)
+		}
+	}
+}
+
+func TestSplitInvalid(t *testing.T) {
+	secret := cipher.RandByte(16)
+
+	_, err := Split(nil, 3, 2)
+	require.Equal(t, ErrEmptySecret, err)
+
+	_, err = Split(secret, 3, 0)
+	require.Equal(t, ErrInvalidThreshold, err)
+
+	_, err = Split(secret, 3, 4)
+	require.Equal(t, ErrInvalidThreshold, err)
+
+	_, err = Split(secret, 0, 0)
+	require.Equal(t, ErrInvalidThreshold, err)
+
+	_, err = Split(secret, MaxShares+1, 2)
+	require.Equal(t, ErrTooManyShares, err)
+}
+
+func TestCombineInvalid(t *testing.T) {
+	shares, err := Split(cipher.RandByte(16), 3, 2)
+	require.NoError(t, err)
+
+	_, err = Combine(nil)
+	require.Equal(t, ErrNoShares, err)
+
+	_, err = Combine([][]byte{shares[0], shares[0]})
+	require.Equal(t, ErrDuplicateShare, err)
+
+	_, err = Combine([][]byte{shares[0], shares[1][:10]})
+	require.Equal(t, ErrShareLengthMismatch, err)
+
+	_, err = Combine([][]byte{shares[0], {1}})
+	require.Equal(t, ErrInvalidShare, err)
+
+	zero := append([]byte{0}, shares[1][1:]...)
+	_, err = Combine([][]byte{shares[0], zero})
+	require.Equal(t, ErrInvalidShare, err)
+}
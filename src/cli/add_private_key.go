@@ -18,6 +18,9 @@ func addPrivateKeyCmd() *cobra.Command {
     wallet (%s) will be
     used if the wallet file or path is not specified
 
+    Private keys added to collection wallets can be hex encoded or
+    in the Bitcoin wallet import format (WIF).
+
     Use caution when using the "-p" command. If you have command
     history enabled your wallet encryption password can be recovered from the
     history log. If you do not include the "-p" option you will be prompted to
@@ -72,6 +75,16 @@ func addPrivateKeyCmd() *cobra.Command {
 
 // AddPrivateKey adds a private key to a *wallet.Wallet. Caller should save the wallet afterwards
 func AddPrivateKey(wlt *wallet.Wallet, key string) error {
+	if wlt.Type() == wallet.WalletTypeCollection {
+		sk, err := wallet.ParseSecKey(key)
+		if err != nil {
+			return err
+		}
+
+		_, err = wlt.ImportSecKeys([]cipher.SecKey{sk})
+		return err
+	}
+
 	sk, err := cipher.SecKeyFromHex(key)
 	if err != nil {
 		return fmt.Errorf("invalid private key: %s, must be a hex string of length 64", key)
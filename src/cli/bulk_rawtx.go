@@ -0,0 +1,698 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// ErrBulkRowTooLarge is returned if a transaction to a single recipient of a bulk distribution exceeds the maximum transaction size
+var ErrBulkRowTooLarge = errors.New("a transaction to a single recipient exceeds the maximum transaction size")
+
+// BulkSendRow is a recipient row of a bulk distribution CSV file
+type BulkSendRow struct {
+	// Row is the index of the row in the CSV file, starting from 0
+	Row   int
+	Addr  string
+	Coins uint64
+	// Hours is the number of coin hours to send, nil if the file has no hours column
+	Hours *uint64
+	// DuplicateOf is the index in the CSV file of an earlier row with the same address, -1 if the address is not duplicated.
+	// Duplicate rows are not sent.
+	DuplicateOf int
+	// Summed is true if the coins and hours of the duplicate row were added to the row DuplicateOf,
+	// instead of the row repeating it
+	Summed bool
+}
+
+// BulkTxn is a transaction of a bulk distribution
+type BulkTxn struct {
+	Transaction coin.Transaction
+	// Rows are the indexes of the transaction's recipients in the rows passed to CreateBulkRawTxns, in the order of the outputs
+	Rows []int
+	// Fee is the number of coin hours burned by the transaction
+	Fee uint64
+}
+
+// parseBulkSendRowsFromCSV parses the rows of a bulk distribution CSV file.
+// Each row has an address, an amount of coins and optionally an amount of coin hours.
+// All rows must have the same number of columns. Rows repeating an earlier row, with the same address, coins and hours,
+// are marked as duplicates. A row with the address of an earlier row but different amounts is invalid, unless sumDuplicates
+// is true, in which case the coins and hours of every row with the same address are added to the first of them.
+// An error listing every invalid row is returned if any row is invalid.
+func parseBulkSendRowsFromCSV(fields [][]string, sumDuplicates bool) ([]BulkSendRow, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("No destination addresses")
+	}
+
+	nColumns := len(fields[0])
+
+	var rows []BulkSendRow
+	var errs []error
+	seen := make(map[string]int, len(fields))
+	for i, f := range fields {
+		if len(f) != nColumns || (len(f) != 2 && len(f) != 3) {
+			errs = append(errs, fmt.Errorf("[row %d] Expected %d columns of address, coins and optionally hours, found %d", i, nColumns, len(f)))
+			continue
+		}
+
+		addr := strings.TrimSpace(f[0])
+		if _, err := cipher.DecodeBase58Address(addr); err != nil {
+			errs = append(errs, fmt.Errorf("[row %d] Invalid address %s: %v", i, addr, err))
+			continue
+		}
+
+		coins, err := droplet.FromString(strings.TrimSpace(f[1]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("[row %d] Invalid amount %s: %v", i, f[1], err))
+			continue
+		}
+
+		if coins == 0 {
+			errs = append(errs, fmt.Errorf("[row %d] Cannot send 0 coins", i))
+			continue
+		}
+
+		if err := params.DropletPrecisionCheck(params.UserVerifyTxn.MaxDropletPrecision, coins); err != nil {
+			errs = append(errs, fmt.Errorf("[row %d] Invalid amount %s: %v", i, f[1], err))
+			continue
+		}
+
+		row := BulkSendRow{
+			Row:         i,
+			Addr:        addr,
+			Coins:       coins,
+			DuplicateOf: -1,
+		}
+
+		if len(f) == 3 {
+			hours, err := strconv.ParseUint(strings.TrimSpace(f[2]), 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("[row %d] Invalid hours %s: %v", i, f[2], err))
+				continue
+			}
+			row.Hours = &hours
+		}
+
+		j, ok := seen[addr]
+		if !ok {
+			seen[addr] = len(rows)
+			rows = append(rows, row)
+			continue
+		}
+
+		first := &rows[j]
+		row.DuplicateOf = first.Row
+
+		switch {
+		case sumDuplicates:
+			if err := sumBulkSendRow(first, row); err != nil {
+				errs = append(errs, fmt.Errorf("[row %d] Cannot add the amounts to row %d: %v", i, first.Row, err))
+				continue
+			}
+			row.Summed = true
+		case row.Coins != first.Coins || (row.Hours != nil && *row.Hours != *first.Hours):
+			errs = append(errs, fmt.Errorf("[row %d] Address %s of row %d has different amounts. Use --sum-duplicates to send the sum of the amounts", i, addr, first.Row))
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(errs) > 0 {
+		errMsgs := make([]string, len(errs))
+		for i, err := range errs {
+			errMsgs[i] = err.Error()
+		}
+
+		return nil, errors.New(strings.Join(errMsgs, "\n"))
+	}
+
+	return rows, nil
+}
+
+// sumBulkSendRow adds the coins and hours of row to first
+func sumBulkSendRow(first *BulkSendRow, row BulkSendRow) error {
+	coins, err := mathutil.AddUint64(first.Coins, row.Coins)
+	if err != nil {
+		return err
+	}
+
+	if row.Hours != nil {
+		hours, err := mathutil.AddUint64(*first.Hours, *row.Hours)
+		if err != nil {
+			return err
+		}
+		first.Hours = &hours
+	}
+
+	first.Coins = coins
+	return nil
+}
+
+// CreateBulkRawTxns creates the transactions of a bulk distribution from a set of addresses contained in a loaded *wallet.Wallet.
+// The recipients which are not duplicates are sent in order, in as many transactions as needed for each to be no larger than
+// the maximum transaction size. Each transaction spends different unspent outputs, so that they can be injected together.
+// If the rows have coin hours, they are sent with the manual hours selection, otherwise coin hours are distributed with
+// the auto share mode and a share factor of 0.5.
+// The transactions are signed if signed is true, otherwise the wallet is only used for its addresses and its frozen outputs,
+// and no password is needed.
+func CreateBulkRawTxns(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr string, rows []BulkSendRow, password []byte, signed bool) ([]BulkTxn, error) {
+	// Watch-only wallets have no secret keys to sign the transactions
+	if signed && wlt.Type() == wallet.WalletTypeXPub {
+		return nil, wallet.ErrWalletCantSign
+	}
+
+	cAddr, err := cipher.DecodeBase58Address(chgAddr)
+	if err != nil {
+		return nil, ErrAddress
+	}
+
+	var recipients []int
+	for i, r := range rows {
+		if r.DuplicateOf == -1 {
+			recipients = append(recipients, i)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("No destination addresses")
+	}
+
+	p := transaction.Params{
+		ChangeAddress: &cAddr,
+		FrozenUxOuts:  wlt.FrozenUxOuts(),
+	}
+
+	if rows[recipients[0]].Hours != nil {
+		p.HoursSelection = transaction.HoursSelection{
+			Type: transaction.HoursSelectionTypeManual,
+		}
+	} else {
+		shareFactor := decimal.New(5, -1)
+		p.HoursSelection = transaction.HoursSelection{
+			Type:        transaction.HoursSelectionTypeAuto,
+			Mode:        transaction.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		}
+	}
+
+	// Get unspent outputs of those addresses
+	outputs, err := c.OutputsForAddresses(inAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	inUxs, err := outputs.SpendableOutputs().ToUxArray()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := outputs.Head.ToCoinBlockHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	signedFlag := visor.TxnUnsigned
+	if signed {
+		signedFlag = visor.TxnSigned
+	}
+
+	f := func(w *wallet.Wallet) ([]BulkTxn, error) {
+		return createBulkTxns(w, p, rows, recipients, inUxs, head, signedFlag)
+	}
+
+	if signed && wlt.IsEncrypted() {
+		var txns []BulkTxn
+		if err := wlt.GuardView(password, func(w *wallet.Wallet) error {
+			var err error
+			txns, err = f(w)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		return txns, nil
+	}
+
+	return f(wlt)
+}
+
+// createBulkTxns creates the transactions of the recipients of a bulk distribution, signed by the keys of w if signedFlag is visor.TxnSigned
+func createBulkTxns(w *wallet.Wallet, p transaction.Params, rows []BulkSendRow, recipients []int, inUxs coin.UxArray, head coin.BlockHeader, signedFlag visor.TxnSignedFlag) ([]BulkTxn, error) {
+	inUxsMap := make(map[cipher.SHA256]coin.UxOut, len(inUxs))
+	for _, u := range inUxs {
+		inUxsMap[u.Hash()] = u
+	}
+
+	maxSize := params.UserVerifyTxn.MaxTransactionSize
+	auxs := coin.NewAddressUxOuts(inUxs)
+
+	var txns []BulkTxn
+	for len(recipients) > 0 {
+		// Send to as many of the remaining recipients as fit in a transaction.
+		// The size of a transaction grows with its outputs, so the number of recipients is
+		// scaled down by the size of the transaction until it fits.
+		n := len(recipients)
+		var txn *coin.Transaction
+		var inputs []transaction.UxBalance
+		for {
+			p.To = make([]coin.TransactionOutput, n)
+			for i, j := range recipients[:n] {
+				r := rows[j]
+				p.To[i] = mustMakeUtxoOutput(r.Addr, r.Coins, 0)
+				if r.Hours != nil {
+					p.To[i].Hours = *r.Hours
+				}
+			}
+
+			var err error
+			txn, inputs, err = transaction.Create(p, auxs, head.Time)
+			if err != nil {
+				return nil, fmt.Errorf("[row %d] %v", rows[recipients[0]].Row, err)
+			}
+
+			// Transactions are created with null signatures, so the size of the signed transaction is known
+			size, err := txn.Size()
+			if err != nil {
+				return nil, err
+			}
+
+			if size <= maxSize {
+				break
+			}
+
+			if n == 1 {
+				return nil, ErrBulkRowTooLarge
+			}
+
+			next := int(uint64(n) * uint64(maxSize) / uint64(size))
+			if next >= n {
+				next = n - 1
+			}
+			if next < 1 {
+				next = 1
+			}
+			n = next
+		}
+
+		if signedFlag == visor.TxnSigned {
+			keys, err := getKeys(w, inputs)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, k := range keys {
+				if err := txn.SignInput(k, i); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		uxIn := make(coin.UxArray, len(inputs))
+		for i, in := range inputs {
+			uxIn[i] = inUxsMap[in.Hash]
+		}
+
+		if err := visor.VerifySingleTxnSoftConstraints(*txn, head.Time, uxIn, params.UserVerifyTxn); err != nil {
+			return nil, err
+		}
+		if err := visor.VerifySingleTxnHardConstraints(*txn, head, uxIn, signedFlag); err != nil {
+			return nil, err
+		}
+		if err := visor.VerifySingleTxnUserConstraints(*txn); err != nil {
+			return nil, err
+		}
+
+		inHours, err := uxIn.CoinHours(head.Time)
+		if err != nil {
+			return nil, err
+		}
+		outHours, err := txn.OutputHours()
+		if err != nil {
+			return nil, err
+		}
+
+		txns = append(txns, BulkTxn{
+			Transaction: *txn,
+			Rows:        append([]int(nil), recipients[:n]...),
+			Fee:         inHours - outHours,
+		})
+
+		// The next transactions can't spend the outputs spent by this transaction, nor its change,
+		// which is unconfirmed until the transaction is in a block
+		auxs = auxs.Sub(coin.NewAddressUxOuts(uxIn))
+		recipients = recipients[n:]
+	}
+
+	return txns, nil
+}
+
+// BulkBatch is the batch file of a bulk distribution written by createRawTransaction --batch-file
+type BulkBatch struct {
+	Signed       bool               `json:"signed"`
+	Recipients   int                `json:"recipients"`
+	Duplicates   int                `json:"duplicates"`
+	Summed       int                `json:"summed"`
+	Coins        string             `json:"coins"`
+	Fee          string             `json:"fee"`
+	Transactions []BulkBatchTxnJSON `json:"transactions"`
+}
+
+// BulkBatchTxnJSON is a transaction of a bulk distribution batch file
+type BulkBatchTxnJSON struct {
+	TxID       string `json:"txid"`
+	RawTx      string `json:"rawtx"`
+	Recipients int    `json:"recipients"`
+	Coins      string `json:"coins"`
+	Fee        string `json:"fee"`
+}
+
+// newBulkBatch creates the batch file of the transactions of a bulk distribution
+func newBulkBatch(rows []BulkSendRow, txns []BulkTxn, signed bool) (*BulkBatch, error) {
+	b := &BulkBatch{
+		Signed:       signed,
+		Transactions: make([]BulkBatchTxnJSON, len(txns)),
+	}
+
+	for _, r := range rows {
+		switch {
+		case r.Summed:
+			b.Summed++
+		case r.DuplicateOf != -1:
+			b.Duplicates++
+		}
+	}
+
+	var totalCoins, totalFee uint64
+	for i, t := range txns {
+		rawTx, err := t.Transaction.SerializeHex()
+		if err != nil {
+			return nil, err
+		}
+
+		var coins uint64
+		for _, o := range t.Transaction.Out[:len(t.Rows)] {
+			coins, err = mathutil.AddUint64(coins, o.Coins)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		coinsStr, err := droplet.ToString(coins)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Transactions[i] = BulkBatchTxnJSON{
+			TxID:       t.Transaction.Hash().Hex(),
+			RawTx:      rawTx,
+			Recipients: len(t.Rows),
+			Coins:      coinsStr,
+			Fee:        fmt.Sprint(t.Fee),
+		}
+
+		b.Recipients += len(t.Rows)
+
+		totalCoins, err = mathutil.AddUint64(totalCoins, coins)
+		if err != nil {
+			return nil, err
+		}
+		totalFee, err = mathutil.AddUint64(totalFee, t.Fee)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	coins, err := droplet.ToString(totalCoins)
+	if err != nil {
+		return nil, err
+	}
+	b.Coins = coins
+	b.Fee = fmt.Sprint(totalFee)
+
+	return b, nil
+}
+
+// bulkReportHeader is the header of the reconciliation report of a bulk distribution
+var bulkReportHeader = []string{"row", "address", "coins", "hours", "status", "txid", "output"}
+
+// bulkReport creates the reconciliation report of a bulk distribution, with a line for each row of the CSV file.
+// A row which is sent has the status "included", the transaction ID and the index of its output in the transaction,
+// and the coin hours sent to it. A duplicate row has the status "duplicate of row N" and is not sent,
+// and a row added to an earlier row has the status "summed into row N".
+func bulkReport(rows []BulkSendRow, txns []BulkTxn) ([][]string, error) {
+	report := make([][]string, len(rows))
+	for i, r := range rows {
+		coins, err := droplet.ToString(r.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		var hours string
+		if r.Hours != nil {
+			hours = fmt.Sprint(*r.Hours)
+		}
+
+		status := "missing"
+		switch {
+		case r.Summed:
+			status = fmt.Sprintf("summed into row %d", r.DuplicateOf)
+		case r.DuplicateOf != -1:
+			status = fmt.Sprintf("duplicate of row %d", r.DuplicateOf)
+		}
+
+		report[i] = []string{fmt.Sprint(r.Row), r.Addr, coins, hours, status, "", ""}
+	}
+
+	for _, t := range txns {
+		txid := t.Transaction.Hash().Hex()
+		for j, i := range t.Rows {
+			o := t.Transaction.Out[j]
+			if o.Address.String() != rows[i].Addr || o.Coins != rows[i].Coins {
+				return nil, fmt.Errorf("output %d of transaction %s does not match row %d", j, txid, rows[i].Row)
+			}
+
+			report[i][3] = fmt.Sprint(o.Hours)
+			report[i][4] = "included"
+			report[i][5] = txid
+			report[i][6] = fmt.Sprint(j)
+		}
+	}
+
+	for i, r := range rows {
+		if report[i][4] == "missing" {
+			return nil, fmt.Errorf("row %d is not in any transaction", r.Row)
+		}
+	}
+
+	return append([][]string{bulkReportHeader}, report...), nil
+}
+
+func writeBulkBatchFile(filename string, b *BulkBatch) error {
+	d, err := json.MarshalIndent(b, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return writeNewFile(filename, append(d, '\n'))
+}
+
+func writeBulkReportFile(filename string, report [][]string) error {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	if err := w.WriteAll(report); err != nil {
+		return err
+	}
+
+	return writeNewFile(filename, []byte(sb.String()))
+}
+
+// writeNewFile writes data to a file which must not exist yet, so that a batch is never overwritten
+func writeNewFile(filename string, data []byte) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// bulkRawTxnCmdHandler creates the transactions of a bulk distribution from the CSV file of createRawTransaction --csv,
+// and writes the batch file and the reconciliation report
+func bulkRawTxnCmdHandler(c *cobra.Command, args []string, batchFile string) (*BulkBatch, error) {
+	if len(args) != 0 {
+		return nil, errors.New("--batch-file does not accept a to address and amount, the recipients are read from --csv")
+	}
+
+	csvFile, err := c.Flags().GetString("csv")
+	if err != nil {
+		return nil, err
+	}
+	if csvFile == "" {
+		return nil, errors.New("--batch-file requires --csv")
+	}
+
+	many, err := c.Flags().GetString("many")
+	if err != nil {
+		return nil, err
+	}
+	if many != "" {
+		return nil, errors.New("--batch-file and -m cannot be combined")
+	}
+
+	reportFile, err := c.Flags().GetString("report")
+	if err != nil {
+		return nil, err
+	}
+	if reportFile == "" {
+		reportFile = batchFile + ".report.csv"
+	}
+
+	unsigned, err := c.Flags().GetBool("unsigned")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range []string{batchFile, reportFile} {
+		if _, err := os.Stat(f); err == nil {
+			return nil, fmt.Errorf("%s already exists", f)
+		}
+	}
+
+	fields, err := openCSV(csvFile)
+	if err != nil {
+		return nil, err
+	}
+
+	sumDuplicates, err := c.Flags().GetBool("sum-duplicates")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := parseBulkSendRowsFromCSV(fields, sumDuplicates)
+	if err != nil {
+		return nil, err
+	}
+
+	wltAddr, err := fromWalletOrAddress(c)
+	if err != nil {
+		return nil, err
+	}
+
+	wlt, err := wallet.Load(wltAddr.Wallet)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	changeAddress, err := c.Flags().GetString("change-address")
+	if err != nil {
+		return nil, err
+	}
+
+	// The password is needed to sign the transactions, and to generate a fresh change address
+	freshChange := changeAddress == "" && wlt.FreshChange()
+
+	var password []byte
+	if (!unsigned || freshChange) && wlt.IsEncrypted() {
+		pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+		password, err = pr.Password()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if freshChange {
+		changeAddress, err = FreshChangeAddress(wltAddr.Wallet, apiTransactionsFinder{apiClient}, password)
+		if err != nil {
+			return nil, err
+		}
+
+		// Reload the wallet, which has the change address now
+		wlt, err = wallet.Load(wltAddr.Wallet)
+		if err != nil {
+			return nil, WalletLoadError{err}
+		}
+	}
+
+	chgAddr, err := getChangeAddress(wltAddr, changeAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	inAddrs := []string{wltAddr.Address}
+	if wltAddr.Address == "" {
+		inAddrs = nil
+		for _, a := range wlt.GetAddresses() {
+			inAddrs = append(inAddrs, a.String())
+		}
+	}
+
+	for _, a := range append([]string{chgAddr}, inAddrs...) {
+		addr, err := cipher.DecodeBase58Address(a)
+		if err != nil {
+			return nil, ErrAddress
+		}
+		if _, ok := wlt.GetEntry(addr); !ok {
+			return nil, fmt.Errorf("%v address is not in wallet", a)
+		}
+	}
+
+	txns, err := CreateBulkRawTxns(apiClient, wlt, inAddrs, chgAddr, rows, password, !unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := newBulkBatch(rows, txns, !unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := bulkReport(rows, txns)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeBulkBatchFile(batchFile, batch); err != nil {
+		return nil, err
+	}
+
+	if err := writeBulkReportFile(reportFile, report); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func printBulkBatchSummary(b *BulkBatch) {
+	if b.Summed > 0 {
+		fmt.Printf("Recipients: %d (%d duplicate rows skipped, %d rows summed)\n", b.Recipients, b.Duplicates, b.Summed)
+	} else {
+		fmt.Printf("Recipients: %d (%d duplicate rows skipped)\n", b.Recipients, b.Duplicates)
+	}
+	fmt.Printf("Coins: %s\n", b.Coins)
+	fmt.Printf("Coin hours burned: %s\n", b.Fee)
+	fmt.Printf("Transactions: %d\n", len(b.Transactions))
+	for i, t := range b.Transactions {
+		fmt.Printf("  %d: %s, %d recipients, %s coins\n", i+1, t.TxID, t.Recipients, t.Coins)
+	}
+}
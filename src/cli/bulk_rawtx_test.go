@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestParseBulkSendRowsFromCSV(t *testing.T) {
+	addr1 := "2Niqzo12tZ9ioZq5vwPHMVR4g7UVpp9TCmP"
+	addr2 := "2UDzBKnxZf4d9pdrBJAqbtoeH641RFLYKxd"
+	hours := func(h uint64) *uint64 {
+		return &h
+	}
+
+	cases := []struct {
+		name          string
+		fields        [][]string
+		sumDuplicates bool
+		rows          []BulkSendRow
+		err           error
+	}{
+		{
+			name: "duplicates",
+			fields: [][]string{
+				{addr1, "123"},
+				{addr2, " 0.5 "},
+				{" " + addr1, "123.0"},
+			},
+			rows: []BulkSendRow{
+				{Row: 0, Addr: addr1, Coins: 123e6, DuplicateOf: -1},
+				{Row: 1, Addr: addr2, Coins: 5e5, DuplicateOf: -1},
+				{Row: 2, Addr: addr1, Coins: 123e6, DuplicateOf: 0},
+			},
+		},
+		{
+			name: "same address with different amounts",
+			fields: [][]string{
+				{addr1, "123", "1"},
+				{addr2, "0.5", "1"},
+				{addr1, "1", "1"},
+				{addr2, "0.5", "2"},
+				{addr2, "0.5", "1"},
+			},
+			err: errors.New(`[row 2] Address 2Niqzo12tZ9ioZq5vwPHMVR4g7UVpp9TCmP of row 0 has different amounts. Use --sum-duplicates to send the sum of the amounts
+[row 3] Address 2UDzBKnxZf4d9pdrBJAqbtoeH641RFLYKxd of row 1 has different amounts. Use --sum-duplicates to send the sum of the amounts`),
+		},
+		{
+			name: "sum duplicates",
+			fields: [][]string{
+				{addr1, "123", "1"},
+				{addr2, "0.5", "1"},
+				{addr1, "1", "2"},
+				{addr1, "123", "1"},
+			},
+			sumDuplicates: true,
+			rows: []BulkSendRow{
+				{Row: 0, Addr: addr1, Coins: 247e6, Hours: hours(4), DuplicateOf: -1},
+				{Row: 1, Addr: addr2, Coins: 5e5, Hours: hours(1), DuplicateOf: -1},
+				{Row: 2, Addr: addr1, Coins: 1e6, Hours: hours(2), DuplicateOf: 0, Summed: true},
+				{Row: 3, Addr: addr1, Coins: 123e6, Hours: hours(1), DuplicateOf: 0, Summed: true},
+			},
+		},
+		{
+			name: "sum duplicates overflow",
+			fields: [][]string{
+				{addr1, "1", "18446744073709551615"},
+				{addr1, "1", "1"},
+			},
+			sumDuplicates: true,
+			err:           errors.New("[row 1] Cannot add the amounts to row 0: uint64 addition overflow"),
+		},
+		{
+			name: "hours",
+			fields: [][]string{
+				{addr1, "1", "10"},
+				{addr2, "2", "0"},
+			},
+			rows: []BulkSendRow{
+				{Row: 0, Addr: addr1, Coins: 1e6, Hours: hours(10), DuplicateOf: -1},
+				{Row: 1, Addr: addr2, Coins: 2e6, Hours: hours(0), DuplicateOf: -1},
+			},
+		},
+		{
+			name: "invalid rows",
+			fields: [][]string{
+				{"xxx", "1"},
+				{addr1, "0.0001"},
+				{addr1, "0"},
+				{addr2, "1.5"},
+				{addr2, "0.1234567"},
+			},
+			err: errors.New(`[row 0] Invalid address xxx: Invalid address length
+[row 1] Invalid amount 0.0001: invalid amount, too many decimal places
+[row 2] Cannot send 0 coins
+[row 4] Invalid amount 0.1234567: Droplet string conversion failed: Too many decimal places`),
+		},
+		{
+			name: "invalid hours",
+			fields: [][]string{
+				{addr1, "1", "-1"},
+			},
+			err: errors.New(`[row 0] Invalid hours -1: strconv.ParseUint: parsing "-1": invalid syntax`),
+		},
+		{
+			name: "inconsistent columns",
+			fields: [][]string{
+				{addr1, "1", "1"},
+				{addr2, "1"},
+				{addr2},
+			},
+			err: errors.New(`[row 1] Expected 3 columns of address, coins and optionally hours, found 2
+[row 2] Expected 3 columns of address, coins and optionally hours, found 1`),
+		},
+		{
+			name: "empty",
+			err:  errors.New("No destination addresses"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := parseBulkSendRowsFromCSV(tc.fields, tc.sumDuplicates)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				require.Nil(t, rows)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.rows, rows)
+		})
+	}
+}
+
+func TestCreateBulkRawTxns(t *testing.T) {
+	wlt, err := GenerateWallet("t.wlt", wallet.Options{
+		Seed:       "seed",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: wallet.CryptoTypeSha256Xor,
+	}, 2)
+	require.NoError(t, err)
+
+	headTime := uint64(2000)
+	var confirmed []visor.UnspentOutput
+	for i := 0; i < 10; i++ {
+		ux := coin.UxOut{
+			Head: coin.UxHead{
+				Time:  1000,
+				BkSeq: 1,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        wlt.Entries[i%2].SkycoinAddress(),
+				Coins:          10e6,
+				Hours:          1000,
+			},
+		}
+		uo, err := visor.NewUnspentOutput(ux, headTime)
+		require.NoError(t, err)
+		confirmed = append(confirmed, uo)
+	}
+
+	summary, err := readable.NewUnspentOutputsSummary(&visor.UnspentOutputsSummary{
+		HeadBlock: &coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: 2,
+					Time:  headTime,
+				},
+			},
+		},
+		Confirmed: confirmed,
+	})
+	require.NoError(t, err)
+	c := (*mockOutputser)(summary)
+
+	inAddrs := []string{wlt.Entries[0].SkycoinAddress().String(), wlt.Entries[1].SkycoinAddress().String()}
+	chgAddr := wlt.Entries[0].SkycoinAddress().String()
+
+	// More recipients than fit in a transaction, with a duplicate and a row summed into another.
+	// The addresses are made from hashes, since generating keys for each would be slow.
+	nRows := 2 * int(params.UserVerifyTxn.MaxTransactionSize) / 37
+	rows := make([]BulkSendRow, nRows)
+	for i := range rows {
+		addr := cipher.Address{
+			Key: cipher.HashRipemd160([]byte(fmt.Sprint(i))),
+		}
+		rows[i] = BulkSendRow{
+			Row:         i,
+			Addr:        addr.String(),
+			Coins:       1e3,
+			DuplicateOf: -1,
+		}
+	}
+	rows[5].Addr = rows[2].Addr
+	rows[5].DuplicateOf = 2
+	rows[7].Addr = rows[3].Addr
+	rows[7].DuplicateOf = 3
+	rows[7].Summed = true
+	rows[3].Coins += rows[7].Coins
+
+	_, err = CreateBulkRawTxns(c, wlt, inAddrs, chgAddr, rows, []byte("wrong"), true)
+	require.Equal(t, wallet.ErrInvalidPassword, err)
+
+	txns, err := CreateBulkRawTxns(c, wlt, inAddrs, chgAddr, rows, []byte("pwd"), true)
+	require.NoError(t, err)
+	require.Len(t, txns, 3)
+
+	var sent []int
+	spent := make(map[cipher.SHA256]struct{})
+	for _, txn := range txns {
+		size, err := txn.Transaction.Size()
+		require.NoError(t, err)
+		require.True(t, size <= params.UserVerifyTxn.MaxTransactionSize)
+		require.True(t, txn.Transaction.IsFullySigned())
+		require.NoError(t, txn.Transaction.Verify())
+
+		// The transactions spend different outputs
+		for _, h := range txn.Transaction.In {
+			_, ok := spent[h]
+			require.False(t, ok)
+			spent[h] = struct{}{}
+		}
+
+		for j, i := range txn.Rows {
+			require.Equal(t, rows[i].Addr, txn.Transaction.Out[j].Address.String())
+			require.Equal(t, rows[i].Coins, txn.Transaction.Out[j].Coins)
+		}
+		require.Len(t, txn.Transaction.Out, len(txn.Rows)+1)
+		require.Equal(t, chgAddr, txn.Transaction.Out[len(txn.Rows)].Address.String())
+		require.NotZero(t, txn.Fee)
+
+		sent = append(sent, txn.Rows...)
+	}
+
+	// Every row but the duplicate and the summed row is sent once, in order
+	require.Len(t, sent, nRows-2)
+	for i, j := range sent {
+		switch {
+		case i < 5:
+			require.Equal(t, i, j)
+		case i < 6:
+			require.Equal(t, i+1, j)
+		default:
+			require.Equal(t, i+2, j)
+		}
+	}
+
+	batch, err := newBulkBatch(rows, txns, true)
+	require.NoError(t, err)
+	require.True(t, batch.Signed)
+	require.Equal(t, nRows-2, batch.Recipients)
+	require.Equal(t, 1, batch.Duplicates)
+	require.Equal(t, 1, batch.Summed)
+	require.Len(t, batch.Transactions, 3)
+	require.Equal(t, txns[0].Transaction.Hash().Hex(), batch.Transactions[0].TxID)
+	rawTxn, err := coin.DeserializeTransactionHex(batch.Transactions[0].RawTx)
+	require.NoError(t, err)
+	require.Equal(t, txns[0].Transaction, rawTxn)
+
+	report, err := bulkReport(rows, txns)
+	require.NoError(t, err)
+	require.Len(t, report, nRows+1)
+	require.Equal(t, bulkReportHeader, report[0])
+	require.Equal(t, []string{"0", rows[0].Addr, "0.001000", fmt.Sprint(txns[0].Transaction.Out[0].Hours), "included", txns[0].Transaction.Hash().Hex(), "0"}, report[1])
+	require.Equal(t, []string{"5", rows[5].Addr, "0.001000", "", "duplicate of row 2", "", ""}, report[6])
+	require.Equal(t, []string{"7", rows[7].Addr, "0.001000", "", "summed into row 3", "", ""}, report[8])
+	require.Equal(t, "0.002000", report[4][2])
+
+	// Unsigned transactions don't need the password
+	txns, err = CreateBulkRawTxns(c, wlt, inAddrs, chgAddr, rows, nil, false)
+	require.NoError(t, err)
+	require.Len(t, txns, 3)
+	for _, txn := range txns {
+		require.False(t, txn.Transaction.IsFullySigned())
+		require.Equal(t, make([]cipher.Sig, len(txn.Transaction.In)), txn.Transaction.Sigs)
+	}
+
+	// Coin hours of the rows are sent with the manual hours selection
+	rows = []BulkSendRow{
+		{
+			Row:         0,
+			Addr:        testutil.MakeAddress().String(),
+			Coins:       1e6,
+			Hours:       new(uint64),
+			DuplicateOf: -1,
+		},
+		{
+			Row:         1,
+			Addr:        testutil.MakeAddress().String(),
+			Coins:       2e6,
+			Hours:       new(uint64),
+			DuplicateOf: -1,
+		},
+	}
+	*rows[0].Hours = 7
+	*rows[1].Hours = 300
+
+	txns, err = CreateBulkRawTxns(c, wlt, inAddrs, chgAddr, rows, []byte("pwd"), true)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	require.Equal(t, []int{0, 1}, txns[0].Rows)
+	require.Equal(t, uint64(7), txns[0].Transaction.Out[0].Hours)
+	require.Equal(t, uint64(300), txns[0].Transaction.Out[1].Hours)
+
+	report, err = bulkReport(rows, txns)
+	require.NoError(t, err)
+	require.Equal(t, "300", report[2][3])
+
+	// Not enough coins for every row
+	rows[1].Coins = 100e6
+	_, err = CreateBulkRawTxns(c, wlt, inAddrs, chgAddr, rows, []byte("pwd"), true)
+	require.EqualError(t, err, "[row 0] balance is not sufficient")
+
+	// Watch-only wallets can't sign
+	xpubWlt, err := wallet.NewWallet("x.wlt", wallet.Options{
+		Type: wallet.WalletTypeXPub,
+		XPub: "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj",
+	})
+	require.NoError(t, err)
+	_, err = CreateBulkRawTxns(c, xpubWlt, inAddrs, chgAddr, rows, nil, true)
+	require.Equal(t, wallet.ErrWalletCantSign, err)
+}
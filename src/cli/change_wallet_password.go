@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func changeWalletPasswordCmd() *gcli.Command {
+	changeWalletPasswordCmd := &gcli.Command{
+		Short: "Change the password of an encrypted wallet",
+		Use:   "changeWalletPassword",
+		Long: fmt.Sprintf(`The default wallet (%s) will be used if no wallet was specified.
+
+    The wallet is re-encrypted with the new password. Use the "-x" option to
+    migrate the wallet to another crypto type at the same time, for example from
+    sha256-xor to scrypt-chacha20poly1305. The previous wallet file is kept as
+    a .bak file next to the wallet.
+
+    Use caution when using the "-p" and "-n" commands. If you have command history
+    enabled your wallet encryption passwords can be recovered from the history log.
+    If you do not include the "-p" or "-n" options you will be prompted to enter
+    the passwords after you enter your command.`, cliConfig.FullWalletPath()),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, _ []string) error {
+			w, err := resolveWalletPath(cliConfig, "")
+			if err != nil {
+				return err
+			}
+
+			var cryptoType wallet.CryptoType
+			if ct := c.Flag("crypto-type").Value.String(); ct != "" {
+				cryptoType, err = wallet.CryptoTypeFromString(ct)
+				if err != nil {
+					printHelp(c)
+					return err
+				}
+			}
+
+			oldPr := newPromptPasswordReader([]byte(c.Flag("password").Value.String()), "enter current password:")
+			newPr := newPromptPasswordReader([]byte(c.Flag("new-password").Value.String()), "enter new password:")
+
+			wlt, err := changeWalletPassword(w, oldPr, newPr, cryptoType)
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				printHelp(c)
+				return err
+			default:
+				return err
+			}
+
+			return printJSON(wallet.NewReadableWallet(wlt))
+		},
+	}
+
+	changeWalletPasswordCmd.Flags().StringP("password", "p", "", "current wallet password")
+	changeWalletPasswordCmd.Flags().StringP("new-password", "n", "", "new wallet password")
+	changeWalletPasswordCmd.Flags().StringP("crypto-type", "x", "", "The crypto type to re-encrypt the wallet with, can be scrypt-chacha20poly1305, argon2id-chacha20poly1305 or sha256-xor. Defaults to the wallet's current crypto type")
+	return changeWalletPasswordCmd
+}
+
+func changeWalletPassword(walletFile string, oldPr, newPr PasswordReader, cryptoType wallet.CryptoType) (*wallet.Wallet, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	if !wlt.IsEncrypted() {
+		return nil, wallet.ErrWalletNotEncrypted
+	}
+
+	oldPassword, err := oldPr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	newPassword, err := newPr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wlt.ChangePassword(oldPassword, newPassword, cryptoType); err != nil {
+		return nil, err
+	}
+
+	// Backs up the wallet file encrypted with the old password
+	b, err := ioutil.ReadFile(walletFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.SaveBinary(walletFile+".bak", b, 0600); err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(walletFile))
+	if err != nil {
+		return nil, err
+	}
+
+	// save the wallet
+	if err := wlt.Save(dir); err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	return wlt, nil
+}
+
+// promptPasswordReader reads the password from the terminal with a custom prompt
+type promptPasswordReader string
+
+// Password implements the PasswordReader's Password method
+func (p promptPasswordReader) Password() ([]byte, error) {
+	return readPasswordFromTerminalPrompt(string(p))
+}
+
+// newPromptPasswordReader reads password from the input bytes first,
+// if it's empty, then read from terminal with the given prompt.
+func newPromptPasswordReader(p []byte, prompt string) PasswordReader {
+	if len(p) != 0 {
+		return PasswordFromBytes(p)
+	}
+
+	return promptPasswordReader(prompt)
+}
@@ -226,21 +226,30 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		addressBalanceCmd(),
 		addressGenCmd(),
 		fiberAddressGenCmd(),
+		paperWalletGenCmd(),
+		paperWalletDecryptCmd(),
 		addressOutputsCmd(),
 		blocksCmd(),
 		broadcastTxCmd(),
 		checkDBCmd(),
 		checkDBEncodingCmd(),
+		combinePartialTxnsCmd(),
+		createPartialTxnCmd(),
 		createRawTxnCmd(),
 		decodeRawTxnCmd(),
 		decryptWalletCmd(),
 		encryptWalletCmd(),
+		changeWalletPasswordCmd(),
+		finalizePartialTxnCmd(),
+		inspectPartialTxnCmd(),
 		lastBlocksCmd(),
 		listAddressesCmd(),
 		listWalletsCmd(),
 		sendCmd(),
 		showConfigCmd(),
 		showSeedCmd(),
+		signPartialTxnCmd(),
+		signerServeCmd(),
 		statusCmd(),
 		transactionCmd(),
 		verifyTransactionCmd(),
@@ -250,8 +259,19 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		walletAddAddressesCmd(),
 		walletBalanceCmd(),
 		walletDirCmd(),
+		walletExportCmd(),
+		walletImportCmd(),
 		walletHisCmd(),
 		walletOutputsCmd(),
+		walletFrozenOutputsCmd(),
+		walletFreezeOutputsCmd(),
+		walletUnfreezeOutputsCmd(),
+		walletPayoutAddCmd(),
+		walletPayoutsCmd(),
+		walletPayoutCancelCmd(),
+		walletSweepCmd(),
+		walletConsolidateCmd(),
+		previewTransactionCmd(),
 		richlistCmd(),
 		addressTransactionsCmd(),
 		pendingTransactionsCmd(),
@@ -294,8 +314,13 @@ func printJSON(obj interface{}) error {
 
 // readPasswordFromTerminal promotes user to enter password and read it.
 func readPasswordFromTerminal() ([]byte, error) {
+	return readPasswordFromTerminalPrompt("enter password:")
+}
+
+// readPasswordFromTerminalPrompt promotes user to enter password with the given prompt and read it.
+func readPasswordFromTerminalPrompt(prompt string) ([]byte, error) {
 	// Promotes to enter the wallet password
-	fmt.Fprint(os.Stdout, "enter password:")
+	fmt.Fprint(os.Stdout, prompt)
 	bp, err := terminal.ReadPassword(int(syscall.Stdin)) // nolint: unconvert
 	if err != nil {
 		return nil, err
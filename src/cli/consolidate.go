@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func walletConsolidateCmd() *gcli.Command {
+	walletConsolidateCmd := &gcli.Command{
+		Short: "Consolidate the smallest unspent outputs of a wallet into a single output",
+		Use:   "walletConsolidate [flags] [wallet id]",
+		Long: `Send the smallest unspent outputs of a wallet to a single output of one
+    of its addresses, so that the wallet can spend its coins in fewer and
+    smaller transactions. Frozen outputs are not spent.
+
+    The outputs are spent in one or more transactions, each within the
+    maximum transaction size. The consolidation is previewed first, showing
+    the number of outputs spent and the coin hours burned for the fee, and
+    executed after confirmation. Use "--preview" to only show the preview,
+    or "-y" to skip the confirmation.
+
+    The consolidation is refused if its transactions differ from the
+    confirmed preview, for example if the wallet received outputs in the
+    meantime. With "-y", use "--expected-fee" to refuse the consolidation
+    unless it burns the given coin hours.
+
+    You will be prompted for the wallet password if the wallet is encrypted
+    and the "-p" option is not used.`,
+		Args:         gcli.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			preview, err := c.Flags().GetBool("preview")
+			if err != nil {
+				return err
+			}
+
+			yes, err := c.Flags().GetBool("yes")
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput && !preview && !yes {
+				printHelp(c)
+				return errors.New("--json requires --preview or --yes")
+			}
+
+			address, err := c.Flags().GetString("address")
+			if err != nil {
+				return err
+			}
+
+			maxInputs, err := c.Flags().GetInt("max-inputs")
+			if err != nil {
+				return err
+			}
+
+			maxTransactions, err := c.Flags().GetInt("max-transactions")
+			if err != nil {
+				return err
+			}
+
+			expectedFee, err := c.Flags().GetString("expected-fee")
+			if err != nil {
+				return err
+			}
+
+			if expectedFee != "" && !yes {
+				printHelp(c)
+				return errors.New("--expected-fee requires --yes")
+			}
+
+			req := api.WalletConsolidateRequest{
+				ID:              args[0],
+				Address:         address,
+				MaxInputs:       maxInputs,
+				MaxTransactions: maxTransactions,
+				Preview:         true,
+			}
+
+			if !yes {
+				rsp, err := apiClient.WalletConsolidate(req)
+				if err != nil {
+					return err
+				}
+
+				if preview {
+					if jsonOutput {
+						return printJSON(rsp)
+					}
+					printConsolidateSummary(rsp)
+					return nil
+				}
+
+				printConsolidateSummary(rsp)
+				ok, err := confirm("Continue? [y/N] ")
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errors.New("consolidation cancelled")
+				}
+
+				for _, txn := range rsp.Transactions {
+					req.ExpectedInnerHashes = append(req.ExpectedInnerHashes, txn.Transaction.InnerHash)
+				}
+				expectedFee = rsp.Fee
+			} else if preview {
+				printHelp(c)
+				return errors.New("--preview and --yes cannot be combined")
+			}
+
+			wlt, err := apiClient.Wallet(args[0])
+			if err != nil {
+				return err
+			}
+
+			var password []byte
+			if wlt.Meta.Encrypted {
+				pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+				password, err = pr.Password()
+				if err != nil {
+					return err
+				}
+			}
+
+			req.Password = string(password)
+			req.Preview = false
+			req.ExpectedFee = expectedFee
+			rsp, err := apiClient.WalletConsolidate(req)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			fmt.Printf("Consolidated %d outputs in %d transactions, burning %s coin hours\n", rsp.Inputs, len(rsp.Transactions), rsp.Fee)
+			for _, txn := range rsp.Transactions {
+				fmt.Printf("txid: %s\n", txn.Transaction.TxID)
+			}
+			return nil
+		},
+	}
+
+	walletConsolidateCmd.Flags().StringP("address", "a", "", "wallet address which receives the outputs, defaults to the first address of the wallet")
+	walletConsolidateCmd.Flags().Int("max-inputs", 0, "maximum number of inputs of a transaction, defaults to as many as fit in a transaction")
+	walletConsolidateCmd.Flags().IntP("max-transactions", "n", 1, "maximum number of transactions to create, at most 10")
+	walletConsolidateCmd.Flags().StringP("password", "p", "", "wallet password")
+	walletConsolidateCmd.Flags().String("expected-fee", "", "coin hours the consolidation must burn, used with --yes")
+	walletConsolidateCmd.Flags().Bool("preview", false, "only preview the consolidation, don't execute it")
+	walletConsolidateCmd.Flags().BoolP("yes", "y", false, "execute the consolidation without confirmation")
+	walletConsolidateCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return walletConsolidateCmd
+}
+
+func printConsolidateSummary(rsp *api.WalletConsolidateResponse) {
+	fmt.Printf("Outputs to consolidate: %d\n", rsp.Inputs)
+	fmt.Printf("Transactions: %d\n", len(rsp.Transactions))
+	for i, txn := range rsp.Transactions {
+		out := txn.Transaction.Out[0]
+		fmt.Printf("  %d: %d outputs -> %s coins and %s coin hours to %s\n", i+1, len(txn.Transaction.In), out.Coins, out.Hours, out.Address)
+	}
+	fmt.Printf("Coin hours burned: %s\n", rsp.Fee)
+}
+
+// confirm prints prompt and reads a yes or no answer from stdin, defaulting to no
+func confirm(prompt string) (bool, error) {
+	fmt.Print(prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/transaction"
@@ -51,6 +54,17 @@ func createRawTxnCmd() *cobra.Command {
     from all addresses within the wallet starting with the first address until
     the amount of the transaction is met.
 
+    Use "--batch-file" with "--csv" for a bulk distribution to thousands of
+    recipients. Each row of the CSV file has an address, an amount of coins and
+    optionally an amount of coin hours. Every row is validated, rows repeating
+    an earlier row are skipped, and the recipients are split into as many
+    transactions as needed to stay within the maximum transaction size. Rows
+    with the address of an earlier row but different amounts are rejected,
+    unless "--sum-duplicates" is used to send the sum of their amounts.
+    The transactions are written to the batch file, signed unless "--unsigned"
+    is used, and a reconciliation report of every row is written to the report
+    file. The transactions spend different outputs and can be broadcast together.
+
     Use caution when using the "-p" command. If you have command history enabled
     your wallet encryption password can be recovered from the history log. If you
     do not include the "-p" option you will be prompted to enter your password
@@ -63,6 +77,37 @@ func createRawTxnCmd() *cobra.Command {
 				return err
 			}
 
+			batchFile, err := c.Flags().GetString("batch-file")
+			if err != nil {
+				return err
+			}
+
+			if batchFile != "" {
+				batch, err := bulkRawTxnCmdHandler(c, args, batchFile)
+				switch err.(type) {
+				case nil:
+				case WalletLoadError:
+					printHelp(c)
+					return err
+				default:
+					return err
+				}
+
+				if jsonOutput {
+					return printJSON(batch)
+				}
+
+				printBulkBatchSummary(batch)
+				return nil
+			}
+
+			for _, f := range []string{"report", "unsigned", "sum-duplicates"} {
+				if c.Flags().Changed(f) {
+					printHelp(c)
+					return fmt.Errorf("--%s requires --batch-file", f)
+				}
+			}
+
 			txn, err := createRawTxnCmdHandler(c, args)
 			switch err.(type) {
 			case nil:
@@ -95,12 +140,18 @@ func createRawTxnCmd() *cobra.Command {
 	createRawTxnCmd.Flags().StringP("wallet-file", "f", "", "wallet file or path. If no path is specified your default wallet path will be used.")
 	createRawTxnCmd.Flags().StringP("address", "a", "", "From address")
 	createRawTxnCmd.Flags().StringP("change-address", "c", "", `Specify different change address.
-By default the from address or a wallets coinbase address will be used.`)
+By default the from address or a wallets coinbase address will be used,
+or a fresh change address if the wallet was created with --fresh-change.`)
 	createRawTxnCmd.Flags().StringP("many", "m", "", `use JSON string to set multiple receive addresses and coins,
 example: -m '[{"addr":"$addr1", "coins": "10.2"}, {"addr":"$addr2", "coins": "20"}]'`)
 	createRawTxnCmd.Flags().StringP("password", "p", "", "Wallet password")
 	createRawTxnCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
 	createRawTxnCmd.Flags().String("csv", "", "CSV file containing addresses and amounts to send")
+	createRawTxnCmd.Flags().String("batch-file", "", `bulk distribution mode, write the transactions to the --csv recipients to this file,
+split into as many transactions as needed`)
+	createRawTxnCmd.Flags().String("report", "", "reconciliation report file of a bulk distribution, defaults to the batch file name with .report.csv appended")
+	createRawTxnCmd.Flags().Bool("unsigned", false, "don't sign the transactions of a bulk distribution")
+	createRawTxnCmd.Flags().Bool("sum-duplicates", false, "send the sum of the amounts of the bulk distribution rows with the same address")
 
 	return createRawTxnCmd
 }
@@ -353,11 +404,56 @@ func parseCreateRawTxnArgs(c *cobra.Command, args []string) (*createRawTxnArgs,
 }
 
 func createRawTxnCmdHandler(c *cobra.Command, args []string) (*coin.Transaction, error) {
+	parsedArgs, err := prepareCreateRawTxnArgs(c, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return createRawTxnFromArgs(parsedArgs)
+}
+
+// prepareCreateRawTxnArgs parses the createRawTransaction args and sets a fresh change address
+// if none is specified and the wallet has the fresh change option
+func prepareCreateRawTxnArgs(c *cobra.Command, args []string) (*createRawTxnArgs, error) {
 	parsedArgs, err := parseCreateRawTxnArgs(c, args)
 	if err != nil {
 		return nil, err
 	}
 
+	changeAddress, err := c.Flags().GetString("change-address")
+	if err != nil {
+		return nil, err
+	}
+
+	if changeAddress == "" {
+		wlt, err := wallet.Load(parsedArgs.WalletID)
+		if err != nil {
+			return nil, WalletLoadError{err}
+		}
+
+		if wlt.FreshChange() {
+			var password []byte
+			if wlt.IsEncrypted() {
+				password, err = parsedArgs.Password.Password()
+				if err != nil {
+					return nil, err
+				}
+				// Avoid prompting for the password again
+				parsedArgs.Password = PasswordFromBytes(password)
+			}
+
+			chgAddr, err := FreshChangeAddress(parsedArgs.WalletID, apiTransactionsFinder{apiClient}, password)
+			if err != nil {
+				return nil, err
+			}
+			parsedArgs.ChangeAddress = chgAddr
+		}
+	}
+
+	return parsedArgs, nil
+}
+
+func createRawTxnFromArgs(parsedArgs *createRawTxnArgs) (*coin.Transaction, error) {
 	if parsedArgs.Address == "" {
 		return CreateRawTxnFromWallet(apiClient, parsedArgs.WalletID, parsedArgs.ChangeAddress, parsedArgs.SendAmounts, parsedArgs.Password)
 	}
@@ -498,6 +594,37 @@ func CreateRawTxnFromAddress(c GetOutputser, addr, walletFile, chgAddr string, t
 	return CreateRawTxn(c, wlt, []string{addr}, chgAddr, toAddrs, password)
 }
 
+// FreshChangeAddress returns a change address with no transaction history from a wallet file with the fresh change option.
+// The address is recorded as issued in the saved wallet file, see wallet.Wallet.FreshChangeAddress.
+// The password is required to generate an address for an encrypted wallet.
+// Returns an empty string if the wallet doesn't have the fresh change option.
+func FreshChangeAddress(walletFile string, tf wallet.TransactionsFinder, password []byte) (string, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return "", WalletLoadError{err}
+	}
+
+	if !wlt.FreshChange() {
+		return "", nil
+	}
+
+	addr, err := wlt.FreshChangeAddress(password, tf)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(walletFile))
+	if err != nil {
+		return "", err
+	}
+
+	if err := wlt.Save(dir); err != nil {
+		return "", WalletSaveError{err}
+	}
+
+	return addr.String(), nil
+}
+
 // GetOutputser implements unspent output querying
 type GetOutputser interface {
 	OutputsForAddresses([]string) (*readable.UnspentOutputsSummary, error)
@@ -509,6 +636,11 @@ func CreateRawTxn(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr
 		return nil, err
 	}
 
+	// Watch-only wallets have no secret keys to sign the transaction
+	if wlt.Type() == wallet.WalletTypeXPub {
+		return nil, wallet.ErrWalletCantSign
+	}
+
 	// Get unspent outputs of those addresses
 	outputs, err := c.OutputsForAddresses(inAddrs)
 	if err != nil {
@@ -553,6 +685,130 @@ func CreateRawTxn(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr
 	return txn, nil
 }
 
+// CreateSplitRawTxns creates the signed transactions of a spend from a set of addresses contained in a loaded *wallet.Wallet.
+// If the spend fits in a transaction no larger than the maximum transaction size, it is the only transaction.
+// Otherwise the spend is split into consolidation transactions, which send the chosen unspent outputs to
+// a single output of the wallet each, followed by the payment transaction, which is last.
+// Coin hours are distributed with the auto share mode and a share factor of 0.5, see transaction.CreateSplit.
+// The payment transaction spends the outputs of the consolidation transactions, so it can only be injected
+// once they are confirmed.
+func CreateSplitRawTxns(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr string, toAddrs []SendAmount, password []byte) ([]coin.Transaction, error) {
+	if err := validateSendAmounts(toAddrs); err != nil {
+		return nil, err
+	}
+
+	// Watch-only wallets have no secret keys to sign the transactions
+	if wlt.Type() == wallet.WalletTypeXPub {
+		return nil, wallet.ErrWalletCantSign
+	}
+
+	cAddr, err := cipher.DecodeBase58Address(chgAddr)
+	if err != nil {
+		return nil, ErrAddress
+	}
+
+	to := make([]coin.TransactionOutput, len(toAddrs))
+	for i, a := range toAddrs {
+		to[i] = mustMakeUtxoOutput(a.Addr, a.Coins, 0)
+	}
+
+	shareFactor := decimal.New(5, -1)
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type:        transaction.HoursSelectionTypeAuto,
+			Mode:        transaction.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		ChangeAddress: &cAddr,
+		To:            to,
+		FrozenUxOuts:  wlt.FrozenUxOuts(),
+	}
+
+	// Get unspent outputs of those addresses
+	outputs, err := c.OutputsForAddresses(inAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	inUxs, err := outputs.SpendableOutputs().ToUxArray()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := outputs.Head.ToCoinBlockHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	f := func(w *wallet.Wallet) ([]coin.Transaction, [][]transaction.UxBalance, error) {
+		return transaction.CreateSplit(p, coin.NewAddressUxOuts(inUxs), head.Time, params.UserVerifyTxn.MaxTransactionSize, func(txn *coin.Transaction, inputs []transaction.UxBalance) error {
+			keys, err := getKeys(w, inputs)
+			if err != nil {
+				return err
+			}
+
+			for i, k := range keys {
+				if err := txn.SignInput(k, i); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	var txns []coin.Transaction
+	var inputs [][]transaction.UxBalance
+	if wlt.IsEncrypted() {
+		err = wlt.GuardView(password, func(w *wallet.Wallet) error {
+			var err error
+			txns, inputs, err = f(w)
+			return err
+		})
+	} else {
+		txns, inputs, err = f(wlt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inUxsMap := make(map[cipher.SHA256]coin.UxOut, len(inUxs))
+	for _, u := range inUxs {
+		inUxsMap[u.Hash()] = u
+	}
+
+	for i, txn := range txns {
+		if err := visor.VerifySingleTxnUserConstraints(txn); err != nil {
+			return nil, err
+		}
+
+		// The payment transaction and chained consolidation transactions of a split
+		// spend the outputs of the split's other transactions, which don't exist yet
+		uxIn := make(coin.UxArray, len(inputs[i]))
+		spendsSplitOutputs := false
+		for j, in := range inputs[i] {
+			ux, ok := inUxsMap[in.Hash]
+			if !ok {
+				spendsSplitOutputs = true
+				break
+			}
+			uxIn[j] = ux
+		}
+		if spendsSplitOutputs {
+			continue
+		}
+
+		if err := visor.VerifySingleTxnSoftConstraints(txn, head.Time, uxIn, params.UserVerifyTxn); err != nil {
+			return nil, err
+		}
+		if err := visor.VerifySingleTxnHardConstraints(txn, head, uxIn, visor.TxnSigned); err != nil {
+			return nil, err
+		}
+	}
+
+	return txns, nil
+}
+
 func createRawTxn(uxouts *readable.UnspentOutputsSummary, wlt *wallet.Wallet, chgAddr string, toAddrs []SendAmount, password []byte) (*coin.Transaction, error) {
 	// Calculate total required coins
 	var totalCoins uint64
@@ -564,7 +820,8 @@ func createRawTxn(uxouts *readable.UnspentOutputsSummary, wlt *wallet.Wallet, ch
 		}
 	}
 
-	spendOutputs, err := chooseSpends(uxouts, totalCoins)
+	// Outputs frozen in the wallet are not spent
+	spendOutputs, err := chooseSpends(withoutFrozenOutputs(uxouts, wlt.FrozenUxOuts()), totalCoins)
 	if err != nil {
 		return nil, err
 	}
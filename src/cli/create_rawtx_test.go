@@ -2,15 +2,22 @@ package cli
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/transaction"
 	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
 )
 
 func TestMakeChangeOut(t *testing.T) {
@@ -598,3 +605,152 @@ func TestParseSendAmountsFromCSV(t *testing.T) {
 		})
 	}
 }
+
+type mockTransactionsFinder map[cipher.Address]bool
+
+func (mt mockTransactionsFinder) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	active := make([]bool, len(addrs))
+	for i, a := range addrs {
+		active[i] = mt[a]
+	}
+	return active, nil
+}
+
+func TestFreshChangeAddress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-fresh-change")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wlt, err := GenerateWallet("t.wlt", wallet.Options{
+		Seed:        "seed",
+		Encrypt:     true,
+		Password:    []byte("pwd"),
+		CryptoType:  wallet.CryptoTypeSha256Xor,
+		FreshChange: true,
+	}, 1)
+	require.NoError(t, err)
+	require.NoError(t, wlt.Save(dir))
+	walletFile := filepath.Join(dir, "t.wlt")
+
+	tf := mockTransactionsFinder{}
+
+	// The password is required to generate a change address
+	_, err = FreshChangeAddress(walletFile, tf, nil)
+	require.Equal(t, wallet.ErrFreshChangeEncrypted, err)
+
+	addr, err := FreshChangeAddress(walletFile, tf, []byte("pwd"))
+	require.NoError(t, err)
+
+	wlt, err = wallet.Load(walletFile)
+	require.NoError(t, err)
+	require.True(t, wlt.IsEncrypted())
+	require.Len(t, wlt.Entries, 2)
+	require.Equal(t, addr, wlt.Entries[1].SkycoinAddress().String())
+	require.Equal(t, wallet.EntryPurposeChange, wlt.Entries[1].Purpose)
+
+	// The change address is issued, so it is not given to another transaction
+	require.Equal(t, []cipher.Address{wlt.Entries[1].SkycoinAddress()}, wlt.IssuedChangeAddresses())
+
+	_, err = FreshChangeAddress(walletFile, tf, nil)
+	require.Equal(t, wallet.ErrFreshChangeEncrypted, err)
+
+	addr2, err := FreshChangeAddress(walletFile, tf, []byte("pwd"))
+	require.NoError(t, err)
+	require.NotEqual(t, addr, addr2)
+
+	wlt, err = wallet.Load(walletFile)
+	require.NoError(t, err)
+	require.Len(t, wlt.Entries, 3)
+	require.Len(t, wlt.IssuedChangeAddresses(), 2)
+
+	// No change address without the fresh change option
+	wlt, err = GenerateWallet("u.wlt", wallet.Options{
+		Seed: "seed",
+	}, 1)
+	require.NoError(t, err)
+	require.NoError(t, wlt.Save(dir))
+
+	addr, err = FreshChangeAddress(filepath.Join(dir, "u.wlt"), tf, nil)
+	require.NoError(t, err)
+	require.Empty(t, addr)
+}
+
+type mockOutputser readable.UnspentOutputsSummary
+
+func (m *mockOutputser) OutputsForAddresses([]string) (*readable.UnspentOutputsSummary, error) {
+	return (*readable.UnspentOutputsSummary)(m), nil
+}
+
+func TestCreateSplitRawTxns(t *testing.T) {
+	wlt, err := GenerateWallet("t.wlt", wallet.Options{
+		Seed: "seed",
+	}, 2)
+	require.NoError(t, err)
+
+	headTime := uint64(2000)
+	var confirmed []visor.UnspentOutput
+	for i := 0; i < int(params.UserVerifyTxn.MaxTransactionSize/64); i++ {
+		ux := coin.UxOut{
+			Head: coin.UxHead{
+				Time:  1000,
+				BkSeq: 1,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        wlt.Entries[i%2].SkycoinAddress(),
+				Coins:          1e6,
+				Hours:          10,
+			},
+		}
+		uo, err := visor.NewUnspentOutput(ux, headTime)
+		require.NoError(t, err)
+		confirmed = append(confirmed, uo)
+	}
+
+	summary, err := readable.NewUnspentOutputsSummary(&visor.UnspentOutputsSummary{
+		HeadBlock: &coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: 2,
+					Time:  headTime,
+				},
+			},
+		},
+		Confirmed: confirmed,
+	})
+	require.NoError(t, err)
+	c := (*mockOutputser)(summary)
+
+	inAddrs := []string{wlt.Entries[0].SkycoinAddress().String(), wlt.Entries[1].SkycoinAddress().String()}
+	chgAddr := wlt.Entries[0].SkycoinAddress().String()
+	destAddr := testutil.MakeAddress()
+	toAddrs := []SendAmount{
+		{
+			Addr:  destAddr.String(),
+			Coins: uint64(len(confirmed)-5) * 1e6,
+		},
+	}
+
+	// A single transaction is too large
+	_, err = CreateRawTxn(c, wlt, inAddrs, chgAddr, toAddrs, nil)
+	require.Equal(t, visor.NewErrTxnViolatesSoftConstraint(visor.ErrTxnExceedsMaxBlockSize), err)
+
+	txns, err := CreateSplitRawTxns(c, wlt, inAddrs, chgAddr, toAddrs, nil)
+	require.NoError(t, err)
+	require.True(t, len(txns) > 2)
+	for _, txn := range txns {
+		require.True(t, txn.IsFullySigned())
+	}
+
+	payment := txns[len(txns)-1]
+	require.Equal(t, destAddr, payment.Out[0].Address)
+	require.Equal(t, toAddrs[0].Coins, payment.Out[0].Coins)
+	require.Len(t, payment.In, len(txns)-1)
+
+	// A spend which fits in a transaction is not split
+	toAddrs[0].Coins = 10e6
+	txns, err = CreateSplitRawTxns(c, wlt, inAddrs, chgAddr, toAddrs, nil)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	require.Equal(t, destAddr, txns[0].Out[0].Address)
+}
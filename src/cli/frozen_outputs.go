@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func walletFrozenOutputsCmd() *gcli.Command {
+	return &gcli.Command{
+		Short: "List the frozen outputs of a wallet",
+		Use:   "walletFrozenOutputs [wallet file]",
+		Long: fmt.Sprintf(`List the frozen outputs of a wallet, the default wallet (%s) will be
+    used if no wallet was specified.
+
+    Frozen outputs are not spent when creating transactions,
+    unless they are explicitly selected.`, cliConfig.FullWalletPath()),
+		Args:                  gcli.MaximumNArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(_ *gcli.Command, args []string) error {
+			var wltPath string
+			if len(args) == 1 {
+				wltPath = args[0]
+			}
+
+			w, err := resolveWalletPath(cliConfig, wltPath)
+			if err != nil {
+				return err
+			}
+
+			wlt, err := wallet.Load(w)
+			if err != nil {
+				return WalletLoadError{err}
+			}
+
+			return printJSON(newFrozenOutputsResult(wlt))
+		},
+	}
+}
+
+func walletFreezeOutputsCmd() *gcli.Command {
+	walletFreezeOutputsCmd := &gcli.Command{
+		Short: "Freeze outputs of a wallet",
+		Use:   "walletFreezeOutputs [flags] [uxid list]",
+		Long: fmt.Sprintf(`Freeze outputs of a wallet, so that they are not spent when
+    creating transactions unless they are explicitly selected. The default
+    wallet (%s) will be used if the wallet file or path is not specified.
+
+    The wallet file is updated directly, restart the node or reload the
+    wallet if it is loaded by a running node.`, cliConfig.FullWalletPath()),
+		Args:         gcli.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			return updateFrozenOutputs(c, args, FreezeOutputsInFile)
+		},
+	}
+
+	walletFreezeOutputsCmd.Flags().StringP("wallet-file", "f", "", "wallet file or path. If no path is specified your default wallet path will be used.")
+
+	return walletFreezeOutputsCmd
+}
+
+func walletUnfreezeOutputsCmd() *gcli.Command {
+	walletUnfreezeOutputsCmd := &gcli.Command{
+		Short: "Unfreeze outputs of a wallet",
+		Use:   "walletUnfreezeOutputs [flags] [uxid list]",
+		Long: fmt.Sprintf(`Unfreeze outputs of a wallet. The default wallet (%s) will be
+    used if the wallet file or path is not specified.
+
+    The wallet file is updated directly, restart the node or reload the
+    wallet if it is loaded by a running node.`, cliConfig.FullWalletPath()),
+		Args:         gcli.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			return updateFrozenOutputs(c, args, UnfreezeOutputsInFile)
+		},
+	}
+
+	walletUnfreezeOutputsCmd.Flags().StringP("wallet-file", "f", "", "wallet file or path. If no path is specified your default wallet path will be used.")
+
+	return walletUnfreezeOutputsCmd
+}
+
+// FrozenOutputsResult is the output of the frozen outputs commands
+type FrozenOutputsResult struct {
+	UxOuts []string `json:"unspents"`
+}
+
+func newFrozenOutputsResult(wlt *wallet.Wallet) FrozenOutputsResult {
+	hashes := wlt.FrozenUxOuts()
+	uxOuts := make([]string, len(hashes))
+	for i, h := range hashes {
+		uxOuts[i] = h.Hex()
+	}
+	return FrozenOutputsResult{
+		UxOuts: uxOuts,
+	}
+}
+
+func updateFrozenOutputs(c *gcli.Command, args []string, update func(walletFile string, uxOuts []cipher.SHA256) (*wallet.Wallet, error)) error {
+	walletFile, err := c.Flags().GetString("wallet-file")
+	if err != nil {
+		return err
+	}
+
+	w, err := resolveWalletPath(cliConfig, walletFile)
+	if err != nil {
+		return err
+	}
+
+	uxOuts := make([]cipher.SHA256, len(args))
+	for i, s := range args {
+		uxOuts[i], err = cipher.SHA256FromHex(s)
+		if err != nil {
+			return fmt.Errorf("invalid uxid %q: %v", s, err)
+		}
+	}
+
+	wlt, err := update(w, uxOuts)
+	switch err.(type) {
+	case nil:
+		return printJSON(newFrozenOutputsResult(wlt))
+	case WalletLoadError:
+		printHelp(c)
+		return err
+	default:
+		return err
+	}
+}
+
+// FreezeOutputsInFile freezes outputs in a wallet file and saves the wallet
+func FreezeOutputsInFile(walletFile string, uxOuts []cipher.SHA256) (*wallet.Wallet, error) {
+	return updateFrozenOutputsInFile(walletFile, func(w *wallet.Wallet) {
+		w.FreezeUxOuts(uxOuts)
+	})
+}
+
+// UnfreezeOutputsInFile unfreezes outputs in a wallet file and saves the wallet
+func UnfreezeOutputsInFile(walletFile string, uxOuts []cipher.SHA256) (*wallet.Wallet, error) {
+	return updateFrozenOutputsInFile(walletFile, func(w *wallet.Wallet) {
+		w.UnfreezeUxOuts(uxOuts)
+	})
+}
+
+func updateFrozenOutputsInFile(walletFile string, f func(w *wallet.Wallet)) (*wallet.Wallet, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	f(wlt)
+
+	dir, err := filepath.Abs(filepath.Dir(walletFile))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wlt.Save(dir); err != nil {
+		return nil, WalletSaveError{err}
+	}
+
+	return wlt, nil
+}
+
+// withoutFrozenOutputs returns a copy of uxouts without the frozen outputs
+func withoutFrozenOutputs(uxouts *readable.UnspentOutputsSummary, frozen []cipher.SHA256) *readable.UnspentOutputsSummary {
+	if len(frozen) == 0 {
+		return uxouts
+	}
+
+	frozenMap := make(map[string]struct{}, len(frozen))
+	for _, h := range frozen {
+		frozenMap[h.Hex()] = struct{}{}
+	}
+
+	filter := func(outs readable.UnspentOutputs) readable.UnspentOutputs {
+		var unfrozen readable.UnspentOutputs
+		for _, o := range outs {
+			if _, ok := frozenMap[o.Hash]; !ok {
+				unfrozen = append(unfrozen, o)
+			}
+		}
+		return unfrozen
+	}
+
+	summary := *uxouts
+	summary.HeadOutputs = filter(uxouts.HeadOutputs)
+	summary.IncomingOutputs = filter(uxouts.IncomingOutputs)
+	return &summary
+}
@@ -4,12 +4,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 
 	gcli "github.com/spf13/cobra"
 
+	"github.com/skycoin/skycoin/src/api"
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/bip39"
 	secp256k1 "github.com/skycoin/skycoin/src/cipher/secp256k1-go"
@@ -32,6 +34,25 @@ func walletCreateCmd() *gcli.Command {
     from the history log. If you do not include the "-p" option you will
     be prompted to enter your password after you enter your command.
 
+    Use --scan to restore a wallet from its seed. The addresses following
+    the generated addresses are checked for transaction history with the
+    node's API, until --gap-limit consecutive addresses have none. The
+    addresses up to the last one with history are added to the wallet,
+    including those that have spent all their coins. Scan progress is
+    written to stderr.
+
+    Use --fresh-change to send the change of each transaction created
+    with the send or createRawTransaction commands to a change address
+    that has never been used. bip44 wallets use their change chain.
+
+    Use --from-shares to restore a wallet from the seed share mnemonics
+    created by "showSeed --shares". The file must contain at least the
+    threshold number of shares, one per line. If the wallet file already
+    exists, the wallet is recovered instead: its addresses must match the
+    seed, its labels and settings are kept, and an encrypted wallet is
+    encrypted again with the password. Use --scan to recover an unencrypted
+    wallet.
+
     All results are returned in JSON format.`, cliConfig.FullWalletPath()),
 		SilenceUsage: true,
 		RunE:         generateWalletHandler,
@@ -40,14 +61,21 @@ func walletCreateCmd() *gcli.Command {
 	walletCreateCmd.Flags().BoolP("random", "r", false, "A random alpha numeric seed will be generated")
 	walletCreateCmd.Flags().BoolP("mnemonic", "m", false, "A mnemonic seed consisting of 12 dictionary words will be generated")
 	walletCreateCmd.Flags().StringP("seed", "s", "", "Your seed")
+	walletCreateCmd.Flags().String("from-shares", "", "File of seed share mnemonics to recover the seed from, one per line")
 	walletCreateCmd.Flags().Uint64P("num", "n", 1, `Number of addresses to generate. By default 1 address is generated.`)
 	walletCreateCmd.Flags().StringP("wallet-file", "f", cliConfig.WalletName, `Name of wallet. The final format will be "yourName.wlt".
 If no wallet name is specified a generic name will be selected.`)
 	walletCreateCmd.Flags().StringP("label", "l", "", "Label used to idetify your wallet.")
 	walletCreateCmd.Flags().BoolP("encrypt", "e", false, "Create encrypted wallet.")
 	walletCreateCmd.Flags().StringP("crypto-type", "x", string(wallet.CryptoTypeScryptChacha20poly1305),
-		"The crypto type for wallet encryption, can be scrypt-chacha20poly1305 or sha256-xor")
+		"The crypto type for wallet encryption, can be scrypt-chacha20poly1305, argon2id-chacha20poly1305 or sha256-xor")
 	walletCreateCmd.Flags().StringP("password", "p", "", "Wallet password")
+	walletCreateCmd.Flags().StringP("type", "t", wallet.WalletTypeDeterministic, "Wallet type, can be deterministic, bip44, xpub or collection. bip44 wallets require a bip39 mnemonic seed")
+	walletCreateCmd.Flags().String("seed-passphrase", "", "bip39 seed passphrase, only used by bip44 wallets")
+	walletCreateCmd.Flags().String("xpub", "", "bip32 extended public key, required for xpub (watch-only) wallets")
+	walletCreateCmd.Flags().Bool("scan", false, "Discover the addresses with transaction history using the node's API. Requires a running node")
+	walletCreateCmd.Flags().Uint64("gap-limit", wallet.DefaultGapLimit, "Number of consecutive addresses without transaction history after which --scan stops")
+	walletCreateCmd.Flags().Bool("fresh-change", false, "Send the change of each transaction to a new change address, not supported by collection wallets")
 
 	return walletCreateCmd
 }
@@ -73,9 +101,15 @@ func generateWalletHandler(c *gcli.Command, _ []string) error {
 		return fmt.Errorf("wallet file name must not contain path")
 	}
 
-	// check if the wallet file does exist
+	fromShares := c.Flag("from-shares").Value.String()
+
+	// check if the wallet file does exist. An existing wallet can be recovered from seed shares
+	wltExists := false
 	if _, err := os.Stat(filepath.Join(cliConfig.WalletDir, wltName)); err == nil {
-		return fmt.Errorf("%v already exist", wltName)
+		if fromShares == "" {
+			return fmt.Errorf("%v already exist", wltName)
+		}
+		wltExists = true
 	}
 
 	// check if the wallet dir does exist.
@@ -112,17 +146,113 @@ func generateWalletHandler(c *gcli.Command, _ []string) error {
 		return err
 	}
 
-	sd, err := makeSeed(s, random, mnemonic)
+	walletType := c.Flag("type").Value.String()
+	if !wallet.IsValidWalletType(walletType) {
+		return wallet.ErrInvalidWalletType
+	}
+
+	seedPassphrase := c.Flag("seed-passphrase").Value.String()
+	if seedPassphrase != "" && walletType != wallet.WalletTypeBip44 {
+		return errors.New("--seed-passphrase is only supported for bip44 wallets")
+	}
+
+	if walletType == wallet.WalletTypeBip44 && random {
+		return errors.New("-r can't be used for bip44 wallets, bip44 wallets require a bip39 mnemonic seed")
+	}
+
+	xpub := c.Flag("xpub").Value.String()
+	if xpub != "" && walletType != wallet.WalletTypeXPub {
+		return errors.New("--xpub is only supported for xpub wallets")
+	}
+
+	if fromShares != "" && (walletType == wallet.WalletTypeXPub || walletType == wallet.WalletTypeCollection) {
+		return fmt.Errorf("%s wallets don't have a seed, --from-shares must not be used", walletType)
+	}
+
+	var sd string
+	var shares []string
+	switch walletType {
+	case wallet.WalletTypeXPub:
+		if xpub == "" {
+			return errors.New("--xpub is required for xpub wallets")
+		}
+		if s != "" || random || mnemonic {
+			return errors.New("xpub wallets don't have a seed, -s, -r and -m must not be used")
+		}
+		if encrypt {
+			return errors.New("xpub wallets don't have secrets and can't be encrypted")
+		}
+	case wallet.WalletTypeCollection:
+		if s != "" || random || mnemonic {
+			return errors.New("collection wallets don't have a seed, -s, -r and -m must not be used")
+		}
+		if c.Flags().Changed("num") {
+			return errors.New("collection wallets are created empty, -n must not be used. Use addPrivateKey to add keys")
+		}
+	default:
+		if fromShares != "" {
+			if s != "" || random || mnemonic {
+				return errors.New("seed already specified with --from-shares, must not use -s, -r or -m")
+			}
+
+			shares, err = readSeedSharesFile(fromShares)
+			if err == nil && !wltExists {
+				sd, err = wallet.CombineSeedShares(shares)
+			}
+		} else {
+			sd, err = makeSeed(s, random, mnemonic)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	cryptoType, err := wallet.CryptoTypeFromString(c.Flag("crypto-type").Value.String())
 	if err != nil {
 		return err
 	}
 
-	cryptoType, err := wallet.CryptoTypeFromString(c.Flag("crypto-type").Value.String())
+	scan, err := c.Flags().GetBool("scan")
 	if err != nil {
 		return err
 	}
 
+	gapLimit, err := c.Flags().GetUint64("gap-limit")
+	if err != nil {
+		return err
+	}
+
+	var tf wallet.TransactionsFinder
+	if scan {
+		if walletType == wallet.WalletTypeCollection {
+			return errors.New("collection wallets can't be scanned, --scan must not be used")
+		}
+		if gapLimit == 0 {
+			return errors.New("--gap-limit must > 0")
+		}
+		tf = apiTransactionsFinder{apiClient}
+	} else {
+		if c.Flags().Changed("gap-limit") {
+			return errors.New("--gap-limit can only be used with --scan")
+		}
+		gapLimit = 0
+	}
+
 	pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+
+	if wltExists {
+		if encrypt || c.Flags().Changed("num") || c.Flags().Changed("label") || c.Flags().Changed("fresh-change") {
+			return errors.New("-e, -n, -l and --fresh-change must not be used when recovering an existing wallet")
+		}
+
+		wlt, err := recoverWalletFromShares(wltName, shares, seedPassphrase, pr, gapLimit, tf)
+		if err != nil {
+			return err
+		}
+
+		return printJSON(wallet.NewReadableWallet(wlt))
+	}
+
 	switch pr.(type) {
 	case PasswordFromBytes:
 		p, err := pr.Password()
@@ -144,15 +274,26 @@ func generateWalletHandler(c *gcli.Command, _ []string) error {
 		}
 	}
 
+	freshChange, err := c.Flags().GetBool("fresh-change")
+	if err != nil {
+		return err
+	}
+
 	opts := wallet.Options{
-		Label:      label,
-		Seed:       sd,
-		Encrypt:    encrypt,
-		CryptoType: cryptoType,
-		Password:   password,
+		Type:           walletType,
+		Label:          label,
+		Seed:           sd,
+		SeedPassphrase: seedPassphrase,
+		XPub:           xpub,
+		Encrypt:        encrypt,
+		CryptoType:     cryptoType,
+		Password:       password,
+		FreshChange:    freshChange,
 	}
 
-	wlt, err := GenerateWallet(wltName, opts, num)
+	wlt, err := GenerateWalletScan(wltName, opts, num, gapLimit, tf, func(p wallet.ScanProgress) {
+		fmt.Fprintf(os.Stderr, "Scanned %d addresses on chain %d, found %d with transaction history\n", p.Scanned, p.Chain, p.Found)
+	})
 	if err != nil {
 		return err
 	}
@@ -188,16 +329,83 @@ func makeSeed(s string, r, m bool) (string, error) {
 	return bip39.NewDefaultMnemonic()
 }
 
+// readSeedSharesFile reads a file of seed share mnemonics, one per line
+func readSeedSharesFile(filename string) ([]string, error) {
+	f, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var shares []string
+	for _, l := range strings.Split(string(f), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			shares = append(shares, l)
+		}
+	}
+
+	return shares, nil
+}
+
+// recoverWalletFromShares recovers an existing wallet of the wallet directory from seed shares
+// with the wallet service, the same way as the node's POST /api/v2/wallet/recover endpoint.
+// The password is only read if the wallet is encrypted.
+func recoverWalletFromShares(wltName string, shares []string, seedPassphrase string, pr PasswordReader, gapLimit uint64, tf wallet.TransactionsFinder) (*wallet.Wallet, error) {
+	serv, err := wallet.NewService(wallet.Config{
+		WalletDir:       cliConfig.WalletDir,
+		CryptoType:      wallet.CryptoTypeScryptChacha20poly1305,
+		EnableWalletAPI: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := serv.GetWallet(wltName)
+	if err != nil {
+		return nil, err
+	}
+
+	var password []byte
+	if w.IsEncrypted() {
+		password, err = pr.Password()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w, scans, err := serv.RecoverWalletFromShares(wltName, shares, seedPassphrase, password, gapLimit, tf)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range scans {
+		fmt.Fprintf(os.Stderr, "Scanned %d addresses on chain %d, found %d with transaction history\n", p.Scanned, p.Chain, p.Found)
+	}
+
+	return w, nil
+}
+
 // PUBLIC
 
 // GenerateWallet generates a new wallet with filename walletFile, label, seed and number of addresses.
 // Caller should save the wallet file to its chosen directory
 func GenerateWallet(walletFile string, opts wallet.Options, numAddrs uint64) (*wallet.Wallet, error) {
+	return GenerateWalletScan(walletFile, opts, numAddrs, 0, nil, nil)
+}
+
+// GenerateWalletScan generates a new wallet like GenerateWallet, then adds the addresses with
+// transaction history found before gapLimit consecutive addresses without history.
+// No scan is done if gapLimit is 0. The progress function is optional.
+// Caller should save the wallet file to its chosen directory
+func GenerateWalletScan(walletFile string, opts wallet.Options, numAddrs, gapLimit uint64, tf wallet.TransactionsFinder, progress wallet.ScanProgressFunc) (*wallet.Wallet, error) {
 	walletFile = filepath.Base(walletFile)
 
 	wlt, err := wallet.NewWallet(walletFile, wallet.Options{
-		Seed:  opts.Seed,
-		Label: opts.Label,
+		Type:           opts.Type,
+		Seed:           opts.Seed,
+		SeedPassphrase: opts.SeedPassphrase,
+		XPub:           opts.XPub,
+		Label:          opts.Label,
+		FreshChange:    opts.FreshChange,
 	})
 	if err != nil {
 		return nil, err
@@ -209,6 +417,12 @@ func GenerateWallet(walletFile string, opts wallet.Options, numAddrs uint64) (*w
 		}
 	}
 
+	if gapLimit > 0 {
+		if _, err := wlt.ScanAddressHistory(gapLimit, tf, progress); err != nil {
+			return nil, err
+		}
+	}
+
 	if !opts.Encrypt {
 		if len(opts.Password) != 0 {
 			return nil, wallet.ErrWalletNotEncrypted
@@ -229,3 +443,38 @@ func MakeAlphanumericSeed() string {
 	seedRaw := cipher.SumSHA256(secp256k1.RandByte(AlphaNumericSeedLength))
 	return hex.EncodeToString(seedRaw[:])
 }
+
+// apiTransactionsFinder implements wallet.TransactionsFinder with the node's transactions API
+type apiTransactionsFinder struct {
+	c *api.Client
+}
+
+// AddressesActivity returns whether each address is an input or output of a confirmed or unconfirmed transaction
+func (f apiTransactionsFinder) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	addrStrs := make([]string, len(addrs))
+	for i, a := range addrs {
+		addrStrs[i] = a.String()
+	}
+
+	txns, err := f.c.TransactionsVerbose(addrStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	for _, txn := range txns {
+		for _, in := range txn.Transaction.In {
+			seen[in.Address] = struct{}{}
+		}
+		for _, out := range txn.Transaction.Out {
+			seen[out.Address] = struct{}{}
+		}
+	}
+
+	active := make([]bool, len(addrs))
+	for i, a := range addrs {
+		_, active[i] = seen[a.String()]
+	}
+
+	return active, nil
+}
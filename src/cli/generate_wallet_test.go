@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestGenerateWalletScan(t *testing.T) {
+	w, err := wallet.NewWallet("t.wlt", wallet.Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	addrs, err := w.GenerateSkycoinAddresses(10)
+	require.NoError(t, err)
+
+	// addrs[1] received coins, which were spent to addrs[3]
+	txns := []readable.TransactionWithStatusVerbose{
+		{
+			Transaction: readable.TransactionVerbose{
+				BlockTransactionVerbose: readable.BlockTransactionVerbose{
+					Out: []readable.TransactionOutput{{Address: addrs[1].String()}},
+				},
+			},
+		},
+		{
+			Transaction: readable.TransactionVerbose{
+				BlockTransactionVerbose: readable.BlockTransactionVerbose{
+					In:  []readable.TransactionInput{{Address: addrs[1].String()}},
+					Out: []readable.TransactionOutput{{Address: addrs[3].String()}},
+				},
+			},
+		},
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// CSRF is disabled
+		if r.URL.Path == "/api/v1/csrf" {
+			http.NotFound(w, r)
+			return
+		}
+
+		requests++
+		require.Equal(t, "/api/v1/transactions", r.URL.Path)
+		require.Equal(t, "1", r.FormValue("verbose"))
+
+		queried := make(map[string]struct{})
+		for _, a := range strings.Split(r.FormValue("addrs"), ",") {
+			queried[a] = struct{}{}
+		}
+
+		// Return the transactions related to the queried addresses
+		var rsp []readable.TransactionWithStatusVerbose
+		for _, txn := range txns {
+			related := false
+			for _, in := range txn.Transaction.In {
+				_, ok := queried[in.Address]
+				related = related || ok
+			}
+			for _, out := range txn.Transaction.Out {
+				_, ok := queried[out.Address]
+				related = related || ok
+			}
+			if related {
+				rsp = append(rsp, txn)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(rsp))
+	}))
+	defer server.Close()
+
+	tf := apiTransactionsFinder{api.NewClient(server.URL)}
+
+	var progress []wallet.ScanProgress
+	wlt, err := GenerateWalletScan("t.wlt", wallet.Options{
+		Seed:       "seed",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: wallet.CryptoTypeSha256Xor,
+	}, 1, 3, tf, func(p wallet.ScanProgress) {
+		progress = append(progress, p)
+	})
+	require.NoError(t, err)
+
+	require.True(t, wlt.IsEncrypted())
+	require.Len(t, wlt.Entries, 5)
+	for i, e := range wlt.Entries[1:] {
+		require.Equal(t, addrs[i], e.SkycoinAddress())
+	}
+
+	require.Equal(t, []wallet.ScanProgress{
+		{Scanned: 3, Found: 2},
+		{Scanned: 5, Found: 4},
+		{Scanned: 7, Found: 4},
+	}, progress)
+	require.Equal(t, len(progress), requests)
+
+	// No scan without a gap limit
+	wlt, err = GenerateWalletScan("t.wlt", wallet.Options{
+		Seed: "seed",
+	}, 2, 0, tf, nil)
+	require.NoError(t, err)
+	require.Len(t, wlt.Entries, 2)
+	require.Equal(t, len(progress), requests)
+}
+
+func TestRecoverWalletFromShares(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	walletDir := cliConfig.WalletDir
+	cliConfig.WalletDir = dir
+	defer func() {
+		cliConfig.WalletDir = walletDir
+	}()
+
+	w, err := GenerateWallet("t.wlt", wallet.Options{
+		Seed:       "seed",
+		Label:      "label",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: wallet.CryptoTypeSha256Xor,
+	}, 2)
+	require.NoError(t, err)
+	require.NoError(t, w.Save(dir))
+
+	shares, err := wallet.SplitSeed("seed", 3, 2)
+	require.NoError(t, err)
+	otherShares, err := wallet.SplitSeed("other seed", 3, 2)
+	require.NoError(t, err)
+
+	_, err = recoverWalletFromShares("t.wlt", shares[:1], "", PasswordFromBytes("newpwd"), 0, nil)
+	require.Equal(t, wallet.ErrNotEnoughSeedShares, err)
+
+	_, err = recoverWalletFromShares("t.wlt", otherShares[1:], "", PasswordFromBytes("newpwd"), 0, nil)
+	require.Equal(t, wallet.ErrWalletRecoverSeedWrong, err)
+
+	_, err = recoverWalletFromShares("x.wlt", shares[1:], "", PasswordFromBytes("newpwd"), 0, nil)
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	w2, err := recoverWalletFromShares("t.wlt", shares[1:], "", PasswordFromBytes("newpwd"), 0, nil)
+	require.NoError(t, err)
+	require.True(t, w2.IsEncrypted())
+	require.Equal(t, "label", w2.Label())
+	require.Equal(t, w.GetAddresses(), w2.GetAddresses())
+
+	// The recovered wallet is saved, encrypted with the new password
+	w3, err := wallet.Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.NoError(t, w3.GuardView([]byte("newpwd"), func(*wallet.Wallet) error {
+		return nil
+	}))
+}
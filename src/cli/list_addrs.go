@@ -9,17 +9,38 @@ import (
 )
 
 func listAddressesCmd() *gcli.Command {
-	return &gcli.Command{
-		Short:                 "Lists all addresses in a given wallet",
-		Use:                   "listAddresses [walletName]",
-		Args:                  gcli.MaximumNArgs(1),
-		DisableFlagsInUseLine: true,
-		SilenceUsage:          true,
-		RunE:                  listAddresses,
+	listAddressesCmd := &gcli.Command{
+		Short: "Lists all addresses in a given wallet",
+		Use:   "listAddresses [walletName]",
+		Long: `Lists all addresses in a given wallet.
+
+    Use the --verbose flag to also show the label, creation time,
+    purpose (receive or change) and tags of each address.`,
+		Args:         gcli.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         listAddresses,
 	}
+
+	listAddressesCmd.Flags().BoolP("verbose", "v", false, "Show the label, creation time, purpose and tags of each address")
+
+	return listAddressesCmd
 }
 
-func listAddresses(_ *gcli.Command, args []string) error {
+// AddressEntry is an address with its metadata, as printed by listAddresses --verbose
+type AddressEntry struct {
+	Address string   `json:"address"`
+	Label   string   `json:"label,omitempty"`
+	Created int64    `json:"created,omitempty"`
+	Purpose string   `json:"purpose,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+func listAddresses(c *gcli.Command, args []string) error {
+	verbose, err := c.Flags().GetBool("verbose")
+	if err != nil {
+		return err
+	}
+
 	var wltPath string
 	if len(args) > 0 {
 		wltPath = args[0]
@@ -36,6 +57,25 @@ func listAddresses(_ *gcli.Command, args []string) error {
 		return WalletLoadError{err}
 	}
 
+	if verbose {
+		entries := make([]AddressEntry, len(wlt.Entries))
+		for i, e := range wlt.Entries {
+			entries[i] = AddressEntry{
+				Address: e.Address.String(),
+				Label:   e.Label,
+				Created: e.Created,
+				Purpose: e.Purpose,
+				Tags:    e.Tags,
+			}
+		}
+
+		return printJSON(struct {
+			Addresses []AddressEntry `json:"addresses"`
+		}{
+			Addresses: entries,
+		})
+	}
+
 	addrs := wlt.GetAddresses()
 
 	s, err := FormatAddressesAsJSON(addrs)
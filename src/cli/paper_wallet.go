@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	htemplate "html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/qrcode"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const (
+	paperWalletQRSize = 200
+	// paperWalletLineLen is the number of characters of a line of text in the SVG format
+	paperWalletLineLen = 44
+)
+
+func paperWalletGenCmd() *cobra.Command {
+	paperWalletGenCmd := &cobra.Command{
+		Short: "Generate printable paper wallets with QR codes",
+		Use:   "paperWalletGen",
+		Long: `Generate paper wallets for cold storage, as a self-contained HTML page or
+    SVG image which can be printed. Each paper wallet shows an address and the
+    seed or secret key which spends its coins, with a QR code for both. The
+    address is the first address of the seed, like in a wallet created with
+    the seed. Nothing is sent over the network.
+
+    With "-x", the seed or secret key is encrypted with a password, similarly
+    to BIP38, so that the paper wallet is useless without the password. The
+    encrypted secret can be decrypted with paperWalletDecrypt. The sha256-xor
+    crypto type is too weak for secrets printed on paper and can't be used.
+
+    Use caution when using the "-p" or "-s" options. If you have command history
+    enabled the password or seed can be recovered from the history log. If you
+    do not include the "-p" option you will be prompted to enter the password
+    after you enter your command.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			num, err := c.Flags().GetInt("num")
+			if err != nil {
+				return err
+			}
+			if num <= 0 {
+				return errors.New("num must be > 0")
+			}
+
+			seed, err := c.Flags().GetString("seed")
+			if err != nil {
+				return err
+			}
+			if seed != "" && num != 1 {
+				printHelp(c)
+				return errors.New("seed can only be used with num 1")
+			}
+
+			useSecKey, err := c.Flags().GetBool("secret-key")
+			if err != nil {
+				return err
+			}
+
+			encrypt, err := c.Flags().GetBool("encrypt")
+			if err != nil {
+				return err
+			}
+
+			format, err := c.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+			format = strings.ToLower(format)
+			switch format {
+			case "html", "svg":
+			default:
+				printHelp(c)
+				return errors.New("format must be html or svg")
+			}
+
+			label, err := c.Flags().GetString("label")
+			if err != nil {
+				return err
+			}
+
+			out, err := c.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				printHelp(c)
+				return errors.New("output file is required")
+			}
+			if _, err := os.Stat(out); err == nil {
+				return fmt.Errorf("%s already exists", out)
+			}
+
+			var cryptoType wallet.CryptoType
+			var password []byte
+			if encrypt {
+				cryptoType, err = wallet.CryptoTypeFromString(c.Flag("crypto-type").Value.String())
+				if err != nil {
+					printHelp(c)
+					return err
+				}
+				if cryptoType == wallet.CryptoTypeSha256Xor {
+					printHelp(c)
+					return wallet.ErrPaperSecretCryptoTypeNotAllowed
+				}
+
+				password, err = newPaperWalletPasswordReader([]byte(c.Flag("password").Value.String())).Password()
+				if err != nil {
+					return err
+				}
+			}
+
+			wallets := make([]*wallet.PaperWallet, num)
+			for i := range wallets {
+				seed, err := resolveSeed(c)
+				if err != nil {
+					return err
+				}
+
+				if useSecKey {
+					keys, err := cipher.GenerateDeterministicKeyPairs([]byte(seed), 1)
+					if err != nil {
+						return err
+					}
+					wallets[i], err = wallet.NewSecKeyPaperWallet(keys[0])
+				} else {
+					wallets[i], err = wallet.NewSeedPaperWallet(seed)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			cards, err := newPaperWalletCards(wallets, label, password, cryptoType)
+			if err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			switch format {
+			case "html":
+				err = renderPaperWalletsHTML(&buf, label, cards)
+			case "svg":
+				err = renderPaperWalletsSVG(&buf, cards)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := ioutil.WriteFile(out, buf.Bytes(), 0600); err != nil {
+				return err
+			}
+
+			fmt.Printf("Wrote %d paper wallets to %s\n", len(cards), out)
+			for _, card := range cards {
+				fmt.Println(card.Address)
+			}
+			return nil
+		},
+	}
+
+	paperWalletGenCmd.Flags().IntP("num", "n", 1, "Number of paper wallets to generate, each with a new seed")
+	paperWalletGenCmd.Flags().StringP("seed", "s", "", "Seed of the paper wallet. Will use a new bip39 seed if not provided.")
+	paperWalletGenCmd.Flags().Bool("hex", false, "Use hex(sha256sum(rand(1024))) (CSPRNG-generated) as the seed if the seed is not provided")
+	paperWalletGenCmd.Flags().BoolP("strict-seed", "t", false, "Seed should be a valid bip39 mnemonic seed.")
+	paperWalletGenCmd.Flags().IntP("entropy", "e", 128, "Entropy of the autogenerated bip39 seed, when the seed is not provided. Can be 128 or 256")
+	paperWalletGenCmd.Flags().BoolP("secret-key", "k", false, "Print the secret key of the address instead of the seed")
+	paperWalletGenCmd.Flags().BoolP("encrypt", "x", false, "Encrypt the seed or secret key with a password")
+	paperWalletGenCmd.Flags().String("crypto-type", string(wallet.CryptoTypeScryptChacha20poly1305), "The crypto type for secret encryption, can be scrypt-chacha20poly1305 or argon2id-chacha20poly1305")
+	paperWalletGenCmd.Flags().StringP("password", "p", "", "Password to encrypt the seed or secret key with")
+	paperWalletGenCmd.Flags().StringP("format", "f", "html", "Output format, html or svg")
+	paperWalletGenCmd.Flags().StringP("label", "l", "", "Title printed on the paper wallets")
+	paperWalletGenCmd.Flags().StringP("output", "o", "", "File to create")
+
+	return paperWalletGenCmd
+}
+
+func paperWalletDecryptCmd() *cobra.Command {
+	paperWalletDecryptCmd := &cobra.Command{
+		Short: "Decrypt the encrypted secret of a paper wallet",
+		Use:   "paperWalletDecrypt [flags] [encrypted secret]",
+		Long: `Decrypt the seed or secret key of a paper wallet generated with
+    "paperWalletGen -x". The decrypted secret is checked against the address
+    it was encrypted with, compare the printed address with the address of
+    the paper wallet.
+
+    Use caution when using the "-p" option. If you have command history enabled
+    the password can be recovered from the history log. If you do not include
+    the "-p" option you will be prompted to enter the password after you enter
+    your command.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			if !wallet.IsEncryptedPaperSecret(args[0]) {
+				return wallet.ErrInvalidEncryptedPaperSecret
+			}
+
+			pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+			password, err := pr.Password()
+			if err != nil {
+				return err
+			}
+
+			p, err := wallet.DecryptPaperSecret(args[0], password)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(struct {
+					Address    string `json:"address"`
+					SecretType string `json:"secret_type"`
+					Secret     string `json:"secret"`
+				}{
+					Address:    p.Address.String(),
+					SecretType: strings.Replace(p.SecretType.String(), " ", "_", -1),
+					Secret:     p.Secret,
+				})
+			}
+
+			fmt.Printf("Address: %s\n", p.Address)
+			fmt.Printf("%s: %s\n", paperSecretName(p.SecretType, false), p.Secret)
+			return nil
+		},
+	}
+
+	paperWalletDecryptCmd.Flags().StringP("password", "p", "", "Password the secret was encrypted with")
+	paperWalletDecryptCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return paperWalletDecryptCmd
+}
+
+// paperWalletPasswordReader reads a new password from the terminal twice, to catch typos
+// which would make a paper wallet unrecoverable
+type paperWalletPasswordReader struct{}
+
+// Password implements the PasswordReader's Password method
+func (p paperWalletPasswordReader) Password() ([]byte, error) {
+	password, err := readPasswordFromTerminalPrompt("enter password:")
+	if err != nil {
+		return nil, err
+	}
+
+	confirm, err := readPasswordFromTerminalPrompt("confirm password:")
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(password, confirm) {
+		return nil, errors.New("passwords do not match")
+	}
+
+	return password, nil
+}
+
+func newPaperWalletPasswordReader(p []byte) PasswordReader {
+	if len(p) != 0 {
+		return PasswordFromBytes(p)
+	}
+
+	return paperWalletPasswordReader{}
+}
+
+// paperWalletCard is the printed content of a paper wallet
+type paperWalletCard struct {
+	Label      string
+	Address    string
+	AddressQR  string
+	SecretName string
+	Secret     string
+	SecretQR   string
+}
+
+func newPaperWalletCards(wallets []*wallet.PaperWallet, label string, password []byte, cryptoType wallet.CryptoType) ([]paperWalletCard, error) {
+	cards := make([]paperWalletCard, len(wallets))
+	for i, p := range wallets {
+		secret := p.Secret
+		encrypted := len(password) != 0
+		if encrypted {
+			var err error
+			secret, err = p.EncryptSecret(password, cryptoType)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		addrQR, err := qrcode.Encode([]byte(p.Address.String()))
+		if err != nil {
+			return nil, err
+		}
+
+		secretQR, err := qrcode.Encode([]byte(secret))
+		if err != nil {
+			return nil, err
+		}
+
+		cards[i] = paperWalletCard{
+			Label:      label,
+			Address:    p.Address.String(),
+			AddressQR:  addrQR.SVG(paperWalletQRSize),
+			SecretName: paperSecretName(p.SecretType, encrypted),
+			Secret:     secret,
+			SecretQR:   secretQR.SVG(paperWalletQRSize),
+		}
+	}
+
+	return cards, nil
+}
+
+func paperSecretName(t wallet.PaperSecretType, encrypted bool) string {
+	name := "Seed"
+	if t == wallet.PaperSecretTypeSecretKey {
+		name = "Secret key"
+	}
+	if encrypted {
+		name = "Encrypted " + strings.ToLower(name)
+	}
+	return name
+}
+
+var paperWalletHTMLTemplate = htemplate.Must(htemplate.New("paper").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .Label}}{{.Label}}{{else}}Skycoin paper wallet{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; }
+.card { display: flex; align-items: center; justify-content: space-between; width: 190mm; margin: 10mm auto; padding: 5mm; border: 1px dashed #000000; page-break-inside: avoid; }
+.card h1 { font-size: 16px; margin: 0 0 4mm 0; }
+.card h2 { font-size: 13px; margin: 3mm 0 1mm 0; }
+.info { flex: 1; margin: 0 5mm; }
+.value { font-family: monospace; font-size: 12px; word-break: break-all; }
+.qr { text-align: center; font-size: 11px; }
+</style>
+</head>
+<body>
+{{range .Cards}}<div class="card">
+<div class="qr">{{.AddressQR}}<div>Address</div></div>
+<div class="info">
+<h1>{{if .Label}}{{.Label}}{{else}}Skycoin paper wallet{{end}}</h1>
+<h2>Address</h2>
+<div class="value">{{.Address}}</div>
+<h2>{{.SecretName}}</h2>
+<div class="value">{{.Secret}}</div>
+</div>
+<div class="qr">{{.SecretQR}}<div>{{.SecretName}}</div></div>
+</div>
+{{end}}</body>
+</html>
+`))
+
+// renderPaperWalletsHTML renders the paper wallets as a self-contained HTML page
+func renderPaperWalletsHTML(w io.Writer, label string, cards []paperWalletCard) error {
+	type htmlCard struct {
+		paperWalletCard
+		AddressQR htemplate.HTML
+		SecretQR  htemplate.HTML
+	}
+
+	htmlCards := make([]htmlCard, len(cards))
+	for i, card := range cards {
+		htmlCards[i] = htmlCard{
+			paperWalletCard: card,
+			// The QR codes are generated SVG elements, which must not be escaped
+			AddressQR: htemplate.HTML(card.AddressQR),
+			SecretQR:  htemplate.HTML(card.SecretQR),
+		}
+	}
+
+	return paperWalletHTMLTemplate.Execute(w, struct {
+		Label string
+		Cards []htmlCard
+	}{
+		Label: label,
+		Cards: htmlCards,
+	})
+}
+
+const (
+	paperWalletSVGWidth      = 800
+	paperWalletSVGCardHeight = 280
+)
+
+var paperWalletSVGTemplate = template.Must(template.New("paper").Funcs(template.FuncMap{
+	"xml":   xmlEscape,
+	"lines": paperWalletLines,
+	"add":   func(a, b int) int { return a + b },
+	"mul":   func(a, b int) int { return a * b },
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+<rect width="{{.Width}}" height="{{.Height}}" fill="#ffffff"/>
+{{range $i, $card := .Cards}}<g transform="translate(0,{{mul $i $.CardHeight}})" font-family="sans-serif">
+<rect x="5" y="5" width="{{add $.Width -10}}" height="{{add $.CardHeight -10}}" fill="none" stroke="#000000" stroke-dasharray="4"/>
+<g transform="translate(20,40)">{{.AddressQR}}</g>
+<text x="120" y="255" font-size="12" text-anchor="middle">Address</text>
+<g transform="translate(580,40)">{{.SecretQR}}</g>
+<text x="680" y="255" font-size="12" text-anchor="middle">{{xml .SecretName}}</text>
+<text x="240" y="30" font-size="16" font-weight="bold">{{if .Label}}{{xml .Label}}{{else}}Skycoin paper wallet{{end}}</text>
+<text x="240" y="60" font-size="13" font-weight="bold">Address</text>
+<text x="240" y="78" font-size="12" font-family="monospace">{{xml .Address}}</text>
+<text x="240" y="108" font-size="13" font-weight="bold">{{xml .SecretName}}</text>
+<text x="240" y="126" font-size="12" font-family="monospace">{{range $j, $line := lines .Secret}}<tspan x="240" dy="{{if $j}}15{{else}}0{{end}}">{{xml $line}}</tspan>{{end}}</text>
+</g>
+{{end}}</svg>
+`))
+
+// renderPaperWalletsSVG renders the paper wallets as an SVG image, one below the other
+func renderPaperWalletsSVG(w io.Writer, cards []paperWalletCard) error {
+	return paperWalletSVGTemplate.Execute(w, struct {
+		Width      int
+		Height     int
+		CardHeight int
+		Cards      []paperWalletCard
+	}{
+		Width:      paperWalletSVGWidth,
+		Height:     paperWalletSVGCardHeight * len(cards),
+		CardHeight: paperWalletSVGCardHeight,
+		Cards:      cards,
+	})
+}
+
+// paperWalletLines splits text in lines of at most paperWalletLineLen characters,
+// breaking at spaces if possible
+func paperWalletLines(s string) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		for len(word) > paperWalletLineLen {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, word[:paperWalletLineLen])
+			word = word[paperWalletLineLen:]
+		}
+
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= paperWalletLineLen:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func xmlEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestPaperWalletLines(t *testing.T) {
+	cases := []struct {
+		name  string
+		s     string
+		lines []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "short",
+			s:     "foo bar",
+			lines: []string{"foo bar"},
+		},
+		{
+			name: "words",
+			s:    "enact seek among recall one save armed parrot license ask giant fog",
+			lines: []string{
+				"enact seek among recall one save armed",
+				"parrot license ask giant fog",
+			},
+		},
+		{
+			name: "long word",
+			s:    strings.Repeat("a", 100),
+			lines: []string{
+				strings.Repeat("a", 44),
+				strings.Repeat("a", 44),
+				strings.Repeat("a", 12),
+			},
+		},
+		{
+			name: "long word after short word",
+			s:    "foo " + strings.Repeat("a", 50),
+			lines: []string{
+				"foo",
+				strings.Repeat("a", 44),
+				strings.Repeat("a", 6),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.lines, paperWalletLines(tc.s))
+		})
+	}
+}
+
+func TestRenderPaperWallets(t *testing.T) {
+	seedWallet, err := wallet.NewSeedPaperWallet("enact seek among recall one save armed parrot license ask giant fog")
+	require.NoError(t, err)
+
+	keyWallet, err := wallet.NewSeedPaperWallet("foo")
+	require.NoError(t, err)
+	sk, err := keyWallet.SecKey()
+	require.NoError(t, err)
+	keyWallet, err = wallet.NewSecKeyPaperWallet(sk)
+	require.NoError(t, err)
+
+	wallets := []*wallet.PaperWallet{seedWallet, keyWallet}
+	label := "Cold <storage> & co"
+
+	cards, err := newPaperWalletCards(wallets, label, nil, "")
+	require.NoError(t, err)
+	require.Len(t, cards, 2)
+	require.Equal(t, "Seed", cards[0].SecretName)
+	require.Equal(t, seedWallet.Secret, cards[0].Secret)
+	require.Equal(t, "Secret key", cards[1].SecretName)
+	require.Equal(t, sk.Hex(), cards[1].Secret)
+
+	_, err = newPaperWalletCards(wallets, label, []byte("pwd"), wallet.CryptoTypeSha256Xor)
+	require.Equal(t, wallet.ErrPaperSecretCryptoTypeNotAllowed, err)
+
+	encCards, err := newPaperWalletCards(wallets, label, []byte("pwd"), wallet.CryptoTypeScryptChacha20poly1305Insecure)
+	require.NoError(t, err)
+	for i, card := range encCards {
+		require.Equal(t, wallets[i].Address.String(), card.Address)
+		require.True(t, wallet.IsEncryptedPaperSecret(card.Secret))
+		p, err := wallet.DecryptPaperSecret(card.Secret, []byte("pwd"))
+		require.NoError(t, err)
+		require.Equal(t, wallets[i], p)
+	}
+	require.Equal(t, "Encrypted seed", encCards[0].SecretName)
+	require.Equal(t, "Encrypted secret key", encCards[1].SecretName)
+
+	var buf bytes.Buffer
+	err = renderPaperWalletsHTML(&buf, label, cards)
+	require.NoError(t, err)
+	html := buf.String()
+	require.Contains(t, html, "<title>Cold &lt;storage&gt; &amp; co</title>")
+	require.Equal(t, 4, strings.Count(html, "<svg "))
+	for _, card := range cards {
+		require.Contains(t, html, card.Address)
+		require.Contains(t, html, card.Secret)
+	}
+
+	buf.Reset()
+	err = renderPaperWalletsSVG(&buf, encCards)
+	require.NoError(t, err)
+	svg := buf.String()
+	require.Contains(t, svg, `height="560"`)
+	require.Equal(t, 5, strings.Count(svg, "<svg "))
+	require.Contains(t, svg, "Cold &lt;storage&gt; &amp; co")
+
+	// The SVG is well formed XML
+	d := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	for _, card := range encCards {
+		require.Contains(t, svg, card.Address)
+		for _, line := range paperWalletLines(card.Secret) {
+			require.Contains(t, svg, ">"+line+"</tspan>")
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func createPartialTxnCmd() *cobra.Command {
+	return &cobra.Command{
+		Short: "Create a partially signed transaction from an unsigned raw transaction",
+		Use:   "createPartialTransaction [raw transaction]",
+		Long: `Looks up the outputs spent by the transaction on the node, and bundles them
+    with the transaction so that its inputs can be signed offline by several parties.
+    Inputs owned by a wallet loaded in the node are annotated with the wallet as their signer.`,
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			rsp, err := apiClient.CreatePartialTransaction(args[0])
+			if err != nil {
+				return err
+			}
+
+			pt, err := transaction.DeserializePartialTransaction(rsp.PartialTransaction)
+			if err != nil {
+				return err
+			}
+
+			return printPartialTxn(pt)
+		},
+	}
+}
+
+func signPartialTxnCmd() *cobra.Command {
+	signPartialTxnCmd := &cobra.Command{
+		Short: "Sign the inputs of a partially signed transaction that are owned by a wallet",
+		Use:   "signPartialTransaction [flags] [partial transaction file]",
+		Long: fmt.Sprintf(`Signs offline; a connection to the node is not required.
+    The default wallet (%s) will be used if no wallet was specified.
+
+    Use caution when using the "-p" command. If you have command history enabled
+    your wallet encryption password can be recovered from the history log. If you
+    do not include the "-p" option you will be prompted to enter your password
+    after you enter your command.`, cliConfig.FullWalletPath()),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			walletFile, err := c.Flags().GetString("wallet-file")
+			if err != nil {
+				return err
+			}
+
+			password, err := c.Flags().GetString("password")
+			if err != nil {
+				return err
+			}
+
+			w, err := resolveWalletPath(cliConfig, walletFile)
+			if err != nil {
+				return err
+			}
+
+			pt, err := readPartialTxn(args[0])
+			if err != nil {
+				return err
+			}
+
+			signed, err := signPartialTxn(w, NewPasswordReader([]byte(password)), pt)
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				printHelp(c)
+				return err
+			default:
+				return err
+			}
+
+			return printPartialTxn(signed)
+		},
+	}
+
+	signPartialTxnCmd.Flags().StringP("wallet-file", "f", "", "wallet file or path. If no path is specified your default wallet path will be used.")
+	signPartialTxnCmd.Flags().StringP("password", "p", "", "Wallet password")
+
+	return signPartialTxnCmd
+}
+
+func signPartialTxn(walletFile string, pr PasswordReader, pt *transaction.PartialTransaction) (*transaction.PartialTransaction, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	if !wlt.IsEncrypted() {
+		return wlt.SignPartialTransaction(pt)
+	}
+
+	password, err := pr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	var signed *transaction.PartialTransaction
+	if err := wlt.GuardView(password, func(w *wallet.Wallet) error {
+		var err error
+		signed, err = w.SignPartialTransaction(pt)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+func combinePartialTxnsCmd() *cobra.Command {
+	return &cobra.Command{
+		Short:                 "Combine the signatures of partially signed transactions of the same transaction",
+		Use:                   "combinePartialTransactions [partial transaction file]...",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			pts := make([]*transaction.PartialTransaction, len(args))
+			for i, f := range args {
+				pt, err := readPartialTxn(f)
+				if err != nil {
+					return err
+				}
+				pts[i] = pt
+			}
+
+			combined, err := transaction.CombinePartialTransactions(pts...)
+			if err != nil {
+				return err
+			}
+
+			return printPartialTxn(combined)
+		},
+	}
+}
+
+func inspectPartialTxnCmd() *cobra.Command {
+	return &cobra.Command{
+		Short:                 "Show the inputs, outputs and signing progress of a partially signed transaction",
+		Use:                   "inspectPartialTransaction [partial transaction file]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			pt, err := readPartialTxn(args[0])
+			if err != nil {
+				return err
+			}
+
+			rsp, err := api.NewInspectPartialTransactionResponse(pt)
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+}
+
+func finalizePartialTxnCmd() *cobra.Command {
+	finalizePartialTxnCmd := &cobra.Command{
+		Short: "Finalize a fully signed partially signed transaction into a raw transaction",
+		Use:   "finalizePartialTransaction [flags] [partial transaction file]",
+		Long: `The raw transaction can be broadcast to the network with broadcastTransaction.
+    Fails if any input of the transaction is not signed.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			pt, err := readPartialTxn(args[0])
+			if err != nil {
+				return err
+			}
+
+			txn, err := pt.Finalize()
+			if err != nil {
+				return err
+			}
+
+			rawTxn, err := txn.SerializeHex()
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(struct {
+					RawTx string `json:"rawtx"`
+				}{
+					RawTx: rawTxn,
+				})
+			}
+
+			fmt.Println(rawTxn)
+
+			return nil
+		},
+	}
+
+	finalizePartialTxnCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return finalizePartialTxnCmd
+}
+
+func readPartialTxn(filename string) (*transaction.PartialTransaction, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := transaction.DeserializePartialTransaction(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	return pt, nil
+}
+
+func printPartialTxn(pt *transaction.PartialTransaction) error {
+	b, err := pt.Serialize()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(b))
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/util/droplet"
+)
+
+func walletPayoutAddCmd() *gcli.Command {
+	walletPayoutAddCmd := &gcli.Command{
+		Short: "Queue payouts for a wallet of the node",
+		Use:   "walletPayoutAdd [flags] [wallet id] [to address] [amount]",
+		Long: `Queue payouts to send coins from a wallet loaded by the node.
+    The node periodically packs the pending payouts of the wallet into
+    transactions with many outputs, which are signed and broadcast.
+    The wallet must not be encrypted.
+
+    Use walletPayouts to check the status and transaction of each payout.`,
+		Args:         gcli.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			toAddrs, err := getToAddresses(c, args[1:])
+			if err != nil {
+				return err
+			}
+
+			payouts := make([]api.PayoutRequest, len(toAddrs))
+			for i, to := range toAddrs {
+				coins, err := droplet.ToString(to.Coins)
+				if err != nil {
+					return err
+				}
+
+				payouts[i] = api.PayoutRequest{
+					Address: to.Addr,
+					Coins:   coins,
+				}
+			}
+
+			rsp, err := apiClient.AddWalletPayouts(args[0], payouts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			return printPayouts(rsp.Payouts)
+		},
+	}
+
+	walletPayoutAddCmd.Flags().String("csv", "", "CSV file containing addresses and amounts to send")
+	walletPayoutAddCmd.Flags().StringP("many", "m", "", `use JSON string to set multiple receive addresses and coins,
+example: -m '[{"addr":"$addr1", "coins": "10.2"}, {"addr":"$addr2", "coins": "20"}]'`)
+	walletPayoutAddCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return walletPayoutAddCmd
+}
+
+func walletPayoutsCmd() *gcli.Command {
+	walletPayoutsCmd := &gcli.Command{
+		Short: "List the payouts queued for a wallet of the node",
+		Use:   "walletPayouts [flags] [wallet id]",
+		Long: `List the payouts queued for a wallet loaded by the node, oldest first,
+    with their status and the transaction which sent them.
+
+    The status of a payout is one of:
+        pending: waiting to be sent, "error" is the error of the last attempt
+        sent: its transaction was broadcast
+        confirmed: its transaction was executed in a block
+        failed: its transaction was dropped, it is not sent again
+        canceled: canceled with walletPayoutCancel`,
+		Args:         gcli.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			status, err := c.Flags().GetString("status")
+			if err != nil {
+				return err
+			}
+
+			var statuses []string
+			if status != "" {
+				statuses = strings.Split(status, ",")
+			}
+
+			rsp, err := apiClient.WalletPayouts(args[0], statuses)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			return printPayouts(rsp.Payouts)
+		},
+	}
+
+	walletPayoutsCmd.Flags().StringP("status", "s", "", "comma separated statuses of the payouts to list, e.g. pending,sent")
+	walletPayoutsCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return walletPayoutsCmd
+}
+
+func walletPayoutCancelCmd() *gcli.Command {
+	walletPayoutCancelCmd := &gcli.Command{
+		Short: "Cancel pending payouts of a wallet of the node",
+		Use:   "walletPayoutCancel [flags] [wallet id] [payout id...]",
+		Long: `Cancel pending payouts of a wallet loaded by the node.
+    No payout is canceled if any of them is not pending.`,
+		Args:         gcli.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			ids := make([]uint64, len(args)-1)
+			for i, s := range args[1:] {
+				ids[i], err = strconv.ParseUint(s, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid payout id %q", s)
+				}
+			}
+
+			rsp, err := apiClient.CancelWalletPayouts(args[0], ids)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			return printPayouts(rsp.Payouts)
+		},
+	}
+
+	walletPayoutCancelCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return walletPayoutCancelCmd
+}
+
+func printPayouts(payouts []api.Payout) error {
+	for _, p := range payouts {
+		line := fmt.Sprintf("%d %s %s %s", p.ID, p.Address, p.Coins, p.Status)
+		if p.Txid != "" {
+			line += " " + p.Txid
+		}
+		if p.Error != "" {
+			line += fmt.Sprintf(" (%s)", p.Error)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
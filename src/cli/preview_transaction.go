@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func previewTransactionCmd() *gcli.Command {
+	previewTransactionCmd := &gcli.Command{
+		Short: "Preview the cost of a transaction without creating it",
+		Use:   "previewTransaction [flags] [to address] [amount]",
+		Long: fmt.Sprintf(`Preview a transaction as createRawTransaction would create it, without
+    signing it. The preview shows the chosen inputs, the coins and coin hours
+    of each output, the change, the coin hours burned for the fee and the
+    size of the transaction, checked against the limits that the node applies
+    to created transactions, to unconfirmed transactions and to transactions
+    included in a block.
+
+    No password is needed, the wallet file is only used for its addresses.
+    The transaction is not broadcast.
+
+    The default wallet (%s) will be used if no wallet and address was
+    specified.`, cliConfig.FullWalletPath()),
+		SilenceUsage: true,
+		Args:         gcli.MaximumNArgs(2),
+		RunE: func(c *gcli.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			req, err := previewTransactionRequest(c, args)
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				printHelp(c)
+				return err
+			default:
+				return err
+			}
+
+			rsp, err := apiClient.PreviewTransaction(*req)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			printTransactionPreview(rsp)
+			return nil
+		},
+	}
+
+	previewTransactionCmd.Flags().StringP("wallet-file", "f", "", "wallet file or path. If no path is specified your default wallet path will be used.")
+	previewTransactionCmd.Flags().StringP("address", "a", "", "From address")
+	previewTransactionCmd.Flags().StringP("change-address", "c", "", `Specify different change address.
+By default the from address or a wallets coinbase address will be used.`)
+	previewTransactionCmd.Flags().StringP("many", "m", "", `use JSON string to set multiple receive addresses and coins,
+example: -m '[{"addr":"$addr1", "coins": "10.2"}, {"addr":"$addr2", "coins": "20"}]'`)
+	previewTransactionCmd.Flags().String("csv", "", "CSV file containing addresses and amounts to send")
+	previewTransactionCmd.Flags().String("share-factor", "0.5", "fraction of the remaining coin hours sent to the receivers, the rest is kept as change")
+	previewTransactionCmd.Flags().String("coin-selection", "", fmt.Sprintf("unspent output selection strategy, one of: %s", strings.Join(transaction.CoinSelections, ", ")))
+	previewTransactionCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return previewTransactionCmd
+}
+
+// previewTransactionRequest creates the POST /api/v2/transaction/preview request from the command's flags and args
+func previewTransactionRequest(c *gcli.Command, args []string) (*api.CreateTransactionRequest, error) {
+	wltAddr, err := fromWalletOrAddress(c)
+	if err != nil {
+		return nil, err
+	}
+
+	changeAddress, err := c.Flags().GetString("change-address")
+	if err != nil {
+		return nil, err
+	}
+	chgAddr, err := getChangeAddress(wltAddr, changeAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	toAddrs, err := getToAddresses(c, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSendAmounts(toAddrs); err != nil {
+		return nil, err
+	}
+
+	shareFactor, err := c.Flags().GetString("share-factor")
+	if err != nil {
+		return nil, err
+	}
+
+	coinSelection, err := c.Flags().GetString("coin-selection")
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	if wltAddr.Address != "" {
+		addrs = []string{wltAddr.Address}
+	} else {
+		wlt, err := wallet.Load(wltAddr.Wallet)
+		if err != nil {
+			return nil, WalletLoadError{err}
+		}
+
+		for _, a := range wlt.GetAddresses() {
+			addrs = append(addrs, a.String())
+		}
+		if len(addrs) == 0 {
+			return nil, errors.New("wallet has no addresses")
+		}
+	}
+
+	to := make([]api.Receiver, len(toAddrs))
+	for i, a := range toAddrs {
+		coins, err := droplet.ToString(a.Coins)
+		if err != nil {
+			return nil, err
+		}
+		to[i] = api.Receiver{
+			Address: a.Addr,
+			Coins:   coins,
+		}
+	}
+
+	return &api.CreateTransactionRequest{
+		HoursSelection: api.HoursSelection{
+			Type:        transaction.HoursSelectionTypeAuto,
+			Mode:        transaction.HoursSelectionModeShare,
+			ShareFactor: shareFactor,
+		},
+		ChangeAddress: &chgAddr,
+		To:            to,
+		Addresses:     addrs,
+		CoinSelection: coinSelection,
+	}, nil
+}
+
+func printTransactionPreview(rsp *api.TransactionPreviewResponse) {
+	txn := rsp.Transaction
+	fmt.Printf("Transaction: %s (unsigned)\n", txn.TxID)
+	fmt.Printf("Inputs: %d\n", len(txn.In))
+	for _, in := range txn.In {
+		fmt.Printf("  %s: %s coins and %s coin hours from %s\n", in.UxID, in.Coins, in.CalculatedHours, in.Address)
+	}
+	fmt.Printf("Outputs: %d\n", len(txn.Out))
+	for _, out := range txn.Out {
+		fmt.Printf("  %s coins and %s coin hours to %s\n", out.Coins, out.Hours, out.Address)
+	}
+	if rsp.Change != nil {
+		fmt.Printf("Change: %s coins and %s coin hours to %s\n", rsp.Change.Coins, rsp.Change.Hours, rsp.Change.Address)
+	} else {
+		fmt.Println("Change: none")
+	}
+	fmt.Printf("Coin hours burned: %s\n", rsp.Fee)
+	fmt.Printf("Size: %d bytes\n", rsp.Size)
+	fmt.Println("Limits:")
+	printVerifyTxnCheck("user", rsp.Limits.User)
+	printVerifyTxnCheck("unconfirmed", rsp.Limits.Unconfirmed)
+	printVerifyTxnCheck("create block", rsp.Limits.CreateBlock)
+}
+
+func printVerifyTxnCheck(name string, c api.VerifyTxnCheck) {
+	status := "ok"
+	if c.Error != "" {
+		status = c.Error
+	}
+	fmt.Printf("  %s: %s\n", name, status)
+	fmt.Printf("    max size %d bytes (%s), burn factor %d requires %s coin hours (%s), max decimals %d (%s)\n",
+		c.MaxTransactionSize, checkStatus(c.SizeOK), c.BurnFactor, c.RequiredFee, checkStatus(c.FeeOK), c.MaxDropletPrecision, checkStatus(c.DecimalsOK))
+}
+
+func checkStatus(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "failed"
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestPreviewTransactionRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-preview-transaction")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wlt, err := GenerateWallet("t.wlt", wallet.Options{
+		Seed:       "seed",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: wallet.CryptoTypeSha256Xor,
+	}, 2)
+	require.NoError(t, err)
+	require.NoError(t, wlt.Save(dir))
+	walletFile := filepath.Join(dir, "t.wlt")
+
+	toAddr := testutil.MakeAddress().String()
+	fromAddr := wlt.Entries[1].SkycoinAddress().String()
+
+	// The addresses of an encrypted wallet are used without its password
+	c := previewTransactionCmd()
+	require.NoError(t, c.Flags().Set("wallet-file", walletFile))
+	req, err := previewTransactionRequest(c, []string{toAddr, "1.5"})
+	require.NoError(t, err)
+	require.Equal(t, []string{wlt.Entries[0].SkycoinAddress().String(), fromAddr}, req.Addresses)
+	require.Equal(t, wlt.Entries[0].SkycoinAddress().String(), *req.ChangeAddress)
+	require.Len(t, req.To, 1)
+	require.Equal(t, toAddr, req.To[0].Address)
+	require.Equal(t, "1.500000", req.To[0].Coins)
+	require.Equal(t, transaction.HoursSelectionTypeAuto, req.HoursSelection.Type)
+	require.Equal(t, transaction.HoursSelectionModeShare, req.HoursSelection.Mode)
+	require.Equal(t, "0.5", req.HoursSelection.ShareFactor)
+	require.Empty(t, req.CoinSelection)
+
+	// The from address is used for the change if no change address is specified
+	c = previewTransactionCmd()
+	require.NoError(t, c.Flags().Set("wallet-file", walletFile))
+	require.NoError(t, c.Flags().Set("address", fromAddr))
+	require.NoError(t, c.Flags().Set("share-factor", "0"))
+	require.NoError(t, c.Flags().Set("coin-selection", transaction.CoinSelectionExactMatch))
+	require.NoError(t, c.Flags().Set("many", `[{"addr":"`+toAddr+`","coins":"2"},{"addr":"`+fromAddr+`","coins":"0.001"}]`))
+	req, err = previewTransactionRequest(c, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{fromAddr}, req.Addresses)
+	require.Equal(t, fromAddr, *req.ChangeAddress)
+	require.Len(t, req.To, 2)
+	require.Equal(t, "2.000000", req.To[0].Coins)
+	require.Equal(t, "0.001000", req.To[1].Coins)
+	require.Equal(t, "0", req.HoursSelection.ShareFactor)
+	require.Equal(t, transaction.CoinSelectionExactMatch, req.CoinSelection)
+
+	c = previewTransactionCmd()
+	require.NoError(t, c.Flags().Set("wallet-file", walletFile))
+	_, err = previewTransactionRequest(c, []string{toAddr, "0"})
+	require.EqualError(t, err, "Cannot send 0 coins")
+
+	c = previewTransactionCmd()
+	require.NoError(t, c.Flags().Set("wallet-file", filepath.Join(dir, "missing.wlt")))
+	_, err = previewTransactionRequest(c, []string{toAddr, "1"})
+	require.IsType(t, WalletLoadError{}, err)
+}
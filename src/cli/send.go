@@ -2,8 +2,15 @@ package cli
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
 )
 
 func sendCmd() *gcli.Command {
@@ -18,29 +25,74 @@ func sendCmd() *gcli.Command {
     Use caution when using the “-p” command. If you have command history enabled
     your wallet encryption password can be recovered from the history log.
     If you do not include the “-p” option you will be prompted to enter your password
-    after you enter your command.`,
+    after you enter your command.
+
+    With “--allow-split”, a spend which needs too many unspent outputs for a single transaction
+    is split into consolidation transactions, which send the unspent outputs to a single output
+    of the wallet each, followed by the payment transaction. A transaction which spends the
+    outputs of consolidation transactions is sent once they are confirmed, and coin hours are
+    burned for the fee of each transaction.`,
 		SilenceUsage: true,
 		RunE: func(c *gcli.Command, args []string) error {
-			rawTxn, err := createRawTxnCmdHandler(c, args)
+			allowSplit, err := c.Flags().GetBool("allow-split")
 			if err != nil {
-				printHelp(c)
 				return err
 			}
 
-			txid, err := apiClient.InjectTransaction(rawTxn)
+			jsonOutput, err := c.Flags().GetBool("json")
 			if err != nil {
 				return err
 			}
 
-			jsonOutput, err := c.Flags().GetBool("json")
+			var rawTxns []coin.Transaction
+			if allowSplit {
+				rawTxns, err = createSplitRawTxnsCmdHandler(c, args)
+			} else {
+				var rawTxn *coin.Transaction
+				rawTxn, err = createRawTxnCmdHandler(c, args)
+				if rawTxn != nil {
+					rawTxns = []coin.Transaction{*rawTxn}
+				}
+			}
 			if err != nil {
+				printHelp(c)
 				return err
 			}
+
+			// The transactions of a split spend are injected in order. A transaction which spends the outputs
+			// of consolidation transactions is injected once they are confirmed.
+			var consolidationTxids []string
+			var txid string
+			for i := range rawTxns {
+				parentTxids := spentTransactionIDs(rawTxns[:i], rawTxns[i])
+				if len(parentTxids) != 0 {
+					if err := waitTransactionsConfirmed(parentTxids, splitConfirmTimeout); err != nil {
+						return unsentTransactionsError(err, rawTxns[i:])
+					}
+				}
+
+				txid, err = apiClient.InjectTransaction(&rawTxns[i])
+				if err != nil {
+					return err
+				}
+
+				if i == len(rawTxns)-1 {
+					break
+				}
+
+				if !jsonOutput {
+					fmt.Printf("consolidation txid:%s\n", txid)
+				}
+				consolidationTxids = append(consolidationTxids, txid)
+			}
+
 			if jsonOutput {
 				return printJSON(struct {
-					Txid string `json:"txid"`
+					Txid               string   `json:"txid"`
+					ConsolidationTxids []string `json:"consolidation_txids,omitempty"`
 				}{
-					Txid: txid,
+					Txid:               txid,
+					ConsolidationTxids: consolidationTxids,
 				})
 			}
 
@@ -58,6 +110,126 @@ example: -m '[{"addr":"$addr1", "coins": "10.2"}, {"addr":"$addr2", "coins": "20
 	sendCmd.Flags().StringP("password", "p", "", "Wallet password")
 	sendCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
 	sendCmd.Flags().String("csv", "", "CSV file containing addresses and amounts to send")
+	sendCmd.Flags().Bool("allow-split", false, `If the transaction would be larger than the maximum transaction size,
+send the spend's unspent outputs to the wallet in consolidation transactions first,
+then send the payment once they are confirmed.`)
 
 	return sendCmd
 }
+
+const (
+	// splitConfirmTimeout is how long send waits for the consolidation transactions of a split spend to confirm
+	splitConfirmTimeout = 10 * time.Minute
+	// splitConfirmInterval is how often send checks whether the consolidation transactions are confirmed
+	splitConfirmInterval = 5 * time.Second
+)
+
+// createSplitRawTxnsCmdHandler creates the transactions of a send with --allow-split.
+// The spend is only split if a single transaction would be larger than the maximum transaction size.
+func createSplitRawTxnsCmdHandler(c *gcli.Command, args []string) ([]coin.Transaction, error) {
+	parsedArgs, err := prepareCreateRawTxnArgs(c, args)
+	if err != nil {
+		return nil, err
+	}
+
+	wlt, err := wallet.Load(parsedArgs.WalletID)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	var password []byte
+	if wlt.IsEncrypted() {
+		password, err = parsedArgs.Password.Password()
+		if err != nil {
+			return nil, err
+		}
+		// Avoid prompting for the password again
+		parsedArgs.Password = PasswordFromBytes(password)
+	}
+
+	txn, err := createRawTxnFromArgs(parsedArgs)
+	if err == nil {
+		return []coin.Transaction{*txn}, nil
+	}
+
+	if e, ok := err.(visor.ErrTxnViolatesSoftConstraint); !ok || e.Err != visor.ErrTxnExceedsMaxBlockSize {
+		return nil, err
+	}
+
+	inAddrs := []string{parsedArgs.Address}
+	if parsedArgs.Address == "" {
+		inAddrs = inAddrs[:0]
+		for _, a := range wlt.GetAddresses() {
+			inAddrs = append(inAddrs, a.String())
+		}
+	}
+
+	return CreateSplitRawTxns(apiClient, wlt, inAddrs, parsedArgs.ChangeAddress, parsedArgs.SendAmounts, password)
+}
+
+// spentTransactionIDs returns the IDs of the transactions of txns whose outputs are spent by txn
+func spentTransactionIDs(txns []coin.Transaction, txn coin.Transaction) []string {
+	spent := make(map[cipher.SHA256]struct{}, len(txn.In))
+	for _, h := range txn.In {
+		spent[h] = struct{}{}
+	}
+
+	var txids []string
+	for _, t := range txns {
+		// The hash of an output doesn't depend on the block it is created in, other than the genesis block
+		for _, ux := range coin.CreateUnspents(coin.BlockHeader{BkSeq: 1}, t) {
+			if _, ok := spent[ux.Hash()]; ok {
+				txids = append(txids, t.Hash().Hex())
+				break
+			}
+		}
+	}
+
+	return txids
+}
+
+// unsentTransactionsError returns an error with the raw transactions of a split spend which were not sent
+func unsentTransactionsError(err error, txns []coin.Transaction) error {
+	if len(txns) == 1 {
+		rawPayment, serr := txns[0].SerializeHex()
+		if serr != nil {
+			return serr
+		}
+		return fmt.Errorf("%v. The payment transaction was not sent, it can be broadcast once the consolidation transactions are confirmed: %s", err, rawPayment)
+	}
+
+	rawTxns := make([]string, len(txns))
+	for i, txn := range txns {
+		var serr error
+		rawTxns[i], serr = txn.SerializeHex()
+		if serr != nil {
+			return serr
+		}
+	}
+	return fmt.Errorf("%v. The last %d transactions were not sent, they can be broadcast in order once the transactions they spend are confirmed: %s", err, len(txns), strings.Join(rawTxns, " "))
+}
+
+// waitTransactionsConfirmed waits until the transactions are confirmed, or returns an error after timeout
+func waitTransactionsConfirmed(txids []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, txid := range txids {
+		for {
+			txn, err := apiClient.Transaction(txid)
+			if err != nil {
+				return err
+			}
+
+			if txn.Status.Confirmed {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("transaction %s was not confirmed within %v", txid, timeout)
+			}
+
+			time.Sleep(splitConfirmInterval)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -17,7 +18,12 @@ func showSeedCmd() *cobra.Command {
     Use caution when using the "-p" command. If you have command history enabled
     your wallet encryption password can be recovered from the history log. If you
     do not include the "-p" option you will be prompted to enter your password
-    after you enter your command.`, cliConfig.FullWalletPath()),
+    after you enter your command.
+
+    Use --shares and --threshold to split the seed into share mnemonics with
+    Shamir's secret sharing, one per line. Any --threshold shares recover the
+    seed with "walletCreate --from-shares", fewer shares reveal nothing about it.
+    Store each share in a separate place.`, cliConfig.FullWalletPath()),
 		SilenceUsage: true,
 		RunE: func(c *cobra.Command, _ []string) error {
 			w, err := resolveWalletPath(cliConfig, "")
@@ -35,6 +41,23 @@ func showSeedCmd() *cobra.Command {
 				return err
 			}
 
+			shares, err := c.Flags().GetInt("shares")
+			if err != nil {
+				return err
+			}
+
+			threshold, err := c.Flags().GetInt("threshold")
+			if err != nil {
+				return err
+			}
+
+			if shares == 0 && threshold != 0 {
+				return errors.New("--threshold can only be used with --shares")
+			}
+			if shares != 0 && threshold == 0 {
+				return errors.New("--threshold is required with --shares")
+			}
+
 			pr := NewPasswordReader([]byte(password))
 			seed, err := getSeed(w, pr)
 			switch err.(type) {
@@ -46,6 +69,28 @@ func showSeedCmd() *cobra.Command {
 				return err
 			}
 
+			if shares != 0 {
+				seedShares, err := wallet.SplitSeed(seed, shares, threshold)
+				if err != nil {
+					return err
+				}
+
+				if jsonOutput {
+					v := struct {
+						Shares []string `json:"shares"`
+					}{
+						Shares: seedShares,
+					}
+
+					return printJSON(v)
+				}
+
+				for _, s := range seedShares {
+					fmt.Println(s)
+				}
+				return nil
+			}
+
 			if jsonOutput {
 				v := struct {
 					Seed string `json:"seed"`
@@ -63,6 +108,8 @@ func showSeedCmd() *cobra.Command {
 
 	showSeedCmd.Flags().StringP("password", "p", "", "Wallet password")
 	showSeedCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+	showSeedCmd.Flags().Int("shares", 0, "Split the seed into this number of share mnemonics")
+	showSeedCmd.Flags().Int("threshold", 0, "Number of share mnemonics required to recover the seed, required with --shares")
 
 	return showSeedCmd
 }
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func signerServeCmd() *gcli.Command {
+	signerServeCmd := &gcli.Command{
+		Short: "Serve the external signer protocol with the keys of a wallet",
+		Use:   "signerServe [flags]",
+		Long: fmt.Sprintf(`Serve the external signer protocol with the secret keys of a wallet
+    file, so that a node can sign the transactions of a wallet whose
+    signer_socket option is set without holding its keys. The default
+    wallet (%s) will be used if the wallet file or path is not specified.
+
+    The protocol exchanges one JSON object per line. Requests are read from
+    stdin and responses written to stdout, unless a unix socket is given
+    with --socket. The socket is created with 0600 permissions, and on
+    Linux connections from other users are refused.
+
+    Use caution when using the "-p" command. If you have command history enabled
+    your wallet encryption password can be recovered from the history log. If you
+    do not include the "-p" option you will be prompted to enter your password
+    after you enter your command.`, cliConfig.FullWalletPath()),
+		Args:         gcli.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, _ []string) error {
+			walletFile, err := c.Flags().GetString("wallet-file")
+			if err != nil {
+				return err
+			}
+
+			w, err := resolveWalletPath(cliConfig, walletFile)
+			if err != nil {
+				return err
+			}
+
+			password, err := c.Flags().GetString("password")
+			if err != nil {
+				return err
+			}
+
+			socketPath, err := c.Flags().GetString("socket")
+			if err != nil {
+				return err
+			}
+
+			signer, err := walletSigner(w, NewPasswordReader([]byte(password)))
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				printHelp(c)
+				return err
+			default:
+				return err
+			}
+
+			if socketPath == "" {
+				return wallet.ServeSigner(os.Stdin, os.Stdout, signer)
+			}
+
+			return serveSignerSocket(socketPath, signer)
+		},
+	}
+
+	signerServeCmd.Flags().StringP("wallet-file", "f", "", "wallet file or path. If no path is specified your default wallet path will be used.")
+	signerServeCmd.Flags().StringP("password", "p", "", "Wallet password")
+	signerServeCmd.Flags().StringP("socket", "s", "", "Unix socket to listen on instead of stdin and stdout")
+
+	return signerServeCmd
+}
+
+// walletSigner creates a software signer with the secret keys of a wallet file
+func walletSigner(walletFile string, pr PasswordReader) (*wallet.SoftwareSigner, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	collectKeys := func(w *wallet.Wallet) []cipher.SecKey {
+		var keys []cipher.SecKey
+		for _, e := range w.Entries {
+			if e.Secret != (cipher.SecKey{}) {
+				keys = append(keys, e.Secret)
+			}
+		}
+		return keys
+	}
+
+	var keys []cipher.SecKey
+	if wlt.IsEncrypted() {
+		password, err := pr.Password()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := wlt.GuardView(password, func(w *wallet.Wallet) error {
+			keys = collectKeys(w)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		keys = collectKeys(wlt)
+	}
+
+	if len(keys) == 0 {
+		return nil, wallet.ErrWalletCantSign
+	}
+
+	return wallet.NewSoftwareSigner(keys)
+}
+
+// serveSignerSocket serves the external signer protocol on a unix socket.
+// Only connections from the current user are served.
+func serveSignerSocket(socketPath string, signer wallet.Signer) error {
+	l, err := listenSignerSocket(socketPath)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	return serveSignerListener(l, signer)
+}
+
+// serveSignerListener serves the external signer protocol on the connections of a listener
+// whose peer passes checkSignerPeer
+func serveSignerListener(l net.Listener, signer wallet.Signer) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		if err := checkSignerPeer(conn); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			conn.Close()
+			continue
+		}
+
+		go func() {
+			defer conn.Close()
+			if err := wallet.ServeSigner(conn, conn, signer); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+}
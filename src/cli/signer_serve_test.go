@@ -0,0 +1,51 @@
+// +build !windows
+
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestServeSignerSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-signer-socket")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "signer.sock")
+
+	l, err := listenSignerSocket(socketPath)
+	require.NoError(t, err)
+	defer l.Close()
+
+	// Only the current user can connect to the socket
+	fi, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	pk, sk := cipher.GenerateKeyPair()
+	addr := cipher.AddressFromPubKey(pk)
+
+	ss, err := wallet.NewSoftwareSigner([]cipher.SecKey{sk})
+	require.NoError(t, err)
+
+	go serveSignerListener(l, ss) // nolint: errcheck
+
+	req := wallet.SignInputRequest{
+		InnerHash: testutil.RandSHA256(t),
+		Input:     testutil.RandSHA256(t),
+		Address:   addr,
+	}
+
+	sig, err := wallet.NewExternalSigner(socketPath).SignInput(req)
+	require.NoError(t, err)
+	require.NoError(t, cipher.VerifyAddressSignedHash(addr, sig, req.Hash()))
+}
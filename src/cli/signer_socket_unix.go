@@ -0,0 +1,17 @@
+// +build !windows
+
+package cli
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenSignerSocket listens on a unix socket which only the current user can connect to.
+// The umask is set while the socket is created, so other users can't connect before its permissions are restricted.
+func listenSignerSocket(socketPath string) (net.Listener, error) {
+	oldMask := syscall.Umask(0177)
+	defer syscall.Umask(oldMask)
+
+	return net.Listen("unix", socketPath)
+}
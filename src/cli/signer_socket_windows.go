@@ -0,0 +1,10 @@
+// +build windows
+
+package cli
+
+import "net"
+
+// listenSignerSocket listens on a unix socket
+func listenSignerSocket(socketPath string) (net.Listener, error) {
+	return net.Listen("unix", socketPath)
+}
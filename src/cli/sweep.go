@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func walletSweepCmd() *gcli.Command {
+	walletSweepCmd := &gcli.Command{
+		Short: "Sweep the coins of secret keys or a seed into a wallet of the node",
+		Use:   "walletSweep [flags] [wallet id] [secret key...]",
+		Long: `Move all coins and coin hours owned by secret keys, or by the first
+    addresses of a deterministic wallet seed, to a new address of a wallet
+    loaded by the node. The keys can be hex encoded or in the Bitcoin wallet
+    import format (WIF).
+
+    The transaction is signed with the swept keys only, then injected and
+    broadcast by the node. The wallet password is only used to generate the
+    new address, you will be prompted for it if the wallet is encrypted and
+    the "-p" option is not used. Outputs spent by unconfirmed transactions
+    are not swept.
+
+    Use caution when passing keys or seeds on the command line. If you have
+    command history enabled they can be recovered from the history log.`,
+		Args:         gcli.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			seed, err := c.Flags().GetString("seed")
+			if err != nil {
+				return err
+			}
+
+			addresses, err := c.Flags().GetUint64("addresses")
+			if err != nil {
+				return err
+			}
+
+			keys := args[1:]
+			switch {
+			case len(keys) == 0 && seed == "":
+				printHelp(c)
+				return errors.New("secret keys or seed are required")
+			case len(keys) != 0 && seed != "":
+				printHelp(c)
+				return errors.New("secret keys and seed cannot be combined")
+			}
+
+			wlt, err := apiClient.Wallet(args[0])
+			if err != nil {
+				return err
+			}
+
+			var password []byte
+			if wlt.Meta.Encrypted {
+				pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+				password, err = pr.Password()
+				if err != nil {
+					return err
+				}
+			}
+
+			rsp, err := apiClient.WalletSweep(api.WalletSweepRequest{
+				ID:        args[0],
+				Password:  string(password),
+				Keys:      keys,
+				Seed:      seed,
+				Addresses: addresses,
+			})
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			out := rsp.Transaction.Out[0]
+			fmt.Printf("Swept %s coins and %s coin hours to %s\n", out.Coins, out.Hours, out.Address)
+			fmt.Printf("txid: %s\n", rsp.Transaction.TxID)
+			return nil
+		},
+	}
+
+	walletSweepCmd.Flags().StringP("seed", "s", "", "deterministic wallet seed to sweep, instead of secret keys")
+	walletSweepCmd.Flags().Uint64P("addresses", "n", 0, "number of addresses of the seed to sweep, defaults to 10")
+	walletSweepCmd.Flags().StringP("password", "p", "", "wallet password")
+	walletSweepCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return walletSweepCmd
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func walletExportCmd() *gcli.Command {
+	walletExportCmd := &gcli.Command{
+		Short: "Export wallets of the node to an encrypted bundle file",
+		Use:   "walletExport [flags] [wallet id...]",
+		Long: `Export wallets loaded by the node, with the transaction notes of their
+    addresses, to a password encrypted bundle file which can be imported
+    with walletImport. The wallets are copied unchanged, encrypted wallets
+    remain encrypted with their own password.
+
+    The node must have the INSECURE_WALLET_SEED API set enabled, since the
+    bundle contains the seeds of unencrypted wallets.
+
+    Use caution when using the "-p" command. If you have command history enabled
+    the bundle password can be recovered from the history log. If you do not
+    include the "-p" option you will be prompted to enter the password after
+    you enter your command.`,
+		Args:         gcli.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			out, err := c.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+
+			if out == "" {
+				printHelp(c)
+				return errors.New("output file is required")
+			}
+
+			if _, err := os.Stat(out); err == nil {
+				return fmt.Errorf("%s already exists", out)
+			}
+
+			cryptoType, err := wallet.CryptoTypeFromString(c.Flag("crypto-type").Value.String())
+			if err != nil {
+				printHelp(c)
+				return err
+			}
+
+			pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+			password, err := pr.Password()
+			if err != nil {
+				return err
+			}
+
+			bundle, err := apiClient.WalletExport(args, string(password), string(cryptoType))
+			if err != nil {
+				return err
+			}
+
+			d, err := json.MarshalIndent(bundle, "", "    ")
+			if err != nil {
+				return err
+			}
+
+			if err := ioutil.WriteFile(out, d, 0600); err != nil {
+				return err
+			}
+
+			fmt.Printf("Exported %d wallets to %s\n", len(args), out)
+			return nil
+		},
+	}
+
+	walletExportCmd.Flags().StringP("output", "o", "", "bundle file to create")
+	walletExportCmd.Flags().StringP("password", "p", "", "bundle password")
+	walletExportCmd.Flags().StringP("crypto-type", "x", "scrypt-chacha20poly1305", "The crypto type for bundle encryption, can be scrypt-chacha20poly1305, argon2id-chacha20poly1305 or sha256-xor")
+	return walletExportCmd
+}
+
+func walletImportCmd() *gcli.Command {
+	walletImportCmd := &gcli.Command{
+		Short: "Import the wallets of an encrypted bundle file into the node",
+		Use:   "walletImport [flags] [bundle file]",
+		Long: `Import the wallets and transaction notes of a bundle file created by
+    walletExport into the node. A wallet whose filename is already used is
+    imported with a numeric suffix, e.g. foo_1.wlt. A wallet with the same
+    seed as a loaded wallet is skipped.
+
+    Use caution when using the "-p" command. If you have command history enabled
+    the bundle password can be recovered from the history log. If you do not
+    include the "-p" option you will be prompted to enter the password after
+    you enter your command.`,
+		Args:         gcli.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *gcli.Command, args []string) error {
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			d, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			var bundle api.WalletBundle
+			if err := json.Unmarshal(d, &bundle); err != nil {
+				return fmt.Errorf("invalid bundle file: %v", err)
+			}
+
+			pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+			password, err := pr.Password()
+			if err != nil {
+				return err
+			}
+
+			rsp, err := apiClient.WalletImport(bundle, string(password))
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			for _, w := range rsp.Wallets {
+				switch {
+				case w.DuplicateOf != "":
+					fmt.Printf("%s skipped, it has the same seed as %s\n", w.Filename, w.DuplicateOf)
+				case w.ImportedAs != w.Filename:
+					fmt.Printf("%s imported as %s\n", w.Filename, w.ImportedAs)
+				default:
+					fmt.Printf("%s imported\n", w.Filename)
+				}
+			}
+			fmt.Printf("%d transaction notes imported\n", rsp.TransactionNotes)
+
+			return nil
+		},
+	}
+
+	walletImportCmd.Flags().StringP("password", "p", "", "bundle password")
+	walletImportCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+	return walletImportCmd
+}
@@ -213,6 +213,11 @@ type DaemonConfig struct { // nolint: golint
 	UnconfirmedRefreshRate time.Duration
 	// How often to remove transactions that become permanently invalid from the unconfirmed pool
 	UnconfirmedRemoveInvalidRate time.Duration
+	// How often to send the pending payouts of the wallets
+	SendPayoutsRate time.Duration
+	// Send the payouts queued in the wallets. Payouts are queued with the wallet API,
+	// so this is enabled with the wallet API
+	EnablePayouts bool
 	// Default "trusted" peers
 	DefaultConnections []string
 	// User agent (sent in introduction messages)
@@ -260,6 +265,7 @@ func NewDaemonConfig() DaemonConfig {
 		BlockCreationInterval:        10,
 		UnconfirmedRefreshRate:       time.Minute,
 		UnconfirmedRemoveInvalidRate: time.Minute,
+		SendPayoutsRate:              time.Minute,
 		Mirror:                       rand.New(rand.NewSource(time.Now().UTC().UnixNano())).Uint32(),
 		UnconfirmedVerifyTxn:         params.UserVerifyTxn,
 		MaxOutgoingMessageLength:     256 * 1024,
@@ -489,6 +495,16 @@ func (dm *Daemon) Run() error {
 		}()
 	}
 
+	// Send the payouts in a separate goroutine, since signing and injecting their transactions
+	// would block the daemon run loop
+	if dm.config.EnablePayouts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dm.runSendPayouts()
+		}()
+	}
+
 	var setupErr error
 	elapser := elapse.NewElapser(daemonRunDurationThreshold, logger)
 
@@ -1784,3 +1800,89 @@ func (dm *Daemon) InjectBroadcastTransaction(txn coin.Transaction) error {
 		return nil
 	})
 }
+
+// runSendPayouts sends the queued payouts every SendPayoutsRate, until the daemon quits
+func (dm *Daemon) runSendPayouts() {
+	ticker := time.NewTicker(dm.config.SendPayoutsRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-dm.quit:
+			return
+		case <-ticker.C:
+			queued, err := dm.visor.HasQueuedPayouts()
+			if err != nil {
+				logger.WithError(err).Error("HasQueuedPayouts failed")
+				continue
+			}
+			if !queued {
+				continue
+			}
+
+			if err := dm.sendPayouts(); err != nil {
+				logger.WithError(err).Error("sendPayouts failed")
+			}
+		}
+	}
+}
+
+// sendPayouts updates the status of the sent payouts, then packs the pending payouts of each wallet
+// into transactions which are injected and broadcast. Errors of a wallet's payouts are recorded in the
+// payouts, which are retried on the next call.
+func (dm *Daemon) sendPayouts() error {
+	if err := dm.visor.UpdateSentPayouts(); err != nil {
+		return err
+	}
+
+	wltIDs, err := dm.visor.PendingPayoutWallets()
+	if err != nil {
+		return err
+	}
+
+	for _, wltID := range wltIDs {
+		// Each transaction sends at least one payout, so this stops once all the payouts are sent
+		for {
+			txn, ids, err := dm.visor.CreatePayoutTransaction(wltID)
+			if err == nil && txn == nil {
+				break
+			}
+
+			if err == nil {
+				err = dm.injectBroadcastPayoutTransaction(*txn, ids)
+			}
+
+			if err != nil {
+				logger.WithError(err).WithField("wallet", wltID).Warning("Failed to send payouts")
+				if err := dm.visor.SetPayoutsError(ids, err); err != nil {
+					return err
+				}
+				break
+			}
+
+			logger.WithFields(logrus.Fields{
+				"wallet":  wltID,
+				"txid":    txn.Hash().Hex(),
+				"payouts": len(ids),
+			}).Info("Sent payouts")
+		}
+	}
+
+	return nil
+}
+
+// injectBroadcastPayoutTransaction injects and broadcasts a transaction sending payouts, and marks the payouts as sent
+func (dm *Daemon) injectBroadcastPayoutTransaction(txn coin.Transaction, ids []uint64) error {
+	return dm.visor.WithUpdateTx("daemon.injectBroadcastPayoutTransaction", func(tx *dbutil.Tx) error {
+		_, head, inputs, err := dm.visor.InjectUserTransactionTx(tx, txn)
+		if err != nil {
+			return err
+		}
+
+		if err := dm.visor.SetPayoutsSentTx(tx, ids, txn.Hash()); err != nil {
+			return err
+		}
+
+		return dm.BroadcastUserTransaction(txn, head, inputs)
+	})
+}
@@ -44,18 +44,29 @@ func NewAddressBalances(wab wallet.AddressBalances) AddressBalances {
 
 // WalletEntry the wallet entry struct
 type WalletEntry struct {
-	Address string `json:"address"`
-	Public  string `json:"public_key"`
+	Address     string   `json:"address"`
+	Public      string   `json:"public_key"`
+	ChildNumber *uint32  `json:"child_number,omitempty"` // For bip44 and xpub
+	Change      *uint32  `json:"change,omitempty"`       // For bip44
+	Label       string   `json:"label,omitempty"`
+	Created     int64    `json:"created,omitempty"`
+	Purpose     string   `json:"purpose,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
 }
 
 // WalletMeta the wallet meta struct
 type WalletMeta struct {
-	Coin       string `json:"coin"`
-	Filename   string `json:"filename"`
-	Label      string `json:"label"`
-	Type       string `json:"type"`
-	Version    string `json:"version"`
-	CryptoType string `json:"crypto_type"`
-	Timestamp  int64  `json:"timestamp"`
-	Encrypted  bool   `json:"encrypted"`
+	Coin         string  `json:"coin"`
+	Filename     string  `json:"filename"`
+	Label        string  `json:"label"`
+	Type         string  `json:"type"`
+	Version      string  `json:"version"`
+	CryptoType   string  `json:"crypto_type"`
+	Timestamp    int64   `json:"timestamp"`
+	Encrypted    bool    `json:"encrypted"`
+	Bip44Coin    *uint32 `json:"bip44_coin,omitempty"`    // For bip44
+	Bip44Account *uint32 `json:"bip44_account,omitempty"` // For bip44
+	XPub         string  `json:"xpub,omitempty"`          // For xpub
+	FreshChange  bool    `json:"fresh_change,omitempty"`
+	SignerSocket string  `json:"signer_socket,omitempty"`
 }
@@ -152,6 +152,8 @@ type NodeConfig struct {
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum total size of transactions in a block
 	MaxBlockTransactionsSize uint32
+	// Allow unconfirmed transactions to spend the outputs of other unconfirmed transactions
+	SpendUnconfirmed bool
 
 	unconfirmedBurnFactor          uint64
 	maxUnconfirmedTransactionSize  uint64
@@ -166,6 +168,12 @@ type NodeConfig struct {
 	WalletDirectory string
 	// Wallet crypto type
 	WalletCryptoType string
+	// Argon2id parameters of wallets encrypted with the argon2id-chacha20poly1305 crypto type
+	WalletArgon2idTime        uint
+	WalletArgon2idMemory      uint
+	WalletArgon2idParallelism uint
+	// Load and unload the wallet files added to or removed from the wallet directory while running
+	WatchWalletDirectory bool
 
 	// Key-value storage
 	// Default to ${DataDirectory}/data
@@ -298,6 +306,10 @@ func NewNodeConfig(mode string, node NodeParameters) NodeConfig {
 		WalletDirectory:  "",
 		WalletCryptoType: string(wallet.CryptoTypeScryptChacha20poly1305),
 
+		WalletArgon2idTime:        uint(wallet.DefaultArgon2idParams.Time),
+		WalletArgon2idMemory:      uint(wallet.DefaultArgon2idParams.Memory),
+		WalletArgon2idParallelism: uint(wallet.DefaultArgon2idParams.Parallelism),
+
 		// Key-value storage
 		KVStorageDirectory: "",
 		EnabledStorageTypes: []kvstorage.Type{
@@ -691,6 +703,7 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.Uint64Var(&c.createBlockMaxTransactionSize, "max-txn-size-create-block", uint64(c.CreateBlockVerifyTxn.MaxTransactionSize), "maximum size of a transaction applied when creating blocks")
 	flag.Uint64Var(&c.createBlockMaxDropletPrecision, "max-decimals-create-block", uint64(c.CreateBlockVerifyTxn.MaxDropletPrecision), "max number of decimal places applied when creating blocks")
 	flag.Uint64Var(&c.maxBlockSize, "max-block-size", uint64(c.MaxBlockTransactionsSize), "maximum total size of transactions in a block")
+	flag.BoolVar(&c.SpendUnconfirmed, "spend-unconfirmed", c.SpendUnconfirmed, "allow transactions to spend the outputs of unconfirmed transactions")
 
 	flag.BoolVar(&c.RunBlockPublisher, "block-publisher", c.RunBlockPublisher, "run the daemon as a block publisher")
 	flag.StringVar(&c.BlockchainPubkeyStr, "blockchain-public-key", c.BlockchainPubkeyStr, "public key of the blockchain")
@@ -701,6 +714,7 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.Uint64Var(&c.GenesisTimestamp, "genesis-timestamp", c.GenesisTimestamp, "genesis block timestamp")
 
 	flag.StringVar(&c.WalletDirectory, "wallet-dir", c.WalletDirectory, "location of the wallet files. Defaults to ~/.skycoin/wallet/")
+	flag.BoolVar(&c.WatchWalletDirectory, "watch-wallet-dir", c.WatchWalletDirectory, "load and unload the wallet files added to, changed in or removed from the wallet directory while running")
 	flag.StringVar(&c.KVStorageDirectory, "storage-dir", c.KVStorageDirectory, "location of the storage data files. Defaults to ~/.skycoin/data/")
 	flag.IntVar(&c.MaxConnections, "max-connections", c.MaxConnections, "Maximum number of total connections allowed")
 	flag.IntVar(&c.MaxOutgoingConnections, "max-outgoing-connections", c.MaxOutgoingConnections, "Maximum number of outgoing connections allowed")
@@ -711,7 +725,10 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.IntVar(&c.MaxIncomingMessageLength, "max-in-msg-len", c.MaxIncomingMessageLength, "Maximum length of incoming wire messages")
 	flag.BoolVar(&c.LocalhostOnly, "localhost-only", c.LocalhostOnly, "Run on localhost and only connect to localhost peers")
 	flag.BoolVar(&c.Arbitrating, "arbitrating", c.Arbitrating, "Run node in arbitrating mode")
-	flag.StringVar(&c.WalletCryptoType, "wallet-crypto-type", c.WalletCryptoType, "wallet crypto type. Can be sha256-xor or scrypt-chacha20poly1305")
+	flag.StringVar(&c.WalletCryptoType, "wallet-crypto-type", c.WalletCryptoType, "wallet crypto type. Can be sha256-xor, scrypt-chacha20poly1305 or argon2id-chacha20poly1305")
+	flag.UintVar(&c.WalletArgon2idTime, "wallet-argon2id-time", c.WalletArgon2idTime, "argon2id time parameter of the argon2id-chacha20poly1305 wallet crypto type")
+	flag.UintVar(&c.WalletArgon2idMemory, "wallet-argon2id-memory", c.WalletArgon2idMemory, "argon2id memory parameter in KiB of the argon2id-chacha20poly1305 wallet crypto type")
+	flag.UintVar(&c.WalletArgon2idParallelism, "wallet-argon2id-parallelism", c.WalletArgon2idParallelism, "argon2id parallelism parameter of the argon2id-chacha20poly1305 wallet crypto type")
 	flag.BoolVar(&c.Version, "version", false, "show node version")
 }
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -278,6 +279,16 @@ func (c *Coin) Run() error {
 		}
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		if err := w.Run(); err != nil {
+			c.logger.Error(err)
+			errC <- err
+		}
+	}()
+
 	if c.config.Node.WebInterface {
 		cancelLaunchBrowser := make(chan struct{})
 
@@ -325,6 +336,9 @@ func (c *Coin) Run() error {
 	c.logger.Info("Closing daemon")
 	d.Shutdown()
 
+	c.logger.Info("Closing wallet service")
+	w.Shutdown()
+
 	c.logger.Info("Waiting for goroutines to finish")
 	wg.Wait()
 
@@ -390,6 +404,7 @@ func (c *Coin) ConfigureVisor() visor.Config {
 	vc.UnconfirmedVerifyTxn = c.config.Node.UnconfirmedVerifyTxn
 	vc.CreateBlockVerifyTxn = c.config.Node.CreateBlockVerifyTxn
 	vc.MaxBlockTransactionsSize = c.config.Node.MaxBlockTransactionsSize
+	vc.SpendUnconfirmed = c.config.Node.SpendUnconfirmed
 
 	vc.GenesisAddress = c.config.Node.genesisAddress
 	vc.GenesisSignature = c.config.Node.genesisSignature
@@ -405,6 +420,7 @@ func (c *Coin) ConfigureWallet() wallet.Config {
 	wc := wallet.NewConfig()
 
 	wc.WalletDir = c.config.Node.WalletDirectory
+	wc.WatchWalletDir = c.config.Node.WatchWalletDirectory
 	_, wc.EnableWalletAPI = c.config.Node.enabledAPISets[api.EndpointsWallet]
 	_, wc.EnableSeedAPI = c.config.Node.enabledAPISets[api.EndpointsInsecureWalletSeed]
 
@@ -416,6 +432,20 @@ func (c *Coin) ConfigureWallet() wallet.Config {
 
 	wc.CryptoType = cryptoType
 
+	if c.config.Node.WalletArgon2idTime > math.MaxUint32 || c.config.Node.WalletArgon2idMemory > math.MaxUint32 || c.config.Node.WalletArgon2idParallelism > math.MaxUint8 {
+		log.Panic("wallet argon2id parameters are too large")
+	}
+
+	wc.Argon2idParams = wallet.Argon2idParams{
+		Time:        uint32(c.config.Node.WalletArgon2idTime),
+		Memory:      uint32(c.config.Node.WalletArgon2idMemory),
+		Parallelism: uint8(c.config.Node.WalletArgon2idParallelism),
+	}
+
+	if err := wc.Argon2idParams.Validate(); err != nil {
+		log.Panic(err)
+	}
+
 	return wc
 }
 
@@ -467,6 +497,7 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Daemon.GenesisHash = c.config.Node.genesisHash
 	dc.Daemon.UserAgent = c.config.Node.userAgent
 	dc.Daemon.UnconfirmedVerifyTxn = c.config.Node.UnconfirmedVerifyTxn
+	_, dc.Daemon.EnablePayouts = c.config.Node.enabledAPISets[api.EndpointsWallet]
 
 	if c.config.Node.OutgoingConnectionsRate == 0 {
 		c.config.Node.OutgoingConnectionsRate = time.Millisecond
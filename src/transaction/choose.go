@@ -9,6 +9,7 @@ import (
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/util/mathutil"
 )
 
 var (
@@ -20,8 +21,26 @@ var (
 	ErrZeroSpend = NewError(errors.New("zero spend amount"))
 	// ErrNoUnspents is returned if a Create is called with no unspent outputs
 	ErrNoUnspents = NewError(errors.New("no unspents to spend"))
+	// ErrUnspentsFrozen is returned if a Create is called with only frozen unspent outputs
+	ErrUnspentsFrozen = NewError(errors.New("no unspents to spend, all unspents are frozen"))
+	// ErrNoExactMatch is returned if no combination of unspent outputs has exactly the coins of a spend
+	ErrNoExactMatch = NewError(errors.New("no combination of unspents matches the spend amount exactly"))
+	// ErrNoSingleAddressSpend is returned if no single address has enough coins and hours for a spend
+	ErrNoSingleAddressSpend = NewError(errors.New("no single address has enough coins and hours for the spend"))
 )
 
+// exactMatchMaxTries bounds the number of combinations searched by ChooseSpendsExactMatch
+const exactMatchMaxTries = 100000
+
+// coinSelectionStrategies maps the coin selection names of Params to their spend choosing methods
+var coinSelectionStrategies = map[string]func([]UxBalance, uint64, uint64) ([]UxBalance, error){
+	CoinSelectionMinimizeUxOuts: ChooseSpendsMinimizeUxOuts,
+	CoinSelectionMaximizeUxOuts: ChooseSpendsMaximizeUxOuts,
+	CoinSelectionExactMatch:     ChooseSpendsExactMatch,
+	CoinSelectionSingleAddress:  ChooseSpendsSingleAddress,
+	CoinSelectionRetainHours:    ChooseSpendsRetainHours,
+}
+
 // UxBalance is an intermediate representation of a UxOut for sorting and spend choosing
 type UxBalance struct {
 	Hash           cipher.SHA256
@@ -84,6 +103,18 @@ func uxBalancesSub(a, b []UxBalance) []UxBalance {
 	return x
 }
 
+// uxBalancesOfAddress returns the uxouts of uxa owned by addr
+func uxBalancesOfAddress(uxa []UxBalance, addr cipher.Address) []UxBalance {
+	var x []UxBalance
+	for _, ux := range uxa {
+		if ux.Address == addr {
+			x = append(x, ux)
+		}
+	}
+
+	return x
+}
+
 // ChooseSpendsMinimizeUxOuts chooses uxout spends to satisfy an amount, using the least number of uxouts
 //     -- PRO: Allows more frequent spending, less waiting for confirmations, useful for exchanges.
 //     -- PRO: When transaction is volume is higher, transactions are prioritized by fee/size. Minimizing uxouts minimizes size.
@@ -183,6 +214,13 @@ func cmpUxBalanceByUxID(a, b UxBalance) bool {
 // It then chooses uxouts with zero coinhours, ordered by sortStrategy
 // It then chooses remaining uxouts with nonzero coinhours, ordered by sortStrategy
 func ChooseSpends(uxa []UxBalance, coins, hours uint64, sortStrategy func([]UxBalance)) ([]UxBalance, error) {
+	return chooseSpends(uxa, coins, hours, sortSpendsCoinsHighToLow, sortStrategy, sortStrategy)
+}
+
+// chooseSpends chooses uxouts like ChooseSpends, choosing the first uxout with nonzero coinhours
+// from the uxouts ordered by firstStrategy, then the uxouts with zero coinhours ordered by zeroStrategy,
+// then the remaining uxouts with nonzero coinhours ordered by nonzeroStrategy
+func chooseSpends(uxa []UxBalance, coins, hours uint64, firstStrategy, zeroStrategy, nonzeroStrategy func([]UxBalance)) ([]UxBalance, error) {
 	if coins == 0 {
 		return nil, ErrZeroSpend
 	}
@@ -213,8 +251,8 @@ func ChooseSpends(uxa []UxBalance, coins, hours uint64, sortStrategy func([]UxBa
 		return nil, fee.ErrTxnNoFee
 	}
 
-	// Sort uxouts with hours lowest to highest and coins highest to lowest
-	sortSpendsCoinsHighToLow(nonzero)
+	// Sort uxouts with hours to choose the first one
+	firstStrategy(nonzero)
 
 	var haveCoins uint64
 	var haveHours uint64
@@ -239,7 +277,7 @@ func ChooseSpends(uxa []UxBalance, coins, hours uint64, sortStrategy func([]UxBa
 	}
 
 	// Sort uxouts without hours according to the sorting strategy
-	sortStrategy(zero)
+	zeroStrategy(zero)
 
 	for _, ux := range zero {
 		spending = append(spending, ux)
@@ -257,7 +295,7 @@ func ChooseSpends(uxa []UxBalance, coins, hours uint64, sortStrategy func([]UxBa
 	}
 
 	// Sort remaining uxouts with hours according to the sorting strategy
-	sortStrategy(nonzero)
+	nonzeroStrategy(nonzero)
 
 	for _, ux := range nonzero {
 		spending = append(spending, ux)
@@ -276,3 +314,182 @@ func ChooseSpends(uxa []UxBalance, coins, hours uint64, sortStrategy func([]UxBa
 
 	return nil, ErrInsufficientHours
 }
+
+// ChooseSpendsExactMatch chooses uxout spends whose coins add up to exactly the amount, so that no change output is created.
+// The combinations of uxouts are searched depth first with branch and bound, trying the uxouts with the most coins first,
+// so the first combination found tends to use few uxouts.
+//     -- PRO: No change output is created, which avoids revealing which output is the change and avoids creating dust.
+//     -- CON: Remaining coin hours can't be returned as change. Manually specified hours which are left over are burned.
+//     -- CON: The search is bounded, so an exact match may not be found among a large number of uxouts.
+// If no exact match exists, ErrNoExactMatch is returned.
+func ChooseSpendsExactMatch(uxa []UxBalance, coins, hours uint64) ([]UxBalance, error) {
+	if coins == 0 {
+		return nil, ErrZeroSpend
+	}
+
+	if len(uxa) == 0 {
+		return nil, ErrNoUnspents
+	}
+
+	var totalCoins, totalHours uint64
+	for _, ux := range uxa {
+		if ux.Coins == 0 {
+			logger.Panic("UxOut coins are 0, can't spend")
+			return nil, errors.New("UxOut coins are 0, can't spend")
+		}
+
+		var err error
+		totalCoins, err = mathutil.AddUint64(totalCoins, ux.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		totalHours, err = mathutil.AddUint64(totalHours, ux.Hours)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Abort if there are no uxouts with non-zero coinhours, they can't be spent yet
+	if totalHours == 0 {
+		return nil, fee.ErrTxnNoFee
+	}
+
+	if totalCoins < coins {
+		return nil, ErrInsufficientBalance
+	}
+
+	sorted := make([]UxBalance, len(uxa))
+	copy(sorted, uxa)
+	sortSpendsCoinsHighToLow(sorted)
+
+	// remainingCoins[i] is the sum of coins of sorted[i:], to bound the search
+	remainingCoins := make([]uint64, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remainingCoins[i] = remainingCoins[i+1] + sorted[i].Coins
+	}
+
+	var selected []int
+	var tries int
+	var coinsMatched bool
+
+	var search func(i int, haveCoins, haveHours uint64) bool
+	search = func(i int, haveCoins, haveHours uint64) bool {
+		if tries >= exactMatchMaxTries {
+			return false
+		}
+		tries++
+
+		if haveCoins == coins {
+			// Adding any other uxout would exceed the amount
+			if haveHours > 0 && fee.RemainingHours(haveHours, params.UserVerifyTxn.BurnFactor) >= hours {
+				return true
+			}
+			coinsMatched = true
+			return false
+		}
+
+		if i == len(sorted) || haveCoins+remainingCoins[i] < coins {
+			return false
+		}
+
+		// Branch with sorted[i], if it doesn't exceed the amount
+		if haveCoins+sorted[i].Coins <= coins {
+			selected = append(selected, i)
+			if search(i+1, haveCoins+sorted[i].Coins, haveHours+sorted[i].Hours) {
+				return true
+			}
+			selected = selected[:len(selected)-1]
+		}
+
+		// Branch without sorted[i]
+		return search(i+1, haveCoins, haveHours)
+	}
+
+	if !search(0, 0, 0) {
+		if coinsMatched {
+			return nil, ErrInsufficientHours
+		}
+		return nil, ErrNoExactMatch
+	}
+
+	spending := make([]UxBalance, len(selected))
+	for i, j := range selected {
+		spending[i] = sorted[j]
+	}
+
+	return spending, nil
+}
+
+// ChooseSpendsSingleAddress chooses uxout spends owned by a single address, using the least number of uxouts.
+// The address which can satisfy the amount with the least number of uxouts is used,
+// then the one with the least change coins, then the address whose bytes are lexically sorted first.
+//     -- PRO: Spending never links addresses together, which improves privacy.
+//     -- CON: A spend can fail even if the total balance of all addresses is sufficient.
+// If no single address can satisfy the amount, ErrNoSingleAddressSpend is returned.
+func ChooseSpendsSingleAddress(uxa []UxBalance, coins, hours uint64) ([]UxBalance, error) {
+	if coins == 0 {
+		return nil, ErrZeroSpend
+	}
+
+	if len(uxa) == 0 {
+		return nil, ErrNoUnspents
+	}
+
+	byAddress := make(map[cipher.Address][]UxBalance)
+	for _, ux := range uxa {
+		byAddress[ux.Address] = append(byAddress[ux.Address], ux)
+	}
+
+	addrs := make([]cipher.Address, 0, len(byAddress))
+	for a := range byAddress {
+		addrs = append(addrs, a)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+
+	var best []UxBalance
+	var bestCoins uint64
+	for _, a := range addrs {
+		spending, err := ChooseSpendsMinimizeUxOuts(byAddress[a], coins, hours)
+		if err != nil {
+			continue
+		}
+
+		var haveCoins uint64
+		for _, ux := range spending {
+			haveCoins, err = mathutil.AddUint64(haveCoins, ux.Coins)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if best == nil || len(spending) < len(best) || (len(spending) == len(best) && haveCoins < bestCoins) {
+			best = spending
+			bestCoins = haveCoins
+		}
+	}
+
+	if best != nil {
+		return best, nil
+	}
+
+	// Report why the spend can't be satisfied at all, if the uxouts of all addresses together can't satisfy it
+	if _, err := ChooseSpendsMinimizeUxOuts(uxa, coins, hours); err != nil {
+		return nil, err
+	}
+
+	return nil, ErrNoSingleAddressSpend
+}
+
+// ChooseSpendsRetainHours chooses uxout spends to satisfy an amount, spending the least coin hours.
+// It first chooses the uxout with the least coinhours that has nonzero coinhours.
+// It then chooses uxouts with zero coinhours, with the most coins first.
+// It then chooses remaining uxouts with nonzero coinhours, with the least coinhours first.
+//     -- PRO: The fee is burned from the coin hours of the spent uxouts, so spending less coin hours burns less.
+//     -- PRO: The uxouts with the most coin hours are kept, to be spent when coin hours are needed.
+//     -- CON: Spending may use more uxouts than ChooseSpendsMinimizeUxOuts.
+func ChooseSpendsRetainHours(uxa []UxBalance, coins, hours uint64) ([]UxBalance, error) {
+	return chooseSpends(uxa, coins, hours, sortSpendsHoursLowToHigh, sortSpendsCoinsHighToLow, sortSpendsHoursLowToHigh)
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"math/rand"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -532,3 +533,277 @@ func verifySortedHoursLowToHigh(t *testing.T, uxb []UxBalance) {
 		return a.Hours <= b.Hours
 	})
 }
+
+func TestChooseSpendsExactMatch(t *testing.T) {
+	uxb := []UxBalance{
+		{Hash: testutil.RandSHA256(t), Coins: 5e6, Hours: 10},
+		{Hash: testutil.RandSHA256(t), Coins: 4e6, Hours: 0},
+		{Hash: testutil.RandSHA256(t), Coins: 3e6, Hours: 20},
+		{Hash: testutil.RandSHA256(t), Coins: 2e6, Hours: 0},
+	}
+
+	cases := []struct {
+		name   string
+		uxb    []UxBalance
+		coins  uint64
+		hours  uint64
+		chosen []UxBalance
+		err    error
+	}{
+		{
+			name:  "zero spend",
+			uxb:   uxb,
+			coins: 0,
+			err:   ErrZeroSpend,
+		},
+		{
+			name:  "no unspents",
+			coins: 1e6,
+			err:   ErrNoUnspents,
+		},
+		{
+			name:  "no hours",
+			uxb:   []UxBalance{uxb[1], uxb[3]},
+			coins: 2e6,
+			err:   fee.ErrTxnNoFee,
+		},
+		{
+			name:  "insufficient balance",
+			uxb:   uxb,
+			coins: 15e6,
+			err:   ErrInsufficientBalance,
+		},
+		{
+			name:  "no exact match",
+			uxb:   uxb,
+			coins: 1e6,
+			err:   ErrNoExactMatch,
+		},
+		{
+			name:  "exact match without hours",
+			uxb:   uxb,
+			coins: 6e6,
+			err:   ErrInsufficientHours,
+		},
+		{
+			name:   "single output",
+			uxb:    uxb,
+			coins:  5e6,
+			chosen: []UxBalance{uxb[0]},
+		},
+		{
+			name:   "skips an output which exceeds the amount",
+			uxb:    uxb,
+			coins:  7e6,
+			chosen: []UxBalance{uxb[0], uxb[3]},
+		},
+		{
+			name:   "skips a combination without enough hours",
+			uxb:    uxb,
+			coins:  9e6,
+			hours:  15,
+			chosen: []UxBalance{uxb[1], uxb[2], uxb[3]},
+		},
+		{
+			name:  "insufficient hours",
+			uxb:   uxb,
+			coins: 9e6,
+			hours: 100,
+			err:   ErrInsufficientHours,
+		},
+		{
+			name:   "all outputs",
+			uxb:    uxb,
+			coins:  14e6,
+			chosen: []UxBalance{uxb[0], uxb[1], uxb[2], uxb[3]},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chosen, err := ChooseSpendsExactMatch(tc.uxb, tc.coins, tc.hours)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.chosen, chosen)
+		})
+	}
+}
+
+func TestChooseSpendsExactMatchRandom(t *testing.T) {
+	nRand := 1000
+	for i := 0; i < nRand; i++ {
+		// Keep the number of outputs small so that the search is exhaustive and can be compared to brute force
+		uxb := makeRandomUxBalances(t)
+		if len(uxb) > 12 {
+			uxb = uxb[:12]
+		}
+		coins := uint64(rand.Intn(30) + 1)
+
+		// Check by brute force if a subset of uxb has exactly the coins, with hours to pay the fee
+		exists := false
+		for subset := 1; subset < 1<<uint(len(uxb)); subset++ {
+			var haveCoins, haveHours uint64
+			for j, ux := range uxb {
+				if subset&(1<<uint(j)) != 0 {
+					haveCoins += ux.Coins
+					haveHours += ux.Hours
+				}
+			}
+			if haveCoins == coins && haveHours > 0 {
+				exists = true
+				break
+			}
+		}
+
+		chosen, err := ChooseSpendsExactMatch(uxb, coins, 0)
+		if !exists {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+
+		var haveCoins, haveHours uint64
+		for _, ux := range chosen {
+			haveCoins += ux.Coins
+			haveHours += ux.Hours
+		}
+		require.Equal(t, coins, haveCoins)
+		require.NotEqual(t, uint64(0), haveHours)
+		verifySortedCoinsHighToLow(t, chosen)
+	}
+}
+
+func TestChooseSpendsSingleAddress(t *testing.T) {
+	addrs := []cipher.Address{testutil.MakeAddress(), testutil.MakeAddress(), testutil.MakeAddress()}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+
+	uxb := []UxBalance{
+		{Hash: testutil.RandSHA256(t), Address: addrs[0], Coins: 2e6, Hours: 10},
+		{Hash: testutil.RandSHA256(t), Address: addrs[0], Coins: 2e6, Hours: 10},
+		{Hash: testutil.RandSHA256(t), Address: addrs[1], Coins: 3e6, Hours: 10},
+		{Hash: testutil.RandSHA256(t), Address: addrs[1], Coins: 1e6, Hours: 0},
+		{Hash: testutil.RandSHA256(t), Address: addrs[2], Coins: 5e6, Hours: 0},
+	}
+
+	cases := []struct {
+		name   string
+		uxb    []UxBalance
+		coins  uint64
+		hours  uint64
+		chosen []UxBalance
+		err    error
+	}{
+		{
+			name:  "zero spend",
+			uxb:   uxb,
+			coins: 0,
+			err:   ErrZeroSpend,
+		},
+		{
+			name:  "no unspents",
+			coins: 1e6,
+			err:   ErrNoUnspents,
+		},
+		{
+			name:  "insufficient balance",
+			uxb:   uxb,
+			coins: 20e6,
+			err:   ErrInsufficientBalance,
+		},
+		{
+			name:  "insufficient hours",
+			uxb:   uxb,
+			coins: 1e6,
+			hours: 100,
+			err:   ErrInsufficientHours,
+		},
+		{
+			name:  "enough balance only across addresses",
+			uxb:   uxb,
+			coins: 5e6,
+			err:   ErrNoSingleAddressSpend,
+		},
+		{
+			name:   "fewest outputs",
+			uxb:    uxb,
+			coins:  3e6,
+			chosen: []UxBalance{uxb[2]},
+		},
+		{
+			name:   "least change, then first address",
+			uxb:    uxb,
+			coins:  4e6,
+			chosen: []UxBalance{uxb[0], uxb[1]},
+		},
+		{
+			name:   "hours from one address",
+			uxb:    uxb,
+			coins:  2e6,
+			hours:  10,
+			chosen: []UxBalance{uxb[0], uxb[1]},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chosen, err := ChooseSpendsSingleAddress(tc.uxb, tc.coins, tc.hours)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, len(tc.chosen), len(chosen))
+			for _, ux := range tc.chosen {
+				require.Contains(t, chosen, ux)
+			}
+		})
+	}
+}
+
+func TestChooseSpendsRetainHours(t *testing.T) {
+	uxb := []UxBalance{
+		{Hash: testutil.RandSHA256(t), Coins: 5e6, Hours: 500},
+		{Hash: testutil.RandSHA256(t), Coins: 1e6, Hours: 10},
+		{Hash: testutil.RandSHA256(t), Coins: 1e6, Hours: 0},
+		{Hash: testutil.RandSHA256(t), Coins: 2e6, Hours: 0},
+		{Hash: testutil.RandSHA256(t), Coins: 2e6, Hours: 50},
+	}
+
+	cases := []struct {
+		name   string
+		coins  uint64
+		hours  uint64
+		chosen []UxBalance
+	}{
+		{
+			name:   "least hours",
+			coins:  1e6,
+			chosen: []UxBalance{uxb[1]},
+		},
+		{
+			name:   "zero hours outputs with most coins first",
+			coins:  3e6,
+			chosen: []UxBalance{uxb[1], uxb[3]},
+		},
+		{
+			name:   "nonzero hours outputs with least hours first",
+			coins:  5e6,
+			chosen: []UxBalance{uxb[1], uxb[3], uxb[2], uxb[4]},
+		},
+		{
+			name:   "hours",
+			coins:  1e6,
+			hours:  50,
+			chosen: []UxBalance{uxb[1], uxb[3], uxb[4]},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chosen, err := ChooseSpendsRetainHours(uxb, tc.coins, tc.hours)
+			require.NoError(t, err)
+			require.Equal(t, tc.chosen, chosen)
+		})
+	}
+
+	_, err := ChooseSpendsRetainHours(uxb[2:4], 1e6, 0)
+	require.Equal(t, fee.ErrTxnNoFee, err)
+}
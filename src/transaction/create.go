@@ -27,7 +27,8 @@ var (
 
 // Create creates an unsigned transaction based upon Params.
 // NOTE: Caller must ensure that auxs correspond to params.UxOuts options
-// Outputs to spend are chosen from the pool of outputs provided.
+// Outputs to spend are chosen from the pool of outputs provided, except the outputs in p.FrozenUxOuts
+// which are not in p.SelectedUxOuts.
 // The outputs are chosen by the following procedure:
 //   - All outputs are merged into one list and are sorted coins highest, hours lowest, with the hash as a tiebreaker
 //   - Outputs are chosen from the beginning of this list, until the requested amount of coins is met.
@@ -35,6 +36,9 @@ var (
 //   - If the total amount of coins in the chosen outputs is exactly equal to the requested amount of coins,
 //     such that there would be no change output but hours remain as change, another output will be chosen to create change,
 //     if the coinhour cost of adding that output is less than the coinhours that would be lost as change
+// p.CoinSelection chooses another strategy for the first two steps, see the ChooseSpends* methods.
+// With CoinSelectionExactMatch, no extra output is chosen to create change.
+// With CoinSelectionSingleAddress, the extra output is chosen from the address of the other outputs.
 // If receiving hours are not explicitly specified, hours are allocated amongst the receiving outputs proportional to the number of coins being sent to them.
 // If the change address is not specified, the address whose bytes are lexically sorted first is chosen from the owners of the outputs being spent.
 func Create(p Params, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []UxBalance, error) {
@@ -51,6 +55,14 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int) (
 	// Determine which unspents to spend
 	uxa := auxs.Flatten()
 
+	// Frozen outputs are not spent, unless the caller explicitly selected them
+	if unfrozen := p.unfrozen(uxa); len(unfrozen) != len(uxa) {
+		if len(unfrozen) == 0 {
+			return nil, nil, ErrUnspentsFrozen
+		}
+		uxa = unfrozen
+	}
+
 	uxb, err := NewUxBalances(uxa, headTime)
 	if err != nil {
 		return nil, nil, err
@@ -80,10 +92,15 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int) (
 		}
 	}
 
-	// Use the MinimizeUxOuts strategy, to use least possible uxouts
+	// Use the MinimizeUxOuts strategy by default, to use least possible uxouts
 	// this will allow more frequent spending
 	// we don't need to check whether we have sufficient balance beforehand as ChooseSpends already checks that
-	spends, err := ChooseSpendsMinimizeUxOuts(uxb, totalOutCoins, requestedHours)
+	chooseSpends := ChooseSpendsMinimizeUxOuts
+	if p.CoinSelection != "" {
+		chooseSpends = coinSelectionStrategies[p.CoinSelection]
+	}
+
+	spends, err := chooseSpends(uxb, totalOutCoins, requestedHours)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -191,12 +208,16 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int) (
 	// This chooses an available input with the least number of coin hours;
 	// if the extra coin hour fee incurred by this additional input is less than
 	// the remaining coin hours, the input is added.
-	if changeCoins == 0 && changeHours > 0 {
+	// An exact match coin selection avoids the change output, so no input is added.
+	if changeCoins == 0 && changeHours > 0 && p.CoinSelection != CoinSelectionExactMatch {
 		logger.Debug("Trying to recover change hours by forcing an extra input")
 		// Find the output with the least coin hours
 		// If size of the fee for this output is less than the changeHours, add it
 		// Update changeCoins and changeHours
 		z := uxBalancesSub(uxb, spends)
+		if p.CoinSelection == CoinSelectionSingleAddress {
+			z = uxBalancesOfAddress(z, spends[0].Address)
+		}
 		sortSpendsHoursLowToHigh(z)
 		if len(z) > 0 {
 			logger.Debug("Extra input found, evaluating if it can recover change hours")
@@ -444,5 +465,51 @@ func VerifyCreatedInvariants(p Params, txn *coin.Transaction, inputs []UxBalance
 		return errors.New("Transaction will not satisy required fee")
 	}
 
+	switch p.CoinSelection {
+	case CoinSelectionExactMatch:
+		if len(txn.Out) != len(p.To) {
+			return errors.New("Transaction has a change output with exact match coin selection")
+		}
+
+		var inputCoins uint64
+		for _, i := range inputs {
+			var err error
+			inputCoins, err = mathutil.AddUint64(inputCoins, i.Coins)
+			if err != nil {
+				return err
+			}
+		}
+
+		var outputCoins uint64
+		for _, o := range txn.Out {
+			var err error
+			outputCoins, err = mathutil.AddUint64(outputCoins, o.Coins)
+			if err != nil {
+				return err
+			}
+		}
+
+		if inputCoins != outputCoins {
+			return errors.New("Total input coins do not match the output coins with exact match coin selection")
+		}
+
+	case CoinSelectionSingleAddress:
+		for _, i := range inputs {
+			if i.Address != inputs[0].Address {
+				return errors.New("Inputs are owned by more than one address with single address coin selection")
+			}
+		}
+
+		if p.ChangeAddress == nil && len(txn.Out) == len(p.To)+1 && txn.Out[len(p.To)].Address != inputs[0].Address {
+			return errors.New("Change output is not sent to the inputs' address with single address coin selection")
+		}
+
+	case CoinSelectionRetainHours:
+		// Change hours are returned to the change output, so only the required fee is burned
+		if len(txn.Out) == len(p.To)+1 && inputHours-outputHours != fee.RequiredFee(inputHours, params.UserVerifyTxn.BurnFactor) {
+			return errors.New("Transaction burns more coin hours than the required fee with retain hours coin selection")
+		}
+	}
+
 	return nil
 }
@@ -80,6 +80,16 @@ func TestCreate(t *testing.T) {
 		uxoutsNoHours[i], uxoutsNoHours[j] = uxoutsNoHours[j], uxoutsNoHours[i]
 	})
 
+	// Create unspent outputs with different coins and hours
+	retainUxouts := []coin.UxOut{
+		makeUxOut(t, secKey, 5e6, 500),
+		makeUxOut(t, secKey, 1e6, 10),
+		makeUxOut(t, secKey, 2e6, 0),
+	}
+	for i := range retainUxouts {
+		retainUxouts[i].Head.Time = headTime
+	}
+
 	changeAddress := testutil.MakeAddress()
 
 	validParams := Params{
@@ -257,6 +267,67 @@ func TestCreate(t *testing.T) {
 			chosenUnspents: []coin.UxOut{originalUxouts[0]},
 		},
 
+		{
+			name: "manual, 1 output, no change, frozen unspent",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   90,
+						Coins:   2e6,
+					},
+				},
+				FrozenUxOuts: []cipher.SHA256{originalUxouts[0].Hash()},
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[1]},
+		},
+
+		{
+			name: "manual, 1 output, no change, frozen unspent selected",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   90,
+						Coins:   2e6,
+					},
+				},
+				FrozenUxOuts:   []cipher.SHA256{originalUxouts[0].Hash()},
+				SelectedUxOuts: []cipher.SHA256{originalUxouts[0].Hash()},
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0]},
+		},
+
+		{
+			name: "all unspents frozen",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   90,
+						Coins:   2e6,
+					},
+				},
+				FrozenUxOuts: []cipher.SHA256{originalUxouts[0].Hash(), originalUxouts[1].Hash()},
+			},
+			unspents: originalUxouts[:2],
+			err:      ErrUnspentsFrozen,
+		},
+
 		// TODO -- belongs in visor_wallet_test.go
 		// {
 		// 	name: "manual, 1 output, no change, unknown address in auxs",
@@ -639,6 +710,120 @@ func TestCreate(t *testing.T) {
 			err: fee.ErrTxnNoFee,
 		},
 
+		{
+			name: "manual, exact match coin selection, no change output",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   10,
+						Coins:   4e6,
+					},
+				},
+				CoinSelection: CoinSelectionExactMatch,
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0], originalUxouts[1]},
+		},
+
+		{
+			name: "exact match coin selection, no exact match",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   10,
+						Coins:   3e6,
+					},
+				},
+				CoinSelection: CoinSelectionExactMatch,
+			},
+			unspents: uxouts,
+			err:      ErrNoExactMatch,
+		},
+
+		{
+			name: "manual, single address coin selection, change to the spent address",
+			params: Params{
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   10,
+						Coins:   3e6,
+					},
+				},
+				CoinSelection: CoinSelectionSingleAddress,
+			},
+			addressUnspents: coin.AddressUxOuts{
+				extraWalletAddrs[0]: []coin.UxOut{extraUxouts[0][0]},
+				extraWalletAddrs[3]: []coin.UxOut{extraUxouts[3][1], extraUxouts[3][2]},
+			},
+			chosenUnspents: []coin.UxOut{extraUxouts[3][1], extraUxouts[3][2]},
+			changeOutput: &coin.TransactionOutput{
+				Address: extraWalletAddrs[3],
+				Hours:   172,
+				Coins:   1e6,
+			},
+		},
+
+		{
+			name: "single address coin selection, no single address has enough coins",
+			params: Params{
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   10,
+						Coins:   5e6,
+					},
+				},
+				CoinSelection: CoinSelectionSingleAddress,
+			},
+			addressUnspents: coin.AddressUxOuts{
+				extraWalletAddrs[0]: []coin.UxOut{extraUxouts[0][0]},
+				extraWalletAddrs[3]: []coin.UxOut{extraUxouts[3][1], extraUxouts[3][2]},
+			},
+			err: ErrNoSingleAddressSpend,
+		},
+
+		{
+			name: "manual, retain hours coin selection",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   1,
+						Coins:   2e6,
+					},
+				},
+				CoinSelection: CoinSelectionRetainHours,
+			},
+			unspents:       retainUxouts,
+			chosenUnspents: []coin.UxOut{retainUxouts[1], retainUxouts[2]},
+			changeOutput: &coin.TransactionOutput{
+				Address: changeAddress,
+				Hours:   8,
+				Coins:   1e6,
+			},
+		},
+
 		{
 			name:     "duplicate unspent output",
 			unspents: append(uxouts, uxouts[:2]...),
@@ -763,6 +948,56 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestVerifyCreatedInvariantsCoinSelection(t *testing.T) {
+	headTime := uint64(time.Now().UTC().Unix())
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+
+	auxs := make(coin.AddressUxOuts)
+	for _, s := range secKeys {
+		uxout := makeUxOut(t, s, 2e6, 100)
+		uxout.Head.Time = headTime
+		auxs[uxout.Body.Address] = []coin.UxOut{uxout}
+	}
+
+	changeAddress := testutil.MakeAddress()
+	p := Params{
+		HoursSelection: HoursSelection{
+			Type: HoursSelectionTypeManual,
+		},
+		ChangeAddress: &changeAddress,
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Hours:   10,
+				Coins:   3e6,
+			},
+		},
+	}
+
+	// The transaction spends outputs of two addresses and has a change output
+	txn, inputs, err := Create(p, auxs, headTime)
+	require.NoError(t, err)
+	require.Len(t, inputs, 2)
+	require.Len(t, txn.Out, 2)
+	require.NoError(t, VerifyCreatedInvariants(p, txn, inputs))
+
+	p.CoinSelection = CoinSelectionExactMatch
+	err = VerifyCreatedInvariants(p, txn, inputs)
+	require.Equal(t, errors.New("Transaction has a change output with exact match coin selection"), err)
+
+	p.CoinSelection = CoinSelectionSingleAddress
+	err = VerifyCreatedInvariants(p, txn, inputs)
+	require.Equal(t, errors.New("Inputs are owned by more than one address with single address coin selection"), err)
+
+	p.CoinSelection = CoinSelectionRetainHours
+	require.NoError(t, VerifyCreatedInvariants(p, txn, inputs))
+
+	// Burning the change hours violates the retain hours invariant
+	txn.Out[1].Hours = 0
+	err = VerifyCreatedInvariants(p, txn, inputs)
+	require.Equal(t, errors.New("Transaction burns more coin hours than the required fee with retain hours coin selection"), err)
+}
+
 func makeUxOut(t *testing.T, s cipher.SecKey, coins, hours uint64) coin.UxOut { // nolint: unparam
 	body := makeUxBody(t, s, coins, hours)
 	tm := rand.Int31n(1000)
@@ -31,8 +31,28 @@ const (
 
 	// HoursSelectionModeShare will distribute coin hours equally amongst destinations
 	HoursSelectionModeShare = "share"
+
+	// CoinSelectionMinimizeUxOuts chooses the outputs to spend with the least number of outputs. This is the default.
+	CoinSelectionMinimizeUxOuts = "minimize_uxouts"
+	// CoinSelectionMaximizeUxOuts chooses the outputs to spend with the most number of outputs
+	CoinSelectionMaximizeUxOuts = "maximize_uxouts"
+	// CoinSelectionExactMatch chooses outputs whose coins add up to the amount exactly, so that there is no change output
+	CoinSelectionExactMatch = "exact_match"
+	// CoinSelectionSingleAddress chooses outputs owned by a single address, to avoid linking addresses together
+	CoinSelectionSingleAddress = "single_address"
+	// CoinSelectionRetainHours chooses the outputs with the least coin hours, to retain the most coin hours
+	CoinSelectionRetainHours = "retain_hours"
 )
 
+// CoinSelections are the names of the coin selection strategies
+var CoinSelections = []string{
+	CoinSelectionMinimizeUxOuts,
+	CoinSelectionMaximizeUxOuts,
+	CoinSelectionExactMatch,
+	CoinSelectionSingleAddress,
+	CoinSelectionRetainHours,
+}
+
 var (
 	// ErrNullChangeAddress ChangeAddress must not be the null address
 	ErrNullChangeAddress = NewError(errors.New("ChangeAddress must not be the null address"))
@@ -60,6 +80,8 @@ var (
 	ErrInvalidShareFactor = NewError(errors.New("HoursSelection.ShareFactor can only be used for share mode"))
 	// ErrShareFactorOutOfRange HoursSelection.ShareFactor must be >= 0 and <= 1
 	ErrShareFactorOutOfRange = NewError(errors.New("HoursSelection.ShareFactor must be >= 0 and <= 1"))
+	// ErrInvalidCoinSelection Invalid CoinSelection
+	ErrInvalidCoinSelection = NewError(errors.New("Invalid CoinSelection"))
 )
 
 // HoursSelection defines options for hours distribution
@@ -74,6 +96,12 @@ type Params struct {
 	HoursSelection HoursSelection
 	To             []coin.TransactionOutput
 	ChangeAddress  *cipher.Address
+	// FrozenUxOuts are outputs which must not be spent, unless they are also in SelectedUxOuts
+	FrozenUxOuts []cipher.SHA256
+	// SelectedUxOuts are outputs explicitly chosen by the caller, which are spendable even if frozen
+	SelectedUxOuts []cipher.SHA256
+	// CoinSelection is the name of the strategy choosing the outputs to spend, defaults to CoinSelectionMinimizeUxOuts
+	CoinSelection string
 }
 
 // Validate validates Params
@@ -151,5 +179,35 @@ func (c Params) Validate() error {
 		}
 	}
 
+	if c.CoinSelection != "" {
+		if _, ok := coinSelectionStrategies[c.CoinSelection]; !ok {
+			return ErrInvalidCoinSelection
+		}
+	}
+
 	return nil
 }
+
+// unfrozen returns the outputs of uxa which are not frozen, or which are frozen but explicitly selected
+func (c Params) unfrozen(uxa coin.UxArray) coin.UxArray {
+	if len(c.FrozenUxOuts) == 0 {
+		return uxa
+	}
+
+	frozen := make(map[cipher.SHA256]struct{}, len(c.FrozenUxOuts))
+	for _, h := range c.FrozenUxOuts {
+		frozen[h] = struct{}{}
+	}
+	for _, h := range c.SelectedUxOuts {
+		delete(frozen, h)
+	}
+
+	unfrozen := make(coin.UxArray, 0, len(uxa))
+	for _, ux := range uxa {
+		if _, ok := frozen[ux.Hash()]; !ok {
+			unfrozen = append(unfrozen, ux)
+		}
+	}
+
+	return unfrozen
+}
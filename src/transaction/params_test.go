@@ -247,6 +247,19 @@ func TestCreateWalletParamsVerify(t *testing.T) {
 			err: "To contains duplicate values",
 		},
 
+		{
+			name: "invalid coin selection",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            toManual,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				CoinSelection: "foo",
+			},
+			err: "Invalid CoinSelection",
+		},
+
 		{
 			name: "valid auto split even share factor",
 			params: Params{
@@ -270,6 +283,18 @@ func TestCreateWalletParamsVerify(t *testing.T) {
 				},
 			},
 		},
+
+		{
+			name: "valid manual exact match coin selection",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            toManual,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				CoinSelection: CoinSelectionExactMatch,
+			},
+		},
 	}
 
 	for _, tc := range cases {
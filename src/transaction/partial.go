@@ -0,0 +1,390 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// PartialTransactionVersion is the version of the partially signed transaction format
+// created by this package
+const PartialTransactionVersion = 1
+
+var (
+	// ErrUnsupportedPartialTransactionVersion is returned if a partially signed transaction has an unknown version
+	ErrUnsupportedPartialTransactionVersion = NewError(errors.New("unsupported partial transaction version"))
+	// ErrPartialTransactionInputsMismatch is returned if the inputs of a partially signed transaction
+	// do not match the inputs of its transaction
+	ErrPartialTransactionInputsMismatch = NewError(errors.New("partial transaction inputs do not match transaction inputs"))
+	// ErrPartialTransactionsMismatch is returned if partially signed transactions of different transactions are combined
+	ErrPartialTransactionsMismatch = NewError(errors.New("partial transactions are not for the same transaction"))
+	// ErrPartialTransactionNotFullySigned is returned if a partially signed transaction is finalized before all inputs are signed
+	ErrPartialTransactionNotFullySigned = NewError(errors.New("partial transaction is not fully signed"))
+	// ErrNoPartialTransactions is returned if no partially signed transactions are combined
+	ErrNoPartialTransactions = NewError(errors.New("no partial transactions to combine"))
+)
+
+// PartialTransactionInput is an input of a partially signed transaction.
+// It carries the output being spent, so that the input can be signed and verified
+// without access to the blockchain, and hints for locating the key which signs it.
+type PartialTransactionInput struct {
+	UxOut coin.UxOut
+	// Signer is the ID of the wallet that owns the input's address, if known
+	Signer string
+	// ChildNumber is the child number of the input's address in a bip44 or xpub wallet
+	ChildNumber uint32
+	// Change is the chain of the input's address in a bip44 wallet
+	Change uint32
+}
+
+// PartialTransaction is a transaction which is being signed by one or more parties.
+// Each party signs the inputs it controls, and the partially signed transactions
+// are combined and finalized once every input is signed.
+type PartialTransaction struct {
+	Version     uint32
+	Transaction coin.Transaction
+	Inputs      []PartialTransactionInput
+}
+
+// NewPartialTransaction creates a PartialTransaction from a transaction and the outputs spent by its inputs.
+// The outputs must be in the same order as the transaction inputs.
+// The transaction may be unsigned or partially signed.
+func NewPartialTransaction(txn coin.Transaction, uxOuts []coin.UxOut) (*PartialTransaction, error) {
+	inputs := make([]PartialTransactionInput, len(uxOuts))
+	for i, o := range uxOuts {
+		inputs[i] = PartialTransactionInput{
+			UxOut: o,
+		}
+	}
+
+	pt := &PartialTransaction{
+		Version:     PartialTransactionVersion,
+		Transaction: copyTransaction(txn),
+		Inputs:      inputs,
+	}
+
+	if len(pt.Transaction.Sigs) == 0 {
+		pt.Transaction.Sigs = make([]cipher.Sig, len(pt.Transaction.In))
+		if err := pt.Transaction.UpdateHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := pt.Validate(); err != nil {
+		return nil, err
+	}
+
+	return pt, nil
+}
+
+// Validate checks that the partially signed transaction is well formed,
+// that its inputs match the outputs being spent and that its signatures are valid
+func (pt *PartialTransaction) Validate() error {
+	if pt.Version != PartialTransactionVersion {
+		return ErrUnsupportedPartialTransactionVersion
+	}
+
+	txn := pt.Transaction
+	if len(pt.Inputs) != len(txn.In) {
+		return ErrPartialTransactionInputsMismatch
+	}
+
+	for i, in := range pt.Inputs {
+		if in.UxOut.Hash() != txn.In[i] {
+			return ErrPartialTransactionInputsMismatch
+		}
+	}
+
+	verify := txn.VerifyUnsigned
+	if txn.IsFullySigned() {
+		verify = txn.Verify
+	}
+	if err := verify(); err != nil {
+		return NewError(fmt.Errorf("invalid transaction: %v", err))
+	}
+
+	if err := txn.VerifyPartialInputSignatures(pt.UxOuts()); err != nil {
+		return NewError(fmt.Errorf("invalid transaction: %v", err))
+	}
+
+	return nil
+}
+
+// UxOuts returns the outputs spent by the transaction, in the order of the transaction inputs
+func (pt *PartialTransaction) UxOuts() coin.UxArray {
+	uxOuts := make(coin.UxArray, len(pt.Inputs))
+	for i, in := range pt.Inputs {
+		uxOuts[i] = in.UxOut
+	}
+	return uxOuts
+}
+
+// IsFullySigned returns true if every input of the transaction is signed
+func (pt *PartialTransaction) IsFullySigned() bool {
+	return pt.Transaction.IsFullySigned()
+}
+
+// Copy returns a deep copy of the partially signed transaction
+func (pt *PartialTransaction) Copy() *PartialTransaction {
+	inputs := make([]PartialTransactionInput, len(pt.Inputs))
+	copy(inputs, pt.Inputs)
+
+	return &PartialTransaction{
+		Version:     pt.Version,
+		Transaction: copyTransaction(pt.Transaction),
+		Inputs:      inputs,
+	}
+}
+
+// CombinePartialTransactions merges the signatures and signer hints of partially signed transactions
+// of the same transaction. The partially signed transactions are not modified.
+func CombinePartialTransactions(pts ...*PartialTransaction) (*PartialTransaction, error) {
+	if len(pts) == 0 {
+		return nil, ErrNoPartialTransactions
+	}
+
+	for _, pt := range pts {
+		if err := pt.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	combined := pts[0].Copy()
+	for _, pt := range pts[1:] {
+		if pt.Transaction.InnerHash != combined.Transaction.InnerHash {
+			return nil, ErrPartialTransactionsMismatch
+		}
+
+		for i, in := range pt.Inputs {
+			if in.UxOut != combined.Inputs[i].UxOut {
+				return nil, ErrPartialTransactionsMismatch
+			}
+
+			// Both signatures have been verified, so either one can be kept
+			if combined.Transaction.Sigs[i].Null() {
+				combined.Transaction.Sigs[i] = pt.Transaction.Sigs[i]
+			}
+
+			if combined.Inputs[i].Signer == "" && in.Signer != "" {
+				combined.Inputs[i].Signer = in.Signer
+				combined.Inputs[i].ChildNumber = in.ChildNumber
+				combined.Inputs[i].Change = in.Change
+			}
+		}
+	}
+
+	if err := combined.Transaction.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	return combined, nil
+}
+
+// Finalize returns the signed transaction, once every input of the partially signed transaction is signed
+func (pt *PartialTransaction) Finalize() (*coin.Transaction, error) {
+	if err := pt.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !pt.IsFullySigned() {
+		return nil, ErrPartialTransactionNotFullySigned
+	}
+
+	txn := copyTransaction(pt.Transaction)
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	if err := txn.VerifyInputSignatures(pt.UxOuts()); err != nil {
+		return nil, NewError(fmt.Errorf("invalid transaction: %v", err))
+	}
+
+	return &txn, nil
+}
+
+// PartialTransactionSignerStatus reports the signing progress of the inputs
+// that belong to one signer of a partially signed transaction
+type PartialTransactionSignerStatus struct {
+	// Signer is the signer hint of the inputs, or the address of the inputs if the signer is unknown
+	Signer string
+	// Inputs are the indexes of the signer's inputs
+	Inputs []int
+	// Signed is the number of the signer's inputs that are signed
+	Signed int
+}
+
+// PartialTransactionStatus reports the signing progress of a partially signed transaction
+type PartialTransactionStatus struct {
+	// Signed reports whether each input is signed
+	Signed []bool
+	// Signers is the signing progress of each signer, in order of their first input
+	Signers     []PartialTransactionSignerStatus
+	FullySigned bool
+}
+
+// Status returns the signing progress of the partially signed transaction
+func (pt *PartialTransaction) Status() PartialTransactionStatus {
+	status := PartialTransactionStatus{
+		Signed:      make([]bool, len(pt.Inputs)),
+		FullySigned: pt.IsFullySigned(),
+	}
+
+	signers := make(map[string]int)
+	for i, in := range pt.Inputs {
+		signed := !pt.Transaction.Sigs[i].Null()
+		status.Signed[i] = signed
+
+		signer := in.Signer
+		if signer == "" {
+			signer = in.UxOut.Body.Address.String()
+		}
+
+		j, ok := signers[signer]
+		if !ok {
+			j = len(status.Signers)
+			signers[signer] = j
+			status.Signers = append(status.Signers, PartialTransactionSignerStatus{
+				Signer: signer,
+			})
+		}
+
+		status.Signers[j].Inputs = append(status.Signers[j].Inputs, i)
+		if signed {
+			status.Signers[j].Signed++
+		}
+	}
+
+	return status
+}
+
+// partialTransactionJSON is the serialized form of a PartialTransaction
+type partialTransactionJSON struct {
+	Version     uint32                        `json:"version"`
+	Transaction string                        `json:"transaction"`
+	Inputs      []partialTransactionInputJSON `json:"inputs"`
+}
+
+type partialTransactionInputJSON struct {
+	Hash           string `json:"uxid"`
+	Time           uint64 `json:"time"`
+	BkSeq          uint64 `json:"block_seq"`
+	SrcTransaction string `json:"src_tx"`
+	Address        string `json:"address"`
+	Coins          uint64 `json:"coins"`
+	Hours          uint64 `json:"hours"`
+	Signer         string `json:"signer,omitempty"`
+	ChildNumber    uint32 `json:"child_number"`
+	Change         uint32 `json:"change"`
+}
+
+// Serialize encodes the partially signed transaction.
+// The encoding is JSON, with the transaction hex encoded and the spent outputs in readable form,
+// so that each signer can review what they sign.
+func (pt *PartialTransaction) Serialize() ([]byte, error) {
+	txnHex, err := pt.Transaction.SerializeHex()
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]partialTransactionInputJSON, len(pt.Inputs))
+	for i, in := range pt.Inputs {
+		inputs[i] = partialTransactionInputJSON{
+			Hash:           in.UxOut.Hash().Hex(),
+			Time:           in.UxOut.Head.Time,
+			BkSeq:          in.UxOut.Head.BkSeq,
+			SrcTransaction: in.UxOut.Body.SrcTransaction.Hex(),
+			Address:        in.UxOut.Body.Address.String(),
+			Coins:          in.UxOut.Body.Coins,
+			Hours:          in.UxOut.Body.Hours,
+			Signer:         in.Signer,
+			ChildNumber:    in.ChildNumber,
+			Change:         in.Change,
+		}
+	}
+
+	return json.MarshalIndent(partialTransactionJSON{
+		Version:     pt.Version,
+		Transaction: txnHex,
+		Inputs:      inputs,
+	}, "", "    ")
+}
+
+// DeserializePartialTransaction decodes and validates a serialized partially signed transaction
+func DeserializePartialTransaction(b []byte) (*PartialTransaction, error) {
+	var ptj partialTransactionJSON
+	if err := json.Unmarshal(b, &ptj); err != nil {
+		return nil, NewError(fmt.Errorf("invalid partial transaction: %v", err))
+	}
+
+	if ptj.Version != PartialTransactionVersion {
+		return nil, ErrUnsupportedPartialTransactionVersion
+	}
+
+	txn, err := coin.DeserializeTransactionHex(ptj.Transaction)
+	if err != nil {
+		return nil, NewError(fmt.Errorf("invalid partial transaction: %v", err))
+	}
+
+	inputs := make([]PartialTransactionInput, len(ptj.Inputs))
+	for i, in := range ptj.Inputs {
+		srcTxn, err := cipher.SHA256FromHex(in.SrcTransaction)
+		if err != nil {
+			return nil, NewError(fmt.Errorf("invalid partial transaction input %d src_tx: %v", i, err))
+		}
+
+		addr, err := cipher.DecodeBase58Address(in.Address)
+		if err != nil {
+			return nil, NewError(fmt.Errorf("invalid partial transaction input %d address: %v", i, err))
+		}
+
+		ux := coin.UxOut{
+			Head: coin.UxHead{
+				Time:  in.Time,
+				BkSeq: in.BkSeq,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: srcTxn,
+				Address:        addr,
+				Coins:          in.Coins,
+				Hours:          in.Hours,
+			},
+		}
+
+		if ux.Hash().Hex() != in.Hash {
+			return nil, NewError(fmt.Errorf("invalid partial transaction input %d: uxid does not match output", i))
+		}
+
+		inputs[i] = PartialTransactionInput{
+			UxOut:       ux,
+			Signer:      in.Signer,
+			ChildNumber: in.ChildNumber,
+			Change:      in.Change,
+		}
+	}
+
+	pt := &PartialTransaction{
+		Version:     ptj.Version,
+		Transaction: txn,
+		Inputs:      inputs,
+	}
+
+	if err := pt.Validate(); err != nil {
+		return nil, err
+	}
+
+	return pt, nil
+}
+
+func copyTransaction(txn coin.Transaction) coin.Transaction {
+	txn2 := txn
+	txn2.Sigs = make([]cipher.Sig, len(txn.Sigs))
+	copy(txn2.Sigs, txn.Sigs)
+	txn2.In = make([]cipher.SHA256, len(txn.In))
+	copy(txn2.In, txn.In)
+	txn2.Out = make([]coin.TransactionOutput, len(txn.Out))
+	copy(txn2.Out, txn.Out)
+	return txn2
+}
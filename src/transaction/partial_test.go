@@ -0,0 +1,318 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+// makeUnsignedTxn creates an unsigned transaction spending one output of each secret key
+func makeUnsignedTxn(t *testing.T, secKeys []cipher.SecKey) (coin.Transaction, []coin.UxOut) {
+	var txn coin.Transaction
+	uxOuts := make([]coin.UxOut, len(secKeys))
+	for i, s := range secKeys {
+		uxOuts[i] = makeUxOut(t, s, 2e6, 100)
+		err := txn.PushInput(uxOuts[i].Hash())
+		require.NoError(t, err)
+	}
+
+	err := txn.PushOutput(testutil.MakeAddress(), uint64(len(secKeys))*2e6, 50)
+	require.NoError(t, err)
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	return txn, uxOuts
+}
+
+// signInputs signs the inputs of a partially signed transaction at the given indexes
+func signInputs(t *testing.T, pt *PartialTransaction, secKeys []cipher.SecKey, indexes ...int) *PartialTransaction {
+	signed := pt.Copy()
+	for _, i := range indexes {
+		err := signed.Transaction.SignInput(secKeys[i], i)
+		require.NoError(t, err)
+	}
+	err := signed.Transaction.UpdateHeader()
+	require.NoError(t, err)
+	return signed
+}
+
+func TestNewPartialTransaction(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 3)
+	txn, uxOuts := makeUnsignedTxn(t, secKeys)
+
+	pt, err := NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+	require.Equal(t, uint32(PartialTransactionVersion), pt.Version)
+	require.Equal(t, txn, pt.Transaction)
+	require.Len(t, pt.Inputs, 3)
+	require.Equal(t, coin.UxArray(uxOuts), pt.UxOuts())
+	require.False(t, pt.IsFullySigned())
+
+	// The transaction is copied
+	pt.Transaction.Sigs[0] = cipher.MustSignHash(cipher.SumSHA256([]byte("foo")), secKeys[0])
+	require.True(t, txn.Sigs[0].Null())
+
+	// Transactions without a signatures array are given null signatures
+	txnNoSigs := txn
+	txnNoSigs.Sigs = nil
+	err = txnNoSigs.UpdateHeader()
+	require.NoError(t, err)
+	pt, err = NewPartialTransaction(txnNoSigs, uxOuts)
+	require.NoError(t, err)
+	require.Equal(t, txn, pt.Transaction)
+
+	// Outputs in the wrong order
+	_, err = NewPartialTransaction(txn, []coin.UxOut{uxOuts[1], uxOuts[0], uxOuts[2]})
+	require.Equal(t, ErrPartialTransactionInputsMismatch, err)
+
+	// Missing output
+	_, err = NewPartialTransaction(txn, uxOuts[:2])
+	require.Equal(t, ErrPartialTransactionInputsMismatch, err)
+
+	// Invalid signature
+	badTxn := txn
+	badTxn.Sigs = make([]cipher.Sig, len(txn.Sigs))
+	badTxn.Sigs[1] = cipher.MustSignHash(cipher.AddSHA256(txn.InnerHash, txn.In[1]), secKeys[0])
+	_, err = NewPartialTransaction(badTxn, uxOuts)
+	testutil.RequireError(t, err, "invalid transaction: Signature not valid for output being spent")
+
+	// Modified inner hash
+	badTxn = txn
+	badTxn.InnerHash = cipher.SumSHA256([]byte("foo"))
+	_, err = NewPartialTransaction(badTxn, uxOuts)
+	testutil.RequireError(t, err, "invalid transaction: InnerHash does not match computed hash")
+}
+
+func TestPartialTransactionValidate(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+	txn, uxOuts := makeUnsignedTxn(t, secKeys)
+
+	pt, err := NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+
+	pt.Version = 2
+	require.Equal(t, ErrUnsupportedPartialTransactionVersion, pt.Validate())
+	pt.Version = PartialTransactionVersion
+
+	// Fully signed partial transactions are valid
+	signed := signInputs(t, pt, secKeys, 0, 1)
+	require.NoError(t, signed.Validate())
+	require.True(t, signed.IsFullySigned())
+
+	// Modified output
+	bad := pt.Copy()
+	bad.Inputs[0].UxOut.Body.Coins++
+	require.Equal(t, ErrPartialTransactionInputsMismatch, bad.Validate())
+}
+
+func TestCombinePartialTransactions(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 3)
+	txn, uxOuts := makeUnsignedTxn(t, secKeys)
+
+	pt, err := NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+
+	a := signInputs(t, pt, secKeys, 0)
+	a.Inputs[0].Signer = "a.wlt"
+	b := signInputs(t, pt, secKeys, 1, 2)
+	b.Inputs[1].Signer = "b.wlt"
+	b.Inputs[1].ChildNumber = 4
+	b.Inputs[1].Change = 1
+
+	_, err = CombinePartialTransactions()
+	require.Equal(t, ErrNoPartialTransactions, err)
+
+	combined, err := CombinePartialTransactions(pt, a)
+	require.NoError(t, err)
+	require.Equal(t, a.Transaction, combined.Transaction)
+	require.False(t, combined.IsFullySigned())
+
+	combined, err = CombinePartialTransactions(a, pt, b)
+	require.NoError(t, err)
+	require.True(t, combined.IsFullySigned())
+	require.Equal(t, "a.wlt", combined.Inputs[0].Signer)
+	require.Equal(t, PartialTransactionInput{
+		UxOut:       uxOuts[1],
+		Signer:      "b.wlt",
+		ChildNumber: 4,
+		Change:      1,
+	}, combined.Inputs[1])
+	require.Equal(t, "", combined.Inputs[2].Signer)
+
+	// The combined partial transactions are not modified
+	require.True(t, pt.Transaction.IsFullyUnsigned())
+	require.True(t, a.Transaction.Sigs[1].Null())
+
+	// Partial transactions of another transaction can't be combined
+	otherTxn, otherUxOuts := makeUnsignedTxn(t, secKeys)
+	other, err := NewPartialTransaction(otherTxn, otherUxOuts)
+	require.NoError(t, err)
+	_, err = CombinePartialTransactions(a, other)
+	require.Equal(t, ErrPartialTransactionsMismatch, err)
+
+	// Invalid partial transactions can't be combined
+	bad := b.Copy()
+	bad.Transaction.Sigs[0] = b.Transaction.Sigs[1]
+	_, err = CombinePartialTransactions(a, bad)
+	testutil.RequireError(t, err, "invalid transaction: Signature not valid for output being spent")
+}
+
+func TestPartialTransactionFinalize(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+	txn, uxOuts := makeUnsignedTxn(t, secKeys)
+
+	pt, err := NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+
+	_, err = pt.Finalize()
+	require.Equal(t, ErrPartialTransactionNotFullySigned, err)
+
+	partial := signInputs(t, pt, secKeys, 1)
+	_, err = partial.Finalize()
+	require.Equal(t, ErrPartialTransactionNotFullySigned, err)
+
+	signed := signInputs(t, partial, secKeys, 0)
+	signedTxn, err := signed.Finalize()
+	require.NoError(t, err)
+	require.NoError(t, signedTxn.Verify())
+	require.NoError(t, signedTxn.VerifyInputSignatures(uxOuts))
+	require.Equal(t, txn.InnerHash, signedTxn.InnerHash)
+}
+
+func TestPartialTransactionStatus(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 3)
+	txn, uxOuts := makeUnsignedTxn(t, secKeys)
+
+	pt, err := NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+	pt.Inputs[0].Signer = "a.wlt"
+	pt.Inputs[2].Signer = "a.wlt"
+
+	require.Equal(t, PartialTransactionStatus{
+		Signed: []bool{false, false, false},
+		Signers: []PartialTransactionSignerStatus{
+			{
+				Signer: "a.wlt",
+				Inputs: []int{0, 2},
+			},
+			{
+				Signer: uxOuts[1].Body.Address.String(),
+				Inputs: []int{1},
+			},
+		},
+	}, pt.Status())
+
+	pt = signInputs(t, pt, secKeys, 2, 1)
+	require.Equal(t, PartialTransactionStatus{
+		Signed: []bool{false, true, true},
+		Signers: []PartialTransactionSignerStatus{
+			{
+				Signer: "a.wlt",
+				Inputs: []int{0, 2},
+				Signed: 1,
+			},
+			{
+				Signer: uxOuts[1].Body.Address.String(),
+				Inputs: []int{1},
+				Signed: 1,
+			},
+		},
+	}, pt.Status())
+
+	pt = signInputs(t, pt, secKeys, 0)
+	require.True(t, pt.Status().FullySigned)
+}
+
+func TestPartialTransactionSerialize(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 2)
+	txn, uxOuts := makeUnsignedTxn(t, secKeys)
+
+	pt, err := NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+	pt = signInputs(t, pt, secKeys, 1)
+	pt.Inputs[1].Signer = "b.wlt"
+	pt.Inputs[1].ChildNumber = 3
+
+	b, err := pt.Serialize()
+	require.NoError(t, err)
+
+	pt2, err := DeserializePartialTransaction(b)
+	require.NoError(t, err)
+	require.Equal(t, pt, pt2)
+
+	var ptj partialTransactionJSON
+	err = json.Unmarshal(b, &ptj)
+	require.NoError(t, err)
+	require.Equal(t, uint32(PartialTransactionVersion), ptj.Version)
+	require.Equal(t, uxOuts[0].Body.Address.String(), ptj.Inputs[0].Address)
+	require.Equal(t, "b.wlt", ptj.Inputs[1].Signer)
+
+	cases := []struct {
+		name   string
+		modify func(*partialTransactionJSON)
+		err    error
+	}{
+		{
+			name: "unsupported version",
+			modify: func(ptj *partialTransactionJSON) {
+				ptj.Version = 2
+			},
+			err: ErrUnsupportedPartialTransactionVersion,
+		},
+		{
+			name: "invalid transaction",
+			modify: func(ptj *partialTransactionJSON) {
+				ptj.Transaction = "foo"
+			},
+			err: NewError(errors.New("invalid partial transaction: encoding/hex: invalid byte: U+006F 'o'")),
+		},
+		{
+			name: "invalid address",
+			modify: func(ptj *partialTransactionJSON) {
+				ptj.Inputs[0].Address = "foo"
+			},
+			err: NewError(errors.New("invalid partial transaction input 0 address: Invalid address length")),
+		},
+		{
+			name: "modified output",
+			modify: func(ptj *partialTransactionJSON) {
+				ptj.Inputs[1].Coins++
+			},
+			err: NewError(errors.New("invalid partial transaction input 1: uxid does not match output")),
+		},
+		{
+			name: "missing input",
+			modify: func(ptj *partialTransactionJSON) {
+				ptj.Inputs = ptj.Inputs[:1]
+			},
+			err: ErrPartialTransactionInputsMismatch,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ptj partialTransactionJSON
+			err := json.Unmarshal(b, &ptj)
+			require.NoError(t, err)
+
+			tc.modify(&ptj)
+
+			b, err := json.Marshal(ptj)
+			require.NoError(t, err)
+
+			_, err = DeserializePartialTransaction(b)
+			require.Equal(t, tc.err, err)
+		})
+	}
+
+	_, err = DeserializePartialTransaction([]byte("foo"))
+	testutil.RequireError(t, err, "invalid partial transaction: invalid character 'o' in literal false (expecting 'a')")
+}
@@ -0,0 +1,261 @@
+package transaction
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+)
+
+var (
+	// ErrSplitTooLarge is returned if a spend needs too many unspent outputs to be split into transactions no larger than the maximum size
+	ErrSplitTooLarge = NewError(errors.New("spend needs too many unspents to be split into transactions no larger than the maximum transaction size"))
+)
+
+// SignFunc signs a transaction created by CreateSplit. inputs are the UxBalances of the transaction's inputs, in order.
+type SignFunc func(txn *coin.Transaction, inputs []UxBalance) error
+
+// CreateSplit creates a spend based upon Params as an ordered set of signed transactions, each no larger than maxSize bytes.
+// If the transaction created by Create is no larger than maxSize, it is the only transaction.
+// Otherwise the outputs chosen by Create are sent by consolidation transactions to a single output each,
+// followed by the payment transaction which is created by Create from the outputs of the consolidation transactions.
+// The chosen outputs are dealt to the consolidation transactions with the most coin hours first,
+// so that each consolidation transaction has coin hours to pay its fee.
+// If fewer of the chosen outputs have coin hours than there are consolidation transactions,
+// the consolidation transactions are chained instead: each one after the first also spends the output
+// of the previous one, which pays its fee, and only the output of the last one is spent by the payment transaction.
+// A chained consolidation transaction can only be injected once the previous one is confirmed.
+// The output of a consolidation transaction is sent to the address whose bytes are lexically sorted first among its inputs,
+// and receives all of the coins and the coin hours of the inputs which are not burned for the fee.
+// The coin hours of the payment transaction's inputs are the coin hours of the chosen outputs
+// less the fee of each consolidation transaction.
+// The payment transaction spends the outputs of the consolidation transactions, so it can only be injected once they are confirmed.
+// The hashes of the consolidation outputs depend on the signatures of the consolidation transactions,
+// so each transaction is signed by sign before the next transaction is created.
+func CreateSplit(p Params, auxs coin.AddressUxOuts, headTime uint64, maxSize uint32, sign SignFunc) ([]coin.Transaction, [][]UxBalance, error) {
+	txn, inputs, err := Create(p, auxs, headTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Transactions are created with null signatures, so the size of the signed transaction is known
+	size, err := txn.Size()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if size <= maxSize {
+		if err := sign(txn, inputs); err != nil {
+			return nil, nil, err
+		}
+
+		return []coin.Transaction{*txn}, [][]UxBalance{inputs}, nil
+	}
+
+	maxConsolidateInputs, err := maxSignedInputs(maxSize, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxPaymentInputs, err := maxSignedInputs(maxSize, len(p.To)+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if maxConsolidateInputs < 2 {
+		return nil, nil, ErrSplitTooLarge
+	}
+
+	n := (len(inputs) + maxConsolidateInputs - 1) / maxConsolidateInputs
+
+	sorted := make([]UxBalance, len(inputs))
+	copy(sorted, inputs)
+	sort.Slice(sorted, makeCmpUxOutByHours(sorted, func(a, b uint64) bool {
+		return a > b
+	}))
+
+	// Chained consolidation transactions have a single output left for the payment transaction
+	chained := countWithHours(sorted) < n
+	if !chained && n > maxPaymentInputs {
+		return nil, nil, ErrSplitTooLarge
+	}
+
+	var chunks [][]UxBalance
+	if chained {
+		// The first chunk has the outputs with coin hours, the other chunks leave room for the output of the previous chunk
+		start, size := 0, maxConsolidateInputs
+		for start < len(sorted) {
+			end := start + size
+			if end > len(sorted) {
+				end = len(sorted)
+			}
+			chunks = append(chunks, sorted[start:end])
+			start, size = end, maxConsolidateInputs-1
+		}
+	} else {
+		chunks = make([][]UxBalance, n)
+		for i, ux := range sorted {
+			chunks[i%n] = append(chunks[i%n], ux)
+		}
+	}
+
+	txns := make([]coin.Transaction, 0, len(chunks)+1)
+	txnInputs := make([][]UxBalance, 0, len(chunks)+1)
+	paymentAuxs := make(coin.AddressUxOuts, len(chunks))
+
+	var prev *UxBalance
+	for i, chunk := range chunks {
+		if prev != nil {
+			chunk = append([]UxBalance{*prev}, chunk...)
+		}
+
+		txn, err := splitConsolidateTransaction(chunk)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := sign(txn, chunk); err != nil {
+			return nil, nil, err
+		}
+
+		txns = append(txns, *txn)
+		txnInputs = append(txnInputs, chunk)
+
+		o := txn.Out[0]
+		ux := coin.UxOut{
+			Head: coin.UxHead{
+				Time: headTime,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: txn.Hash(),
+				Address:        o.Address,
+				Coins:          o.Coins,
+				Hours:          o.Hours,
+			},
+		}
+
+		// A chained consolidation output is spent by the next consolidation transaction
+		if chained && i != len(chunks)-1 {
+			b, err := NewUxBalance(headTime, ux)
+			if err != nil {
+				return nil, nil, err
+			}
+			prev = &b
+			continue
+		}
+
+		paymentAuxs[o.Address] = append(paymentAuxs[o.Address], ux)
+	}
+
+	// The payment spends the outputs of the consolidation transactions, which are neither frozen nor selected
+	p.FrozenUxOuts = nil
+	p.SelectedUxOuts = nil
+
+	payment, paymentInputs, err := Create(p, paymentAuxs, headTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	size, err = payment.Size()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if size > maxSize {
+		return nil, nil, ErrSplitTooLarge
+	}
+
+	if err := sign(payment, paymentInputs); err != nil {
+		return nil, nil, err
+	}
+
+	txns = append(txns, *payment)
+	txnInputs = append(txnInputs, paymentInputs)
+
+	return txns, txnInputs, nil
+}
+
+// splitConsolidateTransaction creates an unsigned transaction sending the inputs to a single output
+// of the address whose bytes are lexically sorted first among the inputs
+func splitConsolidateTransaction(uxb []UxBalance) (*coin.Transaction, error) {
+	var coins, hours uint64
+	addr := uxb[0].Address
+	for _, ux := range uxb {
+		var err error
+		coins, err = mathutil.AddUint64(coins, ux.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		hours, err = mathutil.AddUint64(hours, ux.Hours)
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Compare(ux.Address.Bytes(), addr.Bytes()) < 0 {
+			addr = ux.Address
+		}
+	}
+
+	if hours == 0 {
+		return nil, fee.ErrTxnNoFee
+	}
+
+	txn := &coin.Transaction{}
+	for _, ux := range uxb {
+		if err := txn.PushInput(ux.Hash); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := txn.PushOutput(addr, coins, fee.RemainingHours(hours, params.UserVerifyTxn.BurnFactor)); err != nil {
+		return nil, err
+	}
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// countWithHours returns the number of outputs with coin hours
+func countWithHours(uxb []UxBalance) int {
+	n := 0
+	for _, ux := range uxb {
+		if ux.Hours != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// maxSignedInputs returns the number of inputs of a signed transaction with nOutputs outputs which fit in maxSize bytes
+func maxSignedInputs(maxSize uint32, nOutputs int) (int, error) {
+	txn := coin.Transaction{
+		Out: make([]coin.TransactionOutput, nOutputs),
+	}
+	baseSize, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	if baseSize > maxSize {
+		return 0, nil
+	}
+
+	txn.In = []cipher.SHA256{{}}
+	txn.Sigs = []cipher.Sig{{}}
+	size, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	return int((maxSize - baseSize) / (size - baseSize)), nil
+}
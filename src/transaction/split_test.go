@@ -0,0 +1,243 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/fee"
+)
+
+func TestCreateSplit(t *testing.T) {
+	headTime := uint64(1000)
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 3)
+
+	keys := make(map[cipher.Address]cipher.SecKey, len(secKeys))
+	auxs := make(coin.AddressUxOuts)
+	for i := 0; i < 30; i++ {
+		s := secKeys[i%len(secKeys)]
+		ux := makeUxOut(t, s, 1e6, uint64(10+i))
+		auxs[ux.Body.Address] = append(auxs[ux.Body.Address], ux)
+		keys[ux.Body.Address] = s
+	}
+
+	sign := func(txn *coin.Transaction, inputs []UxBalance) error {
+		for i, in := range inputs {
+			if err := txn.SignInput(keys[in.Address], i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// signedSize returns the size of a signed transaction with nInputs inputs and nOutputs outputs
+	signedSize := func(nInputs, nOutputs int) uint32 {
+		txn := coin.Transaction{
+			In:   make([]cipher.SHA256, nInputs),
+			Sigs: make([]cipher.Sig, nInputs),
+			Out:  make([]coin.TransactionOutput, nOutputs),
+		}
+		size, err := txn.Size()
+		require.NoError(t, err)
+		return size
+	}
+
+	destAddr := testutil.MakeAddress()
+	shareFactor := decimal.New(5, -1)
+	newParams := func(coins uint64) Params {
+		return Params{
+			HoursSelection: HoursSelection{
+				Type:        HoursSelectionTypeAuto,
+				Mode:        HoursSelectionModeShare,
+				ShareFactor: &shareFactor,
+			},
+			To: []coin.TransactionOutput{
+				{
+					Address: destAddr,
+					Coins:   coins,
+				},
+			},
+		}
+	}
+
+	t.Run("single transaction", func(t *testing.T) {
+		p := newParams(5e6)
+		txns, inputs, err := CreateSplit(p, auxs, headTime, params.UserVerifyTxn.MaxTransactionSize, sign)
+		require.NoError(t, err)
+		require.Len(t, txns, 1)
+		require.Len(t, inputs, 1)
+		require.True(t, txns[0].IsFullySigned())
+		require.NoError(t, VerifyCreatedInvariants(p, &txns[0], inputs[0]))
+
+		txn, uxb, err := Create(p, auxs, headTime)
+		require.NoError(t, err)
+		require.Equal(t, uxb, inputs[0])
+		require.Equal(t, txn.In, txns[0].In)
+		require.Equal(t, txn.Out, txns[0].Out)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		p := newParams(25e6)
+		maxSize := signedSize(8, 1)
+		txns, inputs, err := CreateSplit(p, auxs, headTime, maxSize, sign)
+		require.NoError(t, err)
+		require.Len(t, txns, len(inputs))
+
+		// 25 or 26 inputs are chosen, which need 4 consolidation transactions of at most 8 inputs
+		require.Len(t, txns, 5)
+
+		payment := txns[len(txns)-1]
+		paymentInputs := inputs[len(inputs)-1]
+		require.NoError(t, VerifyCreatedInvariants(p, &payment, paymentInputs))
+		require.Equal(t, destAddr, payment.Out[0].Address)
+		require.Equal(t, uint64(25e6), payment.Out[0].Coins)
+		require.Len(t, payment.In, len(txns)-1)
+
+		spent := make(map[cipher.SHA256]struct{})
+		var consolidatedCoins uint64
+		for i, txn := range txns {
+			size, err := txn.Size()
+			require.NoError(t, err)
+			require.True(t, size <= maxSize)
+			require.True(t, txn.IsFullySigned())
+			require.NoError(t, txn.Verify())
+
+			for _, h := range txn.In {
+				_, ok := spent[h]
+				require.False(t, ok)
+				spent[h] = struct{}{}
+			}
+
+			if i == len(txns)-1 {
+				break
+			}
+
+			// Each consolidation transaction sends all of its coins and the hours not burned for the fee
+			// to the first address of its inputs, which is spent by the payment transaction
+			require.Len(t, txn.Out, 1)
+
+			var coins, hours uint64
+			addr := inputs[i][0].Address
+			for _, in := range inputs[i] {
+				coins += in.Coins
+				hours += in.Hours
+				if string(in.Address.Bytes()) < string(addr.Bytes()) {
+					addr = in.Address
+				}
+			}
+			require.Equal(t, addr, txn.Out[0].Address)
+			require.Equal(t, coins, txn.Out[0].Coins)
+			require.Equal(t, fee.RemainingHours(hours, params.UserVerifyTxn.BurnFactor), txn.Out[0].Hours)
+			consolidatedCoins += coins
+
+			ux, err := coin.CreateUnspent(coin.BlockHeader{BkSeq: 1, Time: headTime}, txn, 0)
+			require.NoError(t, err)
+			require.Contains(t, payment.In, ux.Hash())
+
+			for _, in := range paymentInputs {
+				if in.Hash == ux.Hash() {
+					require.Equal(t, txn.Out[0].Coins, in.Coins)
+					require.Equal(t, txn.Out[0].Hours, in.Hours)
+				}
+			}
+		}
+
+		// The payment spends all of the consolidated coins
+		var paymentCoins uint64
+		for _, o := range payment.Out {
+			paymentCoins += o.Coins
+		}
+		require.Equal(t, consolidatedCoins, paymentCoins)
+	})
+
+	t.Run("chained split", func(t *testing.T) {
+		// Only one output has coin hours, so the consolidation transactions are chained to pay their fees
+		chainAuxs := make(coin.AddressUxOuts)
+		for i := 0; i < 30; i++ {
+			s := secKeys[i%len(secKeys)]
+			var hours uint64
+			if i == 0 {
+				hours = 1000
+			}
+			ux := makeUxOut(t, s, 1e6, hours)
+			chainAuxs[ux.Body.Address] = append(chainAuxs[ux.Body.Address], ux)
+		}
+
+		p := newParams(25e6)
+		maxSize := signedSize(8, 1)
+		txns, inputs, err := CreateSplit(p, chainAuxs, headTime, maxSize, sign)
+		require.NoError(t, err)
+		require.Len(t, txns, len(inputs))
+
+		// 25 or 26 inputs are chosen, the first consolidation transaction spends 8 of them
+		// and the others spend 7 of them and the output of the previous consolidation transaction
+		require.Len(t, txns, 5)
+
+		payment := txns[len(txns)-1]
+		paymentInputs := inputs[len(inputs)-1]
+		require.NoError(t, VerifyCreatedInvariants(p, &payment, paymentInputs))
+		require.Equal(t, uint64(25e6), payment.Out[0].Coins)
+		require.Len(t, payment.In, 1)
+
+		spent := make(map[cipher.SHA256]struct{})
+		for i, txn := range txns {
+			size, err := txn.Size()
+			require.NoError(t, err)
+			require.True(t, size <= maxSize)
+			require.True(t, txn.IsFullySigned())
+			require.NoError(t, txn.Verify())
+
+			for _, h := range txn.In {
+				_, ok := spent[h]
+				require.False(t, ok)
+				spent[h] = struct{}{}
+			}
+
+			if i == len(txns)-1 {
+				break
+			}
+
+			var hours uint64
+			for _, in := range inputs[i] {
+				hours += in.Hours
+			}
+			require.NotEqual(t, uint64(0), hours)
+			require.Equal(t, fee.RemainingHours(hours, params.UserVerifyTxn.BurnFactor), txn.Out[0].Hours)
+
+			// The output is spent by the next transaction
+			ux, err := coin.CreateUnspent(coin.BlockHeader{BkSeq: 1, Time: headTime}, txn, 0)
+			require.NoError(t, err)
+			require.Equal(t, ux.Hash(), txns[i+1].In[0])
+			require.Equal(t, txn.Out[0].Hours, inputs[i+1][0].Hours)
+		}
+	})
+
+	t.Run("too many inputs for a consolidation", func(t *testing.T) {
+		_, _, err := CreateSplit(newParams(25e6), auxs, headTime, signedSize(1, 1), sign)
+		require.Equal(t, ErrSplitTooLarge, err)
+	})
+
+	t.Run("too many consolidations for the payment", func(t *testing.T) {
+		_, _, err := CreateSplit(newParams(25e6), auxs, headTime, signedSize(3, 1), sign)
+		require.Equal(t, ErrSplitTooLarge, err)
+	})
+
+	t.Run("create error", func(t *testing.T) {
+		_, _, err := CreateSplit(newParams(100e6), auxs, headTime, signedSize(8, 1), sign)
+		require.Equal(t, ErrInsufficientBalance, err)
+	})
+
+	t.Run("sign error", func(t *testing.T) {
+		signErr := errors.New("sign failed")
+		_, _, err := CreateSplit(newParams(25e6), auxs, headTime, signedSize(8, 1), func(*coin.Transaction, []UxBalance) error {
+			return signErr
+		})
+		require.Equal(t, signErr, err)
+	})
+}
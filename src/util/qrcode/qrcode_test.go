@@ -0,0 +1,226 @@
+package qrcode
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// Symbol 1-M encoding "01234567" in numeric mode, from ISO/IEC 18004 Annex I
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	ecc := reedSolomonRemainder(data, reedSolomonDivisor(eccCodewordsPerBlock[1]))
+	require.Equal(t, []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}, ecc)
+}
+
+func TestNumRawDataModules(t *testing.T) {
+	// The total number of codewords of each version, from ISO/IEC 18004 Table 1
+	cases := map[int]int{
+		1:  26,
+		2:  44,
+		7:  196,
+		10: 346,
+		14: 581,
+		21: 1156,
+		32: 2465,
+		33: 2611,
+		40: 3706,
+	}
+
+	for version, codewords := range cases {
+		require.Equal(t, codewords, numRawDataModules(version)/8, "version %d", version)
+	}
+
+	for version := minVersion; version <= maxVersion; version++ {
+		// Each block has data codewords besides its error correction codewords
+		numBlocks := numErrorCorrectionBlocks[version]
+		require.True(t, numRawDataModules(version)/8/numBlocks > eccCodewordsPerBlock[version])
+	}
+}
+
+func TestAlignmentPatternPositions(t *testing.T) {
+	require.Empty(t, alignmentPatternPositions(1))
+	require.Equal(t, []int{6, 18}, alignmentPatternPositions(2))
+	require.Equal(t, []int{6, 22, 38}, alignmentPatternPositions(7))
+	require.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPatternPositions(32))
+	require.Equal(t, []int{6, 30, 58, 86, 114, 142, 170}, alignmentPatternPositions(40))
+}
+
+func TestEncode(t *testing.T) {
+	cases := []struct {
+		n       int
+		version int
+	}{
+		{0, 1},
+		{14, 1},
+		{15, 2},
+		{26, 2},
+		{213, 10},
+		{214, 11},
+		{2331, 40},
+	}
+
+	for _, tc := range cases {
+		c, err := Encode(bytes.Repeat([]byte{'a'}, tc.n))
+		require.NoError(t, err)
+		require.Equal(t, tc.version, c.Version, "%d bytes", tc.n)
+		require.Equal(t, tc.version*4+17, c.Size)
+		require.True(t, c.Mask >= 0 && c.Mask < 8)
+
+		// Finder patterns
+		for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+			x, y := corner[0], corner[1]
+			require.True(t, c.Black(x, y))
+			require.True(t, c.Black(x+6, y+6))
+			require.False(t, c.Black(x+1, y+1))
+			require.True(t, c.Black(x+3, y+3))
+		}
+
+		// Timing patterns
+		for i := 8; i < c.Size-8; i++ {
+			require.Equal(t, i%2 == 0, c.Black(6, i))
+			require.Equal(t, i%2 == 0, c.Black(i, 6))
+		}
+
+		// Dark module
+		require.True(t, c.Black(8, c.Size-8))
+
+		// Quiet zone
+		require.False(t, c.Black(-1, 0))
+		require.False(t, c.Black(c.Size, 0))
+	}
+
+	_, err := Encode(make([]byte, 2332))
+	require.Equal(t, ErrDataTooLong, err)
+}
+
+func TestEncodeFormatBits(t *testing.T) {
+	c, err := Encode([]byte("2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv"))
+	require.NoError(t, err)
+
+	// Both copies of the format information are the same and encode level M and the mask
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= boolToInt(c.Black(8, i)) << uint(i)
+	}
+	first |= boolToInt(c.Black(8, 7)) << 6
+	first |= boolToInt(c.Black(8, 8)) << 7
+	first |= boolToInt(c.Black(7, 8)) << 8
+	for i := 9; i < 15; i++ {
+		first |= boolToInt(c.Black(14-i, 8)) << uint(i)
+	}
+	for i := 0; i < 8; i++ {
+		second |= boolToInt(c.Black(c.Size-1-i, 8)) << uint(i)
+	}
+	for i := 8; i < 15; i++ {
+		second |= boolToInt(c.Black(8, c.Size-15+i)) << uint(i)
+	}
+
+	require.Equal(t, first, second)
+	format := (first ^ 0x5412) >> 10
+	require.Equal(t, formatBitsM, format>>3)
+	require.Equal(t, c.Mask, format&7)
+}
+
+func TestEncodeGolden(t *testing.T) {
+	// The golden files are the modules of the symbols encoded by the reference encoder rsc.io/qr/coding
+	// with level M, in byte mode, with the same version and mask, as rows of '#' (dark) and '.' (light).
+	// The mask is the one with the lowest penalty score according to ISO/IEC 18004, which was checked
+	// separately for each symbol.
+	cases := []struct {
+		goldenFile string
+		data       string
+		version    int
+		mask       int
+	}{
+		{
+			goldenFile: "skycoin-1.golden",
+			data:       "skycoin",
+			version:    1,
+			mask:       2,
+		},
+		{
+			goldenFile: "address-3.golden",
+			data:       "2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv",
+			version:    3,
+			mask:       4,
+		},
+		{
+			goldenFile: "uri-5.golden",
+			data:       "skycoin:2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv?amount=123.456&label=cold storage wallet",
+			version:    5,
+			mask:       2,
+		},
+		{
+			// Versions 7 and up have version information
+			goldenFile: "encrypted-secret-8.golden",
+			data:       "skyenc1:scrypt-chacha20poly1305:dGhpcyBpcyBub3QgYSByZWFsIGVuY3J5cHRlZCBzZWNyZXQsIGp1c3QgdGVzdCBkYXRhIGZvciB0aGUgcXIgY29kZSBnb2xkZW4gdmVjdG9ycw==",
+			version:    8,
+			mask:       2,
+		},
+		{
+			// Blocks of two different lengths are interleaved
+			goldenFile: "repeated-16.golden",
+			data:       strings.Repeat("skycoin paper wallet ", 20),
+			version:    16,
+			mask:       2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.goldenFile, func(t *testing.T) {
+			golden, err := ioutil.ReadFile(filepath.Join("testdata", tc.goldenFile))
+			require.NoError(t, err)
+
+			c, err := Encode([]byte(tc.data))
+			require.NoError(t, err)
+			require.Equal(t, tc.version, c.Version)
+			require.Equal(t, tc.mask, c.Mask)
+
+			var b strings.Builder
+			for y := 0; y < c.Size; y++ {
+				for x := 0; x < c.Size; x++ {
+					if c.Black(x, y) {
+						b.WriteByte('#')
+					} else {
+						b.WriteByte('.')
+					}
+				}
+				b.WriteByte('\n')
+			}
+
+			require.Equal(t, string(golden), b.String())
+		})
+	}
+}
+
+func TestSVG(t *testing.T) {
+	c, err := Encode([]byte("skycoin"))
+	require.NoError(t, err)
+
+	svg := c.SVG(200)
+	require.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="200" viewBox="0 0 29 29"`))
+	require.True(t, strings.HasSuffix(svg, "</svg>"))
+
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				dark++
+			}
+		}
+	}
+	require.Equal(t, dark, strings.Count(svg, "h1v1h-1z"))
+	require.Contains(t, svg, "M4,4h1v1h-1z")
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
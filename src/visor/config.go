@@ -25,6 +25,10 @@ type Config struct {
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum size of a block, in bytes for creating blocks
 	MaxBlockTransactionsSize uint32
+	// Allow unconfirmed transactions to spend the outputs of other unconfirmed transactions.
+	// A block can only spend outputs confirmed before it, so such a transaction
+	// is included in a block after the transactions whose outputs it spends.
+	SpendUnconfirmed bool
 
 	// Where the blockchain is saved
 	BlockchainFile string
@@ -31,6 +31,14 @@ var (
 // Tx wraps a Tx
 type Tx struct {
 	*bolt.Tx
+
+	rollbackHandlers []func()
+}
+
+// OnRollback adds a handler function to be executed after an Update transaction is rolled back,
+// the counterpart of OnCommit. Handlers are not executed for View transactions.
+func (tx *Tx) OnRollback(fn func()) {
+	tx.rollbackHandlers = append(tx.rollbackHandlers, fn)
 }
 
 // String is implemented to prevent a panic when mocking methods with *Tx arguments.
@@ -89,7 +97,7 @@ func (db *DB) View(name string, f func(*Tx) error) error {
 	t0 := time.Now()
 
 	err := db.DB.View(func(tx *bolt.Tx) error {
-		return f(&Tx{tx})
+		return f(&Tx{Tx: tx})
 	})
 
 	t1 := time.Now()
@@ -116,10 +124,21 @@ func (db *DB) Update(name string, f func(*Tx) error) error {
 
 	t0 := time.Now()
 
+	var rollbackHandlers []func()
 	err := db.DB.Update(func(tx *bolt.Tx) error {
-		return f(&Tx{tx})
+		t := &Tx{Tx: tx}
+		err := f(t)
+		rollbackHandlers = t.rollbackHandlers
+		return err
 	})
 
+	// The transaction was rolled back if it returned an error or failed to commit
+	if err != nil {
+		for _, fn := range rollbackHandlers {
+			fn()
+		}
+	}
+
 	t1 := time.Now()
 	delta := t1.Sub(t0)
 	if db.DurationLog && delta > db.DurationReportingThreshold {
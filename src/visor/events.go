@@ -0,0 +1,288 @@
+package visor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// DefaultEventBufferSize is the number of events buffered by a Subscription created with a bufferSize of 0
+const DefaultEventBufferSize = 100
+
+// EventType is the type of an Event
+type EventType string
+
+const (
+	// EventIncomingUnconfirmed is the event of an output received by an address in a transaction added to the unconfirmed pool
+	EventIncomingUnconfirmed EventType = "incoming_unconfirmed"
+	// EventConfirmed is the event of an output received by an address in a transaction executed in a block
+	EventConfirmed EventType = "confirmed"
+	// EventSpent is the event of an output of an address spent by a transaction executed in a block
+	EventSpent EventType = "spent"
+)
+
+// Event is a change of the outputs of an address, published by the visor once the database transaction
+// which caused it is committed
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Address is the owner of the output
+	Address cipher.Address
+	// Wallets are the IDs of the loaded wallets which contain the address
+	Wallets []string
+	// Txid is the transaction which created or spent the output
+	Txid cipher.SHA256
+	// UxID is the hash of the output
+	UxID  cipher.SHA256
+	Coins uint64
+	Hours uint64
+	// BkSeq is the sequence of the block which executed the transaction, 0 for unconfirmed transactions
+	BkSeq uint64
+}
+
+// EventFilter selects the events delivered to a Subscription.
+// An event matches if its address is one of Addresses or it belongs to one of Wallets.
+// An empty filter matches all events.
+type EventFilter struct {
+	Addresses []cipher.Address
+	Wallets   []string
+}
+
+func (f EventFilter) empty() bool {
+	return len(f.Addresses) == 0 && len(f.Wallets) == 0
+}
+
+// Subscription receives the events of an EventBus which match its filter
+type Subscription struct {
+	bus       *EventBus
+	events    chan Event
+	addresses map[cipher.Address]struct{}
+	wallets   map[string]struct{}
+	matchAll  bool
+	dropped   uint64
+	closed    bool
+}
+
+// Events returns the channel of the subscription's events. It is closed when the subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events not delivered because the subscription's buffer was full
+func (s *Subscription) Dropped() uint64 {
+	s.bus.Lock()
+	defer s.bus.Unlock()
+	return s.dropped
+}
+
+// Close unsubscribes and closes the events channel. It is safe to call Close more than once.
+func (s *Subscription) Close() {
+	s.bus.Lock()
+	defer s.bus.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(s.bus.subscriptions, s)
+	close(s.events)
+}
+
+func (s *Subscription) match(e Event) bool {
+	if s.matchAll {
+		return true
+	}
+
+	if _, ok := s.addresses[e.Address]; ok {
+		return true
+	}
+
+	for _, w := range e.Wallets {
+		if _, ok := s.wallets[w]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EventBus delivers the events published by the visor to their subscribers.
+// Publishing never blocks: a subscriber which does not keep up loses events, which are counted by Subscription.Dropped.
+type EventBus struct {
+	sync.Mutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewEventBus creates an EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe creates a Subscription receiving the events which match filter.
+// bufferSize is the number of events buffered for the subscriber, DefaultEventBufferSize if 0.
+func (b *EventBus) Subscribe(filter EventFilter, bufferSize int) *Subscription {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+
+	s := &Subscription{
+		bus:       b,
+		events:    make(chan Event, bufferSize),
+		addresses: make(map[cipher.Address]struct{}, len(filter.Addresses)),
+		wallets:   make(map[string]struct{}, len(filter.Wallets)),
+		matchAll:  filter.empty(),
+	}
+
+	for _, a := range filter.Addresses {
+		s.addresses[a] = struct{}{}
+	}
+	for _, w := range filter.Wallets {
+		s.wallets[w] = struct{}{}
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	b.subscriptions[s] = struct{}{}
+
+	return s
+}
+
+// HasSubscribers returns true if the bus has any subscription.
+// It is safe to call on a nil EventBus.
+func (b *EventBus) HasSubscribers() bool {
+	if b == nil {
+		return false
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	return len(b.subscriptions) > 0
+}
+
+// Publish delivers the events to the matching subscriptions, without blocking
+func (b *EventBus) Publish(events []Event) {
+	b.Lock()
+	defer b.Unlock()
+
+	for s := range b.subscriptions {
+		for _, e := range events {
+			if !s.match(e) {
+				continue
+			}
+
+			select {
+			case s.events <- e:
+			default:
+				s.dropped++
+			}
+		}
+	}
+}
+
+// uxOutEvents creates the events of outputs created or spent by a transaction
+func uxOutEvents(eventType EventType, txnHash cipher.SHA256, uxOuts coin.UxArray, bkSeq uint64) []Event {
+	events := make([]Event, len(uxOuts))
+	for i, ux := range uxOuts {
+		events[i] = Event{
+			Type:    eventType,
+			Address: ux.Body.Address,
+			Txid:    txnHash,
+			UxID:    ux.Hash(),
+			Coins:   ux.Body.Coins,
+			Hours:   ux.Body.Hours,
+			BkSeq:   bkSeq,
+		}
+	}
+	return events
+}
+
+// blockEvents creates the events of the outputs spent and created by a block, before it is executed
+func (vs *Visor) blockEvents(tx *dbutil.Tx, b coin.Block) ([]Event, error) {
+	// Outputs created by a transaction of the block may be spent by a later transaction of the block
+	created := make(map[cipher.SHA256]coin.UxOut)
+
+	var events []Event
+	for _, txn := range b.Body.Transactions {
+		txnHash := txn.Hash()
+
+		inputs := make(coin.UxArray, 0, len(txn.In))
+		for _, h := range txn.In {
+			if ux, ok := created[h]; ok {
+				inputs = append(inputs, ux)
+				continue
+			}
+
+			ux, err := vs.blockchain.Unspent().Get(tx, h)
+			if err != nil {
+				return nil, err
+			}
+			// A missing input fails the execution of the block
+			if ux != nil {
+				inputs = append(inputs, *ux)
+			}
+		}
+
+		outputs := coin.CreateUnspents(b.Head, txn)
+		for _, ux := range outputs {
+			created[ux.Hash()] = ux
+		}
+
+		events = append(events, uxOutEvents(EventSpent, txnHash, inputs, b.Head.BkSeq)...)
+		events = append(events, uxOutEvents(EventConfirmed, txnHash, outputs, b.Head.BkSeq)...)
+	}
+
+	return events, nil
+}
+
+// unconfirmedTxnEvents creates the events of the outputs of a transaction added to the unconfirmed pool
+func (vs *Visor) unconfirmedTxnEvents(tx *dbutil.Tx, txn coin.Transaction) ([]Event, error) {
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return uxOutEvents(EventIncomingUnconfirmed, txn.Hash(), coin.CreateUnspents(head.Head, txn), 0), nil
+}
+
+// SubscribeEvents subscribes to the events of the addresses and wallets of filter.
+// The subscription must be closed when no longer used. It is used by the GET /api/v2/events endpoint.
+func (vs *Visor) SubscribeEvents(filter EventFilter, bufferSize int) *Subscription {
+	return vs.events.Subscribe(filter, bufferSize)
+}
+
+// publishEventsOnCommit publishes the events once tx is committed.
+// Events of a database transaction rolled back are discarded.
+func (vs *Visor) publishEventsOnCommit(tx *dbutil.Tx, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	tx.OnCommit(func() {
+		vs.publishEvents(events)
+	})
+}
+
+func (vs *Visor) publishEvents(events []Event) {
+	now := time.Now().UTC()
+
+	var addrWallets map[cipher.Address][]string
+	if vs.wallets != nil {
+		addrs := make([]cipher.Address, len(events))
+		for i, e := range events {
+			addrs[i] = e.Address
+		}
+		addrWallets = vs.wallets.AddressWallets(addrs)
+	}
+
+	for i := range events {
+		events[i].Time = now
+		events[i].Wallets = addrWallets[events[i].Address]
+	}
+
+	vs.events.Publish(events)
+}
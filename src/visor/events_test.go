@@ -0,0 +1,258 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func receiveEvents(t *testing.T, s *Subscription) []Event {
+	var events []Event
+	for {
+		select {
+		case e, ok := <-s.Events():
+			require.True(t, ok)
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventBus(t *testing.T) {
+	addrA := testutil.MakeAddress()
+	addrB := testutil.MakeAddress()
+
+	b := NewEventBus()
+	require.False(t, b.HasSubscribers())
+
+	var nilBus *EventBus
+	require.False(t, nilBus.HasSubscribers())
+
+	all := b.Subscribe(EventFilter{}, 0)
+	byAddr := b.Subscribe(EventFilter{
+		Addresses: []cipher.Address{addrA},
+	}, 0)
+	byWallet := b.Subscribe(EventFilter{
+		Wallets: []string{"b.wlt"},
+	}, 0)
+	small := b.Subscribe(EventFilter{}, 1)
+	require.True(t, b.HasSubscribers())
+
+	events := []Event{
+		{
+			Type:    EventConfirmed,
+			Address: addrA,
+		},
+		{
+			Type:    EventSpent,
+			Address: addrB,
+			Wallets: []string{"a.wlt", "b.wlt"},
+		},
+	}
+	b.Publish(events)
+
+	require.Equal(t, events, receiveEvents(t, all))
+	require.Equal(t, events[:1], receiveEvents(t, byAddr))
+	require.Equal(t, events[1:], receiveEvents(t, byWallet))
+
+	// Events are dropped when a subscription's buffer is full
+	require.Equal(t, events[:1], receiveEvents(t, small))
+	require.Equal(t, uint64(1), small.Dropped())
+	require.Equal(t, uint64(0), all.Dropped())
+
+	// Closed subscriptions don't receive events
+	byAddr.Close()
+	byAddr.Close()
+	_, ok := <-byAddr.Events()
+	require.False(t, ok)
+
+	b.Publish(events)
+	require.Equal(t, events, receiveEvents(t, all))
+
+	all.Close()
+	byWallet.Close()
+	small.Close()
+	require.False(t, b.HasSubscribers())
+}
+
+func TestVisorEvents(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	require.NoError(t, err)
+
+	ws, err := wallet.NewService(wallet.Config{
+		EnableWalletAPI: true,
+		CryptoType:      wallet.CryptoTypeScryptChacha20poly1305Insecure,
+		WalletDir:       prepareWltDir(),
+	})
+	require.NoError(t, err)
+
+	w, err := ws.CreateWallet("a.wlt", wallet.Options{
+		Coin: wallet.CoinTypeSkycoin,
+		Seed: "foo",
+	}, nil)
+	require.NoError(t, err)
+	wltAddr := w.Entries[0].SkycoinAddress()
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		wallets:     ws,
+		events:      NewEventBus(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	all := v.SubscribeEvents(EventFilter{}, 0)
+	defer all.Close()
+	byWallet := v.SubscribeEvents(EventFilter{
+		Wallets: []string{"a.wlt"},
+	}, 0)
+	defer byWallet.Close()
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, wltAddr, 10e6)
+
+	// A transaction added to the unconfirmed pool creates incoming events for its outputs
+	known, softErr, err := v.InjectForeignTransaction(txn)
+	require.False(t, known)
+	require.Nil(t, softErr)
+	require.NoError(t, err)
+
+	events := receiveEvents(t, all)
+	require.Len(t, events, 2)
+	for i, e := range events {
+		require.Equal(t, EventIncomingUnconfirmed, e.Type)
+		require.Equal(t, txn.Hash(), e.Txid)
+		require.Equal(t, txn.Out[i].Address, e.Address)
+		require.Equal(t, txn.Out[i].Coins, e.Coins)
+		require.Equal(t, txn.Out[i].Hours, e.Hours)
+		require.Equal(t, uint64(0), e.BkSeq)
+		require.False(t, e.Time.IsZero())
+	}
+	require.Equal(t, []string{"a.wlt"}, events[0].Wallets)
+	require.Empty(t, events[1].Wallets)
+
+	walletEvents := receiveEvents(t, byWallet)
+	require.Equal(t, events[:1], walletEvents)
+
+	// A transaction already in the pool creates no events
+	known, _, err = v.InjectForeignTransaction(txn)
+	require.True(t, known)
+	require.NoError(t, err)
+	require.Empty(t, receiveEvents(t, all))
+
+	// A block creates spent events for the inputs and confirmed events for the outputs of its transactions
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), sb.Head.BkSeq)
+
+	events = receiveEvents(t, all)
+	require.Len(t, events, 3)
+
+	require.Equal(t, EventSpent, events[0].Type)
+	require.Equal(t, genAddress, events[0].Address)
+	require.Equal(t, uxs[0].Hash(), events[0].UxID)
+	require.Equal(t, uxs[0].Body.Coins, events[0].Coins)
+
+	outputs := coin.CreateUnspents(sb.Head, txn)
+	for i, e := range events[1:] {
+		require.Equal(t, EventConfirmed, e.Type)
+		require.Equal(t, txn.Hash(), e.Txid)
+		require.Equal(t, outputs[i].Hash(), e.UxID)
+		require.Equal(t, outputs[i].Body.Address, e.Address)
+		require.Equal(t, uint64(1), e.BkSeq)
+	}
+	require.Equal(t, []string{"a.wlt"}, events[1].Wallets)
+
+	walletEvents = receiveEvents(t, byWallet)
+	require.Equal(t, events[1:2], walletEvents)
+
+	// The incoming unconfirmed output is the confirmed output
+	require.Equal(t, outputs[0].Hash(), walletEvents[0].UxID)
+
+	// A user transaction creates incoming events
+	txn = makeSpendTxn(t, coin.UxArray{outputs[1]}, []cipher.SecKey{genSecret}, wltAddr, 1e6)
+	known, _, _, err = v.InjectUserTransaction(txn)
+	require.False(t, known)
+	require.NoError(t, err)
+
+	events = receiveEvents(t, all)
+	require.Len(t, events, 2)
+	require.Equal(t, EventIncomingUnconfirmed, events[0].Type)
+	require.Equal(t, wltAddr, events[0].Address)
+}
+
+func TestVisorEventsRolledBack(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	require.NoError(t, err)
+
+	ws, err := wallet.NewService(wallet.Config{
+		WalletDir: prepareWltDir(),
+	})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		wallets:     ws,
+		events:      NewEventBus(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	all := v.SubscribeEvents(EventFilter{}, 0)
+	defer all.Close()
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+
+	// Events of an aborted database transaction are not published
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		_, _, _, err := v.InjectUserTransactionTx(tx, txn)
+		require.NoError(t, err)
+		return errors.New("rollback")
+	})
+	require.Error(t, err)
+	require.Empty(t, receiveEvents(t, all))
+}
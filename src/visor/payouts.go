@@ -0,0 +1,494 @@
+package visor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// PayoutsBkt holds the payout requests queued for the wallets
+	PayoutsBkt = []byte("payouts")
+
+	// ErrPayoutNotExist is returned if a payout does not exist
+	ErrPayoutNotExist = NewUserError(errors.New("payout does not exist"))
+	// ErrPayoutNotPending is returned when canceling a payout which is not pending
+	ErrPayoutNotPending = NewUserError(errors.New("payout is not pending"))
+	// ErrPayoutsRequired is returned when enqueuing no payouts
+	ErrPayoutsRequired = NewUserError(errors.New("payouts are required"))
+	// ErrPayoutWalletEncrypted is returned when enqueuing payouts for an encrypted wallet,
+	// since the node can't sign their transactions without the wallet password
+	ErrPayoutWalletEncrypted = NewUserError(errors.New("payouts can't be sent from an encrypted wallet"))
+	// ErrPayoutTransactionDropped is the error of sent payouts whose transaction left the unconfirmed pool without being confirmed
+	ErrPayoutTransactionDropped = errors.New("payout transaction was removed from the unconfirmed pool without being confirmed")
+)
+
+const (
+	// payoutTxnOverhead is the size of a transaction without inputs and outputs, with the length prefixes of its arrays
+	payoutTxnOverhead = 4 + 1 + 32 + 4 + 4 + 4
+	// payoutTxnInputSize is the size of a transaction input with its signature
+	payoutTxnInputSize = 32 + 65
+	// payoutTxnOutputSize is the size of a transaction output
+	payoutTxnOutputSize = 21 + 8 + 8
+)
+
+// PayoutStatus is the status of a Payout
+type PayoutStatus string
+
+const (
+	// PayoutStatusPending is the status of a payout waiting to be sent
+	PayoutStatusPending PayoutStatus = "pending"
+	// PayoutStatusSent is the status of a payout whose transaction was injected and broadcast
+	PayoutStatusSent PayoutStatus = "sent"
+	// PayoutStatusConfirmed is the status of a payout whose transaction was executed in a block
+	PayoutStatusConfirmed PayoutStatus = "confirmed"
+	// PayoutStatusFailed is the status of a payout whose transaction was dropped. It is not sent again.
+	PayoutStatusFailed PayoutStatus = "failed"
+	// PayoutStatusCanceled is the status of a payout canceled before being sent
+	PayoutStatusCanceled PayoutStatus = "canceled"
+)
+
+// PayoutRequest is a request to send coins to an address from a wallet
+type PayoutRequest struct {
+	Address cipher.Address
+	Coins   uint64
+}
+
+// Payout is a payout request queued for a wallet.
+// Pending payouts of a wallet are periodically packed into transactions with many outputs,
+// which are signed by the wallet and injected.
+type Payout struct {
+	ID       uint64
+	WalletID string
+	Address  cipher.Address
+	Coins    uint64
+	Status   PayoutStatus
+	// Txid is the transaction which sent the payout, set once the payout is sent
+	Txid cipher.SHA256
+	// Error is the error of the last attempt to send a pending payout, or the reason a payout failed
+	Error   string
+	Created time.Time
+	Updated time.Time
+}
+
+// payouts bucket
+type payouts struct{}
+
+func (pb *payouts) get(tx *dbutil.Tx, id uint64) (*Payout, error) {
+	var p Payout
+	ok, err := dbutil.GetBucketObjectJSON(tx, PayoutsBkt, dbutil.Itob(id), &p)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return &p, nil
+}
+
+func (pb *payouts) put(tx *dbutil.Tx, p *Payout) error {
+	v, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	return dbutil.PutBucketValue(tx, PayoutsBkt, dbutil.Itob(p.ID), v)
+}
+
+func (pb *payouts) add(tx *dbutil.Tx, p *Payout) error {
+	id, err := dbutil.NextSequence(tx, PayoutsBkt)
+	if err != nil {
+		return err
+	}
+
+	p.ID = id
+	return pb.put(tx, p)
+}
+
+// forEach iterates over the payouts in the order they were added
+func (pb *payouts) forEach(tx *dbutil.Tx, f func(p *Payout) error) error {
+	return dbutil.ForEach(tx, PayoutsBkt, func(_, v []byte) error {
+		var p Payout
+		if err := json.Unmarshal(v, &p); err != nil {
+			return err
+		}
+
+		return f(&p)
+	})
+}
+
+func (pb *payouts) getFiltered(tx *dbutil.Tx, wltID string, statuses []PayoutStatus) ([]Payout, error) {
+	var ps []Payout
+	if err := pb.forEach(tx, func(p *Payout) error {
+		if wltID != "" && p.WalletID != wltID {
+			return nil
+		}
+
+		if len(statuses) != 0 && !hasPayoutStatus(statuses, p.Status) {
+			return nil
+		}
+
+		ps = append(ps, *p)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func hasPayoutStatus(statuses []PayoutStatus, s PayoutStatus) bool {
+	for _, x := range statuses {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// EnqueuePayouts adds payout requests to the payout queue of a wallet. The wallet must not be encrypted.
+func (vs *Visor) EnqueuePayouts(wltID string, reqs []PayoutRequest) ([]Payout, error) {
+	if len(reqs) == 0 {
+		return nil, ErrPayoutsRequired
+	}
+
+	for _, r := range reqs {
+		if r.Address.Null() {
+			return nil, ErrIncludesNullAddress
+		}
+
+		if r.Coins == 0 {
+			return nil, NewUserError(transaction.ErrZeroCoinsReceiver)
+		}
+
+		if err := params.DropletPrecisionCheck(params.UserVerifyTxn.MaxDropletPrecision, r.Coins); err != nil {
+			return nil, NewUserError(err)
+		}
+	}
+
+	w, err := vs.wallets.GetWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.IsEncrypted() {
+		return nil, ErrPayoutWalletEncrypted
+	}
+
+	now := time.Now().UTC()
+	ps := make([]Payout, len(reqs))
+	for i, r := range reqs {
+		ps[i] = Payout{
+			WalletID: wltID,
+			Address:  r.Address,
+			Coins:    r.Coins,
+			Status:   PayoutStatusPending,
+			Created:  now,
+			Updated:  now,
+		}
+	}
+
+	if err := vs.db.Update("EnqueuePayouts", func(tx *dbutil.Tx) error {
+		for i := range ps {
+			if err := vs.payouts.add(tx, &ps[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// GetPayouts returns the payouts of a wallet, in the order they were enqueued.
+// If statuses are specified, only the payouts with one of these statuses are returned.
+func (vs *Visor) GetPayouts(wltID string, statuses []PayoutStatus) ([]Payout, error) {
+	if _, err := vs.wallets.GetWallet(wltID); err != nil {
+		return nil, err
+	}
+
+	var ps []Payout
+	if err := vs.db.View("GetPayouts", func(tx *dbutil.Tx) error {
+		var err error
+		ps, err = vs.payouts.getFiltered(tx, wltID, statuses)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// CancelPayouts cancels pending payouts of a wallet. No payout is canceled if any of them is not pending.
+func (vs *Visor) CancelPayouts(wltID string, ids []uint64) ([]Payout, error) {
+	if _, err := vs.wallets.GetWallet(wltID); err != nil {
+		return nil, err
+	}
+
+	ps := make([]Payout, len(ids))
+	if err := vs.db.Update("CancelPayouts", func(tx *dbutil.Tx) error {
+		now := time.Now().UTC()
+		for i, id := range ids {
+			p, err := vs.payouts.get(tx, id)
+			if err != nil {
+				return err
+			}
+
+			if p == nil || p.WalletID != wltID {
+				return ErrPayoutNotExist
+			}
+
+			if p.Status != PayoutStatusPending {
+				return ErrPayoutNotPending
+			}
+
+			p.Status = PayoutStatusCanceled
+			p.Updated = now
+			if err := vs.payouts.put(tx, p); err != nil {
+				return err
+			}
+
+			ps[i] = *p
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// HasQueuedPayouts returns true if any payout is pending, or sent and waiting for its transaction to be confirmed
+func (vs *Visor) HasQueuedPayouts() (bool, error) {
+	var queued bool
+	if err := vs.db.View("HasQueuedPayouts", func(tx *dbutil.Tx) error {
+		ps, err := vs.payouts.getFiltered(tx, "", []PayoutStatus{PayoutStatusPending, PayoutStatusSent})
+		if err != nil {
+			return err
+		}
+		queued = len(ps) != 0
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return queued, nil
+}
+
+// PendingPayoutWallets returns the IDs of the wallets which have pending payouts
+func (vs *Visor) PendingPayoutWallets() ([]string, error) {
+	var wltIDs []string
+	if err := vs.db.View("PendingPayoutWallets", func(tx *dbutil.Tx) error {
+		seen := make(map[string]struct{})
+		return vs.payouts.forEach(tx, func(p *Payout) error {
+			if p.Status != PayoutStatusPending {
+				return nil
+			}
+
+			if _, ok := seen[p.WalletID]; !ok {
+				seen[p.WalletID] = struct{}{}
+				wltIDs = append(wltIDs, p.WalletID)
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return wltIDs, nil
+}
+
+// CreatePayoutTransaction creates a signed transaction sending the oldest pending payouts of a wallet,
+// as many as fit in a transaction of params.UserVerifyTxn.MaxTransactionSize.
+// The IDs of the payouts sent by the transaction are returned.
+// If the wallet has no pending payouts, a nil transaction is returned.
+func (vs *Visor) CreatePayoutTransaction(wltID string) (*coin.Transaction, []uint64, error) {
+	var pending []Payout
+	if err := vs.db.View("CreatePayoutTransaction", func(tx *dbutil.Tx) error {
+		var err error
+		pending, err = vs.payouts.getFiltered(tx, wltID, []PayoutStatus{PayoutStatusPending})
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	if len(pending) == 0 {
+		return nil, nil, nil
+	}
+
+	// Estimate the number of outputs which fit in a transaction, assuming a single input and a change output.
+	// The batch is reduced if the transaction is too large.
+	maxOutputs := (int(params.UserVerifyTxn.MaxTransactionSize) - payoutTxnOverhead - payoutTxnInputSize) / payoutTxnOutputSize
+	maxOutputs--
+
+	// A transaction can't have duplicate outputs, so payouts with the same address and coins
+	// as a payout of the batch are left to a later transaction
+	var batch []Payout
+	outputs := make(map[PayoutRequest]struct{})
+	for _, p := range pending {
+		if len(batch) == maxOutputs {
+			break
+		}
+
+		o := PayoutRequest{
+			Address: p.Address,
+			Coins:   p.Coins,
+		}
+		if _, ok := outputs[o]; ok {
+			continue
+		}
+
+		outputs[o] = struct{}{}
+		batch = append(batch, p)
+	}
+
+	for {
+		to := make([]coin.TransactionOutput, len(batch))
+		ids := make([]uint64, len(batch))
+		for i, p := range batch {
+			to[i] = coin.TransactionOutput{
+				Address: p.Address,
+				Coins:   p.Coins,
+			}
+			ids[i] = p.ID
+		}
+
+		shareFactor := decimal.New(5, -1)
+		txn, _, err := vs.WalletCreateTransactionSigned(wltID, nil, transaction.Params{
+			HoursSelection: transaction.HoursSelection{
+				Type:        transaction.HoursSelectionTypeAuto,
+				Mode:        transaction.HoursSelectionModeShare,
+				ShareFactor: &shareFactor,
+			},
+			To: to,
+		}, CreateTransactionParams{
+			IgnoreUnconfirmed: true,
+		})
+		if err == nil {
+			return txn, ids, nil
+		}
+
+		if !isTxnTooLarge(err) || len(batch) == 1 {
+			return nil, ids, err
+		}
+
+		// Each additional input takes the place of more than two outputs
+		n := len(batch) - len(batch)/10
+		if n == len(batch) {
+			n--
+		}
+		batch = batch[:n]
+	}
+}
+
+func isTxnTooLarge(err error) bool {
+	e, ok := err.(ErrTxnViolatesSoftConstraint)
+	return ok && e.Err == ErrTxnExceedsMaxBlockSize
+}
+
+// SetPayoutsSentTx marks pending payouts as sent by a transaction
+func (vs *Visor) SetPayoutsSentTx(tx *dbutil.Tx, ids []uint64, txid cipher.SHA256) error {
+	now := time.Now().UTC()
+	for _, id := range ids {
+		p, err := vs.payouts.get(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if p == nil {
+			return ErrPayoutNotExist
+		}
+
+		if p.Status != PayoutStatusPending {
+			return fmt.Errorf("payout %d is not pending", id)
+		}
+
+		p.Status = PayoutStatusSent
+		p.Txid = txid
+		p.Error = ""
+		p.Updated = now
+		if err := vs.payouts.put(tx, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetPayoutsError records the error of an attempt to send pending payouts. The payouts remain pending.
+func (vs *Visor) SetPayoutsError(ids []uint64, sendErr error) error {
+	return vs.db.Update("SetPayoutsError", func(tx *dbutil.Tx) error {
+		now := time.Now().UTC()
+		for _, id := range ids {
+			p, err := vs.payouts.get(tx, id)
+			if err != nil {
+				return err
+			}
+
+			if p == nil || p.Status != PayoutStatusPending {
+				continue
+			}
+
+			p.Error = sendErr.Error()
+			p.Updated = now
+			if err := vs.payouts.put(tx, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateSentPayouts marks the sent payouts whose transaction was executed in a block as confirmed,
+// and the sent payouts whose transaction was removed from the unconfirmed pool as failed
+func (vs *Visor) UpdateSentPayouts() error {
+	return vs.db.Update("UpdateSentPayouts", func(tx *dbutil.Tx) error {
+		sent, err := vs.payouts.getFiltered(tx, "", []PayoutStatus{PayoutStatusSent})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for i := range sent {
+			p := &sent[i]
+
+			txn, err := vs.history.GetTransaction(tx, p.Txid)
+			if err != nil {
+				return err
+			}
+
+			if txn != nil {
+				p.Status = PayoutStatusConfirmed
+			} else {
+				utxn, err := vs.unconfirmed.Get(tx, p.Txid)
+				if err != nil {
+					return err
+				}
+				if utxn != nil {
+					continue
+				}
+
+				p.Status = PayoutStatusFailed
+				p.Error = ErrPayoutTransactionDropped.Error()
+			}
+
+			p.Updated = now
+			if err := vs.payouts.put(tx, p); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
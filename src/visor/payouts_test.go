@@ -0,0 +1,283 @@
+package visor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// prepareFundedWalletVisor creates a block publisher visor with a wallet a.wlt, funded by a block
+func prepareFundedWalletVisor(t *testing.T, db *dbutil.DB) (*Visor, cipher.Address) {
+	require.NoError(t, CreateBuckets(db))
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	require.NoError(t, err)
+
+	ws, err := wallet.NewService(wallet.Config{
+		EnableWalletAPI: true,
+		CryptoType:      wallet.CryptoTypeScryptChacha20poly1305Insecure,
+		WalletDir:       prepareWltDir(),
+	})
+	require.NoError(t, err)
+
+	w, err := ws.CreateWallet("a.wlt", wallet.Options{
+		Coin: wallet.CoinTypeSkycoin,
+		Seed: "foo",
+	}, nil)
+	require.NoError(t, err)
+	wltAddr := w.Entries[0].SkycoinAddress()
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		wallets:     ws,
+		payouts:     &payouts{},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, wltAddr, 500e6)
+	_, _, err = v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	_, err = v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+
+	return v, wltAddr
+}
+
+// createAndExecuteBlockAt creates and executes a block at a given time,
+// which must be later than the head block's time
+func createAndExecuteBlockAt(t *testing.T, v *Visor, when uint64) coin.SignedBlock {
+	var sb coin.SignedBlock
+	err := v.db.Update("", func(tx *dbutil.Tx) error {
+		var err error
+		sb, err = v.createBlock(tx, when)
+		if err != nil {
+			return err
+		}
+		return v.executeSignedBlock(tx, sb)
+	})
+	require.NoError(t, err)
+	return sb
+}
+
+func payoutIDs(ps []Payout) []uint64 {
+	ids := make([]uint64, len(ps))
+	for i, p := range ps {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestVisorPayouts(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v, _ := prepareFundedWalletVisor(t, db)
+
+	addr := testutil.MakeAddress()
+
+	// Invalid requests are rejected
+	_, err := v.EnqueuePayouts("a.wlt", nil)
+	require.Equal(t, ErrPayoutsRequired, err)
+
+	_, err = v.EnqueuePayouts("a.wlt", []PayoutRequest{{Coins: 1e6}})
+	require.Equal(t, ErrIncludesNullAddress, err)
+
+	_, err = v.EnqueuePayouts("a.wlt", []PayoutRequest{{Address: addr}})
+	require.IsType(t, UserError{}, err)
+
+	_, err = v.EnqueuePayouts("a.wlt", []PayoutRequest{{Address: addr, Coins: 1}})
+	require.IsType(t, UserError{}, err)
+
+	_, err = v.EnqueuePayouts("b.wlt", []PayoutRequest{{Address: addr, Coins: 1e6}})
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	queued, err := v.HasQueuedPayouts()
+	require.NoError(t, err)
+	require.False(t, queued)
+
+	// Payouts with the same address and coins are sent in different transactions
+	ps, err := v.EnqueuePayouts("a.wlt", []PayoutRequest{
+		{Address: addr, Coins: 1e6},
+		{Address: addr, Coins: 1e6},
+		{Address: addr, Coins: 2e6},
+		{Address: testutil.MakeAddress(), Coins: 3e6},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3, 4}, payoutIDs(ps))
+	for _, p := range ps {
+		require.Equal(t, PayoutStatusPending, p.Status)
+		require.Equal(t, "a.wlt", p.WalletID)
+	}
+
+	wltIDs, err := v.PendingPayoutWallets()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.wlt"}, wltIDs)
+
+	queued, err = v.HasQueuedPayouts()
+	require.NoError(t, err)
+	require.True(t, queued)
+
+	// Pending payouts can be canceled
+	_, err = v.CancelPayouts("a.wlt", []uint64{4, 5})
+	require.Equal(t, ErrPayoutNotExist, err)
+
+	canceled, err := v.CancelPayouts("a.wlt", []uint64{4})
+	require.NoError(t, err)
+	require.Equal(t, PayoutStatusCanceled, canceled[0].Status)
+
+	_, err = v.CancelPayouts("a.wlt", []uint64{4})
+	require.Equal(t, ErrPayoutNotPending, err)
+
+	txn, ids, err := v.CreatePayoutTransaction("a.wlt")
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 3}, ids)
+	require.Len(t, txn.Out, 3)
+	require.Equal(t, addr, txn.Out[0].Address)
+	require.Equal(t, uint64(1e6), txn.Out[0].Coins)
+	require.Equal(t, addr, txn.Out[1].Address)
+	require.Equal(t, uint64(2e6), txn.Out[1].Coins)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if _, _, _, err := v.InjectUserTransactionTx(tx, *txn); err != nil {
+			return err
+		}
+		return v.SetPayoutsSentTx(tx, ids, txn.Hash())
+	})
+	require.NoError(t, err)
+
+	// The wallet's only output is spent by an unconfirmed transaction
+	_, ids, sendErr := v.CreatePayoutTransaction("a.wlt")
+	require.Error(t, sendErr)
+	require.Equal(t, []uint64{2}, ids)
+	require.NoError(t, v.SetPayoutsError(ids, sendErr))
+
+	ps, err = v.GetPayouts("a.wlt", []PayoutStatus{PayoutStatusPending})
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+	require.Equal(t, uint64(2), ps[0].ID)
+	require.Equal(t, sendErr.Error(), ps[0].Error)
+
+	// Sent payouts are confirmed once their transaction is executed in a block
+	require.NoError(t, v.UpdateSentPayouts())
+	ps, err = v.GetPayouts("a.wlt", []PayoutStatus{PayoutStatusSent})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 3}, payoutIDs(ps))
+	require.Equal(t, txn.Hash(), ps[0].Txid)
+
+	createAndExecuteBlockAt(t, v, uint64(time.Now().UTC().Unix()+10))
+	require.NoError(t, v.UpdateSentPayouts())
+
+	ps, err = v.GetPayouts("a.wlt", nil)
+	require.NoError(t, err)
+	require.Len(t, ps, 4)
+	require.Equal(t, PayoutStatusConfirmed, ps[0].Status)
+	require.Equal(t, PayoutStatusPending, ps[1].Status)
+	require.Equal(t, PayoutStatusConfirmed, ps[2].Status)
+	require.Equal(t, PayoutStatusCanceled, ps[3].Status)
+
+	// Sent payouts whose transaction is dropped from the pool fail
+	txn, ids, err = v.CreatePayoutTransaction("a.wlt")
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, ids)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return v.SetPayoutsSentTx(tx, ids, txn.Hash())
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, v.UpdateSentPayouts())
+	ps, err = v.GetPayouts("a.wlt", []PayoutStatus{PayoutStatusFailed})
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+	require.Equal(t, ErrPayoutTransactionDropped.Error(), ps[0].Error)
+
+	wltIDs, err = v.PendingPayoutWallets()
+	require.NoError(t, err)
+	require.Empty(t, wltIDs)
+
+	queued, err = v.HasQueuedPayouts()
+	require.NoError(t, err)
+	require.False(t, queued)
+
+	txn, ids, err = v.CreatePayoutTransaction("a.wlt")
+	require.NoError(t, err)
+	require.Nil(t, txn)
+	require.Nil(t, ids)
+}
+
+func TestVisorPayoutsMaxTransactionSize(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v, _ := prepareFundedWalletVisor(t, db)
+
+	reqs := make([]PayoutRequest, 1000)
+	for i := range reqs {
+		reqs[i] = PayoutRequest{
+			Address: testutil.MakeAddress(),
+			Coins:   1e3,
+		}
+	}
+
+	_, err := v.EnqueuePayouts("a.wlt", reqs)
+	require.NoError(t, err)
+
+	txn, ids, err := v.CreatePayoutTransaction("a.wlt")
+	require.NoError(t, err)
+	require.True(t, len(ids) < len(reqs))
+	require.Len(t, txn.Out, len(ids)+1)
+
+	size, err := txn.Size()
+	require.NoError(t, err)
+	require.True(t, size <= params.UserVerifyTxn.MaxTransactionSize)
+
+	// The oldest payouts are sent first
+	for i, id := range ids {
+		require.Equal(t, uint64(i+1), id)
+	}
+}
+
+func TestVisorPayoutsEncryptedWallet(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v, _ := prepareFundedWalletVisor(t, db)
+
+	_, err := v.wallets.CreateWallet("b.wlt", wallet.Options{
+		Coin:       wallet.CoinTypeSkycoin,
+		Seed:       "bar",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: wallet.CryptoTypeScryptChacha20poly1305Insecure,
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = v.EnqueuePayouts("b.wlt", []PayoutRequest{{Address: testutil.MakeAddress(), Coins: 1e6}})
+	require.Equal(t, ErrPayoutWalletEncrypted, err)
+}
@@ -0,0 +1,170 @@
+package visor
+
+import (
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// TransactionPreview is an unsigned transaction created by PreviewTransaction,
+// with its coin hour fee and size checked against each set of transaction verification parameters
+type TransactionPreview struct {
+	Transaction coin.Transaction
+	Inputs      []TransactionInput
+	// Change is the change output of the transaction, nil if the transaction has no change
+	Change *coin.TransactionOutput
+	// Fee is the number of coin hours burned by the transaction
+	Fee uint64
+	// Size is the size of the transaction in bytes once signed
+	Size uint32
+	// User is checked against params.UserVerifyTxn, which transactions created by the node must satisfy
+	User VerifyTxnCheck
+	// Unconfirmed is checked against the parameters used to accept transactions into the unconfirmed pool
+	Unconfirmed VerifyTxnCheck
+	// CreateBlock is checked against the parameters used to include transactions in a new block
+	CreateBlock VerifyTxnCheck
+}
+
+// VerifyTxnCheck is the result of checking a transaction against a set of transaction verification parameters
+type VerifyTxnCheck struct {
+	Params params.VerifyTxn
+	// RequiredFee is the minimum number of coin hours the transaction must burn
+	RequiredFee uint64
+	SizeOK      bool
+	FeeOK       bool
+	DecimalsOK  bool
+	// Err is the soft constraint violated by the transaction, nil if it satisfies all of them
+	Err error
+}
+
+// PreviewTransaction creates an unsigned transaction from requested addresses or coin.UxOut hashes like CreateTransaction,
+// and returns it with its fee and size checked against the user, unconfirmed pool and block creation verification parameters.
+// Unlike CreateTransaction, no error is returned if the transaction violates a soft constraint, the violation is
+// reported in the preview instead.
+func (vs *Visor) PreviewTransaction(p transaction.Params, wp CreateTransactionParams) (*TransactionPreview, error) {
+	// Validate parameters before starting database transaction
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if err := wp.Validate(); err != nil {
+		return nil, err
+	}
+	if len(wp.Addresses) == 0 && len(wp.UxOuts) == 0 {
+		return nil, ErrUxOutsOrAddressesRequired
+	}
+
+	var preview *TransactionPreview
+	if err := vs.db.View("PreviewTransaction", func(tx *dbutil.Tx) error {
+		var err error
+		preview, err = vs.previewTransactionTx(tx, p, wp)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
+
+func (vs *Visor) previewTransactionTx(tx *dbutil.Tx, p transaction.Params, wp CreateTransactionParams) (*TransactionPreview, error) {
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		logger.WithError(err).Error("blockchain.Head failed")
+		return nil, err
+	}
+
+	var auxs coin.AddressUxOuts
+	if len(wp.UxOuts) != 0 {
+		auxs, err = vs.getCreateTransactionAuxsUxOut(tx, wp.UxOuts, wp.IgnoreUnconfirmed)
+	} else {
+		auxs, err = vs.getCreateTransactionAuxsAddress(tx, wp.Addresses, wp.IgnoreUnconfirmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	txn, uxb, err := transaction.Create(p, auxs, head.Time())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifySingleTxnUserConstraints(*txn); err != nil {
+		logger.WithError(err).Error("Created transaction violates transaction user constraints")
+		return nil, err
+	}
+
+	outputs, err := vs.unconfirmedOutputs(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyUnconfirmedTxnHardConstraints(tx, vs.blockchain, *txn, outputs, TxnUnsigned); err != nil {
+		logger.WithError(err).Error("Created transaction violates transaction hard constraints")
+		return nil, err
+	}
+
+	uxIn, err := getUnspentsWithUnconfirmed(tx, vs.blockchain, txn.In, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTransactionPreview(*txn, uxb, uxIn, head.Time(), len(p.To), vs.Config)
+}
+
+// newTransactionPreview checks the fee and size of a transaction created by transaction.Create with nTo receivers
+func newTransactionPreview(txn coin.Transaction, uxb []transaction.UxBalance, uxIn coin.UxArray, headTime uint64, nTo int, c Config) (*TransactionPreview, error) {
+	// Transactions are created with null signatures, so the size of the signed transaction is known
+	size, err := txn.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := fee.TransactionFee(&txn, headTime, uxIn)
+	if err != nil {
+		return nil, err
+	}
+
+	inputHours, err := uxIn.CoinHours(headTime)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &TransactionPreview{
+		Transaction: txn,
+		Inputs:      NewTransactionInputsFromUxBalance(uxb),
+		Fee:         f,
+		Size:        size,
+	}
+
+	// transaction.Create appends the change output after the receivers
+	if len(txn.Out) > nTo {
+		change := txn.Out[nTo]
+		preview.Change = &change
+	}
+
+	check := func(verifyParams params.VerifyTxn) VerifyTxnCheck {
+		decimalsOK := true
+		for _, o := range txn.Out {
+			if err := params.DropletPrecisionCheck(verifyParams.MaxDropletPrecision, o.Coins); err != nil {
+				decimalsOK = false
+				break
+			}
+		}
+
+		return VerifyTxnCheck{
+			Params:      verifyParams,
+			RequiredFee: fee.RequiredFee(inputHours, verifyParams.BurnFactor),
+			SizeOK:      size <= verifyParams.MaxTransactionSize,
+			FeeOK:       fee.VerifyTransactionFee(&txn, f, verifyParams.BurnFactor) == nil,
+			DecimalsOK:  decimalsOK,
+			Err:         verifyTxnSoftConstraints(txn, headTime, uxIn, verifyParams),
+		}
+	}
+
+	preview.User = check(params.UserVerifyTxn)
+	preview.Unconfirmed = check(c.UnconfirmedVerifyTxn)
+	preview.CreateBlock = check(c.CreateBlockVerifyTxn)
+
+	return preview, nil
+}
@@ -0,0 +1,106 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+)
+
+func TestVisorPreviewTransaction(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v, wltAddr := prepareFundedWalletVisor(t, db)
+
+	toAddr := testutil.MakeAddress()
+	shareFactor := decimal.New(5, -1)
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type:        transaction.HoursSelectionTypeAuto,
+			Mode:        transaction.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: toAddr,
+				Coins:   1500000,
+			},
+		},
+	}
+
+	_, err := v.PreviewTransaction(p, CreateTransactionParams{})
+	require.Equal(t, ErrUxOutsOrAddressesRequired, err)
+
+	preview, err := v.PreviewTransaction(p, CreateTransactionParams{
+		Addresses: []cipher.Address{wltAddr},
+	})
+	require.NoError(t, err)
+
+	auxs, err := v.GetUnspentsOfAddrs([]cipher.Address{wltAddr})
+	require.NoError(t, err)
+	require.Len(t, auxs[wltAddr], 1)
+	ux := auxs[wltAddr][0]
+
+	txn := preview.Transaction
+	require.Equal(t, []cipher.SHA256{ux.Hash()}, txn.In)
+	require.Len(t, preview.Inputs, 1)
+	require.Equal(t, ux.Hash(), preview.Inputs[0].UxOut.Hash())
+	require.Len(t, txn.Out, 2)
+	require.Equal(t, toAddr, txn.Out[0].Address)
+	require.NotNil(t, preview.Change)
+	require.Equal(t, txn.Out[1], *preview.Change)
+	require.Equal(t, wltAddr, preview.Change.Address)
+	require.Equal(t, ux.Body.Coins-1500000, preview.Change.Coins)
+
+	outputHours, err := txn.OutputHours()
+	require.NoError(t, err)
+	require.Equal(t, preview.Inputs[0].CalculatedHours-outputHours, preview.Fee)
+	require.Equal(t, fee.RequiredFee(preview.Inputs[0].CalculatedHours, params.UserVerifyTxn.BurnFactor), preview.Fee)
+
+	size, err := txn.Size()
+	require.NoError(t, err)
+	require.Equal(t, size, preview.Size)
+
+	// The transaction is not signed
+	require.Equal(t, []cipher.Sig{{}}, txn.Sigs)
+
+	for _, c := range []VerifyTxnCheck{preview.User, preview.Unconfirmed, preview.CreateBlock} {
+		require.True(t, c.SizeOK)
+		require.True(t, c.FeeOK)
+		require.True(t, c.DecimalsOK)
+		require.NoError(t, c.Err)
+	}
+	require.Equal(t, params.UserVerifyTxn, preview.User.Params)
+	require.Equal(t, v.Config.UnconfirmedVerifyTxn, preview.Unconfirmed.Params)
+	require.Equal(t, v.Config.CreateBlockVerifyTxn, preview.CreateBlock.Params)
+
+	// Violations of stricter verification parameters are reported instead of returned
+	v.Config.UnconfirmedVerifyTxn.BurnFactor = params.UserVerifyTxn.BurnFactor / 2
+	v.Config.UnconfirmedVerifyTxn.MaxDropletPrecision = 0
+	v.Config.CreateBlockVerifyTxn.MaxTransactionSize = size - 1
+
+	preview, err = v.PreviewTransaction(p, CreateTransactionParams{
+		Addresses: []cipher.Address{wltAddr},
+	})
+	require.NoError(t, err)
+	require.NoError(t, preview.User.Err)
+
+	require.True(t, preview.Unconfirmed.SizeOK)
+	require.False(t, preview.Unconfirmed.FeeOK)
+	require.False(t, preview.Unconfirmed.DecimalsOK)
+	require.Equal(t, fee.RequiredFee(preview.Inputs[0].CalculatedHours, v.Config.UnconfirmedVerifyTxn.BurnFactor), preview.Unconfirmed.RequiredFee)
+	require.Equal(t, fee.ErrTxnInsufficientFee, preview.Unconfirmed.Err)
+
+	require.False(t, preview.CreateBlock.SizeOK)
+	require.True(t, preview.CreateBlock.FeeOK)
+	require.True(t, preview.CreateBlock.DecimalsOK)
+	require.Equal(t, ErrTxnExceedsMaxBlockSize, preview.CreateBlock.Err)
+}
@@ -0,0 +1,193 @@
+package visor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// ErrSweepKeysRequired is returned when sweeping no secret keys
+	ErrSweepKeysRequired = NewUserError(errors.New("secret keys to sweep are required"))
+	// ErrSweepNoUnspents is returned if the addresses of the swept keys have no spendable outputs
+	ErrSweepNoUnspents = NewUserError(errors.New("no unspent outputs to sweep"))
+)
+
+// CreateSweepTransaction creates a transaction which moves all coins and coin hours of the unspent outputs
+// of the secret keys' addresses to a new address of a wallet, minus the required coin hour fee.
+// The transaction is signed with the secret keys only, the wallet password is only used to generate the address,
+// which is generated once the transaction is verified.
+// Outputs spent by unconfirmed transactions are skipped. If the outputs don't fit in one transaction,
+// the outputs with the most coins are swept, and the rest can be swept once the transaction is confirmed.
+func (vs *Visor) CreateSweepTransaction(wltID string, password []byte, keys []cipher.SecKey) (*coin.Transaction, []TransactionInput, error) {
+	if len(keys) == 0 {
+		return nil, nil, ErrSweepKeysRequired
+	}
+
+	addrKeys := make(map[cipher.Address]cipher.SecKey, len(keys))
+	addrs := make([]cipher.Address, 0, len(keys))
+	for _, k := range keys {
+		addr, err := cipher.AddressFromSecKey(k)
+		if err != nil {
+			return nil, nil, NewUserError(fmt.Errorf("invalid secret key: %v", err))
+		}
+
+		if _, ok := addrKeys[addr]; ok {
+			continue
+		}
+		addrKeys[addr] = k
+		addrs = append(addrs, addr)
+	}
+
+	auxs, err := vs.GetUnspentsOfAddrs(addrs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var uxb []transaction.UxBalance
+	if err := vs.db.View("CreateSweepTransaction", func(tx *dbutil.Tx) error {
+		var err error
+		uxb, err = vs.sweepUxBalances(tx, auxs.Flatten())
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	if len(uxb) == 0 {
+		return nil, nil, ErrSweepNoUnspents
+	}
+
+	// Sweep the outputs with the most coins that fit in a transaction with a single output,
+	// estimating the size of the transaction like the size of a payout transaction
+	maxInputs := (int(params.UserVerifyTxn.MaxTransactionSize) - payoutTxnOverhead - payoutTxnOutputSize) / payoutTxnInputSize
+	sort.Slice(uxb, func(i, j int) bool {
+		if uxb[i].Coins == uxb[j].Coins {
+			return bytes.Compare(uxb[i].Hash[:], uxb[j].Hash[:]) < 0
+		}
+		return uxb[i].Coins > uxb[j].Coins
+	})
+	if len(uxb) > maxInputs {
+		uxb = uxb[:maxInputs]
+	}
+
+	var coins, hours uint64
+	for _, ux := range uxb {
+		coins, err = mathutil.AddUint64(coins, ux.Coins)
+		if err != nil {
+			return nil, nil, err
+		}
+		hours, err = mathutil.AddUint64(hours, ux.Hours)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if hours == 0 {
+		return nil, nil, fee.ErrTxnNoFee
+	}
+
+	// The transaction is verified with the address of a swept output as its destination first,
+	// so that the new address is only added to the wallet once the sweep is known to succeed
+	txn, err := newSweepTransaction(uxb, addrKeys, uxb[0].Address, coins, hours)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := vs.verifySweepTransaction(txn); err != nil {
+		return nil, nil, err
+	}
+
+	newAddrs, err := vs.wallets.NewAddresses(wltID, password, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txn, err = newSweepTransaction(uxb, addrKeys, newAddrs[0], coins, hours)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := vs.verifySweepTransaction(txn); err != nil {
+		return nil, nil, err
+	}
+
+	return txn, NewTransactionInputsFromUxBalance(uxb), nil
+}
+
+// newSweepTransaction creates a transaction sending the coins and coin hours of the outputs to an address,
+// minus the required coin hour fee, signed with the secret keys of the outputs' addresses
+func newSweepTransaction(uxb []transaction.UxBalance, addrKeys map[cipher.Address]cipher.SecKey, to cipher.Address, coins, hours uint64) (*coin.Transaction, error) {
+	txn := &coin.Transaction{}
+	signKeys := make([]cipher.SecKey, len(uxb))
+	for i, ux := range uxb {
+		if err := txn.PushInput(ux.Hash); err != nil {
+			return nil, err
+		}
+		signKeys[i] = addrKeys[ux.Address]
+	}
+
+	if err := txn.PushOutput(to, coins, fee.RemainingHours(hours, params.UserVerifyTxn.BurnFactor)); err != nil {
+		return nil, err
+	}
+
+	txn.SignInputs(signKeys)
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// verifySweepTransaction verifies a created sweep transaction against the user, soft and hard constraints
+func (vs *Visor) verifySweepTransaction(txn *coin.Transaction) error {
+	if err := VerifySingleTxnUserConstraints(*txn); err != nil {
+		logger.WithError(err).Error("Created sweep transaction violates transaction user constraints")
+		return err
+	}
+
+	if err := vs.db.View("CreateSweepTransaction", func(tx *dbutil.Tx) error {
+		_, _, err := vs.verifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn, TxnSigned)
+		return err
+	}); err != nil {
+		logger.WithError(err).Error("Created sweep transaction violates transaction soft/hard constraints")
+		return err
+	}
+
+	return nil
+}
+
+// sweepUxBalances returns the balances of the outputs which are not spent by unconfirmed transactions
+func (vs *Visor) sweepUxBalances(tx *dbutil.Tx, uxa coin.UxArray) ([]transaction.UxBalance, error) {
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make(map[cipher.SHA256]struct{})
+	if err := vs.unconfirmed.ForEach(tx, func(_ cipher.SHA256, txn UnconfirmedTransaction) error {
+		for _, h := range txn.Transaction.In {
+			spent[h] = struct{}{}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	unspent := make(coin.UxArray, 0, len(uxa))
+	for _, ux := range uxa {
+		if _, ok := spent[ux.Hash()]; !ok {
+			unspent = append(unspent, ux)
+		}
+	}
+
+	return transaction.NewUxBalances(unspent, head.Time())
+}
@@ -0,0 +1,80 @@
+package visor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestVisorCreateSweepTransaction(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v, _ := prepareFundedWalletVisor(t, db)
+
+	_, sweepKey := cipher.GenerateKeyPair()
+	sweepAddr := cipher.MustAddressFromSecKey(sweepKey)
+	_, emptyKey := cipher.GenerateKeyPair()
+
+	_, _, err := v.CreateSweepTransaction("a.wlt", nil, nil)
+	require.Equal(t, ErrSweepKeysRequired, err)
+
+	_, _, err = v.CreateSweepTransaction("a.wlt", nil, []cipher.SecKey{sweepKey})
+	require.Equal(t, ErrSweepNoUnspents, err)
+
+	// Send coins to the address of the key to sweep
+	headOutputs, err := v.GetUnspentsOfAddrs([]cipher.Address{genAddress})
+	require.NoError(t, err)
+	txn := makeSpendTxn(t, headOutputs[genAddress], []cipher.SecKey{genSecret}, sweepAddr, 100e6)
+	_, _, err = v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	createAndExecuteBlockAt(t, v, uint64(time.Now().UTC().Unix()+10))
+
+	auxs, err := v.GetUnspentsOfAddrs([]cipher.Address{sweepAddr})
+	require.NoError(t, err)
+	require.Len(t, auxs[sweepAddr], 1)
+	ux := auxs[sweepAddr][0]
+
+	_, _, err = v.CreateSweepTransaction("b.wlt", nil, []cipher.SecKey{sweepKey})
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	// Duplicate keys and keys without unspents are ignored
+	sweepTxn, inputs, err := v.CreateSweepTransaction("a.wlt", nil, []cipher.SecKey{sweepKey, emptyKey, sweepKey})
+	require.NoError(t, err)
+
+	w, err := v.wallets.GetWallet("a.wlt")
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 2)
+	newAddr := w.Entries[1].SkycoinAddress()
+
+	require.Equal(t, []cipher.SHA256{ux.Hash()}, sweepTxn.In)
+	require.Len(t, inputs, 1)
+	require.Equal(t, ux.Hash().Hex(), inputs[0].UxOut.Hash().Hex())
+	require.Len(t, sweepTxn.Out, 1)
+	require.Equal(t, newAddr, sweepTxn.Out[0].Address)
+	require.Equal(t, uint64(100e6), sweepTxn.Out[0].Coins)
+	require.Equal(t, fee.RemainingHours(inputs[0].CalculatedHours, params.UserVerifyTxn.BurnFactor), sweepTxn.Out[0].Hours)
+	require.NoError(t, sweepTxn.Verify())
+
+	known, _, _, err := v.InjectUserTransaction(*sweepTxn)
+	require.False(t, known)
+	require.NoError(t, err)
+
+	// Outputs spent by unconfirmed transactions are not swept
+	_, _, err = v.CreateSweepTransaction("a.wlt", nil, []cipher.SecKey{sweepKey})
+	require.Equal(t, ErrSweepNoUnspents, err)
+
+	createAndExecuteBlockAt(t, v, uint64(time.Now().UTC().Unix()+20))
+
+	auxs, err = v.GetUnspentsOfAddrs([]cipher.Address{sweepAddr, newAddr})
+	require.NoError(t, err)
+	require.Empty(t, auxs[sweepAddr])
+	require.Len(t, auxs[newAddr], 1)
+	require.Equal(t, sweepTxn.Hash(), auxs[newAddr][0].Body.SrcTransaction)
+}
@@ -174,6 +174,8 @@ type UnconfirmedTransactionPool struct {
 	// our future balance and avoid double spending our own coins
 	// Maps from Transaction.Hash() to UxArray.
 	unspent *txnUnspents
+	// Whether transactions may spend the outputs of other transactions in the pool
+	spendUnconfirmed bool
 }
 
 // NewUnconfirmedTransactionPool creates an UnconfirmedTransactionPool instance
@@ -232,9 +234,22 @@ func (utp *UnconfirmedTransactionPool) SetTransactionsAnnounced(tx *dbutil.Tx, h
 // If the transaction violates hard constraints, it is rejected.
 // Soft constraints violations mark a txn as invalid, but the txn is inserted. The soft violation is returned.
 func (utp *UnconfirmedTransactionPool) InjectTransaction(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, verifyParams params.VerifyTxn) (bool, *ErrTxnViolatesSoftConstraint, error) {
+	var outputs map[cipher.SHA256]coin.UxOut
+	if utp.spendUnconfirmed {
+		utxns, err := utp.txns.getAll(tx)
+		if err != nil {
+			return false, nil, err
+		}
+
+		outputs, err = utp.spendableOutputs(tx, bc, utxns)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
 	var isValid int8 = 1
 	var softErr *ErrTxnViolatesSoftConstraint
-	if _, _, err := bc.VerifySingleTxnSoftHardConstraints(tx, txn, verifyParams, TxnSigned); err != nil {
+	if _, _, err := verifyUnconfirmedTxnSoftHardConstraints(tx, bc, txn, outputs, verifyParams, TxnSigned); err != nil {
 		logger.Warningf("bc.VerifySingleTxnSoftHardConstraints failed for txn %s: %v", txn.Hash().Hex(), err)
 		switch e := err.(type) {
 		case ErrTxnViolatesSoftConstraint:
@@ -302,11 +317,46 @@ func (utp *UnconfirmedTransactionPool) AllRawTransactions(tx *dbutil.Tx) (coin.T
 		return nil, err
 	}
 
+	return unconfirmedRawTransactions(utxns), nil
+}
+
+// spendableOutputs returns the outputs created by the valid transactions of utxns, if transactions may spend the outputs
+// of other transactions in the pool. Otherwise it returns nil
+func (utp *UnconfirmedTransactionPool) spendableOutputs(tx *dbutil.Tx, bc Blockchainer, utxns []UnconfirmedTransaction) (map[cipher.SHA256]coin.UxOut, error) {
+	if !utp.spendUnconfirmed {
+		return nil, nil
+	}
+
+	head, err := bc.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newUnconfirmedOutputs(head.Head, utxns), nil
+}
+
+// poolOutputs returns the outputs created by txns, valid or not, if transactions may spend the outputs of other
+// transactions in the pool. Otherwise it returns nil.
+// These are used to check the transactions already in the pool, whose validity depends on the validity of their parents
+func (utp *UnconfirmedTransactionPool) poolOutputs(tx *dbutil.Tx, bc Blockchainer, txns coin.Transactions) (map[cipher.SHA256]coin.UxOut, error) {
+	if !utp.spendUnconfirmed {
+		return nil, nil
+	}
+
+	head, err := bc.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPoolOutputs(head.Head, txns), nil
+}
+
+func unconfirmedRawTransactions(utxns []UnconfirmedTransaction) coin.Transactions {
 	txns := make(coin.Transactions, len(utxns))
 	for i := range utxns {
 		txns[i] = utxns[i].Transaction
 	}
-	return txns, nil
+	return txns
 }
 
 // Remove a single txn by hash
@@ -338,24 +388,59 @@ func (utp *UnconfirmedTransactionPool) Refresh(tx *dbutil.Tx, bc Blockchainer, v
 		return nil, err
 	}
 
+	outputs, err := utp.poolOutputs(tx, bc, unconfirmedRawTransactions(utxns))
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now().UTC()
 	var nowValid []cipher.SHA256
 
+	valid := make(map[cipher.SHA256]bool, len(utxns))
 	for _, utxn := range utxns {
-		utxn.Checked = now.UnixNano()
-
-		_, _, err := bc.VerifySingleTxnSoftHardConstraints(tx, utxn.Transaction, verifyParams, TxnSigned)
+		_, _, err := verifyUnconfirmedTxnSoftHardConstraints(tx, bc, utxn.Transaction, outputs, verifyParams, TxnSigned)
 
 		switch err.(type) {
 		case ErrTxnViolatesSoftConstraint, ErrTxnViolatesHardConstraint:
-			utxn.IsValid = 0
+			valid[utxn.Transaction.Hash()] = false
 		case nil:
+			valid[utxn.Transaction.Hash()] = true
+		default:
+			return nil, err
+		}
+	}
+
+	// A transaction spending the outputs of an invalid transaction is invalid too
+	if outputs != nil {
+		for changed := true; changed; {
+			changed = false
+			for _, utxn := range utxns {
+				h := utxn.Transaction.Hash()
+				if !valid[h] {
+					continue
+				}
+
+				for _, p := range unconfirmedParents(utxn.Transaction, outputs) {
+					if !valid[p] {
+						valid[h] = false
+						changed = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for _, utxn := range utxns {
+		utxn.Checked = now.UnixNano()
+
+		if valid[utxn.Transaction.Hash()] {
 			if utxn.IsValid == 0 {
 				nowValid = append(nowValid, utxn.Transaction.Hash())
 			}
 			utxn.IsValid = 1
-		default:
-			return nil, err
+		} else {
+			utxn.IsValid = 0
 		}
 
 		if err := utp.txns.put(tx, &utxn); err != nil {
@@ -367,26 +452,46 @@ func (utp *UnconfirmedTransactionPool) Refresh(tx *dbutil.Tx, bc Blockchainer, v
 }
 
 // RemoveInvalid checks all unconfirmed txns against the blockchain.
-// If a transaction violates hard constraints it is removed from the pool.
+// If a transaction violates hard constraints it is removed from the pool,
+// along with the transactions spending its outputs.
 // The transactions that were removed are returned.
 func (utp *UnconfirmedTransactionPool) RemoveInvalid(tx *dbutil.Tx, bc Blockchainer) ([]cipher.SHA256, error) {
 	var removeUtxns []cipher.SHA256
 
-	utxns, err := utp.txns.getAll(tx)
+	txns, err := utp.AllRawTransactions(tx)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, utxn := range utxns {
-		err := bc.VerifySingleTxnHardConstraints(tx, utxn.Transaction, TxnSigned)
+	// Removing a transaction removes the outputs spent by its children,
+	// so the remaining transactions are checked again until none are removed
+	for {
+		outputs, err := utp.poolOutputs(tx, bc, txns)
 		if err != nil {
-			switch err.(type) {
-			case ErrTxnViolatesHardConstraint:
-				removeUtxns = append(removeUtxns, utxn.Transaction.Hash())
-			default:
-				return nil, err
+			return nil, err
+		}
+
+		var remaining coin.Transactions
+		for _, txn := range txns {
+			err := verifyUnconfirmedTxnHardConstraints(tx, bc, txn, outputs, TxnSigned)
+			if err != nil {
+				switch err.(type) {
+				case ErrTxnViolatesHardConstraint:
+					removeUtxns = append(removeUtxns, txn.Hash())
+					continue
+				default:
+					return nil, err
+				}
 			}
+
+			remaining = append(remaining, txn)
+		}
+
+		if outputs == nil || len(remaining) == len(txns) {
+			break
 		}
+
+		txns = remaining
 	}
 
 	if err := utp.RemoveTransactions(tx, removeUtxns); err != nil {
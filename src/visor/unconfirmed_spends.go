@@ -0,0 +1,276 @@
+package visor
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+/*
+
+Spending unconfirmed outputs
+
+If Config.SpendUnconfirmed is enabled, a transaction in the unconfirmed pool may spend the outputs
+created by other transactions in the pool (its parents), before they are confirmed.
+
+The outputs of unconfirmed transactions are created with the time of the head block, so their coin hours
+are the hours they would have if the transactions were executed in the next block.
+These are the least hours the outputs can have once confirmed, so a transaction which
+passes verification against them does not become invalid when its parents are confirmed.
+
+Block verification only accepts transactions spending outputs that were confirmed before the block,
+so a transaction is not included in a block until all of its parents are confirmed.
+If a parent is removed from the pool, the transactions spending its outputs are removed with it.
+
+Only the outputs of valid transactions can be spent, since an invalid transaction is not included in a block
+until it becomes valid, and may never be. The transactions already spending the outputs of a transaction
+which becomes invalid are marked invalid with it, and are kept in the pool.
+
+*/
+
+// unconfirmedOutputsHeader returns the block header used to create the outputs of unconfirmed transactions,
+// as if the transactions were executed in the block after the head block bh.
+// CreateUnspents uses the null hash as the source transaction of outputs in the genesis block,
+// so the header of the head block can't be used when the head block is the genesis block
+func unconfirmedOutputsHeader(bh coin.BlockHeader) coin.BlockHeader {
+	return coin.BlockHeader{
+		BkSeq: bh.BkSeq + 1,
+		Time:  bh.Time,
+	}
+}
+
+// newUnconfirmedOutputs returns the outputs created by the valid unconfirmed transactions of utxns, mapped by hash.
+// These are the unconfirmed outputs which can be spent
+func newUnconfirmedOutputs(bh coin.BlockHeader, utxns []UnconfirmedTransaction) map[cipher.SHA256]coin.UxOut {
+	var txns coin.Transactions
+	for _, utxn := range utxns {
+		if utxn.IsValid != 0 {
+			txns = append(txns, utxn.Transaction)
+		}
+	}
+	return newPoolOutputs(bh, txns)
+}
+
+// newPoolOutputs returns the outputs created by the unconfirmed transactions txns, valid or not, mapped by hash.
+// These are used to find the inputs of the transactions in the pool, not to spend them
+func newPoolOutputs(bh coin.BlockHeader, txns coin.Transactions) map[cipher.SHA256]coin.UxOut {
+	next := unconfirmedOutputsHeader(bh)
+	outputs := make(map[cipher.SHA256]coin.UxOut)
+	for _, txn := range txns {
+		for _, ux := range coin.CreateUnspents(next, txn) {
+			outputs[ux.Hash()] = ux
+		}
+	}
+	return outputs
+}
+
+// unconfirmedParents returns the hashes of the unconfirmed transactions which created outputs spent by txn
+func unconfirmedParents(txn coin.Transaction, outputs map[cipher.SHA256]coin.UxOut) []cipher.SHA256 {
+	var parents []cipher.SHA256
+	seen := make(map[cipher.SHA256]struct{})
+	for _, h := range txn.In {
+		ux, ok := outputs[h]
+		if !ok {
+			continue
+		}
+
+		if _, ok := seen[ux.Body.SrcTransaction]; !ok {
+			seen[ux.Body.SrcTransaction] = struct{}{}
+			parents = append(parents, ux.Body.SrcTransaction)
+		}
+	}
+	return parents
+}
+
+// getUnspentsWithUnconfirmed returns the outputs of hashes from the unspent pool, or from the outputs of unconfirmed transactions.
+// blockdb.ErrUnspentNotExist is returned if an output is in neither
+func getUnspentsWithUnconfirmed(tx *dbutil.Tx, bc Blockchainer, hashes []cipher.SHA256, outputs map[cipher.SHA256]coin.UxOut) (coin.UxArray, error) {
+	if outputs == nil {
+		return bc.Unspent().GetArray(tx, hashes)
+	}
+
+	uxa := make(coin.UxArray, len(hashes))
+
+	var confirmed []cipher.SHA256
+	var confirmedIdx []int
+	for i, h := range hashes {
+		if ux, ok := outputs[h]; ok {
+			uxa[i] = ux
+		} else {
+			confirmed = append(confirmed, h)
+			confirmedIdx = append(confirmedIdx, i)
+		}
+	}
+
+	if len(confirmed) == 0 {
+		return uxa, nil
+	}
+
+	confirmedUxa, err := bc.Unspent().GetArray(tx, confirmed)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, ux := range confirmedUxa {
+		uxa[confirmedIdx[i]] = ux
+	}
+
+	return uxa, nil
+}
+
+// verifyUnconfirmedTxnSoftHardConstraints checks that a transaction does not violate hard or soft constraints,
+// like Blockchain.VerifySingleTxnSoftHardConstraints, but its inputs may also be unconfirmed outputs
+func verifyUnconfirmedTxnSoftHardConstraints(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, outputs map[cipher.SHA256]coin.UxOut,
+	verifyParams params.VerifyTxn, signed TxnSignedFlag) (*coin.SignedBlock, coin.UxArray, error) {
+	if len(unconfirmedParents(txn, outputs)) == 0 {
+		return bc.VerifySingleTxnSoftHardConstraints(tx, txn, verifyParams, signed)
+	}
+
+	head, uxIn, err := verifyTxnHardConstraintsWithUnconfirmed(tx, bc, txn, outputs, signed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := VerifySingleTxnSoftConstraints(txn, head.Time(), uxIn, verifyParams); err != nil {
+		return nil, nil, err
+	}
+
+	return head, uxIn, nil
+}
+
+// verifyUnconfirmedTxnHardConstraints checks that a transaction does not violate hard constraints,
+// like Blockchain.VerifySingleTxnHardConstraints, but its inputs may also be unconfirmed outputs
+func verifyUnconfirmedTxnHardConstraints(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, outputs map[cipher.SHA256]coin.UxOut, signed TxnSignedFlag) error {
+	if len(unconfirmedParents(txn, outputs)) == 0 {
+		return bc.VerifySingleTxnHardConstraints(tx, txn, signed)
+	}
+
+	_, _, err := verifyTxnHardConstraintsWithUnconfirmed(tx, bc, txn, outputs, signed)
+	return err
+}
+
+func verifyTxnHardConstraintsWithUnconfirmed(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, outputs map[cipher.SHA256]coin.UxOut,
+	signed TxnSignedFlag) (*coin.SignedBlock, coin.UxArray, error) {
+	uxIn, err := getUnspentsWithUnconfirmed(tx, bc, txn.In, outputs)
+	if err != nil {
+		switch err.(type) {
+		case blockdb.ErrUnspentNotExist:
+			return nil, nil, NewErrTxnViolatesHardConstraint(err)
+		default:
+			return nil, nil, err
+		}
+	}
+
+	head, err := bc.Head(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := VerifySingleTxnHardConstraints(txn, head.Head, uxIn, signed); err != nil {
+		return nil, nil, err
+	}
+
+	return head, uxIn, nil
+}
+
+// sortParentsFirst orders unconfirmed transactions so that each comes after the transactions whose outputs it spends.
+// Otherwise the order of the transactions is kept
+func sortParentsFirst(txns []UnconfirmedTransaction, outputs map[cipher.SHA256]coin.UxOut) []UnconfirmedTransaction {
+	sorted := make([]UnconfirmedTransaction, 0, len(txns))
+	added := make(map[cipher.SHA256]struct{}, len(txns))
+	inPool := make(map[cipher.SHA256]struct{}, len(txns))
+	for _, txn := range txns {
+		inPool[txn.Transaction.Hash()] = struct{}{}
+	}
+
+	for len(sorted) < len(txns) {
+		n := len(sorted)
+		for _, txn := range txns {
+			h := txn.Transaction.Hash()
+			if _, ok := added[h]; ok {
+				continue
+			}
+
+			ready := true
+			for _, p := range unconfirmedParents(txn.Transaction, outputs) {
+				_, isAdded := added[p]
+				_, isInPool := inPool[p]
+				if isInPool && !isAdded {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				sorted = append(sorted, txn)
+				added[h] = struct{}{}
+			}
+		}
+
+		// Transactions can't spend each other's outputs, but don't loop forever if they do
+		if len(sorted) == n {
+			logger.Critical().Error("sortParentsFirst: unconfirmed transactions spend each other's outputs")
+			for _, txn := range txns {
+				if _, ok := added[txn.Transaction.Hash()]; !ok {
+					sorted = append(sorted, txn)
+				}
+			}
+		}
+	}
+
+	return sorted
+}
+
+// unconfirmedOutputs returns the outputs created by the valid unconfirmed transactions, if they can be spent.
+// If Config.SpendUnconfirmed is disabled, it returns nil.
+func (vs *Visor) unconfirmedOutputs(tx *dbutil.Tx) (map[cipher.SHA256]coin.UxOut, error) {
+	if !vs.Config.SpendUnconfirmed {
+		return nil, nil
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	utxns, err := vs.unconfirmed.GetFiltered(tx, IsValid)
+	if err != nil {
+		return nil, err
+	}
+
+	return newUnconfirmedOutputs(head.Head, utxns), nil
+}
+
+// poolOutputs returns the outputs created by all the unconfirmed transactions, valid or not, to find the inputs
+// of the transactions in the pool. Use unconfirmedOutputs for the outputs which can be spent.
+// If Config.SpendUnconfirmed is disabled, it returns nil.
+func (vs *Visor) poolOutputs(tx *dbutil.Tx) (map[cipher.SHA256]coin.UxOut, error) {
+	if !vs.Config.SpendUnconfirmed {
+		return nil, nil
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := vs.unconfirmed.AllRawTransactions(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPoolOutputs(head.Head, txns), nil
+}
+
+// verifySingleTxnSoftHardConstraints checks that a transaction does not violate hard or soft constraints.
+// If Config.SpendUnconfirmed is enabled, its inputs may be the outputs of unconfirmed transactions
+func (vs *Visor) verifySingleTxnSoftHardConstraints(tx *dbutil.Tx, txn coin.Transaction, verifyParams params.VerifyTxn, signed TxnSignedFlag) (*coin.SignedBlock, coin.UxArray, error) {
+	outputs, err := vs.unconfirmedOutputs(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return verifyUnconfirmedTxnSoftHardConstraints(tx, vs.blockchain, txn, outputs, verifyParams, signed)
+}
@@ -0,0 +1,317 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func makeSpendUnconfirmedVisor(t *testing.T, db *dbutil.DB, spendUnconfirmed bool) *Visor {
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey:      genPublic,
+		Arbitrating: true,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	require.NoError(t, err)
+	unconfirmed.spendUnconfirmed = spendUnconfirmed
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.Arbitrating = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.BlockchainSeckey = genSecret
+	cfg.SpendUnconfirmed = spendUnconfirmed
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+	}
+
+	addGenesisBlockToVisor(t, v)
+
+	return v
+}
+
+func genesisUnspents(t *testing.T, v *Visor) coin.UxArray {
+	var gb *coin.SignedBlock
+	err := v.db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+	require.NotNil(t, gb)
+
+	return coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+}
+
+func requireUnconfirmedLen(t *testing.T, v *Visor, n uint64) {
+	err := v.db.View("", func(tx *dbutil.Tx) error {
+		length, err := v.unconfirmed.Len(tx)
+		require.NoError(t, err)
+		require.Equal(t, n, length)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestVisorSpendUnconfirmedDisabled(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := makeSpendUnconfirmedVisor(t, db, false)
+	uxs := genesisUnspents(t, v)
+
+	// The outputs of unconfirmed transactions are created in the block after the genesis block
+	head := unconfirmedOutputsHeader(coin.BlockHeader{})
+
+	parent := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+	known, softErr, err := v.InjectForeignTransaction(parent)
+	require.False(t, known)
+	require.Nil(t, softErr)
+	require.NoError(t, err)
+
+	// The outputs of the parent can't be spent until it is confirmed
+	child := makeSpendTxn(t, coin.CreateUnspents(head, parent), []cipher.SecKey{genSecret, genSecret}, genAddress, 1e6)
+	_, _, err = v.InjectForeignTransaction(child)
+	require.Error(t, err)
+	require.IsType(t, ErrTxnViolatesHardConstraint{}, err)
+
+	requireUnconfirmedLen(t, v, 1)
+}
+
+func TestVisorSpendUnconfirmed(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := makeSpendUnconfirmedVisor(t, db, true)
+	uxs := genesisUnspents(t, v)
+
+	// The outputs of unconfirmed transactions are created in the block after the genesis block
+	head := unconfirmedOutputsHeader(coin.BlockHeader{})
+
+	parent := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+	child := makeSpendTxn(t, coin.CreateUnspents(head, parent), []cipher.SecKey{genSecret, genSecret}, genAddress, 1e6)
+
+	// The child can't be injected before its parent
+	_, _, err := v.InjectForeignTransaction(child)
+	require.Error(t, err)
+	require.IsType(t, ErrTxnViolatesHardConstraint{}, err)
+
+	known, softErr, err := v.InjectForeignTransaction(parent)
+	require.False(t, known)
+	require.Nil(t, softErr)
+	require.NoError(t, err)
+
+	known, softErr, err = v.InjectForeignTransaction(child)
+	require.False(t, known)
+	require.Nil(t, softErr)
+	require.NoError(t, err)
+
+	requireUnconfirmedLen(t, v, 2)
+
+	// The parent is announced before the child
+	txns, err := v.GetAllUnconfirmedTransactions()
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+	require.Equal(t, parent.Hash(), txns[0].Transaction.Hash())
+	require.Equal(t, child.Hash(), txns[1].Transaction.Hash())
+
+	// The child is not included in a block until its parent is confirmed
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+	require.Len(t, sb.Body.Transactions, 1)
+	require.Equal(t, parent.Hash(), sb.Body.Transactions[0].Hash())
+
+	requireUnconfirmedLen(t, v, 1)
+
+	removed, err := v.RemoveInvalidUnconfirmed()
+	require.NoError(t, err)
+	require.Empty(t, removed)
+
+	sb = createAndExecuteBlockAt(t, v, sb.Time()+1)
+	require.Len(t, sb.Body.Transactions, 1)
+	require.Equal(t, child.Hash(), sb.Body.Transactions[0].Hash())
+
+	requireUnconfirmedLen(t, v, 0)
+}
+
+func TestVisorSpendUnconfirmedRemoveInvalidParent(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := makeSpendUnconfirmedVisor(t, db, true)
+	uxs := genesisUnspents(t, v)
+
+	// The outputs of unconfirmed transactions are created in the block after the genesis block
+	head := unconfirmedOutputsHeader(coin.BlockHeader{})
+
+	// Create a parent and a child spending its outputs, and a double spend of the parent with a higher fee.
+	// The double spend is included in the next block, and the parent becomes invalid.
+	// A call to RemoveInvalidUnconfirmed removes the parent and the child.
+
+	var coins uint64 = 10e6
+	parent := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, coins)
+	child := makeSpendTxn(t, coin.CreateUnspents(head, parent), []cipher.SecKey{genSecret, genSecret}, genAddress, 1e6)
+	doubleSpend := makeSpendTxWithFee(t, uxs, []cipher.SecKey{genSecret}, genAddress, coins, 1)
+
+	for _, txn := range []coin.Transaction{parent, child, doubleSpend} {
+		known, softErr, err := v.InjectForeignTransaction(txn)
+		require.False(t, known)
+		require.Nil(t, softErr)
+		require.NoError(t, err)
+	}
+
+	requireUnconfirmedLen(t, v, 3)
+
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+	require.Len(t, sb.Body.Transactions, 1)
+	require.Equal(t, doubleSpend.Hash(), sb.Body.Transactions[0].Hash())
+
+	requireUnconfirmedLen(t, v, 2)
+
+	removed, err := v.RemoveInvalidUnconfirmed()
+	require.NoError(t, err)
+	require.Len(t, removed, 2)
+	require.Contains(t, removed, parent.Hash())
+	require.Contains(t, removed, child.Hash())
+
+	requireUnconfirmedLen(t, v, 0)
+}
+
+func TestVisorSpendUnconfirmedInvalidParent(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := makeSpendUnconfirmedVisor(t, db, true)
+	uxs := genesisUnspents(t, v)
+
+	// The outputs of unconfirmed transactions are created in the block after the genesis block
+	head := unconfirmedOutputsHeader(coin.BlockHeader{})
+
+	parent := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+	child := makeSpendTxn(t, coin.CreateUnspents(head, parent), []cipher.SecKey{genSecret, genSecret}, genAddress, 1e6)
+
+	known, softErr, err := v.InjectForeignTransaction(parent)
+	require.False(t, known)
+	require.Nil(t, softErr)
+	require.NoError(t, err)
+
+	// The parent becomes invalid, e.g. after a change of the verification parameters
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return v.unconfirmed.(*UnconfirmedTransactionPool).txns.update(tx, parent.Hash(), func(utxn *UnconfirmedTransaction) error {
+			utxn.IsValid = 0
+			return nil
+		})
+	})
+	require.NoError(t, err)
+
+	// The outputs of the invalid parent can't be spent, but are still found as the outputs of a pool transaction
+	err = db.View("", func(tx *dbutil.Tx) error {
+		outputs, err := v.unconfirmedOutputs(tx)
+		require.NoError(t, err)
+		require.Empty(t, outputs)
+
+		outputs, err = v.poolOutputs(tx)
+		require.NoError(t, err)
+		require.Len(t, outputs, len(parent.Out))
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, _, err = v.InjectForeignTransaction(child)
+	require.Error(t, err)
+	require.IsType(t, ErrTxnViolatesHardConstraint{}, err)
+
+	requireUnconfirmedLen(t, v, 1)
+}
+
+func TestSortParentsFirst(t *testing.T) {
+	head := unconfirmedOutputsHeader(coin.BlockHeader{})
+	uxs := coin.UxArray{
+		{
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        genAddress,
+				Coins:          100e6,
+				Hours:          1000,
+			},
+		},
+	}
+
+	parent := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+	child := makeSpendTxn(t, coin.CreateUnspents(head, parent), []cipher.SecKey{genSecret, genSecret}, genAddress, 1e6)
+	grandchild := makeSpendTxn(t, coin.CreateUnspents(head, child), []cipher.SecKey{genSecret, genSecret}, genAddress, 1e5)
+	other := makeSpendTxn(t, coin.UxArray{{
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        genAddress,
+			Coins:          1e6,
+			Hours:          100,
+		},
+	}}, []cipher.SecKey{genSecret}, genAddress, 1e6)
+
+	outputs := newPoolOutputs(coin.BlockHeader{}, coin.Transactions{parent, child, grandchild, other})
+
+	toUnconfirmed := func(txns ...coin.Transaction) []UnconfirmedTransaction {
+		utxns := make([]UnconfirmedTransaction, len(txns))
+		for i, txn := range txns {
+			utxns[i] = UnconfirmedTransaction{
+				Transaction: txn,
+			}
+		}
+		return utxns
+	}
+
+	cases := []struct {
+		name   string
+		txns   []UnconfirmedTransaction
+		sorted []UnconfirmedTransaction
+	}{
+		{
+			name:   "empty",
+			txns:   []UnconfirmedTransaction{},
+			sorted: []UnconfirmedTransaction{},
+		},
+		{
+			name:   "sorted",
+			txns:   toUnconfirmed(parent, other, child, grandchild),
+			sorted: toUnconfirmed(parent, other, child, grandchild),
+		},
+		{
+			name:   "reversed",
+			txns:   toUnconfirmed(grandchild, child, other, parent),
+			sorted: toUnconfirmed(other, parent, child, grandchild),
+		},
+		{
+			name:   "missing parent",
+			txns:   toUnconfirmed(grandchild, other, child),
+			sorted: toUnconfirmed(other, child, grandchild),
+		},
+		{
+			name:   "parent not in pool",
+			txns:   toUnconfirmed(grandchild, other),
+			sorted: toUnconfirmed(grandchild, other),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted := sortParentsFirst(tc.txns, outputs)
+			require.Equal(t, tc.sorted, sorted)
+		})
+	}
+}
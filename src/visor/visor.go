@@ -43,6 +43,8 @@ type Visor struct {
 	blockchain  Blockchainer
 	history     Historyer
 	wallets     *wallet.Service
+	events      *EventBus
+	payouts     *payouts
 }
 
 // New creates a Visor for managing the blockchain database
@@ -63,6 +65,7 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 	logger.Infof("Max transaction size for transactions when creating blocks is %d", c.CreateBlockVerifyTxn.MaxTransactionSize)
 	logger.Infof("Max decimals for transactions when creating blocks is %d", c.CreateBlockVerifyTxn.MaxDropletPrecision)
 	logger.Infof("Max block size is %d", c.MaxBlockTransactionsSize)
+	logger.Infof("Spending the outputs of unconfirmed transactions is allowed: %v", c.SpendUnconfirmed)
 
 	if !db.IsReadOnly() {
 		if err := CreateBuckets(db); err != nil {
@@ -102,6 +105,7 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 	if err != nil {
 		return nil, err
 	}
+	utp.spendUnconfirmed = c.SpendUnconfirmed
 
 	v := &Visor{
 		Config:      c,
@@ -111,6 +115,8 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 		unconfirmed: utp,
 		history:     history,
 		wallets:     wltServ,
+		events:      NewEventBus(),
+		payouts:     &payouts{},
 	}
 
 	return v, nil
@@ -293,9 +299,23 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 
 	logger.Infof("unconfirmed pool has %d transactions pending", len(txns))
 
-	// Filter transactions that violate all constraints
+	outputs, err := vs.poolOutputs(tx)
+	if err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	// Filter transactions that violate all constraints.
+	// Transactions spending the outputs of unconfirmed transactions wait for a later block,
+	// since a block can only spend outputs confirmed before it
 	var filteredTxns coin.Transactions
+	nWaiting := 0
 	for _, txn := range txns {
+		if parents := unconfirmedParents(txn, outputs); len(parents) != 0 {
+			logger.Debugf("Transaction %s waits for %d unconfirmed parent transactions", txn.Hash().Hex(), len(parents))
+			nWaiting++
+			continue
+		}
+
 		if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.CreateBlockVerifyTxn, TxnSigned); err != nil {
 			switch err.(type) {
 			case ErrTxnViolatesHardConstraint, ErrTxnViolatesSoftConstraint:
@@ -308,7 +328,11 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 		}
 	}
 
-	nRemoved := len(txns) - len(filteredTxns)
+	if nWaiting > 0 {
+		logger.Infof("CreateBlock deferred %d transactions spending the outputs of unconfirmed transactions", nWaiting)
+	}
+
+	nRemoved := len(txns) - len(filteredTxns) - nWaiting
 	if nRemoved > 0 {
 		logger.Infof("CreateBlock ignored %d transactions violating constraints", nRemoved)
 	}
@@ -390,6 +414,15 @@ func (vs *Visor) executeSignedBlock(tx *dbutil.Tx, b coin.SignedBlock) error {
 		return err
 	}
 
+	// The spent outputs are collected before the block removes them from the unspent pool
+	if vs.events.HasSubscribers() {
+		events, err := vs.blockEvents(tx, b.Block)
+		if err != nil {
+			return err
+		}
+		vs.publishEventsOnCommit(tx, events)
+	}
+
 	if err := vs.blockchain.ExecuteBlock(tx, &b); err != nil {
 		return err
 	}
@@ -481,7 +514,12 @@ func (vs *Visor) unconfirmedOutgoingOutputs(tx *dbutil.Tx) (coin.UxArray, error)
 		inputs = append(inputs, txn.In...)
 	}
 
-	return vs.blockchain.Unspent().GetArray(tx, inputs)
+	outputs, err := vs.poolOutputs(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return getUnspentsWithUnconfirmed(tx, vs.blockchain, inputs, outputs)
 }
 
 // UnconfirmedIncomingOutputs returns all outputs that would be created by unconfirmed transactions
@@ -752,7 +790,19 @@ func (vs *Visor) InjectForeignTransaction(txn coin.Transaction) (bool, *ErrTxnVi
 	if err := vs.db.Update("InjectForeignTransaction", func(tx *dbutil.Tx) error {
 		var err error
 		known, softErr, err = vs.unconfirmed.InjectTransaction(tx, vs.blockchain, txn, vs.Config.UnconfirmedVerifyTxn)
-		return err
+		if err != nil {
+			return err
+		}
+
+		if !known && vs.events.HasSubscribers() {
+			events, err := vs.unconfirmedTxnEvents(tx, txn)
+			if err != nil {
+				return err
+			}
+			vs.publishEventsOnCommit(tx, events)
+		}
+
+		return nil
 	}); err != nil {
 		return false, nil, err
 	}
@@ -790,7 +840,7 @@ func (vs *Visor) InjectUserTransactionTx(tx *dbutil.Tx, txn coin.Transaction) (b
 		return false, nil, nil, err
 	}
 
-	head, inputs, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, params.UserVerifyTxn, TxnSigned)
+	head, inputs, err := vs.verifySingleTxnSoftHardConstraints(tx, txn, params.UserVerifyTxn, TxnSigned)
 	if err != nil {
 		return false, nil, nil, err
 	}
@@ -799,8 +849,37 @@ func (vs *Visor) InjectUserTransactionTx(tx *dbutil.Tx, txn coin.Transaction) (b
 	if softErr != nil {
 		logger.WithError(softErr).Warning("InjectUserTransaction vs.unconfirmed.InjectTransaction returned a softErr unexpectedly")
 	}
+	if err != nil {
+		return false, nil, nil, err
+	}
+
+	// Enforce the spending policies of the wallets that own the inputs.
+	// Returning an error rolls back the injection.
+	if !known {
+		spends, err := vs.wallets.RecordSpendingPolicies(&txn, inputs)
+		if err != nil {
+			return false, nil, nil, err
+		}
+		if spends != nil {
+			// The spends are saved to the policy files only once the injection is committed
+			tx.OnCommit(func() {
+				if err := spends.Save(); err != nil {
+					logger.WithError(err).Error("InjectUserTransaction: saving the spending policy spends failed")
+				}
+			})
+			tx.OnRollback(spends.Discard)
+		}
+
+		if vs.events.HasSubscribers() {
+			events, err := vs.unconfirmedTxnEvents(tx, txn)
+			if err != nil {
+				return false, nil, nil, err
+			}
+			vs.publishEventsOnCommit(tx, events)
+		}
+	}
 
-	return known, head, inputs, err
+	return known, head, inputs, nil
 }
 
 // GetTransactionsForAddress returns the Transactions whose unspents give coins to a cipher.Address.
@@ -819,6 +898,39 @@ func (vs *Visor) GetTransactionsForAddress(a cipher.Address) ([]Transaction, err
 	return txns[a], nil
 }
 
+// AddressesActivity returns whether each address has appeared in a confirmed transaction,
+// according to the historydb address index, or received an output in the unconfirmed pool.
+func (vs *Visor) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	active := make([]bool, len(addrs))
+
+	if err := vs.db.View("AddressesActivity", func(tx *dbutil.Tx) error {
+		for i, a := range addrs {
+			txns, err := vs.history.GetTransactionsForAddress(tx, a)
+			if err != nil {
+				return err
+			}
+
+			if len(txns) != 0 {
+				active[i] = true
+				continue
+			}
+
+			uxs, err := vs.unconfirmed.GetUnspentsOfAddr(tx, a)
+			if err != nil {
+				return err
+			}
+
+			active[i] = len(uxs) != 0
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return active, nil
+}
+
 // GetTransaction returns a Transaction by hash.
 func (vs *Visor) GetTransaction(txnHash cipher.SHA256) (*Transaction, error) {
 	var txn *Transaction
@@ -858,7 +970,15 @@ func (vs *Visor) GetTransactionWithInputs(txnHash cipher.SHA256) (*Transaction,
 			return nil
 		}
 
-		inputs, err = vs.getTransactionInputs(tx, *feeCalcTime, txn.Transaction.In)
+		var outputs map[cipher.SHA256]coin.UxOut
+		if !txn.Status.Confirmed {
+			outputs, err = vs.poolOutputs(tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		inputs, err = vs.getTransactionInputsWithUnconfirmed(tx, *feeCalcTime, txn.Transaction.In, outputs)
 		return err
 	}); err != nil {
 		return nil, nil, err
@@ -983,6 +1103,11 @@ func (vs *Visor) GetTransactionsWithInputs(flts []TxFilter) ([]Transaction, [][]
 			return err
 		}
 
+		outputs, err := vs.poolOutputs(tx)
+		if err != nil {
+			return err
+		}
+
 		inputs = make([][]TransactionInput, len(txns))
 		for i, txn := range txns {
 			feeCalcTime, err := vs.getFeeCalcTimeForTransaction(tx, txn)
@@ -993,7 +1118,12 @@ func (vs *Visor) GetTransactionsWithInputs(flts []TxFilter) ([]Transaction, [][]
 				continue
 			}
 
-			txnInputs, err := vs.getTransactionInputs(tx, *feeCalcTime, txn.Transaction.In)
+			var txnOutputs map[cipher.SHA256]coin.UxOut
+			if !txn.Status.Confirmed {
+				txnOutputs = outputs
+			}
+
+			txnInputs, err := vs.getTransactionInputsWithUnconfirmed(tx, *feeCalcTime, txn.Transaction.In, txnOutputs)
 			if err != nil {
 				return err
 			}
@@ -1347,14 +1477,29 @@ func SendsToAddresses(addresses []cipher.Address) func(UnconfirmedTransaction) b
 	}
 }
 
-// GetAllUnconfirmedTransactions returns all unconfirmed transactions
+// GetAllUnconfirmedTransactions returns all unconfirmed transactions.
+// If Config.SpendUnconfirmed is enabled, the transactions spending the outputs of other unconfirmed transactions
+// come after them, so that they can be rebroadcast in order
 func (vs *Visor) GetAllUnconfirmedTransactions() ([]UnconfirmedTransaction, error) {
 	var txns []UnconfirmedTransaction
 
 	if err := vs.db.View("GetAllUnconfirmedTransactions", func(tx *dbutil.Tx) error {
 		var err error
 		txns, err = vs.unconfirmed.GetFiltered(tx, All)
-		return err
+		if err != nil {
+			return err
+		}
+
+		outputs, err := vs.poolOutputs(tx)
+		if err != nil {
+			return err
+		}
+
+		if outputs != nil {
+			txns = sortParentsFirst(txns, outputs)
+		}
+
+		return nil
 	}); err != nil {
 		return nil, err
 	}
@@ -1400,6 +1545,11 @@ func (vs *Visor) getTransactionInputsForUnconfirmedTxns(tx *dbutil.Tx, txns []Un
 		return nil, err
 	}
 
+	outputs, err := vs.poolOutputs(tx)
+	if err != nil {
+		return nil, err
+	}
+
 	inputs := make([][]TransactionInput, len(txns))
 	for i, txn := range txns {
 		if len(txn.Transaction.In) == 0 {
@@ -1407,7 +1557,7 @@ func (vs *Visor) getTransactionInputsForUnconfirmedTxns(tx *dbutil.Tx, txns []Un
 			continue
 		}
 
-		txnInputs, err := vs.getTransactionInputs(tx, headTime, txn.Transaction.In)
+		txnInputs, err := vs.getTransactionInputsWithUnconfirmed(tx, headTime, txn.Transaction.In, outputs)
 		if err != nil {
 			return nil, err
 		}
@@ -1604,21 +1754,45 @@ func (vs *Visor) getBlockInputs(tx *dbutil.Tx, b *coin.SignedBlock) ([][]Transac
 // getTransactionInputs returns []TransactionInput for a given set of spent output hashes.
 // feeCalcTime is the time against which to calculate the coinhours of the output
 func (vs *Visor) getTransactionInputs(tx *dbutil.Tx, feeCalcTime uint64, inputs []cipher.SHA256) ([]TransactionInput, error) {
+	return vs.getTransactionInputsWithUnconfirmed(tx, feeCalcTime, inputs, nil)
+}
+
+// getTransactionInputsWithUnconfirmed returns []TransactionInput for a given set of spent output hashes,
+// like getTransactionInputs, but the spent outputs may also be outputs of unconfirmed transactions
+func (vs *Visor) getTransactionInputsWithUnconfirmed(tx *dbutil.Tx, feeCalcTime uint64, inputs []cipher.SHA256, outputs map[cipher.SHA256]coin.UxOut) ([]TransactionInput, error) {
 	if len(inputs) == 0 {
 		err := errors.New("getTransactionInputs: inputs is empty only the genesis block transaction has no inputs, which shouldn't call this method")
 		logger.WithError(err).Error()
 		return nil, err
 	}
 
-	uxOuts, err := vs.history.GetUxOuts(tx, inputs)
+	// The outputs of unconfirmed transactions are not in the history
+	historyInputs := inputs
+	if len(outputs) != 0 {
+		historyInputs = nil
+		for _, h := range inputs {
+			if _, ok := outputs[h]; !ok {
+				historyInputs = append(historyInputs, h)
+			}
+		}
+	}
+
+	uxOuts, err := vs.history.GetUxOuts(tx, historyInputs)
 	if err != nil {
 		logger.WithError(err).Error("getTransactionInputs GetUxOuts failed")
 		return nil, err
 	}
 
 	ret := make([]TransactionInput, len(inputs))
-	for i, o := range uxOuts {
-		r, err := NewTransactionInput(o.Out, feeCalcTime)
+	j := 0
+	for i, h := range inputs {
+		ux, ok := outputs[h]
+		if !ok {
+			ux = uxOuts[j].Out
+			j++
+		}
+
+		r, err := NewTransactionInput(ux, feeCalcTime)
 		if err != nil {
 			logger.WithError(err).Error("getTransactionInputs NewTransactionInput failed")
 			return nil, err
@@ -1810,7 +1984,12 @@ func (vs *Visor) unconfirmedSpendsOfAddresses(tx *dbutil.Tx, addrs []cipher.Addr
 		inputs = append(inputs, txn.In...)
 	}
 
-	uxa, err := vs.blockchain.Unspent().GetArray(tx, inputs)
+	outputs, err := vs.poolOutputs(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	uxa, err := getUnspentsWithUnconfirmed(tx, vs.blockchain, inputs, outputs)
 	if err != nil {
 		return nil, err
 	}
@@ -1877,8 +2056,13 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 			inputs = append(inputs, txn.In...)
 		}
 
+		outputs, err := vs.poolOutputs(tx)
+		if err != nil {
+			return err
+		}
+
 		// Get unspents for the inputs being spent
-		uxa, err = vs.blockchain.Unspent().GetArray(tx, inputs)
+		uxa, err = getUnspentsWithUnconfirmed(tx, vs.blockchain, inputs, outputs)
 		if err != nil {
 			return fmt.Errorf("GetArray failed when checking addresses balance: %v", err)
 		}
@@ -1920,7 +2104,9 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 
 		outUxs := spendUxs[addr]
 		inUxs := recvUxs[addr]
-		predictedUxs := uxs.Sub(outUxs).Add(inUxs)
+		// The outgoing outputs are subtracted after adding the incoming outputs,
+		// since they include the incoming outputs spent by other unconfirmed transactions
+		predictedUxs := uxs.Add(inUxs).Sub(outUxs)
 
 		coins, err := uxs.Coins()
 		if err != nil {
@@ -1997,7 +2183,12 @@ func (vs *Visor) VerifyTxnVerbose(txn *coin.Transaction, signed TxnSignedFlag) (
 			return err
 		}
 
-		uxa, err = vs.blockchain.Unspent().GetArray(tx, txn.In)
+		outputs, err := vs.unconfirmedOutputs(tx)
+		if err != nil {
+			return err
+		}
+
+		uxa, err = getUnspentsWithUnconfirmed(tx, vs.blockchain, txn.In, outputs)
 		switch e := err.(type) {
 		case nil:
 			// For unconfirmed transactions, use the blockchain head time to calculate hours
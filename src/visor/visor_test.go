@@ -1919,6 +1919,54 @@ func TestGetTransactions(t *testing.T) {
 	}
 }
 
+func TestAddressesActivity(t *testing.T) {
+	addrs := make([]cipher.Address, 4)
+	for i := range addrs {
+		addrs[i] = testutil.MakeAddress()
+	}
+
+	matchDBTx := mock.MatchedBy(func(tx *dbutil.Tx) bool {
+		return true
+	})
+
+	his := &MockHistoryer{}
+	uncfmTxnPool := &MockUnconfirmedTransactionPooler{}
+
+	// addrs[0] has confirmed transactions
+	his.On("GetTransactionsForAddress", matchDBTx, addrs[0]).Return([]historydb.Transaction{{BlockSeq: 1}}, nil)
+
+	// addrs[1] has only received an unconfirmed output
+	his.On("GetTransactionsForAddress", matchDBTx, addrs[1]).Return(nil, nil)
+	uncfmTxnPool.On("GetUnspentsOfAddr", matchDBTx, addrs[1]).Return(coin.UxArray{{}}, nil)
+
+	// addrs[2] has no activity
+	his.On("GetTransactionsForAddress", matchDBTx, addrs[2]).Return(nil, nil)
+	uncfmTxnPool.On("GetUnspentsOfAddr", matchDBTx, addrs[2]).Return(nil, nil)
+
+	// addrs[3] fails
+	his.On("GetTransactionsForAddress", matchDBTx, addrs[3]).Return(nil, errors.New("history error"))
+
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := &Visor{
+		db:          db,
+		history:     his,
+		unconfirmed: uncfmTxnPool,
+	}
+
+	active, err := v.AddressesActivity(addrs[:3])
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, true, false}, active)
+
+	active, err = v.AddressesActivity(nil)
+	require.NoError(t, err)
+	require.Empty(t, active)
+
+	_, err = v.AddressesActivity(addrs)
+	require.Equal(t, errors.New("history error"), err)
+}
+
 func TestRefreshUnconfirmed(t *testing.T) {
 	db, shutdown := prepareDB(t)
 	defer shutdown()
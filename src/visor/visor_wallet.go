@@ -46,6 +46,8 @@ var (
 	ErrUxOutsOrAddressesRequired = NewUserError(errors.New("UxOuts or Addresses must not be empty"))
 	// ErrNoSpendableOutputs after filtering unconfirmed spend outputs, there are no remaining outputs available for transaction creation
 	ErrNoSpendableOutputs = NewUserError(errors.New("All selected outputs are unavailable for spending"))
+	// ErrUnsignedTxnPassword a password was provided for an unsigned transaction of a wallet without the fresh change option
+	ErrUnsignedTxnPassword = NewUserError(errors.New("password must not be used for unsigned transactions"))
 )
 
 // GetWalletBalance returns balance pairs of specific wallet
@@ -142,6 +144,7 @@ func (vs *Visor) GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]Unconf
 
 // WalletSignTransaction signs a transaction. Specific inputs may be signed by specifying signIndexes.
 // If signIndexes is empty, all inputs will be signed. The transaction must be fully valid and spendable.
+// The signed transaction counts towards the wallet's spending policy even if it is not injected through this node.
 func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.Transaction, signIndexes []int) (*coin.Transaction, []TransactionInput, error) {
 	var inputs []TransactionInput
 	var signedTxn *coin.Transaction
@@ -150,13 +153,18 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 		return nil, nil, ErrTransactionAlreadySigned
 	}
 
+	spends, err := vs.wallets.RecordSpendingPolicy(wltID, txn, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if err := vs.wallets.ViewSecrets(wltID, password, func(w *wallet.Wallet) error {
 		return vs.db.View("WalletSignTransaction", func(tx *dbutil.Tx) error {
 			// Verify the transaction before signing
 			if err := VerifySingleTxnUserConstraints(*txn); err != nil {
 				return err
 			}
-			if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn, TxnUnsigned); err != nil {
+			if _, _, err := vs.verifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn, TxnUnsigned); err != nil {
 				return err
 			}
 
@@ -166,7 +174,12 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 				return err
 			}
 
-			inputs, err = vs.getTransactionInputs(tx, headTime, txn.In)
+			outputs, err := vs.unconfirmedOutputs(tx)
+			if err != nil {
+				return err
+			}
+
+			inputs, err = vs.getTransactionInputsWithUnconfirmed(tx, headTime, txn.In, outputs)
 			if err != nil {
 				return err
 			}
@@ -193,7 +206,7 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 				return err
 			}
 
-			if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, *signedTxn, params.UserVerifyTxn, signed); err != nil {
+			if _, _, err := vs.verifySingleTxnSoftHardConstraints(tx, *signedTxn, params.UserVerifyTxn, signed); err != nil {
 				// This shouldn't happen since we verified in the beginning; if it does, then wallet.SignTransaction has a bug
 				logger.Critical().WithError(err).Error("Signed transaction violates transaction constraints")
 				return err
@@ -202,12 +215,73 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 			return nil
 		})
 	}); err != nil {
+		if spends != nil {
+			spends.Discard()
+		}
 		return nil, nil, err
 	}
 
+	if spends != nil {
+		if err := spends.Save(); err != nil {
+			logger.WithError(err).Error("WalletSignTransaction: saving the spending policy spends failed")
+			return nil, nil, err
+		}
+	}
+
 	return signedTxn, inputs, nil
 }
 
+// CreatePartialTransaction creates a partially signed transaction from an unsigned or partially signed transaction.
+// The outputs spent by the transaction are looked up in the blockchain, so that the partially signed
+// transaction can be signed offline. Inputs whose addresses belong to a loaded wallet are annotated with signer hints.
+func (vs *Visor) CreatePartialTransaction(txn coin.Transaction) (*transaction.PartialTransaction, error) {
+	if txn.IsFullySigned() {
+		return nil, ErrTransactionAlreadySigned
+	}
+
+	var inputs []TransactionInput
+	if err := vs.db.View("CreatePartialTransaction", func(tx *dbutil.Tx) error {
+		if err := VerifySingleTxnUserConstraints(txn); err != nil {
+			return err
+		}
+		if _, _, err := vs.verifySingleTxnSoftHardConstraints(tx, txn, params.UserVerifyTxn, TxnUnsigned); err != nil {
+			return err
+		}
+
+		headTime, err := vs.blockchain.Time(tx)
+		if err != nil {
+			logger.WithError(err).Error("blockchain.Time failed")
+			return err
+		}
+
+		outputs, err := vs.unconfirmedOutputs(tx)
+		if err != nil {
+			return err
+		}
+
+		inputs, err = vs.getTransactionInputsWithUnconfirmed(tx, headTime, txn.In, outputs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	uxOuts := make([]coin.UxOut, len(inputs))
+	for i, in := range inputs {
+		uxOuts[i] = in.UxOut
+	}
+
+	pt, err := transaction.NewPartialTransaction(txn, uxOuts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := vs.wallets.SetPartialTransactionSigners(pt); err != nil && err != wallet.ErrWalletAPIDisabled {
+		return nil, err
+	}
+
+	return pt, nil
+}
+
 // CreateTransactionParams parameters for transaction creation
 type CreateTransactionParams struct {
 	UxOuts    []cipher.SHA256
@@ -215,6 +289,9 @@ type CreateTransactionParams struct {
 	// IgnoreUnconfirmed if true, outputs matching Addresses or UxOuts spent by
 	// an unconfirmed transactions will be ignored, otherwise an error will be returned
 	IgnoreUnconfirmed bool
+	// ApprovalPassword is the approval password of the wallet's spending policy,
+	// required if the transaction is above the approval threshold and was not approved
+	ApprovalPassword []byte
 }
 
 // Validate validates params
@@ -259,6 +336,26 @@ func (vs *Visor) WalletCreateTransactionSigned(wltID string, password []byte, p
 		return nil, nil, err
 	}
 
+	if err := vs.freshChangeAddress(wltID, password, &p); err != nil {
+		return nil, nil, err
+	}
+
+	// The spending policy must be checked before the wallet signs the transaction,
+	// so the transaction is created unsigned, checked, then signed
+	policy, err := vs.wallets.SpendingPolicy(wltID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if policy != nil {
+		// The fresh change address was already generated, so the password is not needed to create the transaction
+		txn, _, err := vs.WalletCreateTransaction(wltID, nil, p, wp)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return vs.WalletSignTransaction(wltID, password, txn, nil)
+	}
+
 	var txn *coin.Transaction
 	var inputs []TransactionInput
 
@@ -273,8 +370,10 @@ func (vs *Visor) WalletCreateTransactionSigned(wltID string, password []byte, p
 	return txn, inputs, nil
 }
 
-// WalletCreateTransaction creates a transaction based upon the parameters in CreateTransactionParams
-func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
+// WalletCreateTransaction creates an unsigned transaction based upon the parameters in CreateTransactionParams.
+// The password is only used to generate a fresh change address for an encrypted wallet with the fresh change option,
+// and must not be provided for other wallets. If provided, it is verified even if no change address is generated.
+func (vs *Visor) WalletCreateTransaction(wltID string, password []byte, p transaction.Params, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
 	// Validate params before opening wallet
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
@@ -283,6 +382,30 @@ func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp
 		return nil, nil, err
 	}
 
+	if len(password) != 0 {
+		w, err := vs.wallets.GetWallet(wltID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !w.FreshChange() {
+			return nil, nil, ErrUnsignedTxnPassword
+		}
+		if !w.IsEncrypted() {
+			return nil, nil, wallet.ErrWalletNotEncrypted
+		}
+
+		// Verify the password, so that a wrong password does not succeed if no change address is generated
+		if err := vs.wallets.ViewSecrets(wltID, password, func(*wallet.Wallet) error {
+			return nil
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := vs.freshChangeAddress(wltID, password, &p); err != nil {
+		return nil, nil, err
+	}
+
 	var txn *coin.Transaction
 	var inputs []TransactionInput
 
@@ -294,39 +417,117 @@ func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp
 		return nil, nil, err
 	}
 
+	if err := vs.wallets.CheckSpendingPolicy(wltID, txn, wp.ApprovalPassword); err != nil {
+		return nil, nil, err
+	}
+
 	return txn, inputs, nil
 }
 
-func (vs *Visor) walletCreateTransaction(methodName string, w *wallet.Wallet, p transaction.Params, wp CreateTransactionParams, signed TxnSignedFlag) (*coin.Transaction, []TransactionInput, error) {
+// WalletCreateConsolidateTransactions creates transactions which send the smallest unspent outputs of a wallet
+// to a single output of the wallet, see wallet.Wallet.CreateConsolidateTransactions.
+// Outputs spent by unconfirmed transactions are not spent.
+// If signed is TxnSigned, the transactions are signed with WalletSignTransaction, otherwise the password is not used.
+func (vs *Visor) WalletCreateConsolidateTransactions(wltID string, password []byte, p wallet.ConsolidateParams, signed TxnSignedFlag) ([]coin.Transaction, [][]TransactionInput, error) {
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
 	}
-	if err := wp.Validate(); err != nil {
+
+	var txns []coin.Transaction
+	var uxbs [][]transaction.UxBalance
+
+	if err := vs.wallets.View(wltID, func(w *wallet.Wallet) error {
+		addrs, err := w.GetSkycoinAddresses()
+		if err != nil {
+			return err
+		}
+
+		return vs.db.View("WalletCreateConsolidateTransactions", func(tx *dbutil.Tx) error {
+			head, err := vs.blockchain.Head(tx)
+			if err != nil {
+				logger.WithError(err).Error("blockchain.Head failed")
+				return err
+			}
+
+			auxs, err := vs.getCreateTransactionAuxsAddress(tx, addrs, true)
+			switch err {
+			case nil:
+			case transaction.ErrNoUnspents, ErrNoSpendableOutputs:
+				return wallet.ErrNothingToConsolidate
+			default:
+				return err
+			}
+
+			txns, uxbs, err = w.CreateConsolidateTransactions(p, auxs, head.Time())
+			if err != nil {
+				return err
+			}
+
+			for _, txn := range txns {
+				if err := VerifySingleTxnUserConstraints(txn); err != nil {
+					logger.WithError(err).Error("Created consolidate transaction violates transaction user constraints")
+					return err
+				}
+
+				if _, _, err := vs.verifySingleTxnSoftHardConstraints(tx, txn, params.UserVerifyTxn, TxnUnsigned); err != nil {
+					logger.WithError(err).Error("Created consolidate transaction violates transaction soft/hard constraints")
+					return err
+				}
+			}
+
+			return nil
+		})
+	}); err != nil {
 		return nil, nil, err
 	}
 
-	// Get all addresses from the wallet for checking params against
-	walletAddresses, err := w.GetSkycoinAddresses()
+	inputs := make([][]TransactionInput, len(txns))
+	for i := range txns {
+		switch signed {
+		case TxnSigned:
+			signedTxn, txnInputs, err := vs.WalletSignTransaction(wltID, password, &txns[i], nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			txns[i] = *signedTxn
+			inputs[i] = txnInputs
+		case TxnUnsigned:
+			inputs[i] = NewTransactionInputsFromUxBalance(uxbs[i])
+		default:
+			logger.Panic("Invalid TxnSignedFlag")
+		}
+	}
+
+	return txns, inputs, nil
+}
+
+// freshChangeAddress sets the change address of p to a fresh change address of the wallet,
+// if no change address was specified and the wallet has the fresh change option
+func (vs *Visor) freshChangeAddress(wltID string, password []byte, p *transaction.Params) error {
+	if p.ChangeAddress != nil {
+		return nil
+	}
+
+	changeAddr, err := vs.wallets.FreshChangeAddress(wltID, password, vs)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	walletAddressesMap := make(map[cipher.Address]struct{}, len(walletAddresses))
-	for _, a := range walletAddresses {
-		walletAddressesMap[a] = struct{}{}
+	p.ChangeAddress = changeAddr
+	return nil
+}
+
+func (vs *Visor) walletCreateTransaction(methodName string, w *wallet.Wallet, p transaction.Params, wp CreateTransactionParams, signed TxnSignedFlag) (*coin.Transaction, []TransactionInput, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := wp.Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	addrs := wp.Addresses
-	if len(addrs) == 0 {
-		// Use all wallet addresses if no addresses or uxouts specified
-		addrs = walletAddresses
-	} else {
-		// Check that requested addresses are in the wallet
-		for _, a := range addrs {
-			if _, ok := walletAddressesMap[a]; !ok {
-				return nil, nil, wallet.ErrUnknownAddress
-			}
-		}
+	addrs, walletAddressesMap, err := walletSpendAddresses(w, wp)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var txn *coin.Transaction
@@ -357,26 +558,9 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 	}
 
 	// Get mapping of addresses to uxOuts based upon CreateTransactionParams
-	var auxs coin.AddressUxOuts
-	if len(wp.UxOuts) != 0 {
-		var err error
-		auxs, err = vs.getCreateTransactionAuxsUxOut(tx, wp.UxOuts, wp.IgnoreUnconfirmed)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		// Check that UxOut addresses are in the wallet,
-		for a := range auxs {
-			if _, ok := walletAddressesMap[a]; !ok {
-				return nil, nil, wallet.ErrUnknownUxOut
-			}
-		}
-	} else {
-		var err error
-		auxs, err = vs.getCreateTransactionAuxsAddress(tx, addrs, wp.IgnoreUnconfirmed)
-		if err != nil {
-			return nil, nil, err
-		}
+	auxs, err := vs.getWalletCreateTransactionAuxs(tx, &p, wp, addrs, walletAddressesMap)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Create and sign transaction
@@ -405,7 +589,7 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 	// because the wallet is not aware of visor-level constraints.
 	// Check that the transaction is valid before returning it to the caller.
 	// TODO -- decimal restriction was moved to params/ package so the wallet can verify now. Move visor/verify to new package?
-	if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn, signed); err != nil {
+	if _, _, err := vs.verifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn, signed); err != nil {
 		logger.WithError(err).Error("Created transaction violates transaction soft/hard constraints")
 		return nil, nil, err
 	}
@@ -413,6 +597,164 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 	return txn, uxb, nil
 }
 
+// walletSpendAddresses returns the addresses of the wallet to spend from based upon CreateTransactionParams,
+// which are all of the wallet's addresses if none are specified, and the set of all of the wallet's addresses
+func walletSpendAddresses(w *wallet.Wallet, wp CreateTransactionParams) ([]cipher.Address, map[cipher.Address]struct{}, error) {
+	// Get all addresses from the wallet for checking params against
+	walletAddresses, err := w.GetSkycoinAddresses()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	walletAddressesMap := make(map[cipher.Address]struct{}, len(walletAddresses))
+	for _, a := range walletAddresses {
+		walletAddressesMap[a] = struct{}{}
+	}
+
+	addrs := wp.Addresses
+	if len(addrs) == 0 {
+		// Use all wallet addresses if no addresses or uxouts specified
+		addrs = walletAddresses
+	} else {
+		// Check that requested addresses are in the wallet
+		for _, a := range addrs {
+			if _, ok := walletAddressesMap[a]; !ok {
+				return nil, nil, wallet.ErrUnknownAddress
+			}
+		}
+	}
+
+	return addrs, walletAddressesMap, nil
+}
+
+// getWalletCreateTransactionAuxs returns the unspent outputs of a wallet to spend from based upon CreateTransactionParams.
+// Explicitly requested uxouts are added to p.SelectedUxOuts.
+func (vs *Visor) getWalletCreateTransactionAuxs(tx *dbutil.Tx, p *transaction.Params, wp CreateTransactionParams,
+	addrs []cipher.Address, walletAddressesMap map[cipher.Address]struct{}) (coin.AddressUxOuts, error) {
+	if len(wp.UxOuts) == 0 {
+		return vs.getCreateTransactionAuxsAddress(tx, addrs, wp.IgnoreUnconfirmed)
+	}
+
+	auxs, err := vs.getCreateTransactionAuxsUxOut(tx, wp.UxOuts, wp.IgnoreUnconfirmed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that UxOut addresses are in the wallet,
+	for a := range auxs {
+		if _, ok := walletAddressesMap[a]; !ok {
+			return nil, wallet.ErrUnknownUxOut
+		}
+	}
+
+	// Explicitly requested uxouts are spent even if they are frozen in the wallet
+	p.SelectedUxOuts = append(p.SelectedUxOuts, wp.UxOuts...)
+
+	return auxs, nil
+}
+
+// WalletCreateSplitTransactions creates the signed transactions of a spend based upon the parameters in CreateTransactionParams.
+// If a single transaction would be larger than the maximum transaction size, the spend is split into
+// consolidation transactions followed by the payment transaction, see wallet.Wallet.CreateSplitTransactionsSigned.
+// The payment transaction spends the outputs of the consolidation transactions, so it can only be injected
+// once they are confirmed, and it is not checked against the soft and hard constraints until then.
+func (vs *Visor) WalletCreateSplitTransactions(wltID string, password []byte, p transaction.Params, wp CreateTransactionParams) ([]coin.Transaction, [][]TransactionInput, error) {
+	// Validate params before unlocking wallet
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := wp.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := vs.freshChangeAddress(wltID, password, &p); err != nil {
+		return nil, nil, err
+	}
+
+	var txns []coin.Transaction
+	var uxbs [][]transaction.UxBalance
+
+	if err := vs.wallets.ViewSecrets(wltID, password, func(w *wallet.Wallet) error {
+		addrs, walletAddressesMap, err := walletSpendAddresses(w, wp)
+		if err != nil {
+			return err
+		}
+
+		return vs.db.View("WalletCreateSplitTransactions", func(tx *dbutil.Tx) error {
+			head, err := vs.blockchain.Head(tx)
+			if err != nil {
+				logger.WithError(err).Error("blockchain.Head failed")
+				return err
+			}
+
+			auxs, err := vs.getWalletCreateTransactionAuxs(tx, &p, wp, addrs, walletAddressesMap)
+			if err != nil {
+				return err
+			}
+
+			txns, uxbs, err = w.CreateSplitTransactionsSigned(p, auxs, head.Time())
+			if err != nil {
+				logger.WithError(err).Error("CreateSplitTransactionsSigned failed")
+				return err
+			}
+
+			splitOutputs := newPoolOutputs(head.Head, txns)
+			for _, txn := range txns {
+				if err := VerifySingleTxnUserConstraints(txn); err != nil {
+					logger.WithError(err).Error("Created split transaction violates transaction user constraints")
+					return err
+				}
+
+				// The payment transaction and chained consolidation transactions of a split
+				// spend the outputs of the split's other transactions, which don't exist yet
+				if len(unconfirmedParents(txn, splitOutputs)) != 0 {
+					continue
+				}
+
+				if _, _, err := vs.verifySingleTxnSoftHardConstraints(tx, txn, params.UserVerifyTxn, TxnSigned); err != nil {
+					logger.WithError(err).Error("Created split transaction violates transaction soft/hard constraints")
+					return err
+				}
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	// Unlike WalletCreateTransactionSigned, the spending policy is checked after signing, since the payment
+	// transaction spends the outputs of the signed consolidation transactions.
+	// The signed transactions are discarded if any of them is rejected, otherwise they are all recorded as spends.
+	spends := make([]*wallet.PendingSpends, 0, len(txns))
+	for i := range txns {
+		s, err := vs.wallets.RecordSpendingPolicy(wltID, &txns[i], wp.ApprovalPassword)
+		if err != nil {
+			for _, s := range spends {
+				s.Discard()
+			}
+			return nil, nil, err
+		}
+		if s != nil {
+			spends = append(spends, s)
+		}
+	}
+
+	for _, s := range spends {
+		if err := s.Save(); err != nil {
+			logger.WithError(err).Error("WalletCreateSplitTransactions: saving the spending policy spends failed")
+			return nil, nil, err
+		}
+	}
+
+	inputs := make([][]TransactionInput, len(txns))
+	for i, uxb := range uxbs {
+		inputs[i] = NewTransactionInputsFromUxBalance(uxb)
+	}
+
+	return txns, inputs, nil
+}
+
 // CreateTransaction creates an unsigned transaction from requested coin.UxOut hashes
 func (vs *Visor) CreateTransaction(p transaction.Params, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
 	// Validate parameters before starting database transaction
@@ -475,7 +817,7 @@ func (vs *Visor) createTransactionTx(tx *dbutil.Tx, p transaction.Params, wp Cre
 	// because the wallet is not aware of visor-level constraints.
 	// Check that the transaction is valid before returning it to the caller.
 	// TODO -- decimal restriction was moved to params/ package so the wallet can verify now. Move visor/verify to new package?
-	if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn, TxnUnsigned); err != nil {
+	if _, _, err := vs.verifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn, TxnUnsigned); err != nil {
 		logger.WithError(err).Error("Created transaction violates transaction soft/hard constraints")
 		return nil, nil, err
 	}
@@ -487,6 +829,7 @@ func (vs *Visor) createTransactionTx(tx *dbutil.Tx, p transaction.Params, wp Cre
 // given a list of unspent output hashes.
 // If ignoreUnconfirmed is true, outputs being spent by unconfirmed transactions are ignored and excluded from the return value.
 // If ignoreUnconfirmed is false, an error is return if any of the specified unspent outputs are spent by an unconfirmed transaction.
+// If Config.SpendUnconfirmed is enabled, the outputs may also be outputs created by unconfirmed transactions.
 func (vs *Visor) getCreateTransactionAuxsUxOut(tx *dbutil.Tx, uxOutHashes []cipher.SHA256, ignoreUnconfirmed bool) (coin.AddressUxOuts, error) {
 	hashesMap := make(map[cipher.SHA256]struct{}, len(uxOutHashes))
 	for _, h := range uxOutHashes {
@@ -530,9 +873,14 @@ func (vs *Visor) getCreateTransactionAuxsUxOut(tx *dbutil.Tx, uxOutHashes []ciph
 		return nil, ErrNoSpendableOutputs
 	}
 
+	outputs, err := vs.unconfirmedOutputs(tx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Retrieve the uxouts from the pool.
 	// An error is returned if any do not exist
-	uxOuts, err := vs.blockchain.Unspent().GetArray(tx, uxOutHashes)
+	uxOuts, err := getUnspentsWithUnconfirmed(tx, vs.blockchain, uxOutHashes, outputs)
 	if err != nil {
 		return nil, err
 	}
@@ -542,7 +890,10 @@ func (vs *Visor) getCreateTransactionAuxsUxOut(tx *dbutil.Tx, uxOutHashes []ciph
 }
 
 // getCreateTransactionAuxsAddress returns a map of the addresses to their unspent outputs,
-// filtering or erroring on unconfirmed outputs depending on the value of ignoreUnconfirmed
+// filtering or erroring on unconfirmed outputs depending on the value of ignoreUnconfirmed.
+// If Config.SpendUnconfirmed is enabled, the outputs created for the addresses by unconfirmed transactions are included,
+// and the outputs spent by unconfirmed transactions are always ignored, since the transactions spending them
+// may have created the outputs to spend instead
 func (vs *Visor) getCreateTransactionAuxsAddress(tx *dbutil.Tx, addrs []cipher.Address, ignoreUnconfirmed bool) (coin.AddressUxOuts, error) {
 	// Get all address unspent hashes
 	addrHashes, err := vs.blockchain.Unspent().GetUnspentHashesOfAddrs(tx, addrs)
@@ -551,6 +902,32 @@ func (vs *Visor) getCreateTransactionAuxsAddress(tx *dbutil.Tx, addrs []cipher.A
 	}
 
 	hashes := addrHashes.Flatten()
+
+	if vs.Config.SpendUnconfirmed {
+		head, err := vs.blockchain.Head(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		recvUxs, err := vs.unconfirmed.RecvOfAddresses(tx, unconfirmedOutputsHeader(head.Head), addrs)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only the outputs of valid unconfirmed transactions can be spent
+		outputs, err := vs.unconfirmedOutputs(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range recvUxs.Flatten().Hashes() {
+			if _, ok := outputs[h]; ok {
+				hashes = append(hashes, h)
+			}
+		}
+		ignoreUnconfirmed = true
+	}
+
 	if len(hashes) == 0 {
 		return nil, transaction.ErrNoUnspents
 	}
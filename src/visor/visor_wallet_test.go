@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -373,6 +374,11 @@ func TestWalletCreateTransaction(t *testing.T) {
 		},
 	}
 
+	// With a change address, no fresh change address is generated
+	changeAddress := testutil.MakeAddress()
+	validParamsChangeAddress := validParams
+	validParamsChangeAddress.ChangeAddress = &changeAddress
+
 	insufficientBalanceParams := transaction.Params{
 		HoursSelection: transaction.HoursSelection{
 			Type: transaction.HoursSelectionTypeManual,
@@ -474,6 +480,15 @@ func TestWalletCreateTransaction(t *testing.T) {
 		password []byte
 		err      error
 
+		frozenUxOuts   []cipher.SHA256
+		freshChange    bool
+		spendingLimits *wallet.SpendingLimits
+
+		// noPassword omits the password of the encrypted wallet when creating the transaction
+		noPassword bool
+		// wrongPassword provides a wrong password of the encrypted wallet when creating the transaction
+		wrongPassword bool
+
 		blockchainHead    *coin.SignedBlock
 		blockchainHeadErr error
 
@@ -538,6 +553,85 @@ func TestWalletCreateTransaction(t *testing.T) {
 			inputs:         inputs,
 		},
 
+		{
+			name:           "all wallet addresses, frozen uxouts",
+			p:              validParams,
+			wp:             CreateTransactionParams{},
+			walletID:       "foo.wlt",
+			frozenUxOuts:   []cipher.SHA256{uxOuts[1]},
+			blockchainHead: headBlock,
+			getUnspentHashesOfAddrs: blockdb.AddressHashes{
+				addrs[1]: uxOuts,
+			},
+			getArrayInputs: uxOuts,
+			getArray:       getArrayRet,
+			err:            transaction.ErrUnspentsFrozen,
+		},
+
+		{
+			name: "specific uxouts, frozen uxouts",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			walletID:       "foo.wlt",
+			frozenUxOuts:   []cipher.SHA256{uxOuts[1]},
+			blockchainHead: headBlock,
+			getArrayInputs: uxOuts,
+			getArray:       getArrayRet,
+			txn:            txn,
+			inputs:         inputs,
+		},
+
+		{
+			name: "specific uxouts, fresh change",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			walletID:       "foo.wlt",
+			freshChange:    true,
+			blockchainHead: headBlock,
+			getArrayInputs: uxOuts,
+			getArray:       getArrayRet,
+			txn:            txn,
+			inputs:         inputs,
+		},
+
+		{
+			name: "specific uxouts, spending policy max coins exceeded",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			walletID: "foo.wlt",
+			spendingLimits: &wallet.SpendingLimits{
+				MaxCoinsPerTransaction: 1e6 - 1,
+			},
+			blockchainHead: headBlock,
+			getArrayInputs: uxOuts,
+			getArray:       getArrayRet,
+			txn:            txn,
+			err:            wallet.ErrSpendingPolicyMaxCoinsExceeded,
+		},
+
+		{
+			name: "specific uxouts, spending policy approval required",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			walletID: "foo.wlt",
+			spendingLimits: &wallet.SpendingLimits{
+				ApprovalThreshold: 1e6 - 1,
+			},
+			blockchainHead: headBlock,
+			getArrayInputs: uxOuts,
+			getArray:       getArrayRet,
+			txn:            txn,
+			err:            wallet.ErrSpendingPolicyApprovalRequired,
+		},
+
 		{
 			name: "unknown wallet address",
 			p:    validParams,
@@ -632,6 +726,76 @@ func TestWalletCreateTransaction(t *testing.T) {
 		cases[i+len(baseCases)].password = []byte("foo")
 	}
 
+	// The password of an encrypted wallet is used by unsigned transactions to generate a fresh change address
+	cases = append(cases, []testCase{
+		{
+			name: "encrypted wallet, specific uxouts, fresh change",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			signed:         TxnUnsigned,
+			walletID:       "foo.wlt",
+			password:       []byte("foo"),
+			freshChange:    true,
+			blockchainHead: headBlock,
+			getArrayInputs: uxOuts,
+			getArray:       getArrayRet,
+			txn:            txn,
+			inputs:         inputs,
+		},
+		{
+			name: "encrypted wallet, specific uxouts, fresh change, no password",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			signed:      TxnUnsigned,
+			walletID:    "foo.wlt",
+			password:    []byte("foo"),
+			noPassword:  true,
+			freshChange: true,
+			err:         wallet.ErrFreshChangeEncrypted,
+		},
+		{
+			name: "encrypted wallet, specific uxouts, fresh change, wrong password",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			signed:        TxnUnsigned,
+			walletID:      "foo.wlt",
+			password:      []byte("foo"),
+			wrongPassword: true,
+			freshChange:   true,
+			err:           wallet.ErrInvalidPassword,
+		},
+		{
+			name: "encrypted wallet, specific uxouts, fresh change, change address, wrong password",
+			p:    validParamsChangeAddress,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			signed:        TxnUnsigned,
+			walletID:      "foo.wlt",
+			password:      []byte("foo"),
+			wrongPassword: true,
+			freshChange:   true,
+			err:           wallet.ErrInvalidPassword,
+		},
+		{
+			name: "encrypted wallet, specific uxouts, password without fresh change",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				UxOuts: uxOuts,
+			},
+			signed:   TxnUnsigned,
+			walletID: "foo.wlt",
+			password: []byte("foo"),
+			err:      ErrUnsignedTxnPassword,
+		},
+	}...)
+
 	for _, tc := range cases {
 		name := fmt.Sprintf("signed-flag=%d %s", tc.signed, tc.name)
 		t.Run(name, func(t *testing.T) {
@@ -663,6 +827,21 @@ func TestWalletCreateTransaction(t *testing.T) {
 			})
 			require.NoError(t, err)
 
+			if len(tc.frozenUxOuts) != 0 {
+				_, err = ws.FreezeUxOuts(tc.walletID, tc.frozenUxOuts)
+				require.NoError(t, err)
+			}
+
+			if tc.freshChange {
+				_, err = ws.SetFreshChange(tc.walletID, true)
+				require.NoError(t, err)
+			}
+
+			if tc.spendingLimits != nil {
+				_, err = ws.SetSpendingPolicy(tc.walletID, *tc.spendingLimits, nil, []byte("approve"))
+				require.NoError(t, err)
+			}
+
 			walletAddrs, err := ws.GetSkycoinAddresses(tc.walletID)
 			require.NoError(t, err)
 
@@ -710,6 +889,11 @@ func TestWalletCreateTransaction(t *testing.T) {
 				b.On("VerifySingleTxnSoftHardConstraints", matchDBTx, matchTxnIgnoreSigs, params.UserVerifyTxn, tc.signed).Return(nil, nil, tc.verifyErr)
 			}
 
+			// With a spending policy, the transaction is created unsigned and checked before it is signed
+			if tc.spendingLimits != nil && tc.signed == TxnSigned {
+				b.On("VerifySingleTxnSoftHardConstraints", matchDBTx, *tc.txn, params.UserVerifyTxn, TxnUnsigned).Return(nil, nil, tc.verifyErr)
+			}
+
 			db, shutdown := prepareDB(t)
 			defer shutdown()
 
@@ -720,13 +904,21 @@ func TestWalletCreateTransaction(t *testing.T) {
 				wallets:     ws,
 			}
 
+			password := tc.password
+			if tc.noPassword {
+				password = nil
+			}
+			if tc.wrongPassword {
+				password = []byte("wrong")
+			}
+
 			var txn *coin.Transaction
 			var inputs []TransactionInput
 			switch tc.signed {
 			case TxnSigned:
-				txn, inputs, err = v.WalletCreateTransactionSigned(tc.walletID, tc.password, tc.p, tc.wp)
+				txn, inputs, err = v.WalletCreateTransactionSigned(tc.walletID, password, tc.p, tc.wp)
 			case TxnUnsigned:
-				txn, inputs, err = v.WalletCreateTransaction(tc.walletID, tc.p, tc.wp)
+				txn, inputs, err = v.WalletCreateTransaction(tc.walletID, password, tc.p, tc.wp)
 			default:
 				t.Fatal("invalid tc.signed value")
 			}
@@ -743,6 +935,19 @@ func TestWalletCreateTransaction(t *testing.T) {
 
 			require.Equal(t, tc.txn, txn)
 			require.Equal(t, tc.inputs, inputs)
+
+			// A fresh change address is generated for the transaction
+			w, err := ws.GetWallet(tc.walletID)
+			require.NoError(t, err)
+			if tc.freshChange {
+				require.Len(t, w.Entries, len(walletAddrs)+1)
+				require.Equal(t, wallet.EntryPurposeChange, w.Entries[len(walletAddrs)].Purpose)
+
+				// The change address is not given to another transaction
+				require.Equal(t, []cipher.Address{w.Entries[len(walletAddrs)].SkycoinAddress()}, w.IssuedChangeAddresses())
+			} else {
+				require.Len(t, w.Entries, len(walletAddrs))
+			}
 		})
 	}
 }
@@ -857,7 +1062,7 @@ func TestWalletCreateTransactionValidation(t *testing.T) {
 			// setup visor
 			v := &Visor{}
 
-			_, _, err := v.WalletCreateTransaction("foo.wlt", tc.p, tc.wp)
+			_, _, err := v.WalletCreateTransaction("foo.wlt", nil, tc.p, tc.wp)
 			require.Equal(t, tc.err, err)
 
 			_, _, err = v.WalletCreateTransactionSigned("foo.wlt", nil, tc.p, tc.wp)
@@ -1372,3 +1577,130 @@ func unconfirmedForEachMockRun(t *testing.T, unconfirmedTxns []coin.Transaction,
 		}
 	}
 }
+
+func TestWalletCreateConsolidateTransactions(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v, wltAddr := prepareFundedWalletVisor(t, db)
+
+	_, _, err := v.WalletCreateConsolidateTransactions("a.wlt", nil, wallet.ConsolidateParams{MaxTransactions: 1}, TxnUnsigned)
+	require.Equal(t, wallet.ErrNothingToConsolidate, err)
+
+	// Send a second output to the wallet
+	headOutputs, err := v.GetUnspentsOfAddrs([]cipher.Address{genAddress})
+	require.NoError(t, err)
+	txn := makeSpendTxn(t, headOutputs[genAddress], []cipher.SecKey{genSecret}, wltAddr, 100e6)
+	_, _, err = v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	createAndExecuteBlockAt(t, v, uint64(time.Now().UTC().Unix()+10))
+
+	auxs, err := v.GetUnspentsOfAddrs([]cipher.Address{wltAddr})
+	require.NoError(t, err)
+	require.Len(t, auxs[wltAddr], 2)
+
+	_, _, err = v.WalletCreateConsolidateTransactions("a.wlt", nil, wallet.ConsolidateParams{}, TxnUnsigned)
+	require.Equal(t, wallet.ErrInvalidConsolidateMaxTransactions, err)
+
+	_, _, err = v.WalletCreateConsolidateTransactions("b.wlt", nil, wallet.ConsolidateParams{MaxTransactions: 1}, TxnUnsigned)
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	txns, inputs, err := v.WalletCreateConsolidateTransactions("a.wlt", nil, wallet.ConsolidateParams{MaxTransactions: 1}, TxnUnsigned)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	require.True(t, txns[0].IsFullyUnsigned())
+	require.Len(t, txns[0].In, 2)
+	require.Len(t, inputs[0], 2)
+	require.Equal(t, uint64(600e6), txns[0].Out[0].Coins)
+	require.Equal(t, wltAddr, txns[0].Out[0].Address)
+
+	txns, inputs, err = v.WalletCreateConsolidateTransactions("a.wlt", nil, wallet.ConsolidateParams{MaxTransactions: 1}, TxnSigned)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	require.True(t, txns[0].IsFullySigned())
+	require.Len(t, inputs[0], 2)
+
+	known, _, _, err := v.InjectUserTransaction(txns[0])
+	require.False(t, known)
+	require.NoError(t, err)
+
+	// Outputs spent by unconfirmed transactions are not consolidated
+	_, _, err = v.WalletCreateConsolidateTransactions("a.wlt", nil, wallet.ConsolidateParams{MaxTransactions: 1}, TxnUnsigned)
+	require.Equal(t, wallet.ErrNothingToConsolidate, err)
+}
+
+func TestWalletCreateSplitTransactions(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v, wltAddr := prepareFundedWalletVisor(t, db)
+
+	// Send a second output to the wallet
+	headOutputs, err := v.GetUnspentsOfAddrs([]cipher.Address{genAddress})
+	require.NoError(t, err)
+	txn := makeSpendTxn(t, headOutputs[genAddress], []cipher.SecKey{genSecret}, wltAddr, 100e6)
+	_, _, err = v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	createAndExecuteBlockAt(t, v, uint64(time.Now().UTC().Unix()+10))
+
+	destAddr := testutil.MakeAddress()
+	shareFactor := decimal.New(5, -1)
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type:        transaction.HoursSelectionTypeAuto,
+			Mode:        transaction.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: destAddr,
+				Coins:   550e6,
+			},
+		},
+	}
+
+	_, _, err = v.WalletCreateSplitTransactions("a.wlt", nil, transaction.Params{}, CreateTransactionParams{})
+	require.Equal(t, transaction.ErrMissingReceivers, err)
+
+	_, _, err = v.WalletCreateSplitTransactions("b.wlt", nil, p, CreateTransactionParams{})
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	// A spend which fits in a transaction is not split
+	txns, inputs, err := v.WalletCreateSplitTransactions("a.wlt", nil, p, CreateTransactionParams{})
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	require.Len(t, inputs[0], 2)
+	require.True(t, txns[0].IsFullySigned())
+
+	// Lower the maximum transaction size so that the spend's two inputs are consolidated first
+	consolidateSize, err := (&coin.Transaction{
+		In:   make([]cipher.SHA256, 2),
+		Sigs: make([]cipher.Sig, 2),
+		Out:  make([]coin.TransactionOutput, 1),
+	}).Size()
+	require.NoError(t, err)
+	maxSize := params.UserVerifyTxn.MaxTransactionSize
+	params.UserVerifyTxn.MaxTransactionSize = consolidateSize
+	defer func() {
+		params.UserVerifyTxn.MaxTransactionSize = maxSize
+	}()
+
+	txns, inputs, err = v.WalletCreateSplitTransactions("a.wlt", nil, p, CreateTransactionParams{})
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+	require.Len(t, inputs[0], 2)
+	require.Len(t, inputs[1], 1)
+	require.Equal(t, wltAddr, txns[0].Out[0].Address)
+	require.Equal(t, uint64(600e6), txns[0].Out[0].Coins)
+	require.Equal(t, txns[0].Out[0].Hours, inputs[1][0].CalculatedHours)
+	require.Equal(t, destAddr, txns[1].Out[0].Address)
+	require.Equal(t, uint64(550e6), txns[1].Out[0].Coins)
+
+	// The payment can be injected once the consolidation is confirmed
+	_, _, err = v.InjectForeignTransaction(txns[0])
+	require.NoError(t, err)
+	createAndExecuteBlockAt(t, v, uint64(time.Now().UTC().Unix()+20))
+
+	_, _, err = v.InjectForeignTransaction(txns[1])
+	require.NoError(t, err)
+}
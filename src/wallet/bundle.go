@@ -0,0 +1,271 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// BundleType is the type of wallet export bundles
+	BundleType = "skycoin-wallet-bundle"
+	// BundleVersion is the version of the wallet export bundle format
+	BundleVersion = "1"
+)
+
+var (
+	// ErrInvalidBundle is returned if a wallet export bundle is malformed
+	ErrInvalidBundle = NewError(errors.New("invalid wallet bundle"))
+	// ErrUnsupportedBundleVersion is returned if a wallet export bundle has an unknown version
+	ErrUnsupportedBundleVersion = NewError(errors.New("unsupported wallet bundle version"))
+	// ErrInvalidBundlePassword is returned if a wallet export bundle can't be decrypted with the password
+	ErrInvalidBundlePassword = NewError(errors.New("invalid wallet bundle password"))
+	// ErrMissingBundlePassword is returned if no password is provided to encrypt or decrypt a wallet export bundle
+	ErrMissingBundlePassword = NewError(errors.New("missing wallet bundle password"))
+	// ErrNoWalletsToExport is returned if no wallets are exported
+	ErrNoWalletsToExport = NewError(errors.New("no wallets to export"))
+)
+
+// Bundle is the content of a wallet export bundle: wallets, copied with their labels, metadata
+// and encryption, and the transaction notes related to them
+type Bundle struct {
+	Created int64             `json:"created"`
+	Wallets []*ReadableWallet `json:"wallets"`
+	// Notes are the transaction notes of the kvstorage, by transaction ID
+	Notes map[string]string `json:"transaction_notes,omitempty"`
+}
+
+// EncryptedBundle is a password encrypted Bundle. It is self-describing, so that it can be
+// saved to a file and imported by another node
+type EncryptedBundle struct {
+	Type       string     `json:"type"`
+	Version    string     `json:"version"`
+	CryptoType CryptoType `json:"crypto_type"`
+	Data       string     `json:"data"`
+}
+
+// Encrypt encrypts the bundle with password
+func (b *Bundle) Encrypt(password []byte, cryptoType CryptoType) (*EncryptedBundle, error) {
+	if len(password) == 0 {
+		return nil, ErrMissingBundlePassword
+	}
+
+	c, err := getCrypto(cryptoType)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range data {
+			data[i] = 0
+		}
+	}()
+
+	encData, err := c.Encrypt(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedBundle{
+		Type:       BundleType,
+		Version:    BundleVersion,
+		CryptoType: cryptoType,
+		Data:       string(encData),
+	}, nil
+}
+
+// Decrypt decrypts the bundle with password
+func (eb *EncryptedBundle) Decrypt(password []byte) (*Bundle, error) {
+	if eb.Type != BundleType {
+		return nil, ErrInvalidBundle
+	}
+
+	if eb.Version != BundleVersion {
+		return nil, ErrUnsupportedBundleVersion
+	}
+
+	if len(password) == 0 {
+		return nil, ErrMissingBundlePassword
+	}
+
+	c, err := getCrypto(eb.CryptoType)
+	if err != nil {
+		return nil, ErrInvalidBundle
+	}
+
+	data, err := c.Decrypt([]byte(eb.Data), password)
+	if err != nil {
+		return nil, ErrInvalidBundlePassword
+	}
+	defer func() {
+		for i := range data {
+			data[i] = 0
+		}
+	}()
+
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, ErrInvalidBundle
+	}
+
+	return &b, nil
+}
+
+// ImportedWallet is the result of the import of a wallet of a Bundle
+type ImportedWallet struct {
+	// Filename is the filename of the wallet in the bundle
+	Filename string
+	// ImportedAs is the filename of the imported wallet, which differs from Filename if it was renamed.
+	// It is empty if the wallet was not imported.
+	ImportedAs string
+	// DuplicateOf is the loaded wallet with the same seed, if the wallet was not imported
+	DuplicateOf string
+}
+
+// ExportWallets creates a Bundle with the wallets, which are copied unchanged.
+// The seed API must be enabled, since the bundle contains the seeds of unencrypted wallets.
+func (serv *Service) ExportWallets(wltIDs []string) (*Bundle, error) {
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if !serv.config.EnableSeedAPI {
+		return nil, ErrSeedAPIDisabled
+	}
+
+	if len(wltIDs) == 0 {
+		return nil, ErrNoWalletsToExport
+	}
+
+	b := &Bundle{
+		Created: time.Now().Unix(),
+	}
+
+	for _, id := range wltIDs {
+		w, err := serv.getWallet(id)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Wallets = append(b.Wallets, NewReadableWallet(w))
+	}
+
+	return b, nil
+}
+
+// ImportWallets imports the wallets of a Bundle into the wallet directory and loads them.
+// A wallet whose filename is already used is renamed. A wallet with the same seed
+// as a loaded wallet is not imported.
+func (serv *Service) ImportWallets(b *Bundle) ([]ImportedWallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	// Verify all the wallets before importing any
+	wlts := make([]*Wallet, len(b.Wallets))
+	for i, rw := range b.Wallets {
+		if rw == nil {
+			return nil, ErrInvalidBundle
+		}
+
+		filename := filepath.Base(rw.filename())
+		if !strings.HasSuffix(filename, "."+WalletExt) {
+			return nil, ErrInvalidBundle
+		}
+
+		// Copies the meta, which the wallet shares with the bundle and is changed when renaming the wallet
+		meta := make(map[string]string, len(rw.Meta))
+		for k, v := range rw.Meta {
+			meta[k] = v
+		}
+
+		w, err := (&ReadableWallet{
+			Meta:    meta,
+			Entries: rw.Entries,
+		}).ToWallet()
+		if err != nil {
+			return nil, NewError(err)
+		}
+
+		if w.coin() != CoinTypeSkycoin {
+			return nil, NewError(fmt.Errorf("wallet %s of the bundle is a %s wallet, only skycoin wallets can be imported", filename, w.coin()))
+		}
+
+		if len(w.Entries) == 0 && w.Type() != WalletTypeCollection {
+			return nil, NewError(fmt.Errorf("wallet %s of the bundle is empty", filename))
+		}
+
+		w.setFilename(filename)
+		wlts[i] = w
+	}
+
+	results := make([]ImportedWallet, len(wlts))
+	for i, w := range wlts {
+		results[i].Filename = w.Filename()
+
+		// Detects a wallet with the same seed among the loaded wallets
+		candidates := make(Wallets, len(serv.wallets)+1)
+		for id, lw := range serv.wallets {
+			candidates[id] = lw
+		}
+		candidates[""] = w
+		if _, addr, dup := candidates.containsDuplicate(); dup {
+			results[i].DuplicateOf = serv.firstAddrIDMap[addr.String()]
+			continue
+		}
+
+		filename, err := serv.uniqueWalletFilename(w.Filename())
+		if err != nil {
+			return nil, err
+		}
+		w.setFilename(filename)
+
+		if err := w.Save(serv.config.WalletDir); err != nil {
+			return nil, err
+		}
+
+		serv.wallets.set(w)
+		if w.Type() != WalletTypeCollection {
+			serv.firstAddrIDMap[w.Entries[0].Address.String()] = filename
+		}
+
+		results[i].ImportedAs = filename
+	}
+
+	return results, nil
+}
+
+// uniqueWalletFilename returns filename if no loaded wallet or file in the wallet directory uses it,
+// otherwise the first unused filename with a numeric suffix, e.g. foo_1.wlt
+func (serv *Service) uniqueWalletFilename(filename string) (string, error) {
+	base := strings.TrimSuffix(filename, "."+WalletExt)
+	for i := 0; ; i++ {
+		if i > 0 {
+			filename = fmt.Sprintf("%s_%d.%s", base, i, WalletExt)
+		}
+
+		if serv.wallets.get(filename) != nil {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(serv.config.WalletDir, filename)); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		return filename, nil
+	}
+}
@@ -0,0 +1,185 @@
+package wallet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleEncryptDecrypt(t *testing.T) {
+	w, err := NewWallet("t.wlt", Options{
+		Seed:  "seed",
+		Label: "foo",
+	})
+	require.NoError(t, err)
+
+	b := &Bundle{
+		Created: 100,
+		Wallets: []*ReadableWallet{NewReadableWallet(w)},
+		Notes: map[string]string{
+			"txid": "note",
+		},
+	}
+
+	for _, ct := range []CryptoType{CryptoTypeSha256Xor, CryptoTypeScryptChacha20poly1305} {
+		t.Run(string(ct), func(t *testing.T) {
+			eb, err := b.Encrypt([]byte("pwd"), ct)
+			require.NoError(t, err)
+			require.Equal(t, BundleType, eb.Type)
+			require.Equal(t, BundleVersion, eb.Version)
+			require.Equal(t, ct, eb.CryptoType)
+			require.NotContains(t, eb.Data, "seed")
+
+			b2, err := eb.Decrypt([]byte("pwd"))
+			require.NoError(t, err)
+			require.Equal(t, b, b2)
+
+			_, err = eb.Decrypt([]byte("wrong"))
+			require.Equal(t, ErrInvalidBundlePassword, err)
+
+			_, err = eb.Decrypt(nil)
+			require.Equal(t, ErrMissingBundlePassword, err)
+
+			eb2 := *eb
+			eb2.Version = "2"
+			_, err = eb2.Decrypt([]byte("pwd"))
+			require.Equal(t, ErrUnsupportedBundleVersion, err)
+
+			eb2 = *eb
+			eb2.Type = "foo"
+			_, err = eb2.Decrypt([]byte("pwd"))
+			require.Equal(t, ErrInvalidBundle, err)
+		})
+	}
+
+	_, err = b.Encrypt(nil, CryptoTypeSha256Xor)
+	require.Equal(t, ErrMissingBundlePassword, err)
+}
+
+func TestServiceExportImportWallets(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+		EnableSeedAPI:   true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("a.wlt", Options{
+		Seed:  "seed-a",
+		Label: "a",
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("b.wlt", Options{
+		Seed:     "seed-b",
+		Label:    "b",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = s.ExportWallets(nil)
+	require.Equal(t, ErrNoWalletsToExport, err)
+
+	_, err = s.ExportWallets([]string{"a.wlt", "c.wlt"})
+	require.Equal(t, ErrWalletNotExist, err)
+
+	b, err := s.ExportWallets([]string{"a.wlt", "b.wlt"})
+	require.NoError(t, err)
+	require.Len(t, b.Wallets, 2)
+	require.Equal(t, "seed-a", b.Wallets[0].Meta[metaSeed])
+	// Encrypted wallets are exported encrypted
+	require.Equal(t, "true", b.Wallets[1].Meta[metaEncrypted])
+	require.Empty(t, b.Wallets[1].Meta[metaSeed])
+
+	// Importing into the same service skips both wallets, which have the same seeds
+	results, err := s.ImportWallets(b)
+	require.NoError(t, err)
+	require.Equal(t, []ImportedWallet{
+		{Filename: "a.wlt", DuplicateOf: "a.wlt"},
+		{Filename: "b.wlt", DuplicateOf: "b.wlt"},
+	}, results)
+
+	// Importing into another service renames the wallets whose filename is used
+	dir2 := prepareWltDir()
+	s2, err := NewService(Config{
+		WalletDir:       dir2,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s2.CreateWallet("a.wlt", Options{
+		Seed: "seed-c",
+	}, nil)
+	require.NoError(t, err)
+
+	// A file in the wallet directory which is not loaded is not overwritten
+	f, err := os.Create(filepath.Join(dir2, "a_1.wlt"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	results, err = s2.ImportWallets(b)
+	require.NoError(t, err)
+	require.Equal(t, []ImportedWallet{
+		{Filename: "a.wlt", ImportedAs: "a_2.wlt"},
+		{Filename: "b.wlt", ImportedAs: "b.wlt"},
+	}, results)
+
+	w, err := s2.GetWallet("a_2.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "a", w.Label())
+	require.Equal(t, "seed-a", w.seed())
+
+	w, err = s2.GetWallet("b.wlt")
+	require.NoError(t, err)
+	require.True(t, w.IsEncrypted())
+
+	_, err = s2.ExportWallets([]string{"b.wlt"})
+	require.Equal(t, ErrSeedAPIDisabled, err)
+
+	// The imported wallets are saved
+	for _, fn := range []string{"a_2.wlt", "b.wlt"} {
+		_, err := os.Stat(filepath.Join(dir2, fn))
+		require.NoError(t, err)
+	}
+
+	// The imported wallets are detected as duplicates
+	results, err = s2.ImportWallets(b)
+	require.NoError(t, err)
+	require.Equal(t, []ImportedWallet{
+		{Filename: "a.wlt", DuplicateOf: "a_2.wlt"},
+		{Filename: "b.wlt", DuplicateOf: "b.wlt"},
+	}, results)
+
+	// No wallet is imported if one of them is invalid
+	w3, err := NewWallet("c.wlt", Options{
+		Seed: "seed-d",
+	})
+	require.NoError(t, err)
+	invalid := NewReadableWallet(w3)
+	invalid.Meta[metaFilename] = "c"
+	_, err = s2.ImportWallets(&Bundle{
+		Wallets: []*ReadableWallet{NewReadableWallet(w3), invalid},
+	})
+	require.Equal(t, ErrInvalidBundle, err)
+	_, err = s2.GetWallet("c.wlt")
+	require.Equal(t, ErrWalletNotExist, err)
+
+	s2, err = NewService(Config{
+		WalletDir:       dir2,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+
+	_, err = s2.ExportWallets([]string{"a.wlt"})
+	require.Equal(t, ErrWalletAPIDisabled, err)
+
+	_, err = s2.ImportWallets(b)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// ParseSecKey parses a secret key that is either hex encoded or in the Bitcoin wallet import format (WIF)
+func ParseSecKey(s string) (cipher.SecKey, error) {
+	if sk, err := cipher.SecKeyFromHex(s); err == nil {
+		return sk, nil
+	}
+
+	sk, err := cipher.SecKeyFromBitcoinWalletImportFormat(s)
+	if err != nil {
+		return cipher.SecKey{}, ErrInvalidSecKeyFormat
+	}
+
+	return sk, nil
+}
+
+// ImportSecKeys adds entries for the given secret keys to a collection wallet.
+// No entries are added if any of the keys is invalid or already in the wallet.
+// Returns the addresses of the imported keys.
+func (w *Wallet) ImportSecKeys(keys []cipher.SecKey) ([]cipher.Addresser, error) {
+	if w.IsEncrypted() {
+		return nil, ErrWalletEncrypted
+	}
+
+	if w.Type() != WalletTypeCollection {
+		return nil, ErrWalletNotCollection
+	}
+
+	existing := make(map[cipher.Addresser]struct{}, len(w.Entries)+len(keys))
+	for _, e := range w.Entries {
+		existing[e.Address] = struct{}{}
+	}
+
+	makeAddress := w.addressConstructor()
+	addrs := make([]cipher.Addresser, 0, len(keys))
+	entries := make([]Entry, 0, len(keys))
+	created := time.Now().Unix()
+	for _, sk := range keys {
+		p, err := cipher.PubKeyFromSecKey(sk)
+		if err != nil {
+			return nil, NewError(fmt.Errorf("invalid secret key: %v", err))
+		}
+
+		a := makeAddress(p)
+		if _, ok := existing[a]; ok {
+			return nil, NewError(fmt.Errorf("address %s is already in the wallet", a))
+		}
+		existing[a] = struct{}{}
+
+		addrs = append(addrs, a)
+		entries = append(entries, Entry{
+			Address: a,
+			Public:  p,
+			Secret:  sk,
+			Created: created,
+			Purpose: EntryPurposeReceive,
+		})
+	}
+
+	w.Entries = append(w.Entries, entries...)
+
+	return addrs, nil
+}
+
+// RemoveEntries removes the entries of the given addresses from a collection wallet.
+// No entries are removed if any of the addresses is not in the wallet.
+// The wallet must be unlocked, so that the removed secret keys are not left in the encrypted secrets.
+func (w *Wallet) RemoveEntries(addrs []cipher.Addresser) error {
+	if w.IsEncrypted() {
+		return ErrWalletEncrypted
+	}
+
+	if w.Type() != WalletTypeCollection {
+		return ErrWalletNotCollection
+	}
+
+	remove := make(map[cipher.Addresser]struct{}, len(addrs))
+	for _, a := range addrs {
+		remove[a] = struct{}{}
+	}
+
+	entries := make([]Entry, 0, len(w.Entries))
+	for _, e := range w.Entries {
+		if _, ok := remove[e.Address]; ok {
+			delete(remove, e.Address)
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	for _, a := range addrs {
+		if _, ok := remove[a]; ok {
+			return NewError(fmt.Errorf("address %s is not in the wallet", a))
+		}
+	}
+
+	w.Entries = entries
+
+	return nil
+}
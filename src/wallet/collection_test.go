@@ -0,0 +1,193 @@
+package wallet
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestParseSecKey(t *testing.T) {
+	_, sk := cipher.GenerateKeyPair()
+
+	tt := []struct {
+		name string
+		key  string
+		err  error
+	}{
+		{
+			name: "hex",
+			key:  sk.Hex(),
+		},
+		{
+			name: "bitcoin wallet import format",
+			key:  cipher.BitcoinWalletImportFormatFromSeckey(sk),
+		},
+		{
+			name: "invalid",
+			key:  "foo",
+			err:  ErrInvalidSecKeyFormat,
+		},
+		{
+			name: "empty",
+			key:  "",
+			err:  ErrInvalidSecKeyFormat,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := ParseSecKey(tc.key)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, sk, k)
+		})
+	}
+}
+
+func TestCollectionWalletImportSecKeys(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 4)
+
+	w, err := NewWallet("test.wlt", Options{
+		Type:      WalletTypeCollection,
+		GenerateN: 5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, w.Entries)
+	require.Empty(t, w.seed())
+	require.NoError(t, w.Validate())
+
+	addrs, err := w.ImportSecKeys(secKeys[:2])
+	require.NoError(t, err)
+	require.Len(t, addrs, 2)
+	require.Len(t, w.Entries, 2)
+	for i, e := range w.Entries {
+		require.Equal(t, secKeys[i], e.Secret)
+		require.Equal(t, cipher.MustAddressFromSecKey(secKeys[i]), e.Address)
+		require.Equal(t, e.Address, addrs[i])
+		require.NoError(t, e.Verify())
+	}
+
+	// Nothing is imported if any of the keys is already in the wallet
+	_, err = w.ImportSecKeys(secKeys[1:])
+	require.Equal(t, NewError(fmt.Errorf("address %s is already in the wallet", addrs[1])), err)
+	require.Len(t, w.Entries, 2)
+
+	// Nothing is imported if a key is repeated
+	_, err = w.ImportSecKeys([]cipher.SecKey{secKeys[2], secKeys[2]})
+	require.Error(t, err)
+	require.Len(t, w.Entries, 2)
+
+	// Nothing is imported if any of the keys is invalid
+	_, err = w.ImportSecKeys([]cipher.SecKey{secKeys[2], {}})
+	require.Error(t, err)
+	require.Len(t, w.Entries, 2)
+
+	// Collection wallets can't generate addresses
+	_, err = w.GenerateAddresses(1)
+	require.Equal(t, ErrWalletCantGenerateAddresses, err)
+
+	// Keys can only be imported into collection wallets
+	w2, err := NewWallet("test.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	_, err = w2.ImportSecKeys(secKeys[2:])
+	require.Equal(t, ErrWalletNotCollection, err)
+
+	// Lock and unlock the wallet, the secrets must be preserved
+	require.NoError(t, w.Lock([]byte("pwd"), CryptoTypeSha256Xor))
+	require.NoError(t, w.Validate())
+	for _, e := range w.Entries {
+		require.True(t, e.Secret.Null())
+	}
+
+	_, err = w.ImportSecKeys(secKeys[2:])
+	require.Equal(t, ErrWalletEncrypted, err)
+
+	err = w.GuardUpdate([]byte("pwd"), func(w *Wallet) error {
+		_, err := w.ImportSecKeys(secKeys[2:])
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 4)
+
+	err = w.GuardView([]byte("pwd"), func(w *Wallet) error {
+		for i, e := range w.Entries {
+			require.Equal(t, secKeys[i], e.Secret)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Save and load the wallet
+	dir, err := ioutil.TempDir("", "collection-wallet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, w.Save(dir))
+	w3, err := Load(filepath.Join(dir, w.Filename()))
+	require.NoError(t, err)
+	require.Equal(t, w.Entries, w3.Entries)
+	require.Equal(t, w.Meta, w3.Meta)
+}
+
+func TestCollectionWalletRemoveEntries(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 3)
+
+	w, err := NewWallet("test.wlt", Options{
+		Type: WalletTypeCollection,
+	})
+	require.NoError(t, err)
+
+	addrs, err := w.ImportSecKeys(secKeys)
+	require.NoError(t, err)
+
+	// Nothing is removed if any of the addresses is not in the wallet
+	unknownAddr := makeAddress()
+	err = w.RemoveEntries([]cipher.Addresser{addrs[0], unknownAddr})
+	require.Equal(t, NewError(fmt.Errorf("address %s is not in the wallet", unknownAddr)), err)
+	require.Len(t, w.Entries, 3)
+
+	require.NoError(t, w.RemoveEntries([]cipher.Addresser{addrs[1]}))
+	require.Len(t, w.Entries, 2)
+	require.Equal(t, addrs[0], w.Entries[0].Address)
+	require.Equal(t, addrs[2], w.Entries[1].Address)
+
+	// The removed secret keys must not be left in the encrypted secrets
+	require.NoError(t, w.Lock([]byte("pwd"), CryptoTypeSha256Xor))
+	require.Equal(t, ErrWalletEncrypted, w.RemoveEntries([]cipher.Addresser{addrs[0]}))
+
+	err = w.GuardUpdate([]byte("pwd"), func(w *Wallet) error {
+		return w.RemoveEntries([]cipher.Addresser{addrs[0]})
+	})
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 1)
+
+	w2, err := w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+	require.Len(t, w2.Entries, 1)
+	require.Equal(t, secKeys[2], w2.Entries[0].Secret)
+
+	// Entries can only be removed from collection wallets
+	w3, err := NewWallet("test.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	require.Equal(t, ErrWalletNotCollection, w3.RemoveEntries([]cipher.Addresser{w3.Entries[0].Address}))
+
+	// All entries can be removed, collection wallets may be empty
+	err = w.GuardUpdate([]byte("pwd"), func(w *Wallet) error {
+		return w.RemoveEntries([]cipher.Addresser{addrs[2]})
+	})
+	require.NoError(t, err)
+	require.Empty(t, w.Entries)
+	require.NoError(t, w.Validate())
+}
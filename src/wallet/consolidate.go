@@ -0,0 +1,203 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+)
+
+var (
+	// ErrNothingToConsolidate is returned if a wallet doesn't have enough unspent outputs with coin hours to consolidate
+	ErrNothingToConsolidate = NewError(errors.New("not enough unspent outputs with coin hours to consolidate"))
+	// ErrInvalidConsolidateMaxInputs is returned if the maximum number of inputs of a consolidation transaction is less than 2
+	ErrInvalidConsolidateMaxInputs = NewError(errors.New("max inputs must be at least 2"))
+	// ErrInvalidConsolidateMaxTransactions is returned if the maximum number of consolidation transactions is less than 1
+	ErrInvalidConsolidateMaxTransactions = NewError(errors.New("max transactions must be at least 1"))
+)
+
+// ConsolidateParams are the parameters for consolidating the unspent outputs of a wallet
+type ConsolidateParams struct {
+	// Address receives the consolidated coins, it must be an address of the wallet.
+	// If it is the null address, the first address of the wallet is used.
+	Address cipher.Address
+	// MaxInputs is the maximum number of inputs of a transaction.
+	// If 0, as many inputs as fit in a transaction of params.UserVerifyTxn.MaxTransactionSize are used.
+	MaxInputs int
+	// MaxTransactions is the maximum number of transactions to create
+	MaxTransactions int
+}
+
+// Validate validates ConsolidateParams
+func (p ConsolidateParams) Validate() error {
+	if p.MaxInputs != 0 && p.MaxInputs < 2 {
+		return ErrInvalidConsolidateMaxInputs
+	}
+
+	if p.MaxTransactions < 1 {
+		return ErrInvalidConsolidateMaxTransactions
+	}
+
+	return nil
+}
+
+// CreateConsolidateTransactions creates unsigned transactions which send the smallest unspent outputs of the wallet
+// to a single output of one of its addresses, so that the wallet has fewer outputs to spend.
+// The outputs frozen in the wallet are not spent.
+// The outputs are sorted coins lowest, hours lowest, with the hash as a tiebreaker,
+// and each transaction spends as many of the remaining outputs as allowed by p.MaxInputs and
+// params.UserVerifyTxn.MaxTransactionSize. The output of a transaction receives all coins and
+// the coin hours of the inputs which are not burned for the fee.
+// No more transactions are created once fewer than two outputs remain, or the remaining outputs have no coin hours.
+// NOTE: Caller must ensure that auxs does not contain outputs spent by unconfirmed transactions.
+func (w *Wallet) CreateConsolidateTransactions(p ConsolidateParams, auxs coin.AddressUxOuts, headTime uint64) ([]coin.Transaction, [][]transaction.UxBalance, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	// Check that auxs does not contain addresses that are not known to this wallet
+	for a := range auxs {
+		if !w.HasEntry(a) {
+			return nil, nil, ErrUnknownAddress
+		}
+	}
+
+	toAddr := p.Address
+	if toAddr.Null() {
+		if len(w.Entries) == 0 {
+			return nil, nil, NewError(errors.New("wallet has no addresses"))
+		}
+		toAddr = w.Entries[0].SkycoinAddress()
+	} else if !w.HasEntry(toAddr) {
+		return nil, nil, ErrUnknownAddress
+	}
+
+	frozen := make(map[cipher.SHA256]struct{})
+	for _, h := range w.FrozenUxOuts() {
+		frozen[h] = struct{}{}
+	}
+
+	var uxa coin.UxArray
+	for _, ux := range auxs.Flatten() {
+		if _, ok := frozen[ux.Hash()]; !ok {
+			uxa = append(uxa, ux)
+		}
+	}
+
+	uxb, err := transaction.NewUxBalances(uxa, headTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(uxb, func(i, j int) bool {
+		a, b := uxb[i], uxb[j]
+		if a.Coins != b.Coins {
+			return a.Coins < b.Coins
+		}
+		if a.Hours != b.Hours {
+			return a.Hours < b.Hours
+		}
+		return bytes.Compare(a.Hash[:], b.Hash[:]) < 0
+	})
+
+	maxInputs, err := consolidateMaxInputs(params.UserVerifyTxn.MaxTransactionSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.MaxInputs != 0 && p.MaxInputs < maxInputs {
+		maxInputs = p.MaxInputs
+	}
+
+	var txns []coin.Transaction
+	var inputs [][]transaction.UxBalance
+	for len(uxb) >= 2 && len(txns) < p.MaxTransactions {
+		n := maxInputs
+		if n > len(uxb) {
+			n = len(uxb)
+		}
+
+		txn, err := consolidateTransaction(toAddr, uxb[:n])
+		if err != nil {
+			return nil, nil, err
+		}
+		if txn == nil {
+			break
+		}
+
+		txns = append(txns, *txn)
+		inputs = append(inputs, uxb[:n])
+		uxb = uxb[n:]
+	}
+
+	if len(txns) == 0 {
+		return nil, nil, ErrNothingToConsolidate
+	}
+
+	return txns, inputs, nil
+}
+
+// consolidateTransaction creates an unsigned transaction sending the inputs to a single output.
+// Returns nil if the inputs have no coin hours to pay the fee.
+func consolidateTransaction(toAddr cipher.Address, uxb []transaction.UxBalance) (*coin.Transaction, error) {
+	var coins, hours uint64
+	for _, ux := range uxb {
+		var err error
+		coins, err = mathutil.AddUint64(coins, ux.Coins)
+		if err != nil {
+			return nil, err
+		}
+		hours, err = mathutil.AddUint64(hours, ux.Hours)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hours == 0 {
+		return nil, nil
+	}
+
+	txn := &coin.Transaction{}
+	for _, ux := range uxb {
+		if err := txn.PushInput(ux.Hash); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := txn.PushOutput(toAddr, coins, fee.RemainingHours(hours, params.UserVerifyTxn.BurnFactor)); err != nil {
+		return nil, err
+	}
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// consolidateMaxInputs returns the number of inputs of a signed transaction with a single output
+// which fit in maxSize bytes
+func consolidateMaxInputs(maxSize uint32) (int, error) {
+	txn := coin.Transaction{
+		Out: []coin.TransactionOutput{{}},
+	}
+	baseSize, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	txn.In = []cipher.SHA256{{}}
+	txn.Sigs = []cipher.Sig{{}}
+	size, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	return int((maxSize - baseSize) / (size - baseSize)), nil
+}
@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/fee"
+)
+
+func makeConsolidateUxOuts(t *testing.T, addr cipher.Address, coins []uint64, hours uint64) coin.UxArray {
+	uxouts := make(coin.UxArray, len(coins))
+	for i, c := range coins {
+		uxouts[i] = coin.UxOut{
+			Head: coin.UxHead{
+				Time:  100,
+				BkSeq: 2,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        addr,
+				Coins:          c,
+				Hours:          hours,
+			},
+		}
+	}
+	return uxouts
+}
+
+func TestWalletCreateConsolidateTransactions(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	addr0 := w.Entries[0].SkycoinAddress()
+	addr1 := w.Entries[1].SkycoinAddress()
+
+	uxouts0 := makeConsolidateUxOuts(t, addr0, []uint64{5e6, 1e6, 3e6}, 100)
+	uxouts1 := makeConsolidateUxOuts(t, addr1, []uint64{2e6, 4e6}, 100)
+	auxs := coin.AddressUxOuts{
+		addr0: uxouts0,
+		addr1: uxouts1,
+	}
+
+	_, _, err = w.CreateConsolidateTransactions(ConsolidateParams{}, auxs, 100)
+	require.Equal(t, ErrInvalidConsolidateMaxTransactions, err)
+
+	_, _, err = w.CreateConsolidateTransactions(ConsolidateParams{MaxInputs: 1, MaxTransactions: 1}, auxs, 100)
+	require.Equal(t, ErrInvalidConsolidateMaxInputs, err)
+
+	_, _, err = w.CreateConsolidateTransactions(ConsolidateParams{Address: testutil.MakeAddress(), MaxTransactions: 1}, auxs, 100)
+	require.Equal(t, ErrUnknownAddress, err)
+
+	_, _, err = w.CreateConsolidateTransactions(ConsolidateParams{MaxTransactions: 1}, coin.AddressUxOuts{
+		testutil.MakeAddress(): uxouts0,
+	}, 100)
+	require.Equal(t, ErrUnknownAddress, err)
+
+	// All outputs are sent to the first address of the wallet
+	txns, inputs, err := w.CreateConsolidateTransactions(ConsolidateParams{MaxTransactions: 1}, auxs, 100)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	require.Len(t, inputs[0], 5)
+	require.Equal(t, []cipher.SHA256{
+		uxouts0[1].Hash(),
+		uxouts1[0].Hash(),
+		uxouts0[2].Hash(),
+		uxouts1[1].Hash(),
+		uxouts0[0].Hash(),
+	}, txns[0].In)
+	require.Equal(t, []coin.TransactionOutput{{
+		Address: addr0,
+		Coins:   15e6,
+		Hours:   fee.RemainingHours(500, params.UserVerifyTxn.BurnFactor),
+	}}, txns[0].Out)
+	require.True(t, txns[0].IsFullyUnsigned())
+	require.Equal(t, txns[0].HashInner(), txns[0].InnerHash)
+
+	// The smallest outputs are sent first, to the requested address, and frozen outputs are not spent
+	w.FreezeUxOuts([]cipher.SHA256{uxouts1[0].Hash()})
+	txns, inputs, err = w.CreateConsolidateTransactions(ConsolidateParams{
+		Address:         addr1,
+		MaxInputs:       2,
+		MaxTransactions: 5,
+	}, auxs, 100)
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+	require.Len(t, inputs, 2)
+	require.Equal(t, []cipher.SHA256{uxouts0[1].Hash(), uxouts0[2].Hash()}, txns[0].In)
+	require.Equal(t, []cipher.SHA256{uxouts1[1].Hash(), uxouts0[0].Hash()}, txns[1].In)
+	require.Equal(t, addr1, txns[0].Out[0].Address)
+	require.Equal(t, uint64(4e6), txns[0].Out[0].Coins)
+	require.Equal(t, uint64(9e6), txns[1].Out[0].Coins)
+
+	// Outputs without coin hours can't pay the fee
+	auxs = coin.AddressUxOuts{
+		addr0: makeConsolidateUxOuts(t, addr0, []uint64{1e6, 2e6}, 0),
+	}
+	_, _, err = w.CreateConsolidateTransactions(ConsolidateParams{MaxTransactions: 1}, auxs, 100)
+	require.Equal(t, ErrNothingToConsolidate, err)
+
+	// A single output is not consolidated
+	auxs = coin.AddressUxOuts{
+		addr0: makeConsolidateUxOuts(t, addr0, []uint64{1e6}, 100),
+	}
+	_, _, err = w.CreateConsolidateTransactions(ConsolidateParams{MaxTransactions: 1}, auxs, 100)
+	require.Equal(t, ErrNothingToConsolidate, err)
+}
+
+func TestWalletCreateConsolidateTransactionsMaxTransactionSize(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 1,
+	})
+	require.NoError(t, err)
+
+	addr := w.Entries[0].SkycoinAddress()
+	coins := make([]uint64, 1000)
+	for i := range coins {
+		coins[i] = uint64(i+1) * 1e3
+	}
+	auxs := coin.AddressUxOuts{
+		addr: makeConsolidateUxOuts(t, addr, coins, 10),
+	}
+
+	txns, inputs, err := w.CreateConsolidateTransactions(ConsolidateParams{MaxTransactions: 10}, auxs, 100)
+	require.NoError(t, err)
+	require.True(t, len(txns) > 1)
+
+	n := 0
+	for i, txn := range txns {
+		// Signatures don't change the size of a transaction
+		size, err := txn.Size()
+		require.NoError(t, err)
+		require.True(t, size <= params.UserVerifyTxn.MaxTransactionSize)
+		require.Len(t, inputs[i], len(txn.In))
+		n += len(txn.In)
+	}
+	require.Equal(t, len(coins), n)
+
+	// The first transaction is full
+	txn := txns[0]
+	txn.In = append(txn.In, cipher.SHA256{})
+	txn.Sigs = append(txn.Sigs, cipher.Sig{})
+	size, err := txn.Size()
+	require.NoError(t, err)
+	require.True(t, size > params.UserVerifyTxn.MaxTransactionSize)
+}
@@ -24,6 +24,8 @@ func CryptoTypeFromString(s string) (CryptoType, error) {
 		return CryptoTypeScryptChacha20poly1305, nil
 	case CryptoTypeScryptChacha20poly1305Insecure:
 		return CryptoTypeScryptChacha20poly1305Insecure, nil
+	case CryptoTypeArgon2idChacha20poly1305:
+		return CryptoTypeArgon2idChacha20poly1305, nil
 	default:
 		return "", errors.New("unknown crypto type")
 	}
@@ -37,6 +39,10 @@ const (
 	CryptoTypeScryptChacha20poly1305 = CryptoType("scrypt-chacha20poly1305")
 	// CryptoTypeScryptChacha20poly1305Insecure uses chacha20poly1305 + scrypt key derivation with a weak work factor (unsafe)
 	CryptoTypeScryptChacha20poly1305Insecure = CryptoType("scrypt-chacha20poly1305-insecure")
+	// CryptoTypeArgon2idChacha20poly1305 uses chacha20poly1305 + argon2id key derivation (memory-hard).
+	// The argon2id parameters are recorded in the encrypted secrets, so the defaults can be raised
+	// without affecting existing wallets.
+	CryptoTypeArgon2idChacha20poly1305 = CryptoType("argon2id-chacha20poly1305")
 )
 
 // cryptoTable records all supported wallet crypto methods
@@ -50,6 +56,38 @@ var cryptoTable = map[CryptoType]cryptor{
 		P:      encrypt.ScryptP,
 		KeyLen: encrypt.ScryptKeyLen,
 	},
+	CryptoTypeArgon2idChacha20poly1305: encrypt.DefaultArgon2idChacha20poly1305,
+}
+
+// Argon2idParams are the argon2id key derivation parameters of the argon2id-chacha20poly1305 crypto type
+type Argon2idParams struct {
+	Time        uint32 // number of passes over the memory
+	Memory      uint32 // memory in KiB
+	Parallelism uint8  // number of lanes (and threads)
+}
+
+// DefaultArgon2idParams are the argon2id parameters used if none are configured
+var DefaultArgon2idParams = Argon2idParams{
+	Time:        encrypt.Argon2idTime,
+	Memory:      encrypt.Argon2idMemory,
+	Parallelism: encrypt.Argon2idParallelism,
+}
+
+// Validate checks that the argon2id parameters can be used to encrypt a wallet
+func (p Argon2idParams) Validate() error {
+	if err := p.cryptor().Validate(); err != nil {
+		return NewError(fmt.Errorf("invalid argon2id parameters: %v", err))
+	}
+	return nil
+}
+
+func (p Argon2idParams) cryptor() encrypt.Argon2idChacha20poly1305 {
+	return encrypt.Argon2idChacha20poly1305{
+		Time:        p.Time,
+		Memory:      p.Memory,
+		Parallelism: p.Parallelism,
+		KeyLen:      encrypt.Argon2idKeyLen,
+	}
 }
 
 // getCrypto gets crypto of given type
@@ -2,15 +2,101 @@ package wallet
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/skycoin/skycoin/src/cipher"
 )
 
+// Entry purposes
+const (
+	// EntryPurposeReceive is the purpose of addresses handed out to receive coins
+	EntryPurposeReceive = "receive"
+	// EntryPurposeChange is the purpose of addresses used for transaction change
+	EntryPurposeChange = "change"
+)
+
+var (
+	// ErrInvalidEntryPurpose is returned if an entry purpose is not "receive", "change" or empty
+	ErrInvalidEntryPurpose = NewError(fmt.Errorf("invalid address purpose, must be %q or %q", EntryPurposeReceive, EntryPurposeChange))
+	// ErrInvalidEntryTag is returned if an entry tag is empty or has leading or trailing whitespace
+	ErrInvalidEntryTag = NewError(errors.New("invalid address tag, tags must be non-empty and have no leading or trailing whitespace"))
+)
+
 // Entry represents the wallet entry
 type Entry struct {
-	Address cipher.Addresser
-	Public  cipher.PubKey
-	Secret  cipher.SecKey
+	Address     cipher.Addresser
+	Public      cipher.PubKey
+	Secret      cipher.SecKey
+	ChildNumber uint32 // For bip44
+	Change      uint32 // For bip44
+
+	Label   string   // Human readable label of the address
+	Created int64    // Unix time when the address was added to the wallet, 0 if unknown
+	Purpose string   // EntryPurposeReceive or EntryPurposeChange, empty if unknown
+	Tags    []string // Free-form tags
+}
+
+// EntryMetaUpdate is the metadata to set on a wallet entry. Nil fields are left unchanged.
+type EntryMetaUpdate struct {
+	Label   *string
+	Purpose *string
+	Tags    *[]string
+}
+
+// Validate checks that the purpose and tags of the update are valid
+func (u EntryMetaUpdate) Validate() error {
+	if u.Purpose != nil {
+		switch *u.Purpose {
+		case "", EntryPurposeReceive, EntryPurposeChange:
+		default:
+			return ErrInvalidEntryPurpose
+		}
+	}
+
+	if u.Tags != nil {
+		for _, t := range *u.Tags {
+			if t == "" || strings.TrimSpace(t) != t {
+				return ErrInvalidEntryTag
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply sets the metadata of the update on the entry. Duplicate tags are removed.
+func (u EntryMetaUpdate) apply(e *Entry) {
+	if u.Label != nil {
+		e.Label = *u.Label
+	}
+
+	if u.Purpose != nil {
+		e.Purpose = *u.Purpose
+	}
+
+	if u.Tags != nil {
+		var tags []string
+		seen := make(map[string]struct{}, len(*u.Tags))
+		for _, t := range *u.Tags {
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			tags = append(tags, t)
+		}
+		e.Tags = tags
+	}
+}
+
+// HasTag returns true if the entry has the given tag
+func (we *Entry) HasTag(tag string) bool {
+	for _, t := range we.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // SkycoinAddress returns the Skycoin address of an entry. Panics if Address is not a Skycoin address
@@ -0,0 +1,243 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// EncryptedPaperSecretPrefix prefixes the encrypted secrets of paper wallets
+const EncryptedPaperSecretPrefix = "skyenc1"
+
+// paperAddressHashLen is the length of the address hash recorded in an encrypted paper wallet secret
+const paperAddressHashLen = 4
+
+var (
+	// ErrInvalidEncryptedPaperSecret is returned if an encrypted paper wallet secret is malformed
+	ErrInvalidEncryptedPaperSecret = NewError(errors.New("invalid encrypted paper wallet secret"))
+	// ErrInvalidPaperSecretPassword is returned if an encrypted paper wallet secret can't be decrypted with the password
+	ErrInvalidPaperSecretPassword = NewError(errors.New("invalid paper wallet password"))
+	// ErrMissingPaperSecretPassword is returned if no password is provided to encrypt or decrypt a paper wallet secret
+	ErrMissingPaperSecretPassword = NewError(errors.New("missing paper wallet password"))
+	// ErrPaperSecretAddressMismatch is returned if the secret of a paper wallet does not spend from its address
+	ErrPaperSecretAddressMismatch = NewError(errors.New("paper wallet secret does not match its address"))
+	// ErrInvalidPaperSecretType is returned if the secret type of a paper wallet is unknown
+	ErrInvalidPaperSecretType = NewError(errors.New("invalid paper wallet secret type"))
+	// ErrPaperSecretCryptoTypeNotAllowed is returned when encrypting a paper wallet secret with sha256-xor,
+	// whose key derivation is too fast to protect a secret printed on paper against password guessing
+	ErrPaperSecretCryptoTypeNotAllowed = NewError(errors.New("sha256-xor can't be used to encrypt paper wallet secrets"))
+)
+
+// PaperSecretType is the type of secret of a paper wallet
+type PaperSecretType byte
+
+const (
+	// PaperSecretTypeSeed is a deterministic wallet seed, the paper wallet address is the first address of the seed
+	PaperSecretTypeSeed PaperSecretType = 1
+	// PaperSecretTypeSecretKey is a hex encoded secret key
+	PaperSecretTypeSecretKey PaperSecretType = 2
+)
+
+// String returns the name of the secret type
+func (t PaperSecretType) String() string {
+	switch t {
+	case PaperSecretTypeSeed:
+		return "seed"
+	case PaperSecretTypeSecretKey:
+		return "secret key"
+	default:
+		return fmt.Sprintf("PaperSecretType(%d)", byte(t))
+	}
+}
+
+// PaperWallet is an address and the secret which spends its coins, to be printed for cold storage
+type PaperWallet struct {
+	Address    cipher.Address
+	SecretType PaperSecretType
+	Secret     string
+}
+
+// NewSeedPaperWallet creates a paper wallet for the first address of a deterministic wallet seed,
+// so that the funds are available once the seed is loaded into a wallet
+func NewSeedPaperWallet(seed string) (*PaperWallet, error) {
+	if seed == "" {
+		return nil, ErrMissingSeed
+	}
+
+	p := &PaperWallet{
+		SecretType: PaperSecretTypeSeed,
+		Secret:     seed,
+	}
+
+	addr, err := p.deriveAddress()
+	if err != nil {
+		return nil, err
+	}
+	p.Address = addr
+
+	return p, nil
+}
+
+// NewSecKeyPaperWallet creates a paper wallet for the address of a secret key
+func NewSecKeyPaperWallet(key cipher.SecKey) (*PaperWallet, error) {
+	p := &PaperWallet{
+		SecretType: PaperSecretTypeSecretKey,
+		Secret:     key.Hex(),
+	}
+
+	addr, err := p.deriveAddress()
+	if err != nil {
+		return nil, err
+	}
+	p.Address = addr
+
+	return p, nil
+}
+
+// SecKey returns the secret key of the paper wallet address
+func (p *PaperWallet) SecKey() (cipher.SecKey, error) {
+	switch p.SecretType {
+	case PaperSecretTypeSeed:
+		keys, err := cipher.GenerateDeterministicKeyPairs([]byte(p.Secret), 1)
+		if err != nil {
+			return cipher.SecKey{}, err
+		}
+		return keys[0], nil
+	case PaperSecretTypeSecretKey:
+		return cipher.SecKeyFromHex(p.Secret)
+	default:
+		return cipher.SecKey{}, ErrInvalidPaperSecretType
+	}
+}
+
+// Verify checks that the secret spends the coins of the address
+func (p *PaperWallet) Verify() error {
+	addr, err := p.deriveAddress()
+	if err != nil {
+		return err
+	}
+
+	if addr != p.Address {
+		return ErrPaperSecretAddressMismatch
+	}
+
+	return nil
+}
+
+func (p *PaperWallet) deriveAddress() (cipher.Address, error) {
+	key, err := p.SecKey()
+	if err != nil {
+		return cipher.Address{}, err
+	}
+
+	return cipher.AddressFromSecKey(key)
+}
+
+// EncryptSecret encrypts the secret of the paper wallet with password, similarly to BIP38:
+// the key is derived from the password by the crypto type, and a hash of the address is encrypted
+// with the secret so that decrypting can check that the secret matches the address printed beside it.
+// The result is formatted as "skyenc1:<crypto type>:<encrypted data>".
+func (p *PaperWallet) EncryptSecret(password []byte, cryptoType CryptoType) (string, error) {
+	if len(password) == 0 {
+		return "", ErrMissingPaperSecretPassword
+	}
+
+	if cryptoType == CryptoTypeSha256Xor {
+		return "", ErrPaperSecretCryptoTypeNotAllowed
+	}
+
+	if err := p.Verify(); err != nil {
+		return "", err
+	}
+
+	c, err := getCrypto(cryptoType)
+	if err != nil {
+		return "", err
+	}
+
+	addrHash := paperAddressHash(p.Address)
+	data := make([]byte, 0, 1+len(addrHash)+len(p.Secret))
+	data = append(data, byte(p.SecretType))
+	data = append(data, addrHash...)
+	data = append(data, p.Secret...)
+	defer func() {
+		for i := range data {
+			data[i] = 0
+		}
+	}()
+
+	encData, err := c.Encrypt(data, password)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{EncryptedPaperSecretPrefix, string(cryptoType), string(encData)}, ":"), nil
+}
+
+// IsEncryptedPaperSecret returns true if s is an encrypted paper wallet secret
+func IsEncryptedPaperSecret(s string) bool {
+	return strings.HasPrefix(s, EncryptedPaperSecretPrefix+":")
+}
+
+// DecryptPaperSecret decrypts a paper wallet secret encrypted by PaperWallet.EncryptSecret,
+// and returns the paper wallet after checking that the secret matches the address encrypted with it
+func DecryptPaperSecret(s string, password []byte) (*PaperWallet, error) {
+	if len(password) == 0 {
+		return nil, ErrMissingPaperSecretPassword
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(s), ":", 3)
+	if len(parts) != 3 || parts[0] != EncryptedPaperSecretPrefix {
+		return nil, ErrInvalidEncryptedPaperSecret
+	}
+
+	cryptoType, err := CryptoTypeFromString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidEncryptedPaperSecret
+	}
+
+	c, err := getCrypto(cryptoType)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.Decrypt([]byte(parts[2]), password)
+	if err != nil {
+		return nil, ErrInvalidPaperSecretPassword
+	}
+	defer func() {
+		for i := range data {
+			data[i] = 0
+		}
+	}()
+
+	if len(data) <= 1+paperAddressHashLen {
+		return nil, ErrInvalidEncryptedPaperSecret
+	}
+
+	p := &PaperWallet{
+		SecretType: PaperSecretType(data[0]),
+		Secret:     string(data[1+paperAddressHashLen:]),
+	}
+
+	addr, err := p.deriveAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(paperAddressHash(addr), data[1:1+paperAddressHashLen]) {
+		return nil, ErrPaperSecretAddressMismatch
+	}
+	p.Address = addr
+
+	return p, nil
+}
+
+// paperAddressHash returns the first bytes of the double SHA256 of the address string, as in BIP38
+func paperAddressHash(addr cipher.Address) []byte {
+	h := cipher.DoubleSHA256([]byte(addr.String()))
+	return h[:paperAddressHashLen]
+}
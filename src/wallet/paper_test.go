@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestNewSeedPaperWallet(t *testing.T) {
+	_, err := NewSeedPaperWallet("")
+	require.Equal(t, ErrMissingSeed, err)
+
+	seed := "enact seek among recall one save armed parrot license ask giant fog"
+	p, err := NewSeedPaperWallet(seed)
+	require.NoError(t, err)
+	require.Equal(t, PaperSecretTypeSeed, p.SecretType)
+	require.Equal(t, seed, p.Secret)
+
+	// The address is the first address of a wallet created from the seed
+	w, err := NewWallet("test.wlt", Options{
+		Seed:      seed,
+		GenerateN: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, w.Entries[0].SkycoinAddress(), p.Address)
+
+	key, err := p.SecKey()
+	require.NoError(t, err)
+	require.Equal(t, w.Entries[0].Secret, key)
+	require.NoError(t, p.Verify())
+
+	pk, _ := cipher.GenerateKeyPair()
+	p.Address = cipher.AddressFromPubKey(pk)
+	require.Equal(t, ErrPaperSecretAddressMismatch, p.Verify())
+}
+
+func TestNewSecKeyPaperWallet(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	p, err := NewSecKeyPaperWallet(sk)
+	require.NoError(t, err)
+	require.Equal(t, PaperSecretTypeSecretKey, p.SecretType)
+	require.Equal(t, sk.Hex(), p.Secret)
+	require.Equal(t, cipher.AddressFromPubKey(pk), p.Address)
+
+	key, err := p.SecKey()
+	require.NoError(t, err)
+	require.Equal(t, sk, key)
+
+	_, err = NewSecKeyPaperWallet(cipher.SecKey{})
+	require.Error(t, err)
+}
+
+func TestPaperWalletEncryptSecret(t *testing.T) {
+	_, sk := cipher.GenerateKeyPair()
+	keyWallet, err := NewSecKeyPaperWallet(sk)
+	require.NoError(t, err)
+
+	seedWallet, err := NewSeedPaperWallet("seed")
+	require.NoError(t, err)
+
+	for _, p := range []*PaperWallet{keyWallet, seedWallet} {
+		for _, ct := range []CryptoType{CryptoTypeScryptChacha20poly1305Insecure} {
+			t.Run(p.SecretType.String()+" "+string(ct), func(t *testing.T) {
+				_, err := p.EncryptSecret(nil, ct)
+				require.Equal(t, ErrMissingPaperSecretPassword, err)
+
+				s, err := p.EncryptSecret([]byte("pwd"), ct)
+				require.NoError(t, err)
+				require.True(t, IsEncryptedPaperSecret(s))
+				require.True(t, strings.HasPrefix(s, EncryptedPaperSecretPrefix+":"+string(ct)+":"))
+				require.NotContains(t, s, p.Secret)
+
+				dp, err := DecryptPaperSecret(s, []byte("pwd"))
+				require.NoError(t, err)
+				require.Equal(t, p, dp)
+
+				_, err = DecryptPaperSecret(s, nil)
+				require.Equal(t, ErrMissingPaperSecretPassword, err)
+			})
+		}
+	}
+
+	s, err := keyWallet.EncryptSecret([]byte("pwd"), CryptoTypeScryptChacha20poly1305Insecure)
+	require.NoError(t, err)
+	_, err = DecryptPaperSecret(s, []byte("wrong"))
+	require.Equal(t, ErrInvalidPaperSecretPassword, err)
+
+	_, err = keyWallet.EncryptSecret([]byte("pwd"), CryptoTypeSha256Xor)
+	require.Equal(t, ErrPaperSecretCryptoTypeNotAllowed, err)
+
+	for _, s := range []string{
+		"",
+		keyWallet.Secret,
+		"skyenc1:foo",
+		"skyenc2:scrypt-chacha20poly1305:abc",
+		"skyenc1:foo:abc",
+	} {
+		_, err = DecryptPaperSecret(s, []byte("pwd"))
+		require.Equal(t, ErrInvalidEncryptedPaperSecret, err, s)
+	}
+
+	// Crafted argon2id parameters are rejected before the key is derived
+	for _, meta := range []string{
+		`{"t":4294967295,"m":1024,"p":1,"keyLen":32,"salt":"","nonce":"AAAAAAAAAAAAAAAA"}`,
+		`{"t":1,"m":4194304,"p":1,"keyLen":32,"salt":"","nonce":"AAAAAAAAAAAAAAAA"}`,
+	} {
+		length := make([]byte, 2)
+		binary.LittleEndian.PutUint16(length, uint16(len(meta)))
+		data := append(append(length, meta...), make([]byte, 32)...)
+		s := "skyenc1:" + string(CryptoTypeArgon2idChacha20poly1305) + ":" + base64.StdEncoding.EncodeToString(data)
+
+		_, err = DecryptPaperSecret(s, []byte("pwd"))
+		require.Equal(t, ErrInvalidPaperSecretPassword, err, meta)
+	}
+
+	// The secret must match the address
+	p := *keyWallet
+	p.Address = seedWallet.Address
+	_, err = p.EncryptSecret([]byte("pwd"), CryptoTypeScryptChacha20poly1305Insecure)
+	require.Equal(t, ErrPaperSecretAddressMismatch, err)
+}
@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/transaction"
+)
+
+// ErrNoInputsToSign is returned if a wallet does not own any of the unsigned inputs of a partially signed transaction
+var ErrNoInputsToSign = NewError(errors.New("wallet does not own any unsigned input of the partial transaction"))
+
+// SignPartialTransaction signs the unsigned inputs of a partially signed transaction
+// whose addresses are in the wallet, and records the wallet as the signer of those inputs.
+// The partially signed transaction is not modified.
+func (w *Wallet) SignPartialTransaction(pt *transaction.PartialTransaction) (*transaction.PartialTransaction, error) {
+	if err := pt.Validate(); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]Entry, len(w.Entries))
+	for _, e := range w.Entries {
+		entries[e.Address.String()] = e
+	}
+
+	var signIndexes []int
+	for i, in := range pt.Inputs {
+		if !pt.Transaction.Sigs[i].Null() {
+			continue
+		}
+		if _, ok := entries[in.UxOut.Body.Address.String()]; ok {
+			signIndexes = append(signIndexes, i)
+		}
+	}
+
+	if len(signIndexes) == 0 {
+		return nil, ErrNoInputsToSign
+	}
+
+	txn, err := w.SignTransaction(&pt.Transaction, signIndexes, pt.UxOuts())
+	if err != nil {
+		return nil, err
+	}
+
+	signed := pt.Copy()
+	signed.Transaction = *txn
+	w.setPartialTransactionSigners(signed, entries, true)
+
+	return signed, nil
+}
+
+// setPartialTransactionSigners sets the signer hints of the inputs whose addresses are in entries.
+// Existing signer hints are kept unless overwrite is true.
+func (w *Wallet) setPartialTransactionSigners(pt *transaction.PartialTransaction, entries map[string]Entry, overwrite bool) {
+	for i, in := range pt.Inputs {
+		e, ok := entries[in.UxOut.Body.Address.String()]
+		if !ok || (in.Signer != "" && !overwrite) {
+			continue
+		}
+
+		pt.Inputs[i].Signer = w.Filename()
+		pt.Inputs[i].ChildNumber = e.ChildNumber
+		pt.Inputs[i].Change = e.Change
+	}
+}
+
+// SetPartialTransactionSigners sets the signer hints of the inputs of a partially signed transaction
+// whose addresses are in the wallet and which have no signer hint yet
+func (w *Wallet) SetPartialTransactionSigners(pt *transaction.PartialTransaction) {
+	entries := make(map[string]Entry, len(w.Entries))
+	for _, e := range w.Entries {
+		entries[e.Address.String()] = e
+	}
+
+	w.setPartialTransactionSigners(pt, entries, false)
+}
@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/transaction"
+)
+
+func makePartialTransaction(t *testing.T, uxOuts []coin.UxOut) *transaction.PartialTransaction {
+	var txn coin.Transaction
+	for _, ux := range uxOuts {
+		err := txn.PushInput(ux.Hash())
+		require.NoError(t, err)
+	}
+
+	err := txn.PushOutput(testutil.MakeAddress(), 1e6, 1)
+	require.NoError(t, err)
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	pt, err := transaction.NewPartialTransaction(txn, uxOuts)
+	require.NoError(t, err)
+
+	return pt
+}
+
+func TestWalletSignPartialTransaction(t *testing.T) {
+	a, err := NewWallet("a.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 1,
+	})
+	require.NoError(t, err)
+
+	b, err := NewWallet("b.wlt", Options{
+		Type:      WalletTypeBip44,
+		Seed:      testBip44Seed,
+		GenerateN: 1,
+	})
+	require.NoError(t, err)
+	_, err = b.GenerateChangeAddresses(1)
+	require.NoError(t, err)
+
+	x, err := NewWallet("x.wlt", Options{
+		Type:      WalletTypeXPub,
+		XPub:      testXPub,
+		GenerateN: 1,
+	})
+	require.NoError(t, err)
+
+	uxA := makeUxOut(t, a.Entries[0].Secret, 1e6, 10)
+	uxB := makeUxOut(t, b.Entries[1].Secret, 1e6, 10)
+	uxC, _ := makeUxOutWithSecret(t)
+	uxX := makeUxOut(t, a.Entries[0].Secret, 1e6, 10)
+	uxX.Body.Address = x.Entries[0].SkycoinAddress()
+
+	pt := makePartialTransaction(t, []coin.UxOut{uxA, uxB, uxC, uxX})
+
+	signedA, err := a.SignPartialTransaction(pt)
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false, false, false}, signedA.Status().Signed)
+	require.Equal(t, "a.wlt", signedA.Inputs[0].Signer)
+	require.NoError(t, signedA.Validate())
+
+	// The partially signed transaction is not modified
+	require.True(t, pt.Transaction.IsFullyUnsigned())
+	require.Empty(t, pt.Inputs[0].Signer)
+
+	// The wallet has no more inputs to sign
+	_, err = a.SignPartialTransaction(signedA)
+	require.Equal(t, ErrNoInputsToSign, err)
+
+	signedB, err := b.SignPartialTransaction(signedA)
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, true, false, false}, signedB.Status().Signed)
+	require.Equal(t, transaction.PartialTransactionInput{
+		UxOut:       uxB,
+		Signer:      "b.wlt",
+		ChildNumber: 0,
+		Change:      1,
+	}, signedB.Inputs[1])
+
+	// Watch-only wallets can't sign
+	_, err = x.SignPartialTransaction(pt)
+	require.Equal(t, ErrWalletCantSign, err)
+
+	// Encrypted wallets must be unlocked to sign
+	err = a.Lock([]byte("pwd"), CryptoTypeSha256Xor)
+	require.NoError(t, err)
+	_, err = a.SignPartialTransaction(pt)
+	require.Equal(t, ErrWalletEncrypted, err)
+
+	err = a.GuardView([]byte("pwd"), func(w *Wallet) error {
+		signed, err := w.SignPartialTransaction(pt)
+		require.NoError(t, err)
+		require.Equal(t, signedA.Status(), signed.Status())
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Invalid partially signed transactions are not signed
+	bad := pt.Copy()
+	bad.Version = 2
+	_, err = b.SignPartialTransaction(bad)
+	require.Equal(t, transaction.ErrUnsupportedPartialTransactionVersion, err)
+}
+
+func TestWalletSetPartialTransactionSigners(t *testing.T) {
+	a, err := NewWallet("a.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	b, err := NewWallet("b.wlt", Options{
+		Type: WalletTypeCollection,
+	})
+	require.NoError(t, err)
+	_, err = b.ImportSecKeys([]cipher.SecKey{a.Entries[1].Secret})
+	require.NoError(t, err)
+
+	uxA := makeUxOut(t, a.Entries[0].Secret, 1e6, 10)
+	uxAB := makeUxOut(t, a.Entries[1].Secret, 1e6, 10)
+	uxC, _ := makeUxOutWithSecret(t)
+
+	pt := makePartialTransaction(t, []coin.UxOut{uxA, uxAB, uxC})
+
+	b.SetPartialTransactionSigners(pt)
+	require.Equal(t, []string{"", "b.wlt", ""}, partialTransactionSigners(pt))
+
+	// Existing signer hints are kept
+	a.SetPartialTransactionSigners(pt)
+	require.Equal(t, []string{"a.wlt", "b.wlt", ""}, partialTransactionSigners(pt))
+	require.Equal(t, uint32(0), pt.Inputs[1].ChildNumber)
+}
+
+func partialTransactionSigners(pt *transaction.PartialTransaction) []string {
+	signers := make([]string, len(pt.Inputs))
+	for i, in := range pt.Inputs {
+		signers[i] = in.Signer
+	}
+	return signers
+}
@@ -0,0 +1,429 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+)
+
+const (
+	// SpendingPolicyExt is the extension of spending policy files, which are saved next to the wallet file
+	SpendingPolicyExt = ".policy"
+
+	// SpendingPolicyWindow is the period of the rolling spend limit of a spending policy
+	SpendingPolicyWindow = 24 * time.Hour
+
+	// approvalCheckPlaintext is encrypted with the approval password to verify it
+	approvalCheckPlaintext = "skycoin spending policy approval"
+)
+
+// SpendingPolicyError is returned if a transaction is rejected by the spending policy of a wallet
+type SpendingPolicyError struct {
+	error
+}
+
+var (
+	// ErrSpendingPolicyDestinationNotAllowed is returned if a transaction sends coins to an address that is not in the allowed destinations
+	ErrSpendingPolicyDestinationNotAllowed = SpendingPolicyError{errors.New("spending policy: destination address is not allowed")}
+	// ErrSpendingPolicyMaxCoinsExceeded is returned if a transaction sends more coins than the maximum coins per transaction
+	ErrSpendingPolicyMaxCoinsExceeded = SpendingPolicyError{errors.New("spending policy: transaction exceeds the maximum coins per transaction")}
+	// ErrSpendingPolicyDailyLimitExceeded is returned if a transaction would exceed the 24 hour spend limit
+	ErrSpendingPolicyDailyLimitExceeded = SpendingPolicyError{errors.New("spending policy: transaction exceeds the 24 hour spend limit")}
+	// ErrSpendingPolicyApprovalRequired is returned if a transaction above the approval threshold has not been approved
+	ErrSpendingPolicyApprovalRequired = SpendingPolicyError{errors.New("spending policy: transaction requires a second approval")}
+
+	// ErrSpendingPolicyNotExist is returned if a wallet has no spending policy
+	ErrSpendingPolicyNotExist = NewError(errors.New("wallet has no spending policy"))
+	// ErrInvalidApprovalPassword is returned if the approval password of a spending policy is missing or wrong
+	ErrInvalidApprovalPassword = NewError(errors.New("invalid approval password"))
+	// ErrMissingApprovalPassword is returned if a spending policy has limits but no approval password
+	ErrMissingApprovalPassword = NewError(errors.New("an approval password is required to set spending limits"))
+)
+
+// SpendingLimits are the limits of a spending policy. Amounts are in droplets, and 0 means no limit.
+// Only the coins sent to addresses outside of the wallet count towards the limits.
+type SpendingLimits struct {
+	// MaxCoinsPerTransaction is the maximum amount of coins a transaction can send
+	MaxCoinsPerTransaction uint64
+	// DailyLimit is the maximum amount of coins that can be sent in any 24 hour period
+	DailyLimit uint64
+	// AllowedDestinations are the addresses outside of the wallet that coins can be sent to.
+	// Coins can be sent to any address if empty.
+	AllowedDestinations []cipher.Address
+	// ApprovalThreshold is the amount of coins above which a transaction requires a second approval
+	ApprovalThreshold uint64
+}
+
+// SpendingPolicy is the spending policy of a wallet, enforced before the wallet signs a transaction
+// and when a transaction spending the wallet's outputs is injected.
+// The transactions sent in the last 24 hours, and the approvals of transactions above the approval threshold,
+// are recorded in the policy.
+type SpendingPolicy struct {
+	SpendingLimits
+
+	approvalCryptoType CryptoType
+	approvalCheck      string
+	spends             []policyEvent
+	approvals          []policyEvent
+}
+
+// policyEvent records a spend or an approval of a transaction, identified by its inner hash
+type policyEvent struct {
+	TxnInnerHash cipher.SHA256
+	Coins        uint64
+	Time         int64
+}
+
+// readableSpendingPolicy is the spending policy file format
+type readableSpendingPolicy struct {
+	MaxCoinsPerTransaction uint64                `json:"max_coins_per_transaction"`
+	DailyLimit             uint64                `json:"daily_limit"`
+	AllowedDestinations    []string              `json:"allowed_destinations"`
+	ApprovalThreshold      uint64                `json:"approval_threshold"`
+	ApprovalCryptoType     string                `json:"approval_crypto_type,omitempty"`
+	ApprovalCheck          string                `json:"approval_check,omitempty"`
+	Spends                 []readablePolicyEvent `json:"spends"`
+	Approvals              []readablePolicyEvent `json:"approvals"`
+}
+
+type readablePolicyEvent struct {
+	TxnInnerHash string `json:"txn_inner_hash"`
+	Coins        uint64 `json:"coins,omitempty"`
+	Time         int64  `json:"time"`
+}
+
+// Validate validates the spending policy. A policy with limits must have an approval password,
+// so that the limits can't be changed without it.
+func (p *SpendingPolicy) Validate() error {
+	for _, a := range p.AllowedDestinations {
+		if a.Null() {
+			return NewError(errors.New("allowed destination address is the null address"))
+		}
+	}
+
+	if p.HasLimits() && !p.HasApprovalPassword() {
+		return ErrMissingApprovalPassword
+	}
+
+	return nil
+}
+
+// HasLimits returns whether any of the spending limits is set
+func (l SpendingLimits) HasLimits() bool {
+	return l.MaxCoinsPerTransaction != 0 ||
+		l.DailyLimit != 0 ||
+		len(l.AllowedDestinations) != 0 ||
+		l.ApprovalThreshold != 0
+}
+
+// HasApprovalPassword returns whether the spending policy has an approval password
+func (p *SpendingPolicy) HasApprovalPassword() bool {
+	return p.approvalCheck != ""
+}
+
+func (p *SpendingPolicy) setApprovalPassword(password []byte, cryptoType CryptoType) error {
+	c, err := getCrypto(cryptoType)
+	if err != nil {
+		return err
+	}
+
+	check, err := c.Encrypt([]byte(approvalCheckPlaintext), password)
+	if err != nil {
+		return err
+	}
+
+	p.approvalCryptoType = cryptoType
+	p.approvalCheck = string(check)
+	return nil
+}
+
+// VerifyApprovalPassword returns ErrInvalidApprovalPassword if the password is not the approval password
+func (p *SpendingPolicy) VerifyApprovalPassword(password []byte) error {
+	if !p.HasApprovalPassword() || len(password) == 0 {
+		return ErrInvalidApprovalPassword
+	}
+
+	c, err := getCrypto(p.approvalCryptoType)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := c.Decrypt([]byte(p.approvalCheck), password)
+	if err != nil || string(plaintext) != approvalCheckPlaintext {
+		return ErrInvalidApprovalPassword
+	}
+
+	return nil
+}
+
+// Spent returns the amount of coins sent in the 24 hours before now
+func (p *SpendingPolicy) Spent(now time.Time) uint64 {
+	var spent uint64
+	for _, s := range p.spends {
+		if isInPolicyWindow(s, now) {
+			// Spends are bounded by the coin supply, so this can't overflow
+			spent += s.Coins
+		}
+	}
+	return spent
+}
+
+// IsApproved returns whether the transaction with the inner hash was approved in the 24 hours before now
+func (p *SpendingPolicy) IsApproved(txnInnerHash cipher.SHA256, now time.Time) bool {
+	return findPolicyEvent(p.approvals, txnInnerHash, now)
+}
+
+// Check checks that a transaction spending the wallet's outputs is allowed by the spending policy.
+// A transaction that was already recorded as spent is allowed.
+func (p *SpendingPolicy) Check(w *Wallet, txn *coin.Transaction, now time.Time) error {
+	if findPolicyEvent(p.spends, txn.InnerHash, now) {
+		return nil
+	}
+
+	_, err := p.check(w, txn, now)
+	return err
+}
+
+// check checks the transaction against the spending policy and returns the amount of coins it sends
+func (p *SpendingPolicy) check(w *Wallet, txn *coin.Transaction, now time.Time) (uint64, error) {
+	allowed := make(map[cipher.Address]struct{}, len(p.AllowedDestinations))
+	for _, a := range p.AllowedDestinations {
+		allowed[a] = struct{}{}
+	}
+
+	var coins uint64
+	for _, o := range txn.Out {
+		if w.HasEntry(o.Address) {
+			continue
+		}
+
+		if len(allowed) != 0 {
+			if _, ok := allowed[o.Address]; !ok {
+				return 0, ErrSpendingPolicyDestinationNotAllowed
+			}
+		}
+
+		var err error
+		coins, err = mathutil.AddUint64(coins, o.Coins)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if p.MaxCoinsPerTransaction != 0 && coins > p.MaxCoinsPerTransaction {
+		return 0, ErrSpendingPolicyMaxCoinsExceeded
+	}
+
+	if p.DailyLimit != 0 {
+		spent, err := mathutil.AddUint64(p.Spent(now), coins)
+		if err != nil || spent > p.DailyLimit {
+			return 0, ErrSpendingPolicyDailyLimitExceeded
+		}
+	}
+
+	if p.ApprovalThreshold != 0 && coins > p.ApprovalThreshold && !p.IsApproved(txn.InnerHash, now) {
+		return 0, ErrSpendingPolicyApprovalRequired
+	}
+
+	return coins, nil
+}
+
+// approve records the approval of a transaction
+func (p *SpendingPolicy) approve(txnInnerHash cipher.SHA256, now time.Time) {
+	p.prune(now)
+	if !findPolicyEvent(p.approvals, txnInnerHash, now) {
+		p.approvals = append(p.approvals, policyEvent{
+			TxnInnerHash: txnInnerHash,
+			Time:         now.Unix(),
+		})
+	}
+}
+
+// checkSpend checks a transaction spending the wallet's outputs and returns the spend to record.
+// Returns nil if the transaction was already recorded.
+func (p *SpendingPolicy) checkSpend(w *Wallet, txn *coin.Transaction, now time.Time) (*policyEvent, error) {
+	if findPolicyEvent(p.spends, txn.InnerHash, now) {
+		return nil, nil
+	}
+
+	coins, err := p.check(w, txn, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policyEvent{
+		TxnInnerHash: txn.InnerHash,
+		Coins:        coins,
+		Time:         now.Unix(),
+	}, nil
+}
+
+// withSpends returns a copy of the spending policy with additional spends, which is used for checks only
+func (p *SpendingPolicy) withSpends(spends []policyEvent) *SpendingPolicy {
+	if len(spends) == 0 {
+		return p
+	}
+
+	q := *p
+	q.spends = make([]policyEvent, 0, len(p.spends)+len(spends))
+	q.spends = append(q.spends, p.spends...)
+	q.spends = append(q.spends, spends...)
+	return &q
+}
+
+// prune removes the spends and approvals older than the policy window
+func (p *SpendingPolicy) prune(now time.Time) {
+	prune := func(events []policyEvent) []policyEvent {
+		var kept []policyEvent
+		for _, e := range events {
+			if isInPolicyWindow(e, now) {
+				kept = append(kept, e)
+			}
+		}
+		return kept
+	}
+
+	p.spends = prune(p.spends)
+	p.approvals = prune(p.approvals)
+}
+
+func isInPolicyWindow(e policyEvent, now time.Time) bool {
+	return now.Sub(time.Unix(e.Time, 0)) < SpendingPolicyWindow
+}
+
+func findPolicyEvent(events []policyEvent, txnInnerHash cipher.SHA256, now time.Time) bool {
+	for _, e := range events {
+		if e.TxnInnerHash == txnInnerHash && isInPolicyWindow(e, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSpendingPolicy loads a spending policy file. Returns nil if the file doesn't exist.
+func loadSpendingPolicy(filename string) (*SpendingPolicy, error) {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var r readableSpendingPolicy
+	if err := file.LoadJSON(filename, &r); err != nil {
+		return nil, err
+	}
+
+	p := &SpendingPolicy{
+		SpendingLimits: SpendingLimits{
+			MaxCoinsPerTransaction: r.MaxCoinsPerTransaction,
+			DailyLimit:             r.DailyLimit,
+			ApprovalThreshold:      r.ApprovalThreshold,
+		},
+		approvalCryptoType: CryptoType(r.ApprovalCryptoType),
+		approvalCheck:      r.ApprovalCheck,
+	}
+
+	for _, s := range r.AllowedDestinations {
+		a, err := cipher.DecodeBase58Address(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed destination address %q: %v", s, err)
+		}
+		p.AllowedDestinations = append(p.AllowedDestinations, a)
+	}
+
+	var err error
+	p.spends, err = policyEventsFromReadable(r.Spends)
+	if err != nil {
+		return nil, err
+	}
+	p.approvals, err = policyEventsFromReadable(r.Approvals)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// save saves the spending policy file. The file is written to a temporary file first and then renamed,
+// so that a failed write doesn't leave a truncated policy behind.
+func (p *SpendingPolicy) save(filename string) error {
+	r := readableSpendingPolicy{
+		MaxCoinsPerTransaction: p.MaxCoinsPerTransaction,
+		DailyLimit:             p.DailyLimit,
+		AllowedDestinations:    make([]string, len(p.AllowedDestinations)),
+		ApprovalThreshold:      p.ApprovalThreshold,
+		ApprovalCryptoType:     string(p.approvalCryptoType),
+		ApprovalCheck:          p.approvalCheck,
+		Spends:                 policyEventsToReadable(p.spends),
+		Approvals:              policyEventsToReadable(p.approvals),
+	}
+
+	for i, a := range p.AllowedDestinations {
+		r.AllowedDestinations[i] = a.String()
+	}
+
+	data, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	tmpname := filename + ".tmp"
+	if err := ioutil.WriteFile(tmpname, data, 0600); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpname, filename); err != nil {
+		if removeErr := os.Remove(tmpname); removeErr != nil {
+			logger.WithError(removeErr).Warningf("os.Remove(%s) failed", tmpname)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// addSpend records a spend, unless a spend of the same transaction is already recorded
+func (p *SpendingPolicy) addSpend(s policyEvent, now time.Time) {
+	p.prune(now)
+	if !findPolicyEvent(p.spends, s.TxnInnerHash, now) {
+		p.spends = append(p.spends, s)
+	}
+}
+
+func policyEventsFromReadable(r []readablePolicyEvent) ([]policyEvent, error) {
+	events := make([]policyEvent, len(r))
+	for i, e := range r {
+		h, err := cipher.SHA256FromHex(e.TxnInnerHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction inner hash %q: %v", e.TxnInnerHash, err)
+		}
+		events[i] = policyEvent{
+			TxnInnerHash: h,
+			Coins:        e.Coins,
+			Time:         e.Time,
+		}
+	}
+	return events, nil
+}
+
+func policyEventsToReadable(events []policyEvent) []readablePolicyEvent {
+	r := make([]readablePolicyEvent, len(events))
+	for i, e := range events {
+		r[i] = readablePolicyEvent{
+			TxnInnerHash: e.TxnInnerHash.Hex(),
+			Coins:        e.Coins,
+			Time:         e.Time,
+		}
+	}
+	return r
+}
@@ -0,0 +1,246 @@
+package wallet
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func makePolicyTxn(outs ...coin.TransactionOutput) *coin.Transaction {
+	txn := &coin.Transaction{
+		Out: outs,
+	}
+	txn.InnerHash = txn.HashInner()
+	return txn
+}
+
+func TestSpendingPolicyCheck(t *testing.T) {
+	w, err := NewWallet("t.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	own := w.Entries[0].SkycoinAddress()
+
+	dest := testutil.MakeAddress()
+	other := testutil.MakeAddress()
+	now := time.Unix(1e9, 0)
+
+	tt := []struct {
+		name     string
+		limits   SpendingLimits
+		spent    uint64
+		approved bool
+		txn      *coin.Transaction
+		err      error
+	}{
+		{
+			name: "no limits",
+			txn:  makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 100e6}),
+		},
+		{
+			name:   "change is not counted",
+			limits: SpendingLimits{MaxCoinsPerTransaction: 1e6},
+			txn: makePolicyTxn(
+				coin.TransactionOutput{Address: dest, Coins: 1e6},
+				coin.TransactionOutput{Address: own, Coins: 100e6},
+			),
+		},
+		{
+			name:   "max coins per transaction exceeded",
+			limits: SpendingLimits{MaxCoinsPerTransaction: 1e6},
+			txn:    makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 2e6}),
+			err:    ErrSpendingPolicyMaxCoinsExceeded,
+		},
+		{
+			name:   "daily limit ok",
+			limits: SpendingLimits{DailyLimit: 10e6},
+			spent:  9e6,
+			txn:    makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 1e6}),
+		},
+		{
+			name:   "daily limit exceeded",
+			limits: SpendingLimits{DailyLimit: 10e6},
+			spent:  9e6,
+			txn:    makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 2e6}),
+			err:    ErrSpendingPolicyDailyLimitExceeded,
+		},
+		{
+			name:   "allowed destination",
+			limits: SpendingLimits{AllowedDestinations: []cipher.Address{dest}},
+			txn: makePolicyTxn(
+				coin.TransactionOutput{Address: dest, Coins: 1e6},
+				coin.TransactionOutput{Address: own, Coins: 1e6},
+			),
+		},
+		{
+			name:   "destination not allowed",
+			limits: SpendingLimits{AllowedDestinations: []cipher.Address{dest}},
+			txn: makePolicyTxn(
+				coin.TransactionOutput{Address: dest, Coins: 1e6},
+				coin.TransactionOutput{Address: other, Coins: 1e6},
+			),
+			err: ErrSpendingPolicyDestinationNotAllowed,
+		},
+		{
+			name:   "below approval threshold",
+			limits: SpendingLimits{ApprovalThreshold: 5e6},
+			txn:    makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 5e6}),
+		},
+		{
+			name:   "approval required",
+			limits: SpendingLimits{ApprovalThreshold: 5e6},
+			txn:    makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 6e6}),
+			err:    ErrSpendingPolicyApprovalRequired,
+		},
+		{
+			name:     "approved",
+			limits:   SpendingLimits{ApprovalThreshold: 5e6},
+			approved: true,
+			txn:      makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 6e6}),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &SpendingPolicy{
+				SpendingLimits: tc.limits,
+			}
+			require.NoError(t, p.setApprovalPassword([]byte("approve"), CryptoTypeSha256Xor))
+
+			if tc.spent != 0 {
+				p.spends = []policyEvent{{
+					TxnInnerHash: testutil.RandSHA256(t),
+					Coins:        tc.spent,
+					Time:         now.Add(-time.Hour).Unix(),
+				}}
+			}
+			if tc.approved {
+				p.approve(tc.txn.InnerHash, now.Add(-time.Hour))
+			}
+
+			err := p.Check(w, tc.txn, now)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestSpendingPolicyCheckSpend(t *testing.T) {
+	w, err := NewWallet("t.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+
+	p := &SpendingPolicy{
+		SpendingLimits: SpendingLimits{DailyLimit: 10e6},
+	}
+	now := time.Unix(1e9, 0)
+	dest := testutil.MakeAddress()
+
+	txn := makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 6e6})
+	spend, err := p.checkSpend(w, txn, now)
+	require.NoError(t, err)
+	require.Equal(t, &policyEvent{
+		TxnInnerHash: txn.InnerHash,
+		Coins:        6e6,
+		Time:         now.Unix(),
+	}, spend)
+	require.Equal(t, uint64(0), p.Spent(now))
+
+	// Spends not recorded yet are counted by a copy of the policy
+	q := p.withSpends([]policyEvent{*spend})
+	require.Equal(t, uint64(6e6), q.Spent(now))
+	require.Equal(t, uint64(0), p.Spent(now))
+
+	p.addSpend(*spend, now)
+	p.addSpend(*spend, now)
+	require.Equal(t, uint64(6e6), p.Spent(now))
+
+	// A recorded transaction is allowed and not counted twice
+	require.NoError(t, p.Check(w, txn, now))
+	spend, err = p.checkSpend(w, txn, now)
+	require.NoError(t, err)
+	require.Nil(t, spend)
+
+	txn2 := makePolicyTxn(coin.TransactionOutput{Address: dest, Coins: 5e6})
+	_, err = p.checkSpend(w, txn2, now)
+	require.Equal(t, ErrSpendingPolicyDailyLimitExceeded, err)
+
+	// The spend leaves the rolling window after 24 hours
+	later := now.Add(SpendingPolicyWindow)
+	require.Equal(t, uint64(0), p.Spent(later))
+	spend, err = p.checkSpend(w, txn2, later)
+	require.NoError(t, err)
+	require.NotNil(t, spend)
+	p.addSpend(*spend, later)
+	require.Len(t, p.spends, 1)
+}
+
+func TestSpendingPolicyApprovalPassword(t *testing.T) {
+	p := &SpendingPolicy{
+		SpendingLimits: SpendingLimits{ApprovalThreshold: 1e6},
+	}
+	require.Equal(t, ErrMissingApprovalPassword, p.Validate())
+	require.Equal(t, ErrInvalidApprovalPassword, p.VerifyApprovalPassword([]byte("approve")))
+
+	require.NoError(t, p.setApprovalPassword([]byte("approve"), CryptoTypeSha256Xor))
+	require.NoError(t, p.Validate())
+	require.NoError(t, p.VerifyApprovalPassword([]byte("approve")))
+	require.Equal(t, ErrInvalidApprovalPassword, p.VerifyApprovalPassword([]byte("wrong")))
+	require.Equal(t, ErrInvalidApprovalPassword, p.VerifyApprovalPassword(nil))
+
+	// Any limit requires an approval password
+	for _, limits := range []SpendingLimits{
+		{MaxCoinsPerTransaction: 1e6},
+		{DailyLimit: 1e6},
+		{AllowedDestinations: []cipher.Address{testutil.MakeAddress()}},
+	} {
+		q := &SpendingPolicy{
+			SpendingLimits: limits,
+		}
+		require.Equal(t, ErrMissingApprovalPassword, q.Validate())
+	}
+	require.NoError(t, (&SpendingPolicy{}).Validate())
+
+	p.AllowedDestinations = []cipher.Address{{}}
+	require.Equal(t, NewError(errors.New("allowed destination address is the null address")), p.Validate())
+}
+
+func TestSpendingPolicySaveLoad(t *testing.T) {
+	dir := prepareWltDir()
+	filename := filepath.Join(dir, "t.wlt"+SpendingPolicyExt)
+
+	p, err := loadSpendingPolicy(filename)
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	now := time.Now()
+	p = &SpendingPolicy{
+		SpendingLimits: SpendingLimits{
+			MaxCoinsPerTransaction: 1e6,
+			DailyLimit:             10e6,
+			AllowedDestinations:    []cipher.Address{testutil.MakeAddress()},
+			ApprovalThreshold:      5e6,
+		},
+	}
+	require.NoError(t, p.setApprovalPassword([]byte("approve"), CryptoTypeSha256Xor))
+	p.approve(testutil.RandSHA256(t), now)
+	p.spends = []policyEvent{{
+		TxnInnerHash: testutil.RandSHA256(t),
+		Coins:        1e6,
+		Time:         now.Unix(),
+	}}
+	require.NoError(t, p.save(filename))
+	testutil.RequireFileNotExists(t, filename+".tmp")
+
+	lp, err := loadSpendingPolicy(filename)
+	require.NoError(t, err)
+	require.Equal(t, p, lp)
+	require.NoError(t, lp.VerifyApprovalPassword([]byte("approve")))
+}
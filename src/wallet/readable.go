@@ -10,13 +10,20 @@ import (
 
 // ReadableEntry wallet entry with json tags
 type ReadableEntry struct {
-	Address string `json:"address"`
-	Public  string `json:"public_key"`
-	Secret  string `json:"secret_key"`
+	Address     string  `json:"address"`
+	Public      string  `json:"public_key"`
+	Secret      string  `json:"secret_key"`
+	ChildNumber *uint32 `json:"child_number,omitempty"` // For bip44
+	Change      *uint32 `json:"change,omitempty"`       // For bip44
+
+	Label   string   `json:"label,omitempty"`
+	Created int64    `json:"created,omitempty"`
+	Purpose string   `json:"purpose,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
 }
 
 // NewReadableEntry creates readable wallet entry
-func NewReadableEntry(coinType CoinType, w Entry) ReadableEntry {
+func NewReadableEntry(coinType CoinType, walletType string, w Entry) ReadableEntry {
 	re := ReadableEntry{}
 	if !w.Address.Null() {
 		re.Address = w.Address.String()
@@ -37,6 +44,24 @@ func NewReadableEntry(coinType CoinType, w Entry) ReadableEntry {
 		}
 	}
 
+	switch walletType {
+	case WalletTypeBip44:
+		childNumber := w.ChildNumber
+		change := w.Change
+		re.ChildNumber = &childNumber
+		re.Change = &change
+	case WalletTypeXPub:
+		childNumber := w.ChildNumber
+		re.ChildNumber = &childNumber
+	}
+
+	re.Label = w.Label
+	re.Created = w.Created
+	re.Purpose = w.Purpose
+	if len(w.Tags) != 0 {
+		re.Tags = append([]string{}, w.Tags...)
+	}
+
 	return re
 }
 
@@ -104,11 +129,34 @@ func newEntryFromReadable(coinType CoinType, w *ReadableEntry) (*Entry, error) {
 		}
 	}
 
-	return &Entry{
+	e := &Entry{
 		Address: a,
 		Public:  p,
 		Secret:  secret,
-	}, nil
+		Label:   w.Label,
+		Created: w.Created,
+		Purpose: w.Purpose,
+	}
+
+	if len(w.Tags) != 0 {
+		e.Tags = append([]string{}, w.Tags...)
+	}
+
+	if err := (EntryMetaUpdate{
+		Purpose: &e.Purpose,
+		Tags:    &e.Tags,
+	}).Validate(); err != nil {
+		return nil, fmt.Errorf("address %s: %v", w.Address, err)
+	}
+
+	if w.ChildNumber != nil {
+		e.ChildNumber = *w.ChildNumber
+	}
+	if w.Change != nil {
+		e.Change = *w.Change
+	}
+
+	return e, nil
 }
 
 // ReadableWallet used for [de]serialization of a Wallet
@@ -121,7 +169,7 @@ type ReadableWallet struct {
 func NewReadableWallet(w *Wallet) *ReadableWallet {
 	readable := make(ReadableEntries, len(w.Entries))
 	for i, e := range w.Entries {
-		readable[i] = NewReadableEntry(w.coin(), e)
+		readable[i] = NewReadableEntry(w.coin(), w.Type(), e)
 	}
 
 	meta := make(map[string]string, len(w.Meta))
@@ -189,6 +237,7 @@ func (rw *ReadableWallet) filename() string {
 func (rw *ReadableWallet) Erase() {
 	delete(rw.Meta, metaSeed)
 	delete(rw.Meta, metaLastSeed)
+	delete(rw.Meta, metaSeedPassphrase)
 	delete(rw.Meta, metaSecrets)
 	for i := range rw.Entries {
 		rw.Entries[i].Secret = ""
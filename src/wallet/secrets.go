@@ -4,8 +4,9 @@ import "encoding/json"
 
 // secrets key name
 const (
-	secretSeed     = "seed"
-	secretLastSeed = "lastSeed"
+	secretSeed           = "seed"
+	secretLastSeed       = "lastSeed"
+	secretSeedPassphrase = "seedPassphrase"
 )
 
 type secrets map[string]string
@@ -0,0 +1,269 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/cipher/bip39/wordlists"
+	"github.com/skycoin/skycoin/src/cipher/shamir"
+)
+
+/*
+Seed shares split a wallet seed with Shamir's secret sharing into share mnemonics,
+any threshold of which recover the seed.
+
+A share mnemonic encodes the following bytes with the bip39 english wordlist, 11 bits per word,
+the last word being padded with zero bits:
+
+	version    1 byte, 0
+	flags      1 byte, seedShareFlagEntropy if the shared secret is the entropy of a bip39 mnemonic seed,
+	           otherwise the secret is the seed string
+	id         2 bytes, random identifier shared by the shares of a split
+	threshold  1 byte
+	x          1 byte, the x coordinate of the share
+	length     1 byte, the length of the share value
+	value      length bytes
+	checksum   4 bytes, the first bytes of the SHA256 of the previous bytes
+
+Sharing the entropy of a bip39 mnemonic seed instead of the seed string makes the shares shorter,
+20 words for a 12 word seed.
+*/
+
+const (
+	seedShareVersion     = 0
+	seedShareFlagEntropy = 1
+
+	seedShareHeaderSize   = 7
+	seedShareChecksumSize = 4
+	seedShareIDSize       = 2
+
+	seedShareWordBits = 11
+)
+
+var (
+	// ErrInvalidSeedShare is returned if a seed share mnemonic is malformed or its checksum is wrong
+	ErrInvalidSeedShare = NewError(errors.New("invalid seed share"))
+	// ErrSeedSharesMismatch is returned if seed shares are not from the same split
+	ErrSeedSharesMismatch = NewError(errors.New("seed shares are not from the same seed"))
+	// ErrNotEnoughSeedShares is returned if fewer seed shares than their threshold are combined
+	ErrNotEnoughSeedShares = NewError(errors.New("not enough seed shares to recover the seed"))
+	// ErrDuplicateSeedShare is returned if the same seed share is combined twice
+	ErrDuplicateSeedShare = NewError(errors.New("duplicate seed share"))
+	// ErrInvalidSeedSharesThreshold is returned if the threshold of a seed split is not between 1 and the number of shares
+	ErrInvalidSeedSharesThreshold = NewError(shamir.ErrInvalidThreshold)
+	// ErrTooManySeedShares is returned if a seed is split into more than shamir.MaxShares shares
+	ErrTooManySeedShares = NewError(shamir.ErrTooManyShares)
+	// ErrSeedTooLong is returned if a seed is too long to be split
+	ErrSeedTooLong = NewError(errors.New("seed is too long to be split into shares"))
+)
+
+var seedShareWordIndex map[string]int
+
+func init() {
+	seedShareWordIndex = make(map[string]int, len(wordlists.English))
+	for i, w := range wordlists.English {
+		seedShareWordIndex[w] = i
+	}
+}
+
+// seedShare is a decoded seed share
+type seedShare struct {
+	flags     byte
+	id        [seedShareIDSize]byte
+	threshold byte
+	value     []byte // x coordinate followed by the share value, as returned by shamir.Split
+}
+
+// SplitSeed splits a seed into n share mnemonics, any threshold of which recover the seed with CombineSeedShares
+func SplitSeed(seed string, n, threshold int) ([]string, error) {
+	if seed == "" {
+		return nil, ErrMissingSeed
+	}
+
+	var flags byte
+	secret := []byte(seed)
+	if bip39.ValidateMnemonic(seed) == nil {
+		// Share the entropy only if the mnemonic is recovered exactly
+		entropy, err := bip39.EntropyFromMnemonic(seed)
+		if err == nil {
+			if m, err := bip39.NewMnemonic(entropy); err == nil && m == seed {
+				flags = seedShareFlagEntropy
+				secret = entropy
+			}
+		}
+	}
+
+	if len(secret) > 255 {
+		return nil, ErrSeedTooLong
+	}
+
+	values, err := shamir.Split(secret, n, threshold)
+	switch err {
+	case nil:
+	case shamir.ErrInvalidThreshold:
+		return nil, ErrInvalidSeedSharesThreshold
+	case shamir.ErrTooManyShares:
+		return nil, ErrTooManySeedShares
+	default:
+		return nil, err
+	}
+
+	var id [seedShareIDSize]byte
+	copy(id[:], cipher.RandByte(seedShareIDSize))
+
+	shares := make([]string, n)
+	for i, v := range values {
+		shares[i] = encodeSeedShare(seedShare{
+			flags:     flags,
+			id:        id,
+			threshold: byte(threshold),
+			value:     v,
+		})
+	}
+
+	return shares, nil
+}
+
+// CombineSeedShares recovers a seed from share mnemonics created by SplitSeed
+func CombineSeedShares(shares []string) (string, error) {
+	if len(shares) == 0 {
+		return "", ErrNotEnoughSeedShares
+	}
+
+	decoded := make([]seedShare, len(shares))
+	values := make([][]byte, len(shares))
+	for i, s := range shares {
+		share, err := decodeSeedShare(s)
+		if err != nil {
+			return "", err
+		}
+
+		if i > 0 {
+			first := decoded[0]
+			if share.id != first.id || share.flags != first.flags || share.threshold != first.threshold ||
+				len(share.value) != len(first.value) {
+				return "", ErrSeedSharesMismatch
+			}
+		}
+
+		decoded[i] = share
+		values[i] = share.value
+	}
+
+	if len(shares) < int(decoded[0].threshold) {
+		return "", ErrNotEnoughSeedShares
+	}
+
+	secret, err := shamir.Combine(values)
+	switch err {
+	case nil:
+	case shamir.ErrDuplicateShare:
+		return "", ErrDuplicateSeedShare
+	default:
+		return "", ErrInvalidSeedShare
+	}
+
+	if decoded[0].flags&seedShareFlagEntropy != 0 {
+		seed, err := bip39.NewMnemonic(secret)
+		if err != nil {
+			return "", ErrInvalidSeedShare
+		}
+		return seed, nil
+	}
+
+	return string(secret), nil
+}
+
+func encodeSeedShare(s seedShare) string {
+	b := make([]byte, 0, seedShareHeaderSize+len(s.value)-1+seedShareChecksumSize)
+	b = append(b, seedShareVersion, s.flags)
+	b = append(b, s.id[:]...)
+	b = append(b, s.threshold, s.value[0], byte(len(s.value)-1))
+	b = append(b, s.value[1:]...)
+
+	h := cipher.SumSHA256(b)
+	b = append(b, h[:seedShareChecksumSize]...)
+
+	// Encode 11 bits per word, most significant bit first
+	words := make([]string, seedShareWords(len(b)))
+	for i := range words {
+		var idx int
+		for j := 0; j < seedShareWordBits; j++ {
+			bit := i*seedShareWordBits + j
+			idx <<= 1
+			if bit < len(b)*8 && b[bit/8]&(0x80>>uint(bit%8)) != 0 {
+				idx |= 1
+			}
+		}
+		words[i] = wordlists.English[idx]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// seedShareWords returns the number of words encoding n bytes
+func seedShareWords(n int) int {
+	return (n*8 + seedShareWordBits - 1) / seedShareWordBits
+}
+
+func decodeSeedShare(mnemonic string) (seedShare, error) {
+	words := strings.Fields(mnemonic)
+
+	nBits := len(words) * seedShareWordBits
+	b := make([]byte, nBits/8)
+	for i, w := range words {
+		idx, ok := seedShareWordIndex[strings.ToLower(w)]
+		if !ok {
+			return seedShare{}, ErrInvalidSeedShare
+		}
+
+		for j := 0; j < seedShareWordBits; j++ {
+			bit := i*seedShareWordBits + j
+			if idx&(1<<uint(seedShareWordBits-1-j)) == 0 {
+				continue
+			}
+			if bit >= len(b)*8 {
+				// Padding bits must be zero
+				return seedShare{}, ErrInvalidSeedShare
+			}
+			b[bit/8] |= 0x80 >> uint(bit%8)
+		}
+	}
+
+	if len(b) < seedShareHeaderSize+seedShareChecksumSize || b[0] != seedShareVersion {
+		return seedShare{}, ErrInvalidSeedShare
+	}
+
+	n := seedShareHeaderSize + int(b[6])
+	if len(words) != seedShareWords(n+seedShareChecksumSize) {
+		return seedShare{}, ErrInvalidSeedShare
+	}
+
+	// The last word may decode to a byte of zero padding bits
+	for _, x := range b[n+seedShareChecksumSize:] {
+		if x != 0 {
+			return seedShare{}, ErrInvalidSeedShare
+		}
+	}
+
+	h := cipher.SumSHA256(b[:n])
+	if !bytes.Equal(h[:seedShareChecksumSize], b[n:n+seedShareChecksumSize]) {
+		return seedShare{}, ErrInvalidSeedShare
+	}
+
+	s := seedShare{
+		flags:     b[1],
+		threshold: b[4],
+		value:     append([]byte{b[5]}, b[seedShareHeaderSize:n]...),
+	}
+	copy(s.id[:], b[2:4])
+
+	if s.threshold == 0 || s.value[0] == 0 || len(s.value) < 2 {
+		return seedShare{}, ErrInvalidSeedShare
+	}
+
+	return s, nil
+}
@@ -0,0 +1,193 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+)
+
+func TestSplitSeed(t *testing.T) {
+	mnemonic12 := bip39.MustNewDefaultMnemonic()
+	entropy, err := bip39.NewEntropy(256)
+	require.NoError(t, err)
+	mnemonic24, err := bip39.NewMnemonic(entropy)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		seed      string
+		n         int
+		threshold int
+		words     int
+	}{
+		{
+			name:      "12 word mnemonic",
+			seed:      mnemonic12,
+			n:         3,
+			threshold: 2,
+			words:     20,
+		},
+		{
+			name:      "24 word mnemonic",
+			seed:      mnemonic24,
+			n:         5,
+			threshold: 3,
+			words:     32,
+		},
+		{
+			name:      "mnemonic with extra whitespace",
+			seed:      strings.Replace(mnemonic12, " ", "  ", 1),
+			n:         2,
+			threshold: 2,
+		},
+		{
+			name:      "alphanumeric seed",
+			seed:      "8a1f5a3c4c0bf8e0e5b4dfd0a6c2e2e1b7f9f3e4d6a8c0b2d4f6a8c0e2f4a6b8",
+			n:         4,
+			threshold: 4,
+		},
+		{
+			name:      "one share",
+			seed:      "seed",
+			n:         1,
+			threshold: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			shares, err := SplitSeed(tc.seed, tc.n, tc.threshold)
+			require.NoError(t, err)
+			require.Len(t, shares, tc.n)
+
+			for _, s := range shares {
+				if tc.words != 0 {
+					require.Len(t, strings.Fields(s), tc.words)
+				}
+				require.NotContains(t, s, tc.seed)
+			}
+
+			for i := 0; i+tc.threshold <= tc.n; i++ {
+				seed, err := CombineSeedShares(shares[i : i+tc.threshold])
+				require.NoError(t, err)
+				require.Equal(t, tc.seed, seed)
+			}
+
+			// Shares are recognized regardless of case and whitespace
+			shares[0] = " " + strings.ToUpper(strings.Replace(shares[0], " ", "\n", -1)) + " "
+			seed, err := CombineSeedShares(shares)
+			require.NoError(t, err)
+			require.Equal(t, tc.seed, seed)
+
+			if tc.threshold > 1 {
+				_, err = CombineSeedShares(shares[:tc.threshold-1])
+				require.Equal(t, ErrNotEnoughSeedShares, err)
+			}
+		})
+	}
+}
+
+func TestSplitSeedInvalid(t *testing.T) {
+	_, err := SplitSeed("", 3, 2)
+	require.Equal(t, ErrMissingSeed, err)
+
+	_, err = SplitSeed("seed", 3, 0)
+	require.Equal(t, ErrInvalidSeedSharesThreshold, err)
+
+	_, err = SplitSeed("seed", 2, 3)
+	require.Equal(t, ErrInvalidSeedSharesThreshold, err)
+
+	_, err = SplitSeed("seed", 256, 3)
+	require.Equal(t, ErrTooManySeedShares, err)
+
+	_, err = SplitSeed(strings.Repeat("a", 256), 3, 2)
+	require.Equal(t, ErrSeedTooLong, err)
+}
+
+func TestCombineSeedSharesInvalid(t *testing.T) {
+	shares, err := SplitSeed(bip39.MustNewDefaultMnemonic(), 3, 2)
+	require.NoError(t, err)
+
+	otherShares, err := SplitSeed(bip39.MustNewDefaultMnemonic(), 3, 2)
+	require.NoError(t, err)
+
+	words := strings.Fields(shares[1])
+
+	// Changes a word of the share
+	changed := append([]string{}, words...)
+	if changed[3] == "abandon" {
+		changed[3] = "ability"
+	} else {
+		changed[3] = "abandon"
+	}
+
+	// Sets the padding bits of the last word
+	padded := append([]string{}, words...)
+	padded[len(padded)-1] = "zoo"
+
+	cases := []struct {
+		name   string
+		shares []string
+		err    error
+	}{
+		{
+			name: "no shares",
+			err:  ErrNotEnoughSeedShares,
+		},
+		{
+			name:   "not enough shares",
+			shares: shares[:1],
+			err:    ErrNotEnoughSeedShares,
+		},
+		{
+			name:   "duplicate share",
+			shares: []string{shares[0], shares[0]},
+			err:    ErrDuplicateSeedShare,
+		},
+		{
+			name:   "shares of different seeds",
+			shares: []string{shares[0], otherShares[1]},
+			err:    ErrSeedSharesMismatch,
+		},
+		{
+			name:   "unknown word",
+			shares: []string{shares[0], strings.Join(append(words[1:], "skycoin"), " ")},
+			err:    ErrInvalidSeedShare,
+		},
+		{
+			name:   "wrong checksum",
+			shares: []string{shares[0], strings.Join(changed, " ")},
+			err:    ErrInvalidSeedShare,
+		},
+		{
+			name:   "missing word",
+			shares: []string{shares[0], strings.Join(words[:len(words)-1], " ")},
+			err:    ErrInvalidSeedShare,
+		},
+		{
+			name:   "extra word",
+			shares: []string{shares[0], strings.Join(append(words, "abandon"), " ")},
+			err:    ErrInvalidSeedShare,
+		},
+		{
+			name:   "non-zero padding",
+			shares: []string{shares[0], strings.Join(padded, " ")},
+			err:    ErrInvalidSeedShare,
+		},
+		{
+			name:   "bip39 mnemonic",
+			shares: []string{shares[0], bip39.MustNewDefaultMnemonic()},
+			err:    ErrInvalidSeedShare,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CombineSeedShares(tc.shares)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
@@ -2,10 +2,19 @@ package wallet
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip44"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/file"
 )
 
 // BalanceGetter interface for getting the balance of given addresses
@@ -13,6 +22,11 @@ type BalanceGetter interface {
 	GetBalanceOfAddrs(addrs []cipher.Address) ([]BalancePair, error)
 }
 
+// TransactionsFinder interface for checking whether addresses have any transaction history
+type TransactionsFinder interface {
+	AddressesActivity(addrs []cipher.Address) ([]bool, error)
+}
+
 // Service wallet service struct
 type Service struct {
 	sync.RWMutex
@@ -20,14 +34,25 @@ type Service struct {
 	config  Config
 	// firstAddrIDMap Key: first address in wallet; Value: wallet id
 	firstAddrIDMap map[string]string
+	// walletDirEvents are the recent changes applied by the wallet directory watcher
+	walletDirEvents []WalletDirEvent
+	// pendingSpends are the spends recorded by RecordSpendingPolicies which are not saved yet,
+	// keyed by spending policy filename
+	pendingSpends map[string][]policyEvent
+	quit          chan struct{}
+	done          chan struct{}
 }
 
 // Config wallet service config
 type Config struct {
-	WalletDir       string
-	CryptoType      CryptoType
+	WalletDir  string
+	CryptoType CryptoType
+	// Argon2idParams are the argon2id parameters of wallets encrypted with the argon2id-chacha20poly1305 crypto type
+	Argon2idParams  Argon2idParams
 	EnableWalletAPI bool
 	EnableSeedAPI   bool
+	// WatchWalletDir enables loading and unloading the wallet files added to or removed from WalletDir while running
+	WatchWalletDir bool
 }
 
 // NewConfig creates a default Config
@@ -35,8 +60,10 @@ func NewConfig() Config {
 	return Config{
 		WalletDir:       "./",
 		CryptoType:      CryptoTypeScryptChacha20poly1305,
+		Argon2idParams:  DefaultArgon2idParams,
 		EnableWalletAPI: false,
 		EnableSeedAPI:   false,
+		WatchWalletDir:  false,
 	}
 }
 
@@ -45,6 +72,13 @@ func NewService(c Config) (*Service, error) {
 	serv := &Service{
 		config:         c,
 		firstAddrIDMap: make(map[string]string),
+		pendingSpends:  make(map[string][]policyEvent),
+		quit:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+
+	if serv.config.Argon2idParams == (Argon2idParams{}) {
+		serv.config.Argon2idParams = DefaultArgon2idParams
 	}
 
 	if !serv.config.EnableWalletAPI {
@@ -111,6 +145,9 @@ func (serv *Service) loadWallet(wltName string, options Options, bg BalanceGette
 	// service decides what crypto type the wallet should use.
 	if options.Encrypt {
 		options.CryptoType = serv.config.CryptoType
+		if options.Argon2idParams == nil {
+			options.Argon2idParams = &serv.config.Argon2idParams
+		}
 	}
 
 	w, err := NewWalletScanAhead(wltName, options, bg)
@@ -118,9 +155,13 @@ func (serv *Service) loadWallet(wltName string, options Options, bg BalanceGette
 		return nil, err
 	}
 
-	// Check for duplicate wallets by initial seed
-	if _, ok := serv.firstAddrIDMap[w.Entries[0].Address.String()]; ok {
-		return nil, ErrSeedUsed
+	// Check for duplicate wallets by initial seed.
+	// Collection wallets have no seed and start empty.
+	hasSeed := w.Type() != WalletTypeCollection
+	if hasSeed {
+		if _, ok := serv.firstAddrIDMap[w.Entries[0].Address.String()]; ok {
+			return nil, ErrSeedUsed
+		}
 	}
 
 	if err := serv.wallets.add(w); err != nil {
@@ -133,7 +174,9 @@ func (serv *Service) loadWallet(wltName string, options Options, bg BalanceGette
 		return nil, err
 	}
 
-	serv.firstAddrIDMap[w.Entries[0].Address.String()] = w.Filename()
+	if hasSeed {
+		serv.firstAddrIDMap[w.Entries[0].Address.String()] = w.Filename()
+	}
 
 	return w.clone(), nil
 }
@@ -150,8 +193,10 @@ func (serv *Service) generateUniqueWalletFilename() string {
 	return wltName
 }
 
-// EncryptWallet encrypts wallet with password
-func (serv *Service) EncryptWallet(wltID string, password []byte) (*Wallet, error) {
+// EncryptWallet encrypts wallet with password.
+// If cryptoType is empty, the service's default crypto type is used.
+// If argon2idParams is nil, the service's argon2id parameters are used by the argon2id-chacha20poly1305 crypto type.
+func (serv *Service) EncryptWallet(wltID string, password []byte, cryptoType CryptoType, argon2idParams *Argon2idParams) (*Wallet, error) {
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -167,7 +212,15 @@ func (serv *Service) EncryptWallet(wltID string, password []byte) (*Wallet, erro
 		return nil, ErrWalletEncrypted
 	}
 
-	if err := w.Lock(password, serv.config.CryptoType); err != nil {
+	if cryptoType == "" {
+		cryptoType = serv.config.CryptoType
+	}
+
+	if argon2idParams == nil {
+		argon2idParams = &serv.config.Argon2idParams
+	}
+
+	if err := w.lock(password, cryptoType, argon2idParams); err != nil {
 		return nil, err
 	}
 
@@ -205,6 +258,9 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (*Wallet, erro
 		return nil, err
 	}
 
+	// The argon2id parameters are only recorded in encrypted wallets
+	unlockWlt.removeArgon2idParams()
+
 	// Updates the wallet file
 	if err := unlockWlt.Save(serv.config.WalletDir); err != nil {
 		return nil, err
@@ -215,6 +271,49 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (*Wallet, erro
 	return unlockWlt, nil
 }
 
+// ChangePassword re-encrypts an encrypted wallet with a new password, optionally migrating
+// it to another crypto type. If cryptoType is empty, the wallet's crypto type is kept.
+// A wallet migrated to the argon2id-chacha20poly1305 crypto type uses the service's argon2id parameters.
+// The previous wallet file is backed up to <filename>.bak before the wallet is saved.
+func (serv *Service) ChangePassword(wltID string, oldPassword, newPassword []byte, cryptoType CryptoType) (*Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	var argon2idParams *Argon2idParams
+	if cryptoType != "" && cryptoType != w.cryptoType() {
+		argon2idParams = &serv.config.Argon2idParams
+	}
+
+	if err := w.changePassword(oldPassword, newPassword, cryptoType, argon2idParams); err != nil {
+		return nil, err
+	}
+
+	// Backs up the wallet file encrypted with the old password
+	wltFile := filepath.Join(serv.config.WalletDir, w.Filename())
+	b, err := ioutil.ReadFile(wltFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.SaveBinary(wltFile+".bak", b, 0600); err != nil {
+		return nil, err
+	}
+
+	if err := w.Save(serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return w, nil
+}
+
 // NewAddresses generate address entries in given wallet,
 // return nil if wallet does not exist.
 // Set password as nil if the wallet is not encrypted, otherwise the password must be provided.
@@ -313,6 +412,38 @@ func (serv *Service) GetWallets() (Wallets, error) {
 	return wlts, nil
 }
 
+// AddressWallets returns the sorted IDs of the loaded wallets which contain each of the addresses.
+// Addresses not contained in any wallet are omitted. No wallets are returned if the wallet API is disabled.
+func (serv *Service) AddressWallets(addrs []cipher.Address) map[cipher.Address][]string {
+	serv.RLock()
+	defer serv.RUnlock()
+
+	m := make(map[cipher.Address][]string)
+	if !serv.config.EnableWalletAPI {
+		return m
+	}
+
+	for _, a := range addrs {
+		if _, ok := m[a]; ok {
+			continue
+		}
+
+		var ids []string
+		for id, w := range serv.wallets {
+			if w.HasEntry(a) {
+				ids = append(ids, id)
+			}
+		}
+
+		if len(ids) > 0 {
+			sort.Strings(ids)
+			m[a] = ids
+		}
+	}
+
+	return m
+}
+
 // UpdateWalletLabel updates the wallet label
 func (serv *Service) UpdateWalletLabel(wltID, label string) error {
 	serv.Lock()
@@ -336,6 +467,482 @@ func (serv *Service) UpdateWalletLabel(wltID, label string) error {
 	return nil
 }
 
+// UpdateAddressMeta sets the label, purpose and tags of an address in a wallet.
+// Nil fields of the update are left unchanged.
+// The address metadata is not encrypted, so the wallet password is not required.
+func (serv *Service) UpdateAddressMeta(wltID string, addr cipher.Addresser, u EntryMetaUpdate) (*Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.UpdateEntryMeta(addr, u); err != nil {
+		return nil, err
+	}
+
+	if err := w.Save(serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return w, nil
+}
+
+// SetFreshChange sets whether transactions created by the wallet send their change to a fresh change address
+func (serv *Service) SetFreshChange(wltID string, freshChange bool) (*Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.SetFreshChange(freshChange); err != nil {
+		return nil, err
+	}
+
+	if err := w.Save(serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return w, nil
+}
+
+// SetSignerSocket sets the unix socket path of an external signer which signs the transactions of the wallet.
+// The wallet signs with its own secret keys if socketPath is empty.
+func (serv *Service) SetSignerSocket(wltID, socketPath string) (*Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.SetSignerSocket(socketPath); err != nil {
+		return nil, err
+	}
+
+	if err := w.Save(serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return w, nil
+}
+
+// FreshChangeAddress returns a change address with no transaction history for a new transaction,
+// if the wallet has the fresh change option. Returns nil if the option is not set.
+// The address is recorded as issued in the saved wallet, see Wallet.FreshChangeAddress.
+// The password is required to generate an address for an encrypted wallet.
+func (serv *Service) FreshChangeAddress(wltID string, password []byte, tf TransactionsFinder) (*cipher.Address, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !w.FreshChange() {
+		return nil, nil
+	}
+
+	addr, err := w.FreshChangeAddress(password, tf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.Save(serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return &addr, nil
+}
+
+func (serv *Service) spendingPolicyFile(wltID string) string {
+	return filepath.Join(serv.config.WalletDir, wltID+SpendingPolicyExt)
+}
+
+// SpendingPolicy returns the spending policy of a wallet. Returns nil if the wallet has no spending policy.
+func (serv *Service) SpendingPolicy(wltID string) (*SpendingPolicy, error) {
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if _, err := serv.getWallet(wltID); err != nil {
+		return nil, err
+	}
+
+	return loadSpendingPolicy(serv.spendingPolicyFile(wltID))
+}
+
+// SetSpendingPolicy sets the limits of the spending policy of a wallet, creating the policy if it doesn't exist.
+// A policy with limits must have an approval password, and the approval password must be provided to change
+// a policy that has one. The approval password is replaced if newApprovalPassword is not empty.
+// The spends and approvals recorded by the policy are kept.
+func (serv *Service) SetSpendingPolicy(wltID string, limits SpendingLimits, approvalPassword, newApprovalPassword []byte) (*SpendingPolicy, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if _, err := serv.getWallet(wltID); err != nil {
+		return nil, err
+	}
+
+	filename := serv.spendingPolicyFile(wltID)
+	p, err := loadSpendingPolicy(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if p == nil {
+		p = &SpendingPolicy{}
+	} else if p.HasApprovalPassword() || p.HasLimits() {
+		if err := p.VerifyApprovalPassword(approvalPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	p.SpendingLimits = limits
+
+	if len(newApprovalPassword) != 0 {
+		if err := p.setApprovalPassword(newApprovalPassword, serv.config.CryptoType); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := p.save(filename); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// ApproveTransaction records the second approval of a transaction above the approval threshold
+// of the spending policy of a wallet. The approval is valid for 24 hours.
+func (serv *Service) ApproveTransaction(wltID string, txnInnerHash cipher.SHA256, approvalPassword []byte) error {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	if _, err := serv.getWallet(wltID); err != nil {
+		return err
+	}
+
+	filename := serv.spendingPolicyFile(wltID)
+	p, err := loadSpendingPolicy(filename)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrSpendingPolicyNotExist
+	}
+
+	if err := p.VerifyApprovalPassword(approvalPassword); err != nil {
+		return err
+	}
+
+	p.approve(txnInnerHash, time.Now())
+	return p.save(filename)
+}
+
+// CheckSpendingPolicy checks a transaction spending the outputs of a wallet against the wallet's spending policy.
+// If the transaction requires a second approval and approvalPassword is provided, the approval is recorded.
+// Returns nil if the wallet has no spending policy.
+func (serv *Service) CheckSpendingPolicy(wltID string, txn *coin.Transaction, approvalPassword []byte) error {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	_, _, err := serv.checkSpendingPolicy(wltID, txn, approvalPassword, time.Now())
+	return err
+}
+
+// RecordSpendingPolicy checks a transaction against the spending policy of a wallet before the wallet signs it,
+// and records it as a pending spend of the policy, like RecordSpendingPolicies does when a transaction is injected.
+// The spend counts towards the limits of the policy even if the signed transaction is injected through another node.
+// Once saved with PendingSpends.Save, it ages out with the policy window like the spends of injected transactions,
+// and the transaction is not recorded a second time when it is injected.
+// If the transaction requires a second approval and approvalPassword is provided, the approval is recorded.
+// Returns nil if the wallet has no spending policy or if the transaction was already recorded.
+func (serv *Service) RecordSpendingPolicy(wltID string, txn *coin.Transaction, approvalPassword []byte) (*PendingSpends, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	now := time.Now()
+	w, p, err := serv.checkSpendingPolicy(wltID, txn, approvalPassword, now)
+	if err != nil || p == nil {
+		return nil, err
+	}
+
+	filename := serv.spendingPolicyFile(wltID)
+	spend, err := p.withSpends(serv.pendingSpends[filename]).checkSpend(w, txn, now)
+	if err != nil || spend == nil {
+		return nil, err
+	}
+
+	// Pending spends which could not be saved age out with the policy window
+	var pending []policyEvent
+	for _, s := range serv.pendingSpends[filename] {
+		if isInPolicyWindow(s, now) {
+			pending = append(pending, s)
+		}
+	}
+	serv.pendingSpends[filename] = append(pending, *spend)
+
+	return &PendingSpends{
+		serv: serv,
+		spends: []pendingSpend{{
+			filename: filename,
+			spend:    *spend,
+		}},
+	}, nil
+}
+
+// checkSpendingPolicy checks a transaction against the spending policy of a wallet, recording its approval
+// if it requires one and approvalPassword is provided. Returns the wallet and its spending policy,
+// which is nil if the wallet has no spending policy. The service lock must be held.
+func (serv *Service) checkSpendingPolicy(wltID string, txn *coin.Transaction, approvalPassword []byte, now time.Time) (*Wallet, *SpendingPolicy, error) {
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filename := serv.spendingPolicyFile(wltID)
+	p, err := loadSpendingPolicy(filename)
+	if err != nil || p == nil {
+		return nil, nil, err
+	}
+
+	err = p.withSpends(serv.pendingSpends[filename]).Check(w, txn, now)
+	if err == nil {
+		return w, p, nil
+	}
+	if err != ErrSpendingPolicyApprovalRequired || len(approvalPassword) == 0 {
+		return nil, nil, err
+	}
+
+	if err := p.VerifyApprovalPassword(approvalPassword); err != nil {
+		return nil, nil, err
+	}
+
+	p.approve(txn.InnerHash, now)
+	if err := p.save(filename); err != nil {
+		return nil, nil, err
+	}
+
+	return w, p, nil
+}
+
+// RecordSpendingPolicies checks a transaction against the spending policies of the loaded wallets that own its inputs,
+// and records it as a pending spend of those policies. Nothing is recorded if any of the policies rejects the transaction.
+// The pending spends count towards the limits of the policies until they are saved with PendingSpends.Save
+// or discarded with PendingSpends.Discard.
+// Returns nil if the wallet API is disabled or if no policy records the transaction.
+func (serv *Service) RecordSpendingPolicies(txn *coin.Transaction, inputs coin.UxArray) (*PendingSpends, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, nil
+	}
+
+	// Visit the wallets in a stable order, so that the same policy error is returned for the same transaction
+	wltIDs := make([]string, 0, len(serv.wallets))
+	for wltID := range serv.wallets {
+		wltIDs = append(wltIDs, wltID)
+	}
+	sort.Strings(wltIDs)
+
+	now := time.Now()
+	var spends []pendingSpend
+	for _, wltID := range wltIDs {
+		w := serv.wallets[wltID]
+
+		owned := false
+		for _, in := range inputs {
+			if w.HasEntry(in.Body.Address) {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			continue
+		}
+
+		filename := serv.spendingPolicyFile(wltID)
+		p, err := loadSpendingPolicy(filename)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			continue
+		}
+
+		spend, err := p.withSpends(serv.pendingSpends[filename]).checkSpend(w, txn, now)
+		if err != nil {
+			return nil, err
+		}
+		if spend != nil {
+			spends = append(spends, pendingSpend{
+				filename: filename,
+				spend:    *spend,
+			})
+		}
+	}
+
+	if len(spends) == 0 {
+		return nil, nil
+	}
+
+	for _, s := range spends {
+		serv.pendingSpends[s.filename] = append(serv.pendingSpends[s.filename], s.spend)
+	}
+
+	return &PendingSpends{
+		serv:   serv,
+		spends: spends,
+	}, nil
+}
+
+// PendingSpends are the spends of a transaction recorded by RecordSpendingPolicies.
+// They are saved to the spending policy files once the transaction is committed, or discarded if it is rolled back.
+type PendingSpends struct {
+	serv   *Service
+	spends []pendingSpend
+}
+
+type pendingSpend struct {
+	filename string
+	spend    policyEvent
+}
+
+// Save saves the pending spends to the spending policy files.
+// The spends that fail to save stay pending, so that they keep counting towards the limits of their policies.
+func (ps *PendingSpends) Save() error {
+	serv := ps.serv
+	serv.Lock()
+	defer serv.Unlock()
+
+	now := time.Now()
+	var saveErr error
+	for _, s := range ps.spends {
+		// The policy is reloaded, in case it was changed since the spend was recorded
+		p, err := loadSpendingPolicy(s.filename)
+		if err == nil && p != nil {
+			p.addSpend(s.spend, now)
+			err = p.save(s.filename)
+		}
+		if err != nil {
+			if saveErr == nil {
+				saveErr = err
+			}
+			continue
+		}
+
+		serv.removePendingSpend(s.filename, s.spend)
+	}
+
+	return saveErr
+}
+
+// Discard discards the pending spends without saving them
+func (ps *PendingSpends) Discard() {
+	serv := ps.serv
+	serv.Lock()
+	defer serv.Unlock()
+
+	for _, s := range ps.spends {
+		serv.removePendingSpend(s.filename, s.spend)
+	}
+}
+
+func (serv *Service) removePendingSpend(filename string, spend policyEvent) {
+	spends := serv.pendingSpends[filename]
+	for i, s := range spends {
+		if s == spend {
+			spends = append(spends[:i:i], spends[i+1:]...)
+			break
+		}
+	}
+
+	if len(spends) == 0 {
+		delete(serv.pendingSpends, filename)
+	} else {
+		serv.pendingSpends[filename] = spends
+	}
+}
+
+// FreezeUxOuts freezes outputs in the wallet, so that they are not spent unless explicitly selected.
+// The outputs are not checked against the blockchain.
+func (serv *Service) FreezeUxOuts(wltID string, uxOuts []cipher.SHA256) (*Wallet, error) {
+	return serv.updateFrozenUxOuts(wltID, func(w *Wallet) {
+		w.FreezeUxOuts(uxOuts)
+	})
+}
+
+// UnfreezeUxOuts unfreezes outputs in the wallet
+func (serv *Service) UnfreezeUxOuts(wltID string, uxOuts []cipher.SHA256) (*Wallet, error) {
+	return serv.updateFrozenUxOuts(wltID, func(w *Wallet) {
+		w.UnfreezeUxOuts(uxOuts)
+	})
+}
+
+func (serv *Service) updateFrozenUxOuts(wltID string, f func(w *Wallet)) (*Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	f(w)
+
+	if err := w.Save(serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return w, nil
+}
+
 // UnloadWallet removes wallet of given wallet id from the service
 func (serv *Service) UnloadWallet(wltID string) error {
 	serv.Lock()
@@ -344,20 +951,30 @@ func (serv *Service) UnloadWallet(wltID string) error {
 		return ErrWalletAPIDisabled
 	}
 
+	serv.removeWallet(wltID)
+	return nil
+}
+
+// removeWallet removes a loaded wallet
+func (serv *Service) removeWallet(wltID string) {
 	wlt := serv.wallets.get(wltID)
-	if wlt != nil && len(wlt.Entries) > 0 {
+	if wlt != nil && wlt.Type() != WalletTypeCollection && len(wlt.Entries) > 0 {
 		addr := wlt.Entries[0].Address.String()
-		delete(serv.firstAddrIDMap, addr)
+		if serv.firstAddrIDMap[addr] == wltID {
+			delete(serv.firstAddrIDMap, addr)
+		}
 	}
 
 	serv.wallets.remove(wltID)
-	return nil
 }
 
 func (serv *Service) setWallets(wlts Wallets) {
 	serv.wallets = wlts
 
 	for wltID, wlt := range wlts {
+		if wlt.Type() == WalletTypeCollection {
+			continue
+		}
 		addr := wlt.Entries[0].Address.String()
 		serv.firstAddrIDMap[addr] = wltID
 	}
@@ -396,6 +1013,130 @@ func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, error
 	return seed, nil
 }
 
+// GetWalletSeedShares splits the seed of an encrypted wallet into n share mnemonics,
+// any threshold of which recover the seed with CombineSeedShares
+func (serv *Service) GetWalletSeedShares(wltID string, password []byte, n, threshold int) ([]string, error) {
+	seed, err := serv.GetWalletSeed(wltID, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitSeed(seed, n, threshold)
+}
+
+// ImportKeys imports secret keys into a collection wallet.
+// Set password as nil if the wallet is not encrypted, otherwise the password must be provided.
+func (serv *Service) ImportKeys(wltID string, password []byte, keys []cipher.SecKey) (*Wallet, error) {
+	return serv.updateCollection(wltID, password, func(w *Wallet) error {
+		_, err := w.ImportSecKeys(keys)
+		return err
+	})
+}
+
+// RemoveKeys removes the keys of the given addresses from a collection wallet.
+// Set password as nil if the wallet is not encrypted, otherwise the password must be provided.
+func (serv *Service) RemoveKeys(wltID string, password []byte, addrs []cipher.Address) (*Wallet, error) {
+	addressers := make([]cipher.Addresser, len(addrs))
+	for i, a := range addrs {
+		addressers[i] = a
+	}
+
+	return serv.updateCollection(wltID, password, func(w *Wallet) error {
+		return w.RemoveEntries(addressers)
+	})
+}
+
+// SignPartialTransaction signs the inputs of a partially signed transaction that are owned by a wallet.
+// Set password as nil if the wallet is not encrypted, otherwise the password must be provided.
+// The transaction is recorded as a spend of the wallet's spending policy, see RecordSpendingPolicy.
+func (serv *Service) SignPartialTransaction(wltID string, password []byte, pt *transaction.PartialTransaction) (*transaction.PartialTransaction, error) {
+	spends, err := serv.RecordSpendingPolicy(wltID, &pt.Transaction, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var signed *transaction.PartialTransaction
+	if err := serv.ViewSecrets(wltID, password, func(w *Wallet) error {
+		var err error
+		signed, err = w.SignPartialTransaction(pt)
+		return err
+	}); err != nil {
+		if spends != nil {
+			spends.Discard()
+		}
+		return nil, err
+	}
+
+	if spends != nil {
+		if err := spends.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return signed, nil
+}
+
+// SetPartialTransactionSigners sets the signer hints of the inputs of a partially signed transaction
+// whose addresses belong to a loaded wallet
+func (serv *Service) SetPartialTransactionSigners(pt *transaction.PartialTransaction) error {
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	// Visit the wallets in a stable order, so that the first wallet owning an address is its signer
+	wltIDs := make([]string, 0, len(serv.wallets))
+	for wltID := range serv.wallets {
+		wltIDs = append(wltIDs, wltID)
+	}
+	sort.Strings(wltIDs)
+
+	for _, wltID := range wltIDs {
+		serv.wallets[wltID].SetPartialTransactionSigners(pt)
+	}
+
+	return nil
+}
+
+// updateCollection modifies the entries of a collection wallet and saves it
+func (serv *Service) updateCollection(wltID string, password []byte, f func(*Wallet) error) (*Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Type() != WalletTypeCollection {
+		return nil, ErrWalletNotCollection
+	}
+
+	if w.IsEncrypted() {
+		if err := w.GuardUpdate(password, f); err != nil {
+			return nil, err
+		}
+	} else if len(password) != 0 {
+		return nil, ErrWalletNotEncrypted
+	} else {
+		if err := f(w); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Save(serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+
+	return w.clone(), nil
+}
+
 // UpdateSecrets opens a wallet for modification of secret data and saves it safely
 func (serv *Service) UpdateSecrets(wltID string, password []byte, f func(*Wallet) error) error {
 	serv.Lock()
@@ -497,30 +1238,119 @@ func (serv *Service) View(wltID string, f func(*Wallet) error) error {
 }
 
 // RecoverWallet recovers an encrypted wallet from seed.
+// The seed passphrase is only used by bip44 wallets.
 // The recovered wallet will be encrypted with the new password, if provided.
-func (serv *Service) RecoverWallet(wltName, seed string, password []byte) (*Wallet, error) {
+func (serv *Service) RecoverWallet(wltName, seed, seedPassphrase string, password []byte) (*Wallet, error) {
+	w, _, err := serv.RecoverWalletScan(wltName, seed, seedPassphrase, password, 0, nil)
+	return w, err
+}
+
+// RecoverWalletFromShares recovers a wallet like RecoverWalletScan, from the seed recovered by combining
+// the seed share mnemonics created by SplitSeed.
+func (serv *Service) RecoverWalletFromShares(wltName string, shares []string, seedPassphrase string, password []byte, gapLimit uint64, tf TransactionsFinder) (*Wallet, []ScanProgress, error) {
+	seed, err := CombineSeedShares(shares)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return serv.RecoverWalletScan(wltName, seed, seedPassphrase, password, gapLimit, tf)
+}
+
+// RecoverWalletScan recovers a wallet from seed like RecoverWallet, then scans the transaction
+// history of the following addresses until gapLimit consecutive addresses have no history,
+// adding the addresses up to the last one with history. No scan is done if gapLimit is 0.
+// Unencrypted wallets can be recovered only with a scan, in which case the password must be empty.
+// The labels and other metadata of the existing addresses are kept, as are the wallet's
+// frozen outputs, fresh change option, external signer and argon2id parameters.
+// The recovery and the scan are done on a copy of the wallet without holding the service lock,
+// and ErrWalletChanged is returned if the wallet was changed in the meantime.
+// Returns the final progress of the scan of each address chain.
+func (serv *Service) RecoverWalletScan(wltName, seed, seedPassphrase string, password []byte, gapLimit uint64, tf TransactionsFinder) (*Wallet, []ScanProgress, error) {
+	w, err := serv.GetWallet(wltName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !w.IsEncrypted() && (gapLimit == 0 || len(password) != 0) {
+		return nil, nil, ErrWalletNotEncrypted
+	}
+
+	if gapLimit > 0 && tf == nil {
+		return nil, nil, ErrNilTransactionsFinder
+	}
+
+	var w2 *Wallet
+	switch w.Type() {
+	case WalletTypeDeterministic:
+		w2, err = recoverDeterministicWallet(w, seed)
+	case WalletTypeBip44:
+		w2, err = recoverBip44Wallet(w, seed, seedPassphrase)
+	default:
+		return nil, nil, ErrWalletNotDeterministic
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var scans []ScanProgress
+	if gapLimit > 0 {
+		n, err := w2.ScanAddressHistory(gapLimit, tf, func(p ScanProgress) {
+			logger.WithField("wallet", wltName).Infof("Address history scan: chain=%d scanned=%d found=%d", p.Chain, p.Scanned, p.Found)
+
+			// Keep the last progress of each chain
+			if len(scans) == 0 || scans[len(scans)-1].Chain != p.Chain {
+				scans = append(scans, p)
+			} else {
+				scans[len(scans)-1] = p
+			}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		logger.WithField("wallet", wltName).Infof("Address history scan added %d addresses", n)
+	}
+
+	copyEntryMeta(w2, w)
+	copyWalletSettings(w2, w)
+
+	if len(password) != 0 {
+		if err := w2.Lock(password, w.cryptoType()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Preserve the timestamp of the old wallet
+	w2.setTimestamp(w.timestamp())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
-		return nil, ErrWalletAPIDisabled
+		return nil, nil, ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltName)
-	if err != nil {
-		return nil, err
+	// Make sure the wallet was not changed, unloaded or replaced while it was being recovered
+	w3 := serv.wallets.get(wltName)
+	if w3 == nil {
+		return nil, nil, ErrWalletNotExist
 	}
-
-	if !w.IsEncrypted() {
-		return nil, ErrWalletNotEncrypted
+	if !reflect.DeepEqual(w, w3) {
+		return nil, nil, ErrWalletChanged
 	}
 
-	if w.Type() != WalletTypeDeterministic {
-		return nil, ErrWalletNotDeterministic
+	// Save to disk
+	if err := w2.Save(serv.config.WalletDir); err != nil {
+		return nil, nil, err
 	}
 
+	serv.wallets.set(w2)
+
+	return w2.clone(), scans, nil
+}
+
+func recoverDeterministicWallet(w *Wallet, seed string) (*Wallet, error) {
 	// Generate the first address from the seed
-	var pk cipher.PubKey
-	pk, _, err = cipher.GenerateDeterministicKeyPair([]byte(seed))
+	pk, _, err := cipher.GenerateDeterministicKeyPair([]byte(seed))
 	if err != nil {
 		return nil, err
 	}
@@ -531,29 +1361,106 @@ func (serv *Service) RecoverWallet(wltName, seed string, password []byte) (*Wall
 		return nil, ErrWalletRecoverSeedWrong
 	}
 
-	// Create a new wallet with the same number of addresses, encrypting if needed
-	w2, err := NewWallet(wltName, Options{
-		Coin:       w.coin(),
-		Label:      w.Label(),
-		Seed:       seed,
-		Encrypt:    len(password) != 0,
-		Password:   password,
-		CryptoType: w.cryptoType(),
-		GenerateN:  uint64(len(w.Entries)),
+	// Create a new wallet with the same number of addresses
+	return NewWallet(w.Filename(), Options{
+		Coin:      w.coin(),
+		Label:     w.Label(),
+		Seed:      seed,
+		GenerateN: uint64(len(w.Entries)),
 	})
+}
+
+func recoverBip44Wallet(w *Wallet, seed, seedPassphrase string) (*Wallet, error) {
+	bip44Coin, err := w.Bip44Coin()
 	if err != nil {
 		return nil, err
 	}
 
-	// Preserve the timestamp of the old wallet
-	w2.setTimestamp(w.timestamp())
+	account, err := w.Bip44Account()
+	if err != nil {
+		return nil, err
+	}
 
-	// Save to disk
-	if err := w2.Save(serv.config.WalletDir); err != nil {
+	w2, err := NewWallet(w.Filename(), Options{
+		Coin:           w.coin(),
+		Type:           WalletTypeBip44,
+		Label:          w.Label(),
+		Seed:           seed,
+		SeedPassphrase: seedPassphrase,
+		Bip44Coin:      &bip44Coin,
+		Bip44Account:   account,
+	})
+	if err != nil {
+		if err == ErrInvalidBip44Seed {
+			return nil, ErrWalletRecoverSeedWrong
+		}
 		return nil, err
 	}
 
-	serv.wallets.set(w2)
+	// Compare to the wallet's first address
+	if w2.Entries[0].Address != w.Entries[0].Address {
+		return nil, ErrWalletRecoverSeedWrong
+	}
+
+	// Regenerate the same number of external and change addresses
+	var nExternal, nChange uint64
+	for _, e := range w.Entries {
+		switch e.Change {
+		case bip44.ExternalChainIndex:
+			nExternal++
+		case bip44.ChangeChainIndex:
+			nChange++
+		}
+	}
+
+	if _, err := w2.GenerateAddresses(nExternal - 1); err != nil {
+		return nil, err
+	}
+
+	if _, err := w2.GenerateChangeAddresses(nChange); err != nil {
+		return nil, err
+	}
+
+	return w2, nil
+}
 
-	return w2.clone(), nil
+// walletSettingsMeta are the meta fields of the wallet settings which are kept when recovering a wallet
+var walletSettingsMeta = []string{
+	metaFrozenUxOuts,
+	metaFreshChange,
+	metaIssuedChange,
+	metaSignerSocket,
+	metaArgon2idTime,
+	metaArgon2idMemory,
+	metaArgon2idParallelism,
+}
+
+// copyWalletSettings copies the wallet settings of src to dst
+func copyWalletSettings(dst, src *Wallet) {
+	for _, k := range walletSettingsMeta {
+		if v, ok := src.Meta[k]; ok {
+			dst.Meta[k] = v
+		}
+	}
+}
+
+// copyEntryMeta copies the label, creation time, purpose and tags of the entries of src
+// to the entries of dst with the same address
+func copyEntryMeta(dst, src *Wallet) {
+	entries := make(map[string]Entry, len(src.Entries))
+	for _, e := range src.Entries {
+		entries[e.Address.String()] = e
+	}
+
+	for i := range dst.Entries {
+		e, ok := entries[dst.Entries[i].Address.String()]
+		if !ok {
+			continue
+		}
+
+		dst.Entries[i].Label = e.Label
+		dst.Entries[i].Created = e.Created
+		dst.Entries[i].Purpose = e.Purpose
+		dst.Entries[i].Tags = append([]string(nil), e.Tags...)
+	}
 }
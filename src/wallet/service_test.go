@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -9,10 +10,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/testutil"
 )
 
@@ -60,7 +64,7 @@ func TestNewService(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			require.Equal(t, 6, len(s.wallets))
+			require.Equal(t, 7, len(s.wallets))
 
 		})
 	}
@@ -604,6 +608,58 @@ func TestServiceGetWallets(t *testing.T) {
 	}
 }
 
+func TestServiceAddressWallets(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	a, err := s.CreateWallet("a.wlt", Options{
+		Seed:      "seed-a",
+		GenerateN: 2,
+	}, nil)
+	require.NoError(t, err)
+
+	b, err := s.CreateWallet("b.wlt", Options{
+		Seed: "seed-b",
+	}, nil)
+	require.NoError(t, err)
+
+	// A collection wallet sharing an address with a.wlt
+	_, err = s.CreateWallet("c.wlt", Options{
+		Type: WalletTypeCollection,
+	}, nil)
+	require.NoError(t, err)
+	_, err = s.ImportKeys("c.wlt", nil, []cipher.SecKey{a.Entries[1].Secret})
+	require.NoError(t, err)
+
+	other := testutil.MakeAddress()
+	m := s.AddressWallets([]cipher.Address{
+		a.Entries[0].SkycoinAddress(),
+		a.Entries[1].SkycoinAddress(),
+		b.Entries[0].SkycoinAddress(),
+		b.Entries[0].SkycoinAddress(),
+		other,
+	})
+
+	require.Equal(t, map[cipher.Address][]string{
+		a.Entries[0].SkycoinAddress(): {"a.wlt"},
+		a.Entries[1].SkycoinAddress(): {"a.wlt", "c.wlt"},
+		b.Entries[0].SkycoinAddress(): {"b.wlt"},
+	}, m)
+
+	// No wallets are returned when the wallet API is disabled
+	s, err = NewService(Config{
+		WalletDir:  dir,
+		CryptoType: CryptoTypeSha256Xor,
+	})
+	require.NoError(t, err)
+	require.Empty(t, s.AddressWallets([]cipher.Address{a.Entries[0].SkycoinAddress()}))
+}
+
 func TestServiceUpdateWalletLabel(t *testing.T) {
 	tt := []struct {
 		name             string
@@ -686,6 +742,360 @@ func TestServiceUpdateWalletLabel(t *testing.T) {
 	}
 }
 
+func TestServiceUpdateAddressMeta(t *testing.T) {
+	label := "savings"
+	purpose := EntryPurposeChange
+	tags := []string{"exchange", "cold"}
+	badPurpose := "spend"
+
+	tt := []struct {
+		name             string
+		encrypt          bool
+		updateWltName    string
+		addr             cipher.Address
+		update           EntryMetaUpdate
+		disableWalletAPI bool
+		err              error
+	}{
+		{
+			name:          "ok",
+			updateWltName: "t.wlt",
+			update: EntryMetaUpdate{
+				Label:   &label,
+				Purpose: &purpose,
+				Tags:    &tags,
+			},
+		},
+		{
+			name:          "ok, encrypted",
+			encrypt:       true,
+			updateWltName: "t.wlt",
+			update: EntryMetaUpdate{
+				Label: &label,
+			},
+		},
+		{
+			name:          "invalid purpose",
+			updateWltName: "t.wlt",
+			update: EntryMetaUpdate{
+				Purpose: &badPurpose,
+			},
+			err: ErrInvalidEntryPurpose,
+		},
+		{
+			name:          "unknown address",
+			updateWltName: "t.wlt",
+			addr:          testutil.MakeAddress(),
+			update: EntryMetaUpdate{
+				Label: &label,
+			},
+			err: ErrUnknownAddress,
+		},
+		{
+			name:          "wallet doesn't exist",
+			updateWltName: "t1.wlt",
+			update: EntryMetaUpdate{
+				Label: &label,
+			},
+			err: ErrWalletNotExist,
+		},
+		{
+			name:             "wallet api disabled",
+			disableWalletAPI: true,
+			err:              ErrWalletAPIDisabled,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := prepareWltDir()
+			s, err := NewService(Config{
+				WalletDir:       dir,
+				CryptoType:      CryptoTypeScryptChacha20poly1305,
+				EnableWalletAPI: !tc.disableWalletAPI,
+			})
+			require.NoError(t, err)
+
+			if tc.disableWalletAPI {
+				_, err = s.UpdateAddressMeta("", cipher.Address{}, tc.update)
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			opts := Options{
+				Seed:  "seed",
+				Label: "label",
+			}
+			if tc.encrypt {
+				opts.Encrypt = true
+				opts.Password = []byte("pwd")
+			}
+
+			w, err := s.CreateWallet("t.wlt", opts, nil)
+			require.NoError(t, err)
+
+			addr := tc.addr
+			if addr.Null() {
+				addr = w.Entries[0].Address.(cipher.Address)
+			}
+
+			nw, err := s.UpdateAddressMeta(tc.updateWltName, addr, tc.update)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				// The wallet file is left unchanged
+				lw, err := Load(filepath.Join(dir, "t.wlt"))
+				require.NoError(t, err)
+				require.Equal(t, w.Entries[0].Label, lw.Entries[0].Label)
+				require.Equal(t, w.Entries[0].Purpose, lw.Entries[0].Purpose)
+				return
+			}
+
+			require.Equal(t, label, nw.Entries[0].Label)
+			require.Equal(t, tc.encrypt, nw.IsEncrypted())
+
+			// The metadata is persisted to the wallet file
+			lw, err := Load(filepath.Join(dir, "t.wlt"))
+			require.NoError(t, err)
+			require.Equal(t, nw.Entries[0].Label, lw.Entries[0].Label)
+			require.Equal(t, nw.Entries[0].Purpose, lw.Entries[0].Purpose)
+			require.Equal(t, nw.Entries[0].Tags, lw.Entries[0].Tags)
+			require.Equal(t, w.Entries[0].Created, lw.Entries[0].Created)
+			if tc.update.Purpose != nil {
+				require.Equal(t, *tc.update.Purpose, lw.Entries[0].Purpose)
+			}
+			if tc.update.Tags != nil {
+				require.Equal(t, *tc.update.Tags, lw.Entries[0].Tags)
+			}
+		})
+	}
+}
+
+func TestServiceFreezeUxOuts(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeScryptChacha20poly1305,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	// Outputs can be frozen without the password of an encrypted wallet
+	_, err = s.CreateWallet("t.wlt", Options{
+		Seed:     "seed",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+
+	h := testutil.RandSHA256(t)
+	w, err := s.FreezeUxOuts("t.wlt", []cipher.SHA256{h})
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{h}, w.FrozenUxOuts())
+	require.True(t, w.IsEncrypted())
+
+	w, err = s.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{h}, w.FrozenUxOuts())
+
+	lw, err := Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{h}, lw.FrozenUxOuts())
+
+	w, err = s.UnfreezeUxOuts("t.wlt", []cipher.SHA256{h})
+	require.NoError(t, err)
+	require.Empty(t, w.FrozenUxOuts())
+
+	lw, err = Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.Empty(t, lw.FrozenUxOuts())
+
+	_, err = s.FreezeUxOuts("t1.wlt", []cipher.SHA256{h})
+	require.Equal(t, ErrWalletNotExist, err)
+
+	s, err = NewService(Config{
+		WalletDir:       prepareWltDir(),
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+
+	_, err = s.FreezeUxOuts("t.wlt", []cipher.SHA256{h})
+	require.Equal(t, ErrWalletAPIDisabled, err)
+	_, err = s.UnfreezeUxOuts("t.wlt", []cipher.SHA256{h})
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
+
+func TestServiceFreshChangeAddress(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", Options{
+		Seed: "seed",
+	}, nil)
+	require.NoError(t, err)
+
+	tf := mockTransactionsFinder{}
+
+	// No fresh change address without the option
+	addr, err := s.FreshChangeAddress("t.wlt", nil, tf)
+	require.NoError(t, err)
+	require.Nil(t, addr)
+
+	w, err := s.SetFreshChange("t.wlt", true)
+	require.NoError(t, err)
+	require.True(t, w.FreshChange())
+	require.Len(t, w.Entries, 1)
+
+	addr, err = s.FreshChangeAddress("t.wlt", nil, tf)
+	require.NoError(t, err)
+	require.NotNil(t, addr)
+
+	w, err = s.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 2)
+	require.Equal(t, *addr, w.Entries[1].SkycoinAddress())
+	require.Equal(t, EntryPurposeChange, w.Entries[1].Purpose)
+
+	lw, err := Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.True(t, lw.FreshChange())
+	require.Len(t, lw.Entries, 2)
+	require.Equal(t, []cipher.Address{*addr}, lw.IssuedChangeAddresses())
+
+	// The issued change address is not given to another transaction, even if it is unused
+	addr2, err := s.FreshChangeAddress("t.wlt", nil, tf)
+	require.NoError(t, err)
+	require.NotEqual(t, addr, addr2)
+
+	w, err = s.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 3)
+	require.Equal(t, []cipher.Address{*addr, *addr2}, w.IssuedChangeAddresses())
+
+	// An unused change address which was not issued is reused
+	var addr3 cipher.Address
+	err = s.Update("t.wlt", func(w *Wallet) error {
+		var err error
+		addr3, err = w.NewChangeAddress()
+		return err
+	})
+	require.NoError(t, err)
+
+	addr4, err := s.FreshChangeAddress("t.wlt", nil, tf)
+	require.NoError(t, err)
+	require.Equal(t, addr3, *addr4)
+
+	w, err = s.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 4)
+	require.Equal(t, []cipher.Address{*addr, *addr2, addr3}, w.IssuedChangeAddresses())
+
+	// A new change address is generated if the most recent one is used
+	err = s.Update("t.wlt", func(w *Wallet) error {
+		var err error
+		addr3, err = w.NewChangeAddress()
+		return err
+	})
+	require.NoError(t, err)
+
+	tf[addr3] = true
+	addr4, err = s.FreshChangeAddress("t.wlt", nil, tf)
+	require.NoError(t, err)
+	require.NotEqual(t, addr3, *addr4)
+
+	w, err = s.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 6)
+
+	// Encrypted wallets require the password to generate a change address
+	_, err = s.CreateWallet("e.wlt", Options{
+		Seed:        "seed2",
+		Encrypt:     true,
+		Password:    []byte("pwd"),
+		FreshChange: true,
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = s.FreshChangeAddress("e.wlt", nil, tf)
+	require.Equal(t, ErrFreshChangeEncrypted, err)
+
+	addr, err = s.FreshChangeAddress("e.wlt", []byte("pwd"), tf)
+	require.NoError(t, err)
+	require.NotNil(t, addr)
+
+	w, err = s.GetWallet("e.wlt")
+	require.NoError(t, err)
+	require.True(t, w.IsEncrypted())
+	require.Len(t, w.Entries, 2)
+	require.Equal(t, *addr, w.Entries[1].SkycoinAddress())
+
+	// The issued change address is not reused, so the password is needed again
+	_, err = s.FreshChangeAddress("e.wlt", nil, tf)
+	require.Equal(t, ErrFreshChangeEncrypted, err)
+
+	_, err = s.FreshChangeAddress("t1.wlt", nil, tf)
+	require.Equal(t, ErrWalletNotExist, err)
+	_, err = s.SetFreshChange("t1.wlt", true)
+	require.Equal(t, ErrWalletNotExist, err)
+
+	s, err = NewService(Config{
+		WalletDir:       prepareWltDir(),
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+
+	_, err = s.FreshChangeAddress("t.wlt", nil, tf)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+	_, err = s.SetFreshChange("t.wlt", true)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
+
+func TestServiceSetSignerSocket(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", Options{
+		Seed: "seed",
+	}, nil)
+	require.NoError(t, err)
+
+	w, err := s.SetSignerSocket("t.wlt", "/run/signer.sock")
+	require.NoError(t, err)
+	require.Equal(t, "/run/signer.sock", w.SignerSocket())
+
+	lw, err := Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.Equal(t, "/run/signer.sock", lw.SignerSocket())
+
+	_, err = s.SetSignerSocket("t.wlt", "signer.sock")
+	require.Equal(t, ErrInvalidSignerSocket, err)
+
+	w, err = s.SetSignerSocket("t.wlt", "")
+	require.NoError(t, err)
+	require.Empty(t, w.SignerSocket())
+
+	_, err = s.SetSignerSocket("t1.wlt", "/run/signer.sock")
+	require.Equal(t, ErrWalletNotExist, err)
+
+	s, err = NewService(Config{
+		WalletDir:       prepareWltDir(),
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+
+	_, err = s.SetSignerSocket("t.wlt", "/run/signer.sock")
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
+
 func TestServiceEncryptWallet(t *testing.T) {
 	tt := []struct {
 		name             string
@@ -693,6 +1103,8 @@ func TestServiceEncryptWallet(t *testing.T) {
 		opts             Options
 		encWltName       string
 		pwd              []byte
+		cryptoType       CryptoType
+		argon2idParams   *Argon2idParams
 		disableWalletAPI bool
 		err              error
 	}{
@@ -705,6 +1117,47 @@ func TestServiceEncryptWallet(t *testing.T) {
 			encWltName: "t.wlt",
 			pwd:        []byte("pwd"),
 		},
+		{
+			name:    "ok, crypto type",
+			wltName: "t.wlt",
+			opts: Options{
+				Seed: "seed",
+			},
+			encWltName: "t.wlt",
+			pwd:        []byte("pwd"),
+			cryptoType: CryptoTypeArgon2idChacha20poly1305,
+		},
+		{
+			name:    "ok, argon2id parameters",
+			wltName: "t.wlt",
+			opts: Options{
+				Seed: "seed",
+			},
+			encWltName: "t.wlt",
+			pwd:        []byte("pwd"),
+			cryptoType: CryptoTypeArgon2idChacha20poly1305,
+			argon2idParams: &Argon2idParams{
+				Time:        2,
+				Memory:      1024,
+				Parallelism: 2,
+			},
+		},
+		{
+			name:    "invalid argon2id parameters",
+			wltName: "t.wlt",
+			opts: Options{
+				Seed: "seed",
+			},
+			encWltName: "t.wlt",
+			pwd:        []byte("pwd"),
+			cryptoType: CryptoTypeArgon2idChacha20poly1305,
+			argon2idParams: &Argon2idParams{
+				Time:        0,
+				Memory:      1024,
+				Parallelism: 2,
+			},
+			err: NewError(errors.New("invalid argon2id parameters: argon2id time must be at least 1")),
+		},
 		{
 			name:    "wallet doesn't exist",
 			wltName: "t.wlt",
@@ -755,7 +1208,7 @@ func TestServiceEncryptWallet(t *testing.T) {
 				require.NoError(t, err)
 
 				if tc.disableWalletAPI {
-					_, err = s.EncryptWallet("", tc.pwd)
+					_, err = s.EncryptWallet("", tc.pwd, tc.cryptoType, tc.argon2idParams)
 					require.Equal(t, tc.err, err)
 					return
 				}
@@ -765,7 +1218,7 @@ func TestServiceEncryptWallet(t *testing.T) {
 				require.NoError(t, err)
 
 				// Encrypt the wallet
-				encWlt, err := s.EncryptWallet(tc.encWltName, tc.pwd)
+				encWlt, err := s.EncryptWallet(tc.encWltName, tc.pwd, tc.cryptoType, tc.argon2idParams)
 				require.Equal(t, tc.err, err)
 				if err != nil {
 					return
@@ -777,10 +1230,28 @@ func TestServiceEncryptWallet(t *testing.T) {
 
 				// Check the encrypted wallet
 				require.True(t, encWlt.IsEncrypted())
+				if tc.cryptoType != "" {
+					require.Equal(t, tc.cryptoType, encWlt.cryptoType())
+				} else {
+					require.Equal(t, ct, encWlt.cryptoType())
+				}
 				require.Equal(t, cipher.SecKey{}, encWlt.Entries[0].Secret)
 				require.Empty(t, encWlt.seed())
 				require.Empty(t, encWlt.lastSeed())
 
+				// Check the argon2id parameters are recorded in the wallet
+				argon2idParams, err := encWlt.argon2idParams()
+				require.NoError(t, err)
+				if encWlt.cryptoType() == CryptoTypeArgon2idChacha20poly1305 {
+					expectParams := DefaultArgon2idParams
+					if tc.argon2idParams != nil {
+						expectParams = *tc.argon2idParams
+					}
+					require.Equal(t, &expectParams, argon2idParams)
+				} else {
+					require.Nil(t, argon2idParams)
+				}
+
 				// Check the decrypted seeds
 				decWlt, err := encWlt.Unlock(tc.pwd)
 				require.NoError(t, err)
@@ -936,66 +1407,240 @@ func TestServiceDecryptWallet(t *testing.T) {
 	}
 }
 
-func TestServiceCreateWalletWithScan(t *testing.T) {
-	seed := "seed1"
-	addrs := make([]cipher.Address, 20)
-	childSeeds := make([]string, 20)
-	lastSeed := []byte(seed)
-	for i := range addrs {
-		s, pk, _, err := cipher.DeterministicKeyPairIterator(lastSeed)
-		require.NoError(t, err)
-		addrs[i] = cipher.AddressFromPubKey(pk)
-		childSeeds[i] = hex.EncodeToString(s)
-		lastSeed = s
-	}
-
-	bg := make(mockBalanceGetter, 20)
-	for _, a := range addrs {
-		bg[a] = BalancePair{}
-	}
-
-	type exp struct {
-		err              error
-		seed             string
-		lastSeed         string
-		entryNum         int
-		confirmedBalance uint64
-		predictedBalance uint64
-	}
-
+func TestServiceChangePassword(t *testing.T) {
 	tt := []struct {
 		name             string
 		opts             Options
-		balGetter        BalanceGetter
+		wltName          string
+		oldPassword      []byte
+		newPassword      []byte
+		cryptoType       CryptoType
+		expectCryptoType CryptoType
 		disableWalletAPI bool
-		expect           exp
+		err              error
 	}{
 		{
-			name: "no coins and scan 0, unencrypted",
+			name: "ok",
 			opts: Options{
-				Seed: "seed1",
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeScryptChacha20poly1305Insecure,
 			},
-			balGetter: bg,
-			expect: exp{
-				err:              nil,
-				seed:             seed,
-				lastSeed:         childSeeds[0],
-				entryNum:         1,
-				confirmedBalance: 0,
-				predictedBalance: 0,
+			wltName:          "test.wlt",
+			oldPassword:      []byte("pwd"),
+			newPassword:      []byte("new pwd"),
+			expectCryptoType: CryptoTypeScryptChacha20poly1305Insecure,
+		},
+		{
+			name: "migrate crypto type",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
 			},
+			wltName:          "test.wlt",
+			oldPassword:      []byte("pwd"),
+			newPassword:      []byte("new pwd"),
+			cryptoType:       CryptoTypeScryptChacha20poly1305Insecure,
+			expectCryptoType: CryptoTypeScryptChacha20poly1305Insecure,
 		},
 		{
-			name: "no coins and scan 0, encrypted",
+			name: "wallet not exist",
 			opts: Options{
-				Seed:     seed,
-				Encrypt:  true,
-				Password: []byte("pwd"),
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
 			},
-			balGetter: bg,
-			expect: exp{
-				err:              nil,
-				seed:             seed,
+			wltName:     "t.wlt",
+			oldPassword: []byte("pwd"),
+			newPassword: []byte("new pwd"),
+			err:         ErrWalletNotExist,
+		},
+		{
+			name: "wallet not encrypted",
+			opts: Options{
+				Seed: "seed",
+			},
+			wltName:     "test.wlt",
+			oldPassword: []byte("pwd"),
+			newPassword: []byte("new pwd"),
+			err:         ErrWalletNotEncrypted,
+		},
+		{
+			name: "invalid password",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			wltName:     "test.wlt",
+			oldPassword: []byte("wrong pwd"),
+			newPassword: []byte("new pwd"),
+			err:         ErrInvalidPassword,
+		},
+		{
+			name: "wallet api disabled",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			wltName:          "test.wlt",
+			oldPassword:      []byte("pwd"),
+			newPassword:      []byte("new pwd"),
+			disableWalletAPI: true,
+			err:              ErrWalletAPIDisabled,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// The service encrypts new wallets with its configured crypto type
+			cryptoType := tc.opts.CryptoType
+			if cryptoType == "" {
+				cryptoType = CryptoTypeSha256Xor
+			}
+
+			dir := prepareWltDir()
+			s, err := NewService(Config{
+				WalletDir:       dir,
+				CryptoType:      cryptoType,
+				EnableWalletAPI: !tc.disableWalletAPI,
+			})
+			require.NoError(t, err)
+
+			if tc.disableWalletAPI {
+				_, err = s.ChangePassword(tc.wltName, tc.oldPassword, tc.newPassword, tc.cryptoType)
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			_, err = s.CreateWallet("test.wlt", tc.opts, nil)
+			require.NoError(t, err)
+
+			fn := filepath.Join(dir, "test.wlt")
+			bakFn := fn + ".bak"
+
+			w, err := s.ChangePassword(tc.wltName, tc.oldPassword, tc.newPassword, tc.cryptoType)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				testutil.RequireFileNotExists(t, bakFn)
+				return
+			}
+
+			verifyWlt := func(w *Wallet) {
+				require.True(t, w.IsEncrypted())
+				require.Equal(t, tc.expectCryptoType, w.cryptoType())
+				require.Empty(t, w.seed())
+
+				_, err := w.Unlock(tc.oldPassword)
+				require.Equal(t, ErrInvalidPassword, err)
+
+				uw, err := w.Unlock(tc.newPassword)
+				require.NoError(t, err)
+				require.Equal(t, tc.opts.Seed, uw.seed())
+			}
+
+			verifyWlt(w)
+
+			// Checks the wallet in the service
+			w, err = s.getWallet(tc.wltName)
+			require.NoError(t, err)
+			verifyWlt(w)
+
+			// Checks the wallet file
+			w, err = Load(fn)
+			require.NoError(t, err)
+			verifyWlt(w)
+
+			// The backup file is the wallet encrypted with the old password
+			testutil.RequireFileExists(t, bakFn)
+			bw, err := Load(bakFn)
+			require.NoError(t, err)
+			require.Equal(t, tc.opts.CryptoType, bw.cryptoType())
+			uw, err := bw.Unlock(tc.oldPassword)
+			require.NoError(t, err)
+			require.Equal(t, tc.opts.Seed, uw.seed())
+
+			// The backup file is not loaded as a wallet
+			s, err = NewService(Config{
+				WalletDir:       dir,
+				CryptoType:      CryptoTypeSha256Xor,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+			wlts, err := s.GetWallets()
+			require.NoError(t, err)
+			require.Len(t, wlts, 1)
+		})
+	}
+}
+
+func TestServiceCreateWalletWithScan(t *testing.T) {
+	seed := "seed1"
+	addrs := make([]cipher.Address, 20)
+	childSeeds := make([]string, 20)
+	lastSeed := []byte(seed)
+	for i := range addrs {
+		s, pk, _, err := cipher.DeterministicKeyPairIterator(lastSeed)
+		require.NoError(t, err)
+		addrs[i] = cipher.AddressFromPubKey(pk)
+		childSeeds[i] = hex.EncodeToString(s)
+		lastSeed = s
+	}
+
+	bg := make(mockBalanceGetter, 20)
+	for _, a := range addrs {
+		bg[a] = BalancePair{}
+	}
+
+	type exp struct {
+		err              error
+		seed             string
+		lastSeed         string
+		entryNum         int
+		confirmedBalance uint64
+		predictedBalance uint64
+	}
+
+	tt := []struct {
+		name             string
+		opts             Options
+		balGetter        BalanceGetter
+		disableWalletAPI bool
+		expect           exp
+	}{
+		{
+			name: "no coins and scan 0, unencrypted",
+			opts: Options{
+				Seed: "seed1",
+			},
+			balGetter: bg,
+			expect: exp{
+				err:              nil,
+				seed:             seed,
+				lastSeed:         childSeeds[0],
+				entryNum:         1,
+				confirmedBalance: 0,
+				predictedBalance: 0,
+			},
+		},
+		{
+			name: "no coins and scan 0, encrypted",
+			opts: Options{
+				Seed:     seed,
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			balGetter: bg,
+			expect: exp{
+				err:              nil,
+				seed:             seed,
 				lastSeed:         childSeeds[0],
 				entryNum:         1,
 				confirmedBalance: 0,
@@ -1463,6 +2108,91 @@ func TestGetWalletSeed(t *testing.T) {
 	}
 }
 
+func TestServiceGetWalletSeedShares(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+		EnableSeedAPI:   true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", Options{
+		Type:     WalletTypeBip44,
+		Seed:     testBip44Seed,
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+
+	shares, err := s.GetWalletSeedShares("t.wlt", []byte("pwd"), 3, 2)
+	require.NoError(t, err)
+	require.Len(t, shares, 3)
+
+	seed, err := CombineSeedShares(shares[1:])
+	require.NoError(t, err)
+	require.Equal(t, testBip44Seed, seed)
+
+	_, err = s.GetWalletSeedShares("t.wlt", []byte("pwd"), 3, 4)
+	require.Equal(t, ErrInvalidSeedSharesThreshold, err)
+
+	_, err = s.GetWalletSeedShares("t.wlt", []byte("wrong"), 3, 2)
+	require.Equal(t, ErrInvalidPassword, err)
+
+	_, err = s.GetWalletSeedShares("t1.wlt", []byte("pwd"), 3, 2)
+	require.Equal(t, ErrWalletNotExist, err)
+
+	s, err = NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.GetWalletSeedShares("t.wlt", []byte("pwd"), 3, 2)
+	require.Equal(t, ErrSeedAPIDisabled, err)
+}
+
+func TestServiceRecoverWalletFromShares(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	w, err := s.CreateWallet("t.wlt", Options{
+		Type:     WalletTypeBip44,
+		Seed:     testBip44Seed,
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+
+	shares, err := SplitSeed(testBip44Seed, 3, 2)
+	require.NoError(t, err)
+
+	_, _, err = s.RecoverWalletFromShares("t.wlt", shares[:1], "", []byte("newpwd"), 0, nil)
+	require.Equal(t, ErrNotEnoughSeedShares, err)
+
+	otherShares, err := SplitSeed(bip39.MustNewDefaultMnemonic(), 3, 2)
+	require.NoError(t, err)
+	_, _, err = s.RecoverWalletFromShares("t.wlt", otherShares[:2], "", []byte("newpwd"), 0, nil)
+	require.Equal(t, ErrWalletRecoverSeedWrong, err)
+
+	w2, scans, err := s.RecoverWalletFromShares("t.wlt", shares[1:], "", []byte("newpwd"), 0, nil)
+	require.NoError(t, err)
+	require.Empty(t, scans)
+	require.True(t, w2.IsEncrypted())
+	require.Equal(t, w.Entries[0].Address, w2.Entries[0].Address)
+	require.NoError(t, w2.GuardView([]byte("newpwd"), func(w *Wallet) error {
+		require.Equal(t, testBip44Seed, w.seed())
+		return nil
+	}))
+}
+
 func TestServiceView(t *testing.T) {
 	tt := []struct {
 		name             string
@@ -2070,3 +2800,719 @@ func checkNoSensitiveData(t *testing.T, w *Wallet) {
 		require.Equal(t, empty, e.Secret)
 	}
 }
+
+func TestServiceRecoverWallet(t *testing.T) {
+	tt := []struct {
+		name           string
+		opts           Options
+		seed           string
+		seedPassphrase string
+		password       []byte
+		err            error
+	}{
+		{
+			name: "ok deterministic",
+			opts: Options{
+				Seed:      "seed",
+				Encrypt:   true,
+				Password:  []byte("pwd"),
+				GenerateN: 3,
+			},
+			seed:     "seed",
+			password: []byte("pwd2"),
+		},
+		{
+			name: "deterministic wrong seed",
+			opts: Options{
+				Seed:     "seed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			seed: "seed2",
+			err:  ErrWalletRecoverSeedWrong,
+		},
+		{
+			name: "ok bip44",
+			opts: Options{
+				Type:           WalletTypeBip44,
+				Seed:           testBip44Seed,
+				SeedPassphrase: "foo",
+				Encrypt:        true,
+				Password:       []byte("pwd"),
+				GenerateN:      3,
+			},
+			seed:           testBip44Seed,
+			seedPassphrase: "foo",
+		},
+		{
+			name: "bip44 wrong seed passphrase",
+			opts: Options{
+				Type:           WalletTypeBip44,
+				Seed:           testBip44Seed,
+				SeedPassphrase: "foo",
+				Encrypt:        true,
+				Password:       []byte("pwd"),
+			},
+			seed:           testBip44Seed,
+			seedPassphrase: "bar",
+			err:            ErrWalletRecoverSeedWrong,
+		},
+		{
+			name: "bip44 seed not a bip39 mnemonic",
+			opts: Options{
+				Type:     WalletTypeBip44,
+				Seed:     testBip44Seed,
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			seed: "seed",
+			err:  ErrWalletRecoverSeedWrong,
+		},
+		{
+			name: "wallet not encrypted",
+			opts: Options{
+				Seed: "seed",
+			},
+			seed: "seed",
+			err:  ErrWalletNotEncrypted,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := prepareWltDir()
+			s, err := NewService(Config{
+				WalletDir:       dir,
+				CryptoType:      CryptoTypeSha256Xor,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+
+			_, err = s.CreateWallet("t.wlt", tc.opts, nil)
+			require.NoError(t, err)
+
+			if tc.opts.Type == WalletTypeBip44 {
+				err = s.UpdateSecrets("t.wlt", tc.opts.Password, func(w *Wallet) error {
+					_, err := w.GenerateChangeAddresses(1)
+					return err
+				})
+				require.NoError(t, err)
+			}
+
+			w, err := s.GetWallet("t.wlt")
+			require.NoError(t, err)
+
+			w2, err := s.RecoverWallet("t.wlt", tc.seed, tc.seedPassphrase, tc.password)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, len(tc.password) != 0, w2.IsEncrypted())
+			require.Equal(t, w.Type(), w2.Type())
+			require.Equal(t, w.timestamp(), w2.timestamp())
+			require.Equal(t, len(w.Entries), len(w2.Entries))
+			for i := range w.Entries {
+				require.Equal(t, w.Entries[i].Address, w2.Entries[i].Address)
+				require.Equal(t, w.Entries[i].ChildNumber, w2.Entries[i].ChildNumber)
+				require.Equal(t, w.Entries[i].Change, w2.Entries[i].Change)
+			}
+
+			if !w2.IsEncrypted() {
+				require.Equal(t, tc.seed, w2.seed())
+				require.Equal(t, tc.seedPassphrase, w2.seedPassphrase())
+			}
+
+			w3, err := s.GetWallet("t.wlt")
+			require.NoError(t, err)
+			require.Equal(t, w2, w3)
+		})
+	}
+}
+
+func TestServiceRecoverWalletScan(t *testing.T) {
+	// Addresses of the "seed" deterministic wallet after the first 2
+	w, err := NewWallet("t.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+	addrs, err := w.GenerateSkycoinAddresses(10)
+	require.NoError(t, err)
+
+	// The 3rd and 5th following addresses have history but no balance
+	tf := mockTransactionsFinder{
+		addrs[2]: true,
+		addrs[4]: true,
+	}
+
+	tt := []struct {
+		name     string
+		opts     Options
+		seed     string
+		password []byte
+		gapLimit uint64
+		tf       TransactionsFinder
+		n        int
+		scans    []ScanProgress
+		err      error
+	}{
+		{
+			name: "ok encrypted",
+			opts: Options{
+				Seed:      "seed",
+				Encrypt:   true,
+				Password:  []byte("pwd"),
+				GenerateN: 2,
+			},
+			seed:     "seed",
+			password: []byte("pwd2"),
+			gapLimit: 3,
+			tf:       tf,
+			n:        7,
+			scans:    []ScanProgress{{Chain: 0, Scanned: 8, Found: 5}},
+		},
+		{
+			name: "ok encrypted without scan",
+			opts: Options{
+				Seed:      "seed",
+				Encrypt:   true,
+				Password:  []byte("pwd"),
+				GenerateN: 2,
+			},
+			seed:     "seed",
+			password: []byte("pwd2"),
+			n:        2,
+		},
+		{
+			name: "ok unencrypted",
+			opts: Options{
+				Seed:      "seed",
+				GenerateN: 2,
+			},
+			seed:     "seed",
+			gapLimit: 3,
+			tf:       tf,
+			n:        7,
+			scans:    []ScanProgress{{Chain: 0, Scanned: 8, Found: 5}},
+		},
+		{
+			name: "gap limit too small",
+			opts: Options{
+				Seed:      "seed",
+				GenerateN: 2,
+			},
+			seed:     "seed",
+			gapLimit: 2,
+			tf:       tf,
+			n:        2,
+			scans:    []ScanProgress{{Chain: 0, Scanned: 2, Found: 0}},
+		},
+		{
+			name: "unencrypted wallet without scan",
+			opts: Options{
+				Seed: "seed",
+			},
+			seed: "seed",
+			err:  ErrWalletNotEncrypted,
+		},
+		{
+			name: "unencrypted wallet with password",
+			opts: Options{
+				Seed: "seed",
+			},
+			seed:     "seed",
+			password: []byte("pwd"),
+			gapLimit: 3,
+			tf:       tf,
+			err:      ErrWalletNotEncrypted,
+		},
+		{
+			name: "nil transactions finder",
+			opts: Options{
+				Seed:     "seed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			seed:     "seed",
+			gapLimit: 3,
+			err:      ErrNilTransactionsFinder,
+		},
+		{
+			name: "wrong seed",
+			opts: Options{
+				Seed: "seed",
+			},
+			seed:     "seed2",
+			gapLimit: 3,
+			tf:       tf,
+			err:      ErrWalletRecoverSeedWrong,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := prepareWltDir()
+			s, err := NewService(Config{
+				WalletDir:       dir,
+				CryptoType:      CryptoTypeSha256Xor,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+
+			w, err := s.CreateWallet("t.wlt", tc.opts, nil)
+			require.NoError(t, err)
+
+			label := "first"
+			_, err = s.UpdateAddressMeta("t.wlt", w.Entries[0].Address, EntryMetaUpdate{
+				Label: &label,
+			})
+			require.NoError(t, err)
+
+			frozen := []cipher.SHA256{testutil.RandSHA256(t), testutil.RandSHA256(t)}
+			_, err = s.FreezeUxOuts("t.wlt", frozen)
+			require.NoError(t, err)
+			_, err = s.SetFreshChange("t.wlt", true)
+			require.NoError(t, err)
+			_, err = s.SetSignerSocket("t.wlt", "/tmp/signer.sock")
+			require.NoError(t, err)
+
+			w, err = s.GetWallet("t.wlt")
+			require.NoError(t, err)
+
+			w2, scans, err := s.RecoverWalletScan("t.wlt", tc.seed, "", tc.password, tc.gapLimit, tc.tf)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, tc.scans, scans)
+
+			require.Equal(t, len(tc.password) != 0, w2.IsEncrypted())
+			require.Equal(t, w.timestamp(), w2.timestamp())
+			require.Len(t, w2.Entries, tc.n)
+			for i, e := range w2.Entries[2:] {
+				require.Equal(t, addrs[i], e.SkycoinAddress())
+			}
+
+			// The metadata of existing entries is kept
+			require.Equal(t, w.Entries[0].Address, w2.Entries[0].Address)
+			require.Equal(t, label, w2.Entries[0].Label)
+			require.Equal(t, w.Entries[0].Created, w2.Entries[0].Created)
+
+			// The wallet settings are kept
+			require.Equal(t, w.FrozenUxOuts(), w2.FrozenUxOuts())
+			require.Len(t, w2.FrozenUxOuts(), 2)
+			require.True(t, w2.FreshChange())
+			require.Equal(t, "/tmp/signer.sock", w2.SignerSocket())
+
+			w3, err := s.GetWallet("t.wlt")
+			require.NoError(t, err)
+			require.Equal(t, w2, w3)
+
+			lw, err := Load(filepath.Join(dir, "t.wlt"))
+			require.NoError(t, err)
+			require.Len(t, lw.Entries, tc.n)
+			require.Equal(t, w.FrozenUxOuts(), lw.FrozenUxOuts())
+		})
+	}
+}
+
+func TestServiceRecoverWalletArgon2idParams(t *testing.T) {
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeArgon2idChacha20poly1305,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	params := Argon2idParams{
+		Time:        2,
+		Memory:      2048,
+		Parallelism: 2,
+	}
+
+	_, err = s.CreateWallet("t.wlt", Options{
+		Seed:           "seed",
+		Encrypt:        true,
+		Password:       []byte("pwd"),
+		Argon2idParams: &params,
+	}, nil)
+	require.NoError(t, err)
+
+	// The recovered wallet is encrypted with the argon2id parameters of the wallet
+	w, err := s.RecoverWallet("t.wlt", "seed", "", []byte("pwd2"))
+	require.NoError(t, err)
+
+	p, err := w.argon2idParams()
+	require.NoError(t, err)
+	require.Equal(t, &params, p)
+
+	uw, err := w.Unlock([]byte("pwd2"))
+	require.NoError(t, err)
+	require.Equal(t, "seed", uw.seed())
+}
+
+// transactionsFinderFunc calls a function before returning the activity of the addresses
+type transactionsFinderFunc struct {
+	tf TransactionsFinder
+	f  func()
+}
+
+func (t transactionsFinderFunc) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	t.f()
+	return t.tf.AddressesActivity(addrs)
+}
+
+func TestServiceRecoverWalletScanWalletChanged(t *testing.T) {
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", Options{
+		Seed:     "seed",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+
+	// The scan doesn't hold the service lock, so the wallet can be changed while it runs
+	tf := transactionsFinderFunc{
+		tf: mockTransactionsFinder{},
+		f: func() {
+			err := s.UpdateWalletLabel("t.wlt", "changed")
+			require.NoError(t, err)
+		},
+	}
+
+	_, _, err = s.RecoverWalletScan("t.wlt", "seed", "", []byte("pwd2"), 3, tf)
+	require.Equal(t, ErrWalletChanged, err)
+
+	// The changed wallet is kept
+	w, err := s.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "changed", w.Label())
+	require.Len(t, w.Entries, 1)
+
+	uw, err := w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+	require.Equal(t, "seed", uw.seed())
+}
+
+func TestServiceImportRemoveKeys(t *testing.T) {
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 3)
+
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeScryptChacha20poly1305Insecure,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	w, err := s.CreateWallet("t1.wlt", Options{
+		Type:     WalletTypeCollection,
+		Label:    "collection",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+	require.Empty(t, w.Entries)
+
+	// Collection wallets have no seed, so several empty collection wallets can be created
+	w2, err := s.CreateWallet("t2.wlt", Options{
+		Type: WalletTypeCollection,
+	}, nil)
+	require.NoError(t, err)
+
+	d, err := s.CreateWallet("t3.wlt", Options{
+		Seed: "seed",
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = s.ImportKeys(d.Filename(), nil, secKeys)
+	require.Equal(t, ErrWalletNotCollection, err)
+
+	_, err = s.ImportKeys(w.Filename(), nil, secKeys)
+	require.Equal(t, ErrMissingPassword, err)
+
+	_, err = s.ImportKeys(w2.Filename(), []byte("pwd"), secKeys)
+	require.Equal(t, ErrWalletNotEncrypted, err)
+
+	_, err = s.ImportKeys("foo.wlt", nil, secKeys)
+	require.Equal(t, ErrWalletNotExist, err)
+
+	w, err = s.ImportKeys(w.Filename(), []byte("pwd"), secKeys)
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 3)
+	checkNoSensitiveData(t, w)
+
+	// The same key can be imported in multiple collection wallets,
+	// including the first key of a deterministic wallet
+	w2, err = s.ImportKeys(w2.Filename(), nil, secKeys[:1])
+	require.NoError(t, err)
+	require.Len(t, w2.Entries, 1)
+	require.Equal(t, d.Entries[0].Address, w2.Entries[0].Address)
+
+	w, err = s.RemoveKeys(w.Filename(), []byte("pwd"), []cipher.Address{cipher.MustAddressFromSecKey(secKeys[1])})
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 2)
+
+	err = s.ViewSecrets(w.Filename(), []byte("pwd"), func(w *Wallet) error {
+		require.Equal(t, secKeys[0], w.Entries[0].Secret)
+		require.Equal(t, secKeys[2], w.Entries[1].Secret)
+		return nil
+	})
+	require.NoError(t, err)
+
+	w2, err = s.RemoveKeys(w2.Filename(), nil, []cipher.Address{w2.Entries[0].SkycoinAddress()})
+	require.NoError(t, err)
+	require.Empty(t, w2.Entries)
+
+	// Unloading a collection wallet must not affect the deterministic wallet with the same first address
+	w2, err = s.ImportKeys(w2.Filename(), nil, secKeys[:1])
+	require.NoError(t, err)
+	require.NoError(t, s.UnloadWallet(w2.Filename()))
+	_, err = s.CreateWallet("t4.wlt", Options{
+		Seed: "seed",
+	}, nil)
+	require.Equal(t, ErrSeedUsed, err)
+
+	// The wallets are loaded from disk, including empty collection wallets and
+	// collection wallets which share their first address with another wallet
+	_, err = s.CreateWallet("t5.wlt", Options{
+		Type: WalletTypeCollection,
+	}, nil)
+	require.NoError(t, err)
+
+	s2, err := NewService(Config{
+		WalletDir:       dir,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, s2.wallets, 4)
+
+	w3, err := s2.GetWallet(w.Filename())
+	require.NoError(t, err)
+	require.Equal(t, w.Entries, w3.Entries)
+
+	s3, err := NewService(Config{
+		WalletDir:       dir,
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+	_, err = s3.ImportKeys(w.Filename(), []byte("pwd"), secKeys)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+	_, err = s3.RemoveKeys(w.Filename(), []byte("pwd"), nil)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
+
+func TestServiceSpendingPolicy(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	w, err := s.CreateWallet("t.wlt", Options{
+		Seed: "seed",
+	}, nil)
+	require.NoError(t, err)
+	own := w.Entries[0].SkycoinAddress()
+
+	_, err = s.SpendingPolicy("x.wlt")
+	require.Equal(t, ErrWalletNotExist, err)
+
+	p, err := s.SpendingPolicy("t.wlt")
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	dest := testutil.MakeAddress()
+	txn := &coin.Transaction{
+		Out: []coin.TransactionOutput{{Address: dest, Coins: 6e6}},
+	}
+	txn.InnerHash = txn.HashInner()
+
+	// Transactions are allowed without a policy
+	require.NoError(t, s.CheckSpendingPolicy("t.wlt", txn, nil))
+
+	_, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{ApprovalThreshold: 5e6}, nil, nil)
+	require.Equal(t, ErrMissingApprovalPassword, err)
+	_, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{DailyLimit: 10e6}, nil, nil)
+	require.Equal(t, ErrMissingApprovalPassword, err)
+
+	p, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{ApprovalThreshold: 5e6}, nil, []byte("approve"))
+	require.NoError(t, err)
+	require.True(t, p.HasApprovalPassword())
+	testutil.RequireFileExists(t, filepath.Join(dir, "t.wlt"+SpendingPolicyExt))
+
+	// The approval password is required to change the policy
+	_, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{}, nil, nil)
+	require.Equal(t, ErrInvalidApprovalPassword, err)
+	_, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{}, []byte("wrong"), nil)
+	require.Equal(t, ErrInvalidApprovalPassword, err)
+
+	require.Equal(t, ErrSpendingPolicyApprovalRequired, s.CheckSpendingPolicy("t.wlt", txn, nil))
+	require.Equal(t, ErrInvalidApprovalPassword, s.CheckSpendingPolicy("t.wlt", txn, []byte("wrong")))
+
+	// The approval password approves the transaction
+	require.NoError(t, s.CheckSpendingPolicy("t.wlt", txn, []byte("approve")))
+	require.NoError(t, s.CheckSpendingPolicy("t.wlt", txn, nil))
+
+	txn2 := &coin.Transaction{
+		Out: []coin.TransactionOutput{{Address: dest, Coins: 7e6}},
+	}
+	txn2.InnerHash = txn2.HashInner()
+	require.Equal(t, ErrSpendingPolicyApprovalRequired, s.CheckSpendingPolicy("t.wlt", txn2, nil))
+	require.Equal(t, ErrInvalidApprovalPassword, s.ApproveTransaction("t.wlt", txn2.InnerHash, []byte("wrong")))
+	require.NoError(t, s.ApproveTransaction("t.wlt", txn2.InnerHash, []byte("approve")))
+	require.NoError(t, s.CheckSpendingPolicy("t.wlt", txn2, nil))
+
+	// Changing the limits keeps the approval password and the approvals
+	p, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{ApprovalThreshold: 5e6, DailyLimit: 10e6}, []byte("approve"), nil)
+	require.NoError(t, err)
+	require.NoError(t, p.VerifyApprovalPassword([]byte("approve")))
+	require.NoError(t, s.CheckSpendingPolicy("t.wlt", txn2, nil))
+
+	// Spends are recorded for the wallets that own the inputs
+	inputs := coin.UxArray{{Body: coin.UxBody{Address: own}}}
+	spends, err := s.RecordSpendingPolicies(txn, nil)
+	require.NoError(t, err)
+	require.Nil(t, spends)
+
+	// Discarded spends are not saved and don't count towards the limits
+	spends, err = s.RecordSpendingPolicies(txn, inputs)
+	require.NoError(t, err)
+	require.NotNil(t, spends)
+	spends.Discard()
+	require.Empty(t, s.pendingSpends)
+	require.NoError(t, s.CheckSpendingPolicy("t.wlt", txn2, nil))
+
+	// Pending spends count towards the limits before they are saved
+	spends, err = s.RecordSpendingPolicies(txn, inputs)
+	require.NoError(t, err)
+	require.NotNil(t, spends)
+	p, err = s.SpendingPolicy("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), p.Spent(time.Now()))
+
+	spends2, err := s.RecordSpendingPolicies(txn, inputs)
+	require.NoError(t, err)
+	require.Nil(t, spends2)
+	_, err = s.RecordSpendingPolicies(txn2, inputs)
+	require.Equal(t, ErrSpendingPolicyDailyLimitExceeded, err)
+	require.Equal(t, ErrSpendingPolicyDailyLimitExceeded, s.CheckSpendingPolicy("t.wlt", txn2, nil))
+
+	require.NoError(t, spends.Save())
+	require.Empty(t, s.pendingSpends)
+	p, err = s.SpendingPolicy("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, uint64(6e6), p.Spent(time.Now()))
+
+	_, err = s.RecordSpendingPolicies(txn2, inputs)
+	require.Equal(t, ErrSpendingPolicyDailyLimitExceeded, err)
+	require.Equal(t, ErrSpendingPolicyDailyLimitExceeded, s.CheckSpendingPolicy("t.wlt", txn2, nil))
+
+	require.NoError(t, s.UnloadWallet("t.wlt"))
+	spends, err = s.RecordSpendingPolicies(txn2, inputs)
+	require.NoError(t, err)
+	require.Nil(t, spends)
+	_, err = s.SpendingPolicy("t.wlt")
+	require.Equal(t, ErrWalletNotExist, err)
+	require.Equal(t, ErrWalletNotExist, s.ApproveTransaction("t.wlt", txn2.InnerHash, []byte("approve")))
+
+	// The wallet API must be enabled
+	s, err = NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+	_, err = s.SpendingPolicy("t.wlt")
+	require.Equal(t, ErrWalletAPIDisabled, err)
+	_, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{}, nil, nil)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+	require.Equal(t, ErrWalletAPIDisabled, s.ApproveTransaction("t.wlt", txn2.InnerHash, nil))
+	require.Equal(t, ErrWalletAPIDisabled, s.CheckSpendingPolicy("t.wlt", txn2, nil))
+	spends, err = s.RecordSpendingPolicies(txn2, inputs)
+	require.NoError(t, err)
+	require.Nil(t, spends)
+}
+
+func TestServiceSignPartialTransactionSpendingPolicy(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	w, err := s.CreateWallet("t.wlt", Options{
+		Seed: "seed",
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{DailyLimit: 15e5}, nil, []byte("approve"))
+	require.NoError(t, err)
+
+	// Each transaction sends 1e6 coins to another address
+	ux := makeUxOut(t, w.Entries[0].Secret, 1e6, 10)
+	pt := makePartialTransaction(t, []coin.UxOut{ux})
+	ux2 := makeUxOut(t, w.Entries[0].Secret, 2e6, 10)
+	pt2 := makePartialTransaction(t, []coin.UxOut{ux2})
+
+	// The signed transaction is recorded as a spend, although it is not injected
+	signed, err := s.SignPartialTransaction("t.wlt", nil, pt)
+	require.NoError(t, err)
+	require.True(t, signed.IsFullySigned())
+	require.Empty(t, s.pendingSpends)
+	p, err := s.SpendingPolicy("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1e6), p.Spent(time.Now()))
+
+	// The second transaction exceeds the daily limit together with the first one
+	_, err = s.SignPartialTransaction("t.wlt", nil, pt2)
+	require.Equal(t, ErrSpendingPolicyDailyLimitExceeded, err)
+	require.Empty(t, s.pendingSpends)
+
+	// Signing the first transaction again doesn't record it twice
+	_, err = s.SignPartialTransaction("t.wlt", nil, pt)
+	require.NoError(t, err)
+
+	// Injecting the signed transaction doesn't record it twice
+	spends, err := s.RecordSpendingPolicies(&signed.Transaction, coin.UxArray{ux})
+	require.NoError(t, err)
+	require.Nil(t, spends)
+
+	p, err = s.SpendingPolicy("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1e6), p.Spent(time.Now()))
+
+	// The spend ages out with the policy window
+	require.Equal(t, uint64(0), p.Spent(time.Now().Add(SpendingPolicyWindow)))
+
+	// Failing to sign discards the spend
+	_, err = s.SetSpendingPolicy("t.wlt", SpendingLimits{DailyLimit: 5e6}, []byte("approve"), nil)
+	require.NoError(t, err)
+	uxC, _ := makeUxOutWithSecret(t)
+	_, err = s.SignPartialTransaction("t.wlt", nil, makePartialTransaction(t, []coin.UxOut{uxC}))
+	require.Equal(t, ErrNoInputsToSign, err)
+	require.Empty(t, s.pendingSpends)
+	p, err = s.SpendingPolicy("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1e6), p.Spent(time.Now()))
+}
@@ -0,0 +1,261 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// ExternalSignerTimeout is the maximum time to wait for an external signer to sign an input
+const ExternalSignerTimeout = time.Minute
+
+var (
+	// ErrSignerUnknownAddress is returned if a signer has no secret key for the address of an input
+	ErrSignerUnknownAddress = NewError(errors.New("signer has no secret key for the address"))
+	// ErrSignerInvalidSignature is returned if a signer returns a signature that doesn't match the address of the input
+	ErrSignerInvalidSignature = NewError(errors.New("signer returned an invalid signature"))
+)
+
+// SignInputRequest describes a transaction input to sign.
+// The signed hash is cipher.AddSHA256(InnerHash, Input), as in coin.Transaction.SignInput.
+type SignInputRequest struct {
+	InnerHash cipher.SHA256
+	Index     int
+	Input     cipher.SHA256
+	Address   cipher.Address
+}
+
+// Hash returns the hash to sign
+func (r SignInputRequest) Hash() cipher.SHA256 {
+	return cipher.AddSHA256(r.InnerHash, r.Input)
+}
+
+// Signer signs transaction inputs on behalf of a wallet, so that the secret keys
+// don't have to be held by the process that builds the transactions
+type Signer interface {
+	// SignInput signs a transaction input with the secret key of its address
+	SignInput(req SignInputRequest) (cipher.Sig, error)
+}
+
+// SoftwareSigner is a Signer holding the secret keys in memory
+type SoftwareSigner struct {
+	keys map[cipher.Address]cipher.SecKey
+}
+
+// NewSoftwareSigner creates a SoftwareSigner with secret keys
+func NewSoftwareSigner(keys []cipher.SecKey) (*SoftwareSigner, error) {
+	s := &SoftwareSigner{
+		keys: make(map[cipher.Address]cipher.SecKey, len(keys)),
+	}
+
+	for _, k := range keys {
+		addr, err := cipher.AddressFromSecKey(k)
+		if err != nil {
+			return nil, err
+		}
+		s.keys[addr] = k
+	}
+
+	return s, nil
+}
+
+// newEntriesSigner creates a SoftwareSigner with the secret keys of wallet entries
+func newEntriesSigner(entries []Entry) *SoftwareSigner {
+	s := &SoftwareSigner{
+		keys: make(map[cipher.Address]cipher.SecKey, len(entries)),
+	}
+
+	for _, e := range entries {
+		if e.Secret != (cipher.SecKey{}) {
+			s.keys[e.SkycoinAddress()] = e.Secret
+		}
+	}
+
+	return s
+}
+
+// SignInput signs a transaction input with the secret key of its address
+func (s *SoftwareSigner) SignInput(req SignInputRequest) (cipher.Sig, error) {
+	key, ok := s.keys[req.Address]
+	if !ok {
+		return cipher.Sig{}, ErrSignerUnknownAddress
+	}
+
+	return cipher.SignHash(req.Hash(), key)
+}
+
+// signInput signs the input of a transaction at index with a signer, and checks the signature
+func signInput(s Signer, txn *coin.Transaction, index int, addr cipher.Address) error {
+	if index < 0 || index >= len(txn.In) {
+		return errors.New("Signature index out of range")
+	}
+	if len(txn.Sigs) != len(txn.In) {
+		return errors.New("Number of signatures does not match number of inputs")
+	}
+	if !txn.Sigs[index].Null() {
+		return errors.New("Input already signed")
+	}
+
+	req := SignInputRequest{
+		InnerHash: txn.InnerHash,
+		Index:     index,
+		Input:     txn.In[index],
+		Address:   addr,
+	}
+
+	sig, err := s.SignInput(req)
+	if err != nil {
+		return err
+	}
+
+	// The signer may be a separate process, so its signature is not trusted
+	if err := cipher.VerifyAddressSignedHash(addr, sig, req.Hash()); err != nil {
+		return ErrSignerInvalidSignature
+	}
+
+	txn.Sigs[index] = sig
+	return nil
+}
+
+// SignerRequest is a request of the external signer protocol.
+// The protocol exchanges one JSON object per line: the client writes a SignerRequest,
+// and the signer replies with a SignerResponse.
+type SignerRequest struct {
+	InnerHash string `json:"inner_hash"`
+	Index     int    `json:"index"`
+	Input     string `json:"input"`
+	Address   string `json:"address"`
+}
+
+// SignerResponse is a response of the external signer protocol.
+// Error is set if the input was not signed.
+type SignerResponse struct {
+	Sig   string `json:"sig,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewSignerRequest creates a SignerRequest
+func NewSignerRequest(req SignInputRequest) SignerRequest {
+	return SignerRequest{
+		InnerHash: req.InnerHash.Hex(),
+		Index:     req.Index,
+		Input:     req.Input.Hex(),
+		Address:   req.Address.String(),
+	}
+}
+
+// ToSignInputRequest converts a SignerRequest to a SignInputRequest
+func (r SignerRequest) ToSignInputRequest() (*SignInputRequest, error) {
+	innerHash, err := cipher.SHA256FromHex(r.InnerHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inner_hash: %v", err)
+	}
+
+	input, err := cipher.SHA256FromHex(r.Input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+
+	addr, err := cipher.DecodeBase58Address(r.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %v", err)
+	}
+
+	return &SignInputRequest{
+		InnerHash: innerHash,
+		Index:     r.Index,
+		Input:     input,
+		Address:   addr,
+	}, nil
+}
+
+// ServeSigner serves the external signer protocol with a signer, reading requests from r and writing responses to w,
+// until r is closed. It can serve a process' stdin and stdout, or a unix socket connection.
+func ServeSigner(r io.Reader, w io.Writer, s Signer) error {
+	scanner := bufio.NewScanner(r)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		var rsp SignerResponse
+		if sig, err := serveSignerRequest(scanner.Bytes(), s); err != nil {
+			rsp.Error = err.Error()
+		} else {
+			rsp.Sig = sig.Hex()
+		}
+
+		if err := enc.Encode(rsp); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func serveSignerRequest(line []byte, s Signer) (cipher.Sig, error) {
+	var req SignerRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return cipher.Sig{}, err
+	}
+
+	r, err := req.ToSignInputRequest()
+	if err != nil {
+		return cipher.Sig{}, err
+	}
+
+	return s.SignInput(*r)
+}
+
+// ExternalSigner is a Signer delegating to a separate process which serves
+// the external signer protocol on a unix socket
+type ExternalSigner struct {
+	dial func() (net.Conn, error)
+}
+
+// NewExternalSigner creates an ExternalSigner connecting to the unix socket at socketPath
+func NewExternalSigner(socketPath string) *ExternalSigner {
+	return &ExternalSigner{
+		dial: func() (net.Conn, error) {
+			return net.DialTimeout("unix", socketPath, ExternalSignerTimeout)
+		},
+	}
+}
+
+// SignInput sends the input to the external signer and returns its signature
+func (s *ExternalSigner) SignInput(req SignInputRequest) (cipher.Sig, error) {
+	conn, err := s.dial()
+	if err != nil {
+		return cipher.Sig{}, fmt.Errorf("external signer: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(ExternalSignerTimeout)); err != nil {
+		return cipher.Sig{}, fmt.Errorf("external signer: %v", err)
+	}
+
+	if err := json.NewEncoder(conn).Encode(NewSignerRequest(req)); err != nil {
+		return cipher.Sig{}, fmt.Errorf("external signer: %v", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return cipher.Sig{}, fmt.Errorf("external signer: %v", err)
+	}
+
+	var rsp SignerResponse
+	if err := json.Unmarshal(line, &rsp); err != nil {
+		return cipher.Sig{}, fmt.Errorf("external signer: %v", err)
+	}
+
+	if rsp.Error != "" {
+		return cipher.Sig{}, fmt.Errorf("external signer: %s", rsp.Error)
+	}
+
+	return cipher.SigFromHex(rsp.Sig)
+}
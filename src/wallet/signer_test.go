@@ -0,0 +1,248 @@
+package wallet
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/transaction"
+)
+
+// signerFunc adapts a function to the Signer interface
+type signerFunc func(req SignInputRequest) (cipher.Sig, error)
+
+func (f signerFunc) SignInput(req SignInputRequest) (cipher.Sig, error) {
+	return f(req)
+}
+
+// pipeSigner creates an ExternalSigner connected to a signer served with ServeSigner
+func pipeSigner(s Signer) *ExternalSigner {
+	return &ExternalSigner{
+		dial: func() (net.Conn, error) {
+			client, server := net.Pipe()
+			go func() {
+				defer server.Close()
+				ServeSigner(server, server, s) // nolint: errcheck
+			}()
+			return client, nil
+		},
+	}
+}
+
+func TestSoftwareSigner(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	addr := cipher.AddressFromPubKey(pk)
+
+	s, err := NewSoftwareSigner([]cipher.SecKey{sk})
+	require.NoError(t, err)
+
+	req := SignInputRequest{
+		InnerHash: testutil.RandSHA256(t),
+		Index:     0,
+		Input:     testutil.RandSHA256(t),
+		Address:   addr,
+	}
+
+	sig, err := s.SignInput(req)
+	require.NoError(t, err)
+	require.NoError(t, cipher.VerifyAddressSignedHash(addr, sig, req.Hash()))
+
+	req.Address = testutil.MakeAddress()
+	_, err = s.SignInput(req)
+	require.Equal(t, ErrSignerUnknownAddress, err)
+
+	_, err = NewSoftwareSigner([]cipher.SecKey{{}})
+	require.Error(t, err)
+}
+
+func TestExternalSigner(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	addr := cipher.AddressFromPubKey(pk)
+
+	ss, err := NewSoftwareSigner([]cipher.SecKey{sk})
+	require.NoError(t, err)
+
+	req := SignInputRequest{
+		InnerHash: testutil.RandSHA256(t),
+		Index:     1,
+		Input:     testutil.RandSHA256(t),
+		Address:   addr,
+	}
+
+	sig, err := pipeSigner(ss).SignInput(req)
+	require.NoError(t, err)
+	require.NoError(t, cipher.VerifyAddressSignedHash(addr, sig, req.Hash()))
+
+	// The error of the signer is returned
+	req.Address = testutil.MakeAddress()
+	_, err = pipeSigner(ss).SignInput(req)
+	require.Equal(t, errors.New("external signer: signer has no secret key for the address"), err)
+
+	// The request is passed to the signer unchanged
+	var received SignInputRequest
+	_, err = pipeSigner(signerFunc(func(r SignInputRequest) (cipher.Sig, error) {
+		received = r
+		return cipher.Sig{}, nil
+	})).SignInput(req)
+	require.NoError(t, err)
+	require.Equal(t, req, received)
+
+	_, err = (&ExternalSigner{
+		dial: func() (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+	}).SignInput(req)
+	require.Equal(t, errors.New("external signer: connection refused"), err)
+}
+
+func TestServeSignerInvalidRequest(t *testing.T) {
+	ss, err := NewSoftwareSigner(nil)
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		ServeSigner(server, server, ss) // nolint: errcheck
+	}()
+	defer client.Close()
+
+	_, err = client.Write([]byte(`{"inner_hash":"00"}` + "\n"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, err := client.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, `{"error":"invalid inner_hash: Invalid hex length"}`+"\n", string(buf[:n]))
+}
+
+func TestWalletSignTransactionExternalSigner(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	ss := newEntriesSigner(w.Entries)
+
+	// The keys are held by the signer only
+	for i := range w.Entries {
+		w.Entries[i].Secret = cipher.SecKey{}
+	}
+
+	dir, err := ioutil.TempDir("", "signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "signer.sock")
+
+	l, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer l.Close()
+
+	var mu sync.Mutex
+	var signer Signer = ss
+	setSigner := func(s Signer) {
+		mu.Lock()
+		defer mu.Unlock()
+		signer = s
+	}
+	getSigner := func() Signer {
+		mu.Lock()
+		defer mu.Unlock()
+		return signer
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			ServeSigner(conn, conn, getSigner()) // nolint: errcheck
+			conn.Close()
+		}
+	}()
+
+	require.NoError(t, w.SetSignerSocket(socketPath))
+	require.Equal(t, socketPath, w.SignerSocket())
+	require.NoError(t, w.Validate())
+
+	var uxouts []coin.UxOut
+	auxs := make(coin.AddressUxOuts)
+	for _, e := range w.Entries {
+		ux := coin.UxOut{
+			Head: coin.UxHead{
+				Time:  100,
+				BkSeq: 2,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        e.SkycoinAddress(),
+				Coins:          2e6,
+				Hours:          100,
+			},
+		}
+		uxouts = append(uxouts, ux)
+		auxs[e.SkycoinAddress()] = []coin.UxOut{ux}
+	}
+
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type: transaction.HoursSelectionTypeManual,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Hours:   10,
+				Coins:   3e6,
+			},
+		},
+	}
+
+	txn, _, err := w.CreateTransactionSigned(p, auxs, 200)
+	require.NoError(t, err)
+	require.True(t, txn.IsFullySigned())
+	require.NoError(t, txn.Verify())
+
+	unsigned, inputs, err := w.CreateTransaction(p, auxs, 200)
+	require.NoError(t, err)
+
+	uxs := make([]coin.UxOut, len(inputs))
+	for i, in := range inputs {
+		for _, ux := range uxouts {
+			if ux.Hash() == in.Hash {
+				uxs[i] = ux
+			}
+		}
+	}
+
+	signed, err := w.SignTransaction(unsigned, nil, uxs)
+	require.NoError(t, err)
+	require.True(t, signed.IsFullySigned())
+	require.NoError(t, signed.Verify())
+
+	// Signatures which don't match the input address are rejected
+	setSigner(signerFunc(func(r SignInputRequest) (cipher.Sig, error) {
+		_, sk := cipher.GenerateKeyPair()
+		return cipher.SignHash(r.Hash(), sk)
+	}))
+
+	_, err = w.SignTransaction(unsigned, nil, uxs)
+	require.Equal(t, ErrSignerInvalidSignature, err)
+
+	// The wallet signs with its own keys without the signer
+	require.NoError(t, w.SetSignerSocket(""))
+	require.Empty(t, w.SignerSocket())
+	_, err = w.SignTransaction(unsigned, nil, uxs)
+	require.Equal(t, ErrSignerUnknownAddress, err)
+
+	require.Equal(t, ErrInvalidSignerSocket, w.SetSignerSocket("signer.sock"))
+}
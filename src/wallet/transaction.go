@@ -6,6 +6,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/transaction"
 )
 
@@ -65,12 +66,14 @@ func copyTransaction(txn *coin.Transaction) *coin.Transaction {
 // The transaction should already have a valid header. The transaction may be partially signed,
 // but a valid existing signature cannot be overwritten.
 // Clients should avoid signing the same transaction multiple times.
+// The inputs are signed by the wallet's Signer.
 func (w *Wallet) SignTransaction(txn *coin.Transaction, signIndexes []int, uxOuts []coin.UxOut) (*coin.Transaction, error) {
 	signedTxn := copyTransaction(txn)
 	txnInnerHash := signedTxn.HashInner()
 
-	if w.IsEncrypted() {
-		return nil, ErrWalletEncrypted
+	signer, err := w.Signer()
+	if err != nil {
+		return nil, err
 	}
 
 	if txnInnerHash != signedTxn.InnerHash {
@@ -120,14 +123,14 @@ func (w *Wallet) SignTransaction(txn *coin.Transaction, signIndexes []int, uxOut
 	}
 
 	// Check that the wallet has all addresses needed for signing
-	toSign := make(map[int][]int)
-	for i, e := range w.Entries {
+	toSign := make(map[cipher.Address][]int)
+	for _, e := range w.Entries {
 		if len(toSign) == len(addrs) {
 			break
 		}
 		addr := e.SkycoinAddress()
 		if x, ok := addrs[addr]; ok {
-			toSign[i] = x
+			toSign[addr] = x
 		}
 	}
 
@@ -136,12 +139,12 @@ func (w *Wallet) SignTransaction(txn *coin.Transaction, signIndexes []int, uxOut
 	}
 
 	// Sign the selected inputs
-	for k, v := range toSign {
+	for addr, v := range toSign {
 		for _, x := range v {
 			if !signedTxn.Sigs[x].Null() {
 				return nil, NewError(fmt.Errorf("Transaction is already signed at index %d", x))
 			}
-			if err := signedTxn.SignInput(w.Entries[k].Secret, x); err != nil {
+			if err := signInput(signer, signedTxn, x, addr); err != nil {
 				return nil, err
 			}
 		}
@@ -175,6 +178,7 @@ func (w *Wallet) SignTransaction(txn *coin.Transaction, signIndexes []int, uxOut
 // Set the password as nil if the wallet is not encrypted, otherwise the password must be provided.
 // NOTE: Caller must ensure that auxs correspond to params.Wallet.Addresses and params.Wallet.UxOuts options
 // Outputs to spend are chosen from the pool of outputs provided.
+// The outputs frozen in the wallet are added to p.FrozenUxOuts, so they are not spent unless they are in p.SelectedUxOuts.
 // The outputs are chosen by the following procedure:
 //   - All outputs are merged into one list and are sorted coins highest, hours lowest, with the hash as a tiebreaker
 //   - Outputs are chosen from the beginning of this list, until the requested amount of coins is met.
@@ -196,6 +200,8 @@ func (w *Wallet) CreateTransaction(p transaction.Params, auxs coin.AddressUxOuts
 		}
 	}
 
+	p.FrozenUxOuts = append(w.FrozenUxOuts(), p.FrozenUxOuts...)
+
 	return transaction.Create(p, auxs, headTime)
 }
 
@@ -203,28 +209,27 @@ func (w *Wallet) CreateTransaction(p transaction.Params, auxs coin.AddressUxOuts
 // Set the password as nil if the wallet is not encrypted, otherwise the password must be provided.
 // Refer to CreateTransaction for information about transaction creation.
 func (w *Wallet) CreateTransactionSigned(p transaction.Params, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []transaction.UxBalance, error) {
+	signer, err := w.Signer()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	txn, uxb, err := w.CreateTransaction(p, auxs, headTime)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Sign the transaction
-	entriesMap := make(map[cipher.Address]Entry)
 	for i, s := range uxb {
-		entry, ok := entriesMap[s.Address]
-		if !ok {
-			entry, ok = w.GetEntry(s.Address)
-			if !ok {
-				// This should not occur because CreateTransaction should have checked it already
-				err := fmt.Errorf("Chosen spend address %s not found in wallet", s.Address)
-				logger.Critical().WithError(err).Error()
-				return nil, nil, err
-			}
-			entriesMap[s.Address] = entry
+		if !w.HasEntry(s.Address) {
+			// This should not occur because CreateTransaction should have checked it already
+			err := fmt.Errorf("Chosen spend address %s not found in wallet", s.Address)
+			logger.Critical().WithError(err).Error()
+			return nil, nil, err
 		}
 
-		if err := txn.SignInput(entry.Secret, i); err != nil {
-			logger.Critical().WithError(err).Error("CreateTransaction SignInput failed")
+		if err := signInput(signer, txn, i, s.Address); err != nil {
+			logger.WithError(err).Error("CreateTransaction signInput failed")
 			return nil, nil, err
 		}
 	}
@@ -237,6 +242,65 @@ func (w *Wallet) CreateTransactionSigned(p transaction.Params, auxs coin.Address
 	return txn, uxb, nil
 }
 
+// CreateSplitTransactionsSigned creates and signs the transactions of a spend based upon transaction.Params,
+// splitting it into consolidation transactions followed by the payment transaction if a single transaction
+// would be larger than params.UserVerifyTxn.MaxTransactionSize. The payment transaction is the last transaction.
+// Refer to transaction.CreateSplit for information about transaction creation.
+func (w *Wallet) CreateSplitTransactionsSigned(p transaction.Params, auxs coin.AddressUxOuts, headTime uint64) ([]coin.Transaction, [][]transaction.UxBalance, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	// Check that auxs does not contain addresses that are not known to this wallet
+	for a := range auxs {
+		if !w.HasEntry(a) {
+			return nil, nil, fmt.Errorf("Address %s from auxs not found in wallet", a)
+		}
+	}
+
+	signer, err := w.Signer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.FrozenUxOuts = append(w.FrozenUxOuts(), p.FrozenUxOuts...)
+
+	txns, inputs, err := transaction.CreateSplit(p, auxs, headTime, params.UserVerifyTxn.MaxTransactionSize, func(txn *coin.Transaction, uxb []transaction.UxBalance) error {
+		for i, s := range uxb {
+			if !w.HasEntry(s.Address) {
+				// This should not occur because the consolidation outputs are sent to addresses of the spent outputs
+				err := fmt.Errorf("Chosen spend address %s not found in wallet", s.Address)
+				logger.Critical().WithError(err).Error()
+				return err
+			}
+
+			if err := signInput(signer, txn, i, s.Address); err != nil {
+				logger.WithError(err).Error("CreateSplitTransactionsSigned signInput failed")
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Sanity check the signed transactions
+	for i := range txns[:len(txns)-1] {
+		if !txns[i].IsFullySigned() {
+			return nil, nil, errors.New("Transaction is not fully signed")
+		}
+	}
+
+	last := len(txns) - 1
+	if err := verifyCreatedSignedInvariants(p, &txns[last], inputs[last]); err != nil {
+		return nil, nil, err
+	}
+
+	return txns, inputs, nil
+}
+
 func verifyCreatedSignedInvariants(p transaction.Params, txn *coin.Transaction, inputs []transaction.UxBalance) error {
 	if !txn.IsFullySigned() {
 		return errors.New("Transaction is not fully signed")
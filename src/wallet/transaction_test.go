@@ -15,6 +15,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/transaction"
 	"github.com/skycoin/skycoin/src/util/fee"
@@ -824,3 +825,200 @@ func makeEntry() Entry {
 		Address: a,
 	}
 }
+
+func TestXPubWalletTransaction(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Type:      WalletTypeXPub,
+		XPub:      testXPub,
+		GenerateN: 1,
+	})
+	require.NoError(t, err)
+
+	addr := w.Entries[0].SkycoinAddress()
+	uxout := coin.UxOut{
+		Head: coin.UxHead{
+			Time:  100,
+			BkSeq: 2,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        addr,
+			Coins:          2e6,
+			Hours:          100,
+		},
+	}
+
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type: transaction.HoursSelectionTypeManual,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Hours:   10,
+				Coins:   1e6,
+			},
+		},
+	}
+	auxs := coin.AddressUxOuts{
+		addr: []coin.UxOut{uxout},
+	}
+
+	// Unsigned transactions can be created by watch-only wallets
+	txn, _, err := w.CreateTransaction(p, auxs, 200)
+	require.NoError(t, err)
+	require.True(t, txn.IsFullyUnsigned())
+
+	_, _, err = w.CreateTransactionSigned(p, auxs, 200)
+	require.Equal(t, ErrWalletCantSign, err)
+
+	_, _, err = w.CreateSplitTransactionsSigned(p, auxs, 200)
+	require.Equal(t, ErrWalletCantSign, err)
+
+	_, err = w.SignTransaction(txn, nil, []coin.UxOut{uxout})
+	require.Equal(t, ErrWalletCantSign, err)
+}
+
+func TestWalletCreateTransactionFrozenUxOuts(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 1,
+	})
+	require.NoError(t, err)
+
+	addr := w.Entries[0].SkycoinAddress()
+	uxouts := make([]coin.UxOut, 2)
+	for i := range uxouts {
+		uxouts[i] = coin.UxOut{
+			Head: coin.UxHead{
+				Time:  100,
+				BkSeq: 2,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        addr,
+				Coins:          2e6,
+				Hours:          100,
+			},
+		}
+	}
+
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type: transaction.HoursSelectionTypeManual,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Hours:   10,
+				Coins:   1e6,
+			},
+		},
+	}
+	auxs := coin.AddressUxOuts{
+		addr: uxouts,
+	}
+
+	// Frozen outputs are not spent
+	w.FreezeUxOuts([]cipher.SHA256{uxouts[0].Hash()})
+	txn, _, err := w.CreateTransactionSigned(p, auxs, 200)
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{uxouts[1].Hash()}, txn.In)
+	require.Empty(t, p.FrozenUxOuts)
+
+	w.FreezeUxOuts([]cipher.SHA256{uxouts[1].Hash()})
+	_, _, err = w.CreateTransaction(p, auxs, 200)
+	require.Equal(t, transaction.ErrUnspentsFrozen, err)
+
+	// Frozen outputs are spent if explicitly selected
+	p.SelectedUxOuts = []cipher.SHA256{uxouts[0].Hash()}
+	txn, _, err = w.CreateTransaction(p, auxs, 200)
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{uxouts[0].Hash()}, txn.In)
+}
+
+func TestWalletCreateSplitTransactionsSigned(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Seed:      "seed",
+		GenerateN: 3,
+	})
+	require.NoError(t, err)
+
+	// More outputs than fit in a single transaction
+	auxs := make(coin.AddressUxOuts)
+	nUxOuts := int(params.UserVerifyTxn.MaxTransactionSize / 64)
+	for i := 0; i < nUxOuts; i++ {
+		addr := w.Entries[i%len(w.Entries)].SkycoinAddress()
+		auxs[addr] = append(auxs[addr], coin.UxOut{
+			Head: coin.UxHead{
+				Time:  100,
+				BkSeq: 2,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        addr,
+				Coins:          1e6,
+				Hours:          100,
+			},
+		})
+	}
+
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type: transaction.HoursSelectionTypeManual,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Hours:   10,
+				Coins:   uint64(nUxOuts-5) * 1e6,
+			},
+		},
+	}
+
+	txn, _, err := w.CreateTransactionSigned(p, auxs, 200)
+	require.NoError(t, err)
+	size, err := txn.Size()
+	require.NoError(t, err)
+	require.True(t, size > params.UserVerifyTxn.MaxTransactionSize)
+
+	txns, inputs, err := w.CreateSplitTransactionsSigned(p, auxs, 200)
+	require.NoError(t, err)
+	require.True(t, len(txns) > 2)
+	require.Len(t, inputs, len(txns))
+
+	uxouts := make(map[cipher.SHA256]coin.UxOut, nUxOuts)
+	for _, uxs := range auxs {
+		for _, ux := range uxs {
+			uxouts[ux.Hash()] = ux
+		}
+	}
+
+	for i, txn := range txns {
+		require.True(t, txn.IsFullySigned())
+
+		size, err := txn.Size()
+		require.NoError(t, err)
+		require.True(t, size <= params.UserVerifyTxn.MaxTransactionSize)
+
+		uxIn := make(coin.UxArray, len(inputs[i]))
+		for j, in := range inputs[i] {
+			ux, ok := uxouts[in.Hash]
+			require.True(t, ok)
+			uxIn[j] = ux
+		}
+
+		require.NoError(t, txn.VerifyInputSignatures(uxIn))
+
+		// The payment spends the outputs of the consolidation transactions
+		if i == len(txns)-1 {
+			require.Equal(t, p.To[0], txn.Out[0])
+		} else {
+			require.Len(t, txn.Out, 1)
+			ux, err := coin.CreateUnspent(coin.BlockHeader{BkSeq: 3}, txn, 0)
+			require.NoError(t, err)
+			uxouts[ux.Hash()] = ux
+		}
+	}
+
+}
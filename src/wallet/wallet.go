@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +17,10 @@ import (
 	"encoding/hex"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip32"
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/cipher/bip44"
+	"github.com/skycoin/skycoin/src/cipher/encrypt"
 
 	"github.com/skycoin/skycoin/src/util/logging"
 )
@@ -58,6 +63,8 @@ var (
 	ErrMissingAuthenticated = NewError(errors.New("missing authenticated metadata"))
 	// ErrWrongCryptoType is returned when decrypting wallet with wrong crypto method
 	ErrWrongCryptoType = NewError(errors.New("wrong crypto type"))
+	// ErrInvalidCryptoType is returned for unknown crypto types
+	ErrInvalidCryptoType = NewError(errors.New("invalid crypto type"))
 	// ErrWalletNotExist is returned if a wallet does not exist
 	ErrWalletNotExist = NewError(errors.New("wallet doesn't exist"))
 	// ErrSeedUsed is returned if a wallet already exists with the same seed
@@ -70,12 +77,44 @@ var (
 	ErrWalletNameConflict = NewError(errors.New("wallet name would conflict with existing wallet, renaming"))
 	// ErrWalletRecoverSeedWrong is returned if the seed does not match the specified wallet when recovering
 	ErrWalletRecoverSeedWrong = NewError(errors.New("wallet recovery seed is wrong"))
+	// ErrWalletChanged is returned if a wallet was changed while it was being recovered
+	ErrWalletChanged = NewError(errors.New("wallet was changed while it was being recovered"))
 	// ErrNilBalanceGetter is returned if Options.ScanN > 0 but a nil BalanceGetter was provided
 	ErrNilBalanceGetter = NewError(errors.New("scan ahead requested but balance getter is nil"))
+	// ErrNilTransactionsFinder is returned if an address history scan is requested but a nil TransactionsFinder was provided
+	ErrNilTransactionsFinder = NewError(errors.New("address history scan requested but transactions finder is nil"))
 	// ErrWalletNotDeterministic is returned if a wallet's type is not deterministic but it is necessary for the requested operation
 	ErrWalletNotDeterministic = NewError(errors.New("wallet type is not deterministic"))
 	// ErrInvalidCoinType is returned for invalid coin types
 	ErrInvalidCoinType = NewError(errors.New("invalid coin type"))
+	// ErrInvalidWalletType is returned for invalid wallet types
+	ErrInvalidWalletType = NewError(errors.New("invalid wallet type"))
+	// ErrSeedPassphraseNotSupported is returned if a seed passphrase is provided for a wallet type that does not use one
+	ErrSeedPassphraseNotSupported = NewError(errors.New("seed passphrase is only supported by bip44 wallets"))
+	// ErrWalletNotBip44 is returned if a wallet's type is not bip44 but it is necessary for the requested operation
+	ErrWalletNotBip44 = NewError(errors.New("wallet type is not bip44"))
+	// ErrInvalidBip44Seed is returned if a bip44 wallet seed is not a valid bip39 mnemonic
+	ErrInvalidBip44Seed = NewError(errors.New("bip44 wallet seed must be a valid bip39 mnemonic"))
+	// ErrMissingXPub is returned when trying to create an xpub wallet without an xpub key
+	ErrMissingXPub = NewError(errors.New("missing xpub"))
+	// ErrXPubNotSupported is returned if an xpub key is provided for a wallet type that does not use one
+	ErrXPubNotSupported = NewError(errors.New("xpub is only supported by xpub wallets"))
+	// ErrSeedNotSupported is returned if a seed is provided for a wallet type that does not use one
+	ErrSeedNotSupported = NewError(errors.New("seed is not supported by this wallet type"))
+	// ErrWalletTypeNotEncryptable is returned when trying to encrypt a wallet type that has no secrets
+	ErrWalletTypeNotEncryptable = NewError(errors.New("wallet type is not encryptable"))
+	// ErrWalletCantSign is returned when trying to sign a transaction with a watch-only wallet
+	ErrWalletCantSign = NewError(errors.New("watch-only wallet can't sign transactions"))
+	// ErrWalletCantGenerateAddresses is returned when trying to generate addresses in a wallet that has no seed
+	ErrWalletCantGenerateAddresses = NewError(errors.New("wallet type can't generate addresses"))
+	// ErrFreshChangeEncrypted is returned if a fresh change address must be generated for an encrypted wallet, but no password was provided
+	ErrFreshChangeEncrypted = NewError(errors.New("wallet is encrypted, a fresh change address can't be generated without the password"))
+	// ErrInvalidSignerSocket is returned if the external signer of a wallet is not the absolute path of a unix socket
+	ErrInvalidSignerSocket = NewError(errors.New("external signer must be the absolute path of a unix socket"))
+	// ErrWalletNotCollection is returned if a wallet's type is not collection but it is necessary for the requested operation
+	ErrWalletNotCollection = NewError(errors.New("wallet type is not collection"))
+	// ErrInvalidSecKeyFormat is returned if a secret key string is neither hex encoded nor in the Bitcoin wallet import format
+	ErrInvalidSecKeyFormat = NewError(errors.New("invalid secret key, must be a hex string of length 64 or a Bitcoin wallet import format string"))
 )
 
 const (
@@ -92,8 +131,24 @@ const (
 
 	// WalletTypeDeterministic deterministic wallet type
 	WalletTypeDeterministic = "deterministic"
+	// WalletTypeBip44 bip44 hierarchical deterministic wallet type
+	WalletTypeBip44 = "bip44"
+	// WalletTypeXPub watch-only wallet type, with addresses derived from a bip32 extended public key
+	WalletTypeXPub = "xpub"
+	// WalletTypeCollection non-deterministic wallet type, holding a collection of imported secret keys
+	WalletTypeCollection = "collection"
 )
 
+// IsValidWalletType returns true if the wallet type is supported
+func IsValidWalletType(t string) bool {
+	switch t {
+	case WalletTypeDeterministic, WalletTypeBip44, WalletTypeXPub, WalletTypeCollection:
+		return true
+	default:
+		return false
+	}
+}
+
 // ResolveCoinType normalizes a coin type string to a CoinType constant
 func ResolveCoinType(s string) (CoinType, error) {
 	switch strings.ToLower(s) {
@@ -119,6 +174,19 @@ const (
 	metaSeed       = "seed"       // wallet seed
 	metaLastSeed   = "lastSeed"   // seed for generating next address
 	metaSecrets    = "secrets"    // secrets which records the encrypted seeds and secrets of address entries
+
+	metaSeedPassphrase = "seedPassphrase" // bip39 seed passphrase of a bip44 wallet
+	metaBip44Coin      = "bip44Coin"      // bip44 coin type of a bip44 wallet
+	metaBip44Account   = "bip44Account"   // bip44 account number of a bip44 wallet
+	metaXPub           = "xpub"           // bip32 extended public key of an xpub wallet
+	metaFrozenUxOuts   = "frozenUxOuts"   // comma separated hashes of the outputs that must not be spent
+	metaFreshChange    = "freshChange"    // whether transactions send their change to a fresh change address
+	metaSignerSocket   = "signerSocket"   // unix socket path of the external signer which signs the transactions
+	metaIssuedChange   = "issuedChange"   // comma separated change addresses given to transactions by FreshChangeAddress
+
+	metaArgon2idTime        = "argon2idTime"        // argon2id time parameter of an argon2id-chacha20poly1305 encrypted wallet
+	metaArgon2idMemory      = "argon2idMemory"      // argon2id memory parameter in KiB of an argon2id-chacha20poly1305 encrypted wallet
+	metaArgon2idParallelism = "argon2idParallelism" // argon2id parallelism parameter of an argon2id-chacha20poly1305 encrypted wallet
 )
 
 // CoinType represents the wallet coin type
@@ -134,14 +202,22 @@ func NewWalletFilename() string {
 
 // Options options that could be used when creating a wallet
 type Options struct {
-	Coin       CoinType   // coin type, skycoin, bitcoin, etc.
-	Label      string     // wallet label.
-	Seed       string     // wallet seed.
-	Encrypt    bool       // whether the wallet need to be encrypted.
-	Password   []byte     // password that would be used for encryption, and would only be used when 'Encrypt' is true.
-	CryptoType CryptoType // wallet encryption type, scrypt-chacha20poly1305 or sha256-xor.
-	ScanN      uint64     // number of addresses that're going to be scanned for a balance. The highest address with a balance will be used.
-	GenerateN  uint64     // number of addresses to generate, regardless of balance
+	Coin           CoinType        // coin type, skycoin, bitcoin, etc.
+	Type           string          // wallet type, deterministic or bip44. Defaults to deterministic.
+	Label          string          // wallet label.
+	Seed           string          // wallet seed.
+	SeedPassphrase string          // bip39 seed passphrase, only used by bip44 wallets.
+	Bip44Coin      *bip44.CoinType // bip44 coin type, only used by bip44 wallets. Defaults to the coin_type of Coin.
+	Bip44Account   uint32          // bip44 account number, only used by bip44 wallets.
+	XPub           string          // bip32 extended public key, only used by xpub wallets.
+	Encrypt        bool            // whether the wallet need to be encrypted.
+	Password       []byte          // password that would be used for encryption, and would only be used when 'Encrypt' is true.
+	CryptoType     CryptoType      // wallet encryption type, scrypt-chacha20poly1305, argon2id-chacha20poly1305 or sha256-xor.
+	Argon2idParams *Argon2idParams // argon2id parameters of the argon2id-chacha20poly1305 crypto type. Defaults to DefaultArgon2idParams.
+	ScanN          uint64          // number of addresses that're going to be scanned for a balance. The highest address with a balance will be used.
+	GenerateN      uint64          // number of addresses to generate, regardless of balance
+	FreshChange    bool            // whether transactions send their change to a fresh change address, not supported by collection wallets.
+	SignerSocket   string          // unix socket path of an external signer which signs the transactions instead of the wallet's secret keys.
 }
 
 // Wallet is consisted of meta and entries.
@@ -157,8 +233,17 @@ type Wallet struct {
 
 // newWallet creates a wallet instance with given name and options.
 func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error) {
-	if opts.Seed == "" {
-		return nil, ErrMissingSeed
+	walletType := opts.Type
+	if walletType == "" {
+		walletType = WalletTypeDeterministic
+	}
+
+	switch walletType {
+	case WalletTypeXPub, WalletTypeCollection:
+	default:
+		if opts.Seed == "" {
+			return nil, ErrMissingSeed
+		}
 	}
 
 	if opts.ScanN > 0 && bg == nil {
@@ -176,15 +261,22 @@ func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error)
 		return nil, fmt.Errorf("Invalid coin type %q", coin)
 	}
 
+	if !IsValidWalletType(walletType) {
+		return nil, ErrInvalidWalletType
+	}
+
+	if walletType != WalletTypeXPub && opts.XPub != "" {
+		return nil, ErrXPubNotSupported
+	}
+
 	w := &Wallet{
 		Meta: map[string]string{
 			metaFilename:   wltName,
 			metaVersion:    Version,
 			metaLabel:      opts.Label,
 			metaSeed:       opts.Seed,
-			metaLastSeed:   opts.Seed,
 			metaTimestamp:  strconv.FormatInt(time.Now().Unix(), 10),
-			metaType:       WalletTypeDeterministic,
+			metaType:       walletType,
 			metaCoin:       string(coin),
 			metaEncrypted:  "false",
 			metaCryptoType: "",
@@ -192,24 +284,107 @@ func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error)
 		},
 	}
 
-	// Create a default wallet
-	generateN := opts.GenerateN
-	if generateN == 0 {
-		generateN = 1
+	switch walletType {
+	case WalletTypeDeterministic:
+		if opts.SeedPassphrase != "" {
+			return nil, ErrSeedPassphraseNotSupported
+		}
+
+		w.setLastSeed(opts.Seed)
+
+	case WalletTypeBip44:
+		if err := bip39.ValidateMnemonic(opts.Seed); err != nil {
+			return nil, ErrInvalidBip44Seed
+		}
+
+		if opts.Bip44Account >= bip32.FirstHardenedChild {
+			return nil, NewError(bip44.ErrInvalidAccount)
+		}
+
+		var bip44Coin bip44.CoinType
+		if opts.Bip44Coin != nil {
+			bip44Coin = *opts.Bip44Coin
+		} else {
+			switch coin {
+			case CoinTypeSkycoin:
+				bip44Coin = bip44.CoinTypeSkycoin
+			case CoinTypeBitcoin:
+				bip44Coin = bip44.CoinTypeBitcoin
+			}
+		}
+
+		w.setSeedPassphrase(opts.SeedPassphrase)
+		w.setBip44Coin(bip44Coin)
+		w.setBip44Account(opts.Bip44Account)
+
+	case WalletTypeXPub:
+		if opts.Seed != "" {
+			return nil, ErrSeedNotSupported
+		}
+
+		if opts.SeedPassphrase != "" {
+			return nil, ErrSeedPassphraseNotSupported
+		}
+
+		if opts.XPub == "" {
+			return nil, ErrMissingXPub
+		}
+
+		if _, err := bip32.DeserializeEncodedPublicKey(opts.XPub); err != nil {
+			return nil, NewError(fmt.Errorf("invalid xpub: %v", err))
+		}
+
+		if opts.Encrypt {
+			return nil, ErrWalletTypeNotEncryptable
+		}
+
+		w.setXPub(opts.XPub)
+
+	case WalletTypeCollection:
+		if opts.Seed != "" {
+			return nil, ErrSeedNotSupported
+		}
+
+		if opts.SeedPassphrase != "" {
+			return nil, ErrSeedPassphraseNotSupported
+		}
+
+		if opts.FreshChange {
+			return nil, ErrWalletCantGenerateAddresses
+		}
 	}
-	if _, err := w.GenerateAddresses(generateN); err != nil {
-		return nil, err
+
+	if opts.FreshChange {
+		w.setFreshChange(true)
 	}
 
-	if opts.ScanN != 0 && coin != CoinTypeSkycoin {
-		return nil, errors.New("Wallet address scanning is not supported for Bitcoin wallets")
+	if opts.SignerSocket != "" {
+		if err := w.SetSignerSocket(opts.SignerSocket); err != nil {
+			return nil, err
+		}
 	}
 
-	if opts.ScanN > generateN {
-		// Scan for addresses with balances
-		if _, err := w.ScanAddresses(opts.ScanN, bg); err != nil {
+	// Collection wallets start empty, their entries are imported later
+	if walletType != WalletTypeCollection {
+		// Create a default wallet
+		generateN := opts.GenerateN
+		if generateN == 0 {
+			generateN = 1
+		}
+		if _, err := w.GenerateAddresses(generateN); err != nil {
 			return nil, err
 		}
+
+		if opts.ScanN != 0 && coin != CoinTypeSkycoin {
+			return nil, errors.New("Wallet address scanning is not supported for Bitcoin wallets")
+		}
+
+		if opts.ScanN > generateN {
+			// Scan for addresses with balances
+			if _, err := w.ScanAddresses(opts.ScanN, bg); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Checks if the wallet need to encrypt
@@ -231,7 +406,7 @@ func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error)
 	}
 
 	// Encrypt the wallet
-	if err := w.Lock(opts.Password, opts.CryptoType); err != nil {
+	if err := w.lock(opts.Password, opts.CryptoType, opts.Argon2idParams); err != nil {
 		return nil, err
 	}
 
@@ -253,8 +428,16 @@ func NewWalletScanAhead(wltName string, opts Options, bg BalanceGetter) (*Wallet
 	return newWallet(wltName, opts, bg)
 }
 
-// Lock encrypts the wallet with the given password and specific crypto type
+// Lock encrypts the wallet with the given password and specific crypto type.
+// The argon2id-chacha20poly1305 crypto type uses the argon2id parameters recorded in the wallet,
+// if it was unlocked from a wallet encrypted with them, otherwise DefaultArgon2idParams.
 func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
+	return w.lock(password, cryptoType, nil)
+}
+
+// lock encrypts the wallet with the given password and specific crypto type.
+// If argon2idParams is nil, the argon2id parameters are chosen as by Lock
+func (w *Wallet) lock(password []byte, cryptoType CryptoType, argon2idParams *Argon2idParams) error {
 	if len(password) == 0 {
 		return ErrMissingPassword
 	}
@@ -263,6 +446,10 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 		return ErrWalletEncrypted
 	}
 
+	if w.Type() == WalletTypeXPub {
+		return ErrWalletTypeNotEncryptable
+	}
+
 	wlt := w.clone()
 
 	// Records seeds in secrets
@@ -274,7 +461,13 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 	}()
 
 	ss.set(secretSeed, wlt.seed())
-	ss.set(secretLastSeed, wlt.lastSeed())
+
+	switch wlt.Type() {
+	case WalletTypeDeterministic:
+		ss.set(secretLastSeed, wlt.lastSeed())
+	case WalletTypeBip44:
+		ss.set(secretSeedPassphrase, wlt.seedPassphrase())
+	}
 
 	// Saves address's secret keys in secrets
 	for _, e := range wlt.Entries {
@@ -291,6 +484,29 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 		return err
 	}
 
+	// Records the argon2id parameters, which are only used by the argon2id crypto type
+	if cryptoType == CryptoTypeArgon2idChacha20poly1305 {
+		if argon2idParams == nil {
+			argon2idParams, err = wlt.argon2idParams()
+			if err != nil {
+				return err
+			}
+		}
+
+		if argon2idParams == nil {
+			argon2idParams = &DefaultArgon2idParams
+		}
+
+		if err := argon2idParams.Validate(); err != nil {
+			return err
+		}
+
+		crypto = argon2idParams.cryptor()
+		wlt.setArgon2idParams(*argon2idParams)
+	} else {
+		wlt.removeArgon2idParams()
+	}
+
 	// Encrypts the secrets
 	encSecret, err := crypto.Encrypt(sb, password)
 	if err != nil {
@@ -351,6 +567,14 @@ func (w *Wallet) Unlock(password []byte) (*Wallet, error) {
 		return nil, err
 	}
 
+	// Checks that the argon2id parameters recorded in the wallet are those the secrets were encrypted with,
+	// since they are used to encrypt the secrets again
+	if ct == CryptoTypeArgon2idChacha20poly1305 {
+		if err := wlt.checkArgon2idParams(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Decrypts the secrets
 	sb, err := crypto.Decrypt([]byte(sstr), password)
 	if err != nil {
@@ -370,11 +594,20 @@ func (w *Wallet) Unlock(password []byte) (*Wallet, error) {
 	}
 	wlt.setSeed(seed)
 
-	lastSeed, ok := ss.get(secretLastSeed)
-	if !ok {
-		return nil, errors.New("lastSeed doesn't exist in secrets")
+	switch wlt.Type() {
+	case WalletTypeDeterministic:
+		lastSeed, ok := ss.get(secretLastSeed)
+		if !ok {
+			return nil, errors.New("lastSeed doesn't exist in secrets")
+		}
+		wlt.setLastSeed(lastSeed)
+	case WalletTypeBip44:
+		seedPassphrase, ok := ss.get(secretSeedPassphrase)
+		if !ok {
+			return nil, errors.New("seed passphrase doesn't exist in secrets")
+		}
+		wlt.setSeedPassphrase(seedPassphrase)
 	}
-	wlt.setLastSeed(lastSeed)
 
 	// Gets addresses related secrets
 	for i, e := range wlt.Entries {
@@ -396,6 +629,48 @@ func (w *Wallet) Unlock(password []byte) (*Wallet, error) {
 	return wlt, nil
 }
 
+// ChangePassword re-encrypts the wallet's secrets with a new password.
+// If cryptoType is empty, the wallet's current crypto type is kept,
+// otherwise the secrets are re-encrypted with the given crypto type.
+// The wallet is left unchanged if the old password is wrong.
+func (w *Wallet) ChangePassword(oldPassword, newPassword []byte, cryptoType CryptoType) error {
+	return w.changePassword(oldPassword, newPassword, cryptoType, nil)
+}
+
+// changePassword re-encrypts the wallet's secrets with a new password, like ChangePassword.
+// If argon2idParams is nil, the argon2id parameters are chosen as by Lock
+func (w *Wallet) changePassword(oldPassword, newPassword []byte, cryptoType CryptoType, argon2idParams *Argon2idParams) error {
+	if !w.IsEncrypted() {
+		return ErrWalletNotEncrypted
+	}
+
+	if len(oldPassword) == 0 || len(newPassword) == 0 {
+		return ErrMissingPassword
+	}
+
+	if cryptoType == "" {
+		cryptoType = w.cryptoType()
+	}
+
+	if _, err := getCrypto(cryptoType); err != nil {
+		return ErrInvalidCryptoType
+	}
+
+	wlt, err := w.Unlock(oldPassword)
+	if err != nil {
+		return err
+	}
+
+	defer wlt.Erase()
+
+	if err := wlt.lock(newPassword, cryptoType, argon2idParams); err != nil {
+		return err
+	}
+
+	w.copyFrom(wlt)
+	return nil
+}
+
 // copyFrom copies the src wallet to w
 func (w *Wallet) copyFrom(src *Wallet) {
 	// Clear the original info first
@@ -417,6 +692,11 @@ func (w *Wallet) Erase() {
 	w.setSeed("")
 	w.setLastSeed("")
 
+	// Wipes the seed passphrase of bip44 wallets
+	if w.Type() == WalletTypeBip44 {
+		w.setSeedPassphrase("")
+	}
+
 	// Wipes private keys in entries
 	for i := range w.Entries {
 		for j := range w.Entries[i].Secret {
@@ -557,12 +837,6 @@ func filterDir(dir string, suffix string) ([]string, error) {
 	return res, nil
 }
 
-// reset resets the wallet entries and move the lastSeed to origin
-func (w *Wallet) reset() {
-	w.Entries = []Entry{}
-	w.setLastSeed(w.seed())
-}
-
 // Validate validates the wallet
 func (w *Wallet) Validate() error {
 	if fn := w.Meta[metaFilename]; fn == "" {
@@ -580,7 +854,7 @@ func (w *Wallet) Validate() error {
 	if !ok {
 		return errors.New("type field not set")
 	}
-	if walletType != WalletTypeDeterministic {
+	if !IsValidWalletType(walletType) {
 		return errors.New("wallet type invalid")
 	}
 
@@ -598,6 +872,49 @@ func (w *Wallet) Validate() error {
 		}
 	}
 
+	if s := w.Meta[metaFreshChange]; s != "" {
+		if _, err := strconv.ParseBool(s); err != nil {
+			return errors.New("freshChange field is not a valid bool")
+		}
+	}
+
+	if s := w.Meta[metaSignerSocket]; s != "" && !filepath.IsAbs(s) {
+		return errors.New("signerSocket field is not an absolute path")
+	}
+
+	if s := w.Meta[metaFrozenUxOuts]; s != "" {
+		for _, h := range strings.Split(s, ",") {
+			if _, err := cipher.SHA256FromHex(h); err != nil {
+				return errors.New("frozenUxOuts field is not a valid list of hashes")
+			}
+		}
+	}
+
+	if s := w.Meta[metaIssuedChange]; s != "" {
+		for _, a := range strings.Split(s, ",") {
+			if _, err := cipher.DecodeBase58Address(a); err != nil {
+				return errors.New("issuedChange field is not a valid list of addresses")
+			}
+		}
+	}
+
+	if walletType == WalletTypeXPub {
+		if isEncrypted {
+			return errors.New("xpub wallet can't be encrypted")
+		}
+
+		xpub := w.Meta[metaXPub]
+		if xpub == "" {
+			return errors.New("xpub field not set")
+		}
+
+		if _, err := bip32.DeserializeEncodedPublicKey(xpub); err != nil {
+			return fmt.Errorf("invalid xpub: %v", err)
+		}
+
+		return nil
+	}
+
 	// checks if the secrets field is empty
 	if isEncrypted {
 		cryptoType, ok := w.Meta[metaCryptoType]
@@ -609,16 +926,35 @@ func (w *Wallet) Validate() error {
 			return errors.New("unknown crypto type")
 		}
 
+		if CryptoType(cryptoType) == CryptoTypeArgon2idChacha20poly1305 {
+			if _, err := w.argon2idParams(); err != nil {
+				return err
+			}
+		}
+
 		if s := w.Meta[metaSecrets]; s == "" {
 			return errors.New("wallet is encrypted, but secrets field not set")
 		}
-	} else {
+	} else if walletType != WalletTypeCollection {
 		if s := w.Meta[metaSeed]; s == "" {
 			return errors.New("seed missing in unencrypted wallet")
 		}
 
-		if s := w.Meta[metaLastSeed]; s == "" {
-			return errors.New("lastSeed missing in unencrypted wallet")
+		if walletType == WalletTypeDeterministic {
+			if s := w.Meta[metaLastSeed]; s == "" {
+				return errors.New("lastSeed missing in unencrypted wallet")
+			}
+		}
+	}
+
+	if walletType == WalletTypeBip44 {
+		if _, err := strconv.ParseUint(w.Meta[metaBip44Coin], 10, 32); err != nil {
+			return errors.New("bip44Coin field is not a valid uint32")
+		}
+
+		account, err := strconv.ParseUint(w.Meta[metaBip44Account], 10, 32)
+		if err != nil || account >= uint64(bip32.FirstHardenedChild) {
+			return errors.New("bip44Account field is not a valid bip44 account number")
 		}
 	}
 
@@ -676,6 +1012,202 @@ func (w *Wallet) setSeed(seed string) {
 	w.Meta[metaSeed] = seed
 }
 
+func (w *Wallet) seedPassphrase() string {
+	return w.Meta[metaSeedPassphrase]
+}
+
+func (w *Wallet) setSeedPassphrase(p string) {
+	w.Meta[metaSeedPassphrase] = p
+}
+
+// Bip44Coin returns the bip44 coin type of a bip44 wallet
+func (w *Wallet) Bip44Coin() (bip44.CoinType, error) {
+	if w.Type() != WalletTypeBip44 {
+		return 0, ErrWalletNotBip44
+	}
+
+	x, err := strconv.ParseUint(w.Meta[metaBip44Coin], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return bip44.CoinType(x), nil
+}
+
+func (w *Wallet) setBip44Coin(ct bip44.CoinType) {
+	w.Meta[metaBip44Coin] = strconv.FormatUint(uint64(ct), 10)
+}
+
+// Bip44Account returns the bip44 account number of a bip44 wallet
+func (w *Wallet) Bip44Account() (uint32, error) {
+	if w.Type() != WalletTypeBip44 {
+		return 0, ErrWalletNotBip44
+	}
+
+	x, err := strconv.ParseUint(w.Meta[metaBip44Account], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(x), nil
+}
+
+func (w *Wallet) setBip44Account(account uint32) {
+	w.Meta[metaBip44Account] = strconv.FormatUint(uint64(account), 10)
+}
+
+// XPub returns the bip32 extended public key of an xpub wallet
+func (w *Wallet) XPub() string {
+	return w.Meta[metaXPub]
+}
+
+func (w *Wallet) setXPub(xpub string) {
+	w.Meta[metaXPub] = xpub
+}
+
+// FreshChange returns true if transactions created by the wallet send their change to a fresh change address
+func (w *Wallet) FreshChange() bool {
+	// Intentionally ignore the error, the value is validated by wallet.Validate()
+	b, _ := strconv.ParseBool(w.Meta[metaFreshChange]) // nolint: errcheck
+	return b
+}
+
+// SetFreshChange sets whether transactions created by the wallet send their change to a fresh change address.
+// Returns ErrWalletCantGenerateAddresses for collection wallets.
+func (w *Wallet) SetFreshChange(freshChange bool) error {
+	if freshChange && w.Type() == WalletTypeCollection {
+		return ErrWalletCantGenerateAddresses
+	}
+
+	w.setFreshChange(freshChange)
+	return nil
+}
+
+func (w *Wallet) setFreshChange(freshChange bool) {
+	if !freshChange {
+		delete(w.Meta, metaFreshChange)
+		return
+	}
+	w.Meta[metaFreshChange] = strconv.FormatBool(freshChange)
+}
+
+// SignerSocket returns the unix socket path of the external signer of the wallet,
+// or an empty string if the wallet signs with its own secret keys
+func (w *Wallet) SignerSocket() string {
+	return w.Meta[metaSignerSocket]
+}
+
+// SetSignerSocket sets the unix socket path of an external signer which signs the transactions of the wallet.
+// The wallet signs with its own secret keys if socketPath is empty.
+func (w *Wallet) SetSignerSocket(socketPath string) error {
+	if socketPath == "" {
+		delete(w.Meta, metaSignerSocket)
+		return nil
+	}
+
+	if !filepath.IsAbs(socketPath) {
+		return ErrInvalidSignerSocket
+	}
+
+	w.Meta[metaSignerSocket] = socketPath
+	return nil
+}
+
+// Signer returns the Signer which signs the transactions of the wallet.
+// Wallets with an external signer delegate signing to it, otherwise the wallet's secret keys are used,
+// which requires the wallet to be unlocked.
+func (w *Wallet) Signer() (Signer, error) {
+	if socketPath := w.SignerSocket(); socketPath != "" {
+		return NewExternalSigner(socketPath), nil
+	}
+
+	if w.IsEncrypted() {
+		return nil, ErrWalletEncrypted
+	}
+
+	if w.Type() == WalletTypeXPub {
+		return nil, ErrWalletCantSign
+	}
+
+	return newEntriesSigner(w.Entries), nil
+}
+
+// FrozenUxOuts returns the hashes of the outputs frozen in the wallet, sorted by hex string.
+// CreateTransaction does not spend frozen outputs, unless they are explicitly selected.
+func (w *Wallet) FrozenUxOuts() []cipher.SHA256 {
+	s := w.Meta[metaFrozenUxOuts]
+	if s == "" {
+		return nil
+	}
+
+	fields := strings.Split(s, ",")
+	hashes := make([]cipher.SHA256, 0, len(fields))
+	for _, f := range fields {
+		h, err := cipher.SHA256FromHex(f)
+		if err != nil {
+			// This can not happen, the meta.frozenUxOuts value is either set by
+			// setFrozenUxOuts() or checked by Validate()
+			logger.WithError(err).Warning("parse wallet.meta.frozenUxOuts hash failed")
+			continue
+		}
+		hashes = append(hashes, h)
+	}
+	return hashes
+}
+
+// IsUxOutFrozen returns true if the output is frozen in the wallet
+func (w *Wallet) IsUxOutFrozen(h cipher.SHA256) bool {
+	for _, f := range w.FrozenUxOuts() {
+		if f == h {
+			return true
+		}
+	}
+	return false
+}
+
+// FreezeUxOuts freezes outputs, so that CreateTransaction does not spend them
+// unless they are explicitly selected. Outputs that are already frozen are ignored.
+func (w *Wallet) FreezeUxOuts(hashes []cipher.SHA256) {
+	w.setFrozenUxOuts(append(w.FrozenUxOuts(), hashes...))
+}
+
+// UnfreezeUxOuts unfreezes outputs. Outputs that are not frozen are ignored.
+func (w *Wallet) UnfreezeUxOuts(hashes []cipher.SHA256) {
+	unfreeze := make(map[cipher.SHA256]struct{}, len(hashes))
+	for _, h := range hashes {
+		unfreeze[h] = struct{}{}
+	}
+
+	var frozen []cipher.SHA256
+	for _, h := range w.FrozenUxOuts() {
+		if _, ok := unfreeze[h]; !ok {
+			frozen = append(frozen, h)
+		}
+	}
+
+	w.setFrozenUxOuts(frozen)
+}
+
+// setFrozenUxOuts sets the frozen outputs, removing duplicates.
+// The meta field is deleted if there are no frozen outputs.
+func (w *Wallet) setFrozenUxOuts(hashes []cipher.SHA256) {
+	set := make(map[string]struct{}, len(hashes))
+	for _, h := range hashes {
+		set[h.Hex()] = struct{}{}
+	}
+
+	if len(set) == 0 {
+		delete(w.Meta, metaFrozenUxOuts)
+		return
+	}
+
+	hexes := make([]string, 0, len(set))
+	for h := range set {
+		hexes = append(hexes, h)
+	}
+	sort.Strings(hexes)
+
+	w.Meta[metaFrozenUxOuts] = strings.Join(hexes, ",")
+}
+
 func (w *Wallet) coin() CoinType {
 	return CoinType(w.Meta[metaCoin])
 }
@@ -726,6 +1258,81 @@ func (w *Wallet) cryptoType() CryptoType {
 	return CryptoType(w.Meta[metaCryptoType])
 }
 
+// argon2idParams returns the argon2id parameters recorded in the wallet, or nil if none are recorded
+func (w *Wallet) argon2idParams() (*Argon2idParams, error) {
+	ts, hasTime := w.Meta[metaArgon2idTime]
+	ms, hasMemory := w.Meta[metaArgon2idMemory]
+	ps, hasParallelism := w.Meta[metaArgon2idParallelism]
+	if !hasTime && !hasMemory && !hasParallelism {
+		return nil, nil
+	}
+
+	t, err := strconv.ParseUint(ts, 10, 32)
+	if err != nil {
+		return nil, errors.New("argon2idTime field is not a valid uint32")
+	}
+
+	m, err := strconv.ParseUint(ms, 10, 32)
+	if err != nil {
+		return nil, errors.New("argon2idMemory field is not a valid uint32")
+	}
+
+	p, err := strconv.ParseUint(ps, 10, 8)
+	if err != nil {
+		return nil, errors.New("argon2idParallelism field is not a valid uint8")
+	}
+
+	return &Argon2idParams{
+		Time:        uint32(t),
+		Memory:      uint32(m),
+		Parallelism: uint8(p),
+	}, nil
+}
+
+func (w *Wallet) setArgon2idParams(p Argon2idParams) {
+	w.Meta[metaArgon2idTime] = strconv.FormatUint(uint64(p.Time), 10)
+	w.Meta[metaArgon2idMemory] = strconv.FormatUint(uint64(p.Memory), 10)
+	w.Meta[metaArgon2idParallelism] = strconv.FormatUint(uint64(p.Parallelism), 10)
+}
+
+func (w *Wallet) removeArgon2idParams() {
+	delete(w.Meta, metaArgon2idTime)
+	delete(w.Meta, metaArgon2idMemory)
+	delete(w.Meta, metaArgon2idParallelism)
+}
+
+// checkArgon2idParams checks that the argon2id parameters recorded in an argon2id-chacha20poly1305 encrypted wallet
+// are those recorded in its encrypted secrets. Wallets encrypted before the parameters were recorded
+// in the wallet have them recorded from the encrypted secrets.
+func (w *Wallet) checkArgon2idParams() error {
+	c, err := encrypt.Argon2idChacha20poly1305Params([]byte(w.secrets()))
+	if err != nil {
+		return err
+	}
+
+	secretsParams := Argon2idParams{
+		Time:        c.Time,
+		Memory:      c.Memory,
+		Parallelism: c.Parallelism,
+	}
+
+	p, err := w.argon2idParams()
+	if err != nil {
+		return err
+	}
+
+	if p == nil {
+		w.setArgon2idParams(secretsParams)
+		return nil
+	}
+
+	if *p != secretsParams {
+		return errors.New("argon2id parameters of the wallet don't match its encrypted secrets")
+	}
+
+	return nil
+}
+
 func (w *Wallet) secrets() string {
 	return w.Meta[metaSecrets]
 }
@@ -756,6 +1363,15 @@ func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Addresser, error) {
 		return nil, ErrWalletEncrypted
 	}
 
+	switch w.Type() {
+	case WalletTypeBip44:
+		return w.generateBip44Addresses(bip44.ExternalChainIndex, num)
+	case WalletTypeXPub:
+		return w.generateXPubAddresses(num)
+	case WalletTypeCollection:
+		return nil, ErrWalletCantGenerateAddresses
+	}
+
 	var seckeys []cipher.SecKey
 	var seed []byte
 	if len(w.Entries) == 0 {
@@ -772,6 +1388,7 @@ func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Addresser, error) {
 
 	addrs := make([]cipher.Addresser, len(seckeys))
 	makeAddress := w.addressConstructor()
+	created := time.Now().Unix()
 	for i, s := range seckeys {
 		p := cipher.MustPubKeyFromSecKey(s)
 		a := makeAddress(p)
@@ -780,11 +1397,322 @@ func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Addresser, error) {
 			Address: a,
 			Secret:  s,
 			Public:  p,
+			Created: created,
+			Purpose: EntryPurposeReceive,
+		})
+	}
+	return addrs, nil
+}
+
+// GenerateChangeAddresses generates addresses on the change chain of a bip44 wallet
+func (w *Wallet) GenerateChangeAddresses(num uint64) ([]cipher.Addresser, error) {
+	if num == 0 {
+		return nil, nil
+	}
+
+	if w.IsEncrypted() {
+		return nil, ErrWalletEncrypted
+	}
+
+	if w.Type() != WalletTypeBip44 {
+		return nil, ErrWalletNotBip44
+	}
+
+	return w.generateBip44Addresses(bip44.ChangeChainIndex, num)
+}
+
+// NewChangeAddress generates an address for receiving the change of a transaction.
+// Bip44 wallets generate the address on the change chain, deterministic and xpub wallets
+// generate the next address and set its purpose to change.
+func (w *Wallet) NewChangeAddress() (cipher.Address, error) {
+	if w.coin() != CoinTypeSkycoin {
+		return cipher.Address{}, errors.New("Wallet coin type is not Skycoin")
+	}
+
+	var err error
+	if w.Type() == WalletTypeBip44 {
+		_, err = w.GenerateChangeAddresses(1)
+	} else {
+		_, err = w.GenerateAddresses(1)
+	}
+	if err != nil {
+		return cipher.Address{}, err
+	}
+
+	e := &w.Entries[len(w.Entries)-1]
+	e.Purpose = EntryPurposeChange
+
+	return e.SkycoinAddress(), nil
+}
+
+// UnusedChangeAddress returns the most recent change address of the wallet,
+// if it was not issued to a transaction by FreshChangeAddress and tf reports that it has no transaction history.
+// Returns false if the wallet has no change address, or if the most recent one was issued or used.
+func (w *Wallet) UnusedChangeAddress(tf TransactionsFinder) (cipher.Address, bool, error) {
+	if tf == nil {
+		return cipher.Address{}, false, ErrNilTransactionsFinder
+	}
+
+	if w.coin() != CoinTypeSkycoin {
+		return cipher.Address{}, false, errors.New("Wallet coin type is not Skycoin")
+	}
+
+	for i := len(w.Entries) - 1; i >= 0; i-- {
+		e := w.Entries[i]
+		if e.Purpose != EntryPurposeChange {
+			continue
+		}
+
+		a := e.SkycoinAddress()
+		if w.isChangeAddressIssued(a) {
+			return cipher.Address{}, false, nil
+		}
+
+		active, err := tf.AddressesActivity([]cipher.Address{a})
+		if err != nil {
+			return cipher.Address{}, false, err
+		}
+
+		return a, !active[0], nil
+	}
+
+	return cipher.Address{}, false, nil
+}
+
+// FreshChangeAddress returns a change address with no transaction history for a new transaction,
+// and records it as issued so that it is not given to another transaction, whether or not
+// the transaction is broadcast. The most recent change address is reused if UnusedChangeAddress returns it,
+// otherwise a new change address is generated. The password is required to generate an address
+// for an encrypted wallet. The wallet must be saved afterwards to persist the issued address.
+func (w *Wallet) FreshChangeAddress(password []byte, tf TransactionsFinder) (cipher.Address, error) {
+	addr, ok, err := w.UnusedChangeAddress(tf)
+	if err != nil {
+		return cipher.Address{}, err
+	}
+
+	if !ok {
+		if !w.IsEncrypted() {
+			addr, err = w.NewChangeAddress()
+		} else if len(password) == 0 {
+			return cipher.Address{}, ErrFreshChangeEncrypted
+		} else {
+			err = w.GuardUpdate(password, func(w *Wallet) error {
+				var err error
+				addr, err = w.NewChangeAddress()
+				return err
+			})
+		}
+		if err != nil {
+			return cipher.Address{}, err
+		}
+	}
+
+	w.issueChangeAddress(addr)
+
+	return addr, nil
+}
+
+// IssuedChangeAddresses returns the change addresses issued to transactions by FreshChangeAddress
+func (w *Wallet) IssuedChangeAddresses() []cipher.Address {
+	s := w.Meta[metaIssuedChange]
+	if s == "" {
+		return nil
+	}
+
+	fields := strings.Split(s, ",")
+	addrs := make([]cipher.Address, 0, len(fields))
+	for _, f := range fields {
+		a, err := cipher.DecodeBase58Address(f)
+		if err != nil {
+			// This can not happen, the meta.issuedChange value is either set by
+			// issueChangeAddress() or checked by Validate()
+			logger.WithError(err).Warning("parse wallet.meta.issuedChange address failed")
+			continue
+		}
+		addrs = append(addrs, a)
+	}
+	return addrs
+}
+
+func (w *Wallet) isChangeAddressIssued(addr cipher.Address) bool {
+	for _, a := range w.IssuedChangeAddresses() {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Wallet) issueChangeAddress(addr cipher.Address) {
+	if w.isChangeAddressIssued(addr) {
+		return
+	}
+
+	if s := w.Meta[metaIssuedChange]; s != "" {
+		w.Meta[metaIssuedChange] = s + "," + addr.String()
+	} else {
+		w.Meta[metaIssuedChange] = addr.String()
+	}
+}
+
+// generateBip44Addresses generates addresses on the given chain of a bip44 wallet,
+// continuing from the highest child number already in the wallet for that chain
+func (w *Wallet) generateBip44Addresses(chain uint32, num uint64) ([]cipher.Addresser, error) {
+	chainKey, err := w.bip44ChainKey(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	var childNumber uint32
+	for _, e := range w.Entries {
+		if e.Change == chain && e.ChildNumber+1 > childNumber {
+			childNumber = e.ChildNumber + 1
+		}
+	}
+
+	purpose := EntryPurposeReceive
+	if chain == bip44.ChangeChainIndex {
+		purpose = EntryPurposeChange
+	}
+
+	addrs := make([]cipher.Addresser, 0, num)
+	makeAddress := w.addressConstructor()
+	created := time.Now().Unix()
+	for uint64(len(addrs)) < num {
+		if childNumber >= bip32.FirstHardenedChild {
+			return nil, errors.New("bip44 chain has no more non-hardened child keys")
+		}
+
+		k, err := chainKey.NewPrivateChildKey(childNumber)
+		if err != nil {
+			// Skip the child number if it derives an invalid key, as required by bip32
+			if bip32.IsImpossibleChildError(err) {
+				childNumber++
+				continue
+			}
+			return nil, err
+		}
+
+		s, err := cipher.NewSecKey(k.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := cipher.PubKeyFromSecKey(s)
+		if err != nil {
+			return nil, err
+		}
+
+		a := makeAddress(p)
+		addrs = append(addrs, a)
+		w.Entries = append(w.Entries, Entry{
+			Address:     a,
+			Secret:      s,
+			Public:      p,
+			ChildNumber: childNumber,
+			Change:      chain,
+			Created:     created,
+			Purpose:     purpose,
+		})
+
+		childNumber++
+	}
+
+	return addrs, nil
+}
+
+// generateXPubAddresses generates addresses from the child public keys of an xpub wallet's
+// extended public key, continuing from the highest child number already in the wallet
+func (w *Wallet) generateXPubAddresses(num uint64) ([]cipher.Addresser, error) {
+	xpub, err := bip32.DeserializeEncodedPublicKey(w.XPub())
+	if err != nil {
+		return nil, err
+	}
+
+	var childNumber uint32
+	for _, e := range w.Entries {
+		if e.ChildNumber+1 > childNumber {
+			childNumber = e.ChildNumber + 1
+		}
+	}
+
+	addrs := make([]cipher.Addresser, 0, num)
+	makeAddress := w.addressConstructor()
+	created := time.Now().Unix()
+	for uint64(len(addrs)) < num {
+		if childNumber >= bip32.FirstHardenedChild {
+			return nil, errors.New("xpub has no more non-hardened child keys")
+		}
+
+		k, err := xpub.NewPublicChildKey(childNumber)
+		if err != nil {
+			// Skip the child number if it derives an invalid key, as required by bip32
+			if bip32.IsImpossibleChildError(err) {
+				childNumber++
+				continue
+			}
+			return nil, err
+		}
+
+		p, err := cipher.NewPubKey(k.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		a := makeAddress(p)
+		addrs = append(addrs, a)
+		w.Entries = append(w.Entries, Entry{
+			Address:     a,
+			Public:      p,
+			ChildNumber: childNumber,
+			Created:     created,
+			Purpose:     EntryPurposeReceive,
 		})
+
+		childNumber++
 	}
+
 	return addrs, nil
 }
 
+// bip44ChainKey derives the bip32 key of a chain in the wallet's bip44 account,
+// m/44'/coin_type'/account'/chain
+func (w *Wallet) bip44ChainKey(chain uint32) (*bip32.PrivateKey, error) {
+	bip44Coin, err := w.Bip44Coin()
+	if err != nil {
+		return nil, err
+	}
+
+	accountNum, err := w.Bip44Account()
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := bip39.NewSeed(w.seed(), w.seedPassphrase())
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := bip44.NewCoin(seed, bip44Coin)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := c.Account(accountNum)
+	if err != nil {
+		return nil, err
+	}
+
+	switch chain {
+	case bip44.ExternalChainIndex:
+		return account.External()
+	case bip44.ChangeChainIndex:
+		return account.Change()
+	default:
+		return nil, fmt.Errorf("invalid bip44 chain %d", chain)
+	}
+}
+
 // GenerateSkycoinAddresses generates Skycoin addresses. If the wallet's coin type is not Skycoin, returns an error
 func (w *Wallet) GenerateSkycoinAddresses(num uint64) ([]cipher.Address, error) {
 	if w.coin() != CoinTypeSkycoin {
@@ -818,7 +1746,6 @@ func (w *Wallet) ScanAddresses(scanN uint64, bg BalanceGetter) (uint64, error) {
 
 	w2 := w.clone()
 
-	nExistingAddrs := uint64(len(w2.Entries))
 	nAddAddrs := uint64(0)
 	n := scanN
 	extraScan := uint64(0)
@@ -859,18 +1786,141 @@ func (w *Wallet) ScanAddresses(scanN uint64, bg BalanceGetter) (uint64, error) {
 		n = scanN - extraScan
 	}
 
-	// Regenerate addresses up to nExistingAddrs + nAddAddrss.
-	// This is necessary to keep the lastSeed updated.
-	w2.reset()
-	if _, err := w2.GenerateSkycoinAddresses(nExistingAddrs + nAddAddrs); err != nil {
+	// Generate the kept addresses on a fresh copy of the wallet, discarding
+	// the extra scanned addresses. This is necessary to keep the lastSeed
+	// of deterministic wallets and the change chain entries of bip44 wallets intact.
+	w3 := w.clone()
+	if _, err := w3.GenerateSkycoinAddresses(nAddAddrs); err != nil {
 		return 0, err
 	}
 
-	*w = *w2
+	*w = *w3
 
 	return nAddAddrs, nil
 }
 
+// DefaultGapLimit is the default number of consecutive addresses without transaction history
+// after which an address history scan stops, as recommended by BIP44
+const DefaultGapLimit = 20
+
+// ScanProgress reports the progress of an address history scan
+type ScanProgress struct {
+	Chain   uint32 // bip44 chain being scanned, always 0 for other wallet types
+	Scanned uint64 // number of addresses checked on the chain so far
+	Found   uint64 // number of addresses that will be added to the chain, up to the last address with history
+}
+
+// ScanProgressFunc is called after each batch of addresses checked by an address history scan
+type ScanProgressFunc func(ScanProgress)
+
+// ScanAddressHistory generates addresses until gapLimit consecutive addresses have no transaction history,
+// keeping the addresses up to the last one with history. Unlike ScanAddresses, addresses that received coins
+// and later spent them all are found. Bip44 wallets scan their external and change chains.
+// The progress function is optional. Returns the number of addresses added to the wallet.
+func (w *Wallet) ScanAddressHistory(gapLimit uint64, tf TransactionsFinder, progress ScanProgressFunc) (uint64, error) {
+	if w.IsEncrypted() {
+		return 0, ErrWalletEncrypted
+	}
+
+	if gapLimit == 0 {
+		return 0, nil
+	}
+
+	if tf == nil {
+		return 0, ErrNilTransactionsFinder
+	}
+
+	if w.coin() != CoinTypeSkycoin {
+		return 0, errors.New("Wallet address scanning is not supported for Bitcoin wallets")
+	}
+
+	if w.Type() == WalletTypeCollection {
+		return 0, ErrWalletCantGenerateAddresses
+	}
+
+	chains := []uint32{bip44.ExternalChainIndex}
+	if w.Type() == WalletTypeBip44 {
+		chains = append(chains, bip44.ChangeChainIndex)
+	}
+
+	// Generate the kept addresses on a fresh copy of the wallet, discarding
+	// the extra scanned addresses, as ScanAddresses does
+	w3 := w.clone()
+	var nAddAddrs uint64
+	for _, chain := range chains {
+		n, err := w.scanChainHistory(chain, gapLimit, tf, progress)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := w3.generateChainAddresses(chain, n); err != nil {
+			return 0, err
+		}
+
+		nAddAddrs += n
+	}
+
+	*w = *w3
+
+	return nAddAddrs, nil
+}
+
+// scanChainHistory returns the number of addresses to add to a chain of the wallet,
+// up to the last address with history before gapLimit consecutive addresses without history
+func (w *Wallet) scanChainHistory(chain uint32, gapLimit uint64, tf TransactionsFinder, progress ScanProgressFunc) (uint64, error) {
+	w2 := w.clone()
+
+	var scanned, found uint64
+	for scanned-found < gapLimit {
+		n := gapLimit - (scanned - found)
+		addrs, err := w2.generateChainAddresses(chain, n)
+		if err != nil {
+			return 0, err
+		}
+
+		skyAddrs := make([]cipher.Address, len(addrs))
+		for i, a := range addrs {
+			skyAddrs[i] = a.(cipher.Address)
+		}
+
+		active, err := tf.AddressesActivity(skyAddrs)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(active) != len(skyAddrs) {
+			return 0, errors.New("AddressesActivity returned a result of the wrong length")
+		}
+
+		for i, ok := range active {
+			if ok {
+				found = scanned + uint64(i) + 1
+			}
+		}
+
+		scanned += n
+
+		if progress != nil {
+			progress(ScanProgress{
+				Chain:   chain,
+				Scanned: scanned,
+				Found:   found,
+			})
+		}
+	}
+
+	return found, nil
+}
+
+// generateChainAddresses generates addresses on the change chain of bip44 wallets,
+// or the default address chain otherwise
+func (w *Wallet) generateChainAddresses(chain uint32, num uint64) ([]cipher.Addresser, error) {
+	if chain == bip44.ChangeChainIndex {
+		return w.GenerateChangeAddresses(num)
+	}
+	return w.GenerateAddresses(num)
+}
+
 // GetAddresses returns all addresses in wallet
 func (w *Wallet) GetAddresses() []cipher.Addresser {
 	addrs := make([]cipher.Addresser, len(w.Entries))
@@ -903,6 +1953,23 @@ func (w *Wallet) GetEntry(a cipher.Address) (Entry, bool) {
 	return Entry{}, false
 }
 
+// UpdateEntryMeta sets the label, purpose and tags of the entry of the given address.
+// Returns ErrUnknownAddress if the address is not in the wallet.
+func (w *Wallet) UpdateEntryMeta(a cipher.Addresser, u EntryMetaUpdate) error {
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	for i := range w.Entries {
+		if w.Entries[i].Address == a {
+			u.apply(&w.Entries[i])
+			return nil
+		}
+	}
+
+	return ErrUnknownAddress
+}
+
 // HasEntry returns true if the wallet has an Entry with a given cipher.Address.
 func (w *Wallet) HasEntry(a cipher.Address) bool {
 	// This doesn't use GetEntry() to avoid copying an Entry in the return value,
@@ -939,5 +2006,12 @@ func (w *Wallet) clone() *Wallet {
 
 	wlt.Entries = append(wlt.Entries, w.Entries...)
 
+	// Copies the tags, so that the clone does not share them with w
+	for i, e := range wlt.Entries {
+		if e.Tags != nil {
+			wlt.Entries[i].Tags = append([]string{}, e.Tags...)
+		}
+	}
+
 	return &wlt
 }
@@ -1,19 +1,26 @@
 package wallet
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip32"
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/cipher/bip44"
 	"github.com/skycoin/skycoin/src/cipher/encrypt"
+	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/logging"
 )
 
@@ -42,9 +49,23 @@ func init() {
 		KeyLen: encrypt.ScryptKeyLen,
 	}
 
+	// Lower the argon2id memory and time parameters for the same reason
+	cryptoTable[CryptoTypeArgon2idChacha20poly1305] = encrypt.Argon2idChacha20poly1305{
+		Time:        1,
+		Memory:      1024,
+		Parallelism: encrypt.Argon2idParallelism,
+		KeyLen:      encrypt.Argon2idKeyLen,
+	}
+	DefaultArgon2idParams = Argon2idParams{
+		Time:        1,
+		Memory:      1024,
+		Parallelism: encrypt.Argon2idParallelism,
+	}
+
 	// When -u flag is specified, update the following wallet files:
 	//     - ./testdata/scrypt-chacha20poly1305-encrypted.wlt
 	//     - ./testdata/sha256xor-encrypted.wlt
+	//     - ./testdata/argon2id-chacha20poly1305-encrypted.wlt
 	if *u {
 		// Update ./testdata/scrypt-chacha20poly1305-encrypted.wlt
 		//     - Create an unencrypted wallet
@@ -86,9 +107,31 @@ func init() {
 		if err := w1.Save("./testdata"); err != nil {
 			log.Panic(err)
 		}
+
+		// Update ./testdata/argon2id-chacha20poly1305-encrypted.wlt
+		//     - Create an argon2id-chacha20poly1305 encrypted wallet with password: "pwd".
+		w2, err := NewWallet("argon2id-chacha20poly1305-encrypted.wlt", Options{
+			Seed:       "seed-argon2id-chacha20poly1305",
+			Label:      "argon2id-chacha20poly1305",
+			Encrypt:    true,
+			Password:   []byte("pwd"),
+			CryptoType: CryptoTypeArgon2idChacha20poly1305,
+		})
+		if err != nil {
+			log.Panic(err)
+		}
+
+		if err := w2.Save("./testdata"); err != nil {
+			log.Panic(err)
+		}
 	}
 }
 
+const testBip44Seed = "motor cross wrap intact soup critic club allow track come dizzy cool"
+
+// testXPub is the account 0 xpub of the bip39 "abandon ... about" test mnemonic, m/44'/0'/0'
+const testXPub = "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj"
+
 type mockBalanceGetter map[cipher.Address]BalancePair
 
 func (mb mockBalanceGetter) GetBalanceOfAddrs(addrs []cipher.Address) ([]BalancePair, error) {
@@ -100,6 +143,16 @@ func (mb mockBalanceGetter) GetBalanceOfAddrs(addrs []cipher.Address) ([]Balance
 	return bals, nil
 }
 
+type mockTransactionsFinder map[cipher.Address]bool
+
+func (mt mockTransactionsFinder) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	active := make([]bool, len(addrs))
+	for i, addr := range addrs {
+		active[i] = mt[addr]
+	}
+	return active, nil
+}
+
 func TestNewWallet(t *testing.T) {
 	type expect struct {
 		meta map[string]string
@@ -240,6 +293,155 @@ func TestNewWallet(t *testing.T) {
 				err: ErrMissingEncrypt,
 			},
 		},
+		{
+			"invalid wallet type",
+			"test.wlt",
+			Options{
+				Type: "foo",
+				Seed: "testseed123",
+			},
+			expect{
+				err: ErrInvalidWalletType,
+			},
+		},
+		{
+			"seed passphrase for deterministic wallet",
+			"test.wlt",
+			Options{
+				Seed:           "testseed123",
+				SeedPassphrase: "foo",
+			},
+			expect{
+				err: ErrSeedPassphraseNotSupported,
+			},
+		},
+		{
+			"ok bip44",
+			"test.wlt",
+			Options{
+				Type:           WalletTypeBip44,
+				Seed:           testBip44Seed,
+				SeedPassphrase: "foo",
+			},
+			expect{
+				err: nil,
+			},
+		},
+		{
+			"ok bip44 encrypted",
+			"test.wlt",
+			Options{
+				Type:           WalletTypeBip44,
+				Seed:           testBip44Seed,
+				SeedPassphrase: "foo",
+				Encrypt:        true,
+				Password:       []byte("pwd"),
+			},
+			expect{
+				err: nil,
+			},
+		},
+		{
+			"bip44 seed not a bip39 mnemonic",
+			"test.wlt",
+			Options{
+				Type: WalletTypeBip44,
+				Seed: "testseed123",
+			},
+			expect{
+				err: ErrInvalidBip44Seed,
+			},
+		},
+		{
+			"ok xpub",
+			"test.wlt",
+			Options{
+				Type: WalletTypeXPub,
+				XPub: testXPub,
+			},
+			expect{
+				err: nil,
+			},
+		},
+		{
+			"xpub missing xpub",
+			"test.wlt",
+			Options{
+				Type: WalletTypeXPub,
+			},
+			expect{
+				err: ErrMissingXPub,
+			},
+		},
+		{
+			"xpub with seed",
+			"test.wlt",
+			Options{
+				Type: WalletTypeXPub,
+				XPub: testXPub,
+				Seed: "testseed123",
+			},
+			expect{
+				err: ErrSeedNotSupported,
+			},
+		},
+		{
+			"xpub encrypted",
+			"test.wlt",
+			Options{
+				Type:     WalletTypeXPub,
+				XPub:     testXPub,
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			expect{
+				err: ErrWalletTypeNotEncryptable,
+			},
+		},
+		{
+			"ok collection",
+			"test.wlt",
+			Options{
+				Type: WalletTypeCollection,
+			},
+			expect{
+				err: nil,
+			},
+		},
+		{
+			"ok collection encrypted",
+			"test.wlt",
+			Options{
+				Type:     WalletTypeCollection,
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			expect{
+				err: nil,
+			},
+		},
+		{
+			"collection with seed",
+			"test.wlt",
+			Options{
+				Type: WalletTypeCollection,
+				Seed: "testseed123",
+			},
+			expect{
+				err: ErrSeedNotSupported,
+			},
+		},
+		{
+			"xpub for deterministic wallet",
+			"test.wlt",
+			Options{
+				Seed: "testseed123",
+				XPub: testXPub,
+			},
+			expect{
+				err: ErrXPubNotSupported,
+			},
+		},
 	}
 
 	for _, tc := range tt {
@@ -262,6 +464,7 @@ func TestNewWallet(t *testing.T) {
 					// Confirms the seeds and entry secrets are all empty
 					require.Equal(t, "", w.seed())
 					require.Equal(t, "", w.lastSeed())
+					require.Equal(t, "", w.seedPassphrase())
 
 					for _, e := range w.Entries {
 						require.True(t, e.Secret.Null())
@@ -460,11 +663,279 @@ func TestLockAndUnLock(t *testing.T) {
 			ucw, err := cw.Unlock([]byte("pwd"))
 			require.NoError(t, err)
 
+			// The argon2id parameters are kept in the unlocked wallet
+			if ct == CryptoTypeArgon2idChacha20poly1305 {
+				p, err := ucw.argon2idParams()
+				require.NoError(t, err)
+				require.Equal(t, &DefaultArgon2idParams, p)
+				ucw.removeArgon2idParams()
+			}
+
 			require.Equal(t, w, ucw)
 		})
 	}
 }
 
+func TestUnlockArgon2idWallet(t *testing.T) {
+	// The testdata wallet was encrypted with the default argon2id parameters,
+	// which differ from those of cryptoTable in tests. The wallet can still
+	// be decrypted because the parameters are recorded in its secrets.
+	w, err := Load("./testdata/argon2id-chacha20poly1305-encrypted.wlt")
+	require.NoError(t, err)
+	require.Equal(t, CryptoTypeArgon2idChacha20poly1305, w.cryptoType())
+
+	_, err = w.Unlock([]byte("wrong pwd"))
+	require.Equal(t, ErrInvalidPassword, err)
+
+	uw, err := w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+	require.Equal(t, "seed-argon2id-chacha20poly1305", uw.seed())
+
+	_, sks := cipher.MustGenerateDeterministicKeyPairsSeed([]byte(uw.seed()), 1)
+	require.Equal(t, sks[0], uw.Entries[0].Secret)
+
+	// The parameters are recorded in the wallet from its secrets
+	p, err := uw.argon2idParams()
+	require.NoError(t, err)
+	require.Equal(t, &Argon2idParams{
+		Time:        encrypt.Argon2idTime,
+		Memory:      encrypt.Argon2idMemory,
+		Parallelism: encrypt.Argon2idParallelism,
+	}, p)
+}
+
+func TestWalletArgon2idParams(t *testing.T) {
+	params := Argon2idParams{
+		Time:        2,
+		Memory:      2048,
+		Parallelism: 2,
+	}
+
+	w, err := NewWallet("t.wlt", Options{
+		Seed:           "seed",
+		Encrypt:        true,
+		Password:       []byte("pwd"),
+		CryptoType:     CryptoTypeArgon2idChacha20poly1305,
+		Argon2idParams: &params,
+	})
+	require.NoError(t, err)
+
+	requireParams := func(t *testing.T, w *Wallet) {
+		p, err := w.argon2idParams()
+		require.NoError(t, err)
+		require.Equal(t, &params, p)
+
+		c, err := encrypt.Argon2idChacha20poly1305Params([]byte(w.secrets()))
+		require.NoError(t, err)
+		require.Equal(t, params.cryptor(), c)
+	}
+
+	// The parameters are recorded in the meta and in the secrets
+	requireParams(t, w)
+	require.Equal(t, "2", w.Meta[metaArgon2idTime])
+	require.Equal(t, "2048", w.Meta[metaArgon2idMemory])
+	require.Equal(t, "2", w.Meta[metaArgon2idParallelism])
+
+	// The parameters survive saving and loading the wallet
+	dir := prepareWltDir()
+	require.NoError(t, w.Save(dir))
+	w, err = Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	requireParams(t, w)
+
+	// The parameters are read back when unlocking, and kept when the wallet is locked again
+	require.NoError(t, w.GuardUpdate([]byte("pwd"), func(w *Wallet) error {
+		p, err := w.argon2idParams()
+		require.NoError(t, err)
+		require.Equal(t, &params, p)
+		_, err = w.GenerateAddresses(1)
+		return err
+	}))
+	requireParams(t, w)
+
+	// The parameters are kept when changing the password
+	require.NoError(t, w.ChangePassword([]byte("pwd"), []byte("pwd2"), ""))
+	requireParams(t, w)
+
+	uw, err := w.Unlock([]byte("pwd2"))
+	require.NoError(t, err)
+	require.Equal(t, "seed", uw.seed())
+
+	// Parameters which don't match the secrets are rejected
+	w2 := w.clone()
+	w2.Meta[metaArgon2idMemory] = "1024"
+	_, err = w2.Unlock([]byte("pwd2"))
+	require.EqualError(t, err, "argon2id parameters of the wallet don't match its encrypted secrets")
+
+	// Malformed parameters are rejected
+	w2.Meta[metaArgon2idMemory] = "x"
+	require.EqualError(t, w2.Validate(), "argon2idMemory field is not a valid uint32")
+
+	// The parameters are removed when changing to another crypto type
+	require.NoError(t, w.ChangePassword([]byte("pwd2"), []byte("pwd2"), CryptoTypeScryptChacha20poly1305))
+	p, err := w.argon2idParams()
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	// Invalid parameters are rejected
+	_, err = NewWallet("t.wlt", Options{
+		Seed:       "seed",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: CryptoTypeArgon2idChacha20poly1305,
+		Argon2idParams: &Argon2idParams{
+			Time:        1,
+			Memory:      8,
+			Parallelism: 2,
+		},
+	})
+	require.Equal(t, NewError(errors.New("invalid argon2id parameters: argon2id memory must be at least 8*parallelism KiB")), err)
+}
+
+func TestWalletChangePassword(t *testing.T) {
+	tt := []struct {
+		name        string
+		opts        Options
+		oldPwd      []byte
+		newPwd      []byte
+		cryptoType  CryptoType
+		expectCrypt CryptoType
+		err         error
+	}{
+		{
+			name: "ok",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeScryptChacha20poly1305Insecure,
+			},
+			oldPwd:      []byte("pwd"),
+			newPwd:      []byte("new pwd"),
+			expectCrypt: CryptoTypeScryptChacha20poly1305Insecure,
+		},
+		{
+			name: "migrate sha256-xor to scrypt-chacha20poly1305",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPwd:      []byte("pwd"),
+			newPwd:      []byte("new pwd"),
+			cryptoType:  CryptoTypeScryptChacha20poly1305Insecure,
+			expectCrypt: CryptoTypeScryptChacha20poly1305Insecure,
+		},
+		{
+			name: "same password, new crypto type",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPwd:      []byte("pwd"),
+			newPwd:      []byte("pwd"),
+			cryptoType:  CryptoTypeScryptChacha20poly1305Insecure,
+			expectCrypt: CryptoTypeScryptChacha20poly1305Insecure,
+		},
+		{
+			name: "wallet not encrypted",
+			opts: Options{
+				Seed: "seed",
+			},
+			oldPwd: []byte("pwd"),
+			newPwd: []byte("new pwd"),
+			err:    ErrWalletNotEncrypted,
+		},
+		{
+			name: "missing old password",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			newPwd: []byte("new pwd"),
+			err:    ErrMissingPassword,
+		},
+		{
+			name: "missing new password",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPwd: []byte("pwd"),
+			err:    ErrMissingPassword,
+		},
+		{
+			name: "invalid password",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPwd: []byte("wrong pwd"),
+			newPwd: []byte("new pwd"),
+			err:    ErrInvalidPassword,
+		},
+		{
+			name: "invalid crypto type",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPwd:     []byte("pwd"),
+			newPwd:     []byte("new pwd"),
+			cryptoType: "foo",
+			err:        ErrInvalidCryptoType,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w := makeWallet(t, tc.opts, 3)
+			cw := w.clone()
+
+			err := w.ChangePassword(tc.oldPwd, tc.newPwd, tc.cryptoType)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				// The wallet is not modified
+				require.Equal(t, cw, w)
+				return
+			}
+
+			require.True(t, w.IsEncrypted())
+			require.Equal(t, tc.expectCrypt, w.cryptoType())
+			require.Empty(t, w.seed())
+			require.Empty(t, w.lastSeed())
+			for _, e := range w.Entries {
+				require.Equal(t, cipher.SecKey{}, e.Secret)
+			}
+
+			_, err = w.Unlock(tc.oldPwd)
+			if !bytes.Equal(tc.oldPwd, tc.newPwd) {
+				require.Equal(t, ErrInvalidPassword, err)
+			}
+
+			wlt, err := w.Unlock(tc.newPwd)
+			require.NoError(t, err)
+			require.Equal(t, tc.opts.Seed, wlt.seed())
+
+			ucw, err := cw.Unlock(tc.oldPwd)
+			require.NoError(t, err)
+			require.Equal(t, ucw.Entries, wlt.Entries)
+			require.Equal(t, ucw.lastSeed(), wlt.lastSeed())
+		})
+	}
+}
+
 func makeWallet(t *testing.T, opts Options, addrNum uint64) *Wallet { // nolint: unparam
 	// Create an unlocked wallet, then generate addresses, lock if the options.Encrypt is true.
 	preOpts := opts
@@ -588,6 +1059,24 @@ func TestLoadWallet(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			"version=0.2 encrypted=true crypto=argon2id-chacha20poly1305",
+			"./testdata/argon2id-chacha20poly1305-encrypted.wlt",
+			expect{
+				meta: map[string]string{
+					"coin":       string(CoinTypeSkycoin),
+					"cryptoType": "argon2id-chacha20poly1305",
+					"encrypted":  "true",
+					"filename":   "argon2id-chacha20poly1305-encrypted.wlt",
+					"label":      "argon2id-chacha20poly1305",
+					"lastSeed":   "",
+					"seed":       "",
+					"type":       WalletTypeDeterministic,
+					"version":    "0.2",
+				},
+				err: nil,
+			},
+		},
 		{
 			"version=0.2 encrypted=false",
 			"./testdata/v2_no_encrypt.wlt",
@@ -721,6 +1210,342 @@ func TestWalletGenerateAddress(t *testing.T) {
 	}
 }
 
+func TestBip44WalletGenerateAddresses(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Type:           WalletTypeBip44,
+		Seed:           testBip44Seed,
+		SeedPassphrase: "foo",
+		Bip44Account:   2,
+		GenerateN:      3,
+	})
+	require.NoError(t, err)
+
+	changeAddrs, err := w.GenerateChangeAddresses(2)
+	require.NoError(t, err)
+	require.Len(t, changeAddrs, 2)
+
+	addrs, err := w.GenerateAddresses(1)
+	require.NoError(t, err)
+	require.Len(t, addrs, 1)
+
+	require.Len(t, w.Entries, 6)
+
+	// Entries must match the keys derived from the bip44 path
+	seed, err := bip39.NewSeed(testBip44Seed, "foo")
+	require.NoError(t, err)
+
+	expect := []struct {
+		change      uint32
+		childNumber uint32
+	}{
+		{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {0, 3},
+	}
+
+	for i, x := range expect {
+		e := w.Entries[i]
+		require.Equal(t, x.change, e.Change)
+		require.Equal(t, x.childNumber, e.ChildNumber)
+		require.NotZero(t, e.Created)
+		if x.change == bip44.ChangeChainIndex {
+			require.Equal(t, EntryPurposeChange, e.Purpose)
+		} else {
+			require.Equal(t, EntryPurposeReceive, e.Purpose)
+		}
+
+		k, err := bip32.NewPrivateKeyFromPath(seed, fmt.Sprintf("m/44'/8000'/2'/%d/%d", x.change, x.childNumber))
+		require.NoError(t, err)
+		require.Equal(t, cipher.MustNewSecKey(k.Key), e.Secret)
+		require.NoError(t, e.Verify())
+	}
+
+	require.Equal(t, w.Entries[5].Address, addrs[0])
+	require.Equal(t, w.Entries[3].Address, changeAddrs[0])
+	require.Equal(t, w.Entries[4].Address, changeAddrs[1])
+
+	// A different seed passphrase derives different addresses
+	w2, err := NewWallet("test.wlt", Options{
+		Type:         WalletTypeBip44,
+		Seed:         testBip44Seed,
+		Bip44Account: 2,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, w.Entries[0].Address, w2.Entries[0].Address)
+
+	// Change addresses are not supported by deterministic wallets
+	w3, err := NewWallet("test.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	_, err = w3.GenerateChangeAddresses(1)
+	require.Equal(t, ErrWalletNotBip44, err)
+
+	// Lock and unlock the wallet, the seed passphrase must be preserved
+	require.NoError(t, w.Lock([]byte("pwd"), CryptoTypeSha256Xor))
+	require.Empty(t, w.seedPassphrase())
+	require.Empty(t, w.seed())
+
+	_, err = w.GenerateChangeAddresses(1)
+	require.Equal(t, ErrWalletEncrypted, err)
+
+	err = w.GuardUpdate([]byte("pwd"), func(w *Wallet) error {
+		require.Equal(t, "foo", w.seedPassphrase())
+		require.Equal(t, testBip44Seed, w.seed())
+		_, err := w.GenerateChangeAddresses(1)
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, w.Entries, 7)
+	require.Equal(t, uint32(1), w.Entries[6].Change)
+	require.Equal(t, uint32(2), w.Entries[6].ChildNumber)
+
+	// Save and load the wallet, the child numbers must be preserved
+	dir, err := ioutil.TempDir("", "bip44-wallet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, w.Save(dir))
+	w4, err := Load(filepath.Join(dir, w.Filename()))
+	require.NoError(t, err)
+	require.Equal(t, w.Entries, w4.Entries)
+	require.Equal(t, w.Meta, w4.Meta)
+}
+
+func TestBip44WalletScanAddresses(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Type: WalletTypeBip44,
+		Seed: testBip44Seed,
+	})
+	require.NoError(t, err)
+
+	_, err = w.GenerateChangeAddresses(1)
+	require.NoError(t, err)
+
+	// Give a balance to the 4th external address
+	w2 := w.clone()
+	addrs, err := w2.GenerateSkycoinAddresses(5)
+	require.NoError(t, err)
+
+	bg := mockBalanceGetter{
+		addrs[2]: BalancePair{Confirmed: Balance{Coins: 10}},
+	}
+
+	n, err := w.ScanAddresses(5, bg)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), n)
+
+	// The change entry must be kept, and the external entries extended up to the address with a balance
+	require.Len(t, w.Entries, 5)
+	require.Equal(t, uint32(1), w.Entries[1].Change)
+	require.Equal(t, addrs[2], w.Entries[4].SkycoinAddress())
+	require.Equal(t, uint32(3), w.Entries[4].ChildNumber)
+}
+
+func TestWalletScanAddressHistory(t *testing.T) {
+	// Addresses following the first address of the "seed" deterministic wallet
+	w, err := NewWallet("test.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	w2 := w.clone()
+	addrs, err := w2.GenerateSkycoinAddresses(20)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name     string
+		gapLimit uint64
+		active   []int
+		n        uint64
+		progress []ScanProgress
+	}{
+		{
+			name:     "no history",
+			gapLimit: 5,
+			progress: []ScanProgress{{Scanned: 5}},
+		},
+		{
+			name:     "history within the gap limit",
+			gapLimit: 5,
+			active:   []int{1, 3},
+			n:        4,
+			progress: []ScanProgress{{Scanned: 5, Found: 4}, {Scanned: 9, Found: 4}},
+		},
+		{
+			name:     "history at the end of the gap limit",
+			gapLimit: 5,
+			active:   []int{4, 9, 14},
+			n:        15,
+			progress: []ScanProgress{
+				{Scanned: 5, Found: 5},
+				{Scanned: 10, Found: 10},
+				{Scanned: 15, Found: 15},
+				{Scanned: 20, Found: 15},
+			},
+		},
+		{
+			name:     "history beyond the gap limit is not found",
+			gapLimit: 3,
+			active:   []int{0, 5},
+			n:        1,
+			progress: []ScanProgress{{Scanned: 3, Found: 1}, {Scanned: 4, Found: 1}},
+		},
+		{
+			name:   "zero gap limit",
+			active: []int{0},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWallet("test.wlt", Options{
+				Seed: "seed",
+			})
+			require.NoError(t, err)
+
+			tf := mockTransactionsFinder{}
+			for _, i := range tc.active {
+				tf[addrs[i]] = true
+			}
+
+			var progress []ScanProgress
+			n, err := w.ScanAddressHistory(tc.gapLimit, tf, func(p ScanProgress) {
+				progress = append(progress, p)
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.n, n)
+			require.Equal(t, tc.progress, progress)
+
+			require.Len(t, w.Entries, int(tc.n)+1)
+			for i, e := range w.Entries[1:] {
+				require.Equal(t, addrs[i], e.SkycoinAddress())
+			}
+
+			// The last seed must allow generating the following addresses
+			next, err := w.GenerateSkycoinAddresses(1)
+			require.NoError(t, err)
+			require.Equal(t, addrs[tc.n], next[0])
+		})
+	}
+}
+
+func TestWalletScanAddressHistoryErrors(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+
+	_, err = w.ScanAddressHistory(5, nil, nil)
+	require.Equal(t, ErrNilTransactionsFinder, err)
+
+	w2 := w.clone()
+	require.NoError(t, w2.Lock([]byte("pwd"), CryptoTypeScryptChacha20poly1305))
+	_, err = w2.ScanAddressHistory(5, mockTransactionsFinder{}, nil)
+	require.Equal(t, ErrWalletEncrypted, err)
+
+	c, err := NewWallet("test.wlt", Options{
+		Type: WalletTypeCollection,
+	})
+	require.NoError(t, err)
+	_, err = c.ScanAddressHistory(5, mockTransactionsFinder{}, nil)
+	require.Equal(t, ErrWalletCantGenerateAddresses, err)
+
+	b, err := NewWallet("test.wlt", Options{
+		Coin: CoinTypeBitcoin,
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	_, err = b.ScanAddressHistory(5, mockTransactionsFinder{}, nil)
+	require.Equal(t, errors.New("Wallet address scanning is not supported for Bitcoin wallets"), err)
+}
+
+func TestBip44WalletScanAddressHistory(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Type: WalletTypeBip44,
+		Seed: testBip44Seed,
+	})
+	require.NoError(t, err)
+
+	w2 := w.clone()
+	external, err := w2.GenerateSkycoinAddresses(10)
+	require.NoError(t, err)
+	change, err := w2.GenerateChangeAddresses(10)
+	require.NoError(t, err)
+
+	// These addresses have history, as if they had received coins and spent them all.
+	// The 10th addresses are beyond the gap limit and not found.
+	tf := mockTransactionsFinder{
+		external[2]:                true,
+		change[1].(cipher.Address): true,
+		change[4].(cipher.Address): true,
+		external[9]:                true,
+		change[9].(cipher.Address): true,
+	}
+
+	var chains []uint32
+	n, err := w.ScanAddressHistory(3, tf, func(p ScanProgress) {
+		if len(chains) == 0 || chains[len(chains)-1] != p.Chain {
+			chains = append(chains, p.Chain)
+		}
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), n)
+	require.Equal(t, []uint32{bip44.ExternalChainIndex, bip44.ChangeChainIndex}, chains)
+
+	require.Len(t, w.Entries, 9)
+	for i, e := range w.Entries[1:4] {
+		require.Equal(t, uint32(bip44.ExternalChainIndex), e.Change)
+		require.Equal(t, external[i], e.SkycoinAddress())
+	}
+	for i, e := range w.Entries[4:] {
+		require.Equal(t, uint32(bip44.ChangeChainIndex), e.Change)
+		require.Equal(t, change[i], e.Address)
+		require.Equal(t, EntryPurposeChange, e.Purpose)
+	}
+}
+
+func TestXPubWalletGenerateAddresses(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Type:      WalletTypeXPub,
+		XPub:      testXPub,
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, testXPub, w.XPub())
+
+	addrs, err := w.GenerateAddresses(1)
+	require.NoError(t, err)
+	require.Len(t, addrs, 1)
+	require.Len(t, w.Entries, 3)
+	require.Equal(t, w.Entries[2].Address, addrs[0])
+
+	// Entries must match the public keys derived from the private key of the same bip32 node
+	seed, err := bip39.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	require.NoError(t, err)
+
+	for i, e := range w.Entries {
+		require.Equal(t, uint32(i), e.ChildNumber)
+		require.True(t, e.Secret.Null())
+
+		k, err := bip32.NewPrivateKeyFromPath(seed, fmt.Sprintf("m/44'/0'/0'/%d", i))
+		require.NoError(t, err)
+		require.Equal(t, cipher.MustNewPubKey(k.PublicKey().Key), e.Public)
+		require.Equal(t, cipher.AddressFromPubKey(e.Public), e.Address)
+	}
+
+	// xpub wallets have no secrets to encrypt
+	require.Equal(t, ErrWalletTypeNotEncryptable, w.Lock([]byte("pwd"), CryptoTypeSha256Xor))
+
+	// Save and load the wallet
+	dir, err := ioutil.TempDir("", "xpub-wallet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, w.Save(dir))
+	w2, err := Load(filepath.Join(dir, w.Filename()))
+	require.NoError(t, err)
+	require.Equal(t, w.Entries, w2.Entries)
+	require.Equal(t, w.Meta, w2.Meta)
+}
+
 func TestWalletGetEntry(t *testing.T) {
 	tt := []struct {
 		name    string
@@ -808,6 +1633,306 @@ func TestWalletAddEntry(t *testing.T) {
 	}
 }
 
+func TestWalletUpdateEntryMeta(t *testing.T) {
+	label := "savings"
+	purpose := EntryPurposeChange
+	emptyPurpose := ""
+	badPurpose := "spend"
+	tags := []string{"cold", "exchange", "cold"}
+	badTags := []string{"cold", " exchange"}
+	emptyTags := []string{""}
+
+	tt := []struct {
+		name    string
+		addr    cipher.Address
+		update  EntryMetaUpdate
+		label   string
+		purpose string
+		tags    []string
+		err     error
+	}{
+		{
+			name:    "no changes",
+			purpose: EntryPurposeReceive,
+		},
+		{
+			name: "label, purpose and tags",
+			update: EntryMetaUpdate{
+				Label:   &label,
+				Purpose: &purpose,
+				Tags:    &tags,
+			},
+			label:   label,
+			purpose: EntryPurposeChange,
+			tags:    []string{"cold", "exchange"},
+		},
+		{
+			name: "clear purpose",
+			update: EntryMetaUpdate{
+				Purpose: &emptyPurpose,
+			},
+		},
+		{
+			name: "invalid purpose",
+			update: EntryMetaUpdate{
+				Label:   &label,
+				Purpose: &badPurpose,
+			},
+			purpose: EntryPurposeReceive,
+			err:     ErrInvalidEntryPurpose,
+		},
+		{
+			name: "tag with whitespace",
+			update: EntryMetaUpdate{
+				Tags: &badTags,
+			},
+			purpose: EntryPurposeReceive,
+			err:     ErrInvalidEntryTag,
+		},
+		{
+			name: "empty tag",
+			update: EntryMetaUpdate{
+				Tags: &emptyTags,
+			},
+			purpose: EntryPurposeReceive,
+			err:     ErrInvalidEntryTag,
+		},
+		{
+			name: "unknown address",
+			addr: testutil.MakeAddress(),
+			update: EntryMetaUpdate{
+				Label: &label,
+			},
+			purpose: EntryPurposeReceive,
+			err:     ErrUnknownAddress,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWallet("t.wlt", Options{
+				Seed:      "seed",
+				GenerateN: 2,
+			})
+			require.NoError(t, err)
+
+			addr := tc.addr
+			if addr.Null() {
+				addr = w.Entries[1].SkycoinAddress()
+			}
+
+			err = w.UpdateEntryMeta(addr, tc.update)
+			require.Equal(t, tc.err, err)
+
+			e := w.Entries[1]
+			require.Equal(t, tc.label, e.Label)
+			require.Equal(t, tc.purpose, e.Purpose)
+			require.Equal(t, tc.tags, e.Tags)
+			for _, tag := range tc.tags {
+				require.True(t, e.HasTag(tag))
+			}
+			require.False(t, e.HasTag("missing"))
+
+			// Other entries are not modified
+			require.Empty(t, w.Entries[0].Label)
+			require.Equal(t, EntryPurposeReceive, w.Entries[0].Purpose)
+			require.Empty(t, w.Entries[0].Tags)
+
+			// The metadata survives a readable wallet round trip
+			rw := NewReadableWallet(w)
+			w2, err := rw.ToWallet()
+			require.NoError(t, err)
+			require.Equal(t, w.Entries, w2.Entries)
+		})
+	}
+}
+
+func TestWalletFreezeUxOuts(t *testing.T) {
+	w, err := NewWallet("t.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	require.Empty(t, w.FrozenUxOuts())
+
+	h1 := testutil.RandSHA256(t)
+	h2 := testutil.RandSHA256(t)
+	h3 := testutil.RandSHA256(t)
+
+	// Duplicates are ignored and the hashes are sorted
+	w.FreezeUxOuts([]cipher.SHA256{h1, h2, h1})
+	frozen := []cipher.SHA256{h1, h2}
+	if h1.Hex() > h2.Hex() {
+		frozen = []cipher.SHA256{h2, h1}
+	}
+	require.Equal(t, frozen, w.FrozenUxOuts())
+	require.True(t, w.IsUxOutFrozen(h1))
+	require.True(t, w.IsUxOutFrozen(h2))
+	require.False(t, w.IsUxOutFrozen(h3))
+	require.NoError(t, w.Validate())
+
+	// The frozen outputs are persisted to the wallet file
+	dir := prepareWltDir()
+	require.NoError(t, w.Save(dir))
+	lw, err := Load(filepath.Join(dir, w.Filename()))
+	require.NoError(t, err)
+	require.Equal(t, frozen, lw.FrozenUxOuts())
+
+	// Unfreezing an output that is not frozen is ignored
+	w.UnfreezeUxOuts([]cipher.SHA256{h1, h3})
+	require.Equal(t, []cipher.SHA256{h2}, w.FrozenUxOuts())
+
+	w.UnfreezeUxOuts([]cipher.SHA256{h2})
+	require.Empty(t, w.FrozenUxOuts())
+	_, ok := w.Meta[metaFrozenUxOuts]
+	require.False(t, ok)
+
+	w.Meta[metaFrozenUxOuts] = h1.Hex() + ",xxx"
+	require.Equal(t, errors.New("frozenUxOuts field is not a valid list of hashes"), w.Validate())
+}
+
+func TestWalletFreshChange(t *testing.T) {
+	w, err := NewWallet("t.wlt", Options{
+		Seed:        "seed",
+		FreshChange: true,
+	})
+	require.NoError(t, err)
+	require.True(t, w.FreshChange())
+	require.NoError(t, w.Validate())
+
+	require.NoError(t, w.SetFreshChange(false))
+	require.False(t, w.FreshChange())
+	_, ok := w.Meta[metaFreshChange]
+	require.False(t, ok)
+
+	require.NoError(t, w.SetFreshChange(true))
+	require.True(t, w.FreshChange())
+
+	// The option is persisted to the wallet file
+	dir := prepareWltDir()
+	require.NoError(t, w.Save(dir))
+	lw, err := Load(filepath.Join(dir, w.Filename()))
+	require.NoError(t, err)
+	require.True(t, lw.FreshChange())
+
+	w.Meta[metaFreshChange] = "xxx"
+	require.Equal(t, errors.New("freshChange field is not a valid bool"), w.Validate())
+
+	// Collection wallets can't generate change addresses
+	_, err = NewWallet("t.wlt", Options{
+		Type:        WalletTypeCollection,
+		FreshChange: true,
+	})
+	require.Equal(t, ErrWalletCantGenerateAddresses, err)
+
+	c, err := NewWallet("t.wlt", Options{
+		Type: WalletTypeCollection,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ErrWalletCantGenerateAddresses, c.SetFreshChange(true))
+}
+
+func TestWalletNewChangeAddress(t *testing.T) {
+	tt := []struct {
+		name   string
+		opts   Options
+		change uint32
+	}{
+		{
+			name: "deterministic",
+			opts: Options{
+				Seed: "seed",
+			},
+		},
+		{
+			name: "bip44",
+			opts: Options{
+				Type: WalletTypeBip44,
+				Seed: testBip44Seed,
+			},
+			change: bip44.ChangeChainIndex,
+		},
+		{
+			name: "xpub",
+			opts: Options{
+				Type: WalletTypeXPub,
+				XPub: testXPub,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWallet("t.wlt", tc.opts)
+			require.NoError(t, err)
+			n := len(w.Entries)
+
+			tf := mockTransactionsFinder{}
+
+			// No change address yet
+			_, ok, err := w.UnusedChangeAddress(tf)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			addr, err := w.NewChangeAddress()
+			require.NoError(t, err)
+			require.Len(t, w.Entries, n+1)
+			e := w.Entries[n]
+			require.Equal(t, addr, e.SkycoinAddress())
+			require.Equal(t, EntryPurposeChange, e.Purpose)
+			require.Equal(t, tc.change, e.Change)
+			require.NoError(t, w.Validate())
+
+			// The change address is reused until it has transaction history
+			a, ok, err := w.UnusedChangeAddress(tf)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, addr, a)
+
+			tf[addr] = true
+			a, ok, err = w.UnusedChangeAddress(tf)
+			require.NoError(t, err)
+			require.False(t, ok)
+			require.Equal(t, addr, a)
+
+			addr2, err := w.NewChangeAddress()
+			require.NoError(t, err)
+			require.NotEqual(t, addr, addr2)
+			a, ok, err = w.UnusedChangeAddress(tf)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, addr2, a)
+
+			// An issued change address is not reused
+			w.issueChangeAddress(addr2)
+			require.NoError(t, w.Validate())
+			require.Equal(t, []cipher.Address{addr2}, w.IssuedChangeAddresses())
+			_, ok, err = w.UnusedChangeAddress(tf)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			w.Meta[metaIssuedChange] = addr2.String() + ",xxx"
+			require.EqualError(t, w.Validate(), "issuedChange field is not a valid list of addresses")
+		})
+	}
+
+	w, err := NewWallet("t.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+	_, _, err = w.UnusedChangeAddress(nil)
+	require.Equal(t, ErrNilTransactionsFinder, err)
+
+	w, err = NewWallet("t.wlt", Options{
+		Seed:       "seed",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: CryptoTypeSha256Xor,
+	})
+	require.NoError(t, err)
+	_, err = w.NewChangeAddress()
+	require.Equal(t, ErrWalletEncrypted, err)
+}
+
 func TestWalletGuard(t *testing.T) {
 	for ct := range cryptoTable {
 		t.Run(fmt.Sprintf("crypto=%v", ct), func(t *testing.T) {
@@ -1170,6 +2295,25 @@ func TestWalletValidate(t *testing.T) {
 		"secrets":    "xacsdasdasdasd",
 	}
 
+	goodMetaBip44 := map[string]string{
+		"filename":       "foo.wlt",
+		"type":           WalletTypeBip44,
+		"coin":           string(CoinTypeSkycoin),
+		"encrypted":      "false",
+		"seed":           testBip44Seed,
+		"seedPassphrase": "",
+		"bip44Coin":      "8000",
+		"bip44Account":   "0",
+	}
+
+	goodMetaXPub := map[string]string{
+		"filename":  "foo.wlt",
+		"type":      WalletTypeXPub,
+		"coin":      string(CoinTypeSkycoin),
+		"encrypted": "false",
+		"xpub":      testXPub,
+	}
+
 	copyMap := func(m map[string]string) map[string]string {
 		n := make(map[string]string, len(m))
 		for k, v := range m {
@@ -1258,6 +2402,48 @@ func TestWalletValidate(t *testing.T) {
 			name: "valid encrypted",
 			meta: goodMetaEncrypted,
 		},
+		{
+			name: "bip44 missing bip44 coin",
+			meta: delField(goodMetaBip44, metaBip44Coin),
+			err:  errors.New("bip44Coin field is not a valid uint32"),
+		},
+		{
+			name: "bip44 invalid bip44 account",
+			meta: setField(goodMetaBip44, metaBip44Account, "2147483648"),
+			err:  errors.New("bip44Account field is not a valid bip44 account number"),
+		},
+		{
+			name: "valid bip44 without last seed",
+			meta: goodMetaBip44,
+		},
+		{
+			name: "xpub encrypted",
+			meta: setField(goodMetaXPub, metaEncrypted, "true"),
+			err:  errors.New("xpub wallet can't be encrypted"),
+		},
+		{
+			name: "xpub missing xpub",
+			meta: delField(goodMetaXPub, metaXPub),
+			err:  errors.New("xpub field not set"),
+		},
+		{
+			name: "xpub invalid",
+			meta: setField(goodMetaXPub, metaXPub, "xpubfoo"),
+			err:  errors.New("invalid xpub: Serialized keys should be exactly 82 bytes"),
+		},
+		{
+			name: "valid xpub without seed",
+			meta: goodMetaXPub,
+		},
+		{
+			name: "valid collection without seed",
+			meta: setField(delField(delField(goodMetaUnencrypted, metaSeed), metaLastSeed), metaType, WalletTypeCollection),
+		},
+		{
+			name: "collection encrypted secrets missing",
+			meta: setField(delField(goodMetaEncrypted, metaSecrets), metaType, WalletTypeCollection),
+			err:  errors.New("wallet is encrypted, but secrets field not set"),
+		},
 	}
 
 	for _, tc := range cases {
@@ -126,7 +126,8 @@ func (wlts Wallets) ToReadable() []*ReadableWallet {
 func (wlts Wallets) containsDuplicate() (string, cipher.Address, bool) {
 	m := make(map[cipher.Address]struct{}, len(wlts))
 	for wltID, wlt := range wlts {
-		if len(wlt.Entries) == 0 {
+		// Collection wallets have no seed, so their first address does not identify them
+		if len(wlt.Entries) == 0 || wlt.Type() == WalletTypeCollection {
 			continue
 		}
 		addr := wlt.Entries[0].SkycoinAddress()
@@ -143,7 +144,8 @@ func (wlts Wallets) containsDuplicate() (string, cipher.Address, bool) {
 // containsEmpty returns true there is an empty wallet and the ID of that wallet if true
 func (wlts Wallets) containsEmpty() (string, bool) {
 	for wltID, wlt := range wlts {
-		if len(wlt.Entries) == 0 {
+		// Collection wallets can be empty
+		if len(wlt.Entries) == 0 && wlt.Type() != WalletTypeCollection {
 			return wltID, true
 		}
 	}
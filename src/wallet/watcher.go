@@ -0,0 +1,256 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// walletDirDebounce is how long the wallet directory watcher waits for a wallet file to stop changing
+	walletDirDebounce = 500 * time.Millisecond
+	// maxWalletDirEvents is the number of recent wallet directory events kept by the service
+	maxWalletDirEvents = 100
+)
+
+// WalletDirEventType is the type of a WalletDirEvent
+type WalletDirEventType string
+
+const (
+	// WalletDirEventLoaded is the event of a wallet file added to the wallet directory and loaded
+	WalletDirEventLoaded WalletDirEventType = "loaded"
+	// WalletDirEventReloaded is the event of a loaded wallet file changed in the wallet directory and reloaded
+	WalletDirEventReloaded WalletDirEventType = "reloaded"
+	// WalletDirEventUnloaded is the event of a loaded wallet file removed from the wallet directory and unloaded
+	WalletDirEventUnloaded WalletDirEventType = "unloaded"
+	// WalletDirEventRejected is the event of a wallet file added or changed in the wallet directory which can't be loaded
+	WalletDirEventRejected WalletDirEventType = "rejected"
+)
+
+// WalletDirEvent is a change of the wallet directory applied by the wallet directory watcher
+type WalletDirEvent struct {
+	Time     time.Time
+	Filename string
+	Type     WalletDirEventType
+	// Err is the reason a wallet file was rejected
+	Err error
+}
+
+// Run watches the wallet directory if Config.WatchWalletDir is set, until Shutdown is called.
+// Wallet files added to, changed in or removed from the wallet directory by other programs
+// are loaded, reloaded or unloaded. Changes made by the service itself are ignored.
+func (serv *Service) Run() error {
+	defer close(serv.done)
+
+	if !serv.config.EnableWalletAPI || !serv.config.WatchWalletDir {
+		<-serv.quit
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch wallet directory: %v", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(serv.config.WalletDir); err != nil {
+		return fmt.Errorf("failed to watch wallet directory %s: %v", serv.config.WalletDir, err)
+	}
+
+	logger.Infof("Watching wallet directory %s", serv.config.WalletDir)
+
+	// Applies the changes made since the wallets were loaded, before the directory was watched
+	if err := serv.syncWalletDir(); err != nil {
+		return fmt.Errorf("failed to sync wallet directory %s: %v", serv.config.WalletDir, err)
+	}
+
+	// Changed files are reloaded once they have not changed for walletDirDebounce,
+	// so that a file being written is not loaded partially
+	pending := make(map[string]struct{})
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-serv.quit:
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			name := filepath.Base(event.Name)
+			if !strings.HasSuffix(name, "."+WalletExt) {
+				continue
+			}
+
+			pending[name] = struct{}{}
+			debounce = time.After(walletDirDebounce)
+
+		case <-debounce:
+			for name := range pending {
+				serv.reloadWalletFile(name)
+			}
+			pending = make(map[string]struct{})
+			debounce = nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.WithError(err).Warning("Wallet directory watcher error")
+		}
+	}
+}
+
+// Shutdown stops the wallet directory watcher
+func (serv *Service) Shutdown() {
+	close(serv.quit)
+	<-serv.done
+}
+
+// WalletDirEvents returns the recent changes of the wallet directory applied by the wallet directory watcher,
+// oldest first
+func (serv *Service) WalletDirEvents() ([]WalletDirEvent, error) {
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	events := make([]WalletDirEvent, len(serv.walletDirEvents))
+	copy(events, serv.walletDirEvents)
+	return events, nil
+}
+
+// syncWalletDir reloads the wallet files of the wallet directory and the loaded wallets
+func (serv *Service) syncWalletDir() error {
+	entries, err := ioutil.ReadDir(serv.config.WalletDir)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{})
+	for _, e := range entries {
+		if e.Mode().IsRegular() && strings.HasSuffix(e.Name(), "."+WalletExt) {
+			names[e.Name()] = struct{}{}
+		}
+	}
+
+	serv.RLock()
+	for id := range serv.wallets {
+		names[id] = struct{}{}
+	}
+	serv.RUnlock()
+
+	for name := range names {
+		serv.reloadWalletFile(name)
+	}
+
+	return nil
+}
+
+// reloadWalletFile loads, reloads or unloads a wallet file of the wallet directory according to its current state
+func (serv *Service) reloadWalletFile(filename string) {
+	serv.Lock()
+	defer serv.Unlock()
+
+	loaded := serv.wallets.get(filename)
+
+	fn := filepath.Join(serv.config.WalletDir, filename)
+	if _, err := os.Stat(fn); os.IsNotExist(err) {
+		if loaded != nil {
+			serv.removeWallet(filename)
+			serv.addWalletDirEvent(filename, WalletDirEventUnloaded, nil)
+		}
+		return
+	}
+
+	w, err := loadWallet(fn)
+	if err != nil {
+		serv.addWalletDirEvent(filename, WalletDirEventRejected, err)
+		return
+	}
+
+	if loaded != nil {
+		// Ignores the files saved by the service itself
+		same, err := sameWalletContent(loaded, w)
+		if err != nil {
+			serv.addWalletDirEvent(filename, WalletDirEventRejected, err)
+			return
+		}
+		if same {
+			return
+		}
+	}
+
+	if len(w.Entries) == 0 && w.Type() != WalletTypeCollection {
+		serv.addWalletDirEvent(filename, WalletDirEventRejected, fmt.Errorf("empty wallet file %s", filename))
+		return
+	}
+
+	if w.Type() != WalletTypeCollection {
+		addr := w.Entries[0].Address.String()
+		if id, ok := serv.firstAddrIDMap[addr]; ok && id != filename {
+			serv.addWalletDirEvent(filename, WalletDirEventRejected, fmt.Errorf("duplicate wallet with initial address %s of wallet %s", addr, id))
+			return
+		}
+	}
+
+	if loaded != nil {
+		serv.removeWallet(filename)
+	}
+
+	serv.wallets.set(w)
+	if w.Type() != WalletTypeCollection {
+		serv.firstAddrIDMap[w.Entries[0].Address.String()] = filename
+	}
+
+	if loaded != nil {
+		serv.addWalletDirEvent(filename, WalletDirEventReloaded, nil)
+	} else {
+		serv.addWalletDirEvent(filename, WalletDirEventLoaded, nil)
+	}
+}
+
+func (serv *Service) addWalletDirEvent(filename string, eventType WalletDirEventType, err error) {
+	l := logger.WithField("wallet", filename)
+	if err != nil {
+		l.WithError(err).Warningf("Wallet directory watcher: wallet %s", eventType)
+	} else {
+		l.Infof("Wallet directory watcher: wallet %s", eventType)
+	}
+
+	serv.walletDirEvents = append(serv.walletDirEvents, WalletDirEvent{
+		Time:     time.Now().UTC(),
+		Filename: filename,
+		Type:     eventType,
+		Err:      err,
+	})
+
+	if n := len(serv.walletDirEvents); n > maxWalletDirEvents {
+		serv.walletDirEvents = serv.walletDirEvents[n-maxWalletDirEvents:]
+	}
+}
+
+// sameWalletContent returns true if two wallets are saved to the same file content
+func sameWalletContent(a, b *Wallet) (bool, error) {
+	da, err := json.Marshal(NewReadableWallet(a))
+	if err != nil {
+		return false, err
+	}
+
+	db, err := json.Marshal(NewReadableWallet(b))
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(da, db), nil
+}
@@ -0,0 +1,193 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func walletDirEventTypes(t *testing.T, s *Service) []WalletDirEventType {
+	events, err := s.WalletDirEvents()
+	require.NoError(t, err)
+
+	types := make([]WalletDirEventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestServiceReloadWalletFile(t *testing.T) {
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	// Wallets saved by the service are ignored
+	_, err = s.CreateWallet("a.wlt", Options{
+		Seed:  "seed-a",
+		Label: "a",
+	}, nil)
+	require.NoError(t, err)
+	s.reloadWalletFile("a.wlt")
+	require.Empty(t, walletDirEventTypes(t, s))
+
+	// A wallet file added to the directory is loaded
+	w, err := NewWallet("b.wlt", Options{
+		Seed:  "seed-b",
+		Label: "b",
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Save(dir))
+	s.reloadWalletFile("b.wlt")
+	require.Equal(t, []WalletDirEventType{WalletDirEventLoaded}, walletDirEventTypes(t, s))
+
+	lw, err := s.GetWallet("b.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "b", lw.Label())
+
+	// A wallet file changed in the directory is reloaded
+	w.setLabel("b2")
+	require.NoError(t, w.Save(dir))
+	s.reloadWalletFile("b.wlt")
+	require.Equal(t, []WalletDirEventType{
+		WalletDirEventLoaded,
+		WalletDirEventReloaded,
+	}, walletDirEventTypes(t, s))
+
+	lw, err = s.GetWallet("b.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "b2", lw.Label())
+
+	// A wallet file with the seed of another wallet is rejected
+	w, err = NewWallet("c.wlt", Options{
+		Seed: "seed-a",
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Save(dir))
+	s.reloadWalletFile("c.wlt")
+	_, err = s.GetWallet("c.wlt")
+	require.Equal(t, ErrWalletNotExist, err)
+
+	// An invalid wallet file is rejected, and the loaded wallet is kept
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.wlt"), []byte("{"), 0600))
+	s.reloadWalletFile("b.wlt")
+	lw, err = s.GetWallet("b.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "b2", lw.Label())
+
+	events, err := s.WalletDirEvents()
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, "c.wlt", events[2].Filename)
+	require.Equal(t, WalletDirEventRejected, events[2].Type)
+	require.Error(t, events[2].Err)
+	require.Equal(t, "b.wlt", events[3].Filename)
+	require.Equal(t, WalletDirEventRejected, events[3].Type)
+	require.Error(t, events[3].Err)
+
+	// A wallet file removed from the directory is unloaded
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.wlt")))
+	s.reloadWalletFile("a.wlt")
+	_, err = s.GetWallet("a.wlt")
+	require.Equal(t, ErrWalletNotExist, err)
+	require.Equal(t, WalletDirEventUnloaded, walletDirEventTypes(t, s)[4])
+
+	// Its seed can be used again
+	s.reloadWalletFile("c.wlt")
+	_, err = s.GetWallet("c.wlt")
+	require.NoError(t, err)
+	require.Equal(t, WalletDirEventLoaded, walletDirEventTypes(t, s)[5])
+
+	// Removing a file which is not loaded is ignored
+	require.NoError(t, os.Remove(filepath.Join(dir, "c.wlt")))
+	s.reloadWalletFile("c.wlt")
+	s.reloadWalletFile("c.wlt")
+	require.Len(t, walletDirEventTypes(t, s), 7)
+
+	// Only the recent events are kept
+	for i := 0; i < maxWalletDirEvents; i++ {
+		s.reloadWalletFile("b.wlt")
+	}
+	require.Len(t, walletDirEventTypes(t, s), maxWalletDirEvents)
+}
+
+func TestServiceRunWatchWalletDir(t *testing.T) {
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+		WatchWalletDir:  true,
+	})
+	require.NoError(t, err)
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- s.Run()
+	}()
+
+	waitWalletLoaded := func(id string, loaded bool) {
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			_, err := s.GetWallet(id)
+			if (err == nil) == loaded {
+				return
+			}
+			require.True(t, time.Now().Before(deadline), "timeout waiting for wallet %s", id)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	w, err := NewWallet("a.wlt", Options{
+		Seed: "seed-a",
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Save(dir))
+	waitWalletLoaded("a.wlt", true)
+
+	// Files which are not wallets are ignored
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("{"), 0600))
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.wlt")))
+	waitWalletLoaded("a.wlt", false)
+
+	require.Equal(t, []WalletDirEventType{
+		WalletDirEventLoaded,
+		WalletDirEventUnloaded,
+	}, walletDirEventTypes(t, s))
+
+	s.Shutdown()
+	require.NoError(t, <-errC)
+}
+
+func TestServiceRunNoWatch(t *testing.T) {
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- s.Run()
+	}()
+
+	s.Shutdown()
+	require.NoError(t, <-errC)
+}